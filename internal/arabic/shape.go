@@ -0,0 +1,189 @@
+// Package arabic prepares Arabic text for output devices without a text
+// shaping engine: thermal printers, plain text and the PDF writer. Shape
+// replaces letters with their contextual presentation forms and Visual
+// reorders a line from logical to display order.
+package arabic
+
+import "unicode"
+
+// Form is the position of a letter within a joined word.
+type Form int
+
+const (
+	Isolated Form = iota
+	Final
+	Initial
+	Medial
+)
+
+// forms lists the presentation forms of each letter in Form order; zero
+// means the letter has no such form.
+var forms = map[rune][4]rune{
+	0x0621: {0xFE80, 0, 0, 0},                // hamza
+	0x0622: {0xFE81, 0xFE82, 0, 0},           // alef with madda above
+	0x0623: {0xFE83, 0xFE84, 0, 0},           // alef with hamza above
+	0x0624: {0xFE85, 0xFE86, 0, 0},           // waw with hamza above
+	0x0625: {0xFE87, 0xFE88, 0, 0},           // alef with hamza below
+	0x0626: {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C}, // yeh with hamza above
+	0x0627: {0xFE8D, 0xFE8E, 0, 0},           // alef
+	0x0628: {0xFE8F, 0xFE90, 0xFE91, 0xFE92}, // beh
+	0x0629: {0xFE93, 0xFE94, 0, 0},           // teh marbuta
+	0x062A: {0xFE95, 0xFE96, 0xFE97, 0xFE98}, // teh
+	0x062B: {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C}, // theh
+	0x062C: {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0}, // jeem
+	0x062D: {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4}, // hah
+	0x062E: {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8}, // khah
+	0x062F: {0xFEA9, 0xFEAA, 0, 0},           // dal
+	0x0630: {0xFEAB, 0xFEAC, 0, 0},           // thal
+	0x0631: {0xFEAD, 0xFEAE, 0, 0},           // reh
+	0x0632: {0xFEAF, 0xFEB0, 0, 0},           // zain
+	0x0633: {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4}, // seen
+	0x0634: {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8}, // sheen
+	0x0635: {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC}, // sad
+	0x0636: {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0}, // dad
+	0x0637: {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4}, // tah
+	0x0638: {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8}, // zah
+	0x0639: {0xFEC9, 0xFECA, 0xFECB, 0xFECC}, // ain
+	0x063A: {0xFECD, 0xFECE, 0xFECF, 0xFED0}, // ghain
+	0x0641: {0xFED1, 0xFED2, 0xFED3, 0xFED4}, // feh
+	0x0642: {0xFED5, 0xFED6, 0xFED7, 0xFED8}, // qaf
+	0x0643: {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC}, // kaf
+	0x0644: {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0}, // lam
+	0x0645: {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4}, // meem
+	0x0646: {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8}, // noon
+	0x0647: {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC}, // heh
+	0x0648: {0xFEED, 0xFEEE, 0, 0},           // waw
+	0x0649: {0xFEEF, 0xFEF0, 0, 0},           // alef maksura
+	0x064A: {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4}, // yeh
+	0x067E: {0xFB56, 0xFB57, 0xFB58, 0xFB59}, // peh
+	0x0686: {0xFB7A, 0xFB7B, 0xFB7C, 0xFB7D}, // tcheh
+	0x0698: {0xFB8A, 0xFB8B, 0, 0},           // jeh
+	0x06A9: {0xFB8E, 0xFB8F, 0xFB90, 0xFB91}, // keheh
+	0x06AF: {0xFB92, 0xFB93, 0xFB94, 0xFB95}, // gaf
+	0x06CC: {0xFBFC, 0xFBFD, 0xFBFE, 0xFBFF}, // farsi yeh
+}
+
+// lamAlef maps an alef to the isolated form of its ligature with a
+// preceding lam; the final form is the next code point.
+var lamAlef = map[rune]rune{
+	0x0622: 0xFEF5,
+	0x0623: 0xFEF7,
+	0x0625: 0xFEF9,
+	0x0627: 0xFEFB,
+}
+
+const (
+	lam     = 0x0644
+	tatweel = 0x0640
+)
+
+// Forms returns the presentation forms of an Arabic letter in Form order.
+func Forms(r rune) ([4]rune, bool) {
+	f, ok := forms[r]
+	return f, ok
+}
+
+// Base returns the letter and form of a presentation form, or ok false when
+// r is not one.
+func Base(r rune) (letter rune, form Form, ok bool) {
+	l, ok := bases[r]
+	return l.letter, l.form, ok
+}
+
+type base struct {
+	letter rune
+	form   Form
+}
+
+var bases = func() map[rune]base {
+	m := map[rune]base{}
+	for letter, f := range forms {
+		for i, g := range f {
+			if g != 0 {
+				m[g] = base{letter, Form(i)}
+			}
+		}
+	}
+	return m
+}()
+
+// joinsBefore reports whether r connects to the letter before it.
+func joinsBefore(r rune) bool {
+	if r == tatweel {
+		return true
+	}
+	f, ok := forms[r]
+	return ok && f[Final] != 0
+}
+
+// joinsAfter reports whether r connects to the letter after it.
+func joinsAfter(r rune) bool {
+	if r == tatweel {
+		return true
+	}
+	f, ok := forms[r]
+	return ok && f[Medial] != 0
+}
+
+func transparent(r rune) bool {
+	return unicode.Is(unicode.Mn, r)
+}
+
+// Shape replaces the Arabic letters of s with their contextual forms and
+// lam followed by alef with the lam-alef ligature. Combining marks are
+// kept and do not break a join. Other text is returned unchanged.
+func Shape(s string) string {
+	in := []rune(s)
+	out := make([]rune, 0, len(in))
+	// neighbour returns the nearest letter before (step -1) or after
+	// (step 1) position i, skipping combining marks.
+	neighbour := func(i, step int) (rune, int) {
+		for j := i + step; j >= 0 && j < len(in); j += step {
+			if !transparent(in[j]) {
+				return in[j], j
+			}
+		}
+		return 0, -1
+	}
+	for i := 0; i < len(in); i++ {
+		r := in[i]
+		f, ok := forms[r]
+		if !ok {
+			out = append(out, r)
+			continue
+		}
+		prev, _ := neighbour(i, -1)
+		before := joinsAfter(prev) && joinsBefore(r)
+		next, j := neighbour(i, 1)
+
+		if r == lam {
+			if lig, ok := lamAlef[next]; ok {
+				if before {
+					lig++
+				}
+				out = append(out, lig)
+				// Keep the marks between lam and alef, drop the alef.
+				out = append(out, in[i+1:j]...)
+				i = j
+				continue
+			}
+		}
+
+		after := joinsAfter(r) && joinsBefore(next)
+		form := Isolated
+		switch {
+		case before && after:
+			form = Medial
+		case before:
+			form = Final
+		case after:
+			form = Initial
+		}
+		g := f[form]
+		if g == 0 {
+			g = f[Isolated]
+		}
+		out = append(out, g)
+	}
+	return string(out)
+}
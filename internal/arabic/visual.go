@@ -0,0 +1,249 @@
+package arabic
+
+import "golang.org/x/text/unicode/bidi"
+
+// Bidi types after the weak type rules; everything neutral is on.
+type class uint8
+
+const (
+	l class = iota
+	r
+	en
+	an
+	es
+	et
+	cs
+	nsm
+	ws
+	on
+	al
+)
+
+// mirrors holds the characters drawn mirrored in right-to-left text.
+var mirrors = map[rune]rune{
+	'(': ')', ')': '(',
+	'[': ']', ']': '[',
+	'{': '}', '}': '{',
+	'<': '>', '>': '<',
+	'«': '»', '»': '«',
+}
+
+// Visual shapes line and returns it in display order for a device that
+// prints left to right. It applies the Unicode bidirectional algorithm to a
+// single line without explicit embeddings: right-to-left runs are reversed
+// and their brackets mirrored, numbers stay left to right. rtl sets the
+// paragraph direction, so the label of a right-to-left receipt line ends up
+// at the right margin.
+func Visual(line string, rtl bool) string {
+	rs := []rune(Shape(line))
+	types := make([]class, len(rs))
+	hasRTL := false
+	for i, c := range rs {
+		types[i] = classify(c)
+		hasRTL = hasRTL || types[i] == r || types[i] == al || types[i] == an
+	}
+	if !rtl && !hasRTL {
+		return string(rs)
+	}
+	baseLevel, baseDir := uint8(0), l
+	if rtl {
+		baseLevel, baseDir = 1, r
+	}
+	resolveWeak(types, baseDir)
+	resolveNeutral(types, baseDir)
+
+	levels := make([]uint8, len(rs))
+	for i, t := range types {
+		switch {
+		case baseLevel == 0 && t == r:
+			levels[i] = 1
+		case baseLevel == 0 && (t == en || t == an):
+			levels[i] = 2
+		case baseLevel == 1 && (t == l || t == en || t == an):
+			levels[i] = 2
+		default:
+			levels[i] = baseLevel
+		}
+	}
+	// Trailing white space takes the paragraph level.
+	for i := len(rs) - 1; i >= 0 && classify(rs[i]) == ws; i-- {
+		levels[i] = baseLevel
+	}
+
+	for i, c := range rs {
+		if levels[i]%2 == 1 {
+			if m, ok := mirrors[c]; ok {
+				rs[i] = m
+			}
+		}
+	}
+	for lv := uint8(2); lv >= 1; lv-- {
+		for i := 0; i < len(rs); {
+			if levels[i] < lv {
+				i++
+				continue
+			}
+			j := i
+			for j < len(rs) && levels[j] >= lv {
+				j++
+			}
+			for a, b := i, j-1; a < b; a, b = a+1, b-1 {
+				rs[a], rs[b] = rs[b], rs[a]
+				levels[a], levels[b] = levels[b], levels[a]
+			}
+			i = j
+		}
+	}
+	return string(rs)
+}
+
+// IsRTL reports whether the first strong character of s is right to left,
+// which makes s a right-to-left paragraph by default.
+func IsRTL(s string) bool {
+	for _, c := range s {
+		switch classify(c) {
+		case r, al:
+			return true
+		case l:
+			return false
+		}
+	}
+	return false
+}
+
+func classify(c rune) class {
+	p, _ := bidi.LookupRune(c)
+	switch p.Class() {
+	case bidi.L:
+		return l
+	case bidi.R:
+		return r
+	case bidi.AL:
+		return al
+	case bidi.EN:
+		return en
+	case bidi.AN:
+		return an
+	case bidi.ES:
+		return es
+	case bidi.ET:
+		return et
+	case bidi.CS:
+		return cs
+	case bidi.NSM:
+		return nsm
+	case bidi.WS, bidi.S, bidi.B:
+		return ws
+	}
+	return on
+}
+
+// resolveWeak applies rules W1 to W7.
+func resolveWeak(t []class, sos class) {
+	// W1: marks take the type of the character before them.
+	for i := range t {
+		if t[i] == nsm {
+			if i == 0 {
+				t[i] = sos
+			} else {
+				t[i] = t[i-1]
+			}
+		}
+	}
+	// W2, W3: numbers after Arabic letters are Arabic numbers; AL is R.
+	strong := sos
+	for i := range t {
+		switch t[i] {
+		case l, r, al:
+			strong = t[i]
+		case en:
+			if strong == al {
+				t[i] = an
+			}
+		}
+	}
+	for i := range t {
+		if t[i] == al {
+			t[i] = r
+		}
+	}
+	// W4: a single separator between two numbers of the same type.
+	for i := 1; i+1 < len(t); i++ {
+		switch {
+		case t[i] == es && t[i-1] == en && t[i+1] == en:
+			t[i] = en
+		case t[i] == cs && t[i-1] == t[i+1] && (t[i-1] == en || t[i-1] == an):
+			t[i] = t[i-1]
+		}
+	}
+	// W5: terminators next to European numbers.
+	for i := 0; i < len(t); i++ {
+		if t[i] != et {
+			continue
+		}
+		j := i
+		for j < len(t) && t[j] == et {
+			j++
+		}
+		if (i > 0 && t[i-1] == en) || (j < len(t) && t[j] == en) {
+			for k := i; k < j; k++ {
+				t[k] = en
+			}
+		}
+		i = j
+	}
+	// W6: remaining separators and terminators are neutral.
+	for i := range t {
+		if t[i] == es || t[i] == et || t[i] == cs {
+			t[i] = on
+		}
+	}
+	// W7: European numbers in left-to-right context are L.
+	strong = sos
+	for i := range t {
+		switch t[i] {
+		case l, r:
+			strong = t[i]
+		case en:
+			if strong == l {
+				t[i] = l
+			}
+		}
+	}
+}
+
+// resolveNeutral applies rules N1 and N2: neutrals between two characters
+// of the same direction take it, others the paragraph direction. Numbers
+// count as right to left.
+func resolveNeutral(t []class, base class) {
+	dir := func(c class) class {
+		if c == en || c == an {
+			return r
+		}
+		return c
+	}
+	for i := 0; i < len(t); i++ {
+		if t[i] != ws && t[i] != on {
+			continue
+		}
+		j := i
+		for j < len(t) && (t[j] == ws || t[j] == on) {
+			j++
+		}
+		before, after := base, base
+		if i > 0 {
+			before = dir(t[i-1])
+		}
+		if j < len(t) {
+			after = dir(t[j])
+		}
+		resolved := base
+		if before == after {
+			resolved = before
+		}
+		for k := i; k < j; k++ {
+			t[k] = resolved
+		}
+		i = j
+	}
+}
@@ -0,0 +1,247 @@
+// Package barcode decodes scanned barcodes: plain product codes, in-store
+// variable-measure EAN-13 codes printed by scales with an embedded weight,
+// price or count, and GS1 element strings (GS1-128, GS1 DataMatrix) that
+// carry a GTIN with batch, expiry and serial. It only decodes; the caller
+// resolves the product by trying Result.Lookup in order.
+//
+// It also encodes EAN-13 and Code 128 into bar modules for the label
+// renderers.
+package barcode
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Kind is the kind of a decoded barcode.
+type Kind string
+
+const (
+	KindPlain           Kind = "plain"
+	KindVariableMeasure Kind = "variable_measure"
+	KindGS1             Kind = "gs1"
+)
+
+// ValueType is what the value embedded in a variable-measure barcode is.
+type ValueType string
+
+const (
+	ValueWeight ValueType = "weight"
+	ValuePrice  ValueType = "price"
+	ValueCount  ValueType = "count"
+)
+
+// ErrInvalidRule is returned by Rule.Validate for an impossible layout.
+var ErrInvalidRule = errors.New("invalid barcode rule")
+
+// ErrInvalidGS1 is returned for a malformed GS1 element string.
+var ErrInvalidGS1 = errors.New("invalid GS1 barcode")
+
+// Rule is the layout of a variable-measure EAN-13 barcode: the prefix, the
+// item code, an optional check digit over the value, the value and the
+// final check digit. The digits always add up to 13.
+type Rule struct {
+	Prefix          string
+	ValueType       ValueType
+	ItemDigits      int
+	ValueDigits     int
+	ValueDecimals   int
+	ValueCheckDigit bool
+}
+
+// Validate checks that the rule describes a 13-digit barcode with an
+// in-store prefix (20-29).
+func (r Rule) Validate() error {
+	if len(r.Prefix) < 2 || len(r.Prefix) > 3 || r.Prefix[0] != '2' || !isDigits(r.Prefix) {
+		return fmt.Errorf("%w: prefix must be 2 or 3 digits starting with 2", ErrInvalidRule)
+	}
+	switch r.ValueType {
+	case ValueWeight, ValuePrice, ValueCount:
+	default:
+		return fmt.Errorf("%w: value type must be weight, price or count", ErrInvalidRule)
+	}
+	if r.ItemDigits <= 0 || r.ValueDigits <= 0 {
+		return fmt.Errorf("%w: item and value digits must be positive", ErrInvalidRule)
+	}
+	if r.ValueDecimals < 0 || r.ValueDecimals > r.ValueDigits {
+		return fmt.Errorf("%w: value decimals must be between 0 and the value digits", ErrInvalidRule)
+	}
+	if r.length() != 13 {
+		return fmt.Errorf("%w: prefix, item, value and check digits add up to %d, not 13", ErrInvalidRule, r.length())
+	}
+	return nil
+}
+
+func (r Rule) length() int {
+	n := len(r.Prefix) + r.ItemDigits + r.ValueDigits + 1
+	if r.ValueCheckDigit {
+		n++
+	}
+	return n
+}
+
+// Result is a decoded barcode.
+type Result struct {
+	Raw  string
+	Kind Kind
+	// Lookup lists the codes to resolve the product by, best first.
+	Lookup []string
+	// ItemCode is the item code of a variable-measure barcode.
+	ItemCode string
+	// GTIN is the 14-digit GTIN of a GS1 barcode.
+	GTIN string
+	// Quantity is an embedded weight (kg) or count, Price an embedded
+	// price; either may be nil.
+	Quantity *big.Rat
+	Price    *big.Rat
+	Batch    string
+	Serial   string
+	// Expiry, BestBefore and ProductionDate come from GS1 AIs 17, 15
+	// and 11.
+	Expiry         *time.Time
+	BestBefore     *time.Time
+	ProductionDate *time.Time
+	// Elements holds every GS1 element read, by AI.
+	Elements map[string]string
+}
+
+// Parse decodes a scanned code. Codes that are neither a variable-measure
+// barcode matching one of rules nor a GS1 element string decode as plain.
+// Rules are tried in order, so longer prefixes should come first. Only GS1
+// input that is marked as such (a symbology identifier, FNC1 separators or
+// bracketed AIs) and fails to decode returns an error.
+func Parse(code string, rules []Rule) (*Result, error) {
+	code = strings.TrimSpace(code)
+	gs1 := false
+	if len(code) > 3 && code[0] == ']' {
+		switch code[1:3] {
+		case "C1", "d2", "Q3", "e0", "J1":
+			gs1 = true
+		}
+		code = code[3:]
+	}
+	switch {
+	case isBracketedAI(code):
+		return parseGS1(code, true)
+	case gs1 || strings.ContainsRune(code, groupSeparator):
+		return parseGS1(code, false)
+	}
+
+	if len(code) == 13 && isDigits(code) && ValidCheckDigit(code) {
+		for _, r := range rules {
+			if strings.HasPrefix(code, r.Prefix) && r.length() == 13 {
+				return parseVariableMeasure(code, r), nil
+			}
+		}
+	}
+	if len(code) > 16 && isDigits(code) && (strings.HasPrefix(code, "01") || strings.HasPrefix(code, "02")) {
+		if res, err := parseGS1(code, false); err == nil && ValidCheckDigit(res.GTIN) {
+			return res, nil
+		}
+	}
+	return &Result{Raw: code, Kind: KindPlain, Lookup: gtinForms(code)}, nil
+}
+
+// parseVariableMeasure reads the item code and value of a barcode matching
+// rule r. The optional value check digit is not verified; the final check
+// digit already guards against misreads.
+func parseVariableMeasure(code string, r Rule) *Result {
+	itemStart := len(r.Prefix)
+	valueStart := itemStart + r.ItemDigits
+	if r.ValueCheckDigit {
+		valueStart++
+	}
+	item := code[itemStart : itemStart+r.ItemDigits]
+	digits, _ := strconv.ParseInt(code[valueStart:valueStart+r.ValueDigits], 10, 64)
+	value := new(big.Rat).SetFrac(big.NewInt(digits), pow10(r.ValueDecimals))
+
+	// Products carry the label barcode with a zero value, or just the
+	// prefix and item code.
+	zeroed := code[:itemStart+r.ItemDigits] + strings.Repeat("0", 12-itemStart-r.ItemDigits)
+	zeroed += string(CheckDigit(zeroed))
+	res := &Result{
+		Raw:      code,
+		Kind:     KindVariableMeasure,
+		Lookup:   []string{zeroed, r.Prefix + item, item},
+		ItemCode: item,
+	}
+	if r.ValueType == ValuePrice {
+		res.Price = value
+	} else {
+		res.Quantity = value
+	}
+	return res
+}
+
+// CheckDigit returns the GS1 mod-10 check digit of digits (EAN-8, EAN-13,
+// UPC-A, GTIN-14 and SSCC without their check digit).
+func CheckDigit(digits string) byte {
+	sum := 0
+	weight := 3
+	for i := len(digits) - 1; i >= 0; i-- {
+		sum += int(digits[i]-'0') * weight
+		weight = 4 - weight
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+// ValidCheckDigit reports whether the last digit of code is its GS1 check
+// digit.
+func ValidCheckDigit(code string) bool {
+	if len(code) < 2 || !isDigits(code) {
+		return false
+	}
+	return CheckDigit(code[:len(code)-1]) == code[len(code)-1]
+}
+
+// gtinForms returns a code with the shorter and longer GTIN forms it may
+// be stored under: a GTIN-14 also as EAN-13, UPC-A and EAN-8 when its
+// leading digits are zero, and a UPC-A also as EAN-13.
+func gtinForms(code string) []string {
+	out := []string{code}
+	if !isDigits(code) {
+		return out
+	}
+	switch len(code) {
+	case 14:
+		for _, n := range []int{13, 12, 8} {
+			if strings.Trim(code[:14-n], "0") == "" {
+				out = append(out, code[14-n:])
+			}
+		}
+	case 13:
+		if code[0] == '0' {
+			out = append(out, code[1:])
+		}
+		out = append(out, "0"+code)
+	case 12:
+		out = append(out, "0"+code, "00"+code)
+	}
+	return out
+}
+
+// isBracketedAI reports whether s starts with a bracketed AI such as (01).
+func isBracketedAI(s string) bool {
+	end := strings.IndexByte(s, ')')
+	return strings.HasPrefix(s, "(") && end >= 3 && end <= 5 && isDigits(s[1:end])
+}
+
+func isDigits(s string) bool {
+	if s == "" {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
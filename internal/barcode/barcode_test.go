@@ -0,0 +1,408 @@
+package barcode
+
+import (
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+	"time"
+)
+
+const gs = string(groupSeparator)
+
+var (
+	weightRule = Rule{Prefix: "21", ValueType: ValueWeight, ItemDigits: 5, ValueDigits: 5, ValueDecimals: 3}
+	priceRule  = Rule{Prefix: "22", ValueType: ValuePrice, ItemDigits: 4, ValueDigits: 5, ValueDecimals: 2, ValueCheckDigit: true}
+	countRule  = Rule{Prefix: "23", ValueType: ValueCount, ItemDigits: 5, ValueDigits: 5}
+	rules      = []Rule{weightRule, priceRule, countRule}
+)
+
+// decoded is the part of a Result a test checks, with amounts as decimal
+// strings and dates as YYYY-MM-DD; empty fields are not set.
+type decoded struct {
+	kind        Kind
+	lookup      []string
+	item, gtin  string
+	qty, price  string
+	batch       string
+	serial      string
+	expiry      string
+	bestBefore  string
+	productionD string
+}
+
+func decode(res *Result) decoded {
+	d := decoded{
+		kind:   res.Kind,
+		lookup: res.Lookup,
+		item:   res.ItemCode,
+		gtin:   res.GTIN,
+		batch:  res.Batch,
+		serial: res.Serial,
+	}
+	amount := func(r *big.Rat) string {
+		if r == nil {
+			return ""
+		}
+		return r.FloatString(3)
+	}
+	date := func(t *time.Time) string {
+		if t == nil {
+			return ""
+		}
+		return t.Format("2006-01-02")
+	}
+	d.qty, d.price = amount(res.Quantity), amount(res.Price)
+	d.expiry, d.bestBefore, d.productionD = date(res.Expiry), date(res.BestBefore), date(res.ProductionDate)
+	return d
+}
+
+func TestParse(t *testing.T) {
+	gtin := []string{"09501101530003", "9501101530003"}
+	tests := []struct {
+		name string
+		code string
+		want decoded
+	}{
+		{
+			name: "plain EAN-13 is also tried as a GTIN-14",
+			code: "4006381333931",
+			want: decoded{kind: KindPlain, lookup: []string{"4006381333931", "04006381333931"}},
+		},
+		{
+			name: "plain UPC-A is also tried as EAN-13 and GTIN-14",
+			code: "036000291452",
+			want: decoded{kind: KindPlain, lookup: []string{"036000291452", "0036000291452", "00036000291452"}},
+		},
+		{
+			name: "plain text",
+			code: "  ABC-123 ",
+			want: decoded{kind: KindPlain, lookup: []string{"ABC-123"}},
+		},
+		{
+			name: "weight rule",
+			code: "2112345012506",
+			want: decoded{
+				kind:   KindVariableMeasure,
+				lookup: []string{"2112345000008", "2112345", "12345"},
+				item:   "12345",
+				qty:    "1.250",
+			},
+		},
+		{
+			name: "price rule skips the value check digit",
+			code: "2212345019991",
+			want: decoded{
+				kind:   KindVariableMeasure,
+				lookup: []string{"2212340000000", "221234", "1234"},
+				item:   "1234",
+				price:  "19.990",
+			},
+		},
+		{
+			name: "count rule",
+			code: "2300042000074",
+			want: decoded{
+				kind:   KindVariableMeasure,
+				lookup: []string{"2300042000005", "2300042", "00042"},
+				item:   "00042",
+				qty:    "7.000",
+			},
+		},
+		{
+			name: "in-store code with a wrong check digit is plain",
+			code: "2112345012507",
+			want: decoded{kind: KindPlain, lookup: []string{"2112345012507", "02112345012507"}},
+		},
+		{
+			name: "in-store code no rule matches is plain",
+			code: "2912345012502",
+			want: decoded{kind: KindPlain, lookup: []string{"2912345012502", "02912345012502"}},
+		},
+		{
+			name: "FNC1-separated elements",
+			code: "0109501101530003" + "10AB12" + gs + "17261231" + "21SER9",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", batch: "AB12", serial: "SER9", expiry: "2026-12-31"},
+		},
+		{
+			name: "leading FNC1 and separator after a fixed-length element",
+			code: gs + "0109501101530003" + gs + "10AB12",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", batch: "AB12"},
+		},
+		{
+			name: "unmarked digits starting with a GTIN",
+			code: "0109501101530003" + "17260531",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", expiry: "2026-05-31"},
+		},
+		{
+			name: "bracketed AIs",
+			code: "(01)09501101530003(10)LOT 7(11)250101(15)260615",
+			want: decoded{
+				kind:        KindGS1,
+				lookup:      gtin,
+				gtin:        "09501101530003",
+				batch:       "LOT 7",
+				bestBefore:  "2026-06-15",
+				productionD: "2025-01-01",
+			},
+		},
+		{
+			name: "AI 17 with day 00 is the last day of the month",
+			code: "(01)09501101530003(17)260200",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", expiry: "2026-02-28"},
+		},
+		{
+			name: "AI 17 with day 00 in a leap year",
+			code: "(01)09501101530003(17)240200",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", expiry: "2024-02-29"},
+		},
+		{
+			name: "AI 17 with day 00 in December",
+			code: "(01)09501101530003(17)261200",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", expiry: "2026-12-31"},
+		},
+		{
+			name: "net weight with three decimals",
+			code: "0109501101530003" + "3103001250",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", qty: "1.250"},
+		},
+		{
+			name: "net weight wins over a count",
+			code: "(01)09501101530003(30)4(3102)000150",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", qty: "1.500"},
+		},
+		{
+			name: "count",
+			code: "(02)09501101530003(37)12",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", qty: "12.000"},
+		},
+		{
+			name: "amount payable",
+			code: "(01)09501101530003(3922)1999",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", price: "19.990"},
+		},
+		{
+			name: "amount payable with an ISO currency",
+			code: "(01)09501101530003(3932)6821999",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", price: "19.990"},
+		},
+		{
+			name: "GS1-128 symbology identifier",
+			code: "]C10109501101530003",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003"},
+		},
+		{
+			name: "GS1 DataMatrix symbology identifier",
+			code: "]d20109501101530003" + "10X1",
+			want: decoded{kind: KindGS1, lookup: gtin, gtin: "09501101530003", batch: "X1"},
+		},
+		{
+			name: "GS1 QR Code symbology identifier",
+			code: "]Q321ABC",
+			want: decoded{kind: KindGS1, serial: "ABC"},
+		},
+		{
+			name: "EAN symbology identifier is dropped",
+			code: "]E04006381333931",
+			want: decoded{kind: KindPlain, lookup: []string{"4006381333931", "04006381333931"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Parse(tt.code, rules)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			if got := decode(res); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) =\n  %+v\nwant\n  %+v", tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"GTIN with a wrong check digit", "(01)09501101530004"},
+		{"GTIN too short", "(01)0950110153000"},
+		{"invalid month", "(01)09501101530003(17)261300"},
+		{"invalid day", "(01)09501101530003(17)260230"},
+		{"empty bracketed element", "(01)09501101530003(10)"},
+		{"bad bracketed AI", "(01)09501101530003(0A)X"},
+		{"truncated fixed-length element", "]C1010950110153"},
+		{"not an AI after a separator", "10AB" + gs + "XY"},
+		{"non-digit weight", "(01)09501101530003(3103)00A250"},
+		{"non-digit count", "(01)09501101530003(30)1.5"},
+		{"amount with only a currency", "(01)09501101530003(3932)682"},
+		{"marked GS1 without elements", "]d2" + gs},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.code, rules)
+			if !errors.Is(err, ErrInvalidGS1) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.code, err, ErrInvalidGS1)
+			}
+		})
+	}
+}
+
+func TestRuleValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		rule    Rule
+		wantErr bool
+	}{
+		{"weight rule", weightRule, false},
+		{"price rule with a value check digit", priceRule, false},
+		{"three-digit prefix", Rule{Prefix: "201", ValueType: ValueCount, ItemDigits: 4, ValueDigits: 5}, false},
+		{"prefix outside 20-29", Rule{Prefix: "31", ValueType: ValueWeight, ItemDigits: 5, ValueDigits: 5}, true},
+		{"one-digit prefix", Rule{Prefix: "2", ValueType: ValueWeight, ItemDigits: 6, ValueDigits: 5}, true},
+		{"unknown value type", Rule{Prefix: "21", ValueType: "volume", ItemDigits: 5, ValueDigits: 5}, true},
+		{"no value digits", Rule{Prefix: "21", ValueType: ValueWeight, ItemDigits: 10}, true},
+		{"more decimals than digits", Rule{Prefix: "21", ValueType: ValueWeight, ItemDigits: 5, ValueDigits: 5, ValueDecimals: 6}, true},
+		{"not 13 digits", Rule{Prefix: "21", ValueType: ValueWeight, ItemDigits: 5, ValueDigits: 4}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.rule.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Validate() = %v, want error %t", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidRule) {
+				t.Errorf("Validate() = %v, want %v", err, ErrInvalidRule)
+			}
+		})
+	}
+}
+
+func TestCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   byte
+	}{
+		{"400638133393", '1'},      // EAN-13
+		{"03600029145", '2'},       // UPC-A
+		{"9638507", '4'},           // EAN-8
+		{"0950110153000", '3'},     // GTIN-14
+		{"00000000000000000", '0'}, // SSCC
+		{"211234501250", '6'},
+		{"230004200007", '4'},
+		{"221234000000", '0'},
+	}
+
+	for _, tt := range tests {
+		if got := CheckDigit(tt.digits); got != tt.want {
+			t.Errorf("CheckDigit(%q) = %c, want %c", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestValidCheckDigit(t *testing.T) {
+	tests := []struct {
+		code string
+		want bool
+	}{
+		{"4006381333931", true},
+		{"4006381333932", false},
+		{"96385074", true},
+		{"09501101530003", true},
+		{"7", false},
+		{"", false},
+		{"40063813339A1", false},
+	}
+
+	for _, tt := range tests {
+		if got := ValidCheckDigit(tt.code); got != tt.want {
+			t.Errorf("ValidCheckDigit(%q) = %t, want %t", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestGTINForms(t *testing.T) {
+	tests := []struct {
+		code string
+		want []string
+	}{
+		{"00000096385074", []string{"00000096385074", "0000096385074", "000096385074", "96385074"}},
+		{"00036000291452", []string{"00036000291452", "0036000291452", "036000291452"}},
+		{"09501101530003", []string{"09501101530003", "9501101530003"}},
+		{"19501101530000", []string{"19501101530000"}},
+		{"0036000291452", []string{"0036000291452", "036000291452", "00036000291452"}},
+		{"4006381333931", []string{"4006381333931", "04006381333931"}},
+		{"036000291452", []string{"036000291452", "0036000291452", "00036000291452"}},
+		{"96385074", []string{"96385074"}},
+		{"SKU-1", []string{"SKU-1"}},
+	}
+
+	for _, tt := range tests {
+		if got := gtinForms(tt.code); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("gtinForms(%q) = %v, want %v", tt.code, got, tt.want)
+		}
+	}
+}
+
+func TestSymbologyFor(t *testing.T) {
+	tests := []struct {
+		code     string
+		want     Symbology
+		wantCode string
+		ok       bool
+	}{
+		{"4006381333931", EAN13, "4006381333931", true},
+		{"036000291452", EAN13, "0036000291452", true},
+		{"4006381333932", Code128, "4006381333932", true},
+		{"SKU-1", Code128, "SKU-1", true},
+		{"", "", "", false},
+		{"AB" + gs + "1", "", "", false},
+	}
+
+	for _, tt := range tests {
+		got, code, ok := SymbologyFor(tt.code)
+		if got != tt.want || code != tt.wantCode || ok != tt.ok {
+			t.Errorf("SymbologyFor(%q) = %q, %q, %t; want %q, %q, %t", tt.code, got, code, ok, tt.want, tt.wantCode, tt.ok)
+		}
+	}
+}
+
+func TestModules(t *testing.T) {
+	tests := []struct {
+		name    string
+		s       Symbology
+		code    string
+		modules int
+	}{
+		// guards 3+5+3 and 12 digits of 7 modules
+		{"EAN-13", EAN13, "4006381333931", 95},
+		// start, 2 digit pairs, check and the 13-module stop
+		{"Code 128 set C", Code128, "1234", 4*11 + 13},
+		// start, 3 characters, check and stop
+		{"Code 128 set B", Code128, "A-1", 5*11 + 13},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Modules(tt.s, tt.code)
+			if err != nil {
+				t.Fatalf("Modules: %v", err)
+			}
+			if len(m) != tt.modules {
+				t.Errorf("got %d modules, want %d", len(m), tt.modules)
+			}
+			if !m[0] || !m[len(m)-1] {
+				t.Errorf("modules must start and end with a bar")
+			}
+		})
+	}
+
+	if _, err := Modules(EAN13, "4006381333932"); err == nil {
+		t.Error("Modules accepted an EAN-13 with a wrong check digit")
+	}
+	if _, err := Modules(Code128, "café"); err == nil {
+		t.Error("Modules accepted non-ASCII Code 128 data")
+	}
+}
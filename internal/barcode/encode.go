@@ -0,0 +1,170 @@
+package barcode
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Symbology is a linear barcode symbology the package can draw.
+type Symbology string
+
+const (
+	EAN13   Symbology = "ean13"
+	Code128 Symbology = "code128"
+)
+
+// QuietZone is the number of blank modules to leave on each side of a
+// drawn barcode.
+const QuietZone = 10
+
+// SymbologyFor picks how to draw code: EAN-13 for a 13-digit code with a
+// valid check digit (and a UPC-A, as EAN-13 with a leading zero), Code 128
+// for anything else in printable ASCII. It reports false when code cannot
+// be drawn.
+func SymbologyFor(code string) (Symbology, string, bool) {
+	if len(code) == 12 && ValidCheckDigit(code) {
+		code = "0" + code
+	}
+	if len(code) == 13 && ValidCheckDigit(code) {
+		return EAN13, code, true
+	}
+	if code == "" {
+		return "", "", false
+	}
+	for i := 0; i < len(code); i++ {
+		if code[i] < 32 || code[i] > 126 {
+			return "", "", false
+		}
+	}
+	return Code128, code, true
+}
+
+// Modules encodes code in the symbology as a run of modules, true for a
+// bar, without quiet zones.
+func Modules(s Symbology, code string) ([]bool, error) {
+	switch s {
+	case EAN13:
+		return ean13Modules(code)
+	case Code128:
+		return code128Modules(code)
+	}
+	return nil, fmt.Errorf("unsupported symbology %q", s)
+}
+
+var (
+	eanL = []string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	eanG = []string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	eanR = []string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+	// eanParity gives, by the first digit, which left-hand digits use the
+	// G (even) set.
+	eanParity = []string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+)
+
+func ean13Modules(code string) ([]bool, error) {
+	if len(code) != 13 || !ValidCheckDigit(code) {
+		return nil, fmt.Errorf("%q is not an EAN-13 with a valid check digit", code)
+	}
+	var b strings.Builder
+	b.WriteString("101")
+	parity := eanParity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		d := code[i] - '0'
+		if parity[i-1] == 'G' {
+			b.WriteString(eanG[d])
+		} else {
+			b.WriteString(eanL[d])
+		}
+	}
+	b.WriteString("01010")
+	for i := 7; i <= 12; i++ {
+		b.WriteString(eanR[code[i]-'0'])
+	}
+	b.WriteString("101")
+	return bits(b.String()), nil
+}
+
+// code128Patterns are the bar and space widths of the Code 128 symbols by
+// value; 103-105 are the start codes A, B and C.
+var code128Patterns = []string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232",
+}
+
+const (
+	code128StartB = 104
+	code128StartC = 105
+	code128Stop   = "2331112"
+)
+
+// code128Modules encodes printable ASCII in code set B, or an even number
+// of digits in the denser code set C.
+func code128Modules(code string) ([]bool, error) {
+	if code == "" {
+		return nil, fmt.Errorf("empty Code 128 data")
+	}
+	var values []int
+	if len(code)%2 == 0 && isDigits(code) {
+		values = append(values, code128StartC)
+		for i := 0; i < len(code); i += 2 {
+			values = append(values, int(code[i]-'0')*10+int(code[i+1]-'0'))
+		}
+	} else {
+		values = append(values, code128StartB)
+		for i := 0; i < len(code); i++ {
+			if code[i] < 32 || code[i] > 126 {
+				return nil, fmt.Errorf("Code 128 cannot encode %q", code[i])
+			}
+			values = append(values, int(code[i])-32)
+		}
+	}
+	sum := values[0]
+	for i, v := range values[1:] {
+		sum += v * (i + 1)
+	}
+	values = append(values, sum%103)
+
+	var out []bool
+	for _, v := range values {
+		out = appendWidths(out, code128Patterns[v])
+	}
+	return appendWidths(out, code128Stop), nil
+}
+
+// appendWidths appends alternating bars and spaces, starting with a bar.
+func appendWidths(out []bool, widths string) []bool {
+	for i := 0; i < len(widths); i++ {
+		for n := 0; n < int(widths[i]-'0'); n++ {
+			out = append(out, i%2 == 0)
+		}
+	}
+	return out
+}
+
+func bits(s string) []bool {
+	out := make([]bool, len(s))
+	for i := range s {
+		out[i] = s[i] == '1'
+	}
+	return out
+}
+
+// NewEAN13 builds an EAN-13 from a prefix and a number, zero-padding the
+// number to fill 12 digits and appending the check digit. It reports false
+// when the number does not fit.
+func NewEAN13(prefix string, n int64) (string, bool) {
+	digits := fmt.Sprintf("%d", n)
+	if !isDigits(prefix) || len(prefix)+len(digits) > 12 {
+		return "", false
+	}
+	body := prefix + strings.Repeat("0", 12-len(prefix)-len(digits)) + digits
+	return body + string(CheckDigit(body)), true
+}
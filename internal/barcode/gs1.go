@@ -0,0 +1,222 @@
+package barcode
+
+import (
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// groupSeparator (ASCII GS) is how scanners transmit FNC1, which ends a
+// variable-length GS1 element.
+const groupSeparator = '\x1d'
+
+// aiLength returns the length of the AI starting with the two digits p.
+func aiLength(p string) int {
+	switch p {
+	case "23", "24", "25", "40", "41", "42", "43":
+		return 3
+	case "31", "32", "33", "34", "35", "36", "39", "70", "71", "72", "80", "81", "82":
+		return 4
+	}
+	return 2
+}
+
+// fixedDataLength returns the data length of the predefined fixed-length
+// AIs starting with the two digits p, or 0 for variable-length AIs.
+func fixedDataLength(p string) int {
+	switch p {
+	case "00":
+		return 18
+	case "01", "02", "03":
+		return 14
+	case "04":
+		return 16
+	case "11", "12", "13", "14", "15", "16", "17", "18", "19":
+		return 6
+	case "20":
+		return 2
+	case "31", "32", "33", "34", "35", "36":
+		return 6
+	case "41":
+		return 13
+	}
+	return 0
+}
+
+// parseGS1 reads a GS1 element string, either raw with FNC1 separators or
+// human-readable with bracketed AIs such as (01)09501101530003(10)AB12.
+func parseGS1(code string, bracketed bool) (*Result, error) {
+	elements := map[string]string{}
+	var err error
+	if bracketed {
+		err = readBracketed(code, elements)
+	} else {
+		err = readRaw(strings.TrimLeft(code, string(groupSeparator)), elements)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Result{Raw: code, Kind: KindGS1, Elements: elements}
+	for ai, v := range elements {
+		switch {
+		case ai == "01" || ai == "02":
+			if len(v) != 14 || !isDigits(v) {
+				return nil, fmt.Errorf("%w: GTIN (%s) must be 14 digits", ErrInvalidGS1, ai)
+			}
+			if !ValidCheckDigit(v) {
+				return nil, fmt.Errorf("%w: GTIN %s has a wrong check digit", ErrInvalidGS1, v)
+			}
+			if ai == "01" || res.GTIN == "" {
+				res.GTIN = v
+			}
+		case ai == "10":
+			res.Batch = v
+		case ai == "21":
+			res.Serial = v
+		case ai == "11" || ai == "15" || ai == "17":
+			d, err := gs1Date(v)
+			if err != nil {
+				return nil, fmt.Errorf("%w: (%s) %v", ErrInvalidGS1, ai, err)
+			}
+			switch ai {
+			case "11":
+				res.ProductionDate = &d
+			case "15":
+				res.BestBefore = &d
+			default:
+				res.Expiry = &d
+			}
+		case ai == "30" || ai == "37":
+			n, ok := new(big.Rat).SetString(v)
+			if !ok || !isDigits(v) {
+				return nil, fmt.Errorf("%w: count (%s) must be digits", ErrInvalidGS1, ai)
+			}
+			if res.Quantity == nil {
+				res.Quantity = n
+			}
+		case strings.HasPrefix(ai, "310"):
+			// Net weight in kg with the AI's last digit as decimals; it
+			// wins over a count.
+			w, err := decimalElement(ai, v)
+			if err != nil {
+				return nil, err
+			}
+			res.Quantity = w
+		case strings.HasPrefix(ai, "392"), strings.HasPrefix(ai, "393"):
+			// Amount payable; 393n starts with an ISO 4217 currency code.
+			if ai[2] == '3' {
+				if len(v) < 4 {
+					return nil, fmt.Errorf("%w: (%s) too short", ErrInvalidGS1, ai)
+				}
+				v = v[3:]
+			}
+			p, err := decimalElement(ai, v)
+			if err != nil {
+				return nil, err
+			}
+			res.Price = p
+		}
+	}
+	if res.GTIN != "" {
+		res.Lookup = gtinForms(res.GTIN)
+	}
+	return res, nil
+}
+
+func readRaw(s string, elements map[string]string) error {
+	for s != "" {
+		if len(s) < 2 || !isDigits(s[:2]) {
+			return fmt.Errorf("%w: expected an AI at %q", ErrInvalidGS1, s)
+		}
+		n := aiLength(s[:2])
+		if len(s) < n || !isDigits(s[:n]) {
+			return fmt.Errorf("%w: truncated AI at %q", ErrInvalidGS1, s)
+		}
+		ai, rest := s[:n], s[n:]
+		var data string
+		if size := fixedDataLength(ai[:2]); size > 0 {
+			if len(rest) < size {
+				return fmt.Errorf("%w: (%s) needs %d characters", ErrInvalidGS1, ai, size)
+			}
+			data, rest = rest[:size], rest[size:]
+			// A separator after a fixed-length element is tolerated.
+			rest = strings.TrimPrefix(rest, string(groupSeparator))
+		} else if i := strings.IndexRune(rest, groupSeparator); i >= 0 {
+			data, rest = rest[:i], rest[i+1:]
+		} else {
+			data, rest = rest, ""
+		}
+		if data == "" {
+			return fmt.Errorf("%w: (%s) is empty", ErrInvalidGS1, ai)
+		}
+		elements[ai] = data
+		s = rest
+	}
+	if len(elements) == 0 {
+		return fmt.Errorf("%w: no elements", ErrInvalidGS1)
+	}
+	return nil
+}
+
+func readBracketed(s string, elements map[string]string) error {
+	for s != "" {
+		end := strings.IndexByte(s, ')')
+		if s[0] != '(' || end < 0 {
+			return fmt.Errorf("%w: expected (AI) at %q", ErrInvalidGS1, s)
+		}
+		ai := s[1:end]
+		if len(ai) < 2 || len(ai) > 4 || !isDigits(ai) {
+			return fmt.Errorf("%w: bad AI (%s)", ErrInvalidGS1, ai)
+		}
+		s = s[end+1:]
+		next := strings.IndexByte(s, '(')
+		if next < 0 {
+			next = len(s)
+		}
+		data := strings.TrimSpace(s[:next])
+		if data == "" {
+			return fmt.Errorf("%w: (%s) is empty", ErrInvalidGS1, ai)
+		}
+		elements[ai] = data
+		s = s[next:]
+	}
+	if len(elements) == 0 {
+		return fmt.Errorf("%w: no elements", ErrInvalidGS1)
+	}
+	return nil
+}
+
+// decimalElement reads an element whose last AI digit is the number of
+// decimals.
+func decimalElement(ai, v string) (*big.Rat, error) {
+	if len(ai) != 4 || !isDigits(v) {
+		return nil, fmt.Errorf("%w: (%s) must be digits", ErrInvalidGS1, ai)
+	}
+	n, _ := new(big.Int).SetString(v, 10)
+	return new(big.Rat).SetFrac(n, pow10(int(ai[3]-'0'))), nil
+}
+
+// gs1Date reads a YYMMDD date. Day 00 means the last day of the month.
+// Years are taken as 20YY.
+func gs1Date(v string) (time.Time, error) {
+	if len(v) != 6 || !isDigits(v) {
+		return time.Time{}, fmt.Errorf("date %q must be YYMMDD", v)
+	}
+	yy, _ := strconv.Atoi(v[:2])
+	mm, _ := strconv.Atoi(v[2:4])
+	dd, _ := strconv.Atoi(v[4:])
+	if mm < 1 || mm > 12 || dd > 31 {
+		return time.Time{}, fmt.Errorf("date %q is not a valid date", v)
+	}
+	if dd == 0 {
+		return time.Date(2000+yy, time.Month(mm)+1, 0, 0, 0, 0, 0, time.UTC), nil
+	}
+	d := time.Date(2000+yy, time.Month(mm), dd, 0, 0, 0, 0, time.UTC)
+	if d.Day() != dd {
+		return time.Time{}, fmt.Errorf("date %q is not a valid date", v)
+	}
+	return d, nil
+}
@@ -0,0 +1,71 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Columns is the column layout of catalog import and export files. One
+// row describes a product or one of its variants; rows of the same SKU
+// share the product columns.
+var Columns = []string{
+	"sku", "name", "description", "product_type",
+	"category_code", "brand_code", "uom_code", "tax_category_code",
+	"is_serialized", "is_batch_managed", "is_active", "is_sellable",
+	"is_purchasable", "allow_decimal_quantity", "track_inventory",
+	"variant_sku", "variant_name", "variant_attributes",
+	"barcode", "barcode_type",
+	"price_list_code", "price",
+	"store_code", "opening_quantity",
+}
+
+// ProductColumns are the columns describing the product itself.
+var ProductColumns = Columns[1:15]
+
+// FormatAttributes writes variant attributes as "color=Red; size=L",
+// sorted by name.
+func FormatAttributes(attrs map[string]string) string {
+	names := make([]string, 0, len(attrs))
+	for name := range attrs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+"="+attrs[name])
+	}
+	return strings.Join(parts, "; ")
+}
+
+// ParseAttributes reads variant attributes written by FormatAttributes.
+func ParseAttributes(s string) (map[string]string, error) {
+	attrs := map[string]string{}
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if !ok || name == "" || value == "" {
+			return nil, fmt.Errorf("%w: %q is not name=value", ErrInvalidMatrix, part)
+		}
+		if _, dup := attrs[name]; dup {
+			return nil, fmt.Errorf("%w: attribute %s is repeated", ErrInvalidMatrix, name)
+		}
+		attrs[name] = value
+	}
+	return attrs, nil
+}
+
+// ParseBool reads a yes/no cell: true, false, yes, no, 1 or 0 in any case.
+func ParseBool(s string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "true", "yes", "y", "1":
+		return true, nil
+	case "false", "no", "n", "0":
+		return false, nil
+	}
+	return false, fmt.Errorf("invalid yes/no value %q", s)
+}
@@ -0,0 +1,143 @@
+// Package catalog holds product catalog rules that do not depend on the
+// database: expanding attribute matrices into variants and the column
+// layout of catalog import and export files.
+package catalog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+// MaxVariants caps the variants one attribute matrix may generate.
+const MaxVariants = 500
+
+// ErrInvalidMatrix is returned for an attribute matrix that cannot be
+// expanded into variants.
+var ErrInvalidMatrix = errors.New("invalid attribute matrix")
+
+// Attribute is one axis of a variant matrix, such as size or color.
+type Attribute struct {
+	Name   string
+	Values []string
+}
+
+// Variant is one combination of attribute values.
+type Variant struct {
+	SKU  string
+	Name string
+	// Attributes maps attribute name to value; stored as the variant's
+	// variant_attributes.
+	Attributes map[string]string
+}
+
+// ExpandVariants returns every combination of the attribute values (size ×
+// color × ...), in attribute and value order. Variant SKUs are the base SKU
+// followed by a code of each value (L, RED), and names the base name
+// followed by the values ("Shirt - L / Red").
+func ExpandVariants(baseSKU, baseName string, attrs []Attribute) ([]Variant, error) {
+	if len(attrs) == 0 {
+		return nil, fmt.Errorf("%w: no attributes", ErrInvalidMatrix)
+	}
+	clean := make([]Attribute, 0, len(attrs))
+	names := map[string]bool{}
+	total := 1
+	for _, a := range attrs {
+		name := strings.TrimSpace(a.Name)
+		if name == "" {
+			return nil, fmt.Errorf("%w: attribute without name", ErrInvalidMatrix)
+		}
+		if names[strings.ToLower(name)] {
+			return nil, fmt.Errorf("%w: attribute %s is repeated", ErrInvalidMatrix, name)
+		}
+		names[strings.ToLower(name)] = true
+
+		var values []string
+		seen := map[string]bool{}
+		for _, v := range a.Values {
+			v = strings.TrimSpace(v)
+			if v == "" || seen[strings.ToLower(v)] {
+				continue
+			}
+			if SKUCode(v) == "" {
+				return nil, fmt.Errorf("%w: value %q of %s has no letters or digits", ErrInvalidMatrix, v, name)
+			}
+			seen[strings.ToLower(v)] = true
+			values = append(values, v)
+		}
+		if len(values) == 0 {
+			return nil, fmt.Errorf("%w: attribute %s has no values", ErrInvalidMatrix, name)
+		}
+		total *= len(values)
+		if total > MaxVariants {
+			return nil, fmt.Errorf("%w: more than %d variants", ErrInvalidMatrix, MaxVariants)
+		}
+		clean = append(clean, Attribute{Name: name, Values: values})
+	}
+
+	out := make([]Variant, 0, total)
+	skus := map[string]bool{}
+	idx := make([]int, len(clean))
+	for {
+		v := Variant{Attributes: make(map[string]string, len(clean))}
+		codes := []string{baseSKU}
+		labels := make([]string, 0, len(clean))
+		for i, a := range clean {
+			value := a.Values[idx[i]]
+			v.Attributes[a.Name] = value
+			codes = append(codes, SKUCode(value))
+			labels = append(labels, value)
+		}
+		v.SKU = strings.Join(codes, "-")
+		v.Name = baseName + " - " + strings.Join(labels, " / ")
+		if skus[v.SKU] {
+			return nil, fmt.Errorf("%w: values give the same SKU %s", ErrInvalidMatrix, v.SKU)
+		}
+		skus[v.SKU] = true
+		out = append(out, v)
+
+		// advance the last axis first, like an odometer
+		i := len(idx) - 1
+		for ; i >= 0; i-- {
+			idx[i]++
+			if idx[i] < len(clean[i].Values) {
+				break
+			}
+			idx[i] = 0
+		}
+		if i < 0 {
+			return out, nil
+		}
+	}
+}
+
+// SKUCode turns an attribute value into its SKU code: letters and digits
+// only, upper case ("X Large" becomes XLARGE).
+func SKUCode(value string) string {
+	var b strings.Builder
+	for _, r := range value {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(unicode.ToUpper(r))
+		}
+	}
+	return b.String()
+}
+
+// SameAttributes reports whether two variants have the same attribute
+// values, ignoring the case of names and values.
+func SameAttributes(a, b map[string]string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	lower := make(map[string]string, len(b))
+	for k, v := range b {
+		lower[strings.ToLower(k)] = strings.ToLower(v)
+	}
+	for k, v := range a {
+		if w, ok := lower[strings.ToLower(k)]; !ok || w != strings.ToLower(v) {
+			return false
+		}
+	}
+	return true
+}
@@ -3,6 +3,9 @@ package config
 import (
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,6 +19,41 @@ type Config struct {
 	DevUserID    string
 	DevUserLogin string
 	LogLevel     string
+	// TrustedProxies are the addresses and CIDR ranges of the proxies whose
+	// X-Forwarded-For header gives the client's address, e.g. for the login
+	// lockout. When empty, the address of the connection is used.
+	TrustedProxies []string
+	// ZatcaSigningKeyPath is a PEM EC private key used to sign ZATCA
+	// invoices. When empty, invoices are generated unsigned.
+	ZatcaSigningKeyPath string
+	// ExpiryAlertInterval is how often the expiry alert job runs for every
+	// tenant. Zero disables the job.
+	ExpiryAlertInterval time.Duration
+	// CatalogImportInterval is how often queued catalog imports left over
+	// from a restart are picked up. Zero disables the job.
+	CatalogImportInterval time.Duration
+
+	// Password policy: passwords need PasswordMinLength characters and the
+	// required kinds of characters, can't repeat the last PasswordHistory
+	// passwords and expire after PasswordMaxAge. Zero disables history and
+	// expiry.
+	PasswordMinLength     int
+	PasswordRequireUpper  bool
+	PasswordRequireLower  bool
+	PasswordRequireDigit  bool
+	PasswordRequireSymbol bool
+	PasswordHistory       int
+	PasswordMaxAge        time.Duration
+
+	// Login lockout: after LoginMaxFailures failed attempts at a login name,
+	// or LoginMaxFailuresPerIP from an IP address, within LoginFailureWindow,
+	// the login name or address is locked out for LoginLockout, doubling
+	// with every further failure up to LoginMaxLockout.
+	LoginMaxFailures      int
+	LoginMaxFailuresPerIP int
+	LoginFailureWindow    time.Duration
+	LoginLockout          time.Duration
+	LoginMaxLockout       time.Duration
 }
 
 // LoadConfig loads configuration from environment file based on environment
@@ -54,6 +92,26 @@ func LoadConfig(env string) *Config {
 		DevUserID:    getEnv("DEV_USER_ID", "00000000-0000-0000-0000-000000000000"),
 		DevUserLogin: getEnv("DEV_USER_LOGIN", "dev_user"),
 		LogLevel:     getEnv("LOG_LEVEL", "info"),
+
+		TrustedProxies: getList("TRUSTED_PROXIES"),
+
+		ZatcaSigningKeyPath:   getEnv("ZATCA_SIGNING_KEY_PATH", ""),
+		ExpiryAlertInterval:   getDuration("EXPIRY_ALERT_INTERVAL", 24*time.Hour),
+		CatalogImportInterval: getDuration("CATALOG_IMPORT_INTERVAL", time.Minute),
+
+		PasswordMinLength:     getInt("PASSWORD_MIN_LENGTH", 8),
+		PasswordRequireUpper:  getBool("PASSWORD_REQUIRE_UPPER", true),
+		PasswordRequireLower:  getBool("PASSWORD_REQUIRE_LOWER", true),
+		PasswordRequireDigit:  getBool("PASSWORD_REQUIRE_DIGIT", true),
+		PasswordRequireSymbol: getBool("PASSWORD_REQUIRE_SYMBOL", false),
+		PasswordHistory:       getInt("PASSWORD_HISTORY", 5),
+		PasswordMaxAge:        getDuration("PASSWORD_MAX_AGE", 0),
+
+		LoginMaxFailures:      getInt("LOGIN_MAX_FAILURES", 5),
+		LoginMaxFailuresPerIP: getInt("LOGIN_MAX_FAILURES_PER_IP", 20),
+		LoginFailureWindow:    getDuration("LOGIN_FAILURE_WINDOW", 24*time.Hour),
+		LoginLockout:          getDuration("LOGIN_LOCKOUT", time.Minute),
+		LoginMaxLockout:       getDuration("LOGIN_MAX_LOCKOUT", time.Hour),
 	}
 }
 
@@ -64,3 +122,60 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getList gets a comma-separated list environment variable, nil when it is
+// unset
+func getList(key string) []string {
+	var list []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// getDuration gets a duration environment variable such as "6h" or returns
+// a default value when it is unset or invalid
+func getDuration(key string, defaultValue time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Note: invalid %s %q, using %s", key, value, defaultValue)
+		return defaultValue
+	}
+	return d
+}
+
+// getInt gets an integer environment variable or returns a default value
+// when it is unset or invalid
+func getInt(key string, defaultValue int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Note: invalid %s %q, using %d", key, value, defaultValue)
+		return defaultValue
+	}
+	return n
+}
+
+// getBool gets a boolean environment variable such as "true" or "0" or
+// returns a default value when it is unset or invalid
+func getBool(key string, defaultValue bool) bool {
+	value := os.Getenv(key)
+	if value == "" {
+		return defaultValue
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Printf("Note: invalid %s %q, using %t", key, value, defaultValue)
+		return defaultValue
+	}
+	return b
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 
 	"NEMBUS/internal/middleware"
 	"NEMBUS/internal/repository"
@@ -36,7 +37,7 @@ func (h *AuthHandler) getRepositoryFromContext(c *gin.Context) *repository.Queri
 
 // Login handles POST /login
 // @Summary      User login
-// @Description  Authenticate user and receive JWT token
+// @Description  Authenticate user and receive JWT token with the default store to work in: the primary store, else the first active store the user has access to. Every attempt is audited with the client's IP address and user agent. Failed attempts all get 401 and lock the login name and the address out for a while after repeated failures (429 with Retry-After). An expired password gets 403; change it through /api/auth/change-password.
 // @Tags         auth
 // @Accept       json
 // @Produce      json
@@ -45,6 +46,8 @@ func (h *AuthHandler) getRepositoryFromContext(c *gin.Context) *repository.Queri
 // @Success      200  {object}  LoginResponse
 // @Failure      400  {object}  ErrorResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      429  {object}  ErrorResponse
 // @Router       /api/auth/login [post]
 func (h *AuthHandler) Login(c *gin.Context) {
 	// Get repository from context and set it on use case
@@ -66,12 +69,68 @@ func (h *AuthHandler) Login(c *gin.Context) {
 	}
 
 	// Call UseCase
-	response := h.useCase.Login(c.Request.Context(), req.UserLogin, req.Password)
+	response := h.useCase.Login(c.Request.Context(), req.UserLogin, req.Password, loginClient(c))
+	writeLoginResponse(c, response)
+}
+
+// ChangePasswordAndLogin handles POST /change-password
+// @Summary      Change password and sign in
+// @Description  Changes a user's password after checking the current one and signs them in, for users whose password has expired. The new password must satisfy the password policy. Attempts are audited and locked out like logins.
+// @Tags         auth
+// @Accept       json
+// @Produce      json
+// @Param        x-tenant-id  header    string                        true  "Tenant identifier"
+// @Param        request      body      ChangeExpiredPasswordRequest  true  "Login name, current and new password"
+// @Success      200  {object}  LoginResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      429  {object}  ErrorResponse
+// @Router       /api/auth/change-password [post]
+func (h *AuthHandler) ChangePasswordAndLogin(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req ChangeExpiredPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+
+	response := h.useCase.ChangePasswordAndLogin(c.Request.Context(), req.UserLogin, req.CurrentPassword, req.NewPassword, loginClient(c))
+	writeLoginResponse(c, response)
+}
+
+// writeLoginResponse responds to a login with the token as data, as it
+// always has, and the default store beside it
+func writeLoginResponse(c *gin.Context, response *repository.Response) {
 	if response.StatusCode != utils.CodeOK {
+		setRetryAfter(c, response)
 		c.JSON(response.StatusCode, response)
 		return
 	}
 
-	// Respond with token
-	c.JSON(response.StatusCode, response)
+	result, _ := response.Data.(usecase.LoginResult)
+	c.JSON(response.StatusCode, gin.H{
+		"statusCode":    response.StatusCode,
+		"message":       response.Message,
+		"data":          result.Token,
+		"default_store": result.DefaultStore,
+	})
+}
+
+// loginClient returns the address and user agent of a login attempt. The
+// address comes from X-Forwarded-For only behind the trusted proxies, see
+// config.Config.TrustedProxies.
+func loginClient(c *gin.Context) usecase.LoginClient {
+	return usecase.LoginClient{IP: c.ClientIP(), UserAgent: c.Request.UserAgent()}
+}
+
+// setRetryAfter sets the Retry-After header of locked out responses
+func setRetryAfter(c *gin.Context, response *repository.Response) {
+	if lockout, ok := response.Data.(usecase.LoginLockout); ok {
+		c.Header("Retry-After", strconv.Itoa(lockout.RetryAfter))
+	}
 }
@@ -0,0 +1,661 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"NEMBUS/internal/catalog"
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogHandler holds the catalog use case.
+type CatalogHandler struct {
+	useCase *usecase.CatalogUseCase
+}
+
+// NewCatalogHandler creates a new catalog handler.
+func NewCatalogHandler(uc *usecase.CatalogUseCase) *CatalogHandler {
+	return &CatalogHandler{useCase: uc}
+}
+
+func (h *CatalogHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// CreateProduct handles POST /api/products
+// @Summary      Create a product
+// @Description  Creates a product with its variants, barcodes, unit conversions and starting prices in one transaction; any failure rolls back everything. Variants are the explicit ones plus every combination of attributes (size × color), with SKUs like TSHIRT-L-RED. The first barcode becomes primary unless one is marked is_primary.
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                true  "Tenant identifier"
+// @Param        Authorization  header    string                true  "Bearer token"
+// @Param        body           body      CreateProductRequest  true  "Product"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products [post]
+func (h *CatalogHandler) CreateProduct(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req CreateProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := &usecase.CreateProductInput{
+		Product:    toProductInput(&req.ProductRequest),
+		Attributes: toAttributes(req.Attributes),
+		Variants:   toVariantInputs(req.Variants),
+	}
+	for _, b := range req.Barcodes {
+		in.Barcodes = append(in.Barcodes, toBarcodeInput(b))
+	}
+	for _, u := range req.UomConversions {
+		in.UomConversions = append(in.UomConversions, toUomConversionInput(u))
+	}
+	for _, p := range req.Prices {
+		in.Prices = append(in.Prices, usecase.CatalogPriceInput{PriceListID: p.PriceListID, VariantSKU: p.VariantSKU, Price: p.Price})
+	}
+
+	resp := h.useCase.CreateProduct(c.Request.Context(), in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListProducts handles GET /api/products
+// @Summary      List products
+// @Description  Returns an organization's products by name, filtered by category, brand, type, active flag and a search on SKU or name
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id      header    string  true   "Tenant identifier"
+// @Param        Authorization    header    string  true   "Bearer token"
+// @Param        organization_id  query     int     false  "Organization ID; the active organization when omitted"
+// @Param        search           query     string  false  "SKU or name contains"
+// @Param        category_id      query     int     false  "Category ID"
+// @Param        brand_id         query     int     false  "Brand ID"
+// @Param        product_type     query     string  false  "Product type"
+// @Param        is_active        query     bool    false  "Active flag"
+// @Param        limit            query     int     false  "Limit"
+// @Param        offset           query     int     false  "Offset"
+// @Success      200              {object}  SuccessResponse
+// @Failure      400              {object}  ErrorResponse
+// @Failure      401              {object}  ErrorResponse
+// @Failure      500              {object}  ErrorResponse
+// @Router       /api/products [get]
+func (h *CatalogHandler) ListProducts(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	orgID, ok := organizationParam(c, c.Query("organization_id"))
+	if !ok {
+		return
+	}
+	categoryID, ok := optionalQueryID(c, "category_id")
+	if !ok {
+		return
+	}
+	brandID, ok := optionalQueryID(c, "brand_id")
+	if !ok {
+		return
+	}
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	if err != nil {
+		offset = 0
+	}
+	f := usecase.ProductFilter{
+		OrganizationID: orgID,
+		Search:         c.Query("search"),
+		CategoryID:     categoryID,
+		BrandID:        brandID,
+		ProductType:    c.Query("product_type"),
+		Limit:          int32(limit),
+		Offset:         int32(offset),
+	}
+	if v, err := strconv.ParseBool(c.Query("is_active")); err == nil {
+		f.IsActive = &v
+	}
+
+	resp := h.useCase.ListProducts(c.Request.Context(), f)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetProduct handles GET /api/products/:id
+// @Summary      Get a product
+// @Description  Returns a product with its variants, barcodes and unit conversions
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Product ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Router       /api/products/{id} [get]
+func (h *CatalogHandler) GetProduct(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.GetProduct(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateProduct handles PATCH /api/products/:id
+// @Summary      Update a product
+// @Description  Changes the given fields of a product; omitted fields keep their value. The SKU and organization cannot change.
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string          true  "Tenant identifier"
+// @Param        Authorization  header    string          true  "Bearer token"
+// @Param        id             path      int             true  "Product ID"
+// @Param        body           body      ProductRequest  true  "Fields to change"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id} [patch]
+func (h *CatalogHandler) UpdateProduct(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req ProductRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toProductInput(&req)
+	resp := h.useCase.UpdateProduct(c.Request.Context(), id, &in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteProduct handles DELETE /api/products/:id
+// @Summary      Delete a product
+// @Description  Deletes a product with its variants, barcodes, conversions and prices. A product already on documents or in stock is deactivated instead.
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Product ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id} [delete]
+func (h *CatalogHandler) DeleteProduct(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeleteProduct(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GenerateVariants handles POST /api/products/:id/variants
+// @Summary      Add variants to a product
+// @Description  Creates every combination of the attribute values (size × color) plus the explicit variants. Combinations the product already has, by SKU or attribute values, are skipped and listed.
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                   true  "Tenant identifier"
+// @Param        Authorization  header    string                   true  "Bearer token"
+// @Param        id             path      int                      true  "Product ID"
+// @Param        body           body      GenerateVariantsRequest  true  "Attribute matrix and variants"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/variants [post]
+func (h *CatalogHandler) GenerateVariants(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req GenerateVariantsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.GenerateVariants(c.Request.Context(), id, toAttributes(req.Attributes), toVariantInputs(req.Variants))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateVariant handles PATCH /api/products/:id/variants/:variant_id
+// @Summary      Update a variant
+// @Description  Renames a variant, replaces its attributes or toggles it; omitted fields keep their value. The SKU cannot change.
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string          true  "Tenant identifier"
+// @Param        Authorization  header    string          true  "Bearer token"
+// @Param        id             path      int             true  "Product ID"
+// @Param        variant_id     path      int             true  "Variant ID"
+// @Param        body           body      VariantRequest  true  "Fields to change"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/variants/{variant_id} [patch]
+func (h *CatalogHandler) UpdateVariant(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	variantID, ok := pathID(c, "variant_id")
+	if !ok {
+		return
+	}
+	var req VariantRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.UpdateVariant(c.Request.Context(), id, variantID, &usecase.VariantInput{
+		Name:       req.Name,
+		Attributes: req.Attributes,
+		IsActive:   req.IsActive,
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteVariant handles DELETE /api/products/:id/variants/:variant_id
+// @Summary      Delete a variant
+// @Description  Deletes a variant with its barcodes and prices. A variant already on documents or in stock is deactivated instead.
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Product ID"
+// @Param        variant_id     path      int     true  "Variant ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/variants/{variant_id} [delete]
+func (h *CatalogHandler) DeleteVariant(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	variantID, ok := pathID(c, "variant_id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeleteVariant(c.Request.Context(), id, variantID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// AddBarcode handles POST /api/products/:id/barcodes
+// @Summary      Add a barcode
+// @Description  Adds a barcode to a product or one of its variants. Barcodes already in use are rejected; the product's first barcode becomes primary.
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string          true  "Tenant identifier"
+// @Param        Authorization  header    string          true  "Bearer token"
+// @Param        id             path      int             true  "Product ID"
+// @Param        body           body      BarcodeRequest  true  "Barcode"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/barcodes [post]
+func (h *CatalogHandler) AddBarcode(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req BarcodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toBarcodeInput(req)
+	resp := h.useCase.AddBarcode(c.Request.Context(), id, &in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// SetPrimaryBarcode handles PUT /api/products/:id/barcodes/:barcode_id/primary
+// @Summary      Set the primary barcode
+// @Description  Makes a barcode the product's primary barcode and returns the product's barcodes
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Product ID"
+// @Param        barcode_id     path      int     true  "Barcode ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/barcodes/{barcode_id}/primary [put]
+func (h *CatalogHandler) SetPrimaryBarcode(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	barcodeID, ok := pathID(c, "barcode_id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.SetPrimaryBarcode(c.Request.Context(), id, barcodeID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteBarcode handles DELETE /api/products/:id/barcodes/:barcode_id
+// @Summary      Delete a barcode
+// @Description  Removes a barcode; when it was primary the next remaining barcode becomes primary
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Product ID"
+// @Param        barcode_id     path      int     true  "Barcode ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/barcodes/{barcode_id} [delete]
+func (h *CatalogHandler) DeleteBarcode(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	barcodeID, ok := pathID(c, "barcode_id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeleteBarcode(c.Request.Context(), id, barcodeID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// AddUomConversion handles POST /api/products/:id/uom-conversions
+// @Summary      Add a unit conversion
+// @Description  Adds a conversion between two units of measure of a product, e.g. 1 box = 12 pieces. A default conversion replaces the previous default.
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                true  "Tenant identifier"
+// @Param        Authorization  header    string                true  "Bearer token"
+// @Param        id             path      int                   true  "Product ID"
+// @Param        body           body      UomConversionRequest  true  "Conversion"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/uom-conversions [post]
+func (h *CatalogHandler) AddUomConversion(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req UomConversionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toUomConversionInput(req)
+	resp := h.useCase.AddUomConversion(c.Request.Context(), id, &in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateUomConversion handles PATCH /api/products/:id/uom-conversions/:conversion_id
+// @Summary      Update a unit conversion
+// @Description  Changes a conversion's factor and default flag; the units cannot change
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                true  "Tenant identifier"
+// @Param        Authorization  header    string                true  "Bearer token"
+// @Param        id             path      int                   true  "Product ID"
+// @Param        conversion_id  path      int                   true  "Conversion ID"
+// @Param        body           body      UomConversionRequest  true  "Factor and default flag"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/uom-conversions/{conversion_id} [patch]
+func (h *CatalogHandler) UpdateUomConversion(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	conversionID, ok := pathID(c, "conversion_id")
+	if !ok {
+		return
+	}
+	var req UomConversionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toUomConversionInput(req)
+	resp := h.useCase.UpdateUomConversion(c.Request.Context(), id, conversionID, &in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteUomConversion handles DELETE /api/products/:id/uom-conversions/:conversion_id
+// @Summary      Delete a unit conversion
+// @Description  Removes a unit conversion from a product
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Product ID"
+// @Param        conversion_id  path      int     true  "Conversion ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/uom-conversions/{conversion_id} [delete]
+func (h *CatalogHandler) DeleteUomConversion(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	conversionID, ok := pathID(c, "conversion_id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeleteUomConversion(c.Request.Context(), id, conversionID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+func toProductInput(req *ProductRequest) usecase.ProductInput {
+	return usecase.ProductInput{
+		OrganizationID:       req.OrganizationID,
+		SKU:                  req.SKU,
+		Name:                 req.Name,
+		Description:          req.Description,
+		CategoryID:           req.CategoryID,
+		BrandID:              req.BrandID,
+		BaseUomID:            req.BaseUomID,
+		ProductType:          req.ProductType,
+		TaxCategoryID:        req.TaxCategoryID,
+		IsSerialized:         req.IsSerialized,
+		IsBatchManaged:       req.IsBatchManaged,
+		IsActive:             req.IsActive,
+		IsSellable:           req.IsSellable,
+		IsPurchasable:        req.IsPurchasable,
+		AllowDecimalQuantity: req.AllowDecimalQuantity,
+		TrackInventory:       req.TrackInventory,
+		Metadata:             req.Metadata,
+	}
+}
+
+func toAttributes(reqs []VariantAttributeRequest) []catalog.Attribute {
+	attrs := make([]catalog.Attribute, 0, len(reqs))
+	for _, a := range reqs {
+		attrs = append(attrs, catalog.Attribute{Name: a.Name, Values: a.Values})
+	}
+	return attrs
+}
+
+func toVariantInputs(reqs []VariantRequest) []usecase.VariantInput {
+	variants := make([]usecase.VariantInput, 0, len(reqs))
+	for _, v := range reqs {
+		variants = append(variants, usecase.VariantInput{SKU: v.SKU, Name: v.Name, Attributes: v.Attributes, IsActive: v.IsActive})
+	}
+	return variants
+}
+
+func toBarcodeInput(req BarcodeRequest) usecase.BarcodeInput {
+	return usecase.BarcodeInput{
+		Barcode:     req.Barcode,
+		BarcodeType: req.BarcodeType,
+		VariantID:   req.VariantID,
+		VariantSKU:  req.VariantSKU,
+		IsPrimary:   req.IsPrimary,
+	}
+}
+
+func toUomConversionInput(req UomConversionRequest) usecase.UomConversionInput {
+	return usecase.UomConversionInput{
+		FromUomID:        req.FromUomID,
+		ToUomID:          req.ToUomID,
+		ConversionFactor: req.ConversionFactor,
+		IsDefault:        req.IsDefault,
+	}
+}
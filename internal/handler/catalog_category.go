@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"net/http"
+
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GetCategoryTree handles GET /api/categories/tree
+// @Summary      Get the category tree
+// @Description  Returns product categories as a nested tree sorted by name, each with its level and the number of active products filed directly under it. With active_only inactive categories and everything below them are left out.
+// @Tags         categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true   "Tenant identifier"
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        active_only    query     bool    false  "Only active categories"
+// @Success      200            {object}  SuccessResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/categories/tree [get]
+func (h *CatalogHandler) GetCategoryTree(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.GetCategoryTree(c.Request.Context(), c.Query("active_only") == "true")
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetCategory handles GET /api/categories/:id
+// @Summary      Get a category
+// @Description  Returns a category with its path from the root, its direct subcategories and its product count
+// @Tags         categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Category ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/categories/{id} [get]
+func (h *CatalogHandler) GetCategory(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.GetCategory(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CreateCategory handles POST /api/categories
+// @Summary      Create a category
+// @Description  Creates a product category, optionally under a parent; its level follows from the parent. Codes are unique.
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string           true  "Tenant identifier"
+// @Param        Authorization  header    string           true  "Bearer token"
+// @Param        body           body      CategoryRequest  true  "Category"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/categories [post]
+func (h *CatalogHandler) CreateCategory(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toCategoryInput(req)
+	resp := h.useCase.CreateCategory(c.Request.Context(), &in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateCategory handles PATCH /api/categories/:id
+// @Summary      Update a category
+// @Description  Changes a category's name, description or active status. Omitted fields are left unchanged; parent_id and code are ignored.
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string           true  "Tenant identifier"
+// @Param        Authorization  header    string           true  "Bearer token"
+// @Param        id             path      int              true  "Category ID"
+// @Param        body           body      CategoryRequest  true  "Fields to change"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/categories/{id} [patch]
+func (h *CatalogHandler) UpdateCategory(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req CategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toCategoryInput(req)
+	resp := h.useCase.UpdateCategory(c.Request.Context(), id, &in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// MoveCategory handles PUT /api/categories/:id/parent
+// @Summary      Move a category
+// @Description  Moves a category and its subtree under another parent, or to the root when parent_id is null, and recalculates category_level for the subtree. Moving a category under itself or one of its subcategories is rejected.
+// @Tags         categories
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string               true  "Tenant identifier"
+// @Param        Authorization  header    string               true  "Bearer token"
+// @Param        id             path      int                  true  "Category ID"
+// @Param        body           body      MoveCategoryRequest  true  "New parent"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/categories/{id}/parent [put]
+func (h *CatalogHandler) MoveCategory(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req MoveCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.MoveCategory(c.Request.Context(), id, req.ParentID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteCategory handles DELETE /api/categories/:id
+// @Summary      Delete a category
+// @Description  Deletes a category. A category with subcategories or products is refused unless reassign_to names a category outside its subtree; the products and subcategories then move there. Expiry rules of the category are deleted with it.
+// @Tags         categories
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true   "Tenant identifier"
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        id             path      int     true   "Category ID"
+// @Param        reassign_to    query     int     false  "Category to move products and subcategories to"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/categories/{id} [delete]
+func (h *CatalogHandler) DeleteCategory(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	reassignTo, ok := optionalQueryID(c, "reassign_to")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeleteCategory(c.Request.Context(), id, reassignTo)
+	c.JSON(resp.StatusCode, resp)
+}
+
+func toCategoryInput(req CategoryRequest) usecase.CategoryInput {
+	return usecase.CategoryInput{
+		ParentID:    req.ParentID,
+		Name:        req.Name,
+		Code:        req.Code,
+		Description: req.Description,
+		IsActive:    req.IsActive,
+	}
+}
@@ -0,0 +1,193 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// StartImport handles POST /api/catalog/imports
+// @Summary      Import a catalog file
+// @Description  Queues an import of products, variants, barcodes, prices and opening stock from a CSV or XLSX file and returns the job. Columns: sku (required), name, description, product_type, category_code, brand_code, uom_code, tax_category_code, is_serialized, is_batch_managed, is_active, is_sellable, is_purchasable, allow_decimal_quantity, track_inventory, variant_sku, variant_name, variant_attributes (color=Red; size=L), barcode, barcode_type, price_list_code, price, store_code, opening_quantity. Rows with the same sku make up one product. Categories, brands, units and tax categories are given by code; create_missing creates unknown categories, brands and units. Products are committed batch_size at a time. A file with errors imports nothing unless skip_invalid is set; dry_run only validates. Poll the job for progress and row errors.
+// @Tags         catalog
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id      header    string  true   "Tenant identifier"
+// @Param        Authorization    header    string  true   "Bearer token"
+// @Param        file             formData  file    true   "CSV or XLSX file"
+// @Param        organization_id  formData  int     false  "Organization owning the products; the active organization when omitted"
+// @Param        dry_run          formData  bool    false  "Validate only"
+// @Param        create_missing   formData  bool    false  "Create unknown categories, brands and units"
+// @Param        skip_invalid     formData  bool    false  "Import valid products even if some rows fail"
+// @Param        batch_size       formData  int     false  "Products per transaction (default 100)"
+// @Success      202              {object}  SuccessResponse
+// @Failure      400              {object}  ErrorResponse
+// @Failure      401              {object}  ErrorResponse
+// @Failure      404              {object}  ErrorResponse
+// @Failure      500              {object}  ErrorResponse
+// @Router       /api/catalog/imports [post]
+func (h *CatalogHandler) StartImport(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	orgID, ok := organizationParam(c, c.PostForm("organization_id"))
+	if !ok {
+		return
+	}
+	batchSize := 0
+	if s := c.PostForm("batch_size"); s != "" {
+		var err error
+		if batchSize, err = strconv.Atoi(s); err != nil || batchSize <= 0 {
+			c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid batch_size", nil))
+			return
+		}
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "file is required", nil))
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "cannot read file", nil))
+		return
+	}
+	defer f.Close()
+
+	resp := h.useCase.StartImport(c.Request.Context(), fh.Filename, f, usecase.CatalogImportOptions{
+		OrganizationID: orgID,
+		DryRun:         c.PostForm("dry_run") == "true" || c.PostForm("dry_run") == "1",
+		CreateMissing:  c.PostForm("create_missing") == "true" || c.PostForm("create_missing") == "1",
+		SkipInvalid:    c.PostForm("skip_invalid") == "true" || c.PostForm("skip_invalid") == "1",
+		BatchSize:      batchSize,
+		CreatedBy:      currentUserID(c),
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListImportJobs handles GET /api/catalog/imports
+// @Summary      List catalog imports
+// @Description  Returns an organization's catalog import jobs, newest first
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id      header    string  true   "Tenant identifier"
+// @Param        Authorization    header    string  true   "Bearer token"
+// @Param        organization_id  query     int     false  "Organization ID; the active organization when omitted"
+// @Param        limit            query     int     false  "Page size (default 100)"
+// @Param        offset           query     int     false  "Offset"
+// @Success      200              {object}  SuccessResponse
+// @Failure      400              {object}  ErrorResponse
+// @Failure      401              {object}  ErrorResponse
+// @Failure      500              {object}  ErrorResponse
+// @Router       /api/catalog/imports [get]
+func (h *CatalogHandler) ListImportJobs(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	orgID, ok := organizationParam(c, c.Query("organization_id"))
+	if !ok {
+		return
+	}
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	if err != nil {
+		offset = 0
+	}
+
+	resp := h.useCase.ListImportJobs(c.Request.Context(), orgID, int32(limit), int32(offset))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetImportJob handles GET /api/catalog/imports/:id
+// @Summary      Get a catalog import
+// @Description  Returns an import job with its status, row counts, row-level errors and a summary of what was (or, for a dry run, would be) created
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Import job ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Router       /api/catalog/imports/{id} [get]
+func (h *CatalogHandler) GetImportJob(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.GetImportJob(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ExportCatalog handles GET /api/catalog/export
+// @Summary      Export the catalog
+// @Description  Downloads an organization's products, variants and barcodes as CSV or XLSX in the import column layout, so an edited export can be imported again. Prices come from price_list_code (default list when empty); store_code fills opening_quantity with the store's quantity on hand.
+// @Tags         catalog
+// @Produce      text/csv
+// @Produce      application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Security     BearerAuth
+// @Param        x-tenant-id      header    string  true   "Tenant identifier"
+// @Param        Authorization    header    string  true   "Bearer token"
+// @Param        organization_id  query     int     false  "Organization ID; the active organization when omitted"
+// @Param        format           query     string  false  "csv or xlsx (default csv)"
+// @Param        price_list_code  query     string  false  "Price list for the price column"
+// @Param        store_code       query     string  false  "Store for the opening_quantity column"
+// @Success      200              {file}    file
+// @Failure      400              {object}  ErrorResponse
+// @Failure      401              {object}  ErrorResponse
+// @Failure      404              {object}  ErrorResponse
+// @Failure      500              {object}  ErrorResponse
+// @Router       /api/catalog/export [get]
+func (h *CatalogHandler) ExportCatalog(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	orgID, ok := organizationParam(c, c.Query("organization_id"))
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.ExportCatalog(c.Request.Context(), usecase.CatalogExportOptions{
+		OrganizationID: orgID,
+		Format:         c.Query("format"),
+		PriceListCode:  c.Query("price_list_code"),
+		StoreCode:      c.Query("store_code"),
+	})
+	out, ok := resp.Data.(*usecase.CatalogExport)
+	if resp.StatusCode != utils.CodeOK || !ok {
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", out.Filename))
+	c.Data(http.StatusOK, out.ContentType, out.Body)
+}
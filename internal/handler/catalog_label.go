@@ -0,0 +1,105 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"NEMBUS/internal/labels"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// GenerateBarcodes handles POST /api/catalog/barcodes/generate
+// @Summary      Generate internal barcodes
+// @Description  Gives every active product without variants and every active variant that has no barcode an internal barcode: an EAN-13 with check digit numbered behind an in-store prefix (default 20, must not overlap a scale barcode rule), or a Code 128 of the SKU. Codes are unique in the tenant; the product's first barcode becomes primary.
+// @Tags         catalog
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                   true  "Tenant identifier"
+// @Param        Authorization  header    string                   true  "Bearer token"
+// @Param        body           body      GenerateBarcodesRequest  true  "Products and symbology"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/catalog/barcodes/generate [post]
+func (h *CatalogHandler) GenerateBarcodes(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req GenerateBarcodesRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.GenerateBarcodes(c.Request.Context(), &usecase.GenerateBarcodesInput{
+		OrganizationID: req.OrganizationID,
+		ProductIDs:     req.ProductIDs,
+		Symbology:      req.Symbology,
+		Prefix:         req.Prefix,
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// RenderLabels handles POST /api/catalog/labels
+// @Summary      Print shelf labels and price tags
+// @Description  Renders a label per product and active variant with name, variant and unit, barcode and the price in effect on effective_date (store lists apply with store_id). A promotional price is shown with the regular price crossed out. Select products with product_ids, or a price change batch with price_list_id: the products whose price in that list starts on effective_date. Templates: shelf (60x35 mm) and price_tag (40x25 mm). PDF has a page per label, PNG stacks the labels at 300 dpi and ZPL is for 203 dpi label printers.
+// @Tags         catalog
+// @Accept       json
+// @Produce      application/pdf
+// @Produce      image/png
+// @Produce      plain
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string         true  "Tenant identifier"
+// @Param        Authorization  header    string         true  "Bearer token"
+// @Param        body           body      LabelsRequest  true  "Products, template and format"
+// @Success      200            {file}    file
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/catalog/labels [post]
+func (h *CatalogHandler) RenderLabels(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req LabelsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	date, err := parseOptionalDate("effective_date", req.EffectiveDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.RenderLabels(c.Request.Context(), &usecase.LabelInput{
+		OrganizationID: req.OrganizationID,
+		ProductIDs:     req.ProductIDs,
+		PriceListID:    req.PriceListID,
+		EffectiveDate:  date,
+		StoreID:        req.StoreID,
+		Template:       req.Template,
+		Format:         req.Format,
+		Copies:         req.Copies,
+	})
+	out, ok := resp.Data.(*labels.Output)
+	if resp.StatusCode != utils.CodeOK || !ok {
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", out.Filename))
+	c.Data(http.StatusOK, out.ContentType, out.Body)
+}
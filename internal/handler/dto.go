@@ -20,10 +20,19 @@ type LoginRequest struct {
 	Password  string `json:"password" binding:"required" example:"securepassword123"`
 }
 
-// LoginResponse represents login response
+// ChangeExpiredPasswordRequest represents a password change at sign in
+type ChangeExpiredPasswordRequest struct {
+	UserLogin       string `json:"user_login" binding:"required" example:"johndoe"`
+	CurrentPassword string `json:"current_password" binding:"required" example:"securepassword123"`
+	NewPassword     string `json:"new_password" binding:"required" example:"NewSecurePassword123"`
+}
+
+// LoginResponse represents login response; data is the token
 type LoginResponse struct {
-	Token string `json:"token" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
-	Type  string `json:"type" example:"Bearer"`
+	StatusCode   int            `json:"statusCode" example:"200"`
+	Message      string         `json:"message" example:"login successful"`
+	Data         string         `json:"data" example:"eyJhbGciOiJIUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	DefaultStore *StoreResponse `json:"default_store"`
 }
 
 // CreateUserRequest represents user creation request
@@ -33,10 +42,36 @@ type CreateUserRequest struct {
 	Username     string  `json:"username" binding:"required" example:"johndoe"`
 	Email        string  `json:"email" binding:"required" example:"john@example.com"`
 	IsActive     bool    `json:"is_active" example:"true"`
-	Password     *string `json:"password,omitempty" example:"securepassword123"`
+	Password     *string `json:"password,omitempty" example:"SecurePassword123"`
 	EmployeeCode *string `json:"employee_code,omitempty" example:"EMP001"`
 }
 
+// UpdateUserRequest represents user profile update request; omitted fields
+// are kept
+type UpdateUserRequest struct {
+	Email        *string     `json:"email,omitempty" example:"john@example.com"`
+	FirstName    *string     `json:"first_name,omitempty" example:"John"`
+	LastName     *string     `json:"last_name,omitempty" example:"Doe"`
+	EmployeeCode *string     `json:"employee_code,omitempty" example:"EMP001"`
+	Metadata     interface{} `json:"metadata,omitempty"`
+}
+
+// SetUserActiveRequest activates or deactivates a user
+type SetUserActiveRequest struct {
+	IsActive *bool `json:"is_active" binding:"required" example:"false"`
+}
+
+// ResetPasswordRequest represents an administrator's password reset
+type ResetPasswordRequest struct {
+	NewPassword string `json:"new_password" binding:"required" example:"NewSecurePassword123"`
+}
+
+// ChangePasswordRequest represents a user's change of their own password
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required" example:"securepassword123"`
+	NewPassword     string `json:"new_password" binding:"required" example:"NewSecurePassword123"`
+}
+
 // ErrorResponse represents an error response
 type ErrorResponse struct {
 	Error   string `json:"error" example:"Invalid request"`
@@ -143,6 +178,22 @@ type UpdateRoleRequest struct {
 	IsActive    bool    `json:"is_active" example:"true"`
 }
 
+// CloneRoleRequest names the copy of a role; the description defaults to the
+// source role's
+type CloneRoleRequest struct {
+	Name        string  `json:"name" binding:"required" example:"Senior Cashier"`
+	Code        string  `json:"code" binding:"required" example:"SENIOR_CASHIER"`
+	Description *string `json:"description,omitempty" example:"Cashier who may approve returns"`
+}
+
+// CreateRoleFromTemplateRequest overrides the name, code and description of
+// a role created from a template
+type CreateRoleFromTemplateRequest struct {
+	Name        string  `json:"name,omitempty" example:"Cashier"`
+	Code        string  `json:"code,omitempty" example:"CASHIER"`
+	Description *string `json:"description,omitempty" example:"Rings up sales on the POS of their stores"`
+}
+
 // AssignPermissionItem represents one permission assignment
 type AssignPermissionItem struct {
 	PermissionID int32  `json:"permission_id" example:"1"`
@@ -160,6 +211,39 @@ type RemovePermissionFromRoleRequest struct {
 	PermissionIDs []int32 `json:"permission_ids" example:"[2,3,4]"`
 }
 
+// CreatePermissionRequest represents permission creation request
+type CreatePermissionRequest struct {
+	Name        string  `json:"name" binding:"required" example:"Approve refunds"`
+	Code        string  `json:"code" binding:"required" example:"pos.refund_approve"`
+	Description *string `json:"description,omitempty" example:"Approve refunds above the cashier limit"`
+	Metadata    string  `json:"metadata,omitempty" example:"{\"group\":\"pos\"}"`
+}
+
+// UpdatePermissionRequest represents permission update request; omitted fields are unchanged
+type UpdatePermissionRequest struct {
+	Name        *string `json:"name,omitempty" example:"Approve refunds"`
+	Description *string `json:"description,omitempty" example:"Approve refunds above the cashier limit"`
+	Metadata    string  `json:"metadata,omitempty" example:"{\"group\":\"pos\"}"`
+}
+
+// NodePermissionsRequest represents permissions to attach to or detach from a module, menu or submenu
+type NodePermissionsRequest struct {
+	PermissionIDs []int32 `json:"permission_ids" binding:"required" example:"[2,3,4]"`
+}
+
+// PermissionGrantItem represents one role x permission cell of the permission matrix
+type PermissionGrantItem struct {
+	RoleID       int32   `json:"role_id" example:"1"`
+	PermissionID int32   `json:"permission_id" example:"2"`
+	Scope        *string `json:"scope,omitempty" example:"all"`
+}
+
+// UpdatePermissionMatrixRequest represents bulk grant and revoke of role permissions
+type UpdatePermissionMatrixRequest struct {
+	Grant  []PermissionGrantItem `json:"grant"`
+	Revoke []PermissionGrantItem `json:"revoke"`
+}
+
 // RoleNavigationResponse represents the response for GetNavigationByRoleCodeWithUserCounts
 type RoleNavigationResponse struct {
 	StatusCode int    `json:"statusCode"`
@@ -217,6 +301,19 @@ type ToggleMenuActiveRequest struct {
 	IsActive bool `json:"is_active" example:"true"`
 }
 
+// ReorderMenusRequest represents request body to reorder the menus under a parent
+type ReorderMenusRequest struct {
+	ModuleID     int32   `json:"module_id" binding:"required" example:"1"`
+	ParentMenuID *int32  `json:"parent_menu_id,omitempty" example:"2"`
+	MenuIDs      []int32 `json:"menu_ids" binding:"required" example:"3,1,2"`
+}
+
+// MoveMenuRequest represents request body to move a menu under another parent
+type MoveMenuRequest struct {
+	ParentMenuID *int32 `json:"parent_menu_id,omitempty" example:"2"`
+	Position     *int32 `json:"position,omitempty" example:"1"`
+}
+
 // ListMenusResponse represents a list of menus
 type ListMenusResponse struct {
 	Menus []MenuResponse `json:"menus"`
@@ -291,6 +388,20 @@ type ToggleSubmenuActiveRequest struct {
 	IsActive bool `json:"is_active" example:"true"`
 }
 
+// ReorderSubmenusRequest represents request body to reorder the submenus under a parent
+type ReorderSubmenusRequest struct {
+	MenuID          int32   `json:"menu_id" binding:"required" example:"1"`
+	ParentSubmenuID *int32  `json:"parent_submenu_id,omitempty" example:"2"`
+	SubmenuIDs      []int32 `json:"submenu_ids" binding:"required" example:"3,1,2"`
+}
+
+// MoveSubmenuRequest represents request body to move a submenu to another menu or parent
+type MoveSubmenuRequest struct {
+	MenuID          *int32 `json:"menu_id,omitempty" example:"1"`
+	ParentSubmenuID *int32 `json:"parent_submenu_id,omitempty" example:"2"`
+	Position        *int32 `json:"position,omitempty" example:"1"`
+}
+
 // ListSubmenusResponse represents a list of submenus
 type ListSubmenusResponse struct {
 	Submenus []SubmenuResponse `json:"submenus"`
@@ -325,7 +436,7 @@ type ListActiveSubmenusByMenuResponse struct {
 
 // CreatePosProductRequest represents POS "add product" request.
 type CreatePosProductRequest struct {
-	OrganizationID       int32   `json:"organization_id" binding:"required"`
+	OrganizationID       int32   `json:"organization_id"`
 	SKU                  string  `json:"sku" binding:"required"`
 	Name                 string  `json:"name" binding:"required"`
 	Description          *string `json:"description,omitempty"`
@@ -346,7 +457,7 @@ type CreatePosProductRequest struct {
 }
 
 type AddProductRequest struct {
-	OrganizationID       int32   `json:"organization_id" binding:"required"`
+	OrganizationID       int32   `json:"organization_id"`
 	SKU                  string  `json:"sku" binding:"required"`
 	Name                 string  `json:"name" binding:"required"`
 	Description          *string `json:"description"`
@@ -399,6 +510,14 @@ type AssignRoleToUserRequest struct {
 	Metadata interface{} `json:"metadata"`
 }
 
+// GrantStoreAccessRequest gives a user access to a store; is_primary makes it
+// the store selected at login
+type GrantStoreAccessRequest struct {
+	StoreID   int32       `json:"store_id" binding:"required" example:"2"`
+	IsPrimary bool        `json:"is_primary" example:"false"`
+	Metadata  interface{} `json:"metadata,omitempty"`
+}
+
 // CreateStoreRequest represents request body for creating a store
 type CreateStoreRequest struct {
 	Name          string                 `json:"name" example:"Main Warehouse"`
@@ -439,3 +558,335 @@ type StoreResponse struct {
 	CreatedAt      string                 `json:"created_at" example:"2026-02-04T10:15:30Z"`
 	UpdatedAt      string                 `json:"updated_at" example:"2026-02-04T10:15:30Z"`
 }
+
+// SaveReceiptTemplateRequest represents a receipt template create/replace request
+type SaveReceiptTemplateRequest struct {
+	Name             string   `json:"name" binding:"required" example:"Default receipt"`
+	Language         string   `json:"language" example:"bilingual"` // en, ar or bilingual
+	PaperWidth       int32    `json:"paper_width" example:"42"`     // characters per line
+	HeaderLines      []string `json:"header_lines"`
+	FooterLines      []string `json:"footer_lines"`
+	ShowTaxBreakdown *bool    `json:"show_tax_breakdown" example:"true"`
+	ShowBarcode      *bool    `json:"show_barcode" example:"true"`
+	CodePage         *int32   `json:"code_page" example:"37"` // ESC t table for Arabic text, 37 = PC864; English uses Windows-1252
+	IsDefault        bool     `json:"is_default" example:"true"`
+	IsActive         *bool    `json:"is_active" example:"true"`
+}
+
+// SaveBarcodeRuleRequest represents a variable-measure barcode rule create/replace request
+type SaveBarcodeRuleRequest struct {
+	Name            string `json:"name" binding:"required" example:"Scale weight (kg)"`
+	ValueType       string `json:"value_type" binding:"required" example:"weight"` // weight, price or count
+	ItemDigits      int32  `json:"item_digits" example:"5"`
+	ValueDigits     int32  `json:"value_digits" example:"5"`
+	ValueDecimals   int32  `json:"value_decimals" example:"3"`
+	ValueCheckDigit bool   `json:"value_check_digit" example:"false"` // a check digit between item code and value
+	IsActive        *bool  `json:"is_active" example:"true"`
+}
+
+// PosCheckoutLineRequest represents one cart line of a POS checkout
+type PosCheckoutLineRequest struct {
+	ProductID        int32    `json:"product_id" binding:"required" example:"12"`
+	ProductVariantID *int32   `json:"product_variant_id"`
+	Quantity         string   `json:"quantity" binding:"required" example:"2"`
+	Discount         string   `json:"discount" example:"0.00"` // line discount amount
+	SerialNumber     string   `json:"serial_number"`
+	SerialNumbers    []string `json:"serial_numbers"` // one per unit of serialized products
+	BatchNumber      string   `json:"batch_number"`
+}
+
+// PosCheckoutPaymentRequest represents one tender of a POS checkout
+type PosCheckoutPaymentRequest struct {
+	Method    string `json:"method" binding:"required" example:"cash"`
+	Amount    string `json:"amount" binding:"required" example:"50.00"`
+	Reference string `json:"reference"`
+}
+
+// PosCheckoutRequest represents the request body for completing a POS sale
+type PosCheckoutRequest struct {
+	CashierID  int32                       `json:"cashier_id" example:"3"` // optional; defaults to the signed-in user's cashier
+	CustomerID *int32                      `json:"customer_id"`
+	Lines      []PosCheckoutLineRequest    `json:"lines" binding:"required,dive"`
+	Payments   []PosCheckoutPaymentRequest `json:"payments" binding:"required,dive"`
+}
+
+// OrderLineRequest represents one line of a sales or purchase order
+type OrderLineRequest struct {
+	ProductID        int32  `json:"product_id" binding:"required" example:"12"`
+	ProductVariantID *int32 `json:"product_variant_id"`
+	UomID            *int32 `json:"uom_id"`
+	Quantity         string `json:"quantity" binding:"required" example:"10"`
+	UnitPrice        string `json:"unit_price" example:"9.50"` // optional on sales orders
+	Discount         string `json:"discount" example:"0.00"`   // line discount amount
+}
+
+// CreateSalesOrderRequest represents the request body for creating a sales order
+type CreateSalesOrderRequest struct {
+	OrganizationID int32              `json:"organization_id" example:"1"`
+	StoreID        int32              `json:"store_id" binding:"required" example:"1"`
+	CustomerID     *int32             `json:"customer_id"`
+	PriceListID    *int32             `json:"price_list_id"`
+	OrderDate      string             `json:"order_date" example:"2026-03-14"`
+	DeliveryDate   string             `json:"delivery_date" example:"2026-03-20"`
+	Lines          []OrderLineRequest `json:"lines" binding:"required,dive"`
+}
+
+// CreatePurchaseOrderRequest represents the request body for creating a purchase order
+type CreatePurchaseOrderRequest struct {
+	OrganizationID       int32              `json:"organization_id" example:"1"`
+	SupplierID           int32              `json:"supplier_id" binding:"required" example:"4"`
+	StoreID              int32              `json:"store_id" binding:"required" example:"1"`
+	PoDate               string             `json:"po_date" example:"2026-03-14"`
+	ExpectedDeliveryDate string             `json:"expected_delivery_date" example:"2026-03-28"`
+	Lines                []OrderLineRequest `json:"lines" binding:"required,dive"`
+}
+
+// CreatePriceListAssignmentRequest represents the request body for assigning a price list
+type CreatePriceListAssignmentRequest struct {
+	PriceListID  int32  `json:"price_list_id" binding:"required" example:"2"`
+	Scope        string `json:"scope" binding:"required" example:"customer_type"` // customer, customer_type, store, promotion
+	CustomerID   *int32 `json:"customer_id"`
+	CustomerType string `json:"customer_type" example:"wholesale"`
+	StoreID      *int32 `json:"store_id"`
+	Priority     int32  `json:"priority" example:"10"`
+	ValidFrom    string `json:"valid_from" example:"2026-01-01"`
+	ValidTo      string `json:"valid_to" example:"2026-12-31"`
+}
+
+// PriceListRequest represents the request body for creating or updating a price list
+type PriceListRequest struct {
+	Name          string `json:"name" binding:"required" example:"Wholesale SAR"`
+	Code          string `json:"code" example:"WHOLESALE_SAR"` // required on create, ignored on update
+	PriceListType string `json:"price_list_type" example:"wholesale"`
+	CurrencyCode  string `json:"currency_code" example:"SAR"`
+	ValidFrom     string `json:"valid_from" example:"2026-01-01"`
+	ValidTo       string `json:"valid_to" example:"2026-12-31"`
+	IsDefault     bool   `json:"is_default"`
+	IsActive      *bool  `json:"is_active"`
+}
+
+// ProductPriceRequest represents the request body for adding or scheduling a product price
+type ProductPriceRequest struct {
+	ProductID        int32  `json:"product_id" binding:"required" example:"12"`
+	ProductVariantID *int32 `json:"product_variant_id"`
+	UomID            *int32 `json:"uom_id"`
+	Price            string `json:"price" binding:"required" example:"10.50"`
+	MinQuantity      string `json:"min_quantity" example:"1"`
+	MaxQuantity      string `json:"max_quantity"`
+	ValidFrom        string `json:"valid_from" example:"2026-04-01"`
+	ValidTo          string `json:"valid_to"`
+}
+
+// UpdateProductPriceRequest represents the request body for updating a product price; empty fields are kept
+type UpdateProductPriceRequest struct {
+	Price       string `json:"price" example:"11.00"`
+	MinQuantity string `json:"min_quantity"`
+	MaxQuantity string `json:"max_quantity"`
+	ValidFrom   string `json:"valid_from"`
+	ValidTo     string `json:"valid_to"`
+	IsActive    *bool  `json:"is_active"`
+}
+
+// PriceAdjustmentRequest represents the request body for a bulk price adjustment
+type PriceAdjustmentRequest struct {
+	Mode          string `json:"mode" binding:"required" example:"percent"` // percent or fixed
+	Amount        string `json:"amount" binding:"required" example:"5"`     // may be negative
+	CategoryID    *int32 `json:"category_id"`
+	BrandID       *int32 `json:"brand_id"`
+	EffectiveFrom string `json:"effective_from" example:"2026-05-01"` // future date schedules the change
+}
+
+// StockTransferLineRequest is one product of a stock transfer
+type StockTransferLineRequest struct {
+	ProductID        int32    `json:"product_id" binding:"required" example:"12"`
+	ProductVariantID *int32   `json:"product_variant_id"`
+	Quantity         string   `json:"quantity" binding:"required" example:"5"`
+	BatchNumber      string   `json:"batch_number"`   // overrides FEFO; needs inventory.batch_override
+	SerialNumbers    []string `json:"serial_numbers"` // one per unit of serialized products
+}
+
+// StockTransferRequest represents the request body for moving stock between stores
+type StockTransferRequest struct {
+	FromStoreID int32                      `json:"from_store_id" binding:"required" example:"1"`
+	ToStoreID   int32                      `json:"to_store_id" binding:"required" example:"2"`
+	Notes       string                     `json:"notes"`
+	Lines       []StockTransferLineRequest `json:"lines" binding:"required,dive"`
+}
+
+// GoodsReceiptLineRequest is the quantity received against one purchase order line
+type GoodsReceiptLineRequest struct {
+	LineNumber        int32    `json:"line_number" binding:"required" example:"1"`
+	Quantity          string   `json:"quantity" binding:"required" example:"10"`
+	BatchNumber       string   `json:"batch_number"` // required for batch-managed products
+	ManufacturingDate string   `json:"manufacturing_date" example:"2026-01-10"`
+	ExpiryDate        string   `json:"expiry_date" example:"2027-01-10"`
+	SerialNumbers     []string `json:"serial_numbers"` // one per unit of serialized products
+}
+
+// GoodsReceiptRequest represents the request body for receiving goods against a purchase order
+type GoodsReceiptRequest struct {
+	Lines []GoodsReceiptLineRequest `json:"lines" binding:"required,dive"`
+}
+
+// PosReturnLineRequest is one item brought back on a POS return
+type PosReturnLineRequest struct {
+	LineNumber   int32  `json:"line_number" example:"1"`       // line of the original sale; optional when serial_number is given
+	SerialNumber string `json:"serial_number"`                 // required for serialized products
+	Quantity     string `json:"quantity" example:"1"`          // default the whole line
+	Disposition  string `json:"disposition" example:"restock"` // restock or rma
+}
+
+// PosReturnRequest represents the request body for a POS return
+type PosReturnRequest struct {
+	CashierID         int32                  `json:"cashier_id" example:"3"` // optional; defaults to the signed-in user's cashier
+	TransactionNumber string                 `json:"transaction_number" binding:"required" example:"T1-20260314-153012345"`
+	RefundMethod      string                 `json:"refund_method" example:"cash"`
+	Reason            string                 `json:"reason"`
+	Lines             []PosReturnLineRequest `json:"lines" binding:"required,dive"`
+}
+
+// AlertStatusUpdateRequest represents the request body for acknowledging or resolving an alert
+type AlertStatusUpdateRequest struct {
+	Status string `json:"status" binding:"required" example:"acknowledged"` // acknowledged or resolved
+}
+
+// ExpiryRuleRequest represents the request body for setting an expiry rule
+type ExpiryRuleRequest struct {
+	CategoryID         *int32 `json:"category_id" example:"4"` // omit for the default rule
+	NearExpiryDays     int32  `json:"near_expiry_days" binding:"required" example:"14"`
+	MaxMarkdownPercent string `json:"max_markdown_percent" example:"40"`
+}
+
+// ProductRequest holds the product fields for creating or updating a product; omitted fields keep their default or current value
+type ProductRequest struct {
+	OrganizationID       int32                  `json:"organization_id" example:"1"` // the active organization when omitted
+	SKU                  string                 `json:"sku" example:"TSHIRT"`        // required on create; cannot change
+	Name                 string                 `json:"name" example:"Cotton T-Shirt"`
+	Description          *string                `json:"description"`
+	CategoryID           *int32                 `json:"category_id"`
+	BrandID              *int32                 `json:"brand_id"`
+	BaseUomID            *int32                 `json:"base_uom_id"`
+	ProductType          *string                `json:"product_type"`
+	TaxCategoryID        *int32                 `json:"tax_category_id"`
+	IsSerialized         *bool                  `json:"is_serialized"`
+	IsBatchManaged       *bool                  `json:"is_batch_managed"`
+	IsActive             *bool                  `json:"is_active"`
+	IsSellable           *bool                  `json:"is_sellable"`
+	IsPurchasable        *bool                  `json:"is_purchasable"`
+	AllowDecimalQuantity *bool                  `json:"allow_decimal_quantity"`
+	TrackInventory       *bool                  `json:"track_inventory"`
+	Metadata             map[string]interface{} `json:"metadata"`
+}
+
+// VariantAttributeRequest is one axis of a variant matrix
+type VariantAttributeRequest struct {
+	Name   string   `json:"name" binding:"required" example:"size"`
+	Values []string `json:"values" binding:"required" example:"S,M,L"`
+}
+
+// VariantRequest is a variant given explicitly
+type VariantRequest struct {
+	SKU        string            `json:"sku" example:"TSHIRT-XL-NAVY"`
+	Name       string            `json:"name" example:"Cotton T-Shirt - XL / Navy"`
+	Attributes map[string]string `json:"attributes"`
+	IsActive   *bool             `json:"is_active"`
+}
+
+// BarcodeRequest is a barcode of a product or one of its variants
+type BarcodeRequest struct {
+	Barcode     string `json:"barcode" binding:"required" example:"6281000000017"`
+	BarcodeType string `json:"barcode_type" example:"EAN13"`
+	VariantID   *int32 `json:"variant_id"`
+	VariantSKU  string `json:"variant_sku" example:"TSHIRT-L-RED"` // on create, instead of variant_id
+	IsPrimary   bool   `json:"is_primary"`
+}
+
+// UomConversionRequest is a unit conversion of a product
+type UomConversionRequest struct {
+	FromUomID        int32  `json:"from_uom_id" example:"2"`
+	ToUomID          int32  `json:"to_uom_id" example:"1"`
+	ConversionFactor string `json:"conversion_factor" binding:"required" example:"12"`
+	IsDefault        bool   `json:"is_default"`
+}
+
+// CatalogPriceRequest is a starting price of a new product
+type CatalogPriceRequest struct {
+	PriceListID *int32 `json:"price_list_id"` // default price list when omitted
+	VariantSKU  string `json:"variant_sku"`
+	Price       string `json:"price" binding:"required" example:"49.00"`
+}
+
+// CreateProductRequest represents the request body for creating a product with its variants, barcodes, conversions and prices
+type CreateProductRequest struct {
+	ProductRequest
+	Attributes     []VariantAttributeRequest `json:"attributes"`
+	Variants       []VariantRequest          `json:"variants"`
+	Barcodes       []BarcodeRequest          `json:"barcodes"`
+	UomConversions []UomConversionRequest    `json:"uom_conversions"`
+	Prices         []CatalogPriceRequest     `json:"prices"`
+}
+
+// GenerateVariantsRequest represents the request body for adding variants to a product
+type GenerateVariantsRequest struct {
+	Attributes []VariantAttributeRequest `json:"attributes"`
+	Variants   []VariantRequest          `json:"variants"`
+}
+
+// GenerateBarcodesRequest represents the request body for generating internal barcodes
+type GenerateBarcodesRequest struct {
+	OrganizationID int32   `json:"organization_id" example:"1"`
+	ProductIDs     []int32 `json:"product_ids"`               // all products without a barcode when empty
+	Symbology      string  `json:"symbology" example:"ean13"` // ean13 (default) or code128
+	Prefix         string  `json:"prefix" example:"20"`       // in-store EAN-13 prefix, default 20
+}
+
+// LabelsRequest represents the request body for rendering shelf labels and price tags
+type LabelsRequest struct {
+	OrganizationID int32   `json:"organization_id" example:"1"`
+	ProductIDs     []int32 `json:"product_ids"`
+	PriceListID    *int32  `json:"price_list_id"`                       // labels the list's price changes on effective_date
+	EffectiveDate  string  `json:"effective_date" example:"2026-05-01"` // default today
+	StoreID        *int32  `json:"store_id"`
+	Template       string  `json:"template" example:"shelf"` // shelf (default) or price_tag
+	Format         string  `json:"format" example:"pdf"`     // pdf (default), png or zpl
+	Copies         int     `json:"copies" example:"1"`
+}
+
+// CategoryRequest represents the request body for creating or updating a product category
+type CategoryRequest struct {
+	ParentID    *int32  `json:"parent_id"` // on create only; use the move endpoint to reparent
+	Name        string  `json:"name" example:"Dairy"`
+	Code        string  `json:"code" example:"DAIRY"` // on create only
+	Description *string `json:"description"`
+	IsActive    *bool   `json:"is_active"`
+}
+
+// MoveCategoryRequest represents the request body for moving a category
+type MoveCategoryRequest struct {
+	ParentID *int32 `json:"parent_id"` // null moves the category to the root
+}
+
+// BrandRequest represents the request body for creating or updating a brand
+type BrandRequest struct {
+	Name     string `json:"name" example:"Almarai"`
+	Code     string `json:"code" example:"ALMARAI"` // on create only
+	IsActive *bool  `json:"is_active"`
+}
+
+// UnitOfMeasureRequest represents the request body for creating or updating a unit of measure
+type UnitOfMeasureRequest struct {
+	Code          string  `json:"code" example:"KG"` // on create only
+	Name          string  `json:"name" example:"Kilogram"`
+	UomType       *string `json:"uom_type" example:"weight"`
+	DecimalPlaces *int32  `json:"decimal_places" example:"3"` // 0 to 6, default 2
+	IsActive      *bool   `json:"is_active"`
+}
+
+// TaxCategoryRequest represents the request body for creating or updating a tax category
+type TaxCategoryRequest struct {
+	Name        string `json:"name" example:"Standard VAT"`
+	Code        string `json:"code" example:"VAT15"`     // on create only
+	TaxRate     string `json:"tax_rate" example:"15.00"` // percentage, required on create
+	IsInclusive *bool  `json:"is_inclusive"`
+	IsActive    *bool  `json:"is_active"`
+}
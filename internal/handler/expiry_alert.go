@@ -0,0 +1,232 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExpiryAlertHandler holds the expiry alert use case.
+type ExpiryAlertHandler struct {
+	useCase *usecase.ExpiryAlertUseCase
+}
+
+// NewExpiryAlertHandler creates a new expiry alert handler.
+func NewExpiryAlertHandler(uc *usecase.ExpiryAlertUseCase) *ExpiryAlertHandler {
+	return &ExpiryAlertHandler{useCase: uc}
+}
+
+func (h *ExpiryAlertHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// ListStoreAlerts handles GET /api/stores/:id/alerts
+// @Summary      Store alerts inbox
+// @Description  Returns a store's expired and near-expiry alerts, soonest expiry first, with the suggested markdown price of near-expiry stock. Without status, resolved alerts are left out.
+// @Tags         inventory-alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true   "Tenant identifier"
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        id             path      int     true   "Store ID"
+// @Param        status         query     string  false  "open, acknowledged or resolved"
+// @Param        alert_type     query     string  false  "near_expiry or expired"
+// @Param        limit          query     int     false  "Limit"
+// @Param        offset         query     int     false  "Offset"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      403            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/stores/{id}/alerts [get]
+func (h *ExpiryAlertHandler) ListStoreAlerts(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	storeID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	if err != nil {
+		offset = 0
+	}
+
+	resp := h.useCase.ListStoreAlerts(c.Request.Context(), storeID, c.Query("status"), c.Query("alert_type"), int32(limit), int32(offset))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateAlertStatus handles PATCH /api/inventory/alerts/:id
+// @Summary      Acknowledge or resolve an alert
+// @Description  Sets an alert's status to acknowledged or resolved. The expiry check keeps refreshing acknowledged alerts and leaves resolved ones resolved.
+// @Tags         inventory-alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                    true  "Tenant identifier"
+// @Param        Authorization  header    string                    true  "Bearer token"
+// @Param        id             path      int                       true  "Alert ID"
+// @Param        body           body      AlertStatusUpdateRequest  true  "New status"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Router       /api/inventory/alerts/{id} [patch]
+func (h *ExpiryAlertHandler) UpdateAlertStatus(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req AlertStatusUpdateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.UpdateAlertStatus(c.Request.Context(), id, req.Status, currentUserID(c))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// RunExpiryCheck handles POST /api/inventory/expiry-check
+// @Summary      Run the expiry check now
+// @Description  Runs the scheduled expiry check for the tenant immediately: marks expired batches, raises expired and near-expiry alerts with markdown suggestions and resolves alerts whose stock is gone.
+// @Tags         inventory-alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Success      200            {object}  SuccessResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/inventory/expiry-check [post]
+func (h *ExpiryAlertHandler) RunExpiryCheck(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.RunExpiryCheck(c.Request.Context())
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListExpiryRules handles GET /api/inventory/expiry-rules
+// @Summary      List expiry rules
+// @Description  Returns the near-expiry window and maximum markdown per product category. The rule without category_id is the default; categories without a rule use their parent's.
+// @Tags         inventory-alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Success      200            {object}  SuccessResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/inventory/expiry-rules [get]
+func (h *ExpiryAlertHandler) ListExpiryRules(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.ListExpiryRules(c.Request.Context())
+	c.JSON(resp.StatusCode, resp)
+}
+
+// SetExpiryRule handles PUT /api/inventory/expiry-rules
+// @Summary      Set an expiry rule
+// @Description  Creates or replaces the expiry rule of a category, or the default rule when category_id is omitted. The suggested markdown grows from nothing at the edge of the window to max_markdown_percent on the expiry day.
+// @Tags         inventory-alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string             true  "Tenant identifier"
+// @Param        Authorization  header    string             true  "Bearer token"
+// @Param        body           body      ExpiryRuleRequest  true  "Rule"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/inventory/expiry-rules [put]
+func (h *ExpiryAlertHandler) SetExpiryRule(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req ExpiryRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.SetExpiryRule(c.Request.Context(), &usecase.ExpiryRuleInput{
+		CategoryID:         req.CategoryID,
+		NearExpiryDays:     req.NearExpiryDays,
+		MaxMarkdownPercent: req.MaxMarkdownPercent,
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteExpiryRule handles DELETE /api/inventory/expiry-rules/:category_id
+// @Summary      Delete a category's expiry rule
+// @Description  Removes a category's rule so the category inherits its parent's rule or the default again
+// @Tags         inventory-alerts
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        category_id    path      int     true  "Category ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/inventory/expiry-rules/{category_id} [delete]
+func (h *ExpiryAlertHandler) DeleteExpiryRule(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	categoryID, ok := pathID(c, "category_id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeleteExpiryRule(c.Request.Context(), categoryID)
+	c.JSON(resp.StatusCode, resp)
+}
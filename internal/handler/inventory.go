@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// InventoryHandler holds the inventory use case.
+type InventoryHandler struct {
+	useCase *usecase.InventoryUseCase
+}
+
+// NewInventoryHandler creates a new inventory handler.
+func NewInventoryHandler(uc *usecase.InventoryUseCase) *InventoryHandler {
+	return &InventoryHandler{useCase: uc}
+}
+
+func (h *InventoryHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// TransferStock handles POST /api/inventory/transfers
+// @Summary      Transfer stock between stores
+// @Description  Moves stock from one store of the active organization to another. Batch-managed products are taken from batches first-expiry-first-out (expired batches are never moved) and arrive under the same batch numbers and dates; a line's batch_number picks the batch instead and requires the inventory.batch_override permission. Serialized products need one serial number per unit, on hand in the source store.
+// @Tags         inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                true  "Tenant identifier"
+// @Param        Authorization  header    string                true  "Bearer token"
+// @Param        body           body      StockTransferRequest  true  "Transfer payload"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      403            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/inventory/transfers [post]
+func (h *InventoryHandler) TransferStock(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req StockTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	input := &usecase.StockTransferInput{
+		UserID:      currentUserID(c),
+		FromStoreID: req.FromStoreID,
+		ToStoreID:   req.ToStoreID,
+		Notes:       req.Notes,
+	}
+	for _, l := range req.Lines {
+		input.Lines = append(input.Lines, usecase.StockTransferLine{
+			ProductID:        l.ProductID,
+			ProductVariantID: l.ProductVariantID,
+			Quantity:         l.Quantity,
+			BatchNumber:      l.BatchNumber,
+			SerialNumbers:    l.SerialNumbers,
+		})
+	}
+
+	resp := h.useCase.TransferStock(c.Request.Context(), input)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// TraceSerialNumber handles GET /api/serials/:serial
+// @Summary      Trace serial number
+// @Description  Returns a serialized unit with its status (in_stock, sold, returned, rma) and current store, and every stock movement of it oldest first with the number of the sale, return, purchase order or transfer it belongs to. The unit's store must be in the active organization and the caller's inventory.view scope; only movements touching such stores are listed.
+// @Tags         inventory
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        serial         path      string  true  "Serial number"
+// @Success      200            {object}  SuccessResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      403            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/serials/{serial} [get]
+func (h *InventoryHandler) TraceSerialNumber(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.TraceSerialNumber(c.Request.Context(), c.Param("serial"))
+	c.JSON(resp.StatusCode, resp)
+}
@@ -0,0 +1,574 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MasterDataHandler holds the master data use case.
+type MasterDataHandler struct {
+	useCase *usecase.MasterDataUseCase
+}
+
+// NewMasterDataHandler creates a new master data handler.
+func NewMasterDataHandler(uc *usecase.MasterDataUseCase) *MasterDataHandler {
+	return &MasterDataHandler{useCase: uc}
+}
+
+func (h *MasterDataHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// activeFilter reads the active_only query parameter; absent lists both
+// active and inactive records.
+func activeFilter(c *gin.Context) *bool {
+	s, ok := c.GetQuery("active_only")
+	if !ok || s == "" {
+		return nil
+	}
+	v := s == "true"
+	return &v
+}
+
+// ListBrands handles GET /api/brands
+// @Summary      List brands
+// @Description  Returns brands with the number of active products and categories using them, most used first. Search matches name and code.
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true   "Tenant identifier"
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        active_only    query     bool    false  "true for active brands, false for inactive ones; both when omitted"
+// @Param        search         query     string  false  "Name or code contains"
+// @Param        limit          query     int     false  "Limit"
+// @Param        offset         query     int     false  "Offset"
+// @Success      200            {object}  SuccessResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/brands [get]
+func (h *MasterDataHandler) ListBrands(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	if err != nil {
+		offset = 0
+	}
+
+	resp := h.useCase.ListBrands(c.Request.Context(), activeFilter(c), c.Query("search"), int32(limit), int32(offset))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetBrand handles GET /api/brands/:id
+// @Summary      Get a brand
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Brand ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Router       /api/brands/{id} [get]
+func (h *MasterDataHandler) GetBrand(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.GetBrand(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CreateBrand handles POST /api/brands
+// @Summary      Create a brand
+// @Description  Creates a brand. Codes are unique.
+// @Tags         master-data
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string        true  "Tenant identifier"
+// @Param        Authorization  header    string        true  "Bearer token"
+// @Param        body           body      BrandRequest  true  "Brand"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/brands [post]
+func (h *MasterDataHandler) CreateBrand(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req BrandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.CreateBrand(c.Request.Context(), &usecase.BrandInput{Name: req.Name, Code: req.Code, IsActive: req.IsActive})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateBrand handles PATCH /api/brands/:id
+// @Summary      Update a brand
+// @Description  Renames a brand or activates/deactivates it. Omitted fields are left unchanged; the code cannot be changed.
+// @Tags         master-data
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string        true  "Tenant identifier"
+// @Param        Authorization  header    string        true  "Bearer token"
+// @Param        id             path      int           true  "Brand ID"
+// @Param        body           body      BrandRequest  true  "Fields to change"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/brands/{id} [patch]
+func (h *MasterDataHandler) UpdateBrand(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req BrandRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.UpdateBrand(c.Request.Context(), id, &usecase.BrandInput{Name: req.Name, IsActive: req.IsActive})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteBrand handles DELETE /api/brands/:id
+// @Summary      Delete a brand
+// @Description  Deletes a brand. A brand used by products is refused; deactivate it instead.
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Brand ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/brands/{id} [delete]
+func (h *MasterDataHandler) DeleteBrand(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeleteBrand(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListUnits handles GET /api/units-of-measure
+// @Summary      List units of measure
+// @Description  Returns units of measure with the number of active products and unit conversions using them.
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true   "Tenant identifier"
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        active_only    query     bool    false  "true for active units, false for inactive ones; both when omitted"
+// @Param        uom_type       query     string  false  "Unit type, e.g. weight or count"
+// @Success      200            {object}  SuccessResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/units-of-measure [get]
+func (h *MasterDataHandler) ListUnits(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.ListUnits(c.Request.Context(), activeFilter(c), c.Query("uom_type"))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetUnit handles GET /api/units-of-measure/:id
+// @Summary      Get a unit of measure
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Unit of measure ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Router       /api/units-of-measure/{id} [get]
+func (h *MasterDataHandler) GetUnit(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.GetUnit(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CreateUnit handles POST /api/units-of-measure
+// @Summary      Create a unit of measure
+// @Description  Creates a unit of measure with 0 to 6 decimal places (2 by default). Codes are unique.
+// @Tags         master-data
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                true  "Tenant identifier"
+// @Param        Authorization  header    string                true  "Bearer token"
+// @Param        body           body      UnitOfMeasureRequest  true  "Unit of measure"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/units-of-measure [post]
+func (h *MasterDataHandler) CreateUnit(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req UnitOfMeasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toUnitInput(req)
+	resp := h.useCase.CreateUnit(c.Request.Context(), &in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateUnit handles PATCH /api/units-of-measure/:id
+// @Summary      Update a unit of measure
+// @Description  Changes a unit's name, type, decimal places or active status. Omitted fields are left unchanged; the code cannot be changed.
+// @Tags         master-data
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                true  "Tenant identifier"
+// @Param        Authorization  header    string                true  "Bearer token"
+// @Param        id             path      int                   true  "Unit of measure ID"
+// @Param        body           body      UnitOfMeasureRequest  true  "Fields to change"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/units-of-measure/{id} [patch]
+func (h *MasterDataHandler) UpdateUnit(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req UnitOfMeasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toUnitInput(req)
+	resp := h.useCase.UpdateUnit(c.Request.Context(), id, &in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteUnit handles DELETE /api/units-of-measure/:id
+// @Summary      Delete a unit of measure
+// @Description  Deletes a unit of measure. A unit used by products, unit conversions or prices is refused with its usage counts; deactivate it instead.
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Unit of measure ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/units-of-measure/{id} [delete]
+func (h *MasterDataHandler) DeleteUnit(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeleteUnit(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListTaxCategories handles GET /api/tax-categories
+// @Summary      List tax categories
+// @Description  Returns tax categories with the number of active products in each.
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true   "Tenant identifier"
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        active_only    query     bool    false  "true for active categories, false for inactive ones; both when omitted"
+// @Success      200            {object}  SuccessResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/tax-categories [get]
+func (h *MasterDataHandler) ListTaxCategories(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.ListTaxCategories(c.Request.Context(), activeFilter(c))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetTaxCategory handles GET /api/tax-categories/:id
+// @Summary      Get a tax category
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Tax category ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Router       /api/tax-categories/{id} [get]
+func (h *MasterDataHandler) GetTaxCategory(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.GetTaxCategory(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CreateTaxCategory handles POST /api/tax-categories
+// @Summary      Create a tax category
+// @Description  Creates a tax category with a rate between 0 and 100 percent. Codes are unique. The creation is recorded in the tax change history.
+// @Tags         master-data
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string              true  "Tenant identifier"
+// @Param        Authorization  header    string              true  "Bearer token"
+// @Param        body           body      TaxCategoryRequest  true  "Tax category"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/tax-categories [post]
+func (h *MasterDataHandler) CreateTaxCategory(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req TaxCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toTaxCategoryInput(req)
+	resp := h.useCase.CreateTaxCategory(c.Request.Context(), &in, currentUserID(c))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateTaxCategory handles PATCH /api/tax-categories/:id
+// @Summary      Update a tax category
+// @Description  Changes a tax category's name, rate, inclusive flag or active status. Omitted fields are left unchanged; the code cannot be changed. Changes to the rate, inclusive flag or status are recorded with the user who made them.
+// @Tags         master-data
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string              true  "Tenant identifier"
+// @Param        Authorization  header    string              true  "Bearer token"
+// @Param        id             path      int                 true  "Tax category ID"
+// @Param        body           body      TaxCategoryRequest  true  "Fields to change"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/tax-categories/{id} [patch]
+func (h *MasterDataHandler) UpdateTaxCategory(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req TaxCategoryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	in := toTaxCategoryInput(req)
+	resp := h.useCase.UpdateTaxCategory(c.Request.Context(), id, &in, currentUserID(c))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteTaxCategory handles DELETE /api/tax-categories/:id
+// @Summary      Delete a tax category
+// @Description  Deletes a tax category. A category used by products is refused; deactivate it instead. The delete is recorded in the tax change history.
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Tax category ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/tax-categories/{id} [delete]
+func (h *MasterDataHandler) DeleteTaxCategory(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeleteTaxCategory(c.Request.Context(), id, currentUserID(c))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListTaxCategoryChanges handles GET /api/tax-categories/history
+// @Summary      Tax change history
+// @Description  Returns who created, changed or deleted a tax category and when, with the old and new rate, inclusive flag and status, newest first. Looked up by code so the history of a deleted category stays available.
+// @Tags         master-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        code           query     string  true  "Tax category code"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/tax-categories/history [get]
+func (h *MasterDataHandler) ListTaxCategoryChanges(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	code := c.Query("code")
+	if code == "" {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "code is required", nil))
+		return
+	}
+
+	resp := h.useCase.ListTaxCategoryChanges(c.Request.Context(), code)
+	c.JSON(resp.StatusCode, resp)
+}
+
+func toUnitInput(req UnitOfMeasureRequest) usecase.UnitInput {
+	return usecase.UnitInput{
+		Code:          req.Code,
+		Name:          req.Name,
+		UomType:       req.UomType,
+		DecimalPlaces: req.DecimalPlaces,
+		IsActive:      req.IsActive,
+	}
+}
+
+func toTaxCategoryInput(req TaxCategoryRequest) usecase.TaxCategoryInput {
+	return usecase.TaxCategoryInput{
+		Name:        req.Name,
+		Code:        req.Code,
+		TaxRate:     req.TaxRate,
+		IsInclusive: req.IsInclusive,
+		IsActive:    req.IsActive,
+	}
+}
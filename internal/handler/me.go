@@ -0,0 +1,235 @@
+package handler
+
+import (
+	"net/http"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MeHandler serves the signed-in user's own profile, navigation and
+// permissions, taking the user from the JWT.
+type MeHandler struct {
+	userUseCase       *usecase.UserUseCase
+	authUseCase       *usecase.AuthUseCase
+	navigationUseCase *usecase.NavigationUseCase
+	permissionUseCase *usecase.PermissionUseCase
+}
+
+// NewMeHandler creates a new handler instance
+func NewMeHandler(userUC *usecase.UserUseCase, authUC *usecase.AuthUseCase, navigationUC *usecase.NavigationUseCase, permissionUC *usecase.PermissionUseCase) *MeHandler {
+	return &MeHandler{
+		userUseCase:       userUC,
+		authUseCase:       authUC,
+		navigationUseCase: navigationUC,
+		permissionUseCase: permissionUC,
+	}
+}
+
+// getRepositoryFromContext extracts repository from Gin context
+func (h *MeHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// signedInUser returns the user ID of the JWT, or writes 401 without one.
+func signedInUser(c *gin.Context) (*int32, bool) {
+	userID := currentUserID(c)
+	if userID == nil {
+		c.JSON(http.StatusUnauthorized, utils.NewResponse(http.StatusUnauthorized, "invalid user in token", nil))
+		return nil, false
+	}
+	return userID, true
+}
+
+// GetProfile handles GET /api/me
+// @Summary      Get my profile
+// @Description  Returns the signed-in user with their organization, roles, permissions, store access, primary store and cashier details
+// @Tags         me
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header   string  true  "Tenant identifier"
+// @Param        Authorization  header   string  true  "Bearer token"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/me [get]
+func (h *MeHandler) GetProfile(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.userUseCase.SetRepository(repo)
+
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+
+	resp := h.userUseCase.GetUserProfile(c.Request.Context(), userID, *userID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetNavigation handles GET /api/me/navigation
+// @Summary      Get my navigation
+// @Description  Returns the signed-in user's navigation: modules, menus, submenus with permissions and UI settings. The response carries an ETag; send it back in If-None-Match to get 304 Not Modified while the navigation is unchanged.
+// @Tags         me
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header   string  true  "Tenant identifier"
+// @Param        Authorization  header   string  true  "Bearer token"
+// @Param        If-None-Match  header   string  false "ETag of the navigation the client has"
+// @Success      200  {object}  SuccessResponse
+// @Success      304  "Navigation not modified"
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/me/navigation [get]
+func (h *MeHandler) GetNavigation(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.navigationUseCase.SetRepository(repo)
+
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+
+	resp := h.navigationUseCase.GetUserNavigation(c.Request.Context(), userID, *userID)
+	writeNavigation(c, resp)
+}
+
+// GetPermissions handles GET /api/me/permissions
+// @Summary      Get my permissions
+// @Description  Returns the signed-in user's roles, the permissions granted by them and the permission codes
+// @Tags         me
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header   string  true  "Tenant identifier"
+// @Param        Authorization  header   string  true  "Bearer token"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/me/permissions [get]
+func (h *MeHandler) GetPermissions(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.permissionUseCase.SetRepository(repo)
+
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+
+	resp := h.permissionUseCase.GetUserPermissions(c.Request.Context(), userID, *userID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CheckSubmenuPermission handles GET /api/me/permissions/submenu/:submenu_code
+// @Summary      Check my submenu permission
+// @Description  Checks if the signed-in user has access to a submenu by submenu code
+// @Tags         me
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header   string  true  "Tenant identifier"
+// @Param        Authorization  header   string  true  "Bearer token"
+// @Param        submenu_code   path     string  true  "Submenu code"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/me/permissions/submenu/{submenu_code} [get]
+func (h *MeHandler) CheckSubmenuPermission(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.permissionUseCase.SetRepository(repo)
+
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+
+	resp := h.permissionUseCase.CheckUserSubmenuPermission(c.Request.Context(), userID, *userID, c.Param("submenu_code"))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetRoutes handles GET /api/me/routes
+// @Summary      Get my routes
+// @Description  Returns a flat list of the routes the signed-in user can open, with their submenu, menu, module and the permissions that grant each, for client-side route guards
+// @Tags         me
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header   string  true  "Tenant identifier"
+// @Param        Authorization  header   string  true  "Bearer token"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/me/routes [get]
+func (h *MeHandler) GetRoutes(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.navigationUseCase.SetRepository(repo)
+
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+
+	resp := h.navigationUseCase.GetAccessibleRoutes(c.Request.Context(), userID, *userID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ChangePassword handles POST /api/me/password
+// @Summary      Change my password
+// @Description  Changes the signed-in user's password after checking the current one. Attempts are audited and locked out like logins (429 with Retry-After). Every other session is signed out; the response carries a fresh token and the default store, like the login response, to keep this one going.
+// @Tags         me
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header   string                 true  "Tenant identifier"
+// @Param        Authorization  header   string                 true  "Bearer token"
+// @Param        body           body     ChangePasswordRequest  true  "Current and new password"
+// @Success      200  {object}  LoginResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      429  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/me/password [post]
+func (h *MeHandler) ChangePassword(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.authUseCase.SetRepository(repo)
+
+	userID, ok := signedInUser(c)
+	if !ok {
+		return
+	}
+	var req ChangePasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid request body", nil))
+		return
+	}
+
+	resp := h.authUseCase.ChangePassword(c.Request.Context(), *userID, req.CurrentPassword, req.NewPassword, loginClient(c))
+	writeLoginResponse(c, resp)
+}
@@ -433,3 +433,78 @@ func (h *MenuHandler) DeleteMenu(c *gin.Context) {
 	resp := h.useCase.DeleteMenu(c.Request.Context(), int32(id))
 	c.JSON(resp.StatusCode, resp)
 }
+
+// ReorderMenus handles POST /menus/reorder
+// @Summary      Reorder menus
+// @Description  Set the order of the menus of a module under a parent menu (top-level menus without parent_menu_id). menu_ids must list every menu under the parent exactly once; display orders are renumbered 1, 2, 3, ... in that order.
+// @Tags         menus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header  string                       true  "Tenant identifier"
+// @Param        Authorization header  string                       true  "Bearer token"
+// @Param        body          body    handler.ReorderMenusRequest  true  "Menu order"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/menus/reorder [post]
+func (h *MenuHandler) ReorderMenus(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req ReorderMenusRequest
+	if err := c.BindJSON(&req); err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid request body", err.Error())
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	resp := h.useCase.ReorderMenus(c.Request.Context(), req.ModuleID, req.ParentMenuID, req.MenuIDs)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// MoveMenu handles POST /menus/{id}/move
+// @Summary      Move a menu
+// @Description  Move a menu with its child menus under another parent menu of the same module, or to the top level without parent_menu_id, at a 1-based position among its new siblings (last by default). Moving a menu under itself or one of its child menus is rejected. Display orders of the old and new siblings are renumbered.
+// @Tags         menus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header  string                   true  "Tenant identifier"
+// @Param        Authorization header  string                   true  "Bearer token"
+// @Param        id            path    int                      true  "Menu ID"
+// @Param        body          body    handler.MoveMenuRequest  true  "Target parent and position"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/menus/{id}/move [post]
+func (h *MenuHandler) MoveMenu(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid menu id", nil)
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	var req MoveMenuRequest
+	if err := c.BindJSON(&req); err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid request body", err.Error())
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	resp := h.useCase.MoveMenu(c.Request.Context(), int32(id), req.ParentMenuID, req.Position)
+	c.JSON(resp.StatusCode, resp)
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"NEMBUS/internal/middleware"
 	"NEMBUS/internal/repository"
@@ -41,17 +43,21 @@ func (h *NavigationHandler) getRepositoryFromContext(c *gin.Context) *repository
 
 // GetUserNavigation handles GET /api/navigation/user/:user_id
 // @Summary      Get user navigation
-// @Description  Returns the complete navigation structure for a specific user including modules, menus, submenus with permissions and UI settings
+// @Description  Returns the complete navigation structure for a specific user including modules, menus, submenus with permissions and UI settings. Reading another user's navigation requires the users.view_any permission; users read their own from /api/me/navigation. The response carries an ETag; send it back in If-None-Match to get 304 Not Modified while the navigation is unchanged.
 // @Tags         navigation
 // @Accept       json
 // @Produce      json
 // @Security     BearerAuth
 // @Param        x-tenant-id     header   string  true  "Tenant identifier"
 // @Param        Authorization  header   string  true  "Bearer token"
+// @Param        If-None-Match  header   string  false "ETag of the navigation the client has"
 // @Param        user_id        path     int     true  "User ID"
 // @Success      200  {object}  SuccessResponse
+// @Success      304  "Navigation not modified"
 // @Failure      400  {object}  ErrorResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /api/navigation/user/{user_id} [get]
 func (h *NavigationHandler) GetUserNavigation(c *gin.Context) {
@@ -75,12 +81,56 @@ func (h *NavigationHandler) GetUserNavigation(c *gin.Context) {
 	}
 
 	// Call usecase
-	resp := h.useCase.GetUserNavigation(c.Request.Context(), int32(userID))
+	resp := h.useCase.GetUserNavigation(c.Request.Context(), currentUserID(c), int32(userID))
+	writeNavigation(c, resp)
+}
 
-	// Respond with the response from use case
+// writeNavigation writes a navigation response with its ETag, or 304 when
+// the client's If-None-Match still matches.
+func writeNavigation(c *gin.Context, resp *repository.Response) {
+	nav, ok := resp.Data.(*usecase.CachedNavigation)
+	if resp.StatusCode != utils.CodeOK || !ok {
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	// Let the client reuse its copy while the navigation is unchanged
+	c.Header("ETag", nav.ETag)
+	c.Header("Cache-Control", "private, no-cache")
+	if etagMatches(c.GetHeader("If-None-Match"), nav.ETag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.JSON(resp.StatusCode, utils.NewResponse(resp.StatusCode, resp.Message, nav.Navigation))
+}
+
+// GetNavigationCacheStats handles GET /api/navigation/cache/stats
+// @Summary      Navigation cache statistics
+// @Description  Returns the hits, misses, hit rate, invalidations and entries of this server's navigation cache since it started
+// @Tags         navigation
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header   string  true  "Tenant identifier"
+// @Param        Authorization  header   string  true  "Bearer token"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Router       /api/navigation/cache/stats [get]
+func (h *NavigationHandler) GetNavigationCacheStats(c *gin.Context) {
+	resp := h.useCase.GetNavigationCacheStats(c.Request.Context())
 	c.JSON(resp.StatusCode, resp)
 }
 
+// etagMatches reports whether an If-None-Match header lists etag.
+func etagMatches(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == etag || tag == "*" {
+			return true
+		}
+	}
+	return false
+}
+
 // GetNavigationByRoleCode handles GET /api/navigation/role/:role_code
 // @Summary      Get navigation by role
 // @Description  Returns the complete navigation structure for a specific role including modules, menus, submenus with permissions, UI settings, and the number of users assigned to this role
@@ -166,3 +216,84 @@ func (h *NavigationHandler) GetNavigationByRoleCodeWithUserCounts(c *gin.Context
 		responseData,
 	))
 }
+
+// ExportNavigationBundle handles GET /api/navigation/bundle
+// @Summary      Export the navigation bundle
+// @Description  Downloads the tenant's modules, menus, submenus, their permission mappings and the permissions they reference as a JSON or YAML bundle. Everything is keyed by code, so the bundle can be applied to another tenant.
+// @Tags         navigation
+// @Produce      json
+// @Produce      application/yaml
+// @Security     BearerAuth
+// @Param        x-tenant-id    header   string  true   "Tenant identifier"
+// @Param        Authorization  header   string  true   "Bearer token"
+// @Param        format         query    string  false  "json or yaml (default json)"
+// @Success      200  {file}    file
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/navigation/bundle [get]
+func (h *NavigationHandler) ExportNavigationBundle(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.ExportNavigationBundle(c.Request.Context(), c.Query("format"))
+	out, ok := resp.Data.(*usecase.NavigationBundleFile)
+	if resp.StatusCode != utils.CodeOK || !ok {
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", out.Filename))
+	c.Data(http.StatusOK, out.ContentType, out.Body)
+}
+
+// ApplyNavigationBundle handles POST /api/navigation/bundle
+// @Summary      Apply a navigation bundle
+// @Description  Makes the tenant's navigation match a bundle in one transaction, matching by code: permissions, modules, menus and submenus are created or updated, modules, menus and submenus missing from the bundle are deactivated, and module, menu and submenu permission mappings are granted and revoked to match. Menus and submenus name their parent by code; a parent must be listed before its children. With dry_run the changes are only listed; applying needs the permissions.manage and roles.manage_system permissions. The body is JSON, or YAML when format=yaml or the Content-Type mentions yaml.
+// @Tags         navigation
+// @Accept       json
+// @Accept       application/yaml
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header   string  true   "Tenant identifier"
+// @Param        Authorization  header   string  true   "Bearer token"
+// @Param        format         query    string  false  "json or yaml (default from Content-Type)"
+// @Param        dry_run        query    bool    false  "List the changes without applying them"
+// @Param        bundle         body     usecase.NavigationBundle  true  "Navigation bundle"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/navigation/bundle [post]
+func (h *NavigationHandler) ApplyNavigationBundle(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	format := c.Query("format")
+	if format == "" {
+		format = usecase.NavigationBundleJSON
+		if strings.Contains(c.ContentType(), "yaml") {
+			format = usecase.NavigationBundleYAML
+		}
+	}
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "cannot read body", nil))
+		return
+	}
+	bundle, err := usecase.ParseNavigationBundle(format, body)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	dryRun := c.Query("dry_run") == "true" || c.Query("dry_run") == "1"
+
+	resp := h.useCase.ApplyNavigationBundle(c.Request.Context(), currentUserID(c), bundle, dryRun)
+	c.JSON(resp.StatusCode, resp)
+}
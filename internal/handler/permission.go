@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
 
@@ -37,7 +38,7 @@ func (h *PermissionHandler) getRepositoryFromContext(c *gin.Context) *repository
 
 // CheckUserSubmenuPermission handles GET /api/permissions/user/:user_id/submenu/:submenu_code
 // @Summary      Check user submenu permission
-// @Description  Checks if a user has access to a specific submenu by submenu code
+// @Description  Checks if a user has access to a specific submenu by submenu code. Checking another user requires the users.view_any permission; users check themselves with /api/me/permissions/submenu/{submenu_code}.
 // @Tags         permissions
 // @Accept       json
 // @Produce      json
@@ -49,6 +50,7 @@ func (h *PermissionHandler) getRepositoryFromContext(c *gin.Context) *repository
 // @Success      200  {object}  SuccessResponse
 // @Failure      400  {object}  ErrorResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      404  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /api/permissions/user/{user_id}/submenu/{submenu_code} [get]
@@ -84,8 +86,379 @@ func (h *PermissionHandler) CheckUserSubmenuPermission(c *gin.Context) {
 	}
 
 	// Call usecase
-	resp := h.useCase.CheckUserSubmenuPermission(c.Request.Context(), int32(userID), submenuCode)
+	resp := h.useCase.CheckUserSubmenuPermission(c.Request.Context(), currentUserID(c), int32(userID), submenuCode)
 
 	// Respond with the response from use case
 	c.JSON(resp.StatusCode, resp)
 }
+
+// CreatePermission handles POST /api/permissions
+// @Summary      Create a permission
+// @Description  Creates a permission. Requires the permissions.manage permission.
+// @Tags         permissions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string                           true  "Tenant identifier"
+// @Param        Authorization  header  string                           true  "Bearer token"
+// @Param        permission     body    handler.CreatePermissionRequest  true  "Permission data"
+// @Success      201  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/permissions [post]
+func (h *PermissionHandler) CreatePermission(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req struct {
+		Name        string      `json:"name" binding:"required"`
+		Code        string      `json:"code" binding:"required"`
+		Description *string     `json:"description"`
+		Metadata    interface{} `json:"metadata"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid request body", err.Error())
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+	metadataBytes, err := json.Marshal(req.Metadata)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, utils.NewResponse(utils.CodeError, "failed to process metadata", nil))
+		return
+	}
+
+	resp := h.useCase.CreatePermission(c.Request.Context(), currentUserID(c), req.Name, req.Code, req.Description, metadataBytes)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListPermissions handles GET /api/permissions
+// @Summary      List permissions
+// @Description  Lists permissions by name
+// @Tags         permissions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string  true   "Tenant identifier"
+// @Param        Authorization  header  string  true   "Bearer token"
+// @Param        limit          query   int     false  "Page size (default 100)"
+// @Param        offset         query   int     false  "Offset (default 0)"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/permissions [get]
+func (h *PermissionHandler) ListPermissions(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	if err != nil {
+		offset = 0
+	}
+
+	resp := h.useCase.ListPermissions(c.Request.Context(), int32(limit), int32(offset))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetPermission handles GET /api/permissions/:id
+// @Summary      Get a permission
+// @Description  Returns a permission by ID
+// @Tags         permissions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string  true  "Tenant identifier"
+// @Param        Authorization  header  string  true  "Bearer token"
+// @Param        id             path    int     true  "Permission ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Router       /api/permissions/{id} [get]
+func (h *PermissionHandler) GetPermission(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.GetPermission(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdatePermission handles PUT /api/permissions/:id
+// @Summary      Update a permission
+// @Description  Changes the name, description or metadata of a permission; omitted fields are unchanged and the code cannot change. Requires the permissions.manage permission.
+// @Tags         permissions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string                           true  "Tenant identifier"
+// @Param        Authorization  header  string                           true  "Bearer token"
+// @Param        id             path    int                              true  "Permission ID"
+// @Param        permission     body    handler.UpdatePermissionRequest  true  "Permission data"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/permissions/{id} [put]
+func (h *PermissionHandler) UpdatePermission(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	var req struct {
+		Name        *string     `json:"name"`
+		Description *string     `json:"description"`
+		Metadata    interface{} `json:"metadata"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid request body", err.Error())
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+	var metadataBytes []byte
+	if req.Metadata != nil {
+		b, err := json.Marshal(req.Metadata)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, utils.NewResponse(utils.CodeError, "failed to process metadata", nil))
+			return
+		}
+		metadataBytes = b
+	}
+
+	resp := h.useCase.UpdatePermission(c.Request.Context(), currentUserID(c), id, req.Name, req.Description, metadataBytes)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeletePermission handles DELETE /api/permissions/:id
+// @Summary      Delete a permission
+// @Description  Deletes a permission with its grants to roles and its mappings to modules, menus and submenus. Permissions the application checks itself (users.view_any, inventory.batch_override, permissions.manage) cannot be deleted. Requires the permissions.manage permission.
+// @Tags         permissions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string  true  "Tenant identifier"
+// @Param        Authorization  header  string  true  "Bearer token"
+// @Param        id             path    int     true  "Permission ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/permissions/{id} [delete]
+func (h *PermissionHandler) DeletePermission(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DeletePermission(c.Request.Context(), currentUserID(c), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListNodePermissions handles GET /api/permissions/nodes/:kind/:node_id
+// @Summary      List the permissions of a navigation node
+// @Description  Lists the permissions required by a module, menu or submenu; roles granted any of them see it in their navigation
+// @Tags         permissions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string  true  "Tenant identifier"
+// @Param        Authorization  header  string  true  "Bearer token"
+// @Param        kind           path    string  true  "Node kind"  Enums(module, menu, submenu)
+// @Param        node_id        path    int     true  "Module, menu or submenu ID"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/permissions/nodes/{kind}/{node_id} [get]
+func (h *PermissionHandler) ListNodePermissions(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	nodeID, ok := pathID(c, "node_id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.ListNodePermissions(c.Request.Context(), c.Param("kind"), nodeID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// AttachNodePermissions handles POST /api/permissions/nodes/:kind/:node_id
+// @Summary      Attach permissions to a navigation node
+// @Description  Maps permissions to a module, menu or submenu; permissions already mapped are skipped. Requires the permissions.manage permission.
+// @Tags         permissions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string                          true  "Tenant identifier"
+// @Param        Authorization  header  string                          true  "Bearer token"
+// @Param        kind           path    string                          true  "Node kind"  Enums(module, menu, submenu)
+// @Param        node_id        path    int                             true  "Module, menu or submenu ID"
+// @Param        body           body    handler.NodePermissionsRequest  true  "Permission IDs"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/permissions/nodes/{kind}/{node_id} [post]
+func (h *PermissionHandler) AttachNodePermissions(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	nodeID, ok := pathID(c, "node_id")
+	if !ok {
+		return
+	}
+
+	var req NodePermissionsRequest
+	if err := c.BindJSON(&req); err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid request body", err.Error())
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	resp := h.useCase.AttachNodePermissions(c.Request.Context(), currentUserID(c), c.Param("kind"), nodeID, req.PermissionIDs)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DetachNodePermissions handles DELETE /api/permissions/nodes/:kind/:node_id
+// @Summary      Detach permissions from a navigation node
+// @Description  Removes permissions from a module, menu or submenu; permissions not mapped to it are ignored. Requires the permissions.manage permission.
+// @Tags         permissions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string                          true  "Tenant identifier"
+// @Param        Authorization  header  string                          true  "Bearer token"
+// @Param        kind           path    string                          true  "Node kind"  Enums(module, menu, submenu)
+// @Param        node_id        path    int                             true  "Module, menu or submenu ID"
+// @Param        body           body    handler.NodePermissionsRequest  true  "Permission IDs"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/permissions/nodes/{kind}/{node_id} [delete]
+func (h *PermissionHandler) DetachNodePermissions(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	nodeID, ok := pathID(c, "node_id")
+	if !ok {
+		return
+	}
+
+	var req NodePermissionsRequest
+	if err := c.BindJSON(&req); err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid request body", err.Error())
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	resp := h.useCase.DetachNodePermissions(c.Request.Context(), currentUserID(c), c.Param("kind"), nodeID, req.PermissionIDs)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetPermissionMatrix handles GET /api/permissions/matrix
+// @Summary      Get the role x permission matrix
+// @Description  Returns every role, every permission and the grants between them with their scope
+// @Tags         permissions
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string  true  "Tenant identifier"
+// @Param        Authorization  header  string  true  "Bearer token"
+// @Success      200  {object}  SuccessResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/permissions/matrix [get]
+func (h *PermissionHandler) GetPermissionMatrix(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.GetPermissionMatrix(c.Request.Context())
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdatePermissionMatrix handles PATCH /api/permissions/matrix
+// @Summary      Bulk grant and revoke role permissions
+// @Description  Grants and revokes permissions of roles in one transaction and returns the resulting matrix. Granting a permission a role already has changes its scope when one is given; revoking one it doesn't have is ignored. Requires the permissions.manage permission, and roles.manage_system to change system roles or grant users.manage, permissions.manage, roles.manage_system, stores.access_any or stores.access_manage.
+// @Tags         permissions
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header  string                                 true  "Tenant identifier"
+// @Param        Authorization  header  string                                 true  "Bearer token"
+// @Param        body           body    handler.UpdatePermissionMatrixRequest  true  "Grants and revokes"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/permissions/matrix [patch]
+func (h *PermissionHandler) UpdatePermissionMatrix(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req struct {
+		Grant  []usecase.PermissionGrant `json:"grant"`
+		Revoke []usecase.PermissionGrant `json:"revoke"`
+	}
+	if err := c.BindJSON(&req); err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid request body", err.Error())
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	resp := h.useCase.UpdatePermissionMatrix(c.Request.Context(), currentUserID(c), req.Grant, req.Revoke)
+	c.JSON(resp.StatusCode, resp)
+}
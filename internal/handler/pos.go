@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/receipt"
 	"NEMBUS/internal/repository"
 	"NEMBUS/internal/usecase"
 	"NEMBUS/utils"
@@ -45,9 +47,11 @@ func (h *PosHandler) getRepositoryFromContext(c *gin.Context) *repository.Querie
 // @Param        category_id           query     int     false  "Filter by category ID"
 // @Param        search_term           query     string  false  "Filter by name, SKU, or barcode"
 // @Param        include_out_of_stock  query     bool    false  "Include out-of-stock products (default false)"
+// @Param        customer_id           query     int     false  "Resolve prices for this customer"
 // @Success      200                   {object}  SuccessResponse
 // @Failure      400                   {object}  ErrorResponse
 // @Failure      401                   {object}  ErrorResponse
+// @Failure      403                   {object}  ErrorResponse
 // @Failure      404                   {object}  ErrorResponse
 // @Failure      500                   {object}  ErrorResponse
 // @Router       /api/pos/stores/{store_id}/products [get]
@@ -79,8 +83,12 @@ func (h *PosHandler) ListProducts(c *gin.Context) {
 		searchTerm = &s
 	}
 	includeOutOfStock := c.Query("include_out_of_stock") == "true" || c.Query("include_out_of_stock") == "1"
+	customerID, ok := optionalQueryID(c, "customer_id")
+	if !ok {
+		return
+	}
 
-	resp := h.useCase.ListProductsForStore(c.Request.Context(), int32(storeID), categoryID, searchTerm, includeOutOfStock)
+	resp := h.useCase.ListProductsForStore(c.Request.Context(), int32(storeID), customerID, categoryID, searchTerm, includeOutOfStock)
 	c.JSON(resp.StatusCode, resp)
 }
 
@@ -96,9 +104,11 @@ func (h *PosHandler) ListProducts(c *gin.Context) {
 // @Param        store_id               path      int     true   "Store ID"
 // @Param        category_id            path      int     true   "Category ID"
 // @Param        include_subcategories  query     bool    false  "Include subcategories (default true)"
+// @Param        customer_id            query     int     false  "Resolve prices for this customer"
 // @Success      200                    {object}  SuccessResponse
 // @Failure      400                    {object}  ErrorResponse
 // @Failure      401                    {object}  ErrorResponse
+// @Failure      403                    {object}  ErrorResponse
 // @Failure      404                    {object}  ErrorResponse
 // @Failure      500                    {object}  ErrorResponse
 // @Router       /api/pos/stores/{store_id}/products/category/{category_id} [get]
@@ -120,14 +130,18 @@ func (h *PosHandler) GetProductsByCategory(c *gin.Context) {
 		return
 	}
 	includeSubcategories := c.Query("include_subcategories") != "false" && c.Query("include_subcategories") != "0"
+	customerID, ok := optionalQueryID(c, "customer_id")
+	if !ok {
+		return
+	}
 
-	resp := h.useCase.GetProductsByCategory(c.Request.Context(), int32(storeID), int32(categoryID), includeSubcategories)
+	resp := h.useCase.GetProductsByCategory(c.Request.Context(), int32(storeID), customerID, int32(categoryID), includeSubcategories)
 	c.JSON(resp.StatusCode, resp)
 }
 
 // SearchProduct handles GET /api/pos/stores/:store_id/products/search
 // @Summary      Search POS product by barcode, ID, or name
-// @Description  Searches by barcode (exact), product ID (exact), or name/SKU (fuzzy). Returns single product or list of matches.
+// @Description  Searches by barcode (exact), product ID (exact), or name/SKU (fuzzy). Returns single product or list of matches. Scale barcodes matching a barcode rule (prefix 2x with embedded weight, price or count) and GS1 codes (GS1-128/DataMatrix with FNC1 separators or bracketed AIs) resolve their product and add a scan object with the pre-filled quantity, line_total, batch_number, serial_number and expiry_date. GTINs also match products stored under their EAN-13/UPC-A forms.
 // @Tags         pos
 // @Accept       json
 // @Produce      json
@@ -140,6 +154,7 @@ func (h *PosHandler) GetProductsByCategory(c *gin.Context) {
 // @Success      200           {object}  SuccessResponse
 // @Failure      400           {object}  ErrorResponse
 // @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
 // @Failure      404           {object}  ErrorResponse
 // @Failure      500           {object}  ErrorResponse
 // @Router       /api/pos/stores/{store_id}/products/search [get]
@@ -247,3 +262,346 @@ func (h *PosHandler) AddProduct(c *gin.Context) {
 	resp := h.useCase.AddProduct(c.Request.Context(), input)
 	c.JSON(resp.StatusCode, resp)
 }
+
+// GetReceipt handles GET /api/pos/transactions/:number/receipt
+// @Summary      Render POS receipt
+// @Description  Renders the receipt for a POS transaction as plain text, an ESC/POS byte stream or a PDF. Arabic text is shaped and laid out right to left in every format. The store header comes from the store metadata (address, phone, vat_number, cr_number, name_ar, address_ar).
+// @Tags         pos
+// @Produce      plain
+// @Produce      octet-stream
+// @Produce      application/pdf
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true   "Tenant identifier"
+// @Param        Authorization header    string  true   "Bearer token"
+// @Param        number        path      string  true   "Transaction number"
+// @Param        format        query     string  false  "text, escpos or pdf (default text)"
+// @Param        template      query     string  false  "Receipt template code (default: tenant default template)"
+// @Param        lang          query     string  false  "Override template language: en, ar or bilingual"
+// @Success      200           {file}    file
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/pos/transactions/{number}/receipt [get]
+func (h *PosHandler) GetReceipt(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	format, err := receipt.ParseFormat(c.Query("format"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.RenderReceipt(c.Request.Context(), c.Param("number"), format, c.Query("template"), c.Query("lang"))
+	out, ok := resp.Data.(*receipt.Output)
+	if resp.StatusCode != utils.CodeOK || !ok {
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", out.Filename))
+	c.Data(http.StatusOK, out.ContentType, out.Body)
+}
+
+// ListReceiptTemplates handles GET /api/pos/receipt-templates
+// @Summary      List receipt templates
+// @Description  Returns the tenant's receipt templates, default first
+// @Tags         pos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true   "Tenant identifier"
+// @Param        Authorization header    string  true   "Bearer token"
+// @Success      200           {object}  SuccessResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/pos/receipt-templates [get]
+func (h *PosHandler) ListReceiptTemplates(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.ListReceiptTemplates(c.Request.Context())
+	c.JSON(resp.StatusCode, resp)
+}
+
+// SaveReceiptTemplate handles PUT /api/pos/receipt-templates/:code
+// @Summary      Create or replace receipt template
+// @Description  Creates or replaces the receipt template with the given code. Setting is_default makes it the tenant default.
+// @Tags         pos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string                      true  "Tenant identifier"
+// @Param        Authorization header    string                      true  "Bearer token"
+// @Param        code          path      string                      true  "Template code"
+// @Param        body          body      SaveReceiptTemplateRequest  true  "Template payload"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/pos/receipt-templates/{code} [put]
+func (h *PosHandler) SaveReceiptTemplate(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req SaveReceiptTemplateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.SaveReceiptTemplate(c.Request.Context(), &usecase.ReceiptTemplateInput{
+		Code:             c.Param("code"),
+		Name:             req.Name,
+		Language:         req.Language,
+		PaperWidth:       req.PaperWidth,
+		HeaderLines:      req.HeaderLines,
+		FooterLines:      req.FooterLines,
+		ShowTaxBreakdown: req.ShowTaxBreakdown,
+		ShowBarcode:      req.ShowBarcode,
+		CodePage:         req.CodePage,
+		IsDefault:        req.IsDefault,
+		IsActive:         req.IsActive,
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListBarcodeRules handles GET /api/pos/barcode-rules
+// @Summary      List barcode rules
+// @Description  Returns the tenant's variable-measure barcode rules: the layouts of in-store EAN-13 barcodes with an embedded weight, price or count
+// @Tags         pos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Success      200           {object}  SuccessResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/pos/barcode-rules [get]
+func (h *PosHandler) ListBarcodeRules(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.ListBarcodeRules(c.Request.Context())
+	c.JSON(resp.StatusCode, resp)
+}
+
+// SaveBarcodeRule handles PUT /api/pos/barcode-rules/:prefix
+// @Summary      Create or replace barcode rule
+// @Description  Creates or replaces the variable-measure barcode rule for a prefix (20-29, or three digits starting with 2). Prefix, item digits, value digits, the optional value check digit and the final check digit must add up to 13.
+// @Tags         pos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string                  true  "Tenant identifier"
+// @Param        Authorization header    string                  true  "Bearer token"
+// @Param        prefix        path      string                  true  "Barcode prefix"
+// @Param        body          body      SaveBarcodeRuleRequest  true  "Rule payload"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/pos/barcode-rules/{prefix} [put]
+func (h *PosHandler) SaveBarcodeRule(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req SaveBarcodeRuleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.SaveBarcodeRule(c.Request.Context(), &usecase.BarcodeRuleInput{
+		Prefix:          c.Param("prefix"),
+		Name:            req.Name,
+		ValueType:       req.ValueType,
+		ItemDigits:      req.ItemDigits,
+		ValueDigits:     req.ValueDigits,
+		ValueDecimals:   req.ValueDecimals,
+		ValueCheckDigit: req.ValueCheckDigit,
+		IsActive:        req.IsActive,
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteBarcodeRule handles DELETE /api/pos/barcode-rules/:prefix
+// @Summary      Delete barcode rule
+// @Description  Deletes the variable-measure barcode rule for a prefix
+// @Tags         pos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        prefix        path      string  true  "Barcode prefix"
+// @Success      200           {object}  SuccessResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/pos/barcode-rules/{prefix} [delete]
+func (h *PosHandler) DeleteBarcodeRule(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.DeleteBarcodeRule(c.Request.Context(), c.Param("prefix"))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// Checkout handles POST /api/pos/checkout
+// @Summary      Complete POS sale
+// @Description  Prices the cart from the customer's (or default) price list, computes inclusive/exclusive tax per product tax category with the organization's rounding mode, validates payments and records the sale on the signed-in user's open cashier session; cashier_id, if given, must be the user's cashier, and the user needs access to the terminal's store. Tax-exempt customers (customers.metadata tax_exempt) are zero-rated. Serialized products need one in-stock serial number per unit (serial_number or serial_numbers); each unit is recorded on its own line and marked sold. Batch-managed products are taken from batches first-expiry-first-out (expired batches are never sold); a line's batch_number picks the batch instead and requires the inventory.batch_override permission.
+// @Tags         pos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string              true  "Tenant identifier"
+// @Param        Authorization header    string              true  "Bearer token"
+// @Param        body          body      PosCheckoutRequest  true  "Cart and payments"
+// @Success      201           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/pos/checkout [post]
+func (h *PosHandler) Checkout(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req PosCheckoutRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	input := &usecase.PosCheckoutInput{
+		UserID:     currentUserID(c),
+		CashierID:  req.CashierID,
+		CustomerID: req.CustomerID,
+	}
+	for _, l := range req.Lines {
+		input.Lines = append(input.Lines, usecase.PosCheckoutLine{
+			ProductID:        l.ProductID,
+			ProductVariantID: l.ProductVariantID,
+			Quantity:         l.Quantity,
+			Discount:         l.Discount,
+			SerialNumber:     l.SerialNumber,
+			SerialNumbers:    l.SerialNumbers,
+			BatchNumber:      l.BatchNumber,
+		})
+	}
+	for _, p := range req.Payments {
+		input.Payments = append(input.Payments, usecase.PosCheckoutPayment{
+			Method:    p.Method,
+			Amount:    p.Amount,
+			Reference: p.Reference,
+		})
+	}
+
+	resp := h.useCase.Checkout(c.Request.Context(), input)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ReturnSale handles POST /api/pos/returns
+// @Summary      Return items of a POS sale
+// @Description  Records a return against a completed sale on the signed-in user's open cashier session (cashier_id, if given, must be the user's cashier, and the user needs access to the terminal's store) and refunds the lines' share of the original amounts. Quantities already returned cannot be returned again. Serialized products need the unit's serial_number, which moves from sold to returned (restock) or rma. Restocked goods go back into stock and their batch; rma goods do not.
+// @Tags         pos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string            true  "Tenant identifier"
+// @Param        Authorization header    string            true  "Bearer token"
+// @Param        body          body      PosReturnRequest  true  "Sale and returned lines"
+// @Success      201           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/pos/returns [post]
+func (h *PosHandler) ReturnSale(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req PosReturnRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	input := &usecase.PosReturnInput{
+		UserID:            currentUserID(c),
+		CashierID:         req.CashierID,
+		TransactionNumber: req.TransactionNumber,
+		RefundMethod:      req.RefundMethod,
+		Reason:            req.Reason,
+	}
+	for _, l := range req.Lines {
+		input.Lines = append(input.Lines, usecase.PosReturnLine{
+			LineNumber:   l.LineNumber,
+			SerialNumber: l.SerialNumber,
+			Quantity:     l.Quantity,
+			Disposition:  l.Disposition,
+		})
+	}
+
+	resp := h.useCase.ReturnSale(c.Request.Context(), input)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// optionalQueryID reads an optional integer query parameter. On a malformed
+// value it writes a 400 response and returns ok=false.
+func optionalQueryID(c *gin.Context, name string) (*int32, bool) {
+	s := c.Query(name)
+	if s == "" {
+		return nil, true
+	}
+	id, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid "+name, nil))
+		return nil, false
+	}
+	v := int32(id)
+	return &v, true
+}
+
+// organizationParam parses an optional organization_id; 0 means the active
+// organization of the request. It writes a 400 response when s is invalid.
+func organizationParam(c *gin.Context, s string) (int32, bool) {
+	if s == "" {
+		return 0, true
+	}
+	id, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid organization_id", nil))
+		return 0, false
+	}
+	return int32(id), true
+}
@@ -0,0 +1,627 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PriceListHandler holds the price list use case.
+type PriceListHandler struct {
+	useCase *usecase.PriceListUseCase
+}
+
+// NewPriceListHandler creates a new price list handler.
+func NewPriceListHandler(uc *usecase.PriceListUseCase) *PriceListHandler {
+	return &PriceListHandler{useCase: uc}
+}
+
+func (h *PriceListHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// pathID parses an integer path parameter, writing a 400 response when it
+// is malformed.
+func pathID(c *gin.Context, name string) (int32, bool) {
+	id, err := strconv.ParseInt(c.Param(name), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid "+name, nil))
+		return 0, false
+	}
+	return int32(id), true
+}
+
+// ListPriceLists handles GET /api/price-lists
+// @Summary      List price lists
+// @Description  Returns price lists. active=true keeps active lists; valid=true keeps active lists valid today.
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true   "Tenant identifier"
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        active         query     bool    false  "Only active lists"
+// @Param        valid          query     bool    false  "Only lists valid today"
+// @Success      200            {object}  SuccessResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists [get]
+func (h *PriceListHandler) ListPriceLists(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	active := c.Query("active") == "true" || c.Query("active") == "1"
+	valid := c.Query("valid") == "true" || c.Query("valid") == "1"
+	resp := h.useCase.ListPriceLists(c.Request.Context(), active, valid)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetPriceList handles GET /api/price-lists/:id
+// @Summary      Get price list
+// @Description  Returns a price list
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Price list ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Router       /api/price-lists/{id} [get]
+func (h *PriceListHandler) GetPriceList(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.GetPriceList(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CreatePriceList handles POST /api/price-lists
+// @Summary      Create price list
+// @Description  Creates a price list. A list created as default replaces the previous default.
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string            true  "Tenant identifier"
+// @Param        Authorization  header    string            true  "Bearer token"
+// @Param        body           body      PriceListRequest  true  "Price list payload"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists [post]
+func (h *PriceListHandler) CreatePriceList(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	in, ok := priceListInput(c)
+	if !ok {
+		return
+	}
+	resp := h.useCase.CreatePriceList(c.Request.Context(), in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdatePriceList handles PUT /api/price-lists/:id
+// @Summary      Update price list
+// @Description  Updates a price list; the code cannot change
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string            true  "Tenant identifier"
+// @Param        Authorization  header    string            true  "Bearer token"
+// @Param        id             path      int               true  "Price list ID"
+// @Param        body           body      PriceListRequest  true  "Price list payload"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists/{id} [put]
+func (h *PriceListHandler) UpdatePriceList(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	in, ok := priceListInput(c)
+	if !ok {
+		return
+	}
+	resp := h.useCase.UpdatePriceList(c.Request.Context(), id, in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeletePriceList handles DELETE /api/price-lists/:id
+// @Summary      Delete price list
+// @Description  Deletes a price list and its prices
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Price list ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists/{id} [delete]
+func (h *PriceListHandler) DeletePriceList(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.DeletePriceList(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// SetDefaultPriceList handles PUT /api/price-lists/:id/default
+// @Summary      Set default price list
+// @Description  Makes an active price list the tenant's default
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Price list ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists/{id}/default [put]
+func (h *PriceListHandler) SetDefaultPriceList(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.SetDefaultPriceList(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ExpirePriceList handles POST /api/price-lists/:id/expire
+// @Summary      Expire price list prices
+// @Description  Ends every running price of the list as of yesterday
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Price list ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists/{id}/expire [post]
+func (h *PriceListHandler) ExpirePriceList(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.ExpirePriceList(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListPrices handles GET /api/price-lists/:id/prices
+// @Summary      List price list prices
+// @Description  Returns the active price rows of a price list, including scheduled and ended versions
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Price list ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists/{id}/prices [get]
+func (h *PriceListHandler) ListPrices(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.ListPrices(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CreateProductPrice handles POST /api/price-lists/:id/prices
+// @Summary      Add product price
+// @Description  Adds a price row to the list as given (variant, unit, quantity tier and validity are optional). Use the schedule endpoint to replace a running price from a date.
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string               true  "Tenant identifier"
+// @Param        Authorization  header    string               true  "Bearer token"
+// @Param        id             path      int                  true  "Price list ID"
+// @Param        body           body      ProductPriceRequest  true  "Price payload"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists/{id}/prices [post]
+func (h *PriceListHandler) CreateProductPrice(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	in, ok := productPriceInput(c)
+	if !ok {
+		return
+	}
+	resp := h.useCase.CreateProductPrice(c.Request.Context(), id, in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// SchedulePriceChange handles POST /api/price-lists/:id/prices/schedule
+// @Summary      Schedule price change
+// @Description  Sets a product price from valid_from (default today). The running version of the same price (variant, unit, min quantity) ends the day before; a version starting that day is repriced. Future changes activate automatically on their date.
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string               true  "Tenant identifier"
+// @Param        Authorization  header    string               true  "Bearer token"
+// @Param        id             path      int                  true  "Price list ID"
+// @Param        body           body      ProductPriceRequest  true  "Price payload"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists/{id}/prices/schedule [post]
+func (h *PriceListHandler) SchedulePriceChange(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	in, ok := productPriceInput(c)
+	if !ok {
+		return
+	}
+	resp := h.useCase.SchedulePriceChange(c.Request.Context(), id, in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// AdjustPrices handles POST /api/price-lists/:id/prices/adjust
+// @Summary      Bulk adjust prices
+// @Description  Raises or lowers the list's prices by a percentage or a fixed amount, optionally only for a category or brand. With a future effective_from the change is scheduled as new price versions; otherwise running prices change now.
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                  true  "Tenant identifier"
+// @Param        Authorization  header    string                  true  "Bearer token"
+// @Param        id             path      int                     true  "Price list ID"
+// @Param        body           body      PriceAdjustmentRequest  true  "Adjustment payload"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/price-lists/{id}/prices/adjust [post]
+func (h *PriceListHandler) AdjustPrices(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req PriceAdjustmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	from, err := parseOptionalDate("effective_from", req.EffectiveFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	resp := h.useCase.AdjustPrices(c.Request.Context(), id, &usecase.PriceAdjustmentInput{
+		Mode:          req.Mode,
+		Amount:        req.Amount,
+		CategoryID:    req.CategoryID,
+		BrandID:       req.BrandID,
+		EffectiveFrom: from,
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ImportPrices handles POST /api/price-lists/:id/prices/import
+// @Summary      Import prices
+// @Description  Imports prices from a CSV or XLSX file with a header row: sku, price (required), variant_sku, uom (unit code), min_quantity, max_quantity, valid_from, valid_to. Rows are validated against SKUs and units and reported with their line number. Each row is applied as a dated price change. A file with errors is rejected unless skip_invalid is set; dry_run only validates.
+// @Tags         price-lists
+// @Accept       multipart/form-data
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id      header    string  true   "Tenant identifier"
+// @Param        Authorization    header    string  true   "Bearer token"
+// @Param        id               path      int     true   "Price list ID"
+// @Param        file             formData  file    true   "CSV or XLSX file"
+// @Param        organization_id  formData  int     false  "Organization owning the SKUs; the active organization when omitted"
+// @Param        dry_run          formData  bool    false  "Validate only"
+// @Param        skip_invalid     formData  bool    false  "Import valid rows even if some rows fail"
+// @Success      200              {object}  SuccessResponse
+// @Failure      400              {object}  ErrorResponse
+// @Failure      401              {object}  ErrorResponse
+// @Failure      404              {object}  ErrorResponse
+// @Failure      500              {object}  ErrorResponse
+// @Router       /api/price-lists/{id}/prices/import [post]
+func (h *PriceListHandler) ImportPrices(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	orgID, ok := organizationParam(c, c.PostForm("organization_id"))
+	if !ok {
+		return
+	}
+	fh, err := c.FormFile("file")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "file is required", nil))
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "cannot read file", nil))
+		return
+	}
+	defer f.Close()
+
+	resp := h.useCase.ImportPrices(c.Request.Context(), id, fh.Filename, f, usecase.PriceImportOptions{
+		OrganizationID: orgID,
+		DryRun:         c.PostForm("dry_run") == "true" || c.PostForm("dry_run") == "1",
+		SkipInvalid:    c.PostForm("skip_invalid") == "true" || c.PostForm("skip_invalid") == "1",
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateProductPrice handles PUT /api/product-prices/:id
+// @Summary      Update product price
+// @Description  Updates one price row; empty fields are kept
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                     true  "Tenant identifier"
+// @Param        Authorization  header    string                     true  "Bearer token"
+// @Param        id             path      int                        true  "Product price ID"
+// @Param        body           body      UpdateProductPriceRequest  true  "Price payload"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/product-prices/{id} [put]
+func (h *PriceListHandler) UpdateProductPrice(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req UpdateProductPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	in := &usecase.ProductPriceInput{Price: req.Price, MinQuantity: req.MinQuantity, MaxQuantity: req.MaxQuantity}
+	var err error
+	if in.ValidFrom, err = parseOptionalDate("valid_from", req.ValidFrom); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	if in.ValidTo, err = parseOptionalDate("valid_to", req.ValidTo); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	resp := h.useCase.UpdateProductPrice(c.Request.Context(), id, in, req.IsActive)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeleteProductPrice handles DELETE /api/product-prices/:id
+// @Summary      Delete product price
+// @Description  Deletes one price row
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Product price ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/product-prices/{id} [delete]
+func (h *PriceListHandler) DeleteProductPrice(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.DeleteProductPrice(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetPriceComparison handles GET /api/products/:id/price-comparison
+// @Summary      Compare product prices
+// @Description  Returns a product's prices across all active price lists
+// @Tags         price-lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Product ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/products/{id}/price-comparison [get]
+func (h *PriceListHandler) GetPriceComparison(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.GetPriceComparison(c.Request.Context(), id)
+	c.JSON(resp.StatusCode, resp)
+}
+
+func priceListInput(c *gin.Context) (*usecase.PriceListInput, bool) {
+	var req PriceListRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return nil, false
+	}
+	in := &usecase.PriceListInput{
+		Name:          req.Name,
+		Code:          req.Code,
+		PriceListType: req.PriceListType,
+		CurrencyCode:  req.CurrencyCode,
+		IsDefault:     req.IsDefault,
+		IsActive:      req.IsActive,
+	}
+	var err error
+	if in.ValidFrom, err = parseOptionalDate("valid_from", req.ValidFrom); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return nil, false
+	}
+	if in.ValidTo, err = parseOptionalDate("valid_to", req.ValidTo); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return nil, false
+	}
+	return in, true
+}
+
+func productPriceInput(c *gin.Context) (*usecase.ProductPriceInput, bool) {
+	var req ProductPriceRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return nil, false
+	}
+	in := &usecase.ProductPriceInput{
+		ProductID:        req.ProductID,
+		ProductVariantID: req.ProductVariantID,
+		UomID:            req.UomID,
+		Price:            req.Price,
+		MinQuantity:      req.MinQuantity,
+		MaxQuantity:      req.MaxQuantity,
+	}
+	var err error
+	if in.ValidFrom, err = parseOptionalDate("valid_from", req.ValidFrom); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return nil, false
+	}
+	if in.ValidTo, err = parseOptionalDate("valid_to", req.ValidTo); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return nil, false
+	}
+	return in, true
+}
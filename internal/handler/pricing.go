@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PricingHandler holds the pricing use case.
+type PricingHandler struct {
+	useCase *usecase.PricingUseCase
+}
+
+// NewPricingHandler creates a new pricing handler.
+func NewPricingHandler(uc *usecase.PricingUseCase) *PricingHandler {
+	return &PricingHandler{useCase: uc}
+}
+
+func (h *PricingHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// ResolvePrice handles GET /api/pricing/resolve
+// @Summary      Resolve price
+// @Description  Returns the price a sale would use for a product and explains the choice. Lists are tried in order customer > customer_type > store > promotion > default (by assignment priority within a source); inside a list the most specific row wins (variant, unit, quantity tier, latest valid_from). price_list_id restricts resolution to that list.
+// @Tags         pricing
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true   "Tenant identifier"
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        product_id     query     int     true   "Product ID"
+// @Param        variant_id     query     int     false  "Product variant ID"
+// @Param        store_id       query     int     false  "Store ID"
+// @Param        customer_id    query     int     false  "Customer ID"
+// @Param        price_list_id  query     int     false  "Only use this price list"
+// @Param        uom_id         query     int     false  "Selling unit (default base unit)"
+// @Param        quantity       query     string  false  "Quantity (default 1)"
+// @Param        date           query     string  false  "Sale date YYYY-MM-DD (default today)"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/pricing/resolve [get]
+func (h *PricingHandler) ResolvePrice(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	productID, err := strconv.ParseInt(c.Query("product_id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid product_id", nil))
+		return
+	}
+	in := &usecase.PriceQuery{ProductID: int32(productID), Quantity: c.Query("quantity")}
+	for name, dst := range map[string]**int32{
+		"variant_id":    &in.VariantID,
+		"store_id":      &in.StoreID,
+		"customer_id":   &in.CustomerID,
+		"price_list_id": &in.PriceListID,
+		"uom_id":        &in.UomID,
+	} {
+		v, ok := optionalQueryID(c, name)
+		if !ok {
+			return
+		}
+		*dst = v
+	}
+	if in.Date, err = parseOptionalDate("date", c.Query("date")); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.ResolvePrice(c.Request.Context(), in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListPriceListAssignments handles GET /api/pricing/assignments
+// @Summary      List price list assignments
+// @Description  Returns the assignments that make price lists apply to customers, customer types, stores and promotions
+// @Tags         pricing
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true   "Tenant identifier"
+// @Param        Authorization  header    string  true   "Bearer token"
+// @Param        scope          query     string  false  "customer, customer_type, store or promotion"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/pricing/assignments [get]
+func (h *PricingHandler) ListPriceListAssignments(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.ListPriceListAssignments(c.Request.Context(), c.Query("scope"))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CreatePriceListAssignment handles POST /api/pricing/assignments
+// @Summary      Assign price list
+// @Description  Makes a price list applicable to a customer, a customer type, a store, or as a promotion (optionally for one store), with a priority and optional validity dates
+// @Tags         pricing
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string                            true  "Tenant identifier"
+// @Param        Authorization  header    string                            true  "Bearer token"
+// @Param        body           body      CreatePriceListAssignmentRequest  true  "Assignment payload"
+// @Success      201            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      404            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/pricing/assignments [post]
+func (h *PricingHandler) CreatePriceListAssignment(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req CreatePriceListAssignmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	validFrom, err := parseOptionalDate("valid_from", req.ValidFrom)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	validTo, err := parseOptionalDate("valid_to", req.ValidTo)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.CreatePriceListAssignment(c.Request.Context(), &usecase.PriceListAssignmentInput{
+		PriceListID:  req.PriceListID,
+		Scope:        req.Scope,
+		CustomerID:   req.CustomerID,
+		CustomerType: req.CustomerType,
+		StoreID:      req.StoreID,
+		Priority:     req.Priority,
+		ValidFrom:    validFrom,
+		ValidTo:      validTo,
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DeletePriceListAssignment handles DELETE /api/pricing/assignments/:id
+// @Summary      Delete price list assignment
+// @Description  Removes a price list assignment
+// @Tags         pricing
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id    header    string  true  "Tenant identifier"
+// @Param        Authorization  header    string  true  "Bearer token"
+// @Param        id             path      int     true  "Assignment ID"
+// @Success      200            {object}  SuccessResponse
+// @Failure      400            {object}  ErrorResponse
+// @Failure      401            {object}  ErrorResponse
+// @Failure      500            {object}  ErrorResponse
+// @Router       /api/pricing/assignments/{id} [delete]
+func (h *PricingHandler) DeletePriceListAssignment(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid id", nil))
+		return
+	}
+
+	resp := h.useCase.DeletePriceListAssignment(c.Request.Context(), int32(id))
+	c.JSON(resp.StatusCode, resp)
+}
@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PurchaseOrderHandler holds the purchase order use case.
+type PurchaseOrderHandler struct {
+	useCase *usecase.PurchaseOrderUseCase
+}
+
+// NewPurchaseOrderHandler creates a new purchase order handler.
+func NewPurchaseOrderHandler(uc *usecase.PurchaseOrderUseCase) *PurchaseOrderHandler {
+	return &PurchaseOrderHandler{useCase: uc}
+}
+
+func (h *PurchaseOrderHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// CreatePurchaseOrder handles POST /api/purchase-orders
+// @Summary      Create purchase order
+// @Description  Creates a draft purchase order at supplier prices; input tax is computed per product tax category (inclusive/exclusive, organization rounding mode) and returned with a per-rate breakdown.
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string                      true  "Tenant identifier"
+// @Param        Authorization header    string                      true  "Bearer token"
+// @Param        body          body      CreatePurchaseOrderRequest  true  "Purchase order payload"
+// @Success      201           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/purchase-orders [post]
+func (h *PurchaseOrderHandler) CreatePurchaseOrder(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req CreatePurchaseOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	poDate, err := parseOptionalDate("po_date", req.PoDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	expected, err := parseOptionalDate("expected_delivery_date", req.ExpectedDeliveryDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.CreatePurchaseOrder(c.Request.Context(), &usecase.PurchaseOrderInput{
+		OrganizationID:       req.OrganizationID,
+		SupplierID:           req.SupplierID,
+		StoreID:              req.StoreID,
+		PoDate:               poDate,
+		ExpectedDeliveryDate: expected,
+		CreatedBy:            currentUserID(c),
+		Lines:                orderLineInputs(req.Lines),
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetPurchaseOrder handles GET /api/purchase-orders/:id
+// @Summary      Get purchase order
+// @Description  Returns a purchase order with ordered, received and pending quantities per line
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        id            path      int     true  "Purchase order ID"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/purchase-orders/{id} [get]
+func (h *PurchaseOrderHandler) GetPurchaseOrder(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid purchase order id", nil))
+		return
+	}
+
+	resp := h.useCase.GetPurchaseOrder(c.Request.Context(), int32(id))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ReceiveGoods handles POST /api/purchase-orders/:id/receive
+// @Summary      Receive goods
+// @Description  Books goods received against purchase order lines into the order's store: stock, batches (batch_number required for batch-managed products) and serial numbers (one per unit for serialized products; serials already in use are refused unless the unit is back from an RMA). Quantities beyond what is outstanding are refused. The order becomes partially_received or received.
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string               true  "Tenant identifier"
+// @Param        Authorization header    string               true  "Bearer token"
+// @Param        id            path      int                  true  "Purchase order ID"
+// @Param        body          body      GoodsReceiptRequest  true  "Received lines"
+// @Success      201           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/purchase-orders/{id}/receive [post]
+func (h *PurchaseOrderHandler) ReceiveGoods(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid purchase order id", nil))
+		return
+	}
+	var req GoodsReceiptRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	input := &usecase.GoodsReceiptInput{ReceivedBy: currentUserID(c)}
+	for _, l := range req.Lines {
+		mfg, err := parseOptionalDate("manufacturing_date", l.ManufacturingDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+			return
+		}
+		expiry, err := parseOptionalDate("expiry_date", l.ExpiryDate)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+			return
+		}
+		input.Lines = append(input.Lines, usecase.GoodsReceiptLine{
+			LineNumber:        l.LineNumber,
+			Quantity:          l.Quantity,
+			BatchNumber:       l.BatchNumber,
+			ManufacturingDate: mfg,
+			ExpiryDate:        expiry,
+			SerialNumbers:     l.SerialNumbers,
+		})
+	}
+
+	resp := h.useCase.ReceiveGoods(c.Request.Context(), int32(id), input)
+	c.JSON(resp.StatusCode, resp)
+}
@@ -36,7 +36,7 @@ func (h *RoleHandler) getRepositoryFromContext(c *gin.Context) *repository.Queri
 
 // CreateRole handles POST /api/roles
 // @Summary      Create a new role
-// @Description  Create a new role with optional metadata and system flag
+// @Description  Create a new role with optional metadata and system flag. Creating a system role needs the roles.manage_system permission.
 // @Tags         roles
 // @Accept       json
 // @Produce      json
@@ -47,6 +47,7 @@ func (h *RoleHandler) getRepositoryFromContext(c *gin.Context) *repository.Queri
 // @Success      201           {object}  SuccessResponse
 // @Failure      400           {object}  ErrorResponse
 // @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
 // @Failure      500           {object}  ErrorResponse
 // @Router       /api/roles [post]
 func (h *RoleHandler) CreateRole(c *gin.Context) {
@@ -90,6 +91,7 @@ func (h *RoleHandler) CreateRole(c *gin.Context) {
 
 	resp := h.useCase.CreateRole(
 		c.Request.Context(),
+		currentUserID(c),
 		req.Name,
 		req.Code,
 		req.Description,
@@ -235,7 +237,7 @@ func (h *RoleHandler) ListNonSystemRoles(c *gin.Context) {
 
 // UpdateRole handles PUT /api/roles/:id
 // @Summary      Update a role
-// @Description  Update role details including name, description and active status
+// @Description  Update role details including name, description and active status. Changing a system role needs the roles.manage_system permission, and system roles cannot be deactivated.
 // @Tags         roles
 // @Accept       json
 // @Produce      json
@@ -247,6 +249,7 @@ func (h *RoleHandler) ListNonSystemRoles(c *gin.Context) {
 // @Success      200           {object}  SuccessResponse
 // @Failure      400           {object}  ErrorResponse
 // @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
 // @Failure      404           {object}  ErrorResponse
 // @Failure      500           {object}  ErrorResponse
 // @Router       /api/roles/{id} [put]
@@ -296,6 +299,7 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 
 	resp := h.useCase.UpdateRole(
 		c.Request.Context(),
+		currentUserID(c),
 		int32(roleID),
 		req.Name,
 		req.Description,
@@ -307,7 +311,7 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 
 // DeleteRole handles DELETE /api/roles/:id
 // @Summary      Delete a role
-// @Description  Delete a role by ID (non-system roles only)
+// @Description  Delete a role by ID. System roles cannot be deleted.
 // @Tags         roles
 // @Accept       json
 // @Produce      json
@@ -318,6 +322,7 @@ func (h *RoleHandler) UpdateRole(c *gin.Context) {
 // @Success      200           {object}  SuccessResponse
 // @Failure      400           {object}  ErrorResponse
 // @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
 // @Failure      404           {object}  ErrorResponse
 // @Failure      500           {object}  ErrorResponse
 // @Router       /api/roles/{id} [delete]
@@ -341,7 +346,7 @@ func (h *RoleHandler) DeleteRole(c *gin.Context) {
 
 // ToggleRoleActive handles PATCH /api/roles/:id/active
 // @Summary      Toggle role active status
-// @Description  Enable or disable a role
+// @Description  Enable or disable a role. System roles cannot be disabled, and enabling one needs the roles.manage_system permission.
 // @Tags         roles
 // @Accept       json
 // @Produce      json
@@ -353,6 +358,7 @@ func (h *RoleHandler) DeleteRole(c *gin.Context) {
 // @Success      200           {object}  SuccessResponse
 // @Failure      400           {object}  ErrorResponse
 // @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
 // @Failure      404           {object}  ErrorResponse
 // @Failure      500           {object}  ErrorResponse
 // @Router       /api/roles/{id}/active [patch]
@@ -378,13 +384,13 @@ func (h *RoleHandler) ToggleRoleActive(c *gin.Context) {
 		return
 	}
 
-	resp := h.useCase.ToggleRoleActive(c.Request.Context(), int32(roleID), req.IsActive)
+	resp := h.useCase.ToggleRoleActive(c.Request.Context(), currentUserID(c), int32(roleID), req.IsActive)
 	c.JSON(resp.StatusCode, resp)
 }
 
 // AssignPermissionToRole handles POST /api/roles/:id/permissions
 // @Summary      Assign permissions to role
-// @Description  Assign one or more permissions to a role with optional scope and metadata
+// @Description  Assign one or more permissions to a role with optional scope and metadata. Requires the permissions.manage permission, and roles.manage_system to change a system role's permissions or grant users.manage, permissions.manage, roles.manage_system, stores.access_any or stores.access_manage.
 // @Tags         roles
 // @Accept       json
 // @Produce      json
@@ -396,6 +402,7 @@ func (h *RoleHandler) ToggleRoleActive(c *gin.Context) {
 // @Success      201             {object}  SuccessResponse
 // @Failure      400             {object}  ErrorResponse
 // @Failure      401             {object}  ErrorResponse
+// @Failure      403             {object}  ErrorResponse
 // @Failure      404             {object}  ErrorResponse
 // @Failure      500             {object}  ErrorResponse
 // @Router       /api/roles/{id}/permissions [post]
@@ -447,6 +454,7 @@ func (h *RoleHandler) AssignPermissionToRole(c *gin.Context) {
 
 	resp := h.useCase.AssignPermissionToRole(
 		c.Request.Context(),
+		currentUserID(c),
 		int32(roleID),
 		permissions,
 	)
@@ -455,7 +463,7 @@ func (h *RoleHandler) AssignPermissionToRole(c *gin.Context) {
 
 // RemovePermissionFromRole handles DELETE /api/roles/:id/permissions
 // @Summary      Remove permissions from role
-// @Description  Remove one or more permissions from a role
+// @Description  Remove one or more permissions from a role. Requires the permissions.manage permission, and roles.manage_system to change a system role's permissions.
 // @Tags         roles
 // @Accept       json
 // @Produce      json
@@ -467,6 +475,8 @@ func (h *RoleHandler) AssignPermissionToRole(c *gin.Context) {
 // @Success      200 {object} SuccessResponse
 // @Failure      400 {object} ErrorResponse
 // @Failure      401 {object} ErrorResponse
+// @Failure      403 {object} ErrorResponse
+// @Failure      404 {object} ErrorResponse
 // @Failure      500 {object} ErrorResponse
 // @Router       /api/roles/{id}/permissions [delete]
 func (h *RoleHandler) RemovePermissionFromRole(c *gin.Context) {
@@ -494,6 +504,7 @@ func (h *RoleHandler) RemovePermissionFromRole(c *gin.Context) {
 
 	resp := h.useCase.RemovePermissionFromRole(
 		c.Request.Context(),
+		currentUserID(c),
 		int32(roleID),
 		req.PermissionIDs,
 	)
@@ -576,3 +587,131 @@ func (h *RoleHandler) CheckRoleHasPermission(c *gin.Context) {
 	resp := h.useCase.CheckRoleHasPermission(c.Request.Context(), int32(roleID), int32(permID))
 	c.JSON(resp.StatusCode, resp)
 }
+
+// ListRoleTemplates handles GET /api/roles/templates
+// @Summary      List role templates
+// @Description  Lists the shipped role templates (Cashier, Store Manager, Warehouse Clerk) with their default permissions, scopes and UI customizations
+// @Tags         roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Success      200           {object}  SuccessResponse
+// @Failure      401           {object}  ErrorResponse
+// @Router       /api/roles/templates [get]
+func (h *RoleHandler) ListRoleTemplates(c *gin.Context) {
+	resp := h.useCase.ListRoleTemplates(c.Request.Context())
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CreateRoleFromTemplate handles POST /api/roles/templates/:code
+// @Summary      Create a role from a template
+// @Description  Creates an active role with the template's permissions and UI customizations. Name and code default to the template's. Permissions and submenus the tenant doesn't have are skipped and listed. Templates granting a permission that guards users, roles, permissions or store access need roles.manage_system.
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string                         true   "Tenant identifier"
+// @Param        Authorization header    string                         true   "Bearer token"
+// @Param        code          path      string                         true   "Template code"
+// @Param        body          body      CreateRoleFromTemplateRequest  false  "Name, code and description of the role"
+// @Success      201           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/roles/templates/{code} [post]
+func (h *RoleHandler) CreateRoleFromTemplate(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req CreateRoleFromTemplateRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid request body", nil))
+			return
+		}
+	}
+
+	resp := h.useCase.CreateRoleFromTemplate(c.Request.Context(), currentUserID(c), c.Param("code"), req.Name, req.Code, req.Description)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// CloneRole handles POST /api/roles/:id/clone
+// @Summary      Clone a role
+// @Description  Creates an active, non-system role with the permissions, scopes, metadata and UI customizations of the role. Cloning a system role, or a role granting a permission that guards users, roles, permissions or store access, needs roles.manage_system.
+// @Tags         roles
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string            true  "Tenant identifier"
+// @Param        Authorization header    string            true  "Bearer token"
+// @Param        id            path      int               true  "Role ID"
+// @Param        body          body      CloneRoleRequest  true  "Name, code and description of the copy"
+// @Success      201           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/roles/{id}/clone [post]
+func (h *RoleHandler) CloneRole(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	roleID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req CloneRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid request body", nil))
+		return
+	}
+
+	resp := h.useCase.CloneRole(c.Request.Context(), currentUserID(c), roleID, req.Name, req.Code, req.Description)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// DiffRoles handles GET /api/roles/:id/diff/:other_id
+// @Summary      Compare two roles
+// @Description  Compares the effective permissions of two roles by code: those only one role has, those both have with different scopes, and those both have alike. A permission granted without a scope counts as scope all.
+// @Tags         roles
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        id            path      int     true  "Role ID (a)"
+// @Param        other_id      path      int     true  "Role ID (b)"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/roles/{id}/diff/{other_id} [get]
+func (h *RoleHandler) DiffRoles(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	roleID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	otherID, ok := pathID(c, "other_id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.DiffRoles(c.Request.Context(), roleID, otherID)
+	c.JSON(resp.StatusCode, resp)
+}
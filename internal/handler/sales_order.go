@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SalesOrderHandler holds the sales order use case.
+type SalesOrderHandler struct {
+	useCase *usecase.SalesOrderUseCase
+}
+
+// NewSalesOrderHandler creates a new sales order handler.
+func NewSalesOrderHandler(uc *usecase.SalesOrderUseCase) *SalesOrderHandler {
+	return &SalesOrderHandler{useCase: uc}
+}
+
+func (h *SalesOrderHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// CreateSalesOrder handles POST /api/sales-orders
+// @Summary      Create sales order
+// @Description  Creates a draft sales order. Lines without unit_price are priced by the price resolver (customer, customer type, store, promotion, default lists on the order date) or from price_list_id only when given; tax is computed per product tax category (inclusive/exclusive, organization rounding mode, customer exemption) and returned with a per-rate breakdown.
+// @Tags         sales-orders
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string                   true  "Tenant identifier"
+// @Param        Authorization header    string                   true  "Bearer token"
+// @Param        body          body      CreateSalesOrderRequest  true  "Sales order payload"
+// @Success      201           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/sales-orders [post]
+func (h *SalesOrderHandler) CreateSalesOrder(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req CreateSalesOrderRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	orderDate, err := parseOptionalDate("order_date", req.OrderDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+	deliveryDate, err := parseOptionalDate("delivery_date", req.DeliveryDate)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, err.Error(), nil))
+		return
+	}
+
+	resp := h.useCase.CreateSalesOrder(c.Request.Context(), &usecase.SalesOrderInput{
+		OrganizationID: req.OrganizationID,
+		StoreID:        req.StoreID,
+		CustomerID:     req.CustomerID,
+		PriceListID:    req.PriceListID,
+		OrderDate:      orderDate,
+		DeliveryDate:   deliveryDate,
+		CreatedBy:      currentUserID(c),
+		Lines:          orderLineInputs(req.Lines),
+	})
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetSalesOrder handles GET /api/sales-orders/:id
+// @Summary      Get sales order
+// @Description  Returns a sales order with its lines
+// @Tags         sales-orders
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        id            path      int     true  "Sales order ID"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/sales-orders/{id} [get]
+func (h *SalesOrderHandler) GetSalesOrder(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid sales order id", nil))
+		return
+	}
+
+	resp := h.useCase.GetSalesOrder(c.Request.Context(), int32(id))
+	c.JSON(resp.StatusCode, resp)
+}
+
+func orderLineInputs(lines []OrderLineRequest) []usecase.OrderLineInput {
+	out := make([]usecase.OrderLineInput, 0, len(lines))
+	for _, l := range lines {
+		out = append(out, usecase.OrderLineInput{
+			ProductID:        l.ProductID,
+			ProductVariantID: l.ProductVariantID,
+			UomID:            l.UomID,
+			Quantity:         l.Quantity,
+			UnitPrice:        l.UnitPrice,
+			Discount:         l.Discount,
+		})
+	}
+	return out
+}
+
+// parseOptionalDate parses a YYYY-MM-DD request field; empty means unset.
+func parseOptionalDate(field, s string) (*time.Time, error) {
+	if s == "" {
+		return nil, nil
+	}
+	t, err := time.Parse("2006-01-02", s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s %q (use YYYY-MM-DD)", field, s)
+	}
+	return &t, nil
+}
+
+// currentUserID returns the authenticated user's id, if the token carries a
+// numeric user_id.
+func currentUserID(c *gin.Context) *int32 {
+	s, ok := middleware.GetUserIDFromContext(c)
+	if !ok {
+		return nil
+	}
+	id, err := strconv.ParseInt(s, 10, 32)
+	if err != nil {
+		return nil
+	}
+	v := int32(id)
+	return &v
+}
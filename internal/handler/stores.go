@@ -113,6 +113,7 @@ func (h *StoreHandler) CreateStore(c *gin.Context) {
 // @Param        id             path      string  true  "Store ID"
 // @Success      200  {object}  StoreResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      404  {object}  ErrorResponse
 // @Router       /api/stores/{id} [get]
 func (h *StoreHandler) GetStore(c *gin.Context) {
@@ -152,6 +153,7 @@ func (h *StoreHandler) GetStore(c *gin.Context) {
 // @Param        store_type     query     string  false "Filter by store type"
 // @Success      200  {array}   StoreResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /api/stores [get]
 func (h *StoreHandler) ListStores(c *gin.Context) {
@@ -243,6 +245,7 @@ func (h *StoreHandler) DeleteStore(c *gin.Context) {
 // @Param        Authorization header    string  true  "Bearer token"
 // @Success      200  {array}   StoreResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /api/stores/pos-enabled [get]
 func (h *StoreHandler) ListPOSEnabledStores(c *gin.Context) {
@@ -271,6 +274,7 @@ func (h *StoreHandler) ListPOSEnabledStores(c *gin.Context) {
 // @Param        Authorization header    string  true  "Bearer token"
 // @Success      200  {array}   StoreResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /api/stores/warehouses [get]
 func (h *StoreHandler) ListWarehouseStores(c *gin.Context) {
@@ -301,6 +305,7 @@ func (h *StoreHandler) ListWarehouseStores(c *gin.Context) {
 // @Param        is_active      query     bool    false "Filter by active status"
 // @Success      200  {array}   StoreResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /api/stores/parent/{parent_id} [get]
 func (h *StoreHandler) ListStoresByParent(c *gin.Context) {
@@ -345,6 +350,7 @@ func (h *StoreHandler) ListStoresByParent(c *gin.Context) {
 // @Param        is_active      query     bool    false "Filter locations by active status"
 // @Success 200 {array} object
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /api/stores/{id}/locations [get]
 func (h *StoreHandler) GetStorageLocationHierarchy(c *gin.Context) {
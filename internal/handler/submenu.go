@@ -428,3 +428,78 @@ func (h *SubmenuHandler) DeleteSubmenu(c *gin.Context) {
 	resp := h.useCase.DeleteSubmenu(c.Request.Context(), int32(id))
 	c.JSON(resp.StatusCode, resp)
 }
+
+// ReorderSubmenus handles POST /submenus/reorder
+// @Summary      Reorder submenus
+// @Description  Set the order of the submenus of a menu under a parent submenu (top-level submenus without parent_submenu_id). submenu_ids must list every submenu under the parent exactly once; display orders are renumbered 1, 2, 3, ... in that order.
+// @Tags         submenus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header  string                          true  "Tenant identifier"
+// @Param        Authorization header  string                          true  "Bearer token"
+// @Param        body          body    handler.ReorderSubmenusRequest  true  "Submenu order"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/submenus/reorder [post]
+func (h *SubmenuHandler) ReorderSubmenus(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var req ReorderSubmenusRequest
+	if err := c.BindJSON(&req); err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid request body", err.Error())
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	resp := h.useCase.ReorderSubmenus(c.Request.Context(), req.MenuID, req.ParentSubmenuID, req.SubmenuIDs)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// MoveSubmenu handles POST /submenus/{id}/move
+// @Summary      Move a submenu
+// @Description  Move a submenu with its descendants to another menu (its own without menu_id) under a parent submenu of that menu, or to the top level without parent_submenu_id, at a 1-based position among its new siblings (last by default). Moving a submenu under itself or one of its descendants is rejected. Display orders of the old and new siblings are renumbered.
+// @Tags         submenus
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header  string                      true  "Tenant identifier"
+// @Param        Authorization header  string                      true  "Bearer token"
+// @Param        id            path    int                         true  "Submenu ID"
+// @Param        body          body    handler.MoveSubmenuRequest  true  "Target menu, parent and position"
+// @Success      200  {object}  SuccessResponse
+// @Failure      400  {object}  ErrorResponse
+// @Failure      401  {object}  ErrorResponse
+// @Failure      404  {object}  ErrorResponse
+// @Failure      500  {object}  ErrorResponse
+// @Router       /api/submenus/{id}/move [post]
+func (h *SubmenuHandler) MoveSubmenu(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	id, err := strconv.ParseInt(c.Param("id"), 10, 32)
+	if err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid submenu id", nil)
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	var req MoveSubmenuRequest
+	if err := c.BindJSON(&req); err != nil {
+		resp := utils.NewResponse(utils.CodeBadReq, "invalid request body", err.Error())
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+
+	resp := h.useCase.MoveSubmenu(c.Request.Context(), int32(id), req.MenuID, req.ParentSubmenuID, req.Position)
+	c.JSON(resp.StatusCode, resp)
+}
@@ -38,7 +38,7 @@ func (h *UserHandler) getRepositoryFromContext(c *gin.Context) *repository.Queri
 
 // CreateUser handles POST /users
 // @Summary      Create a new user
-// @Description  Create a new user with optional login credentials
+// @Description  Create a new user with optional login credentials. Requires the users.manage permission.
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -49,6 +49,7 @@ func (h *UserHandler) getRepositoryFromContext(c *gin.Context) *repository.Queri
 // @Success      201  {object}  SuccessResponse
 // @Failure      400  {object}  ErrorResponse
 // @Failure      401  {object}  ErrorResponse
+// @Failure      403  {object}  ErrorResponse
 // @Failure      500  {object}  ErrorResponse
 // @Router       /api/users [post]
 func (h *UserHandler) CreateUser(c *gin.Context) {
@@ -75,7 +76,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	}
 
 	// Call UseCase
-	response := h.useCase.CreateUser(c.Request.Context(), req.FirstName, req.LastName, req.Username, req.Email, req.IsActive, req.Password, req.EmployeeCode)
+	response := h.useCase.CreateUser(c.Request.Context(), currentUserID(c), req.FirstName, req.LastName, req.Username, req.Email, req.IsActive, req.Password, req.EmployeeCode)
 
 	// Respond with the response from use case
 	c.JSON(response.StatusCode, response)
@@ -158,7 +159,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 
 // AssignRoleToUser handles POST /users/:id/roles
 // @Summary      Assign role to user
-// @Description  Assign a specific role to a user with optional metadata
+// @Description  Assign a specific role to a user with optional metadata. Requires the users.manage permission, and roles.manage_system for a system role.
 // @Tags         users
 // @Accept       json
 // @Produce      json
@@ -170,6 +171,7 @@ func (h *UserHandler) ListUsers(c *gin.Context) {
 // @Success      201           {object}  SuccessResponse
 // @Failure      400           {object}  ErrorResponse
 // @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
 // @Failure      404           {object}  ErrorResponse
 // @Failure      500           {object}  ErrorResponse
 // @Router       /api/users/addUserRoles/{id} [post]
@@ -217,6 +219,7 @@ func (h *UserHandler) AssignRoleToUser(c *gin.Context) {
 	// Call usecase
 	resp := h.useCase.AssignRoleToUser(
 		c.Request.Context(),
+		currentUserID(c),
 		int32(userID),
 		req.RoleID,
 		req.StoreID,
@@ -226,3 +229,457 @@ func (h *UserHandler) AssignRoleToUser(c *gin.Context) {
 	// Return standard response
 	c.JSON(resp.StatusCode, resp)
 }
+
+// ListUserStores handles GET /users/:id/stores
+// @Summary      List a user's store access
+// @Description  Lists the stores the user has access to, primary first. Reading another user's needs the stores.access_manage permission.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        id            path      int     true  "User ID"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id}/stores [get]
+func (h *UserHandler) ListUserStores(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.ListUserStoreAccess(c.Request.Context(), currentUserID(c), userID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GrantStoreAccess handles POST /users/:id/stores
+// @Summary      Grant store access
+// @Description  Gives the user access to a store of the active organization, or updates the access they have. A primary store is selected at login; making one primary clears the flag on the others. Needs the stores.access_manage permission.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string                   true  "Tenant identifier"
+// @Param        Authorization header    string                   true  "Bearer token"
+// @Param        id            path      int                      true  "User ID"
+// @Param        body          body      GrantStoreAccessRequest  true  "Store access"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id}/stores [post]
+func (h *UserHandler) GrantStoreAccess(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req GrantStoreAccessRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid request body", nil))
+		return
+	}
+	var metadata []byte
+	if req.Metadata != nil {
+		b, err := json.Marshal(req.Metadata)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid metadata", nil))
+			return
+		}
+		metadata = b
+	}
+	resp := h.useCase.GrantStoreAccess(c.Request.Context(), currentUserID(c), userID, req.StoreID, req.IsPrimary, metadata)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// SetPrimaryStore handles PUT /users/:id/stores/:store_id/primary
+// @Summary      Set a user's primary store
+// @Description  Makes a store the user has access to their primary store, selected at login. Needs the stores.access_manage permission.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        id            path      int     true  "User ID"
+// @Param        store_id      path      int     true  "Store ID"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id}/stores/{store_id}/primary [put]
+func (h *UserHandler) SetPrimaryStore(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	storeID, ok := pathID(c, "store_id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.SetPrimaryStore(c.Request.Context(), currentUserID(c), userID, storeID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// RevokeStoreAccess handles DELETE /users/:id/stores/:store_id
+// @Summary      Revoke store access
+// @Description  Takes the user's access to a store of the active organization away. Needs the stores.access_manage permission.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        id            path      int     true  "User ID"
+// @Param        store_id      path      int     true  "Store ID"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id}/stores/{store_id} [delete]
+func (h *UserHandler) RevokeStoreAccess(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	storeID, ok := pathID(c, "store_id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.RevokeStoreAccess(c.Request.Context(), currentUserID(c), userID, storeID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// SearchUsers handles GET /users/search
+// @Summary      Search users
+// @Description  Lists the users of the active organization a page at a time, without password hashes. q matches username, email, names and employee code. Needs the users.view_any permission.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true   "Tenant identifier"
+// @Param        Authorization header    string  true   "Bearer token"
+// @Param        q             query     string  false  "Search term"
+// @Param        is_active     query     bool    false  "Active users only (true) or inactive only (false)"
+// @Param        role_id       query     int     false  "Users with this role"
+// @Param        store_id      query     int     false  "Users with access to this store"
+// @Param        limit         query     int     false  "Limit number of results"
+// @Param        offset        query     int     false  "Offset for pagination"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/search [get]
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	var filter usecase.UserFilter
+	if q := c.Query("q"); q != "" {
+		filter.Search = &q
+	}
+	if s := c.Query("is_active"); s != "" {
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid is_active", nil))
+			return
+		}
+		filter.IsActive = &v
+	}
+	for name, dst := range map[string]**int32{"role_id": &filter.RoleID, "store_id": &filter.StoreID} {
+		if s := c.Query(name); s != "" {
+			id, err := strconv.ParseInt(s, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid "+name, nil))
+				return
+			}
+			v := int32(id)
+			*dst = &v
+		}
+	}
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	if err != nil {
+		offset = 0
+	}
+
+	resp := h.useCase.SearchUsers(c.Request.Context(), currentUserID(c), filter, int32(limit), int32(offset))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetUserDetail handles GET /users/:id/details
+// @Summary      Get user details
+// @Description  Returns the user, without password hash, with their roles, store access and when their password last changed. Reading another user needs the users.view_any permission.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        id            path      int     true  "User ID"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id}/details [get]
+func (h *UserHandler) GetUserDetail(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	resp := h.useCase.GetUserDetail(c.Request.Context(), currentUserID(c), userID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// UpdateUser handles PATCH /users/:id
+// @Summary      Update a user
+// @Description  Changes the email, names, employee code and metadata of a user of the active organization; omitted fields are kept. Needs the users.manage permission.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string             true  "Tenant identifier"
+// @Param        Authorization header    string             true  "Bearer token"
+// @Param        id            path      int                true  "User ID"
+// @Param        body          body      UpdateUserRequest  true  "Profile fields"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id} [patch]
+func (h *UserHandler) UpdateUser(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req UpdateUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid request body", nil))
+		return
+	}
+	in := usecase.UserProfileInput{
+		Email:        req.Email,
+		FirstName:    req.FirstName,
+		LastName:     req.LastName,
+		EmployeeCode: req.EmployeeCode,
+	}
+	if req.Metadata != nil {
+		b, err := json.Marshal(req.Metadata)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid metadata", nil))
+			return
+		}
+		in.Metadata = b
+	}
+
+	resp := h.useCase.UpdateUserProfile(c.Request.Context(), currentUserID(c), userID, in)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// SetUserActive handles PATCH /users/:id/active
+// @Summary      Activate or deactivate a user
+// @Description  Deactivating a user signs them out everywhere: their tokens are refused from now on. Users cannot deactivate themselves. Needs the users.manage permission.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string                true  "Tenant identifier"
+// @Param        Authorization header    string                true  "Bearer token"
+// @Param        id            path      int                   true  "User ID"
+// @Param        body          body      SetUserActiveRequest  true  "Active flag"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id}/active [patch]
+func (h *UserHandler) SetUserActive(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req SetUserActiveRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid request body", nil))
+		return
+	}
+
+	resp := h.useCase.SetUserActive(c.Request.Context(), currentUserID(c), userID, *req.IsActive)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ResetUserPassword handles POST /users/:id/reset-password
+// @Summary      Reset a user's password
+// @Description  Sets a new password for a user of the active organization and signs them out everywhere. Needs the users.manage permission.
+// @Tags         users
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string                true  "Tenant identifier"
+// @Param        Authorization header    string                true  "Bearer token"
+// @Param        id            path      int                   true  "User ID"
+// @Param        body          body      ResetPasswordRequest  true  "New password"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id}/reset-password [post]
+func (h *UserHandler) ResetUserPassword(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, utils.NewResponse(utils.CodeBadReq, "invalid request body", nil))
+		return
+	}
+
+	resp := h.useCase.ResetUserPassword(c.Request.Context(), currentUserID(c), userID, req.NewPassword)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// RevokeRoleFromUser handles DELETE /users/:id/roles/:role_id
+// @Summary      Revoke a role from a user
+// @Description  Takes a role away from a user of the active organization. Needs the users.manage permission.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        id            path      int     true  "User ID"
+// @Param        role_id       path      int     true  "Role ID"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id}/roles/{role_id} [delete]
+func (h *UserHandler) RevokeRoleFromUser(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	roleID, ok := pathID(c, "role_id")
+	if !ok {
+		return
+	}
+
+	resp := h.useCase.RevokeRoleFromUser(c.Request.Context(), currentUserID(c), userID, roleID)
+	c.JSON(resp.StatusCode, resp)
+}
+
+// ListUserLoginAudit handles GET /users/:id/logins
+// @Summary      List a user's login attempts
+// @Description  Lists the successful and failed login attempts of a user of the active organization, latest first, with the client's IP address and user agent. Needs the users.manage permission.
+// @Tags         users
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true   "Tenant identifier"
+// @Param        Authorization header    string  true   "Bearer token"
+// @Param        id            path      int     true   "User ID"
+// @Param        limit         query     int     false  "Limit number of results"
+// @Param        offset        query     int     false  "Offset for pagination"
+// @Success      200           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/users/{id}/logins [get]
+func (h *UserHandler) ListUserLoginAudit(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	userID, ok := pathID(c, "id")
+	if !ok {
+		return
+	}
+	limit, err := strconv.ParseInt(c.DefaultQuery("limit", "100"), 10, 32)
+	if err != nil {
+		limit = 100
+	}
+	offset, err := strconv.ParseInt(c.DefaultQuery("offset", "0"), 10, 32)
+	if err != nil {
+		offset = 0
+	}
+
+	resp := h.useCase.ListUserLoginAudit(c.Request.Context(), currentUserID(c), userID, int32(limit), int32(offset))
+	c.JSON(resp.StatusCode, resp)
+}
@@ -0,0 +1,120 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ZatcaHandler holds the ZATCA e-invoicing use case.
+type ZatcaHandler struct {
+	useCase *usecase.ZatcaUseCase
+}
+
+// NewZatcaHandler creates a new ZATCA handler.
+func NewZatcaHandler(uc *usecase.ZatcaUseCase) *ZatcaHandler {
+	return &ZatcaHandler{useCase: uc}
+}
+
+func (h *ZatcaHandler) getRepositoryFromContext(c *gin.Context) *repository.Queries {
+	repo, ok := c.Request.Context().Value(middleware.RepoKey).(*repository.Queries)
+	if !ok {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+		c.Abort()
+		return nil
+	}
+	return repo
+}
+
+// IssueInvoice handles POST /api/zatca/transactions/:number/invoice
+// @Summary      Issue ZATCA simplified tax invoice
+// @Description  Generates the UBL 2.1 simplified tax invoice, invoice hash (chained per terminal), counter and TLV QR code for a POS transaction. Returns the existing invoice if one was already issued.
+// @Tags         zatca
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        number        path      string  true  "Transaction number"
+// @Success      200           {object}  SuccessResponse
+// @Success      201           {object}  SuccessResponse
+// @Failure      400           {object}  ErrorResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/zatca/transactions/{number}/invoice [post]
+func (h *ZatcaHandler) IssueInvoice(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.IssueInvoice(c.Request.Context(), c.Param("number"))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetInvoice handles GET /api/zatca/transactions/:number/invoice
+// @Summary      Get ZATCA invoice
+// @Description  Returns the issued invoice record (counter, hashes, QR code, XML) for a POS transaction
+// @Tags         zatca
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        number        path      string  true  "Transaction number"
+// @Success      200           {object}  SuccessResponse
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/zatca/transactions/{number}/invoice [get]
+func (h *ZatcaHandler) GetInvoice(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.GetInvoice(c.Request.Context(), c.Param("number"))
+	c.JSON(resp.StatusCode, resp)
+}
+
+// GetInvoiceXML handles GET /api/zatca/transactions/:number/invoice/xml
+// @Summary      Download ZATCA invoice XML
+// @Description  Returns the UBL 2.1 invoice document for a POS transaction
+// @Tags         zatca
+// @Produce      xml
+// @Security     BearerAuth
+// @Param        x-tenant-id   header    string  true  "Tenant identifier"
+// @Param        Authorization header    string  true  "Bearer token"
+// @Param        number        path      string  true  "Transaction number"
+// @Success      200           {string}  string
+// @Failure      401           {object}  ErrorResponse
+// @Failure      403           {object}  ErrorResponse
+// @Failure      404           {object}  ErrorResponse
+// @Failure      500           {object}  ErrorResponse
+// @Router       /api/zatca/transactions/{number}/invoice/xml [get]
+func (h *ZatcaHandler) GetInvoiceXML(c *gin.Context) {
+	repo := h.getRepositoryFromContext(c)
+	if repo == nil {
+		return
+	}
+	h.useCase.SetRepository(repo)
+
+	resp := h.useCase.GetInvoice(c.Request.Context(), c.Param("number"))
+	inv, ok := resp.Data.(repository.ZatcaInvoice)
+	if !ok {
+		c.JSON(resp.StatusCode, resp)
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("inline; filename=%q", c.Param("number")+".xml"))
+	c.Data(http.StatusOK, "application/xml; charset=utf-8", []byte(inv.InvoiceXml))
+}
@@ -0,0 +1,88 @@
+package inventory
+
+import (
+	"math/big"
+	"time"
+)
+
+// Alert types raised by the expiry check.
+const (
+	AlertNearExpiry = "near_expiry"
+	AlertExpired    = "expired"
+)
+
+// ExpiryRule is the near-expiry window and the largest markdown for the
+// products of a category.
+type ExpiryRule struct {
+	NearExpiryDays int
+	// MaxMarkdown is a percentage of the current price.
+	MaxMarkdown *big.Rat
+}
+
+// DefaultExpiryRule applies when a tenant has no default rule configured.
+var DefaultExpiryRule = ExpiryRule{NearExpiryDays: 30, MaxMarkdown: new(big.Rat)}
+
+// ExpiryRules resolves the rule of a product category. Categories without
+// their own rule use the nearest ancestor's, then the default.
+type ExpiryRules struct {
+	Default    ExpiryRule
+	ByCategory map[int32]ExpiryRule
+	Parents    map[int32]int32
+}
+
+// For returns the rule of a category; zero means uncategorized.
+func (r ExpiryRules) For(categoryID int32) ExpiryRule {
+	seen := map[int32]bool{}
+	for id := categoryID; id != 0 && !seen[id]; id = r.Parents[id] {
+		seen[id] = true
+		if rule, ok := r.ByCategory[id]; ok {
+			return rule
+		}
+	}
+	return r.Default
+}
+
+// MaxNearExpiryDays is the widest near-expiry window of all rules, the
+// horizon to look for expiring stock.
+func (r ExpiryRules) MaxNearExpiryDays() int {
+	days := r.Default.NearExpiryDays
+	for _, rule := range r.ByCategory {
+		if rule.NearExpiryDays > days {
+			days = rule.NearExpiryDays
+		}
+	}
+	return days
+}
+
+// DaysUntil counts whole days from on to expiry; negative once expired.
+func DaysUntil(expiry, on time.Time) int {
+	return int(truncateDay(expiry).Sub(truncateDay(on)).Hours() / 24)
+}
+
+// SuggestMarkdown returns the markdown percentage for stock expiring in
+// daysLeft days under rule. It grows linearly from nothing at the edge of
+// the near-expiry window to the rule's maximum on the expiry day, rounded
+// to whole percent.
+func SuggestMarkdown(rule ExpiryRule, daysLeft int) *big.Rat {
+	if rule.MaxMarkdown == nil || rule.NearExpiryDays <= 0 || daysLeft >= rule.NearExpiryDays {
+		return new(big.Rat)
+	}
+	if daysLeft < 0 {
+		daysLeft = 0
+	}
+	pct := new(big.Rat).Mul(rule.MaxMarkdown, big.NewRat(int64(rule.NearExpiryDays-daysLeft), int64(rule.NearExpiryDays)))
+	return roundRat(pct, 0)
+}
+
+// MarkdownPrice applies a markdown percentage to price, rounded to two
+// decimals.
+func MarkdownPrice(price, pct *big.Rat) *big.Rat {
+	factor := new(big.Rat).Sub(big.NewRat(1, 1), new(big.Rat).Quo(pct, big.NewRat(100, 1)))
+	return roundRat(new(big.Rat).Mul(price, factor), 2)
+}
+
+// roundRat rounds x half away from zero to the given number of decimals.
+func roundRat(x *big.Rat, decimals int) *big.Rat {
+	out, _ := new(big.Rat).SetString(x.FloatString(decimals))
+	return out
+}
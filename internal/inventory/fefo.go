@@ -0,0 +1,141 @@
+// Package inventory holds stock rules that do not depend on the database:
+// first-expiry-first-out batch allocation, expiry checks and markdowns, and
+// the serial number lifecycle.
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInsufficientStock is returned when the sellable batches do not
+	// cover the requested quantity.
+	ErrInsufficientStock = errors.New("insufficient batch stock")
+	// ErrBatchExpired is returned when a manually chosen batch is past its
+	// expiry date.
+	ErrBatchExpired = errors.New("batch is expired")
+	// ErrBatchNotFound is returned when a manually chosen batch is not
+	// among the batches of the product in the store.
+	ErrBatchNotFound = errors.New("batch not found")
+)
+
+// Batch is a stock batch of one product in one store.
+type Batch struct {
+	ID           int32
+	Number       string
+	Expiry       *time.Time
+	Manufactured *time.Time
+	Available    *big.Rat
+}
+
+// Allocation is the quantity taken from one batch.
+type Allocation struct {
+	Batch    Batch
+	Quantity *big.Rat
+}
+
+// Expired reports whether b can no longer be sold on the given day. A batch
+// is sellable through its expiry date.
+func Expired(b Batch, on time.Time) bool {
+	return b.Expiry != nil && truncateDay(*b.Expiry).Before(truncateDay(on))
+}
+
+// SortFEFO orders batches first-expiry-first-out: earliest expiry first,
+// batches without expiry last, then oldest manufacturing date, then ID.
+func SortFEFO(batches []Batch) {
+	sort.SliceStable(batches, func(i, j int) bool {
+		a, b := batches[i], batches[j]
+		if c := compareDates(a.Expiry, b.Expiry); c != 0 {
+			return c < 0
+		}
+		if c := compareDates(a.Manufactured, b.Manufactured); c != 0 {
+			return c < 0
+		}
+		return a.ID < b.ID
+	})
+}
+
+// Allocate takes qty from batches first-expiry-first-out, skipping expired
+// and empty batches. The quantity may be split across several batches. When
+// the sellable batches fall short the error wraps ErrInsufficientStock and
+// mentions any expired stock that was held back.
+func Allocate(batches []Batch, qty *big.Rat, on time.Time) ([]Allocation, error) {
+	sorted := append([]Batch(nil), batches...)
+	SortFEFO(sorted)
+
+	remaining := new(big.Rat).Set(qty)
+	expired := new(big.Rat)
+	var out []Allocation
+	for _, b := range sorted {
+		if b.Available == nil || b.Available.Sign() <= 0 {
+			continue
+		}
+		if Expired(b, on) {
+			expired.Add(expired, b.Available)
+			continue
+		}
+		if remaining.Sign() <= 0 {
+			break
+		}
+		take := new(big.Rat).Set(b.Available)
+		if take.Cmp(remaining) > 0 {
+			take.Set(remaining)
+		}
+		out = append(out, Allocation{Batch: b, Quantity: take})
+		remaining.Sub(remaining, take)
+	}
+	if remaining.Sign() > 0 {
+		short := new(big.Rat).Sub(qty, remaining)
+		msg := fmt.Sprintf("%s available of %s", short.FloatString(3), qty.FloatString(3))
+		if expired.Sign() > 0 {
+			msg += fmt.Sprintf(" (%s in expired batches cannot be sold)", expired.FloatString(3))
+		}
+		return nil, fmt.Errorf("%w: %s", ErrInsufficientStock, msg)
+	}
+	return out, nil
+}
+
+// AllocateBatch takes qty from the batch with the given number, for a
+// manual override of FEFO. Expired batches are refused.
+func AllocateBatch(batches []Batch, number string, qty *big.Rat, on time.Time) ([]Allocation, error) {
+	number = strings.TrimSpace(number)
+	for _, b := range batches {
+		if !strings.EqualFold(b.Number, number) {
+			continue
+		}
+		if Expired(b, on) {
+			return nil, fmt.Errorf("%w: %s expired on %s", ErrBatchExpired, b.Number, b.Expiry.Format("2006-01-02"))
+		}
+		if b.Available == nil || b.Available.Cmp(qty) < 0 {
+			avail := "0.000"
+			if b.Available != nil {
+				avail = b.Available.FloatString(3)
+			}
+			return nil, fmt.Errorf("%w: batch %s has %s of %s", ErrInsufficientStock, b.Number, avail, qty.FloatString(3))
+		}
+		return []Allocation{{Batch: b, Quantity: new(big.Rat).Set(qty)}}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrBatchNotFound, number)
+}
+
+// compareDates orders dates ascending with nil (no date) last.
+func compareDates(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+	return truncateDay(*a).Compare(truncateDay(*b))
+}
+
+func truncateDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,74 @@
+package inventory
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// Serial number statuses. A unit is received in_stock, sold, and may come
+// back as returned (back on the shelf, sellable again) or go to rma (sent
+// back to the supplier).
+const (
+	SerialInStock  = "in_stock"
+	SerialSold     = "sold"
+	SerialReturned = "returned"
+	SerialRMA      = "rma"
+)
+
+// ErrSerialTransition is returned for a status change the lifecycle does
+// not allow, such as selling a unit twice.
+var ErrSerialTransition = errors.New("invalid serial number status change")
+
+var serialTransitions = map[string][]string{
+	SerialInStock:  {SerialSold, SerialRMA},
+	SerialSold:     {SerialReturned, SerialRMA},
+	SerialReturned: {SerialSold, SerialInStock, SerialRMA},
+	SerialRMA:      {SerialInStock},
+}
+
+// CheckSerialTransition reports whether a unit may move from one status to
+// another. An empty from status is treated as in_stock.
+func CheckSerialTransition(from, to string) error {
+	if from == "" {
+		from = SerialInStock
+	}
+	for _, s := range serialTransitions[from] {
+		if s == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s to %s", ErrSerialTransition, from, to)
+}
+
+// Sellable reports whether a unit with the given status can be sold.
+func Sellable(status string) bool {
+	return CheckSerialTransition(status, SerialSold) == nil
+}
+
+// CheckSerials trims the serial numbers captured for qty units of a
+// serialized product and checks there is exactly one per unit and none is
+// repeated.
+func CheckSerials(serials []string, qty *big.Rat) ([]string, error) {
+	if !qty.IsInt() {
+		return nil, errors.New("serialized products are sold in whole units")
+	}
+	out := make([]string, 0, len(serials))
+	seen := make(map[string]bool, len(serials))
+	for _, s := range serials {
+		s = strings.TrimSpace(s)
+		if s == "" {
+			continue
+		}
+		if seen[s] {
+			return nil, fmt.Errorf("serial number %s is repeated", s)
+		}
+		seen[s] = true
+		out = append(out, s)
+	}
+	if big.NewInt(int64(len(out))).Cmp(qty.Num()) != 0 {
+		return nil, fmt.Errorf("%s serial numbers required, %d given", qty.FloatString(0), len(out))
+	}
+	return out, nil
+}
@@ -0,0 +1,29 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+)
+
+// CatalogImportJob runs the queued catalog imports of one tenant per run.
+// Imports normally start as soon as they are uploaded; this picks up the
+// ones interrupted by a restart.
+func CatalogImportJob(interval time.Duration) Job {
+	return Job{
+		Name:     "catalog-imports",
+		Interval: interval,
+		Run: func(ctx context.Context, repo *repository.Queries) error {
+			uc := usecase.NewCatalogUseCase()
+			uc.SetRepository(repo)
+			for {
+				ran, err := uc.RunImportJob(ctx, nil)
+				if err != nil || !ran {
+					return err
+				}
+			}
+		},
+	}
+}
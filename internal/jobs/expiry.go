@@ -0,0 +1,28 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/usecase"
+	"NEMBUS/utils"
+)
+
+// ExpiryAlertJob marks expired batches and raises expiry alerts with
+// markdown suggestions for one tenant per run.
+func ExpiryAlertJob(interval time.Duration) Job {
+	return Job{
+		Name:     "expiry-alerts",
+		Interval: interval,
+		Run: func(ctx context.Context, repo *repository.Queries) error {
+			uc := usecase.NewExpiryAlertUseCase()
+			uc.SetRepository(repo)
+			if resp := uc.RunExpiryCheck(ctx); resp.StatusCode != utils.CodeOK {
+				return errors.New(resp.Message)
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,87 @@
+// Package jobs runs scheduled background work against every active tenant
+// database.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"NEMBUS/internal/middleware/manager"
+	"NEMBUS/internal/repository"
+)
+
+// Job is work run on a fixed interval once per active tenant.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context, repo *repository.Queries) error
+}
+
+// Scheduler runs jobs for every active tenant. Tenant databases are reached
+// through the same pool cache the HTTP middleware uses.
+type Scheduler struct {
+	masterRepo    *repository.Queries
+	tenantManager *manager.Manager
+	jobs          []Job
+}
+
+// NewScheduler creates a scheduler over the tenants of the master database.
+func NewScheduler(masterRepo *repository.Queries, tenantManager *manager.Manager) *Scheduler {
+	return &Scheduler{masterRepo: masterRepo, tenantManager: tenantManager}
+}
+
+// Add registers a job. Jobs with a non-positive interval are disabled.
+func (s *Scheduler) Add(job Job) {
+	if job.Interval <= 0 {
+		log.Printf("job %s disabled", job.Name)
+		return
+	}
+	s.jobs = append(s.jobs, job)
+}
+
+// Start runs every job once and then on its interval until ctx is done.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+	for {
+		s.RunOnce(ctx, job)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RunOnce runs job for each active tenant in turn. A failing tenant is
+// logged and does not stop the others.
+func (s *Scheduler) RunOnce(ctx context.Context, job Job) {
+	tenants, err := s.masterRepo.ListActiveTenants(ctx)
+	if err != nil {
+		log.Printf("job %s: list tenants: %v", job.Name, err)
+		return
+	}
+	for _, t := range tenants {
+		if ctx.Err() != nil {
+			return
+		}
+		pool, err := s.tenantManager.GetPool(ctx, t.Slug)
+		if err != nil {
+			log.Printf("job %s: tenant %s: %v", job.Name, t.Slug, err)
+			continue
+		}
+		start := time.Now()
+		if err := job.Run(ctx, repository.New(pool)); err != nil {
+			log.Printf("job %s: tenant %s: %v", job.Name, t.Slug, err)
+			continue
+		}
+		log.Printf("job %s: tenant %s done in %s", job.Name, t.Slug, time.Since(start).Round(time.Millisecond))
+	}
+}
@@ -0,0 +1,311 @@
+// Package labels renders shelf labels and price tags as PDF, PNG or ZPL.
+// A label is laid out once in millimetres and each format draws the same
+// elements, so a PDF proof and a ZPL print job look alike. Like the
+// receipt package it works on pre-formatted strings and has no knowledge
+// of the database types.
+package labels
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"NEMBUS/internal/barcode"
+)
+
+// Format is an output format for rendered labels.
+type Format string
+
+const (
+	FormatPDF Format = "pdf"
+	FormatPNG Format = "png"
+	FormatZPL Format = "zpl"
+)
+
+// ParseFormat parses a format name; empty means PDF.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatPDF:
+		return FormatPDF, nil
+	case FormatPNG:
+		return FormatPNG, nil
+	case FormatZPL:
+		return FormatZPL, nil
+	}
+	return "", fmt.Errorf("unsupported label format %q (use pdf, png or zpl)", s)
+}
+
+// Template is a label size and layout.
+type Template string
+
+const (
+	// TemplateShelf is a 60 x 35 mm shelf-edge label.
+	TemplateShelf Template = "shelf"
+	// TemplatePriceTag is a 40 x 25 mm price tag.
+	TemplatePriceTag Template = "price_tag"
+)
+
+// ParseTemplate parses a template name; empty means a shelf label.
+func ParseTemplate(s string) (Template, error) {
+	switch Template(strings.ToLower(strings.TrimSpace(s))) {
+	case "", TemplateShelf:
+		return TemplateShelf, nil
+	case TemplatePriceTag:
+		return TemplatePriceTag, nil
+	}
+	return "", fmt.Errorf("unsupported label template %q (use shelf or price_tag)", s)
+}
+
+// Label is the content of one label. Amounts are formatted by the caller.
+type Label struct {
+	Name string
+	// Detail is a second line such as the variant and unit.
+	Detail string
+	SKU    string
+	// Barcode is drawn as EAN-13 when it is a valid EAN-13 or UPC-A and as
+	// Code 128 otherwise.
+	Barcode  string
+	Currency string
+	Price    string
+	// RegularPrice is shown crossed out next to a promotional Price, with
+	// PromoText above it.
+	RegularPrice string
+	PromoText    string
+}
+
+// Output is rendered labels ready to be sent to the client.
+type Output struct {
+	ContentType string
+	Filename    string
+	Body        []byte
+}
+
+// geometry is the layout of a template, in millimetres.
+type geometry struct {
+	width, height float64
+	margin        float64
+	nameSize      float64
+	nameLines     int
+	detailSize    float64
+	priceSize     float64
+	barHeight     float64
+	// barWidth is the share of the inner width the barcode may take; the
+	// SKU is printed beside it when there is room.
+	barWidth  float64
+	smallSize float64
+}
+
+var geometries = map[Template]geometry{
+	TemplateShelf:    {width: 60, height: 35, margin: 2, nameSize: 3.2, nameLines: 2, detailSize: 2.4, priceSize: 8, barHeight: 9, barWidth: 0.68, smallSize: 2},
+	TemplatePriceTag: {width: 40, height: 25, margin: 1.5, nameSize: 2.6, nameLines: 1, detailSize: 2, priceSize: 6.5, barHeight: 6, barWidth: 1, smallSize: 1.7},
+}
+
+// maxModule is the widest barcode module drawn, in millimetres.
+const maxModule = 0.33
+
+// Render lays out the labels with the template and encodes them in the
+// requested format: one page per label for PDF, labels stacked in one
+// image for PNG and one ^XA...^XZ block per label for ZPL.
+func Render(labels []Label, tpl Template, format Format) (*Output, error) {
+	g, ok := geometries[tpl]
+	if !ok {
+		return nil, fmt.Errorf("unsupported label template %q", tpl)
+	}
+	if len(labels) == 0 {
+		return nil, fmt.Errorf("no labels to render")
+	}
+	pages := make([][]element, len(labels))
+	for i, l := range labels {
+		pages[i] = layout(l, g)
+	}
+	switch format {
+	case FormatPDF:
+		return &Output{ContentType: "application/pdf", Filename: "labels.pdf", Body: renderPDF(pages, g)}, nil
+	case FormatPNG:
+		body, err := renderPNG(pages, g)
+		if err != nil {
+			return nil, err
+		}
+		return &Output{ContentType: "image/png", Filename: "labels.png", Body: body}, nil
+	case FormatZPL:
+		return &Output{ContentType: "text/plain; charset=utf-8", Filename: "labels.zpl", Body: renderZPL(pages, g)}, nil
+	}
+	return nil, fmt.Errorf("unsupported label format %q", format)
+}
+
+type elementKind int
+
+const (
+	kindText elementKind = iota
+	kindBars
+	kindRule
+)
+
+// element is one thing drawn on a label. Text is placed by the left end of
+// its baseline with size as the em height; bars and rules by their
+// top-left corner.
+type element struct {
+	kind    elementKind
+	x, y    float64
+	w, h    float64
+	size    float64
+	bold    bool
+	text    string
+	modules []bool
+}
+
+// textWidth estimates the width of s; every format sizes its font so that
+// a character is about 0.6 em wide.
+func textWidth(s string, size float64) float64 {
+	return float64(len([]rune(s))) * size * 0.6
+}
+
+// fit returns how many characters of the given size fit in width.
+func fit(width, size float64) int {
+	return int(width / (size * 0.6))
+}
+
+func layout(l Label, g geometry) []element {
+	var els []element
+	text := func(x, y, size float64, bold bool, s string) {
+		els = append(els, element{kind: kindText, x: x, y: y, size: size, bold: bold, text: s})
+	}
+	inner := g.width - 2*g.margin
+	y := g.margin
+	for _, line := range wrap(l.Name, fit(inner, g.nameSize), g.nameLines) {
+		y += g.nameSize
+		text(g.margin, y, g.nameSize, true, line)
+	}
+	if l.Detail != "" {
+		y += g.detailSize + 0.6
+		text(g.margin, y, g.detailSize, false, clip(l.Detail, fit(inner, g.detailSize)))
+	}
+
+	// The price is right-aligned and shrunk to fit beside the currency and
+	// the crossed-out regular price.
+	if l.Price != "" {
+		left := 0.0
+		if l.RegularPrice != "" {
+			left = max(textWidth(l.RegularPrice, g.detailSize), textWidth(l.PromoText, g.smallSize)) + 1
+		}
+		if l.Currency != "" {
+			left += textWidth(l.Currency, g.detailSize) + 0.8
+		}
+		size := min(g.priceSize, (inner-left)/(0.6*float64(len([]rune(l.Price)))))
+		y += size + 0.8
+		priceX := g.width - g.margin - textWidth(l.Price, size)
+		text(priceX, y, size, true, l.Price)
+		if l.Currency != "" {
+			text(priceX-0.8-textWidth(l.Currency, g.detailSize), y, g.detailSize, false, l.Currency)
+		}
+		if l.RegularPrice != "" {
+			text(g.margin, y, g.detailSize, false, l.RegularPrice)
+			els = append(els, element{
+				kind: kindRule,
+				x:    g.margin,
+				y:    y - g.detailSize*0.35,
+				w:    textWidth(l.RegularPrice, g.detailSize),
+				h:    0.25,
+			})
+			if l.PromoText != "" {
+				text(g.margin, y-g.detailSize-0.6, g.smallSize, true, clip(l.PromoText, fit(inner/2, g.smallSize)))
+			}
+		}
+	}
+
+	// Barcode and SKU along the bottom edge.
+	baseline := g.height - g.margin
+	modules, err := []bool(nil), error(nil)
+	symbology, code, ok := barcode.SymbologyFor(l.Barcode)
+	if ok {
+		modules, err = barcode.Modules(symbology, code)
+	}
+	barHeight := min(g.barHeight, baseline-g.smallSize-0.4-(y+1))
+	if !ok || err != nil || barHeight < 3 {
+		if l.SKU != "" {
+			text(g.margin, baseline, g.smallSize, false, clip(l.SKU, fit(inner, g.smallSize)))
+		}
+		return els
+	}
+	barArea := inner * g.barWidth
+	mw := min(maxModule, barArea/float64(len(modules)+2*barcode.QuietZone))
+	x := max(g.margin, barcode.QuietZone*mw)
+	els = append(els, element{
+		kind:    kindBars,
+		x:       x,
+		y:       baseline - g.smallSize - 0.4 - barHeight,
+		w:       mw * float64(len(modules)),
+		h:       barHeight,
+		modules: modules,
+	})
+	barsWidth := mw * float64(len(modules))
+	text(x+(barsWidth-textWidth(code, g.smallSize))/2, baseline, g.smallSize, false, code)
+	if g.barWidth < 1 && l.SKU != "" && l.SKU != code {
+		room := g.width - g.margin - (x + barsWidth + 1)
+		sku := clip(l.SKU, fit(room, g.smallSize))
+		text(g.width-g.margin-textWidth(sku, g.smallSize), baseline, g.smallSize, false, sku)
+	}
+	return els
+}
+
+// wrap breaks s into at most lines lines of width characters, cutting the
+// last line short with ".." when the text does not fit.
+func wrap(s string, width, lines int) []string {
+	words := strings.Fields(s)
+	var out []string
+	cur := ""
+	for i := 0; i < len(words); i++ {
+		w := words[i]
+		switch {
+		case cur == "":
+			cur = w
+		case len([]rune(cur))+1+len([]rune(w)) <= width:
+			cur += " " + w
+		default:
+			if len(out) == lines-1 {
+				return append(out, clip(strings.Join(append([]string{cur}, words[i:]...), " "), width))
+			}
+			out = append(out, clip(cur, width))
+			cur = w
+		}
+	}
+	if cur != "" {
+		out = append(out, clip(cur, width))
+	}
+	return out
+}
+
+// clip cuts s to width characters, marking the cut with "..".
+func clip(s string, width int) string {
+	r := []rune(s)
+	if len(r) <= width {
+		return s
+	}
+	if width <= 2 {
+		return string(r[:max(width, 0)])
+	}
+	return string(r[:width-2]) + ".."
+}
+
+// runs returns the start and length of each run of bars in modules.
+func runs(modules []bool) [][2]int {
+	var out [][2]int
+	for i := 0; i < len(modules); {
+		if !modules[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(modules) && modules[j] {
+			j++
+		}
+		out = append(out, [2]int{i, j - i})
+		i = j
+	}
+	return out
+}
+
+// dots converts millimetres to printer dots.
+func dots(mm, perMM float64) int {
+	return int(math.Round(mm * perMM))
+}
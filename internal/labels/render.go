@@ -0,0 +1,169 @@
+package labels
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+	"unicode"
+
+	"NEMBUS/internal/pdf"
+)
+
+// renderPDF draws one label per page, each page the size of the label.
+func renderPDF(pages [][]element, g geometry) []byte {
+	pt := pdf.MillimetersToPoints
+	doc := pdf.New()
+	for _, els := range pages {
+		p := doc.AddPage(pt(g.width), pt(g.height))
+		for _, e := range els {
+			switch e.kind {
+			case kindText:
+				font := pdf.Helvetica
+				if e.bold {
+					font = pdf.HelveticaBold
+				}
+				p.Text(pt(e.x), pt(e.y), font, pt(e.size), e.text)
+			case kindRule:
+				p.Rect(pt(e.x), pt(e.y), pt(e.w), pt(e.h))
+			case kindBars:
+				mw := e.w / float64(len(e.modules))
+				for _, r := range runs(e.modules) {
+					p.Rect(pt(e.x+float64(r[0])*mw), pt(e.y), pt(float64(r[1])*mw), pt(e.h))
+				}
+			}
+		}
+	}
+	return doc.Bytes()
+}
+
+const (
+	// pngDotsPerMM is about 300 dpi.
+	pngDotsPerMM = 12.0
+	// pngGap separates stacked labels, in millimetres.
+	pngGap = 2.0
+)
+
+// renderPNG stacks the labels in one greyscale image, each outlined so it
+// can be cut out.
+func renderPNG(pages [][]element, g geometry) ([]byte, error) {
+	w := dots(g.width, pngDotsPerMM)
+	h := dots(g.height, pngDotsPerMM)
+	gap := dots(pngGap, pngDotsPerMM)
+	img := image.NewGray(image.Rect(0, 0, w, len(pages)*(h+gap)-gap))
+	fill(img, img.Bounds(), color.Gray{Y: 0xff})
+	black := color.Gray{Y: 0}
+	light := color.Gray{Y: 0xc0}
+
+	for i, els := range pages {
+		top := i * (h + gap)
+		frame := image.Rect(0, top, w, top+h)
+		fill(img, image.Rect(frame.Min.X, frame.Min.Y, frame.Max.X, frame.Min.Y+1), light)
+		fill(img, image.Rect(frame.Min.X, frame.Max.Y-1, frame.Max.X, frame.Max.Y), light)
+		fill(img, image.Rect(frame.Min.X, frame.Min.Y, frame.Min.X+1, frame.Max.Y), light)
+		fill(img, image.Rect(frame.Max.X-1, frame.Min.Y, frame.Max.X, frame.Max.Y), light)
+
+		for _, e := range els {
+			x := dots(e.x, pngDotsPerMM)
+			y := top + dots(e.y, pngDotsPerMM)
+			switch e.kind {
+			case kindText:
+				drawText(img.SubImage(frame).(*image.Gray), x, y, e.size, e.bold, e.text)
+			case kindRule:
+				fill(img, image.Rect(x, y, x+dots(e.w, pngDotsPerMM), y+max(1, dots(e.h, pngDotsPerMM))), black)
+			case kindBars:
+				mw := max(1, int(math.Floor(e.w/float64(len(e.modules))*pngDotsPerMM)))
+				for _, r := range runs(e.modules) {
+					fill(img, image.Rect(x+r[0]*mw, y, x+(r[0]+r[1])*mw, y+dots(e.h, pngDotsPerMM)), black)
+				}
+			}
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func fill(img *image.Gray, r image.Rectangle, c color.Gray) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetGray(x, y, c)
+		}
+	}
+}
+
+// drawText draws s with the bitmap font, its baseline at y. A font pixel
+// is a tenth of the em so a 6-pixel advance matches textWidth; bold is
+// drawn twice, one dot apart.
+func drawText(img *image.Gray, x, baseline int, size float64, bold bool, s string) {
+	scale := max(1, int(math.Floor(size*pngDotsPerMM/10)))
+	top := baseline - 7*scale
+	black := color.Gray{Y: 0}
+	for _, r := range s {
+		glyph, ok := glyphs[unicode.ToUpper(r)]
+		if !ok {
+			glyph = glyphs['?']
+		}
+		for row, bits := range glyph {
+			for col := 0; col < 5; col++ {
+				if bits&(0x10>>col) == 0 {
+					continue
+				}
+				px := x + col*scale
+				py := top + row*scale
+				fill(img, image.Rect(px, py, px+scale, py+scale), black)
+				if bold {
+					fill(img, image.Rect(px+1, py, px+scale+1, py+scale), black)
+				}
+			}
+		}
+		x += 6 * scale
+	}
+}
+
+// zplDotsPerMM is the 203 dpi resolution of most label printers.
+const zplDotsPerMM = 8.0
+
+// renderZPL writes one label format per label. Text uses the scalable
+// font 0 in UTF-8 (^CI28); bars are drawn as boxes at whole-dot module
+// widths so the printout matches the other formats.
+func renderZPL(pages [][]element, g geometry) []byte {
+	var b strings.Builder
+	for _, els := range pages {
+		b.WriteString("^XA\n^CI28\n")
+		fmt.Fprintf(&b, "^PW%d\n^LL%d\n", dots(g.width, zplDotsPerMM), dots(g.height, zplDotsPerMM))
+		for _, e := range els {
+			x := dots(e.x, zplDotsPerMM)
+			switch e.kind {
+			case kindText:
+				h := dots(e.size, zplDotsPerMM)
+				fmt.Fprintf(&b, "^FO%d,%d^A0N,%d,%d^FH^FD%s^FS\n", x, dots(e.y-e.size*0.8, zplDotsPerMM), h, dots(e.size*0.6, zplDotsPerMM), zplEscape(e.text))
+			case kindRule:
+				t := max(1, dots(e.h, zplDotsPerMM))
+				fmt.Fprintf(&b, "^FO%d,%d^GB%d,%d,%d^FS\n", x, dots(e.y, zplDotsPerMM), dots(e.w, zplDotsPerMM), t, t)
+			case kindBars:
+				mw := max(1, int(math.Floor(e.w/float64(len(e.modules))*zplDotsPerMM)))
+				y := dots(e.y, zplDotsPerMM)
+				h := dots(e.h, zplDotsPerMM)
+				for _, r := range runs(e.modules) {
+					fmt.Fprintf(&b, "^FO%d,%d^GB%d,%d,%d^FS\n", x+r[0]*mw, y, r[1]*mw, h, r[1]*mw)
+				}
+			}
+		}
+		b.WriteString("^XZ\n")
+	}
+	return []byte(b.String())
+}
+
+// zplEscape hex-escapes the characters ZPL treats as commands, for use
+// after ^FH.
+func zplEscape(s string) string {
+	r := strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E")
+	return r.Replace(s)
+}
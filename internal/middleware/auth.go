@@ -2,6 +2,7 @@ package middleware
 
 import (
 	"errors"
+	"math"
 	"net/http"
 	"os"
 	"strings"
@@ -110,14 +111,16 @@ func GenerateJWTToken(userID string, userLogin string) (string, error) {
 	}
 
 	// Set token expiration (24 hours)
-	expirationTime := time.Now().Add(24 * time.Hour)
+	now := time.Now()
+	expirationTime := now.Add(24 * time.Hour)
 
 	// Create claims
 	claims := jwt.MapClaims{
 		"user_id":    userID,
 		"user_login": userLogin,
 		"exp":        expirationTime.Unix(),
-		"iat":        time.Now().Unix(),
+		// Microseconds, to compare with the sessions' revocation time
+		"iat": float64(now.UnixMicro()) / 1e6,
 	}
 
 	// Create token
@@ -132,6 +135,16 @@ func GenerateJWTToken(userID string, userLogin string) (string, error) {
 	return tokenString, nil
 }
 
+// tokenIssuedAt returns the issue time of a token to the microsecond; see
+// GenerateJWTToken.
+func tokenIssuedAt(claims jwt.MapClaims) (time.Time, bool) {
+	iat, ok := claims["iat"].(float64)
+	if !ok {
+		return time.Time{}, false
+	}
+	return time.UnixMicro(int64(math.Round(iat * 1e6))), true
+}
+
 // GenerateDevToken generates a development token with custom user ID and login
 // This is a convenience function for testing
 func GenerateDevToken(userID, userLogin string) (string, error) {
@@ -0,0 +1,128 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"NEMBUS/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5"
+)
+
+const OrganizationIDKey contextKey = "organization_id"
+
+// OrganizationMiddleware resolves the active organization of the request and
+// stores it, with the signed-in user, in the request context. It must run
+// after JWTAuthMiddleware and TenantMiddleware. Inactive users and tokens
+// issued before the user's sessions were revoked are refused. The
+// x-organization-id header selects an organization; without it the user's
+// own organization is used. A user may select their own organization or one
+// of a store they have access to.
+func OrganizationMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		repo, ok := c.Request.Context().Value(RepoKey).(*repository.Queries)
+		if !ok {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "repository not found in context"})
+			c.Abort()
+			return
+		}
+		userIDStr, _ := GetUserIDFromContext(c)
+		userID, err := strconv.ParseInt(userIDStr, 10, 32)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid user in token"})
+			c.Abort()
+			return
+		}
+		user, err := repo.GetUser(c.Request.Context(), int32(userID))
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User not found"})
+			c.Abort()
+			return
+		}
+		if !user.IsActive.Bool {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "User account is inactive"})
+			c.Abort()
+			return
+		}
+		security, err := repo.GetUserSecurity(c.Request.Context(), user.ID)
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			c.Abort()
+			return
+		}
+		if security.SessionsRevokedAt.Valid {
+			claims, _ := GetClaimsFromContext(c)
+			issuedAt, ok := tokenIssuedAt(claims)
+			if !ok || issuedAt.Before(security.SessionsRevokedAt.Time) {
+				c.JSON(http.StatusUnauthorized, gin.H{"error": "Session revoked, sign in again"})
+				c.Abort()
+				return
+			}
+		}
+
+		orgID := user.OrganizationID
+		if header := c.GetHeader("x-organization-id"); header != "" {
+			id, err := strconv.ParseInt(header, 10, 32)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "x-organization-id must be an organization ID"})
+				c.Abort()
+				return
+			}
+			orgID = int32(id)
+			if orgID != user.OrganizationID {
+				allowed, err := repo.CheckUserHasOrganizationAccess(c.Request.Context(), repository.CheckUserHasOrganizationAccessParams{
+					UserID:         user.ID,
+					OrganizationID: orgID,
+				})
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+					c.Abort()
+					return
+				}
+				if !allowed {
+					c.JSON(http.StatusForbidden, gin.H{"error": "No access to this organization"})
+					c.Abort()
+					return
+				}
+			}
+		}
+		org, err := repo.GetOrganization(c.Request.Context(), orgID)
+		if err != nil || !org.IsActive.Bool {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Organization not found or inactive"})
+			c.Abort()
+			return
+		}
+
+		ctx := WithOrganizationID(WithUserID(c.Request.Context(), user.ID), orgID)
+		c.Request = c.Request.WithContext(ctx)
+		c.Set(string(OrganizationIDKey), orgID)
+
+		c.Next()
+	}
+}
+
+// WithOrganizationID returns a copy of ctx carrying the active organization.
+func WithOrganizationID(ctx context.Context, orgID int32) context.Context {
+	return context.WithValue(ctx, OrganizationIDKey, orgID)
+}
+
+// OrganizationIDFromContext extracts the active organization from a request
+// context
+func OrganizationIDFromContext(ctx context.Context) (int32, bool) {
+	orgID, ok := ctx.Value(OrganizationIDKey).(int32)
+	return orgID, ok
+}
+
+// WithUserID returns a copy of ctx carrying the signed-in user.
+func WithUserID(ctx context.Context, userID int32) context.Context {
+	return context.WithValue(ctx, UserIDKey, userID)
+}
+
+// UserIDFromContext extracts the signed-in user from a request context
+func UserIDFromContext(ctx context.Context) (int32, bool) {
+	userID, ok := ctx.Value(UserIDKey).(int32)
+	return userID, ok
+}
@@ -14,6 +14,7 @@ import (
 type contextKey string
 
 const RepoKey contextKey = "tenant_repo"
+const TenantIDKey contextKey = "tenant_id"
 
 // TenantMiddleware returns a Gin middleware that injects tenant-specific repository
 func TenantMiddleware(tm *manager.Manager) gin.HandlerFunc {
@@ -41,8 +42,16 @@ func TenantMiddleware(tm *manager.Manager) gin.HandlerFunc {
 		// Injects the tenant-specific repository into the request context
 		repo := repository.New(pool)
 		ctx := context.WithValue(c.Request.Context(), RepoKey, repo)
+		ctx = context.WithValue(ctx, TenantIDKey, tenantID)
 		c.Request = c.Request.WithContext(ctx)
 
 		c.Next()
 	}
 }
+
+// TenantIDFromContext extracts the tenant slug of the request from a request
+// context
+func TenantIDFromContext(ctx context.Context) string {
+	tenantID, _ := ctx.Value(TenantIDKey).(string)
+	return tenantID
+}
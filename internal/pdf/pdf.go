@@ -0,0 +1,212 @@
+// Package pdf is a minimal PDF 1.4 writer for the documents this service
+// prints (receipts, labels). It only supports the standard Type 1 fonts,
+// text, lines and filled rectangles, which is all those layouts need. Text
+// is set in the standard Type 1 fonts, or in the embedded DejaVu Sans Mono
+// for scripts they lack, such as Arabic.
+//
+// Coordinates are in points with the origin at the top-left corner of the
+// page; the writer flips them into PDF user space.
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// Font is one of the PDF standard 14 fonts or an embedded TrueType font.
+type Font string
+
+const (
+	Helvetica     Font = "Helvetica"
+	HelveticaBold Font = "Helvetica-Bold"
+	Courier       Font = "Courier"
+	CourierBold   Font = "Courier-Bold"
+
+	// Mono and MonoBold are the embedded DejaVu Sans Mono fonts. They are
+	// written to a document only when used.
+	Mono     Font = "DejaVuSansMono"
+	MonoBold Font = "DejaVuSansMono-Bold"
+)
+
+// fonts is the fixed resource order of the standard fonts; every page
+// references all of them, and the embedded fonts the document uses.
+var fonts = []Font{Helvetica, HelveticaBold, Courier, CourierBold}
+
+// embedded is the resource order of the embedded fonts.
+var embedded = []Font{Mono, MonoBold}
+
+var loaded = func() map[Font]*trueType {
+	m := map[Font]*trueType{}
+	for _, f := range embedded {
+		tt, err := loadTrueType(f)
+		if err != nil {
+			panic(err)
+		}
+		m[f] = tt
+	}
+	return m
+}()
+
+// MillimetersToPoints converts a length in millimetres to PDF points.
+func MillimetersToPoints(mm float64) float64 {
+	return mm * 72 / 25.4
+}
+
+// Document is an in-memory PDF document.
+type Document struct {
+	pages []*Page
+	// glyphs records, per embedded font, the glyphs used and the
+	// characters they were drawn for.
+	glyphs map[Font]map[uint16]rune
+}
+
+// Page is a single page of a Document.
+type Page struct {
+	Width   float64
+	Height  float64
+	doc     *Document
+	content bytes.Buffer
+}
+
+// New creates an empty document.
+func New() *Document {
+	return &Document{glyphs: map[Font]map[uint16]rune{}}
+}
+
+// AddPage appends a page of the given size in points and returns it.
+func (d *Document) AddPage(width, height float64) *Page {
+	p := &Page{Width: width, Height: height, doc: d}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// Text draws s with its baseline at (x, y). With the standard fonts,
+// characters outside the Windows-1252 repertoire are replaced with '?', as
+// those fonts cannot render them. The embedded fonts draw glyphs as given,
+// so Arabic must already be shaped and in display order (see
+// arabic.Visual).
+func (p *Page) Text(x, y float64, font Font, size float64, s string) {
+	text := "(" + escape(s) + ")"
+	if tt, ok := loaded[font]; ok {
+		used := p.doc.glyphs[font]
+		if used == nil {
+			used = map[uint16]rune{}
+			p.doc.glyphs[font] = used
+		}
+		var b strings.Builder
+		b.WriteByte('<')
+		for _, r := range s {
+			if r == '\r' || r == '\n' {
+				continue
+			}
+			g := tt.glyph(r)
+			if _, ok := used[g]; !ok {
+				used[g] = r
+			}
+			fmt.Fprintf(&b, "%04X", g)
+		}
+		b.WriteByte('>')
+		text = b.String()
+	}
+	fmt.Fprintf(&p.content, "BT /%s %.2f Tf %.2f %.2f Td %s Tj ET\n",
+		fontResource(font), size, x, p.Height-y, text)
+}
+
+// Rect draws a filled black rectangle whose top-left corner is (x, y).
+func (p *Page) Rect(x, y, w, h float64) {
+	fmt.Fprintf(&p.content, "%.3f %.3f %.3f %.3f re f\n", x, p.Height-y-h, w, h)
+}
+
+// Line draws a straight black line of the given stroke width.
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.content, "%.2f w %.2f %.2f m %.2f %.2f l S\n",
+		width, x1, p.Height-y1, x2, p.Height-y2)
+}
+
+// TextWidth returns the width in points of s set in a Courier font. Other
+// fonts are approximated with the same metrics.
+func TextWidth(s string, size float64) float64 {
+	return float64(len([]rune(s))) * size * 0.6
+}
+
+// Bytes serialises the document.
+func (d *Document) Bytes() []byte {
+	var buf bytes.Buffer
+	offsets := []int{0}
+	obj := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets)-1, body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+
+	// 1: catalog, 2: page tree, 3..: standard fonts, then five objects per
+	// embedded font in use, then page/content pairs.
+	var used []Font
+	for _, f := range embedded {
+		if len(d.glyphs[f]) > 0 {
+			used = append(used, f)
+		}
+	}
+	firstPage := 3 + len(fonts) + 5*len(used)
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+i*2)
+	}
+	obj("<< /Type /Catalog /Pages 2 0 R >>")
+	obj(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+
+	var fontRefs strings.Builder
+	for i, f := range fonts {
+		obj(fmt.Sprintf("<< /Type /Font /Subtype /Type1 /BaseFont /%s /Encoding /WinAnsiEncoding >>", f))
+		fmt.Fprintf(&fontRefs, "/%s %d 0 R ", fontResource(f), 3+i)
+	}
+	for _, f := range used {
+		id := loaded[f].objects(obj, len(offsets), d.glyphs[f])
+		fmt.Fprintf(&fontRefs, "/%s %d 0 R ", fontResource(f), id)
+	}
+
+	for i, p := range d.pages {
+		obj(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << %s>> >> /Contents %d 0 R >>",
+			p.Width, p.Height, fontRefs.String(), firstPage+i*2+1))
+		obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", p.content.Len(), p.content.String()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets))
+	for _, off := range offsets[1:] {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets), xref)
+	return buf.Bytes()
+}
+
+func fontResource(f Font) string {
+	for i, known := range append(fonts, embedded...) {
+		if known == f {
+			return fmt.Sprintf("F%d", i+1)
+		}
+	}
+	return "F1"
+}
+
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		c, ok := charmap.Windows1252.EncodeRune(r)
+		if !ok {
+			c = '?'
+		}
+		switch c {
+		case '\\', '(', ')':
+			b.WriteByte('\\')
+		case '\r', '\n':
+			continue
+		}
+		b.WriteByte(c)
+	}
+	return b.String()
+}
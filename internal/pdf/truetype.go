@@ -0,0 +1,352 @@
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"embed"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DejaVu Sans Mono covers Latin and Arabic, including the Arabic
+// presentation forms, so shaped Arabic text can be set with it. See
+// fonts/LICENSE.
+//
+//go:embed fonts/DejaVuSansMono.ttf fonts/DejaVuSansMono-Bold.ttf
+var fontFiles embed.FS
+
+// trueType is a parsed TrueType font embedded as a CID-keyed font
+// (Identity-H), so text is written as glyph ids.
+type trueType struct {
+	name     string
+	tables   map[string][]byte
+	unitsEm  float64
+	bbox     [4]int16
+	ascent   int16
+	descent  int16
+	capH     int16
+	advances []uint16
+	glyphs   map[rune]uint16
+}
+
+var trueTypes = map[Font]string{
+	Mono:     "fonts/DejaVuSansMono.ttf",
+	MonoBold: "fonts/DejaVuSansMono-Bold.ttf",
+}
+
+// loadTrueType parses the tables of an embedded font the writer needs:
+// head, hhea, hmtx, OS/2 and the Unicode BMP cmap for metrics and glyph
+// ids, and maxp, loca and glyf for subsetting.
+func loadTrueType(font Font) (*trueType, error) {
+	data, err := fontFiles.ReadFile(trueTypes[font])
+	if err != nil {
+		return nil, err
+	}
+	tables := map[string][]byte{}
+	if len(data) < 12 {
+		return nil, fmt.Errorf("font %s: truncated", font)
+	}
+	n := int(binary.BigEndian.Uint16(data[4:]))
+	for i := 0; i < n; i++ {
+		rec := data[12+16*i:]
+		off, length := binary.BigEndian.Uint32(rec[8:]), binary.BigEndian.Uint32(rec[12:])
+		if int(off+length) > len(data) {
+			return nil, fmt.Errorf("font %s: table %s out of range", font, rec[:4])
+		}
+		tables[string(rec[:4])] = data[off : off+length]
+	}
+	for _, t := range []string{"head", "hhea", "hmtx", "maxp", "loca", "glyf", "post", "cmap", "OS/2"} {
+		if tables[t] == nil {
+			return nil, fmt.Errorf("font %s: no %s table", font, t)
+		}
+	}
+
+	f := &trueType{name: string(font), tables: tables, glyphs: map[rune]uint16{}}
+	head := tables["head"]
+	f.unitsEm = float64(binary.BigEndian.Uint16(head[18:]))
+	for i := range f.bbox {
+		f.bbox[i] = int16(binary.BigEndian.Uint16(head[36+2*i:]))
+	}
+	hhea := tables["hhea"]
+	f.ascent = int16(binary.BigEndian.Uint16(hhea[4:]))
+	f.descent = int16(binary.BigEndian.Uint16(hhea[6:]))
+	metrics := int(binary.BigEndian.Uint16(hhea[34:]))
+	hmtx := tables["hmtx"]
+	f.advances = make([]uint16, metrics)
+	for i := range f.advances {
+		f.advances[i] = binary.BigEndian.Uint16(hmtx[4*i:])
+	}
+	f.capH = f.ascent
+	if os2 := tables["OS/2"]; binary.BigEndian.Uint16(os2) >= 2 && len(os2) >= 90 {
+		f.capH = int16(binary.BigEndian.Uint16(os2[88:]))
+	}
+	if err := f.parseCmap(tables["cmap"]); err != nil {
+		return nil, fmt.Errorf("font %s: %w", font, err)
+	}
+	return f, nil
+}
+
+// parseCmap reads the Windows Unicode BMP subtable (platform 3, encoding 1,
+// format 4).
+func (f *trueType) parseCmap(cmap []byte) error {
+	count := int(binary.BigEndian.Uint16(cmap[2:]))
+	for i := 0; i < count; i++ {
+		rec := cmap[4+8*i:]
+		if binary.BigEndian.Uint16(rec) != 3 || binary.BigEndian.Uint16(rec[2:]) != 1 {
+			continue
+		}
+		sub := cmap[binary.BigEndian.Uint32(rec[4:]):]
+		if binary.BigEndian.Uint16(sub) != 4 {
+			continue
+		}
+		segs := int(binary.BigEndian.Uint16(sub[6:])) / 2
+		ends := sub[14:]
+		starts := ends[2*segs+2:]
+		deltas := starts[2*segs:]
+		offsets := deltas[2*segs:]
+		for s := 0; s < segs; s++ {
+			start, end := binary.BigEndian.Uint16(starts[2*s:]), binary.BigEndian.Uint16(ends[2*s:])
+			delta := binary.BigEndian.Uint16(deltas[2*s:])
+			ro := int(binary.BigEndian.Uint16(offsets[2*s:]))
+			for c := uint32(start); c <= uint32(end) && c != 0xFFFF; c++ {
+				var g uint16
+				if ro == 0 {
+					g = uint16(c) + delta
+				} else {
+					at := 2*s + ro + 2*int(c-uint32(start))
+					if at+2 > len(offsets) {
+						break
+					}
+					if g = binary.BigEndian.Uint16(offsets[at:]); g != 0 {
+						g += delta
+					}
+				}
+				if g != 0 {
+					f.glyphs[rune(c)] = g
+				}
+			}
+		}
+		return nil
+	}
+	return fmt.Errorf("no Unicode BMP cmap")
+}
+
+// glyph returns the glyph id of r, or of '?' when the font lacks it.
+func (f *trueType) glyph(r rune) uint16 {
+	if g, ok := f.glyphs[r]; ok {
+		return g
+	}
+	return f.glyphs['?']
+}
+
+func (f *trueType) advance(g uint16) int {
+	if len(f.advances) == 0 {
+		return 0
+	}
+	if int(g) >= len(f.advances) {
+		g = uint16(len(f.advances) - 1)
+	}
+	return int(float64(f.advances[g]) * 1000 / f.unitsEm)
+}
+
+// scale converts font units to the 1000 unit text space of PDF fonts.
+func (f *trueType) scale(v int16) int {
+	return int(float64(v) * 1000 / f.unitsEm)
+}
+
+// objects writes the font dictionaries, with a ToUnicode map and widths for
+// the glyphs in used, and returns the number of the Type0 font object.
+// next is the number the first object gets.
+func (f *trueType) objects(obj func(string), next int, used map[uint16]rune) int {
+	gids := make([]int, 0, len(used))
+	for g := range used {
+		gids = append(gids, int(g))
+	}
+	sort.Ints(gids)
+
+	var widths, cmap strings.Builder
+	for _, g := range gids {
+		fmt.Fprintf(&widths, "%d [%d] ", g, f.advance(uint16(g)))
+	}
+	fmt.Fprintf(&cmap, "/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n"+
+		"/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n"+
+		"/CMapName /Adobe-Identity-UCS def /CMapType 2 def\n"+
+		"1 begincodespacerange <0000> <FFFF> endcodespacerange\n")
+	for i := 0; i < len(gids); i += 100 {
+		chunk := gids[i:min(i+100, len(gids))]
+		fmt.Fprintf(&cmap, "%d beginbfchar\n", len(chunk))
+		for _, g := range chunk {
+			fmt.Fprintf(&cmap, "<%04X> <%s>\n", g, utf16Hex(used[uint16(g)]))
+		}
+		cmap.WriteString("endbfchar\n")
+	}
+	cmap.WriteString("endcmap CMapName currentdict /CMap defineresource pop end end\n")
+
+	data := f.subset(used)
+	var packed bytes.Buffer
+	zw := zlib.NewWriter(&packed)
+	zw.Write(data)
+	zw.Close()
+
+	font, cid, desc, file, toUnicode := next, next+1, next+2, next+3, next+4
+	obj(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.name, cid, toUnicode))
+	obj(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /W [%s] >>",
+		f.name, desc, widths.String()))
+	obj(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 33 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.name, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.capH), file))
+	obj(fmt.Sprintf("<< /Length %d /Length1 %d /Filter /FlateDecode >>\nstream\n%s\nendstream", packed.Len(), len(data), packed.String()))
+	obj(fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", cmap.Len(), cmap.String()))
+	return font
+}
+
+func utf16Hex(r rune) string {
+	if r > 0xFFFF {
+		r -= 0x10000
+		return fmt.Sprintf("%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+	}
+	return fmt.Sprintf("%04X", r)
+}
+
+// subsetTables are the tables a PDF reader needs from an embedded font.
+var subsetTables = []string{"OS/2", "cvt ", "fpgm", "glyf", "head", "hhea", "hmtx", "loca", "maxp", "name", "post", "prep"}
+
+// subset returns the font with the outlines of all glyphs but the used ones
+// (with the components of composite glyphs, and glyph 0) removed. Glyph ids
+// are kept, so the text needs no remapping.
+func (f *trueType) subset(used map[uint16]rune) []byte {
+	glyf := f.tables["glyf"]
+	numGlyphs := int(binary.BigEndian.Uint16(f.tables["maxp"][4:]))
+	longLoca := binary.BigEndian.Uint16(f.tables["head"][50:]) == 1
+	offset := func(g int) int {
+		if longLoca {
+			return int(binary.BigEndian.Uint32(f.tables["loca"][4*g:]))
+		}
+		return 2 * int(binary.BigEndian.Uint16(f.tables["loca"][2*g:]))
+	}
+	outline := func(g int) []byte {
+		if g >= numGlyphs {
+			return nil
+		}
+		start, end := offset(g), offset(g+1)
+		if start >= end || end > len(glyf) {
+			return nil
+		}
+		return glyf[start:end]
+	}
+
+	keep := map[int]bool{0: true}
+	queue := []int{0}
+	for g := range used {
+		if !keep[int(g)] {
+			keep[int(g)] = true
+			queue = append(queue, int(g))
+		}
+	}
+	for len(queue) > 0 {
+		g := queue[0]
+		queue = queue[1:]
+		data := outline(g)
+		if len(data) < 10 || int16(binary.BigEndian.Uint16(data)) >= 0 {
+			continue
+		}
+		// Composite glyph: walk the component records.
+		for p := 10; p+4 <= len(data); {
+			flags := binary.BigEndian.Uint16(data[p:])
+			component := int(binary.BigEndian.Uint16(data[p+2:]))
+			if !keep[component] {
+				keep[component] = true
+				queue = append(queue, component)
+			}
+			p += 4
+			if flags&0x0001 != 0 {
+				p += 4
+			} else {
+				p += 2
+			}
+			switch {
+			case flags&0x0008 != 0:
+				p += 2
+			case flags&0x0040 != 0:
+				p += 4
+			case flags&0x0080 != 0:
+				p += 8
+			}
+			if flags&0x0020 == 0 {
+				break
+			}
+		}
+	}
+
+	var newGlyf bytes.Buffer
+	loca := make([]byte, 4*(numGlyphs+1))
+	for g := 0; g < numGlyphs; g++ {
+		binary.BigEndian.PutUint32(loca[4*g:], uint32(newGlyf.Len()))
+		if keep[g] {
+			newGlyf.Write(outline(g))
+			for newGlyf.Len()%4 != 0 {
+				newGlyf.WriteByte(0)
+			}
+		}
+	}
+	binary.BigEndian.PutUint32(loca[4*numGlyphs:], uint32(newGlyf.Len()))
+	head := append([]byte(nil), f.tables["head"]...)
+	binary.BigEndian.PutUint32(head[8:], 0)
+	binary.BigEndian.PutUint16(head[50:], 1)
+
+	// A version 3 post table has no glyph names.
+	post := append([]byte(nil), f.tables["post"][:32]...)
+	binary.BigEndian.PutUint32(post, 0x00030000)
+
+	tables := map[string][]byte{"glyf": newGlyf.Bytes(), "loca": loca, "head": head, "post": post}
+	var names []string
+	for _, t := range subsetTables {
+		if tables[t] == nil {
+			tables[t] = f.tables[t]
+		}
+		if tables[t] != nil {
+			names = append(names, t)
+		}
+	}
+
+	var out bytes.Buffer
+	n := len(names)
+	entrySelector := 0
+	for 1<<(entrySelector+1) <= n {
+		entrySelector++
+	}
+	searchRange := 16 << entrySelector
+	binary.Write(&out, binary.BigEndian, []uint16{0x0001, 0x0000, uint16(n), uint16(searchRange), uint16(entrySelector), uint16(16*n - searchRange)})
+	offsetAt := 12 + 16*n
+	var body bytes.Buffer
+	headAt := 0
+	for _, t := range names {
+		data := tables[t]
+		if t == "head" {
+			headAt = offsetAt + body.Len()
+		}
+		out.WriteString(t)
+		binary.Write(&out, binary.BigEndian, []uint32{checksum(data), uint32(offsetAt + body.Len()), uint32(len(data))})
+		body.Write(data)
+		for body.Len()%4 != 0 {
+			body.WriteByte(0)
+		}
+	}
+	out.Write(body.Bytes())
+	font := out.Bytes()
+	binary.BigEndian.PutUint32(font[headAt+8:], 0xB1B0AFBA-checksum(font))
+	return font
+}
+
+func checksum(b []byte) uint32 {
+	var sum uint32
+	for i := 0; i < len(b); i += 4 {
+		var word [4]byte
+		copy(word[:], b[i:])
+		sum += binary.BigEndian.Uint32(word[:])
+	}
+	return sum
+}
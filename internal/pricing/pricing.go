@@ -0,0 +1,274 @@
+// Package pricing picks the selling price of a product from the price lists
+// that apply to a sale. It works on plain values loaded by the caller and
+// records every decision it takes, so a resolved price can be explained.
+//
+// Price lists are tried in source precedence order:
+//
+//	customer > customer_type > store > promotion > default
+//
+// and, within one source, by descending assignment priority. The first list
+// that has a usable price wins. Inside a list a price row must be valid on
+// the sale date, match the variant (or be product-level), match the unit of
+// measure (or have none) and cover the quantity; among those the most
+// specific row wins: variant over product-level, exact UoM over unspecified,
+// highest quantity tier, latest valid_from.
+package pricing
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+)
+
+// Source is the reason a price list applies to a sale.
+type Source string
+
+const (
+	SourceCustomer     Source = "customer"
+	SourceCustomerType Source = "customer_type"
+	SourceStore        Source = "store"
+	SourcePromotion    Source = "promotion"
+	SourceDefault      Source = "default"
+	// SourceExplicit is a list chosen by the caller (e.g. on a sales order);
+	// it is the only list considered.
+	SourceExplicit Source = "explicit"
+)
+
+var precedence = map[Source]int{
+	SourceExplicit:     0,
+	SourceCustomer:     1,
+	SourceCustomerType: 2,
+	SourceStore:        3,
+	SourcePromotion:    4,
+	SourceDefault:      5,
+}
+
+// ParseScope validates a price list assignment scope. Default lists are
+// flagged on the price list itself and cannot be assigned.
+func ParseScope(s string) (Source, error) {
+	switch src := Source(s); src {
+	case SourceCustomer, SourceCustomerType, SourceStore, SourcePromotion:
+		return src, nil
+	}
+	return "", fmt.Errorf("invalid price list scope %q (use customer, customer_type, store or promotion)", s)
+}
+
+// List is a price list applicable to the sale.
+type List struct {
+	ID       int32
+	Code     string
+	Source   Source
+	Priority int32
+}
+
+// Price is one product_prices row. Zero IDs and nil pointers mean "not set".
+type Price struct {
+	ID          int32
+	PriceListID int32
+	ProductID   int32
+	VariantID   int32
+	UomID       int32
+	MinQuantity *big.Rat
+	MaxQuantity *big.Rat
+	ValidFrom   *time.Time
+	ValidTo     *time.Time
+	Price       *big.Rat
+}
+
+// Request describes what is being priced.
+type Request struct {
+	ProductID int32
+	VariantID int32
+	// UomID is the selling unit; zero means the product's base unit. When
+	// both UomID and BaseUomID are zero (product listings) any unit matches.
+	UomID     int32
+	BaseUomID int32
+	Quantity  *big.Rat
+	Date      time.Time
+}
+
+// Result is a resolved price and how it was found.
+type Result struct {
+	Price         *big.Rat
+	PriceID       int32
+	PriceListID   int32
+	PriceListCode string
+	Source        Source
+	MinQuantity   *big.Rat
+	UomID         int32
+	// Explanation lists, in order, every list that was tried and why it
+	// did or did not price the request.
+	Explanation []string
+}
+
+// ErrNoPrice is returned when no applicable list prices the request.
+var ErrNoPrice = errors.New("no applicable price")
+
+// SortLists orders lists by precedence and drops repeats of a list that is
+// already applicable through a stronger source.
+func SortLists(lists []List) []List {
+	out := append([]List(nil), lists...)
+	sort.SliceStable(out, func(i, j int) bool {
+		pi, pj := precedence[out[i].Source], precedence[out[j].Source]
+		if pi != pj {
+			return pi < pj
+		}
+		if out[i].Priority != out[j].Priority {
+			return out[i].Priority > out[j].Priority
+		}
+		return out[i].ID < out[j].ID
+	})
+	seen := map[int32]bool{}
+	n := 0
+	for _, l := range out {
+		if seen[l.ID] {
+			continue
+		}
+		seen[l.ID] = true
+		out[n] = l
+		n++
+	}
+	return out[:n]
+}
+
+// Resolve prices req from lists (in any order) using the candidate price
+// rows. Rows of other products are ignored. On ErrNoPrice the returned
+// result still carries the explanation.
+func Resolve(lists []List, prices []Price, req Request) (*Result, error) {
+	qty := req.Quantity
+	if qty == nil {
+		qty = big.NewRat(1, 1)
+	}
+	uom := req.UomID
+	if uom == 0 {
+		uom = req.BaseUomID
+	}
+	date := truncateDay(req.Date)
+
+	byList := map[int32][]Price{}
+	for _, p := range prices {
+		if p.ProductID == req.ProductID {
+			byList[p.PriceListID] = append(byList[p.PriceListID], p)
+		}
+	}
+
+	res := &Result{}
+	for _, l := range SortLists(lists) {
+		best, why := pick(byList[l.ID], req.VariantID, uom, req.BaseUomID, qty, date)
+		label := fmt.Sprintf("%s list %s", l.Source, l.Code)
+		if best == nil {
+			res.Explanation = append(res.Explanation, label+": "+why)
+			continue
+		}
+		res.Price = new(big.Rat).Set(best.Price)
+		res.PriceID = best.ID
+		res.PriceListID = l.ID
+		res.PriceListCode = l.Code
+		res.Source = l.Source
+		res.MinQuantity = best.MinQuantity
+		res.UomID = best.UomID
+		res.Explanation = append(res.Explanation, label+": selected price "+best.Price.FloatString(2)+" ("+describe(best)+")")
+		return res, nil
+	}
+	if len(lists) == 0 {
+		res.Explanation = append(res.Explanation, "no price list applies to this sale")
+	}
+	return res, ErrNoPrice
+}
+
+// pick returns the most specific usable row of one list, or the reason none
+// was usable.
+func pick(rows []Price, variant, uom, baseUom int32, qty *big.Rat, date time.Time) (*Price, string) {
+	if len(rows) == 0 {
+		return nil, "no price for this product"
+	}
+	var usable []Price
+	reason := "no price for this product"
+	for _, p := range rows {
+		switch {
+		case p.VariantID != 0 && p.VariantID != variant:
+			reason = "no price for this variant"
+			continue
+		case uom != 0 && p.UomID != 0 && p.UomID != uom:
+			reason = "no price in this unit of measure"
+			continue
+		case p.UomID == 0 && baseUom != 0 && uom != baseUom:
+			// A row without a unit prices the base unit only.
+			reason = "no price in this unit of measure"
+			continue
+		case p.ValidFrom != nil && truncateDay(*p.ValidFrom).After(date):
+			reason = "price not yet valid on " + date.Format("2006-01-02")
+			continue
+		case p.ValidTo != nil && truncateDay(*p.ValidTo).Before(date):
+			reason = "price expired before " + date.Format("2006-01-02")
+			continue
+		case p.MinQuantity != nil && p.MinQuantity.Cmp(qty) > 0:
+			reason = "quantity " + qty.FloatString(3) + " below the minimum tier"
+			continue
+		case p.MaxQuantity != nil && p.MaxQuantity.Cmp(qty) < 0:
+			reason = "quantity " + qty.FloatString(3) + " above the maximum tier"
+			continue
+		case p.Price == nil:
+			continue
+		}
+		usable = append(usable, p)
+	}
+	if len(usable) == 0 {
+		return nil, reason
+	}
+	sort.SliceStable(usable, func(i, j int) bool {
+		a, b := usable[i], usable[j]
+		if (a.VariantID != 0) != (b.VariantID != 0) {
+			return a.VariantID != 0
+		}
+		if (a.UomID != 0) != (b.UomID != 0) {
+			return a.UomID != 0
+		}
+		if c := ratOrZero(a.MinQuantity).Cmp(ratOrZero(b.MinQuantity)); c != 0 {
+			return c > 0
+		}
+		if fa, fb := timeOrZero(a.ValidFrom), timeOrZero(b.ValidFrom); !fa.Equal(fb) {
+			return fa.After(fb)
+		}
+		return a.ID > b.ID
+	})
+	return &usable[0], ""
+}
+
+func describe(p *Price) string {
+	s := "product-level"
+	if p.VariantID != 0 {
+		s = "variant-specific"
+	}
+	if p.UomID != 0 {
+		s += fmt.Sprintf(", unit %d", p.UomID)
+	}
+	if p.MinQuantity != nil && p.MinQuantity.Sign() > 0 {
+		s += ", tier from " + p.MinQuantity.FloatString(3)
+	}
+	if p.ValidFrom != nil || p.ValidTo != nil {
+		s += ", dated"
+	}
+	return s
+}
+
+func truncateDay(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, time.UTC)
+}
+
+func timeOrZero(t *time.Time) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+	return *t
+}
+
+func ratOrZero(r *big.Rat) *big.Rat {
+	if r == nil {
+		return new(big.Rat)
+	}
+	return r
+}
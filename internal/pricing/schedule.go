@@ -0,0 +1,64 @@
+package pricing
+
+import (
+	"math/big"
+	"time"
+)
+
+// Change plans a dated price change: a new version of a price (same list,
+// product, variant, unit and quantity tier) that takes effect on a date.
+// Because prices are resolved against the sale date, the new version
+// activates on its own once the date is reached.
+type Change struct {
+	// Close lists versions still running on the effective date; they end
+	// the day before.
+	Close []int32
+	// CloseOn is the valid_to given to the closed versions.
+	CloseOn time.Time
+	// Replace is a version that already starts on the effective date; its
+	// price is updated instead of inserting another version.
+	Replace int32
+	// ValidTo ends the new version the day before the next scheduled
+	// version, if there is one.
+	ValidTo *time.Time
+}
+
+// PlanChange plans a new version starting on from among the existing
+// versions of the same price.
+func PlanChange(versions []Price, from time.Time) Change {
+	from = truncateDay(from)
+	ch := Change{CloseOn: from.AddDate(0, 0, -1)}
+	for _, v := range versions {
+		switch {
+		case v.ValidFrom != nil && truncateDay(*v.ValidFrom).Equal(from):
+			ch.Replace = v.ID
+		case v.ValidFrom != nil && truncateDay(*v.ValidFrom).After(from):
+			next := truncateDay(*v.ValidFrom).AddDate(0, 0, -1)
+			if ch.ValidTo == nil || next.Before(*ch.ValidTo) {
+				ch.ValidTo = &next
+			}
+		case v.ValidTo == nil || !truncateDay(*v.ValidTo).Before(from):
+			ch.Close = append(ch.Close, v.ID)
+		}
+	}
+	return ch
+}
+
+// Adjust applies a bulk adjustment to price: a percentage when percent is
+// true, otherwise a fixed amount. The result is rounded to scale places
+// and never negative.
+func Adjust(price, amount *big.Rat, percent bool, scale int) *big.Rat {
+	out := new(big.Rat)
+	if percent {
+		factor := new(big.Rat).Quo(amount, big.NewRat(100, 1))
+		factor.Add(factor, big.NewRat(1, 1))
+		out.Mul(price, factor)
+	} else {
+		out.Add(price, amount)
+	}
+	if out.Sign() < 0 {
+		return new(big.Rat)
+	}
+	r, _ := new(big.Rat).SetString(out.FloatString(scale))
+	return r
+}
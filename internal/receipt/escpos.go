@@ -0,0 +1,143 @@
+package receipt
+
+import (
+	"bytes"
+	"strings"
+
+	"NEMBUS/internal/arabic"
+
+	"golang.org/x/text/encoding/charmap"
+)
+
+// ESC/POS control sequences used by the renderer.
+var (
+	escInit    = []byte{0x1b, '@'}
+	escBoldOn  = []byte{0x1b, 'E', 1}
+	escBoldOff = []byte{0x1b, 'E', 0}
+	escFeedCut = []byte{0x1d, 'V', 'B', 3}
+)
+
+// ESC t character tables, as numbered by Epson.
+const (
+	CodePageWPC1252 = 16
+	CodePagePC864   = 37
+)
+
+// renderESCPOS encodes the laid-out rows for a thermal printer and selects
+// the matching character table. English receipts are sent in Windows-1252.
+// Arabic and bilingual ones are sent in PC864, whose table holds the
+// contextual letter forms the rows are shaped into, on the template's
+// CodePage.
+func renderESCPOS(rows []row, r *Receipt, tpl Template) []byte {
+	encode, table := encode1252, byte(CodePageWPC1252)
+	if tpl.Language != LanguageEnglish {
+		encode, table = encodePC864, byte(tpl.CodePage)
+		if tpl.CodePage <= 0 {
+			table = CodePagePC864
+		}
+	}
+
+	var buf bytes.Buffer
+	buf.Write(escInit)
+	buf.Write([]byte{0x1b, 't', table})
+	for _, rw := range rows {
+		if rw.bold {
+			buf.Write(escBoldOn)
+		}
+		for _, line := range strings.Split(rw.text, "\n") {
+			for _, c := range line {
+				buf.WriteByte(encode(c))
+			}
+			buf.WriteByte('\n')
+		}
+		if rw.bold {
+			buf.Write(escBoldOff)
+		}
+	}
+	if tpl.ShowBarcode && r.QRCode != "" {
+		writeQRCode(&buf, r.QRCode)
+	}
+	buf.Write(escFeedCut)
+	return buf.Bytes()
+}
+
+// writeQRCode prints data as a centred QR code using the printer's native
+// GS ( k model 2 commands.
+func writeQRCode(buf *bytes.Buffer, data string) {
+	buf.Write([]byte{0x1b, 'a', 1})
+	// model 2
+	buf.Write([]byte{0x1d, '(', 'k', 4, 0, '1', 'A', '2', 0})
+	// module size
+	buf.Write([]byte{0x1d, '(', 'k', 3, 0, '1', 'C', 5})
+	// error correction level M
+	buf.Write([]byte{0x1d, '(', 'k', 3, 0, '1', 'E', '1'})
+	n := len(data) + 3
+	buf.Write([]byte{0x1d, '(', 'k', byte(n), byte(n >> 8), '1', 'P', '0'})
+	buf.WriteString(data)
+	buf.Write([]byte{0x1d, '(', 'k', 3, 0, '1', 'Q', '0'})
+	buf.Write([]byte{'\n', 0x1b, 'a', 0})
+}
+
+func encode1252(c rune) byte {
+	b, ok := charmap.Windows1252.EncodeRune(c)
+	if !ok {
+		return '?'
+	}
+	return b
+}
+
+// encodePC864 encodes a shaped character in PC864. The code page lacks some
+// letter forms; a medial form falls back to the initial one and the other
+// forms to the isolated one, which is how those letters are printed.
+func encodePC864(c rune) byte {
+	if b, ok := pc864Bytes[c]; ok {
+		return b
+	}
+	if letter, form, ok := arabic.Base(c); ok {
+		f, _ := arabic.Forms(letter)
+		try := []arabic.Form{arabic.Isolated, arabic.Final}
+		if form == arabic.Medial || form == arabic.Initial {
+			try = []arabic.Form{arabic.Initial, arabic.Isolated}
+		}
+		for _, alt := range try {
+			if b, ok := pc864Bytes[f[alt]]; ok && f[alt] != 0 {
+				return b
+			}
+		}
+	}
+	return '?'
+}
+
+// pc864 is the upper half of IBM code page 864; the lower half is ASCII,
+// except that '%' prints as the Arabic percent sign.
+var pc864 = [128]rune{
+	0x00B0, 0x00B7, 0x2219, 0x221A, 0x2592, 0x2500, 0x2502, 0x253C,
+	0x2524, 0x252C, 0x251C, 0x2534, 0x2510, 0x250C, 0x2514, 0x2518,
+	0x03B2, 0x221E, 0x03C6, 0x00B1, 0x00BD, 0x00BC, 0x2248, 0x00AB,
+	0x00BB, 0xFEF7, 0xFEF8, 0, 0, 0xFEFB, 0xFEFC, 0,
+	0x00A0, 0x00AD, 0xFE82, 0x00A3, 0x00A4, 0xFE84, 0, 0,
+	0xFE8E, 0xFE8F, 0xFE95, 0xFE99, 0x060C, 0xFE9D, 0xFEA1, 0xFEA5,
+	0x0660, 0x0661, 0x0662, 0x0663, 0x0664, 0x0665, 0x0666, 0x0667,
+	0x0668, 0x0669, 0xFED1, 0x061B, 0xFEB1, 0xFEB5, 0xFEB9, 0x061F,
+	0x00A2, 0xFE80, 0xFE81, 0xFE83, 0xFE85, 0xFECA, 0xFE8B, 0xFE8D,
+	0xFE91, 0xFE93, 0xFE97, 0xFE9B, 0xFE9F, 0xFEA3, 0xFEA7, 0xFEA9,
+	0xFEAB, 0xFEAD, 0xFEAF, 0xFEB3, 0xFEB7, 0xFEBB, 0xFEBF, 0xFEC1,
+	0xFEC5, 0xFECB, 0xFECF, 0x00A6, 0x00AC, 0x00F7, 0x00D7, 0xFEC9,
+	0x0640, 0xFED3, 0xFED7, 0xFEDB, 0xFEDF, 0xFEE3, 0xFEE7, 0xFEEB,
+	0xFEED, 0xFEEF, 0xFEF3, 0xFEBD, 0xFECC, 0xFECE, 0xFECD, 0xFEE1,
+	0xFE7D, 0x0651, 0xFEE5, 0xFEE9, 0xFEEC, 0xFEF0, 0xFEF2, 0xFED0,
+	0xFED5, 0xFEF5, 0xFEF6, 0xFEDD, 0xFED9, 0xFEF1, 0x25A0, 0,
+}
+
+var pc864Bytes = func() map[rune]byte {
+	m := map[rune]byte{'٪': '%'}
+	for c := rune(0); c < 0x80; c++ {
+		m[c] = byte(c)
+	}
+	for i, c := range pc864 {
+		if c != 0 {
+			m[c] = byte(0x80 + i)
+		}
+	}
+	return m
+}()
@@ -0,0 +1,47 @@
+package receipt
+
+import (
+	"strings"
+
+	"NEMBUS/internal/pdf"
+)
+
+const (
+	pdfFontSize   = 8.0
+	pdfLineHeight = 10.0
+	pdfMargin     = 8.0
+)
+
+// renderPDF prints the rows in the embedded DejaVu Sans Mono, which has the
+// Arabic letter forms, on a single page sized for 80mm roll paper, growing
+// the page height with the receipt length.
+func renderPDF(rows []row, tpl Template) []byte {
+	var lines []row
+	for _, r := range rows {
+		for _, l := range strings.Split(r.text, "\n") {
+			lines = append(lines, row{text: l, bold: r.bold})
+		}
+	}
+
+	pageWidth := pdf.MillimetersToPoints(80)
+	// Shrink the font when the configured width does not fit the roll.
+	size := pdfFontSize
+	if w := pdf.TextWidth(strings.Repeat(" ", tpl.Width), size); w > pageWidth-2*pdfMargin {
+		size = size * (pageWidth - 2*pdfMargin) / w
+	}
+	lineHeight := pdfLineHeight * size / pdfFontSize
+	height := 2*pdfMargin + float64(len(lines))*lineHeight
+
+	doc := pdf.New()
+	page := doc.AddPage(pageWidth, height)
+	y := pdfMargin + size
+	for _, l := range lines {
+		font := pdf.Mono
+		if l.bold {
+			font = pdf.MonoBold
+		}
+		page.Text(pdfMargin, y, font, size, l.text)
+		y += lineHeight
+	}
+	return doc.Bytes()
+}
@@ -0,0 +1,444 @@
+// Package receipt renders POS sale receipts as plain text, ESC/POS byte
+// streams and PDF documents. It works on a presentation model with
+// pre-formatted amounts so it has no knowledge of the database types.
+package receipt
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"NEMBUS/internal/arabic"
+)
+
+// Format is an output format for a rendered receipt.
+type Format string
+
+const (
+	FormatText   Format = "text"
+	FormatESCPOS Format = "escpos"
+	FormatPDF    Format = "pdf"
+)
+
+// ParseFormat validates a format name, defaulting to text when empty.
+func ParseFormat(s string) (Format, error) {
+	switch Format(strings.ToLower(strings.TrimSpace(s))) {
+	case "", FormatText:
+		return FormatText, nil
+	case FormatESCPOS:
+		return FormatESCPOS, nil
+	case FormatPDF:
+		return FormatPDF, nil
+	}
+	return "", fmt.Errorf("unsupported receipt format %q (use text, escpos or pdf)", s)
+}
+
+// Language controls which labels a receipt is printed with.
+type Language string
+
+const (
+	LanguageEnglish   Language = "en"
+	LanguageArabic    Language = "ar"
+	LanguageBilingual Language = "bilingual"
+)
+
+// Header is the store block printed at the top of a receipt.
+type Header struct {
+	Name      string
+	NameAr    string
+	Address   string
+	AddressAr string
+	Phone     string
+	VATNumber string
+	CRNumber  string
+}
+
+// Line is one sold item.
+type Line struct {
+	Name      string
+	SKU       string
+	Quantity  string
+	UnitPrice string
+	Discount  string
+	Total     string
+}
+
+// TaxLine is one row of the per-rate tax summary.
+type TaxLine struct {
+	Name      string
+	Rate      string
+	Inclusive bool
+	Taxable   string
+	Tax       string
+}
+
+// Payment is one tender applied to the sale.
+type Payment struct {
+	Method    string
+	Amount    string
+	Reference string
+}
+
+// Receipt is everything printed for a single POS transaction.
+type Receipt struct {
+	Header       Header
+	Title        string
+	Number       string
+	Date         time.Time
+	Cashier      string
+	Terminal     string
+	Customer     string
+	Currency     string
+	Lines        []Line
+	Subtotal     string
+	Discount     string
+	Tax          string
+	Total        string
+	TaxBreakdown []TaxLine
+	Payments     []Payment
+	Paid         string
+	Change       string
+	// TaxInvoice prints the simplified tax invoice title; QRCode is the
+	// ZATCA TLV payload, printed as a QR code on ESC/POS output.
+	TaxInvoice bool
+	QRCode     string
+}
+
+// Template is the per-tenant receipt layout configuration. CodePage is the
+// ESC/POS character table (ESC t) the printer uses for PC864, the code page
+// Arabic and bilingual receipts are sent in; English receipts are sent in
+// Windows-1252 (table 16).
+type Template struct {
+	Language         Language
+	Width            int
+	HeaderLines      []string
+	FooterLines      []string
+	ShowTaxBreakdown bool
+	ShowBarcode      bool
+	CodePage         int
+}
+
+// DefaultTemplate is used when a tenant has not configured a template.
+func DefaultTemplate() Template {
+	return Template{
+		Language:         LanguageBilingual,
+		Width:            42,
+		FooterLines:      []string{"Thank you for shopping with us"},
+		ShowTaxBreakdown: true,
+		ShowBarcode:      true,
+		CodePage:         CodePagePC864,
+	}
+}
+
+// Output is a rendered receipt ready to be written to the client.
+type Output struct {
+	ContentType string
+	Filename    string
+	Body        []byte
+}
+
+// Render lays out r with tpl and encodes it in the requested format.
+func Render(r *Receipt, tpl Template, format Format) (*Output, error) {
+	if tpl.Width < 24 {
+		tpl.Width = 24
+	}
+	switch format {
+	case FormatText:
+		return &Output{
+			ContentType: "text/plain; charset=utf-8",
+			Filename:    r.Number + ".txt",
+			Body:        renderText(layout(r, tpl)),
+		}, nil
+	case FormatESCPOS:
+		return &Output{
+			ContentType: "application/octet-stream",
+			Filename:    r.Number + ".bin",
+			Body:        renderESCPOS(layout(r, tpl), r, tpl),
+		}, nil
+	case FormatPDF:
+		return &Output{
+			ContentType: "application/pdf",
+			Filename:    r.Number + ".pdf",
+			Body:        renderPDF(layout(r, tpl), tpl),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported receipt format %q", format)
+}
+
+// row is one laid-out line, already padded to the template width and in
+// display order: Arabic is shaped and right-to-left text reversed, so every
+// format prints it left to right as is.
+type row struct {
+	text string
+	bold bool
+}
+
+var labels = map[string][2]string{
+	"receipt":     {"Receipt", "إيصال"},
+	"tax_invoice": {"Simplified Tax Invoice", "فاتورة ضريبية مبسطة"},
+	"number":      {"No", "رقم"},
+	"date":        {"Date", "التاريخ"},
+	"cashier":     {"Cashier", "الكاشير"},
+	"terminal":    {"Terminal", "الجهاز"},
+	"customer":    {"Customer", "العميل"},
+	"subtotal":    {"Subtotal", "المجموع"},
+	"discount":    {"Discount", "الخصم"},
+	"tax":         {"Tax", "الضريبة"},
+	"total":       {"Total", "الإجمالي"},
+	"tax_summary": {"Tax summary", "ملخص الضريبة"},
+	"inclusive":   {"incl.", "شامل"},
+	"paid":        {"Paid", "المدفوع"},
+	"change":      {"Change", "الباقي"},
+	"vat_number":  {"VAT No", "الرقم الضريبي"},
+	"cr_number":   {"CR No", "السجل التجاري"},
+	"phone":       {"Tel", "هاتف"},
+}
+
+func (t Template) label(key string) string {
+	l, ok := labels[key]
+	if !ok {
+		return key
+	}
+	switch t.Language {
+	case LanguageArabic:
+		return l[1]
+	case LanguageBilingual:
+		return l[0] + " / " + l[1]
+	}
+	return l[0]
+}
+
+// layout lays the receipt out and converts each line to display order.
+// Arabic receipts are right to left: labels go to the right margin, amounts
+// to the left and free text is right aligned. The label and the value of a
+// line are reordered on their own, like Unicode isolates, so an Arabic label
+// never swallows the number next to it.
+func layout(r *Receipt, tpl Template) []row {
+	w := tpl.Width
+	rtl := tpl.Language == LanguageArabic
+	var rows []row
+	visual := func(s string) string {
+		return arabic.Visual(s, rtl || arabic.IsRTL(s))
+	}
+	// isolate takes the direction of its first strong character, so
+	// amounts, dates and numbers read left to right on every receipt.
+	isolate := func(s string) string {
+		return arabic.Visual(s, arabic.IsRTL(s))
+	}
+	add := func(text string, bold bool) {
+		v := visual(text)
+		if rtl {
+			v = strings.Repeat(" ", max(w-width(v), 0)) + v
+		}
+		rows = append(rows, row{text: v, bold: bold})
+	}
+	addCenter := func(s string, bold bool) {
+		rows = append(rows, row{text: center(visual(s), w), bold: bold})
+	}
+	addField := func(label, value string) {
+		l, v := isolate(label+":"), isolate(value)
+		if rtl {
+			l, v = v, l
+		}
+		rows = append(rows, row{text: center(l+" "+v, w)})
+	}
+	addPair := func(label, value string, bold bool) {
+		trimmed := strings.TrimLeft(label, " ")
+		indent := strings.Repeat(" ", len(label)-len(trimmed))
+		rows = append(rows, row{text: pair(indent+isolate(trimmed), isolate(value), w, rtl), bold: bold})
+	}
+	rule := func() { rows = append(rows, row{text: strings.Repeat("-", w)}) }
+
+	// Store header
+	if tpl.Language != LanguageArabic || r.Header.NameAr == "" {
+		for _, s := range wrap(r.Header.Name, w) {
+			addCenter(s, true)
+		}
+	}
+	if tpl.Language != LanguageEnglish && r.Header.NameAr != "" {
+		for _, s := range wrap(r.Header.NameAr, w) {
+			addCenter(s, true)
+		}
+	}
+	if tpl.Language != LanguageArabic || r.Header.AddressAr == "" {
+		for _, s := range wrap(r.Header.Address, w) {
+			addCenter(s, false)
+		}
+	}
+	if tpl.Language != LanguageEnglish && r.Header.AddressAr != "" {
+		for _, s := range wrap(r.Header.AddressAr, w) {
+			addCenter(s, false)
+		}
+	}
+	if r.Header.Phone != "" {
+		addField(tpl.label("phone"), r.Header.Phone)
+	}
+	if r.Header.VATNumber != "" {
+		addField(tpl.label("vat_number"), r.Header.VATNumber)
+	}
+	if r.Header.CRNumber != "" {
+		addField(tpl.label("cr_number"), r.Header.CRNumber)
+	}
+	for _, h := range tpl.HeaderLines {
+		for _, s := range wrap(h, w) {
+			addCenter(s, false)
+		}
+	}
+	rule()
+
+	title := r.Title
+	switch {
+	case title != "":
+	case r.TaxInvoice:
+		title = tpl.label("tax_invoice")
+	default:
+		title = tpl.label("receipt")
+	}
+	addCenter(title, true)
+	addPair(tpl.label("number"), r.Number, false)
+	addPair(tpl.label("date"), r.Date.Format("2006-01-02 15:04"), false)
+	if r.Cashier != "" {
+		addPair(tpl.label("cashier"), r.Cashier, false)
+	}
+	if r.Terminal != "" {
+		addPair(tpl.label("terminal"), r.Terminal, false)
+	}
+	if r.Customer != "" {
+		addPair(tpl.label("customer"), r.Customer, false)
+	}
+	rule()
+
+	// Items
+	for _, l := range r.Lines {
+		for _, s := range wrap(l.Name, w) {
+			add(s, false)
+		}
+		addPair("  "+l.Quantity+" x "+l.UnitPrice, l.Total, false)
+		if l.Discount != "" && !isZero(l.Discount) {
+			addPair("  "+tpl.label("discount"), "-"+l.Discount, false)
+		}
+	}
+	rule()
+
+	// Totals
+	addPair(tpl.label("subtotal"), r.Subtotal, false)
+	if r.Discount != "" && !isZero(r.Discount) {
+		addPair(tpl.label("discount"), "-"+r.Discount, false)
+	}
+	addPair(tpl.label("tax"), r.Tax, false)
+	total := r.Total
+	if r.Currency != "" {
+		total = r.Currency + " " + total
+	}
+	addPair(tpl.label("total"), total, true)
+
+	if tpl.ShowTaxBreakdown && len(r.TaxBreakdown) > 0 {
+		rule()
+		add(tpl.label("tax_summary"), true)
+		for _, t := range r.TaxBreakdown {
+			name := t.Name + " " + t.Rate + "%"
+			if t.Inclusive {
+				name += " " + tpl.label("inclusive")
+			}
+			addPair(name, t.Taxable+" / "+t.Tax, false)
+		}
+	}
+
+	if len(r.Payments) > 0 {
+		rule()
+		for _, p := range r.Payments {
+			addPair(strings.ToUpper(p.Method), p.Amount, false)
+		}
+		addPair(tpl.label("paid"), r.Paid, false)
+		addPair(tpl.label("change"), r.Change, true)
+	}
+
+	if len(tpl.FooterLines) > 0 {
+		rule()
+		for _, f := range tpl.FooterLines {
+			for _, s := range wrap(f, w) {
+				addCenter(s, false)
+			}
+		}
+	}
+	return rows
+}
+
+// width is the number of columns s takes once shaped: lam-alef ligatures
+// take one and combining marks none.
+func width(s string) int {
+	n := 0
+	for _, r := range arabic.Shape(s) {
+		if !unicode.Is(unicode.Mn, r) {
+			n++
+		}
+	}
+	return n
+}
+
+// pair prints left and right justified on one line, or on two lines when
+// they do not fit together. rtl mirrors the line: left goes to the right
+// margin, with its indentation on that side, and right to the left one.
+func pair(left, right string, w int, rtl bool) string {
+	if rtl {
+		trimmed := strings.TrimLeft(left, " ")
+		left = trimmed + strings.Repeat(" ", len(left)-len(trimmed))
+		gap := w - width(left) - width(right)
+		if gap < 1 {
+			return strings.Repeat(" ", max(w-width(left), 0)) + left + "\n" + right
+		}
+		return right + strings.Repeat(" ", gap) + left
+	}
+	gap := w - width(left) - width(right)
+	if gap < 1 {
+		return left + "\n" + strings.Repeat(" ", max(w-width(right), 0)) + right
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
+
+func center(s string, w int) string {
+	pad := (w - width(s)) / 2
+	if pad <= 0 {
+		return s
+	}
+	return strings.Repeat(" ", pad) + s
+}
+
+// wrap breaks s on spaces so that no line exceeds w characters.
+func wrap(s string, w int) []string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return nil
+	}
+	var out []string
+	var cur string
+	for _, word := range strings.Fields(s) {
+		for width(word) > w {
+			if cur != "" {
+				out = append(out, cur)
+				cur = ""
+			}
+			runes := []rune(word)
+			out = append(out, string(runes[:w]))
+			word = string(runes[w:])
+		}
+		switch {
+		case cur == "":
+			cur = word
+		case width(cur)+1+width(word) <= w:
+			cur += " " + word
+		default:
+			out = append(out, cur)
+			cur = word
+		}
+	}
+	if cur != "" {
+		out = append(out, cur)
+	}
+	return out
+}
+
+func isZero(amount string) bool {
+	return strings.Trim(amount, "0.-") == ""
+}
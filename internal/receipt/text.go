@@ -0,0 +1,12 @@
+package receipt
+
+import "bytes"
+
+func renderText(rows []row) []byte {
+	var buf bytes.Buffer
+	for _, r := range rows {
+		buf.WriteString(r.text)
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
@@ -11,6 +11,18 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countBrandProducts = `-- name: CountBrandProducts :one
+SELECT COUNT(*) FROM products
+WHERE brand_id = $1
+`
+
+func (q *Queries) CountBrandProducts(ctx context.Context, brandID pgtype.Int4) (int64, error) {
+	row := q.db.QueryRow(ctx, countBrandProducts, brandID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const listBrandsWithStats = `-- name: ListBrandsWithStats :many
 SELECT 
     b.id, b.name, b.code, b.is_active,
@@ -18,8 +30,8 @@ SELECT
     COUNT(DISTINCT p.category_id) AS category_count
 FROM brands b
 LEFT JOIN products p ON p.brand_id = b.id AND p.is_active = true
-WHERE b.is_active = $1
-  AND ($2::text IS NULL OR b.name ILIKE '%' || $2 || '%')
+WHERE ($1::boolean IS NULL OR b.is_active = $1)
+  AND ($2::text IS NULL OR b.name ILIKE '%' || $2 || '%' OR b.code ILIKE '%' || $2 || '%')
 GROUP BY b.id
 ORDER BY product_count DESC, b.name
 LIMIT $4 OFFSET $3
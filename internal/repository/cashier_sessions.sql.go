@@ -67,6 +67,24 @@ func (q *Queries) CloseCashierSession(ctx context.Context, arg CloseCashierSessi
 	return i, err
 }
 
+const getActiveCashierIDByUser = `-- name: GetActiveCashierIDByUser :one
+SELECT cs.cashier_id
+FROM cashier_sessions cs
+JOIN cashiers c ON cs.cashier_id = c.id
+WHERE c.user_id = $1
+  AND cs.status = 'open'
+  AND cs.closing_time IS NULL
+ORDER BY cs.opening_time DESC
+LIMIT 1
+`
+
+func (q *Queries) GetActiveCashierIDByUser(ctx context.Context, userID int32) (int32, error) {
+	row := q.db.QueryRow(ctx, getActiveCashierIDByUser, userID)
+	var cashier_id int32
+	err := row.Scan(&cashier_id)
+	return cashier_id, err
+}
+
 const getActiveCashierSession = `-- name: GetActiveCashierSession :one
 SELECT 
     cs.id, cs.cashier_id, cs.pos_terminal_id, cs.session_number, cs.opening_time, cs.closing_time, cs.opening_balance, cs.closing_balance, cs.expected_balance, cs.variance, cs.status, cs.metadata, cs.created_at,
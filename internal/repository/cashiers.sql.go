@@ -11,6 +11,28 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const getCashier = `-- name: GetCashier :one
+SELECT id, user_id, store_id, cashier_code, drawer_limit, discount_limit, is_active, metadata, created_at FROM cashiers
+WHERE id = $1
+`
+
+func (q *Queries) GetCashier(ctx context.Context, id int32) (Cashier, error) {
+	row := q.db.QueryRow(ctx, getCashier, id)
+	var i Cashier
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.StoreID,
+		&i.CashierCode,
+		&i.DrawerLimit,
+		&i.DiscountLimit,
+		&i.IsActive,
+		&i.Metadata,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getCashierWithLimits = `-- name: GetCashierWithLimits :one
 SELECT 
     c.id,
@@ -11,6 +11,70 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const adjustInventoryStock = `-- name: AdjustInventoryStock :execrows
+UPDATE inventory_stock
+SET quantity_on_hand   = COALESCE(quantity_on_hand, 0) + $1,
+    quantity_available = COALESCE(quantity_available, 0) + $1,
+    updated_at         = CURRENT_TIMESTAMP
+WHERE id = (
+    SELECT s.id FROM inventory_stock s
+    WHERE s.product_id = $2
+      AND s.store_id = $3
+      AND s.product_variant_id IS NOT DISTINCT FROM $4
+    ORDER BY s.storage_location_id NULLS FIRST, s.id
+    LIMIT 1
+)
+`
+
+type AdjustInventoryStockParams struct {
+	QuantityDelta    pgtype.Numeric `json:"quantity_delta"`
+	ProductID        int32          `json:"product_id"`
+	StoreID          int32          `json:"store_id"`
+	ProductVariantID pgtype.Int4    `json:"product_variant_id"`
+}
+
+// Applies a signed quantity change to the store's stock row for a product
+// (the unlocated row first when stock is split across locations).
+func (q *Queries) AdjustInventoryStock(ctx context.Context, arg AdjustInventoryStockParams) (int64, error) {
+	result, err := q.db.Exec(ctx, adjustInventoryStock,
+		arg.QuantityDelta,
+		arg.ProductID,
+		arg.StoreID,
+		arg.ProductVariantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createInventoryStock = `-- name: CreateInventoryStock :exec
+INSERT INTO inventory_stock (
+    product_id, product_variant_id, store_id, quantity_on_hand, quantity_available
+) VALUES (
+    $1, $2, $3,
+    $4, $4
+)
+`
+
+type CreateInventoryStockParams struct {
+	ProductID        int32          `json:"product_id"`
+	ProductVariantID pgtype.Int4    `json:"product_variant_id"`
+	StoreID          int32          `json:"store_id"`
+	Quantity         pgtype.Numeric `json:"quantity"`
+}
+
+// Opens the store's stock row for a product that has none yet.
+func (q *Queries) CreateInventoryStock(ctx context.Context, arg CreateInventoryStockParams) error {
+	_, err := q.db.Exec(ctx, createInventoryStock,
+		arg.ProductID,
+		arg.ProductVariantID,
+		arg.StoreID,
+		arg.Quantity,
+	)
+	return err
+}
+
 const getLowStockProducts = `-- name: GetLowStockProducts :many
 SELECT 
     s.product_id,
@@ -173,6 +173,85 @@ func (q *Queries) ListActiveMenusByModule(ctx context.Context, moduleID int32) (
 	return items, nil
 }
 
+const listMenuSiblings = `-- name: ListMenuSiblings :many
+SELECT id, module_id, parent_menu_id, name, code, route_path, icon, display_order, is_active, metadata, created_at, updated_at FROM menus
+WHERE module_id = $1
+  AND parent_menu_id IS NOT DISTINCT FROM $2
+ORDER BY display_order, id
+`
+
+type ListMenuSiblingsParams struct {
+	ModuleID     int32       `json:"module_id"`
+	ParentMenuID pgtype.Int4 `json:"parent_menu_id"`
+}
+
+// Menus of a module under the same parent menu (top-level menus when the
+// parent is NULL), in display order.
+func (q *Queries) ListMenuSiblings(ctx context.Context, arg ListMenuSiblingsParams) ([]Menu, error) {
+	rows, err := q.db.Query(ctx, listMenuSiblings, arg.ModuleID, arg.ParentMenuID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Menu
+	for rows.Next() {
+		var i Menu
+		if err := rows.Scan(
+			&i.ID,
+			&i.ModuleID,
+			&i.ParentMenuID,
+			&i.Name,
+			&i.Code,
+			&i.RoutePath,
+			&i.Icon,
+			&i.DisplayOrder,
+			&i.IsActive,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listMenuSubtreeIDs = `-- name: ListMenuSubtreeIDs :many
+WITH RECURSIVE subtree AS (
+    SELECT id FROM menus WHERE id = $1
+    UNION
+    SELECT m.id
+    FROM menus m
+    INNER JOIN subtree s ON m.parent_menu_id = s.id
+)
+SELECT id FROM subtree
+`
+
+// The menu and all of its descendant menus.
+func (q *Queries) ListMenuSubtreeIDs(ctx context.Context, id int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listMenuSubtreeIDs, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listMenus = `-- name: ListMenus :many
 SELECT id, module_id, parent_menu_id, name, code, route_path, icon, display_order, is_active, metadata, created_at, updated_at FROM menus
 ORDER BY display_order, id
@@ -289,6 +368,55 @@ func (q *Queries) ListMenusByParent(ctx context.Context, parentMenuID pgtype.Int
 	return items, nil
 }
 
+const setMenuDisplayOrder = `-- name: SetMenuDisplayOrder :exec
+UPDATE menus
+SET display_order = $2
+WHERE id = $1
+`
+
+type SetMenuDisplayOrderParams struct {
+	ID           int32       `json:"id"`
+	DisplayOrder pgtype.Int4 `json:"display_order"`
+}
+
+func (q *Queries) SetMenuDisplayOrder(ctx context.Context, arg SetMenuDisplayOrderParams) error {
+	_, err := q.db.Exec(ctx, setMenuDisplayOrder, arg.ID, arg.DisplayOrder)
+	return err
+}
+
+const setMenuParent = `-- name: SetMenuParent :one
+UPDATE menus
+SET parent_menu_id = $1
+WHERE id = $2
+RETURNING id, module_id, parent_menu_id, name, code, route_path, icon, display_order, is_active, metadata, created_at, updated_at
+`
+
+type SetMenuParentParams struct {
+	ParentMenuID pgtype.Int4 `json:"parent_menu_id"`
+	ID           int32       `json:"id"`
+}
+
+// Unlike UpdateMenu this leaves every other column alone.
+func (q *Queries) SetMenuParent(ctx context.Context, arg SetMenuParentParams) (Menu, error) {
+	row := q.db.QueryRow(ctx, setMenuParent, arg.ParentMenuID, arg.ID)
+	var i Menu
+	err := row.Scan(
+		&i.ID,
+		&i.ModuleID,
+		&i.ParentMenuID,
+		&i.Name,
+		&i.Code,
+		&i.RoutePath,
+		&i.Icon,
+		&i.DisplayOrder,
+		&i.IsActive,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const toggleMenuActive = `-- name: ToggleMenuActive :one
 UPDATE menus
 SET is_active = $2
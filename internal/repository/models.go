@@ -11,6 +11,21 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type BarcodeRule struct {
+	ID              int32            `json:"id"`
+	Prefix          string           `json:"prefix"`
+	Name            string           `json:"name"`
+	ValueType       string           `json:"value_type"`
+	ItemDigits      int32            `json:"item_digits"`
+	ValueDigits     int32            `json:"value_digits"`
+	ValueDecimals   int32            `json:"value_decimals"`
+	ValueCheckDigit bool             `json:"value_check_digit"`
+	IsActive        pgtype.Bool      `json:"is_active"`
+	Metadata        []byte           `json:"metadata"`
+	CreatedAt       pgtype.Timestamp `json:"created_at"`
+	UpdatedAt       pgtype.Timestamp `json:"updated_at"`
+}
+
 type Brand struct {
 	ID        int32            `json:"id"`
 	Name      string           `json:"name"`
@@ -49,6 +64,28 @@ type CashierSession struct {
 	CreatedAt       pgtype.Timestamp `json:"created_at"`
 }
 
+type CatalogImportJob struct {
+	ID             int32            `json:"id"`
+	OrganizationID int32            `json:"organization_id"`
+	FileName       string           `json:"file_name"`
+	Status         string           `json:"status"`
+	DryRun         bool             `json:"dry_run"`
+	Options        []byte           `json:"options"`
+	InputFile      []byte           `json:"input_file"`
+	TotalRows      int32            `json:"total_rows"`
+	ProcessedRows  int32            `json:"processed_rows"`
+	SucceededRows  int32            `json:"succeeded_rows"`
+	FailedRows     int32            `json:"failed_rows"`
+	Errors         []byte           `json:"errors"`
+	Summary        []byte           `json:"summary"`
+	ErrorMessage   pgtype.Text      `json:"error_message"`
+	CreatedBy      pgtype.Int4      `json:"created_by"`
+	StartedAt      pgtype.Timestamp `json:"started_at"`
+	FinishedAt     pgtype.Timestamp `json:"finished_at"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
 type Customer struct {
 	ID                 int32            `json:"id"`
 	OrganizationID     int32            `json:"organization_id"`
@@ -85,6 +122,40 @@ type DiscountAnalytic struct {
 	UpdatedAt                pgtype.Timestamp `json:"updated_at"`
 }
 
+type ExpiryAlertRule struct {
+	ID                 int32            `json:"id"`
+	CategoryID         pgtype.Int4      `json:"category_id"`
+	NearExpiryDays     int32            `json:"near_expiry_days"`
+	MaxMarkdownPercent pgtype.Numeric   `json:"max_markdown_percent"`
+	IsActive           pgtype.Bool      `json:"is_active"`
+	CreatedAt          pgtype.Timestamp `json:"created_at"`
+	UpdatedAt          pgtype.Timestamp `json:"updated_at"`
+}
+
+type InventoryAlert struct {
+	ID               int32            `json:"id"`
+	AlertType        string           `json:"alert_type"`
+	StoreID          int32            `json:"store_id"`
+	ProductID        int32            `json:"product_id"`
+	ProductVariantID pgtype.Int4      `json:"product_variant_id"`
+	BatchID          pgtype.Int4      `json:"batch_id"`
+	SerialNumberID   pgtype.Int4      `json:"serial_number_id"`
+	ExpiryDate       pgtype.Date      `json:"expiry_date"`
+	DaysUntilExpiry  pgtype.Int4      `json:"days_until_expiry"`
+	Quantity         pgtype.Numeric   `json:"quantity"`
+	CurrentPrice     pgtype.Numeric   `json:"current_price"`
+	SuggestedPrice   pgtype.Numeric   `json:"suggested_price"`
+	MarkdownPercent  pgtype.Numeric   `json:"markdown_percent"`
+	Message          pgtype.Text      `json:"message"`
+	Status           string           `json:"status"`
+	AcknowledgedBy   pgtype.Int4      `json:"acknowledged_by"`
+	AcknowledgedAt   pgtype.Timestamp `json:"acknowledged_at"`
+	ResolvedAt       pgtype.Timestamp `json:"resolved_at"`
+	LastEvaluatedAt  pgtype.Timestamp `json:"last_evaluated_at"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+}
+
 type InventoryAnalytic struct {
 	ID                 int32            `json:"id"`
 	OrganizationID     int32            `json:"organization_id"`
@@ -129,6 +200,17 @@ type InventoryStock struct {
 	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
 }
 
+type LoginAudit struct {
+	ID            int32            `json:"id"`
+	UserID        pgtype.Int4      `json:"user_id"`
+	UserLogin     string           `json:"user_login"`
+	Success       bool             `json:"success"`
+	FailureReason pgtype.Text      `json:"failure_reason"`
+	IpAddress     pgtype.Text      `json:"ip_address"`
+	UserAgent     pgtype.Text      `json:"user_agent"`
+	CreatedAt     pgtype.Timestamp `json:"created_at"`
+}
+
 type Menu struct {
 	ID           int32            `json:"id"`
 	ModuleID     int32            `json:"module_id"`
@@ -194,6 +276,13 @@ type Permission struct {
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 }
 
+type PasswordHistory struct {
+	ID           int32            `json:"id"`
+	UserID       int32            `json:"user_id"`
+	PasswordHash string           `json:"password_hash"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+}
+
 type PosPayment struct {
 	ID              int32            `json:"id"`
 	TransactionID   int32            `json:"transaction_id"`
@@ -272,6 +361,22 @@ type PriceList struct {
 	UpdatedAt     pgtype.Timestamp `json:"updated_at"`
 }
 
+type PriceListAssignment struct {
+	ID           int32            `json:"id"`
+	PriceListID  int32            `json:"price_list_id"`
+	Scope        string           `json:"scope"`
+	CustomerID   pgtype.Int4      `json:"customer_id"`
+	CustomerType pgtype.Text      `json:"customer_type"`
+	StoreID      pgtype.Int4      `json:"store_id"`
+	Priority     int32            `json:"priority"`
+	ValidFrom    pgtype.Date      `json:"valid_from"`
+	ValidTo      pgtype.Date      `json:"valid_to"`
+	IsActive     pgtype.Bool      `json:"is_active"`
+	Metadata     []byte           `json:"metadata"`
+	CreatedAt    pgtype.Timestamp `json:"created_at"`
+	UpdatedAt    pgtype.Timestamp `json:"updated_at"`
+}
+
 type Product struct {
 	ID                   int32            `json:"id"`
 	OrganizationID       int32            `json:"organization_id"`
@@ -473,6 +578,24 @@ type PurchaseOrderLine struct {
 	Metadata         []byte         `json:"metadata"`
 }
 
+type ReceiptTemplate struct {
+	ID               int32            `json:"id"`
+	Code             string           `json:"code"`
+	Name             string           `json:"name"`
+	Language         string           `json:"language"`
+	PaperWidth       int32            `json:"paper_width"`
+	HeaderLines      []byte           `json:"header_lines"`
+	FooterLines      []byte           `json:"footer_lines"`
+	ShowTaxBreakdown pgtype.Bool      `json:"show_tax_breakdown"`
+	ShowBarcode      pgtype.Bool      `json:"show_barcode"`
+	CodePage         pgtype.Int4      `json:"code_page"`
+	IsDefault        pgtype.Bool      `json:"is_default"`
+	IsActive         pgtype.Bool      `json:"is_active"`
+	Metadata         []byte           `json:"metadata"`
+	CreatedAt        pgtype.Timestamp `json:"created_at"`
+	UpdatedAt        pgtype.Timestamp `json:"updated_at"`
+}
+
 type Role struct {
 	ID           int32            `json:"id"`
 	Name         string           `json:"name"`
@@ -700,6 +823,22 @@ type TaxCategory struct {
 	CreatedAt   pgtype.Timestamp `json:"created_at"`
 }
 
+type TaxCategoryChange struct {
+	ID              int32            `json:"id"`
+	TaxCategoryID   pgtype.Int4      `json:"tax_category_id"`
+	TaxCategoryCode string           `json:"tax_category_code"`
+	Action          string           `json:"action"`
+	OldTaxRate      pgtype.Numeric   `json:"old_tax_rate"`
+	NewTaxRate      pgtype.Numeric   `json:"new_tax_rate"`
+	OldIsInclusive  pgtype.Bool      `json:"old_is_inclusive"`
+	NewIsInclusive  pgtype.Bool      `json:"new_is_inclusive"`
+	OldIsActive     pgtype.Bool      `json:"old_is_active"`
+	NewIsActive     pgtype.Bool      `json:"new_is_active"`
+	ChangedBy       pgtype.Int4      `json:"changed_by"`
+	ChangedAt       pgtype.Timestamp `json:"changed_at"`
+	Metadata        []byte           `json:"metadata"`
+}
+
 type Tenant struct {
 	ID         uuid.UUID        `json:"id"`
 	TenantName string           `json:"tenant_name"`
@@ -755,6 +894,13 @@ type UserRole struct {
 	AssignedAt pgtype.Timestamp `json:"assigned_at"`
 }
 
+type UserSecurity struct {
+	UserID            int32            `json:"user_id"`
+	SessionsRevokedAt pgtype.Timestamp `json:"sessions_revoked_at"`
+	PasswordChangedAt pgtype.Timestamp `json:"password_changed_at"`
+	UpdatedAt         pgtype.Timestamp `json:"updated_at"`
+}
+
 type UserStoreAccess struct {
 	ID        int32            `json:"id"`
 	UserID    int32            `json:"user_id"`
@@ -817,3 +963,19 @@ type VwPosProductCatalog struct {
 	TrackInventory       pgtype.Bool    `json:"track_inventory"`
 	ProductMetadata      []byte         `json:"product_metadata"`
 }
+
+type ZatcaInvoice struct {
+	ID             int32            `json:"id"`
+	TransactionID  int32            `json:"transaction_id"`
+	PosTerminalID  int32            `json:"pos_terminal_id"`
+	InvoiceCounter int64            `json:"invoice_counter"`
+	InvoiceUuid    uuid.UUID        `json:"invoice_uuid"`
+	InvoiceHash    string           `json:"invoice_hash"`
+	PreviousHash   string           `json:"previous_hash"`
+	QrCode         string           `json:"qr_code"`
+	InvoiceXml     string           `json:"invoice_xml"`
+	Signature      pgtype.Text      `json:"signature"`
+	Status         pgtype.Text      `json:"status"`
+	Metadata       []byte           `json:"metadata"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+}
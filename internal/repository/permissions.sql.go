@@ -565,6 +565,58 @@ func (q *Queries) GetUserPermissionsWithScope(ctx context.Context, userID int32)
 	return items, nil
 }
 
+const grantPermissionToRole = `-- name: GrantPermissionToRole :exec
+INSERT INTO role_permissions (role_id, permission_id, scope)
+VALUES ($1, $2, COALESCE($3, 'all'))
+ON CONFLICT (role_id, permission_id)
+DO UPDATE SET scope = COALESCE($3, role_permissions.scope)
+`
+
+type GrantPermissionToRoleParams struct {
+	RoleID       int32       `json:"role_id"`
+	PermissionID int32       `json:"permission_id"`
+	Scope        pgtype.Text `json:"scope"`
+}
+
+// Grants the permission, or changes the scope of an existing grant when a
+// scope is given.
+func (q *Queries) GrantPermissionToRole(ctx context.Context, arg GrantPermissionToRoleParams) error {
+	_, err := q.db.Exec(ctx, grantPermissionToRole, arg.RoleID, arg.PermissionID, arg.Scope)
+	return err
+}
+
+const listAllPermissions = `-- name: ListAllPermissions :many
+SELECT id, name, code, description, metadata, created_at FROM permissions
+ORDER BY code
+`
+
+func (q *Queries) ListAllPermissions(ctx context.Context) ([]Permission, error) {
+	rows, err := q.db.Query(ctx, listAllPermissions)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Permission
+	for rows.Next() {
+		var i Permission
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Code,
+			&i.Description,
+			&i.Metadata,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPermissions = `-- name: ListPermissions :many
 SELECT id, name, code, description, metadata, created_at FROM permissions
 ORDER BY name
@@ -603,6 +655,38 @@ func (q *Queries) ListPermissions(ctx context.Context, arg ListPermissionsParams
 	return items, nil
 }
 
+const listRolePermissionGrants = `-- name: ListRolePermissionGrants :many
+SELECT role_id, permission_id, scope FROM role_permissions
+ORDER BY role_id, permission_id
+`
+
+type ListRolePermissionGrantsRow struct {
+	RoleID       int32       `json:"role_id"`
+	PermissionID int32       `json:"permission_id"`
+	Scope        pgtype.Text `json:"scope"`
+}
+
+// Every grant of a permission to a role, for the role x permission matrix.
+func (q *Queries) ListRolePermissionGrants(ctx context.Context) ([]ListRolePermissionGrantsRow, error) {
+	rows, err := q.db.Query(ctx, listRolePermissionGrants)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListRolePermissionGrantsRow
+	for rows.Next() {
+		var i ListRolePermissionGrantsRow
+		if err := rows.Scan(&i.RoleID, &i.PermissionID, &i.Scope); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const revokePermissionFromMenu = `-- name: RevokePermissionFromMenu :exec
 DELETE FROM menu_permissions 
 WHERE menu_id = $1 AND permission_id = $2
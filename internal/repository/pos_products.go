@@ -196,6 +196,7 @@ type PosProductByCategoryRow struct {
 	BrandName         pgtype.Text    `json:"brand_name"`
 	Barcode           pgtype.Text    `json:"barcode"`
 	EffectivePrice    pgtype.Numeric `json:"effective_price"`
+	PromoPrice        pgtype.Numeric `json:"promo_price"` // set by the price resolver
 	HasPromotion      pgtype.Bool    `json:"has_promotion"`
 	PromotionName     pgtype.Text    `json:"promotion_name"`
 	QuantityAvailable pgtype.Numeric `json:"quantity_available"`
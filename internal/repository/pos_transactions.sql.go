@@ -135,6 +135,39 @@ func (q *Queries) CreatePosTransactionLine(ctx context.Context, arg CreatePosTra
 	return err
 }
 
+const getPosTransactionByNumber = `-- name: GetPosTransactionByNumber :one
+SELECT id, transaction_number, store_id, pos_terminal_id, cashier_session_id, cashier_id, customer_id, price_list_id, transaction_type, transaction_date, subtotal, tax_amount, discount_amount, total_amount, total_cost, status, voided_by, voided_at, metadata, created_at FROM pos_transactions
+WHERE transaction_number = $1
+`
+
+func (q *Queries) GetPosTransactionByNumber(ctx context.Context, transactionNumber string) (PosTransaction, error) {
+	row := q.db.QueryRow(ctx, getPosTransactionByNumber, transactionNumber)
+	var i PosTransaction
+	err := row.Scan(
+		&i.ID,
+		&i.TransactionNumber,
+		&i.StoreID,
+		&i.PosTerminalID,
+		&i.CashierSessionID,
+		&i.CashierID,
+		&i.CustomerID,
+		&i.PriceListID,
+		&i.TransactionType,
+		&i.TransactionDate,
+		&i.Subtotal,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.TotalAmount,
+		&i.TotalCost,
+		&i.Status,
+		&i.VoidedBy,
+		&i.VoidedAt,
+		&i.Metadata,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getPosTransactionFull = `-- name: GetPosTransactionFull :many
 SELECT 
     t.id, t.transaction_number, t.transaction_date, t.status,
@@ -237,6 +270,193 @@ func (q *Queries) GetPosTransactionFull(ctx context.Context, id int32) ([]GetPos
 	return items, nil
 }
 
+const getPosTransactionLinesForInvoice = `-- name: GetPosTransactionLinesForInvoice :many
+SELECT
+    tl.line_number,
+    p.name AS product_name,
+    tl.quantity,
+    tl.unit_price,
+    tl.discount_amount,
+    tl.tax_amount,
+    tl.line_total,
+    COALESCE((tl.metadata->>'tax_rate')::numeric, 0)::numeric      AS tax_rate,
+    COALESCE((tl.metadata->>'tax_inclusive')::bool, false)::bool AS is_inclusive
+FROM pos_transaction_lines tl
+JOIN products p ON tl.product_id = p.id
+WHERE tl.transaction_id = $1
+ORDER BY tl.line_number
+`
+
+type GetPosTransactionLinesForInvoiceRow struct {
+	LineNumber     int32          `json:"line_number"`
+	ProductName    string         `json:"product_name"`
+	Quantity       pgtype.Numeric `json:"quantity"`
+	UnitPrice      pgtype.Numeric `json:"unit_price"`
+	DiscountAmount pgtype.Numeric `json:"discount_amount"`
+	TaxAmount      pgtype.Numeric `json:"tax_amount"`
+	LineTotal      pgtype.Numeric `json:"line_total"`
+	TaxRate        pgtype.Numeric `json:"tax_rate"`
+	IsInclusive    bool           `json:"is_inclusive"`
+}
+
+func (q *Queries) GetPosTransactionLinesForInvoice(ctx context.Context, transactionID int32) ([]GetPosTransactionLinesForInvoiceRow, error) {
+	rows, err := q.db.Query(ctx, getPosTransactionLinesForInvoice, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPosTransactionLinesForInvoiceRow
+	for rows.Next() {
+		var i GetPosTransactionLinesForInvoiceRow
+		if err := rows.Scan(
+			&i.LineNumber,
+			&i.ProductName,
+			&i.Quantity,
+			&i.UnitPrice,
+			&i.DiscountAmount,
+			&i.TaxAmount,
+			&i.LineTotal,
+			&i.TaxRate,
+			&i.IsInclusive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPosTransactionTaxBreakdown = `-- name: GetPosTransactionTaxBreakdown :many
+SELECT
+    COALESCE(tl.metadata->>'tax_name', '')::text                  AS tax_name,
+    COALESCE((tl.metadata->>'tax_rate')::numeric, 0)::numeric      AS tax_rate,
+    COALESCE((tl.metadata->>'tax_inclusive')::bool, false)::bool AS is_inclusive,
+    SUM(tl.line_total - CASE WHEN (tl.metadata->>'tax_inclusive')::bool THEN tl.tax_amount ELSE 0 END)::numeric AS taxable_amount,
+    SUM(tl.tax_amount)::numeric                                    AS tax_amount
+FROM pos_transaction_lines tl
+WHERE tl.transaction_id = $1
+GROUP BY 1, 2, 3
+ORDER BY tax_rate DESC, tax_name
+`
+
+type GetPosTransactionTaxBreakdownRow struct {
+	TaxName       string         `json:"tax_name"`
+	TaxRate       pgtype.Numeric `json:"tax_rate"`
+	IsInclusive   bool           `json:"is_inclusive"`
+	TaxableAmount pgtype.Numeric `json:"taxable_amount"`
+	TaxAmount     pgtype.Numeric `json:"tax_amount"`
+}
+
+func (q *Queries) GetPosTransactionTaxBreakdown(ctx context.Context, transactionID int32) ([]GetPosTransactionTaxBreakdownRow, error) {
+	rows, err := q.db.Query(ctx, getPosTransactionTaxBreakdown, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPosTransactionTaxBreakdownRow
+	for rows.Next() {
+		var i GetPosTransactionTaxBreakdownRow
+		if err := rows.Scan(
+			&i.TaxName,
+			&i.TaxRate,
+			&i.IsInclusive,
+			&i.TaxableAmount,
+			&i.TaxAmount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPosTransactionLines = `-- name: ListPosTransactionLines :many
+SELECT id, transaction_id, line_number, product_id, product_variant_id, serial_number, batch_number, quantity, uom_id, unit_price, discount_amount, tax_amount, line_total, cost_price, metadata FROM pos_transaction_lines
+WHERE transaction_id = $1
+ORDER BY line_number
+`
+
+func (q *Queries) ListPosTransactionLines(ctx context.Context, transactionID int32) ([]PosTransactionLine, error) {
+	rows, err := q.db.Query(ctx, listPosTransactionLines, transactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PosTransactionLine
+	for rows.Next() {
+		var i PosTransactionLine
+		if err := rows.Scan(
+			&i.ID,
+			&i.TransactionID,
+			&i.LineNumber,
+			&i.ProductID,
+			&i.ProductVariantID,
+			&i.SerialNumber,
+			&i.BatchNumber,
+			&i.Quantity,
+			&i.UomID,
+			&i.UnitPrice,
+			&i.DiscountAmount,
+			&i.TaxAmount,
+			&i.LineTotal,
+			&i.CostPrice,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listReturnedQuantities = `-- name: ListReturnedQuantities :many
+SELECT
+    (l.metadata->>'original_line_id')::int AS original_line_id,
+    SUM(l.quantity)::numeric               AS quantity
+FROM pos_transaction_lines l
+JOIN pos_transactions t ON t.id = l.transaction_id
+WHERE t.transaction_type = 'return'
+  AND t.status = 'completed'
+  AND (t.metadata->>'original_transaction_id')::int = $1::int
+GROUP BY 1
+`
+
+type ListReturnedQuantitiesRow struct {
+	OriginalLineID int32          `json:"original_line_id"`
+	Quantity       pgtype.Numeric `json:"quantity"`
+}
+
+// Quantities already returned per line of a sale, summed over its completed
+// return transactions.
+func (q *Queries) ListReturnedQuantities(ctx context.Context, originalTransactionID int32) ([]ListReturnedQuantitiesRow, error) {
+	rows, err := q.db.Query(ctx, listReturnedQuantities, originalTransactionID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListReturnedQuantitiesRow
+	for rows.Next() {
+		var i ListReturnedQuantitiesRow
+		if err := rows.Scan(&i.OriginalLineID, &i.Quantity); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listTodaysPosTransactions = `-- name: ListTodaysPosTransactions :many
 SELECT 
     t.id,
@@ -43,6 +43,44 @@ func (q *Queries) AdjustBatchQuantity(ctx context.Context, arg AdjustBatchQuanti
 	return i, err
 }
 
+const consumeBatchQuantity = `-- name: ConsumeBatchQuantity :one
+UPDATE product_batches
+SET quantity_available = quantity_available - $1
+WHERE id = $2
+  AND status = 'active'
+  AND quantity_available >= $1
+  AND (expiry_date IS NULL OR expiry_date >= $3)
+RETURNING id, product_id, product_variant_id, batch_number, manufacturing_date, expiry_date, store_id, quantity_available, status, metadata, created_at, updated_at
+`
+
+type ConsumeBatchQuantityParams struct {
+	Quantity pgtype.Numeric `json:"quantity"`
+	ID       int32          `json:"id"`
+	OnDate   pgtype.Date    `json:"on_date"`
+}
+
+// Takes stock from a batch only while it is active, not expired on the given
+// date and still holds enough; no row means the allocation is stale.
+func (q *Queries) ConsumeBatchQuantity(ctx context.Context, arg ConsumeBatchQuantityParams) (ProductBatch, error) {
+	row := q.db.QueryRow(ctx, consumeBatchQuantity, arg.Quantity, arg.ID, arg.OnDate)
+	var i ProductBatch
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.ProductVariantID,
+		&i.BatchNumber,
+		&i.ManufacturingDate,
+		&i.ExpiryDate,
+		&i.StoreID,
+		&i.QuantityAvailable,
+		&i.Status,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createProductBatch = `-- name: CreateProductBatch :one
 
 INSERT INTO product_batches (
@@ -555,13 +593,16 @@ SELECT
     p.name as product_name,
     p.sku as product_sku,
     from_store.name as from_store_name,
-    to_store.name as to_store_name
+    to_store.name as to_store_name,
+    COALESCE(pt.transaction_number, po.po_number, sm.metadata->>'transfer_number', '')::text as reference_number
 FROM stock_movements sm
 INNER JOIN products p ON sm.product_id = p.id
 LEFT JOIN stores from_store ON sm.from_store_id = from_store.id
 LEFT JOIN stores to_store ON sm.to_store_id = to_store.id
+LEFT JOIN pos_transactions pt ON sm.reference_type = 'pos_transaction' AND pt.id = sm.reference_id
+LEFT JOIN purchase_orders po ON sm.reference_type = 'purchase_order' AND po.id = sm.reference_id
 WHERE sm.serial_number = $1
-ORDER BY sm.movement_date DESC
+ORDER BY sm.movement_date, sm.id
 `
 
 type GetSerialNumberHistoryRow struct {
@@ -590,6 +631,7 @@ type GetSerialNumberHistoryRow struct {
 	ProductSku       string           `json:"product_sku"`
 	FromStoreName    pgtype.Text      `json:"from_store_name"`
 	ToStoreName      pgtype.Text      `json:"to_store_name"`
+	ReferenceNumber  string           `json:"reference_number"`
 }
 
 // =====================================================
@@ -631,6 +673,7 @@ func (q *Queries) GetSerialNumberHistory(ctx context.Context, serialNumber pgtyp
 			&i.ProductSku,
 			&i.FromStoreName,
 			&i.ToStoreName,
+			&i.ReferenceNumber,
 		); err != nil {
 			return nil, err
 		}
@@ -694,6 +737,58 @@ func (q *Queries) GetSerialNumberStockSummary(ctx context.Context, organizationI
 	return items, nil
 }
 
+const listAllocatableBatches = `-- name: ListAllocatableBatches :many
+SELECT id, product_id, product_variant_id, batch_number, manufacturing_date, expiry_date, store_id, quantity_available, status, metadata, created_at, updated_at FROM product_batches
+WHERE product_id = $1
+  AND product_variant_id IS NOT DISTINCT FROM $2
+  AND store_id = $3
+  AND status = 'active'
+  AND quantity_available > 0
+ORDER BY expiry_date NULLS LAST, manufacturing_date NULLS LAST, id
+`
+
+type ListAllocatableBatchesParams struct {
+	ProductID        int32       `json:"product_id"`
+	ProductVariantID pgtype.Int4 `json:"product_variant_id"`
+	StoreID          pgtype.Int4 `json:"store_id"`
+}
+
+// Active batches of a product (exact variant) with stock in a store, in
+// first-expiry-first-out order. Expired batches are included so callers can
+// report them; they are never allocated.
+func (q *Queries) ListAllocatableBatches(ctx context.Context, arg ListAllocatableBatchesParams) ([]ProductBatch, error) {
+	rows, err := q.db.Query(ctx, listAllocatableBatches, arg.ProductID, arg.ProductVariantID, arg.StoreID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProductBatch
+	for rows.Next() {
+		var i ProductBatch
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.ProductVariantID,
+			&i.BatchNumber,
+			&i.ManufacturingDate,
+			&i.ExpiryDate,
+			&i.StoreID,
+			&i.QuantityAvailable,
+			&i.Status,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listBatchesByStore = `-- name: ListBatchesByStore :many
 SELECT 
     pb.id, pb.product_id, pb.product_variant_id, pb.batch_number, pb.manufacturing_date, pb.expiry_date, pb.store_id, pb.quantity_available, pb.status, pb.metadata, pb.created_at, pb.updated_at,
@@ -844,6 +939,58 @@ func (q *Queries) ListProductBatches(ctx context.Context, arg ListProductBatches
 	return items, nil
 }
 
+const receiveBatchQuantity = `-- name: ReceiveBatchQuantity :one
+INSERT INTO product_batches (
+    product_id, product_variant_id, batch_number,
+    manufacturing_date, expiry_date, store_id,
+    quantity_available, status, metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, 'active', '{}'
+)
+ON CONFLICT (product_id, batch_number, store_id) DO UPDATE
+SET quantity_available = product_batches.quantity_available + EXCLUDED.quantity_available
+RETURNING id, product_id, product_variant_id, batch_number, manufacturing_date, expiry_date, store_id, quantity_available, status, metadata, created_at, updated_at
+`
+
+type ReceiveBatchQuantityParams struct {
+	ProductID         int32          `json:"product_id"`
+	ProductVariantID  pgtype.Int4    `json:"product_variant_id"`
+	BatchNumber       string         `json:"batch_number"`
+	ManufacturingDate pgtype.Date    `json:"manufacturing_date"`
+	ExpiryDate        pgtype.Date    `json:"expiry_date"`
+	StoreID           pgtype.Int4    `json:"store_id"`
+	QuantityAvailable pgtype.Numeric `json:"quantity_available"`
+}
+
+// Adds stock to a batch in a store, creating the batch on first receipt.
+func (q *Queries) ReceiveBatchQuantity(ctx context.Context, arg ReceiveBatchQuantityParams) (ProductBatch, error) {
+	row := q.db.QueryRow(ctx, receiveBatchQuantity,
+		arg.ProductID,
+		arg.ProductVariantID,
+		arg.BatchNumber,
+		arg.ManufacturingDate,
+		arg.ExpiryDate,
+		arg.StoreID,
+		arg.QuantityAvailable,
+	)
+	var i ProductBatch
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.ProductVariantID,
+		&i.BatchNumber,
+		&i.ManufacturingDate,
+		&i.ExpiryDate,
+		&i.StoreID,
+		&i.QuantityAvailable,
+		&i.Status,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const updateProductBatch = `-- name: UpdateProductBatch :one
 UPDATE product_batches
 SET 
@@ -11,6 +11,19 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countCategoryProducts = `-- name: CountCategoryProducts :one
+SELECT COUNT(*) FROM products
+WHERE category_id = $1
+`
+
+// Products of any status filed under the category.
+func (q *Queries) CountCategoryProducts(ctx context.Context, categoryID pgtype.Int4) (int64, error) {
+	row := q.db.QueryRow(ctx, countCategoryProducts, categoryID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const getCategoryWithPath = `-- name: GetCategoryWithPath :one
 WITH RECURSIVE cat_path AS (
     SELECT pc.id, pc.parent_category_id, pc.name, pc.code, ARRAY[pc.id] AS path
@@ -153,3 +166,184 @@ func (q *Queries) ListCategoriesWithUsageCount(ctx context.Context, arg ListCate
 	}
 	return items, nil
 }
+
+const listCategoryProductCounts = `-- name: ListCategoryProductCounts :many
+SELECT category_id, COUNT(*) AS product_count
+FROM products
+WHERE category_id IS NOT NULL
+  AND is_active = true
+GROUP BY category_id
+`
+
+type ListCategoryProductCountsRow struct {
+	CategoryID   pgtype.Int4 `json:"category_id"`
+	ProductCount int64       `json:"product_count"`
+}
+
+func (q *Queries) ListCategoryProductCounts(ctx context.Context) ([]ListCategoryProductCountsRow, error) {
+	rows, err := q.db.Query(ctx, listCategoryProductCounts)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListCategoryProductCountsRow
+	for rows.Next() {
+		var i ListCategoryProductCountsRow
+		if err := rows.Scan(&i.CategoryID, &i.ProductCount); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCategorySubtreeIDs = `-- name: ListCategorySubtreeIDs :many
+WITH RECURSIVE subtree AS (
+    SELECT id FROM product_categories WHERE id = $1
+    UNION
+    SELECT c.id
+    FROM product_categories c
+    INNER JOIN subtree s ON c.parent_category_id = s.id
+)
+SELECT id FROM subtree
+`
+
+// The category and all of its descendants.
+func (q *Queries) ListCategorySubtreeIDs(ctx context.Context, id int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listCategorySubtreeIDs, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockCategoryTree = `-- name: LockCategoryTree :exec
+SELECT pg_advisory_xact_lock(hashtext('product_categories'), 0)
+`
+
+// Serialises changes to the category tree until the transaction ends.
+func (q *Queries) LockCategoryTree(ctx context.Context) error {
+	_, err := q.db.Exec(ctx, lockCategoryTree)
+	return err
+}
+
+const reassignCategoryProducts = `-- name: ReassignCategoryProducts :execrows
+UPDATE products
+SET category_id = $1
+WHERE category_id = $2
+`
+
+type ReassignCategoryProductsParams struct {
+	ToCategoryID   pgtype.Int4 `json:"to_category_id"`
+	FromCategoryID pgtype.Int4 `json:"from_category_id"`
+}
+
+func (q *Queries) ReassignCategoryProducts(ctx context.Context, arg ReassignCategoryProductsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reassignCategoryProducts, arg.ToCategoryID, arg.FromCategoryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const reparentCategoryChildren = `-- name: ReparentCategoryChildren :execrows
+UPDATE product_categories
+SET parent_category_id = $1
+WHERE parent_category_id = $2
+`
+
+type ReparentCategoryChildrenParams struct {
+	ToCategoryID   pgtype.Int4 `json:"to_category_id"`
+	FromCategoryID pgtype.Int4 `json:"from_category_id"`
+}
+
+func (q *Queries) ReparentCategoryChildren(ctx context.Context, arg ReparentCategoryChildrenParams) (int64, error) {
+	result, err := q.db.Exec(ctx, reparentCategoryChildren, arg.ToCategoryID, arg.FromCategoryID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const setCategoryParent = `-- name: SetCategoryParent :one
+UPDATE product_categories
+SET parent_category_id = $1
+WHERE id = $2
+RETURNING id, parent_category_id, name, code, description, category_level, is_active, metadata, created_at, updated_at
+`
+
+type SetCategoryParentParams struct {
+	ParentCategoryID pgtype.Int4 `json:"parent_category_id"`
+	ID               int32       `json:"id"`
+}
+
+// Unlike UpdateProductCategory this can also clear the parent.
+func (q *Queries) SetCategoryParent(ctx context.Context, arg SetCategoryParentParams) (ProductCategory, error) {
+	row := q.db.QueryRow(ctx, setCategoryParent, arg.ParentCategoryID, arg.ID)
+	var i ProductCategory
+	err := row.Scan(
+		&i.ID,
+		&i.ParentCategoryID,
+		&i.Name,
+		&i.Code,
+		&i.Description,
+		&i.CategoryLevel,
+		&i.IsActive,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const updateCategorySubtreeLevels = `-- name: UpdateCategorySubtreeLevels :execrows
+WITH RECURSIVE ancestors AS (
+    SELECT parent_category_id AS id, 1 AS depth
+    FROM product_categories
+    WHERE id = $1 AND parent_category_id IS NOT NULL
+    UNION ALL
+    SELECT c.parent_category_id, a.depth + 1
+    FROM product_categories c
+    INNER JOIN ancestors a ON c.id = a.id
+    WHERE c.parent_category_id IS NOT NULL
+),
+subtree AS (
+    SELECT id, (SELECT COUNT(*) FROM ancestors)::int + 1 AS level
+    FROM product_categories
+    WHERE id = $1
+    UNION ALL
+    SELECT c.id, s.level + 1
+    FROM product_categories c
+    INNER JOIN subtree s ON c.parent_category_id = s.id
+)
+UPDATE product_categories pc
+SET category_level = s.level
+FROM subtree s
+WHERE pc.id = s.id
+  AND pc.category_level IS DISTINCT FROM s.level
+`
+
+// Sets category_level to the depth (roots are 1) for the category and its
+// descendants, after it was created or moved.
+func (q *Queries) UpdateCategorySubtreeLevels(ctx context.Context, id int32) (int64, error) {
+	result, err := q.db.Exec(ctx, updateCategorySubtreeLevels, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
@@ -11,21 +11,45 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
-const bulkUpdatePrices = `-- name: BulkUpdatePrices :exec
-UPDATE product_prices
-SET price = price * (1 + $2 / 100.0)
-WHERE price_list_id = $1
-  AND is_active = true
+const bulkUpdatePrices = `-- name: BulkUpdatePrices :execrows
+UPDATE product_prices pp
+SET price = GREATEST(ROUND(CASE
+        WHEN $1::text = 'fixed' THEN pp.price + $2::numeric
+        ELSE pp.price * (1 + $2::numeric / 100.0)
+    END, 2), 0)
+FROM products p
+WHERE p.id = pp.product_id
+  AND pp.price_list_id = $3
+  AND pp.is_active = true
+  AND (pp.valid_from IS NULL OR pp.valid_from <= CURRENT_DATE)
+  AND (pp.valid_to IS NULL OR pp.valid_to >= CURRENT_DATE)
+  AND ($4::int IS NULL OR p.category_id = $4)
+  AND ($5::int IS NULL OR p.brand_id = $5)
 `
 
 type BulkUpdatePricesParams struct {
-	PriceListID int32       `json:"price_list_id"`
-	Column2     interface{} `json:"column_2"`
+	Mode        string         `json:"mode"`
+	Amount      pgtype.Numeric `json:"amount"`
+	PriceListID int32          `json:"price_list_id"`
+	CategoryID  pgtype.Int4    `json:"category_id"`
+	BrandID     pgtype.Int4    `json:"brand_id"`
 }
 
-func (q *Queries) BulkUpdatePrices(ctx context.Context, arg BulkUpdatePricesParams) error {
-	_, err := q.db.Exec(ctx, bulkUpdatePrices, arg.PriceListID, arg.Column2)
-	return err
+// Adjusts the active prices of a list running today by a percentage or a
+// fixed amount, optionally only for one category or brand. Versions
+// scheduled for later dates keep their price. Prices never go below zero.
+func (q *Queries) BulkUpdatePrices(ctx context.Context, arg BulkUpdatePricesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, bulkUpdatePrices,
+		arg.Mode,
+		arg.Amount,
+		arg.PriceListID,
+		arg.CategoryID,
+		arg.BrandID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
 }
 
 const createProductPrice = `-- name: CreateProductPrice :one
@@ -120,14 +144,14 @@ func (q *Queries) ExpirePrices(ctx context.Context, priceListID int32) error {
 const getEffectivePrice = `-- name: GetEffectivePrice :one
 SELECT pp.id, pp.product_id, pp.product_variant_id, pp.price_list_id, pp.uom_id, pp.price, pp.min_quantity, pp.max_quantity, pp.valid_from, pp.valid_to, pp.is_active, pp.metadata, pp.created_at, pp.updated_at FROM product_prices pp
 WHERE pp.product_id = $1
-  AND pp.product_variant_id = COALESCE($4, pp.product_variant_id)
+  AND (pp.product_variant_id IS NULL OR pp.product_variant_id = $4)
   AND pp.price_list_id = $2
   AND pp.is_active = true
   AND (pp.valid_from IS NULL OR pp.valid_from <= CURRENT_DATE)
   AND (pp.valid_to IS NULL OR pp.valid_to >= CURRENT_DATE)
   AND pp.min_quantity <= $3
   AND (pp.max_quantity IS NULL OR pp.max_quantity >= $3)
-ORDER BY pp.min_quantity DESC
+ORDER BY pp.product_variant_id NULLS LAST, pp.min_quantity DESC
 LIMIT 1
 `
 
@@ -372,6 +396,118 @@ func (q *Queries) GetProductWithPricing(ctx context.Context, id int32) (GetProdu
 	return i, err
 }
 
+const listPriceCandidates = `-- name: ListPriceCandidates :many
+SELECT id, product_id, product_variant_id, price_list_id, uom_id, price, min_quantity, max_quantity, valid_from, valid_to, is_active, metadata, created_at, updated_at FROM product_prices
+WHERE product_id = ANY($1::int[])
+  AND price_list_id = ANY($2::int[])
+  AND is_active = true
+ORDER BY product_id, price_list_id, id
+`
+
+type ListPriceCandidatesParams struct {
+	ProductIds   []int32 `json:"product_ids"`
+	PriceListIds []int32 `json:"price_list_ids"`
+}
+
+// Active price rows of the given products in the given price lists; the
+// price resolver applies variant, UoM, tier and date rules in Go.
+func (q *Queries) ListPriceCandidates(ctx context.Context, arg ListPriceCandidatesParams) ([]ProductPrice, error) {
+	rows, err := q.db.Query(ctx, listPriceCandidates, arg.ProductIds, arg.PriceListIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProductPrice
+	for rows.Next() {
+		var i ProductPrice
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.ProductVariantID,
+			&i.PriceListID,
+			&i.UomID,
+			&i.Price,
+			&i.MinQuantity,
+			&i.MaxQuantity,
+			&i.ValidFrom,
+			&i.ValidTo,
+			&i.IsActive,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listPriceVersions = `-- name: ListPriceVersions :many
+SELECT id, product_id, product_variant_id, price_list_id, uom_id, price, min_quantity, max_quantity, valid_from, valid_to, is_active, metadata, created_at, updated_at FROM product_prices
+WHERE price_list_id = $1
+  AND product_id = $2
+  AND product_variant_id IS NOT DISTINCT FROM $3
+  AND uom_id IS NOT DISTINCT FROM $4
+  AND min_quantity IS NOT DISTINCT FROM $5
+  AND is_active = true
+ORDER BY valid_from NULLS FIRST, id
+`
+
+type ListPriceVersionsParams struct {
+	PriceListID      int32          `json:"price_list_id"`
+	ProductID        int32          `json:"product_id"`
+	ProductVariantID pgtype.Int4    `json:"product_variant_id"`
+	UomID            pgtype.Int4    `json:"uom_id"`
+	MinQuantity      pgtype.Numeric `json:"min_quantity"`
+}
+
+// All active versions of one price: same list, product, variant, unit and
+// quantity tier.
+func (q *Queries) ListPriceVersions(ctx context.Context, arg ListPriceVersionsParams) ([]ProductPrice, error) {
+	rows, err := q.db.Query(ctx, listPriceVersions,
+		arg.PriceListID,
+		arg.ProductID,
+		arg.ProductVariantID,
+		arg.UomID,
+		arg.MinQuantity,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProductPrice
+	for rows.Next() {
+		var i ProductPrice
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.ProductVariantID,
+			&i.PriceListID,
+			&i.UomID,
+			&i.Price,
+			&i.MinQuantity,
+			&i.MaxQuantity,
+			&i.ValidFrom,
+			&i.ValidTo,
+			&i.IsActive,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPricesByPriceList = `-- name: ListPricesByPriceList :many
 SELECT 
     pp.id, pp.product_id, pp.product_variant_id, pp.price_list_id, pp.uom_id, pp.price, pp.min_quantity, pp.max_quantity, pp.valid_from, pp.valid_to, pp.is_active, pp.metadata, pp.created_at, pp.updated_at,
@@ -447,6 +583,67 @@ func (q *Queries) ListPricesByPriceList(ctx context.Context, priceListID int32)
 	return items, nil
 }
 
+const listPricesForAdjustment = `-- name: ListPricesForAdjustment :many
+SELECT pp.id, pp.product_id, pp.product_variant_id, pp.price_list_id, pp.uom_id, pp.price, pp.min_quantity, pp.max_quantity, pp.valid_from, pp.valid_to, pp.is_active, pp.metadata, pp.created_at, pp.updated_at FROM product_prices pp
+JOIN products p ON p.id = pp.product_id
+WHERE pp.price_list_id = $1
+  AND pp.is_active = true
+  AND (pp.valid_from IS NULL OR pp.valid_from <= $2::date)
+  AND (pp.valid_to IS NULL OR pp.valid_to >= $2::date)
+  AND ($3::int IS NULL OR p.category_id = $3)
+  AND ($4::int IS NULL OR p.brand_id = $4)
+ORDER BY pp.product_id, pp.id
+`
+
+type ListPricesForAdjustmentParams struct {
+	PriceListID int32       `json:"price_list_id"`
+	OnDate      pgtype.Date `json:"on_date"`
+	CategoryID  pgtype.Int4 `json:"category_id"`
+	BrandID     pgtype.Int4 `json:"brand_id"`
+}
+
+// Active prices of a list running on a date, optionally only for one
+// category or brand.
+func (q *Queries) ListPricesForAdjustment(ctx context.Context, arg ListPricesForAdjustmentParams) ([]ProductPrice, error) {
+	rows, err := q.db.Query(ctx, listPricesForAdjustment,
+		arg.PriceListID,
+		arg.OnDate,
+		arg.CategoryID,
+		arg.BrandID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ProductPrice
+	for rows.Next() {
+		var i ProductPrice
+		if err := rows.Scan(
+			&i.ID,
+			&i.ProductID,
+			&i.ProductVariantID,
+			&i.PriceListID,
+			&i.UomID,
+			&i.Price,
+			&i.MinQuantity,
+			&i.MaxQuantity,
+			&i.ValidFrom,
+			&i.ValidTo,
+			&i.IsActive,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listProductPrices = `-- name: ListProductPrices :many
 SELECT 
     pp.id, pp.product_id, pp.product_variant_id, pp.price_list_id, pp.uom_id, pp.price, pp.min_quantity, pp.max_quantity, pp.valid_from, pp.valid_to, pp.is_active, pp.metadata, pp.created_at, pp.updated_at,
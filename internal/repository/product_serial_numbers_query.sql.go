@@ -397,6 +397,49 @@ func (q *Queries) ListProductSerialNumbersByStore(ctx context.Context, currentSt
 	return items, nil
 }
 
+const moveSerialNumber = `-- name: MoveSerialNumber :one
+UPDATE product_serial_numbers
+SET
+    status = $1,
+    current_store_id = $2
+WHERE id = $3
+  AND status = $4
+RETURNING id, product_id, product_variant_id, serial_number, status, current_store_id, manufacturing_date, expiry_date, metadata, created_at, updated_at
+`
+
+type MoveSerialNumberParams struct {
+	Status     pgtype.Text `json:"status"`
+	StoreID    pgtype.Int4 `json:"store_id"`
+	ID         int32       `json:"id"`
+	FromStatus pgtype.Text `json:"from_status"`
+}
+
+// Changes a serial's status and store only if it still has from_status, so
+// concurrent sales or returns of the same unit cannot both succeed.
+func (q *Queries) MoveSerialNumber(ctx context.Context, arg MoveSerialNumberParams) (ProductSerialNumber, error) {
+	row := q.db.QueryRow(ctx, moveSerialNumber,
+		arg.Status,
+		arg.StoreID,
+		arg.ID,
+		arg.FromStatus,
+	)
+	var i ProductSerialNumber
+	err := row.Scan(
+		&i.ID,
+		&i.ProductID,
+		&i.ProductVariantID,
+		&i.SerialNumber,
+		&i.Status,
+		&i.CurrentStoreID,
+		&i.ManufacturingDate,
+		&i.ExpiryDate,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const transferSerialNumber = `-- name: TransferSerialNumber :one
 UPDATE product_serial_numbers
 SET 
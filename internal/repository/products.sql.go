@@ -677,9 +677,10 @@ const listProducts = `-- name: ListProducts :many
 SELECT id, organization_id, sku, name, description, category_id, brand_id, base_uom_id, product_type, tax_category_id, is_serialized, is_batch_managed, is_active, is_sellable, is_purchasable, allow_decimal_quantity, track_inventory, metadata, created_at, updated_at FROM products
 WHERE organization_id = $1
   AND is_active = COALESCE($4, is_active)
-  AND category_id = COALESCE($5, category_id)
-  AND brand_id = COALESCE($6, brand_id)
-  AND product_type = COALESCE($7, product_type)
+  AND ($5::int IS NULL OR category_id = $5)
+  AND ($6::int IS NULL OR brand_id = $6)
+  AND ($7::text IS NULL OR product_type = $7)
+  AND ($8::text IS NULL OR sku ILIKE '%' || $8 || '%' OR name ILIKE '%' || $8 || '%')
 ORDER BY name
 LIMIT $2 OFFSET $3
 `
@@ -692,8 +693,11 @@ type ListProductsParams struct {
 	CategoryID     pgtype.Int4 `json:"category_id"`
 	BrandID        pgtype.Int4 `json:"brand_id"`
 	ProductType    pgtype.Text `json:"product_type"`
+	Search         pgtype.Text `json:"search"`
 }
 
+// Products of an organization; each filter applies only when given, and
+// search matches SKU or name.
 func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]Product, error) {
 	rows, err := q.db.Query(ctx, listProducts,
 		arg.OrganizationID,
@@ -703,6 +707,7 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]P
 		arg.CategoryID,
 		arg.BrandID,
 		arg.ProductType,
+		arg.Search,
 	)
 	if err != nil {
 		return nil, err
@@ -743,6 +748,52 @@ func (q *Queries) ListProducts(ctx context.Context, arg ListProductsParams) ([]P
 	return items, nil
 }
 
+const listProductsByIDs = `-- name: ListProductsByIDs :many
+SELECT id, organization_id, sku, name, description, category_id, brand_id, base_uom_id, product_type, tax_category_id, is_serialized, is_batch_managed, is_active, is_sellable, is_purchasable, allow_decimal_quantity, track_inventory, metadata, created_at, updated_at FROM products
+WHERE id = ANY($1::int[])
+`
+
+func (q *Queries) ListProductsByIDs(ctx context.Context, ids []int32) ([]Product, error) {
+	rows, err := q.db.Query(ctx, listProductsByIDs, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Product
+	for rows.Next() {
+		var i Product
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.Sku,
+			&i.Name,
+			&i.Description,
+			&i.CategoryID,
+			&i.BrandID,
+			&i.BaseUomID,
+			&i.ProductType,
+			&i.TaxCategoryID,
+			&i.IsSerialized,
+			&i.IsBatchManaged,
+			&i.IsActive,
+			&i.IsSellable,
+			&i.IsPurchasable,
+			&i.AllowDecimalQuantity,
+			&i.TrackInventory,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPurchasableProducts = `-- name: ListPurchasableProducts :many
 SELECT id, organization_id, sku, name, description, category_id, brand_id, base_uom_id, product_type, tax_category_id, is_serialized, is_batch_managed, is_active, is_sellable, is_purchasable, allow_decimal_quantity, track_inventory, metadata, created_at, updated_at FROM products
 WHERE organization_id = $1
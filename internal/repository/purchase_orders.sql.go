@@ -11,6 +11,133 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const createPurchaseOrderHeader = `-- name: CreatePurchaseOrderHeader :one
+INSERT INTO purchase_orders (
+    po_number, organization_id, supplier_id, store_id,
+    po_date, expected_delivery_date, status,
+    subtotal, tax_amount, discount_amount, total_amount,
+    created_by, metadata
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+RETURNING id, po_number, status, total_amount
+`
+
+type CreatePurchaseOrderHeaderParams struct {
+	PoNumber             string         `json:"po_number"`
+	OrganizationID       int32          `json:"organization_id"`
+	SupplierID           int32          `json:"supplier_id"`
+	StoreID              int32          `json:"store_id"`
+	PoDate               pgtype.Date    `json:"po_date"`
+	ExpectedDeliveryDate pgtype.Date    `json:"expected_delivery_date"`
+	Status               pgtype.Text    `json:"status"`
+	Subtotal             pgtype.Numeric `json:"subtotal"`
+	TaxAmount            pgtype.Numeric `json:"tax_amount"`
+	DiscountAmount       pgtype.Numeric `json:"discount_amount"`
+	TotalAmount          pgtype.Numeric `json:"total_amount"`
+	CreatedBy            pgtype.Int4    `json:"created_by"`
+	Metadata             []byte         `json:"metadata"`
+}
+
+type CreatePurchaseOrderHeaderRow struct {
+	ID          int32          `json:"id"`
+	PoNumber    string         `json:"po_number"`
+	Status      pgtype.Text    `json:"status"`
+	TotalAmount pgtype.Numeric `json:"total_amount"`
+}
+
+func (q *Queries) CreatePurchaseOrderHeader(ctx context.Context, arg CreatePurchaseOrderHeaderParams) (CreatePurchaseOrderHeaderRow, error) {
+	row := q.db.QueryRow(ctx, createPurchaseOrderHeader,
+		arg.PoNumber,
+		arg.OrganizationID,
+		arg.SupplierID,
+		arg.StoreID,
+		arg.PoDate,
+		arg.ExpectedDeliveryDate,
+		arg.Status,
+		arg.Subtotal,
+		arg.TaxAmount,
+		arg.DiscountAmount,
+		arg.TotalAmount,
+		arg.CreatedBy,
+		arg.Metadata,
+	)
+	var i CreatePurchaseOrderHeaderRow
+	err := row.Scan(
+		&i.ID,
+		&i.PoNumber,
+		&i.Status,
+		&i.TotalAmount,
+	)
+	return i, err
+}
+
+const createPurchaseOrderLine = `-- name: CreatePurchaseOrderLine :exec
+INSERT INTO purchase_order_lines (
+    purchase_order_id, line_number, product_id, product_variant_id,
+    quantity, uom_id, unit_price, discount_amount,
+    tax_amount, line_total, metadata
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+`
+
+type CreatePurchaseOrderLineParams struct {
+	PurchaseOrderID  int32          `json:"purchase_order_id"`
+	LineNumber       int32          `json:"line_number"`
+	ProductID        int32          `json:"product_id"`
+	ProductVariantID pgtype.Int4    `json:"product_variant_id"`
+	Quantity         pgtype.Numeric `json:"quantity"`
+	UomID            pgtype.Int4    `json:"uom_id"`
+	UnitPrice        pgtype.Numeric `json:"unit_price"`
+	DiscountAmount   pgtype.Numeric `json:"discount_amount"`
+	TaxAmount        pgtype.Numeric `json:"tax_amount"`
+	LineTotal        pgtype.Numeric `json:"line_total"`
+	Metadata         []byte         `json:"metadata"`
+}
+
+func (q *Queries) CreatePurchaseOrderLine(ctx context.Context, arg CreatePurchaseOrderLineParams) error {
+	_, err := q.db.Exec(ctx, createPurchaseOrderLine,
+		arg.PurchaseOrderID,
+		arg.LineNumber,
+		arg.ProductID,
+		arg.ProductVariantID,
+		arg.Quantity,
+		arg.UomID,
+		arg.UnitPrice,
+		arg.DiscountAmount,
+		arg.TaxAmount,
+		arg.LineTotal,
+		arg.Metadata,
+	)
+	return err
+}
+
+const getPurchaseOrder = `-- name: GetPurchaseOrder :one
+SELECT id, po_number, organization_id, supplier_id, store_id, po_date, expected_delivery_date, status, subtotal, tax_amount, discount_amount, total_amount, created_by, approved_by, metadata, created_at, updated_at FROM purchase_orders WHERE id = $1
+`
+
+func (q *Queries) GetPurchaseOrder(ctx context.Context, id int32) (PurchaseOrder, error) {
+	row := q.db.QueryRow(ctx, getPurchaseOrder, id)
+	var i PurchaseOrder
+	err := row.Scan(
+		&i.ID,
+		&i.PoNumber,
+		&i.OrganizationID,
+		&i.SupplierID,
+		&i.StoreID,
+		&i.PoDate,
+		&i.ExpectedDeliveryDate,
+		&i.Status,
+		&i.Subtotal,
+		&i.TaxAmount,
+		&i.DiscountAmount,
+		&i.TotalAmount,
+		&i.CreatedBy,
+		&i.ApprovedBy,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getPurchaseOrderWithReceivedQty = `-- name: GetPurchaseOrderWithReceivedQty :many
 SELECT 
     po.id,
@@ -83,3 +210,81 @@ func (q *Queries) GetPurchaseOrderWithReceivedQty(ctx context.Context, id int32)
 	}
 	return items, nil
 }
+
+const listPurchaseOrderLines = `-- name: ListPurchaseOrderLines :many
+SELECT id, purchase_order_id, line_number, product_id, product_variant_id, quantity, received_quantity, uom_id, unit_price, discount_amount, tax_amount, line_total, metadata FROM purchase_order_lines
+WHERE purchase_order_id = $1
+ORDER BY line_number
+`
+
+func (q *Queries) ListPurchaseOrderLines(ctx context.Context, purchaseOrderID int32) ([]PurchaseOrderLine, error) {
+	rows, err := q.db.Query(ctx, listPurchaseOrderLines, purchaseOrderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []PurchaseOrderLine
+	for rows.Next() {
+		var i PurchaseOrderLine
+		if err := rows.Scan(
+			&i.ID,
+			&i.PurchaseOrderID,
+			&i.LineNumber,
+			&i.ProductID,
+			&i.ProductVariantID,
+			&i.Quantity,
+			&i.ReceivedQuantity,
+			&i.UomID,
+			&i.UnitPrice,
+			&i.DiscountAmount,
+			&i.TaxAmount,
+			&i.LineTotal,
+			&i.Metadata,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const receivePurchaseOrderLine = `-- name: ReceivePurchaseOrderLine :execrows
+UPDATE purchase_order_lines
+SET received_quantity = COALESCE(received_quantity, 0) + $1
+WHERE id = $2
+  AND COALESCE(received_quantity, 0) + $1 <= quantity
+`
+
+type ReceivePurchaseOrderLineParams struct {
+	Quantity pgtype.Numeric `json:"quantity"`
+	ID       int32          `json:"id"`
+}
+
+// Adds a received quantity to an order line unless it would exceed the
+// ordered quantity.
+func (q *Queries) ReceivePurchaseOrderLine(ctx context.Context, arg ReceivePurchaseOrderLineParams) (int64, error) {
+	result, err := q.db.Exec(ctx, receivePurchaseOrderLine, arg.Quantity, arg.ID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const updatePurchaseOrderStatus = `-- name: UpdatePurchaseOrderStatus :exec
+UPDATE purchase_orders
+SET status = $2
+WHERE id = $1
+`
+
+type UpdatePurchaseOrderStatusParams struct {
+	ID     int32       `json:"id"`
+	Status pgtype.Text `json:"status"`
+}
+
+func (q *Queries) UpdatePurchaseOrderStatus(ctx context.Context, arg UpdatePurchaseOrderStatusParams) error {
+	_, err := q.db.Exec(ctx, updatePurchaseOrderStatus, arg.ID, arg.Status)
+	return err
+}
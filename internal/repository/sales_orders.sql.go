@@ -72,6 +72,47 @@ func (q *Queries) CreateSalesOrderHeader(ctx context.Context, arg CreateSalesOrd
 	return i, err
 }
 
+const createSalesOrderLine = `-- name: CreateSalesOrderLine :exec
+INSERT INTO sales_order_lines (
+    sales_order_id, line_number, product_id, product_variant_id,
+    quantity, uom_id, unit_price, discount_amount,
+    tax_amount, line_total, cost_price, metadata
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)
+`
+
+type CreateSalesOrderLineParams struct {
+	SalesOrderID     int32          `json:"sales_order_id"`
+	LineNumber       int32          `json:"line_number"`
+	ProductID        int32          `json:"product_id"`
+	ProductVariantID pgtype.Int4    `json:"product_variant_id"`
+	Quantity         pgtype.Numeric `json:"quantity"`
+	UomID            pgtype.Int4    `json:"uom_id"`
+	UnitPrice        pgtype.Numeric `json:"unit_price"`
+	DiscountAmount   pgtype.Numeric `json:"discount_amount"`
+	TaxAmount        pgtype.Numeric `json:"tax_amount"`
+	LineTotal        pgtype.Numeric `json:"line_total"`
+	CostPrice        pgtype.Numeric `json:"cost_price"`
+	Metadata         []byte         `json:"metadata"`
+}
+
+func (q *Queries) CreateSalesOrderLine(ctx context.Context, arg CreateSalesOrderLineParams) error {
+	_, err := q.db.Exec(ctx, createSalesOrderLine,
+		arg.SalesOrderID,
+		arg.LineNumber,
+		arg.ProductID,
+		arg.ProductVariantID,
+		arg.Quantity,
+		arg.UomID,
+		arg.UnitPrice,
+		arg.DiscountAmount,
+		arg.TaxAmount,
+		arg.LineTotal,
+		arg.CostPrice,
+		arg.Metadata,
+	)
+	return err
+}
+
 const getSalesOrderFull = `-- name: GetSalesOrderFull :many
 SELECT 
     so.id, so.order_number, so.organization_id, so.customer_id, so.store_id, so.order_date, so.delivery_date, so.price_list_id, so.status, so.subtotal, so.tax_amount, so.discount_amount, so.total_amount, so.created_by, so.approved_by, so.metadata, so.created_at, so.updated_at,
@@ -243,6 +243,32 @@ func (q *Queries) GetStoreByCode(ctx context.Context, arg GetStoreByCodeParams)
 	return i, err
 }
 
+const listOrganizationStoreIDs = `-- name: ListOrganizationStoreIDs :many
+SELECT id FROM stores
+WHERE organization_id = $1
+ORDER BY id
+`
+
+func (q *Queries) ListOrganizationStoreIDs(ctx context.Context, organizationID int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listOrganizationStoreIDs, organizationID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listPOSEnabledStores = `-- name: ListPOSEnabledStores :many
 SELECT id, organization_id, parent_store_id, name, code, store_type, is_warehouse, is_pos_enabled, timezone, is_active, metadata, created_at, updated_at FROM stores
 WHERE organization_id = $1
@@ -290,6 +316,7 @@ SELECT id, organization_id, parent_store_id, name, code, store_type, is_warehous
 WHERE organization_id = $1
   AND is_active = COALESCE($4, is_active)
   AND store_type = COALESCE($5, store_type)
+  AND ($6::int[] IS NULL OR id = ANY($6::int[]))
 ORDER BY name
 LIMIT $2 OFFSET $3
 `
@@ -300,6 +327,7 @@ type ListStoresParams struct {
 	Offset         int32       `json:"offset"`
 	IsActive       pgtype.Bool `json:"is_active"`
 	StoreType      pgtype.Text `json:"store_type"`
+	StoreIds       []int32     `json:"store_ids"`
 }
 
 func (q *Queries) ListStores(ctx context.Context, arg ListStoresParams) ([]Store, error) {
@@ -309,6 +337,7 @@ func (q *Queries) ListStores(ctx context.Context, arg ListStoresParams) ([]Store
 		arg.Offset,
 		arg.IsActive,
 		arg.StoreType,
+		arg.StoreIds,
 	)
 	if err != nil {
 		return nil, err
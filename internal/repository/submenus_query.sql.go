@@ -173,6 +173,85 @@ func (q *Queries) ListActiveSubmenusByMenu(ctx context.Context, menuID int32) ([
 	return items, nil
 }
 
+const listSubmenuSiblings = `-- name: ListSubmenuSiblings :many
+SELECT id, menu_id, parent_submenu_id, name, code, route_path, icon, display_order, is_active, metadata, created_at, updated_at FROM submenus
+WHERE menu_id = $1
+  AND parent_submenu_id IS NOT DISTINCT FROM $2
+ORDER BY display_order, id
+`
+
+type ListSubmenuSiblingsParams struct {
+	MenuID          int32       `json:"menu_id"`
+	ParentSubmenuID pgtype.Int4 `json:"parent_submenu_id"`
+}
+
+// Submenus of a menu under the same parent submenu (top-level submenus when
+// the parent is NULL), in display order.
+func (q *Queries) ListSubmenuSiblings(ctx context.Context, arg ListSubmenuSiblingsParams) ([]Submenu, error) {
+	rows, err := q.db.Query(ctx, listSubmenuSiblings, arg.MenuID, arg.ParentSubmenuID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Submenu
+	for rows.Next() {
+		var i Submenu
+		if err := rows.Scan(
+			&i.ID,
+			&i.MenuID,
+			&i.ParentSubmenuID,
+			&i.Name,
+			&i.Code,
+			&i.RoutePath,
+			&i.Icon,
+			&i.DisplayOrder,
+			&i.IsActive,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listSubmenuSubtreeIDs = `-- name: ListSubmenuSubtreeIDs :many
+WITH RECURSIVE subtree AS (
+    SELECT id FROM submenus WHERE id = $1
+    UNION
+    SELECT s.id
+    FROM submenus s
+    INNER JOIN subtree t ON s.parent_submenu_id = t.id
+)
+SELECT id FROM subtree
+`
+
+// The submenu and all of its descendant submenus.
+func (q *Queries) ListSubmenuSubtreeIDs(ctx context.Context, id int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listSubmenuSubtreeIDs, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var id int32
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listSubmenus = `-- name: ListSubmenus :many
 SELECT id, menu_id, parent_submenu_id, name, code, route_path, icon, display_order, is_active, metadata, created_at, updated_at FROM submenus
 ORDER BY display_order, id
@@ -289,6 +368,85 @@ func (q *Queries) ListSubmenusByParent(ctx context.Context, parentSubmenuID pgty
 	return items, nil
 }
 
+const setSubmenuDisplayOrder = `-- name: SetSubmenuDisplayOrder :exec
+UPDATE submenus
+SET display_order = $2
+WHERE id = $1
+`
+
+type SetSubmenuDisplayOrderParams struct {
+	ID           int32       `json:"id"`
+	DisplayOrder pgtype.Int4 `json:"display_order"`
+}
+
+func (q *Queries) SetSubmenuDisplayOrder(ctx context.Context, arg SetSubmenuDisplayOrderParams) error {
+	_, err := q.db.Exec(ctx, setSubmenuDisplayOrder, arg.ID, arg.DisplayOrder)
+	return err
+}
+
+const setSubmenuParent = `-- name: SetSubmenuParent :one
+UPDATE submenus
+SET menu_id = $1,
+    parent_submenu_id = $2
+WHERE id = $3
+RETURNING id, menu_id, parent_submenu_id, name, code, route_path, icon, display_order, is_active, metadata, created_at, updated_at
+`
+
+type SetSubmenuParentParams struct {
+	MenuID          int32       `json:"menu_id"`
+	ParentSubmenuID pgtype.Int4 `json:"parent_submenu_id"`
+	ID              int32       `json:"id"`
+}
+
+// Moves a submenu to a menu and parent submenu, leaving every other column
+// alone. Its descendants follow with SetSubmenuSubtreeMenu.
+func (q *Queries) SetSubmenuParent(ctx context.Context, arg SetSubmenuParentParams) (Submenu, error) {
+	row := q.db.QueryRow(ctx, setSubmenuParent, arg.MenuID, arg.ParentSubmenuID, arg.ID)
+	var i Submenu
+	err := row.Scan(
+		&i.ID,
+		&i.MenuID,
+		&i.ParentSubmenuID,
+		&i.Name,
+		&i.Code,
+		&i.RoutePath,
+		&i.Icon,
+		&i.DisplayOrder,
+		&i.IsActive,
+		&i.Metadata,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const setSubmenuSubtreeMenu = `-- name: SetSubmenuSubtreeMenu :execrows
+WITH RECURSIVE subtree AS (
+    SELECT id FROM submenus WHERE parent_submenu_id = $1
+    UNION
+    SELECT s.id
+    FROM submenus s
+    INNER JOIN subtree t ON s.parent_submenu_id = t.id
+)
+UPDATE submenus
+SET menu_id = $2
+WHERE id IN (SELECT id FROM subtree)
+`
+
+type SetSubmenuSubtreeMenuParams struct {
+	ID     pgtype.Int4 `json:"id"`
+	MenuID int32       `json:"menu_id"`
+}
+
+// Moves the descendants of a submenu to its new menu.
+func (q *Queries) SetSubmenuSubtreeMenu(ctx context.Context, arg SetSubmenuSubtreeMenuParams) (int64, error) {
+	result, err := q.db.Exec(ctx, setSubmenuSubtreeMenu, arg.ID, arg.MenuID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
 const toggleSubmenuActive = `-- name: ToggleSubmenuActive :one
 UPDATE submenus
 SET is_active = $2
@@ -11,6 +11,18 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const countTaxCategoryProducts = `-- name: CountTaxCategoryProducts :one
+SELECT COUNT(*) FROM products
+WHERE tax_category_id = $1
+`
+
+func (q *Queries) CountTaxCategoryProducts(ctx context.Context, taxCategoryID pgtype.Int4) (int64, error) {
+	row := q.db.QueryRow(ctx, countTaxCategoryProducts, taxCategoryID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createTaxCategory = `-- name: CreateTaxCategory :one
 INSERT INTO tax_categories (
     name,
@@ -177,6 +189,55 @@ func (q *Queries) ListTaxCategories(ctx context.Context) ([]TaxCategory, error)
 	return items, nil
 }
 
+const listTaxCategoriesWithUsage = `-- name: ListTaxCategoriesWithUsage :many
+SELECT
+    tc.id, tc.name, tc.code, tc.tax_rate, tc.is_inclusive, tc.is_active,
+    COUNT(p.id) AS product_count
+FROM tax_categories tc
+LEFT JOIN products p ON p.tax_category_id = tc.id
+WHERE ($1::boolean IS NULL OR tc.is_active = $1)
+GROUP BY tc.id
+ORDER BY tc.name
+`
+
+type ListTaxCategoriesWithUsageRow struct {
+	ID           int32          `json:"id"`
+	Name         string         `json:"name"`
+	Code         string         `json:"code"`
+	TaxRate      pgtype.Numeric `json:"tax_rate"`
+	IsInclusive  pgtype.Bool    `json:"is_inclusive"`
+	IsActive     pgtype.Bool    `json:"is_active"`
+	ProductCount int64          `json:"product_count"`
+}
+
+func (q *Queries) ListTaxCategoriesWithUsage(ctx context.Context, activeOnly pgtype.Bool) ([]ListTaxCategoriesWithUsageRow, error) {
+	rows, err := q.db.Query(ctx, listTaxCategoriesWithUsage, activeOnly)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListTaxCategoriesWithUsageRow
+	for rows.Next() {
+		var i ListTaxCategoriesWithUsageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Code,
+			&i.TaxRate,
+			&i.IsInclusive,
+			&i.IsActive,
+			&i.ProductCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const toggleTaxCategoryActive = `-- name: ToggleTaxCategoryActive :one
 UPDATE tax_categories
 SET is_active = $2
@@ -0,0 +1,29 @@
+package repository
+
+import (
+	"context"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// ExecTx runs fn inside a database transaction. The transaction commits when
+// fn returns nil and rolls back otherwise. Called on a Queries that is already
+// bound to a transaction, it runs fn inside a savepoint.
+func (q *Queries) ExecTx(ctx context.Context, fn func(*Queries) error) error {
+	db, ok := q.db.(interface {
+		Begin(context.Context) (pgx.Tx, error)
+	})
+	if !ok {
+		return errors.New("repository: database handle does not support transactions")
+	}
+	tx, err := db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	if err := fn(q.WithTx(tx)); err != nil {
+		_ = tx.Rollback(ctx)
+		return err
+	}
+	return tx.Commit(ctx)
+}
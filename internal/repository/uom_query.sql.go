@@ -187,6 +187,28 @@ func (q *Queries) GetUnitOfMeasureByCode(ctx context.Context, code string) (Unit
 	return i, err
 }
 
+const getUnitOfMeasureUsage = `-- name: GetUnitOfMeasureUsage :one
+SELECT
+    (SELECT COUNT(*) FROM products p WHERE p.base_uom_id = $1::int) AS product_count,
+    (SELECT COUNT(*) FROM product_uom_conversions c WHERE c.from_uom_id = $1::int OR c.to_uom_id = $1::int) AS conversion_count,
+    (SELECT COUNT(*) FROM product_prices pp WHERE pp.uom_id = $1::int) AS price_count
+`
+
+type GetUnitOfMeasureUsageRow struct {
+	ProductCount    int64 `json:"product_count"`
+	ConversionCount int64 `json:"conversion_count"`
+	PriceCount      int64 `json:"price_count"`
+}
+
+// What references a unit: products using it as base unit, unit
+// conversions (deleted with the unit) and prices.
+func (q *Queries) GetUnitOfMeasureUsage(ctx context.Context, id int32) (GetUnitOfMeasureUsageRow, error) {
+	row := q.db.QueryRow(ctx, getUnitOfMeasureUsage, id)
+	var i GetUnitOfMeasureUsageRow
+	err := row.Scan(&i.ProductCount, &i.ConversionCount, &i.PriceCount)
+	return i, err
+}
+
 const listActiveUnitsOfMeasure = `-- name: ListActiveUnitsOfMeasure :many
 SELECT id, code, name, uom_type, decimal_places, is_active, metadata FROM units_of_measure
 WHERE is_active = true
@@ -323,6 +345,62 @@ func (q *Queries) ListUnitsOfMeasure(ctx context.Context) ([]UnitsOfMeasure, err
 	return items, nil
 }
 
+const listUnitsOfMeasureWithUsage = `-- name: ListUnitsOfMeasureWithUsage :many
+SELECT
+    u.id, u.code, u.name, u.uom_type, u.decimal_places, u.is_active,
+    (SELECT COUNT(*) FROM products p WHERE p.base_uom_id = u.id) AS product_count,
+    (SELECT COUNT(*) FROM product_uom_conversions c WHERE c.from_uom_id = u.id OR c.to_uom_id = u.id) AS conversion_count
+FROM units_of_measure u
+WHERE ($1::boolean IS NULL OR u.is_active = $1)
+  AND ($2::text IS NULL OR u.uom_type = $2)
+ORDER BY u.name
+`
+
+type ListUnitsOfMeasureWithUsageParams struct {
+	ActiveOnly pgtype.Bool `json:"active_only"`
+	UomType    pgtype.Text `json:"uom_type"`
+}
+
+type ListUnitsOfMeasureWithUsageRow struct {
+	ID              int32       `json:"id"`
+	Code            string      `json:"code"`
+	Name            string      `json:"name"`
+	UomType         pgtype.Text `json:"uom_type"`
+	DecimalPlaces   pgtype.Int4 `json:"decimal_places"`
+	IsActive        pgtype.Bool `json:"is_active"`
+	ProductCount    int64       `json:"product_count"`
+	ConversionCount int64       `json:"conversion_count"`
+}
+
+func (q *Queries) ListUnitsOfMeasureWithUsage(ctx context.Context, arg ListUnitsOfMeasureWithUsageParams) ([]ListUnitsOfMeasureWithUsageRow, error) {
+	rows, err := q.db.Query(ctx, listUnitsOfMeasureWithUsage, arg.ActiveOnly, arg.UomType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUnitsOfMeasureWithUsageRow
+	for rows.Next() {
+		var i ListUnitsOfMeasureWithUsageRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Code,
+			&i.Name,
+			&i.UomType,
+			&i.DecimalPlaces,
+			&i.IsActive,
+			&i.ProductCount,
+			&i.ConversionCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const updateProductUOMConversion = `-- name: UpdateProductUOMConversion :one
 UPDATE product_uom_conversions
 SET 
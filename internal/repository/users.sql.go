@@ -42,6 +42,31 @@ func (q *Queries) AssignRoleToUser(ctx context.Context, arg AssignRoleToUserPara
 	return i, err
 }
 
+const checkUserHasOrganizationAccess = `-- name: CheckUserHasOrganizationAccess :one
+SELECT EXISTS(
+    SELECT 1 FROM users u
+    WHERE u.id = $1 AND u.organization_id = $2
+    UNION ALL
+    SELECT 1 FROM user_store_access usa
+    INNER JOIN stores s ON s.id = usa.store_id
+    WHERE usa.user_id = $1 AND s.organization_id = $2
+) as has_access
+`
+
+type CheckUserHasOrganizationAccessParams struct {
+	UserID         int32 `json:"user_id"`
+	OrganizationID int32 `json:"organization_id"`
+}
+
+// A user works in their own organization and in the organizations of the
+// stores they have access to.
+func (q *Queries) CheckUserHasOrganizationAccess(ctx context.Context, arg CheckUserHasOrganizationAccessParams) (bool, error) {
+	row := q.db.QueryRow(ctx, checkUserHasOrganizationAccess, arg.UserID, arg.OrganizationID)
+	var has_access bool
+	err := row.Scan(&has_access)
+	return has_access, err
+}
+
 const checkUserHasRole = `-- name: CheckUserHasRole :one
 SELECT EXISTS(
     SELECT 1 FROM user_roles 
@@ -162,6 +187,100 @@ func (q *Queries) DeleteUser(ctx context.Context, id int32) error {
 	return err
 }
 
+const filterUsers = `-- name: FilterUsers :many
+SELECT
+    u.id, u.organization_id, u.username, u.email, u.first_name, u.last_name,
+    u.employee_code, u.is_active, u.metadata, u.created_at, u.updated_at,
+    COUNT(*) OVER () AS total_count
+FROM users u
+WHERE u.organization_id = $1
+  AND ($2::text IS NULL
+    OR u.username ILIKE '%' || $2 || '%'
+    OR u.email ILIKE '%' || $2 || '%'
+    OR u.first_name ILIKE '%' || $2 || '%'
+    OR u.last_name ILIKE '%' || $2 || '%'
+    OR u.employee_code ILIKE '%' || $2 || '%')
+  AND ($3::boolean IS NULL OR u.is_active = $3)
+  AND ($4::int IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles ur
+    WHERE ur.user_id = u.id AND ur.role_id = $4
+  ))
+  AND ($5::int IS NULL OR EXISTS (
+    SELECT 1 FROM user_store_access usa
+    WHERE usa.user_id = u.id AND usa.store_id = $5
+  ))
+ORDER BY u.first_name, u.last_name, u.id
+LIMIT $6 OFFSET $7
+`
+
+type FilterUsersParams struct {
+	OrganizationID int32       `json:"organization_id"`
+	Search         pgtype.Text `json:"search"`
+	IsActive       pgtype.Bool `json:"is_active"`
+	RoleID         pgtype.Int4 `json:"role_id"`
+	StoreID        pgtype.Int4 `json:"store_id"`
+	Limit          int32       `json:"limit"`
+	Offset         int32       `json:"offset"`
+}
+
+type FilterUsersRow struct {
+	ID             int32            `json:"id"`
+	OrganizationID int32            `json:"organization_id"`
+	Username       string           `json:"username"`
+	Email          string           `json:"email"`
+	FirstName      pgtype.Text      `json:"first_name"`
+	LastName       pgtype.Text      `json:"last_name"`
+	EmployeeCode   pgtype.Text      `json:"employee_code"`
+	IsActive       pgtype.Bool      `json:"is_active"`
+	Metadata       []byte           `json:"metadata"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+	TotalCount     int64            `json:"total_count"`
+}
+
+// Users of an organization matching the optional filters, with the number of
+// matches for paging. Password hashes are left out.
+func (q *Queries) FilterUsers(ctx context.Context, arg FilterUsersParams) ([]FilterUsersRow, error) {
+	rows, err := q.db.Query(ctx, filterUsers,
+		arg.OrganizationID,
+		arg.Search,
+		arg.IsActive,
+		arg.RoleID,
+		arg.StoreID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []FilterUsersRow
+	for rows.Next() {
+		var i FilterUsersRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.OrganizationID,
+			&i.Username,
+			&i.Email,
+			&i.FirstName,
+			&i.LastName,
+			&i.EmployeeCode,
+			&i.IsActive,
+			&i.Metadata,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.TotalCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getStoreUsers = `-- name: GetStoreUsers :many
 SELECT u.id, u.organization_id, u.username, u.email, u.password_hash, u.first_name, u.last_name, u.employee_code, u.is_active, u.metadata, u.created_at, u.updated_at FROM users u
 INNER JOIN user_store_access usa ON u.id = usa.user_id
@@ -557,6 +676,86 @@ func (q *Queries) GrantStoreAccessToUser(ctx context.Context, arg GrantStoreAcce
 	return i, err
 }
 
+const listUserStoreAccess = `-- name: ListUserStoreAccess :many
+SELECT usa.id, usa.user_id, usa.store_id, usa.is_primary, usa.metadata, usa.granted_at,
+    s.organization_id, s.name AS store_name, s.code AS store_code, s.is_active AS store_is_active
+FROM user_store_access usa
+INNER JOIN stores s ON s.id = usa.store_id
+WHERE usa.user_id = $1
+ORDER BY usa.is_primary DESC, s.name
+`
+
+type ListUserStoreAccessRow struct {
+	ID             int32            `json:"id"`
+	UserID         int32            `json:"user_id"`
+	StoreID        int32            `json:"store_id"`
+	IsPrimary      pgtype.Bool      `json:"is_primary"`
+	Metadata       []byte           `json:"metadata"`
+	GrantedAt      pgtype.Timestamp `json:"granted_at"`
+	OrganizationID int32            `json:"organization_id"`
+	StoreName      string           `json:"store_name"`
+	StoreCode      string           `json:"store_code"`
+	StoreIsActive  pgtype.Bool      `json:"store_is_active"`
+}
+
+// The store access of a user with the stores' names, primary first.
+func (q *Queries) ListUserStoreAccess(ctx context.Context, userID int32) ([]ListUserStoreAccessRow, error) {
+	rows, err := q.db.Query(ctx, listUserStoreAccess, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ListUserStoreAccessRow
+	for rows.Next() {
+		var i ListUserStoreAccessRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.StoreID,
+			&i.IsPrimary,
+			&i.Metadata,
+			&i.GrantedAt,
+			&i.OrganizationID,
+			&i.StoreName,
+			&i.StoreCode,
+			&i.StoreIsActive,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listUserStoreIDs = `-- name: ListUserStoreIDs :many
+SELECT store_id FROM user_store_access
+WHERE user_id = $1
+ORDER BY store_id
+`
+
+func (q *Queries) ListUserStoreIDs(ctx context.Context, userID int32) ([]int32, error) {
+	rows, err := q.db.Query(ctx, listUserStoreIDs, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []int32
+	for rows.Next() {
+		var store_id int32
+		if err := rows.Scan(&store_id); err != nil {
+			return nil, err
+		}
+		items = append(items, store_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listUsers = `-- name: ListUsers :many
 SELECT id, organization_id, username, email, password_hash, first_name, last_name, employee_code, is_active, metadata, created_at, updated_at FROM users
 WHERE organization_id = $1
@@ -0,0 +1,55 @@
+package router
+
+import (
+	"NEMBUS/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterCatalogRoutes registers product, variant, barcode and unit
+// conversion routes under /api/products, category tree routes under
+// /api/categories, and catalog import, export, barcode generation and label
+// routes under /api/catalog.
+func RegisterCatalogRoutes(r *gin.RouterGroup, h *handler.CatalogHandler) {
+	products := r.Group("/products")
+	{
+		products.POST("", h.CreateProduct)
+		products.GET("", h.ListProducts)
+		products.GET("/:id", h.GetProduct)
+		products.PATCH("/:id", h.UpdateProduct)
+		products.DELETE("/:id", h.DeleteProduct)
+
+		products.POST("/:id/variants", h.GenerateVariants)
+		products.PATCH("/:id/variants/:variant_id", h.UpdateVariant)
+		products.DELETE("/:id/variants/:variant_id", h.DeleteVariant)
+
+		products.POST("/:id/barcodes", h.AddBarcode)
+		products.PUT("/:id/barcodes/:barcode_id/primary", h.SetPrimaryBarcode)
+		products.DELETE("/:id/barcodes/:barcode_id", h.DeleteBarcode)
+
+		products.POST("/:id/uom-conversions", h.AddUomConversion)
+		products.PATCH("/:id/uom-conversions/:conversion_id", h.UpdateUomConversion)
+		products.DELETE("/:id/uom-conversions/:conversion_id", h.DeleteUomConversion)
+	}
+
+	categories := r.Group("/categories")
+	{
+		categories.GET("/tree", h.GetCategoryTree)
+		categories.POST("", h.CreateCategory)
+		categories.GET("/:id", h.GetCategory)
+		categories.PATCH("/:id", h.UpdateCategory)
+		categories.PUT("/:id/parent", h.MoveCategory)
+		categories.DELETE("/:id", h.DeleteCategory)
+	}
+
+	catalog := r.Group("/catalog")
+	{
+		catalog.POST("/imports", h.StartImport)
+		catalog.GET("/imports", h.ListImportJobs)
+		catalog.GET("/imports/:id", h.GetImportJob)
+		catalog.GET("/export", h.ExportCatalog)
+
+		catalog.POST("/barcodes/generate", h.GenerateBarcodes)
+		catalog.POST("/labels", h.RenderLabels)
+	}
+}
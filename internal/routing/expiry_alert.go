@@ -0,0 +1,23 @@
+package router
+
+import (
+	"NEMBUS/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterExpiryAlertRoutes registers the store alerts inbox and the expiry
+// rule and alert routes under /api/inventory.
+func RegisterExpiryAlertRoutes(r *gin.RouterGroup, h *handler.ExpiryAlertHandler) {
+	// GET /api/stores/:id/alerts
+	r.GET("/stores/:id/alerts", h.ListStoreAlerts)
+
+	inventory := r.Group("/inventory")
+	{
+		inventory.PATCH("/alerts/:id", h.UpdateAlertStatus)
+		inventory.POST("/expiry-check", h.RunExpiryCheck)
+		inventory.GET("/expiry-rules", h.ListExpiryRules)
+		inventory.PUT("/expiry-rules", h.SetExpiryRule)
+		inventory.DELETE("/expiry-rules/:category_id", h.DeleteExpiryRule)
+	}
+}
@@ -0,0 +1,25 @@
+package router
+
+import (
+	"NEMBUS/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterInventoryRoutes registers inventory routes under /api/inventory.
+func RegisterInventoryRoutes(r *gin.RouterGroup, h *handler.InventoryHandler) {
+	inventory := r.Group("/inventory")
+	{
+		// POST /api/inventory/transfers
+		inventory.POST("/transfers", h.TransferStock)
+	}
+}
+
+// RegisterSerialRoutes registers serial number routes under /api/serials.
+func RegisterSerialRoutes(r *gin.RouterGroup, h *handler.InventoryHandler) {
+	serials := r.Group("/serials")
+	{
+		// GET /api/serials/:serial
+		serials.GET("/:serial", h.TraceSerialNumber)
+	}
+}
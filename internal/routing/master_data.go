@@ -0,0 +1,39 @@
+package router
+
+import (
+	"NEMBUS/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterMasterDataRoutes registers the brand, unit of measure and tax
+// category admin routes.
+func RegisterMasterDataRoutes(r *gin.RouterGroup, h *handler.MasterDataHandler) {
+	brands := r.Group("/brands")
+	{
+		brands.GET("", h.ListBrands)
+		brands.POST("", h.CreateBrand)
+		brands.GET("/:id", h.GetBrand)
+		brands.PATCH("/:id", h.UpdateBrand)
+		brands.DELETE("/:id", h.DeleteBrand)
+	}
+
+	units := r.Group("/units-of-measure")
+	{
+		units.GET("", h.ListUnits)
+		units.POST("", h.CreateUnit)
+		units.GET("/:id", h.GetUnit)
+		units.PATCH("/:id", h.UpdateUnit)
+		units.DELETE("/:id", h.DeleteUnit)
+	}
+
+	taxes := r.Group("/tax-categories")
+	{
+		taxes.GET("", h.ListTaxCategories)
+		taxes.POST("", h.CreateTaxCategory)
+		taxes.GET("/history", h.ListTaxCategoryChanges)
+		taxes.GET("/:id", h.GetTaxCategory)
+		taxes.PATCH("/:id", h.UpdateTaxCategory)
+		taxes.DELETE("/:id", h.DeleteTaxCategory)
+	}
+}
@@ -0,0 +1,19 @@
+package router
+
+import (
+	"NEMBUS/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+func RegisterMeRoutes(r *gin.RouterGroup, h *handler.MeHandler) {
+	me := r.Group("/me")
+	{
+		me.GET("", h.GetProfile)
+		me.GET("/navigation", h.GetNavigation)
+		me.GET("/permissions", h.GetPermissions)
+		me.GET("/permissions/submenu/:submenu_code", h.CheckSubmenuPermission)
+		me.GET("/routes", h.GetRoutes)
+		me.POST("/password", h.ChangePassword)
+	}
+}
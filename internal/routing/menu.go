@@ -14,6 +14,8 @@ func RegisterMenuRoutes(r *gin.RouterGroup, h *handler.MenuHandler) {
 		menu.GET("/:id", h.GetMenu)
 		menu.GET("/module/:moduleId", h.ListMenusByModule)
 		menu.GET("/parent/:parentId", h.ListMenusByParent)
+		menu.POST("/reorder", h.ReorderMenus)
+		menu.POST("/:id/move", h.MoveMenu)
 		menu.PATCH("/:id/toggle-active", h.ToggleMenuActive)
 		menu.PUT("/:id", h.UpdateMenu)
 		menu.DELETE("/:id", h.DeleteMenu)
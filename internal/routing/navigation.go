@@ -11,5 +11,8 @@ func RegisterNavigationRoutes(r *gin.RouterGroup, h *handler.NavigationHandler)
 	{
 		navigation.GET("/user/:user_id", h.GetUserNavigation)
 		navigation.GET("/rolesWithUserCounts/:role_code", h.GetNavigationByRoleCodeWithUserCounts)
+		navigation.GET("/cache/stats", h.GetNavigationCacheStats)
+		navigation.GET("/bundle", h.ExportNavigationBundle)
+		navigation.POST("/bundle", h.ApplyNavigationBundle)
 	}
 }
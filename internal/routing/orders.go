@@ -0,0 +1,31 @@
+package router
+
+import (
+	"NEMBUS/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterSalesOrderRoutes registers sales order routes under /api/sales-orders.
+func RegisterSalesOrderRoutes(r *gin.RouterGroup, h *handler.SalesOrderHandler) {
+	orders := r.Group("/sales-orders")
+	{
+		// POST /api/sales-orders
+		orders.POST("", h.CreateSalesOrder)
+		// GET /api/sales-orders/:id
+		orders.GET("/:id", h.GetSalesOrder)
+	}
+}
+
+// RegisterPurchaseOrderRoutes registers purchase order routes under /api/purchase-orders.
+func RegisterPurchaseOrderRoutes(r *gin.RouterGroup, h *handler.PurchaseOrderHandler) {
+	orders := r.Group("/purchase-orders")
+	{
+		// POST /api/purchase-orders
+		orders.POST("", h.CreatePurchaseOrder)
+		// GET /api/purchase-orders/:id
+		orders.GET("/:id", h.GetPurchaseOrder)
+		// POST /api/purchase-orders/:id/receive
+		orders.POST("/:id/receive", h.ReceiveGoods)
+	}
+}
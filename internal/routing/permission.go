@@ -10,5 +10,21 @@ func RegisterPermissionRoutes(r *gin.RouterGroup, h *handler.PermissionHandler)
 	permissions := r.Group("/permissions")
 	{
 		permissions.GET("/user/:user_id/submenu/:submenu_code", h.CheckUserSubmenuPermission)
+
+		// CRUD
+		permissions.POST("", h.CreatePermission)
+		permissions.GET("", h.ListPermissions)
+		permissions.GET("/:id", h.GetPermission)
+		permissions.PUT("/:id", h.UpdatePermission)
+		permissions.DELETE("/:id", h.DeletePermission)
+
+		// Role x permission matrix
+		permissions.GET("/matrix", h.GetPermissionMatrix)
+		permissions.PATCH("/matrix", h.UpdatePermissionMatrix)
+
+		// Permissions required by modules, menus and submenus
+		permissions.GET("/nodes/:kind/:node_id", h.ListNodePermissions)
+		permissions.POST("/nodes/:kind/:node_id", h.AttachNodePermissions)
+		permissions.DELETE("/nodes/:kind/:node_id", h.DetachNodePermissions)
 	}
 }
@@ -14,6 +14,24 @@ func RegisterPosRoutes(r *gin.RouterGroup, h *handler.PosHandler) {
 
 	// POST /api/pos/products
 	pos.POST("/products", h.AddProduct)
+
+	// POST /api/pos/checkout - complete a sale
+	pos.POST("/checkout", h.Checkout)
+
+	// POST /api/pos/returns - return items of a completed sale
+	pos.POST("/returns", h.ReturnSale)
+
+	// GET /api/pos/transactions/:number/receipt?format=text|escpos|pdf
+	pos.GET("/transactions/:number/receipt", h.GetReceipt)
+
+	// Receipt templates
+	pos.GET("/receipt-templates", h.ListReceiptTemplates)
+	pos.PUT("/receipt-templates/:code", h.SaveReceiptTemplate)
+
+	// Variable-measure barcode rules
+	pos.GET("/barcode-rules", h.ListBarcodeRules)
+	pos.PUT("/barcode-rules/:prefix", h.SaveBarcodeRule)
+	pos.DELETE("/barcode-rules/:prefix", h.DeleteBarcodeRule)
 	// ------------------------------------
 	// Store-specific routes
 	// ------------------------------------
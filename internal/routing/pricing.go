@@ -0,0 +1,66 @@
+package router
+
+import (
+	"NEMBUS/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterPricingRoutes registers pricing routes under /api/pricing.
+func RegisterPricingRoutes(r *gin.RouterGroup, h *handler.PricingHandler) {
+	pricing := r.Group("/pricing")
+	{
+		// GET /api/pricing/resolve
+		pricing.GET("/resolve", h.ResolvePrice)
+		// GET /api/pricing/assignments
+		pricing.GET("/assignments", h.ListPriceListAssignments)
+		// POST /api/pricing/assignments
+		pricing.POST("/assignments", h.CreatePriceListAssignment)
+		// DELETE /api/pricing/assignments/:id
+		pricing.DELETE("/assignments/:id", h.DeletePriceListAssignment)
+	}
+}
+
+// RegisterPriceListRoutes registers price list routes under /api/price-lists,
+// single price routes under /api/product-prices and the product price
+// comparison.
+func RegisterPriceListRoutes(r *gin.RouterGroup, h *handler.PriceListHandler) {
+	lists := r.Group("/price-lists")
+	{
+		// GET /api/price-lists
+		lists.GET("", h.ListPriceLists)
+		// POST /api/price-lists
+		lists.POST("", h.CreatePriceList)
+		// GET /api/price-lists/:id
+		lists.GET("/:id", h.GetPriceList)
+		// PUT /api/price-lists/:id
+		lists.PUT("/:id", h.UpdatePriceList)
+		// DELETE /api/price-lists/:id
+		lists.DELETE("/:id", h.DeletePriceList)
+		// PUT /api/price-lists/:id/default
+		lists.PUT("/:id/default", h.SetDefaultPriceList)
+		// POST /api/price-lists/:id/expire
+		lists.POST("/:id/expire", h.ExpirePriceList)
+		// GET /api/price-lists/:id/prices
+		lists.GET("/:id/prices", h.ListPrices)
+		// POST /api/price-lists/:id/prices
+		lists.POST("/:id/prices", h.CreateProductPrice)
+		// POST /api/price-lists/:id/prices/schedule
+		lists.POST("/:id/prices/schedule", h.SchedulePriceChange)
+		// POST /api/price-lists/:id/prices/adjust
+		lists.POST("/:id/prices/adjust", h.AdjustPrices)
+		// POST /api/price-lists/:id/prices/import
+		lists.POST("/:id/prices/import", h.ImportPrices)
+	}
+
+	prices := r.Group("/product-prices")
+	{
+		// PUT /api/product-prices/:id
+		prices.PUT("/:id", h.UpdateProductPrice)
+		// DELETE /api/product-prices/:id
+		prices.DELETE("/:id", h.DeleteProductPrice)
+	}
+
+	// GET /api/products/:id/price-comparison
+	r.GET("/products/:id/price-comparison", h.GetPriceComparison)
+}
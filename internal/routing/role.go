@@ -22,6 +22,12 @@ func RegisterRoleRoutes(r *gin.RouterGroup, h *handler.RoleHandler) {
 		roles.GET("/non-system", h.ListNonSystemRoles)
 		roles.PATCH("/:id/active", h.ToggleRoleActive)
 
+		// Templates, cloning and comparison
+		roles.GET("/templates", h.ListRoleTemplates)
+		roles.POST("/templates/:code", h.CreateRoleFromTemplate)
+		roles.POST("/:id/clone", h.CloneRole)
+		roles.GET("/:id/diff/:other_id", h.DiffRoles)
+
 		// Role permissions
 		roles.POST("/:id/permissions", h.AssignPermissionToRole)
 		roles.GET("/:id/permissions", h.GetRolePermissions)
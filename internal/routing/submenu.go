@@ -16,6 +16,8 @@ func RegisterSubmenuRoutes(r *gin.RouterGroup, h *handler.SubmenuHandler) {
 		submenu.GET("/active/:menu_id", h.ListActiveSubmenusByMenu) // List active submenus by menu
 		submenu.GET("/parent/:parent_id", h.ListSubmenusByParent)   // List submenus by parent submenu
 		submenu.GET("/by-code", h.GetSubmenuByCode)                 // Get submenu by code (menu_id + code query)
+		submenu.POST("/reorder", h.ReorderSubmenus)                 // Reorder submenus under a parent
+		submenu.POST("/:id/move", h.MoveSubmenu)                    // Move submenu to another menu or parent
 		submenu.PATCH("/:id/toggle", h.ToggleSubmenuActive)         // Toggle submenu active status
 		submenu.PUT("/:id", h.UpdateSubmenu)                        // Update submenu by ID
 		submenu.DELETE("/:id", h.DeleteSubmenu)                     // Delete submenu by ID
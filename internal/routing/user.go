@@ -12,8 +12,21 @@ func RegisterUserRoutes(r *gin.RouterGroup, h *handler.UserHandler) {
 		user.POST("", h.CreateUser)
 		user.GET("/:id", h.GetUser)
 		user.GET("", h.ListUsers)
+		user.GET("/search", h.SearchUsers)
+		user.GET("/:id/details", h.GetUserDetail)
+		user.PATCH("/:id", h.UpdateUser)
+		user.PATCH("/:id/active", h.SetUserActive)
+		user.POST("/:id/reset-password", h.ResetUserPassword)
+		user.GET("/:id/logins", h.ListUserLoginAudit)
 
 		// 🔑 User Roles
 		user.POST("addUserRoles/:id", h.AssignRoleToUser)
+		user.DELETE("/:id/roles/:role_id", h.RevokeRoleFromUser)
+
+		// Store access; the primary store is selected at login
+		user.GET("/:id/stores", h.ListUserStores)
+		user.POST("/:id/stores", h.GrantStoreAccess)
+		user.PUT("/:id/stores/:store_id/primary", h.SetPrimaryStore)
+		user.DELETE("/:id/stores/:store_id", h.RevokeStoreAccess)
 	}
 }
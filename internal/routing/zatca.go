@@ -0,0 +1,20 @@
+package router
+
+import (
+	"NEMBUS/internal/handler"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RegisterZatcaRoutes registers ZATCA e-invoicing routes under /api/zatca.
+func RegisterZatcaRoutes(r *gin.RouterGroup, h *handler.ZatcaHandler) {
+	zatca := r.Group("/zatca")
+	{
+		// POST /api/zatca/transactions/:number/invoice - issue (idempotent)
+		zatca.POST("/transactions/:number/invoice", h.IssueInvoice)
+		// GET /api/zatca/transactions/:number/invoice
+		zatca.GET("/transactions/:number/invoice", h.GetInvoice)
+		// GET /api/zatca/transactions/:number/invoice/xml
+		zatca.GET("/transactions/:number/invoice/xml", h.GetInvoiceXML)
+	}
+}
@@ -0,0 +1,281 @@
+// Package spreadsheet reads tabular uploads (CSV and XLSX) into rows of
+// strings. Only the first worksheet of a workbook is read; cell values are
+// returned as stored, so numbers and dates in XLSX files come back in their
+// raw form (dates as serial day numbers, see ParseDate).
+package spreadsheet
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrUnsupportedFormat is returned for files that are neither CSV nor XLSX.
+var ErrUnsupportedFormat = errors.New("unsupported file format (use .csv or .xlsx)")
+
+// Read reads all rows of a CSV or XLSX file, chosen by the file name's
+// extension. Trailing empty rows are dropped.
+func Read(name string, r io.Reader) ([][]string, error) {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".csv":
+		return ReadCSV(r)
+	case ".xlsx":
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return ReadXLSX(data)
+	}
+	return nil, ErrUnsupportedFormat
+}
+
+// ReadCSV reads a comma-separated file. A UTF-8 byte order mark is ignored
+// and rows may have different lengths.
+func ReadCSV(r io.Reader) ([][]string, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	cr := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+	rows, err := cr.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return trimRows(rows), nil
+}
+
+type xlsxWorkbook struct {
+	Sheets []struct {
+		RID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+	} `xml:"sheets>sheet"`
+}
+
+type xlsxRelationships struct {
+	Rels []struct {
+		ID     string `xml:"Id,attr"`
+		Target string `xml:"Target,attr"`
+	} `xml:"Relationship"`
+}
+
+type xlsxSharedStrings struct {
+	Items []xlsxRichText `xml:"si"`
+}
+
+type xlsxRichText struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (t xlsxRichText) String() string {
+	if len(t.Runs) == 0 {
+		return t.T
+	}
+	var b strings.Builder
+	for _, r := range t.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+type xlsxSheet struct {
+	Rows []struct {
+		R     int `xml:"r,attr"`
+		Cells []struct {
+			Ref    string       `xml:"r,attr"`
+			Type   string       `xml:"t,attr"`
+			Value  string       `xml:"v"`
+			Inline xlsxRichText `xml:"is"`
+		} `xml:"c"`
+	} `xml:"sheetData>row"`
+}
+
+// ReadXLSX reads the first worksheet of an Office Open XML workbook.
+func ReadXLSX(data []byte) ([][]string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("read xlsx: %w", err)
+	}
+	files := map[string]*zip.File{}
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	var shared xlsxSharedStrings
+	if f, ok := files["xl/sharedStrings.xml"]; ok {
+		if err := decodeXML(f, &shared); err != nil {
+			return nil, err
+		}
+	}
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("read xlsx: worksheet %s not found", sheetPath)
+	}
+	var sheet xlsxSheet
+	if err := decodeXML(f, &sheet); err != nil {
+		return nil, err
+	}
+
+	var rows [][]string
+	for _, row := range sheet.Rows {
+		// Rows and cells may be sparse; their references place them.
+		for row.R > len(rows)+1 {
+			rows = append(rows, nil)
+		}
+		var cells []string
+		for i, c := range row.Cells {
+			col := i
+			if c.Ref != "" {
+				if n, ok := columnIndex(c.Ref); ok {
+					col = n
+				}
+			}
+			for len(cells) < col {
+				cells = append(cells, "")
+			}
+			v := c.Value
+			switch c.Type {
+			case "s":
+				idx, err := strconv.Atoi(v)
+				if err != nil || idx < 0 || idx >= len(shared.Items) {
+					return nil, fmt.Errorf("read xlsx: bad shared string index %q in %s", v, c.Ref)
+				}
+				v = shared.Items[idx].String()
+			case "inlineStr":
+				v = c.Inline.String()
+			case "b":
+				if v == "1" {
+					v = "TRUE"
+				} else {
+					v = "FALSE"
+				}
+			}
+			cells = append(cells, v)
+		}
+		rows = append(rows, cells)
+	}
+	return trimRows(rows), nil
+}
+
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+	wbFile, ok := files["xl/workbook.xml"]
+	if !ok {
+		return "", errors.New("read xlsx: not a workbook")
+	}
+	var wb xlsxWorkbook
+	if err := decodeXML(wbFile, &wb); err != nil {
+		return "", err
+	}
+	relFile, ok := files["xl/_rels/workbook.xml.rels"]
+	if !ok || len(wb.Sheets) == 0 {
+		return fallback, nil
+	}
+	var rels xlsxRelationships
+	if err := decodeXML(relFile, &rels); err != nil {
+		return "", err
+	}
+	for _, r := range rels.Rels {
+		if r.ID == wb.Sheets[0].RID {
+			if strings.HasPrefix(r.Target, "/") {
+				return strings.TrimPrefix(r.Target, "/"), nil
+			}
+			return path.Join("xl", r.Target), nil
+		}
+	}
+	return fallback, nil
+}
+
+func decodeXML(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("read xlsx: %w", err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("read xlsx %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// columnIndex returns the zero-based column of a cell reference such as "C7".
+func columnIndex(ref string) (int, bool) {
+	n := 0
+	i := 0
+	for ; i < len(ref) && ref[i] >= 'A' && ref[i] <= 'Z'; i++ {
+		n = n*26 + int(ref[i]-'A'+1)
+	}
+	if i == 0 {
+		return 0, false
+	}
+	return n - 1, true
+}
+
+func trimRows(rows [][]string) [][]string {
+	for len(rows) > 0 && IsBlank(rows[len(rows)-1]) {
+		rows = rows[:len(rows)-1]
+	}
+	return rows
+}
+
+// IsBlank reports whether every cell of row is empty.
+func IsBlank(row []string) bool {
+	for _, c := range row {
+		if strings.TrimSpace(c) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseDate parses a date cell: YYYY-MM-DD, or a spreadsheet serial day
+// number as stored by XLSX for date-formatted cells.
+func ParseDate(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if t, err := time.Parse("2006-01-02", s); err == nil {
+		return t, nil
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f > 0 && f < 2958466 {
+		return time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(f)), nil
+	}
+	return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD)", s)
+}
+
+// Header maps lower-cased, trimmed column names of a header row to their
+// index.
+func Header(row []string) map[string]int {
+	h := make(map[string]int, len(row))
+	for i, name := range row {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if _, dup := h[name]; name != "" && !dup {
+			h[name] = i
+		}
+	}
+	return h
+}
+
+// Cell returns the trimmed cell of row in the named column, or "" when the
+// column is missing.
+func Cell(row []string, header map[string]int, name string) string {
+	i, ok := header[name]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
@@ -0,0 +1,313 @@
+// Package tax computes document taxes for POS sales, sales orders and
+// purchase orders. All arithmetic is exact (math/big.Rat); amounts are only
+// rounded where the rounding mode requires it, half away from zero.
+//
+// A line's amount is quantity x unit price - discount, in the price basis of
+// its rate: tax-inclusive rates carry the tax inside the amount, exclusive
+// rates add it on top.
+package tax
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+)
+
+// Rounding selects where tax amounts are rounded to the currency scale.
+type Rounding string
+
+const (
+	// RoundPerLine rounds each line's tax; document totals are the sum of
+	// the rounded line amounts.
+	RoundPerLine Rounding = "line"
+	// RoundPerInvoice sums unrounded tax per rate and rounds once per rate.
+	// The rounded rate total is then spread back over the lines so that the
+	// lines still add up to the document.
+	RoundPerInvoice Rounding = "invoice"
+)
+
+// ParseRounding validates a rounding mode name, defaulting to per line.
+func ParseRounding(s string) (Rounding, error) {
+	switch Rounding(s) {
+	case "", RoundPerLine:
+		return RoundPerLine, nil
+	case RoundPerInvoice:
+		return RoundPerInvoice, nil
+	}
+	return "", fmt.Errorf("invalid tax rounding %q (use line or invoice)", s)
+}
+
+// Rate is a tax category applied to a line.
+type Rate struct {
+	Code      string
+	Name      string
+	Percent   *big.Rat // e.g. 15 for 15%
+	Inclusive bool
+}
+
+// Line is one document line to be taxed.
+type Line struct {
+	Quantity  *big.Rat
+	UnitPrice *big.Rat
+	Discount  *big.Rat // amount, in the same basis as UnitPrice; may be nil
+	Rate      Rate
+	// Exempt zero-rates this line only (e.g. an exempt product).
+	Exempt bool
+}
+
+// Options control a calculation.
+type Options struct {
+	Rounding Rounding
+	// Scale is the number of decimal places of the currency (default 2).
+	Scale int
+	// Exempt zero-rates the whole document, e.g. for a tax-exempt customer.
+	// Inclusive prices are reduced to their net amount.
+	Exempt bool
+}
+
+// LineResult is the computed tax of one line. Net excludes tax; LineTotal is
+// the amount in the line's price basis (Net+Tax for inclusive rates, Net for
+// exclusive ones), which is what is persisted as line_total.
+type LineResult struct {
+	Gross     *big.Rat // quantity x unit price
+	Discount  *big.Rat
+	Net       *big.Rat
+	Tax       *big.Rat
+	Total     *big.Rat // Net + Tax
+	LineTotal *big.Rat
+	Rate      Rate
+	Exempt    bool
+}
+
+// RateTotal is the per-rate tax breakdown of a document.
+type RateTotal struct {
+	Code      string
+	Name      string
+	Percent   *big.Rat
+	Inclusive bool
+	Exempt    bool
+	Taxable   *big.Rat
+	Tax       *big.Rat
+}
+
+// Result is the taxed document.
+type Result struct {
+	Lines     []LineResult
+	Breakdown []RateTotal
+	Subtotal  *big.Rat // sum of net amounts
+	Discount  *big.Rat
+	Tax       *big.Rat
+	Total     *big.Rat // Subtotal + Tax
+}
+
+// ErrNegativeAmount is returned when a discount exceeds its line amount.
+var ErrNegativeAmount = errors.New("tax: line discount exceeds line amount")
+
+// Calculate taxes lines according to opt.
+func Calculate(lines []Line, opt Options) (*Result, error) {
+	if opt.Rounding == "" {
+		opt.Rounding = RoundPerLine
+	}
+	if opt.Scale <= 0 {
+		opt.Scale = 2
+	}
+
+	res := &Result{
+		Lines:    make([]LineResult, len(lines)),
+		Subtotal: new(big.Rat),
+		Discount: new(big.Rat),
+		Tax:      new(big.Rat),
+		Total:    new(big.Rat),
+	}
+	// Unrounded tax per line, used for invoice-level rounding.
+	exact := make([]*big.Rat, len(lines))
+
+	for i, l := range lines {
+		if l.Quantity == nil || l.UnitPrice == nil {
+			return nil, fmt.Errorf("tax: line %d: quantity and unit price are required", i+1)
+		}
+		percent := l.Rate.Percent
+		if percent == nil {
+			percent = new(big.Rat)
+		}
+		if percent.Sign() < 0 {
+			return nil, fmt.Errorf("tax: line %d: negative tax rate", i+1)
+		}
+		gross := new(big.Rat).Mul(l.Quantity, l.UnitPrice)
+		discount := new(big.Rat)
+		if l.Discount != nil {
+			discount.Set(l.Discount)
+		}
+		amount := new(big.Rat).Sub(gross, discount)
+		if amount.Sign() < 0 && gross.Sign() >= 0 {
+			return nil, fmt.Errorf("line %d: %w", i+1, ErrNegativeAmount)
+		}
+
+		rate := new(big.Rat).Quo(percent, big.NewRat(100, 1))
+		exempt := opt.Exempt || l.Exempt
+		lr := LineResult{Gross: gross, Discount: discount, Rate: l.Rate, Exempt: exempt}
+
+		var taxExact *big.Rat
+		switch {
+		case exempt && l.Rate.Inclusive:
+			// The customer pays the price without the tax it contains.
+			lr.Net = Round(new(big.Rat).Quo(amount, new(big.Rat).Add(big.NewRat(1, 1), rate)), opt.Scale)
+			taxExact = new(big.Rat)
+		case exempt:
+			lr.Net = Round(amount, opt.Scale)
+			taxExact = new(big.Rat)
+		case l.Rate.Inclusive:
+			// tax = amount * r / (1 + r)
+			taxExact = new(big.Rat).Quo(new(big.Rat).Mul(amount, rate), new(big.Rat).Add(big.NewRat(1, 1), rate))
+		default:
+			lr.Net = Round(amount, opt.Scale)
+			taxExact = new(big.Rat).Mul(lr.Net, rate)
+		}
+		exact[i] = taxExact
+		lr.Tax = Round(taxExact, opt.Scale)
+		if lr.Net == nil {
+			// Inclusive: the amount is fixed, the rounded tax comes out of it.
+			lr.Net = new(big.Rat).Sub(Round(amount, opt.Scale), lr.Tax)
+		}
+		res.Lines[i] = lr
+	}
+
+	if opt.Rounding == RoundPerInvoice {
+		spreadInvoiceRounding(res.Lines, exact, opt.Scale)
+	}
+
+	groups := map[string]*RateTotal{}
+	var order []string
+	for i := range res.Lines {
+		lr := &res.Lines[i]
+		lr.Total = new(big.Rat).Add(lr.Net, lr.Tax)
+		if lr.Rate.Inclusive && !lr.Exempt {
+			lr.LineTotal = new(big.Rat).Set(lr.Total)
+		} else {
+			lr.LineTotal = new(big.Rat).Set(lr.Net)
+		}
+
+		res.Subtotal.Add(res.Subtotal, lr.Net)
+		res.Discount.Add(res.Discount, lr.Discount)
+		res.Tax.Add(res.Tax, lr.Tax)
+
+		key := groupKey(lr.Rate, lr.Exempt)
+		g, ok := groups[key]
+		if !ok {
+			g = &RateTotal{
+				Code:      lr.Rate.Code,
+				Name:      lr.Rate.Name,
+				Percent:   ratOrZero(lr.Rate.Percent),
+				Inclusive: lr.Rate.Inclusive,
+				Exempt:    lr.Exempt,
+				Taxable:   new(big.Rat),
+				Tax:       new(big.Rat),
+			}
+			groups[key] = g
+			order = append(order, key)
+		}
+		g.Taxable.Add(g.Taxable, lr.Net)
+		g.Tax.Add(g.Tax, lr.Tax)
+	}
+	res.Total.Add(res.Subtotal, res.Tax)
+
+	sort.SliceStable(order, func(i, j int) bool {
+		return groups[order[i]].Percent.Cmp(groups[order[j]].Percent) > 0
+	})
+	for _, key := range order {
+		res.Breakdown = append(res.Breakdown, *groups[key])
+	}
+	return res, nil
+}
+
+// spreadInvoiceRounding rounds tax once per rate group and distributes the
+// rounded group total over its lines by largest remainder, so the lines add
+// up exactly to the group total. Inclusive lines keep their amount fixed and
+// absorb the adjustment in Net.
+func spreadInvoiceRounding(lines []LineResult, exact []*big.Rat, scale int) {
+	unit := new(big.Rat).SetFrac(big.NewInt(1), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil))
+
+	members := map[string][]int{}
+	var keys []string
+	for i, lr := range lines {
+		key := groupKey(lr.Rate, lr.Exempt)
+		if _, ok := members[key]; !ok {
+			keys = append(keys, key)
+		}
+		members[key] = append(members[key], i)
+	}
+
+	for _, key := range keys {
+		idx := members[key]
+		total := new(big.Rat)
+		for _, i := range idx {
+			total.Add(total, exact[i])
+		}
+		target := Round(total, scale)
+
+		// Start from truncated line taxes, then hand out the remaining units
+		// to the lines with the largest truncated remainder.
+		allocated := new(big.Rat)
+		type rem struct {
+			i int
+			r *big.Rat
+		}
+		rems := make([]rem, 0, len(idx))
+		for _, i := range idx {
+			t := truncate(exact[i], scale)
+			rems = append(rems, rem{i, new(big.Rat).Sub(exact[i], t)})
+			allocated.Add(allocated, t)
+			setTax(&lines[i], t)
+		}
+		diff := new(big.Rat).Sub(target, allocated)
+		step := new(big.Rat).Set(unit)
+		if diff.Sign() < 0 {
+			step.Neg(step)
+		}
+		// Largest remainder in the direction of the adjustment: for return
+		// lines the remainders are negative and the most negative goes first.
+		if sign := diff.Sign(); sign != 0 {
+			sort.SliceStable(rems, func(a, b int) bool { return rems[a].r.Cmp(rems[b].r) == sign })
+		}
+		for k := 0; diff.Sign() != 0 && len(rems) > 0; k = (k + 1) % len(rems) {
+			i := rems[k].i
+			setTax(&lines[i], new(big.Rat).Add(lines[i].Tax, step))
+			diff.Sub(diff, step)
+		}
+	}
+}
+
+// setTax replaces a line's tax, keeping the paid amount of inclusive lines.
+func setTax(lr *LineResult, tax *big.Rat) {
+	if lr.Rate.Inclusive && !lr.Exempt {
+		amount := new(big.Rat).Add(lr.Net, lr.Tax)
+		lr.Net = new(big.Rat).Sub(amount, tax)
+	}
+	lr.Tax = tax
+}
+
+func groupKey(r Rate, exempt bool) string {
+	return fmt.Sprintf("%s|%s|%t|%t", r.Code, ratOrZero(r.Percent).RatString(), r.Inclusive, exempt)
+}
+
+func ratOrZero(r *big.Rat) *big.Rat {
+	if r == nil {
+		return new(big.Rat)
+	}
+	return r
+}
+
+// Round rounds r half away from zero to scale decimal places.
+func Round(r *big.Rat, scale int) *big.Rat {
+	out, _ := new(big.Rat).SetString(r.FloatString(scale))
+	return out
+}
+
+// truncate drops digits beyond scale, rounding toward zero.
+func truncate(r *big.Rat, scale int) *big.Rat {
+	m := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)
+	n := new(big.Int).Mul(r.Num(), m)
+	n.Quo(n, r.Denom())
+	return new(big.Rat).SetFrac(n, m)
+}
@@ -0,0 +1,337 @@
+package tax
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+)
+
+func rat(s string) *big.Rat {
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		panic("bad rational " + s)
+	}
+	return r
+}
+
+var (
+	vat15    = Rate{Code: "VAT15", Percent: rat("15")}
+	vat15Inc = Rate{Code: "VAT15I", Percent: rat("15"), Inclusive: true}
+	zero     = Rate{Code: "ZERO", Percent: rat("0")}
+)
+
+func line(qty, price string, rate Rate) Line {
+	return Line{Quantity: rat(qty), UnitPrice: rat(price), Rate: rate}
+}
+
+func discounted(l Line, discount string) Line {
+	l.Discount = rat(discount)
+	return l
+}
+
+func exempt(l Line) Line {
+	l.Exempt = true
+	return l
+}
+
+// wantLine is a line result as two-decimal strings.
+type wantLine struct {
+	net, tax, lineTotal string
+}
+
+func TestCalculate(t *testing.T) {
+	tests := []struct {
+		name     string
+		lines    []Line
+		opt      Options
+		want     []wantLine
+		subtotal string
+		tax      string
+		total    string
+	}{
+		{
+			name:     "exclusive rate adds tax on top",
+			lines:    []Line{line("2", "10.00", vat15)},
+			want:     []wantLine{{"20.00", "3.00", "20.00"}},
+			subtotal: "20.00", tax: "3.00", total: "23.00",
+		},
+		{
+			name:     "inclusive rate takes tax out of the amount",
+			lines:    []Line{line("1", "11.50", vat15Inc)},
+			want:     []wantLine{{"10.00", "1.50", "11.50"}},
+			subtotal: "10.00", tax: "1.50", total: "11.50",
+		},
+		{
+			name:     "inclusive rounded tax keeps the paid amount",
+			lines:    []Line{line("1", "10.00", vat15Inc)},
+			want:     []wantLine{{"8.70", "1.30", "10.00"}},
+			subtotal: "8.70", tax: "1.30", total: "10.00",
+		},
+		{
+			name:     "zero rate",
+			lines:    []Line{line("3", "1.25", zero)},
+			want:     []wantLine{{"3.75", "0.00", "3.75"}},
+			subtotal: "3.75", tax: "0.00", total: "3.75",
+		},
+		{
+			name:     "exempt exclusive line",
+			lines:    []Line{exempt(line("1", "10.00", vat15))},
+			want:     []wantLine{{"10.00", "0.00", "10.00"}},
+			subtotal: "10.00", tax: "0.00", total: "10.00",
+		},
+		{
+			name:     "exempt inclusive line is reduced to its net amount",
+			lines:    []Line{exempt(line("1", "11.50", vat15Inc))},
+			want:     []wantLine{{"10.00", "0.00", "10.00"}},
+			subtotal: "10.00", tax: "0.00", total: "10.00",
+		},
+		{
+			name:     "exempt document zero-rates every line",
+			lines:    []Line{line("1", "11.50", vat15Inc), line("1", "10.00", vat15)},
+			opt:      Options{Exempt: true},
+			want:     []wantLine{{"10.00", "0.00", "10.00"}, {"10.00", "0.00", "10.00"}},
+			subtotal: "20.00", tax: "0.00", total: "20.00",
+		},
+		{
+			name:     "discount comes off before tax",
+			lines:    []Line{discounted(line("2", "10.00", vat15), "5.00")},
+			want:     []wantLine{{"15.00", "2.25", "15.00"}},
+			subtotal: "15.00", tax: "2.25", total: "17.25",
+		},
+		{
+			name:     "discount equal to the amount",
+			lines:    []Line{discounted(line("1", "10.00", vat15), "10.00")},
+			want:     []wantLine{{"0.00", "0.00", "0.00"}},
+			subtotal: "0.00", tax: "0.00", total: "0.00",
+		},
+		{
+			name:     "return line is negative",
+			lines:    []Line{line("-1", "10.00", vat15)},
+			want:     []wantLine{{"-10.00", "-1.50", "-10.00"}},
+			subtotal: "-10.00", tax: "-1.50", total: "-11.50",
+		},
+		{
+			name:     "return line may carry a discount",
+			lines:    []Line{discounted(line("-1", "10.00", vat15), "5.00")},
+			want:     []wantLine{{"-15.00", "-2.25", "-15.00"}},
+			subtotal: "-15.00", tax: "-2.25", total: "-17.25",
+		},
+		{
+			name:     "inclusive return line",
+			lines:    []Line{line("-1", "11.50", vat15Inc)},
+			want:     []wantLine{{"-10.00", "-1.50", "-11.50"}},
+			subtotal: "-10.00", tax: "-1.50", total: "-11.50",
+		},
+		{
+			name:     "line rounding rounds each line half away from zero",
+			lines:    []Line{line("1", "0.10", vat15), line("1", "0.10", vat15), line("1", "0.10", vat15)},
+			opt:      Options{Rounding: RoundPerLine},
+			want:     []wantLine{{"0.10", "0.02", "0.10"}, {"0.10", "0.02", "0.10"}, {"0.10", "0.02", "0.10"}},
+			subtotal: "0.30", tax: "0.06", total: "0.36",
+		},
+		{
+			name:     "invoice rounding rounds the rate total once",
+			lines:    []Line{line("1", "0.10", vat15), line("1", "0.10", vat15), line("1", "0.10", vat15)},
+			opt:      Options{Rounding: RoundPerInvoice},
+			want:     []wantLine{{"0.10", "0.02", "0.10"}, {"0.10", "0.02", "0.10"}, {"0.10", "0.01", "0.10"}},
+			subtotal: "0.30", tax: "0.05", total: "0.35",
+		},
+		{
+			name: "invoice rounding gives the units to the largest remainders",
+			// exact taxes 0.0165, 0.0195 and 0.018: 0.054 rounds to 0.05
+			lines:    []Line{line("1", "0.11", vat15), line("1", "0.13", vat15), line("1", "0.12", vat15)},
+			opt:      Options{Rounding: RoundPerInvoice},
+			want:     []wantLine{{"0.11", "0.01", "0.11"}, {"0.13", "0.02", "0.13"}, {"0.12", "0.02", "0.12"}},
+			subtotal: "0.36", tax: "0.05", total: "0.41",
+		},
+		{
+			name:     "line rounding of the same lines",
+			lines:    []Line{line("1", "0.11", vat15), line("1", "0.13", vat15), line("1", "0.12", vat15)},
+			want:     []wantLine{{"0.11", "0.02", "0.11"}, {"0.13", "0.02", "0.13"}, {"0.12", "0.02", "0.12"}},
+			subtotal: "0.36", tax: "0.06", total: "0.42",
+		},
+		{
+			name:     "invoice rounding of return lines gives the units to the most negative remainders",
+			lines:    []Line{line("-1", "0.11", vat15), line("-1", "0.13", vat15), line("-1", "0.12", vat15)},
+			opt:      Options{Rounding: RoundPerInvoice},
+			want:     []wantLine{{"-0.11", "-0.01", "-0.11"}, {"-0.13", "-0.02", "-0.13"}, {"-0.12", "-0.02", "-0.12"}},
+			subtotal: "-0.36", tax: "-0.05", total: "-0.41",
+		},
+		{
+			name: "invoice rounding of inclusive lines moves the adjustment into net",
+			// exact tax 0.013043... per line: 0.0391 rounds to 0.04
+			lines:    []Line{line("1", "0.10", vat15Inc), line("1", "0.10", vat15Inc), line("1", "0.10", vat15Inc)},
+			opt:      Options{Rounding: RoundPerInvoice},
+			want:     []wantLine{{"0.08", "0.02", "0.10"}, {"0.09", "0.01", "0.10"}, {"0.09", "0.01", "0.10"}},
+			subtotal: "0.26", tax: "0.04", total: "0.30",
+		},
+		{
+			name: "invoice rounding is per rate",
+			lines: []Line{
+				line("1", "0.10", vat15), line("1", "0.10", vat15Inc),
+				line("1", "0.10", vat15), line("1", "0.10", vat15Inc),
+			},
+			opt: Options{Rounding: RoundPerInvoice},
+			want: []wantLine{
+				{"0.10", "0.02", "0.10"}, {"0.08", "0.02", "0.10"},
+				{"0.10", "0.01", "0.10"}, {"0.09", "0.01", "0.10"},
+			},
+			subtotal: "0.37", tax: "0.06", total: "0.43",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := Calculate(tt.lines, tt.opt)
+			if err != nil {
+				t.Fatalf("Calculate: %v", err)
+			}
+			if len(res.Lines) != len(tt.want) {
+				t.Fatalf("got %d lines, want %d", len(res.Lines), len(tt.want))
+			}
+			for i, w := range tt.want {
+				got := wantLine{
+					res.Lines[i].Net.FloatString(2),
+					res.Lines[i].Tax.FloatString(2),
+					res.Lines[i].LineTotal.FloatString(2),
+				}
+				if got != w {
+					t.Errorf("line %d: got net/tax/line total %v, want %v", i+1, got, w)
+				}
+			}
+			if got := res.Subtotal.FloatString(2); got != tt.subtotal {
+				t.Errorf("subtotal = %s, want %s", got, tt.subtotal)
+			}
+			if got := res.Tax.FloatString(2); got != tt.tax {
+				t.Errorf("tax = %s, want %s", got, tt.tax)
+			}
+			if got := res.Total.FloatString(2); got != tt.total {
+				t.Errorf("total = %s, want %s", got, tt.total)
+			}
+		})
+	}
+}
+
+func TestCalculateErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		lines   []Line
+		wantErr error
+	}{
+		{
+			name:    "discount greater than the amount",
+			lines:   []Line{discounted(line("1", "10.00", vat15), "12.00")},
+			wantErr: ErrNegativeAmount,
+		},
+		{
+			name:    "discount on a zero amount",
+			lines:   []Line{discounted(line("0", "10.00", vat15), "1.00")},
+			wantErr: ErrNegativeAmount,
+		},
+		{
+			name:  "negative rate",
+			lines: []Line{line("1", "10.00", Rate{Code: "BAD", Percent: rat("-5")})},
+		},
+		{
+			name:  "missing unit price",
+			lines: []Line{{Quantity: rat("1"), Rate: vat15}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Calculate(tt.lines, Options{})
+			if err == nil {
+				t.Fatal("Calculate succeeded, want an error")
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCalculateBreakdown(t *testing.T) {
+	res, err := Calculate([]Line{
+		line("1", "10.00", zero),
+		line("1", "10.00", vat15),
+		exempt(line("1", "10.00", vat15)),
+		line("1", "20.00", vat15),
+	}, Options{})
+	if err != nil {
+		t.Fatalf("Calculate: %v", err)
+	}
+
+	want := []struct {
+		code, taxable, tax string
+		exempt             bool
+	}{
+		{"VAT15", "30.00", "4.50", false},
+		{"VAT15", "10.00", "0.00", true},
+		{"ZERO", "10.00", "0.00", false},
+	}
+	if len(res.Breakdown) != len(want) {
+		t.Fatalf("got %d rate totals, want %d", len(res.Breakdown), len(want))
+	}
+	for i, w := range want {
+		g := res.Breakdown[i]
+		if g.Code != w.code || g.Exempt != w.exempt || g.Taxable.FloatString(2) != w.taxable || g.Tax.FloatString(2) != w.tax {
+			t.Errorf("rate total %d = %s exempt=%t taxable %s tax %s, want %s exempt=%t taxable %s tax %s",
+				i+1, g.Code, g.Exempt, g.Taxable.FloatString(2), g.Tax.FloatString(2), w.code, w.exempt, w.taxable, w.tax)
+		}
+	}
+}
+
+// Round relies on big.Rat.FloatString rounding half away from zero, which
+// its documentation doesn't promise; this pins it.
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in    string
+		scale int
+		want  string
+	}{
+		{"0.005", 2, "0.01"},
+		{"-0.005", 2, "-0.01"},
+		{"0.015", 2, "0.02"},
+		{"-0.015", 2, "-0.02"},
+		{"1.125", 2, "1.13"},
+		{"-1.125", 2, "-1.13"},
+		{"0.0049", 2, "0.00"},
+		{"-0.0049", 2, "0.00"},
+		{"2.5", 0, "3"},
+		{"-2.5", 0, "-3"},
+		{"1.5", 0, "2"},
+		{"1/3", 2, "0.33"},
+		{"2/3", 2, "0.67"},
+		{"-2/3", 2, "-0.67"},
+		{"1.0005", 3, "1.001"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			if got := Round(rat(tt.in), tt.scale).FloatString(tt.scale); got != tt.want {
+				t.Errorf("Round(%s, %d) = %s, want %s", tt.in, tt.scale, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRounding(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Rounding
+		wantErr bool
+	}{
+		{"", RoundPerLine, false},
+		{"line", RoundPerLine, false},
+		{"invoice", RoundPerInvoice, false},
+		{"document", "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseRounding(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseRounding(%q) = %q, %v; want %q, error %t", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
@@ -3,22 +3,24 @@ package usecase
 import (
 	"context"
 	"strconv"
+	"time"
 
 	"NEMBUS/internal/middleware"
 	"NEMBUS/internal/repository"
 	"NEMBUS/utils" // Assuming your NewResponse is here
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 type AuthUseCase struct {
-	repo *repository.Queries
+	repo           *repository.Queries
+	passwordPolicy PasswordPolicy
+	lockout        LockoutPolicy
 }
 
 // NewAuthUseCase creates a new auth use case without a repository
 // Repository will be injected per request via SetRepository
-func NewAuthUseCase() *AuthUseCase {
-	return &AuthUseCase{}
+// Logins are refused for expired passwords and locked out per lockout
+func NewAuthUseCase(passwordPolicy PasswordPolicy, lockout LockoutPolicy) *AuthUseCase {
+	return &AuthUseCase{passwordPolicy: passwordPolicy, lockout: lockout}
 }
 
 // SetRepository sets the repository for this request
@@ -26,8 +28,20 @@ func (uc *AuthUseCase) SetRepository(repo *repository.Queries) {
 	uc.repo = repo
 }
 
-// Login authenticates a user and returns a JWT token
-func (uc *AuthUseCase) Login(ctx context.Context, userLogin, password string) *repository.Response {
+// LoginResult is the data of a successful login. DefaultStore is the store
+// the client should select, see DefaultStore; it is nil for users without
+// store access.
+type LoginResult struct {
+	Token        string
+	DefaultStore *repository.Store
+}
+
+// Login authenticates a user and returns a JWT token with their default store.
+// Every attempt is recorded in the login audit. Failed attempts get the same
+// unauthorized response whatever the reason, and lock the login name and the
+// client's address out for a while, see LockoutPolicy. Users whose password
+// has expired get a forbidden response and must use ChangePasswordAndLogin.
+func (uc *AuthUseCase) Login(ctx context.Context, userLogin, password string, client LoginClient) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
@@ -40,34 +54,116 @@ func (uc *AuthUseCase) Login(ctx context.Context, userLogin, password string) *r
 		return utils.NewResponse(utils.CodeBadReq, "password cannot be empty", nil)
 	}
 
-	// Get user by username
-	user, err := uc.repo.GetUserByUsername(ctx, userLogin)
+	now := time.Now().UTC()
+	if resp := uc.checkLockout(ctx, userLogin, client, now); resp != nil {
+		return resp
+	}
+	user, resp := uc.authenticate(ctx, userLogin, password, client, now)
+	if resp != nil {
+		return resp
+	}
+
+	changedAt, err := passwordChangedAt(ctx, uc.repo, user)
 	if err != nil {
-		return utils.NewResponse(utils.CodeNotFound, "invalid credentials", nil)
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if uc.passwordPolicy.Expired(changedAt, now) {
+		if err := uc.audit(ctx, &user.ID, userLogin, reasonPasswordExpired, client, now); err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeForbidden, "password expired, change it to sign in", nil)
 	}
 
-	// Check if user is active
-	if !user.IsActive.Bool || !user.IsActive.Valid {
-		return utils.NewResponse(utils.CodeError, "user account is inactive", nil)
+	return uc.signIn(ctx, user, client, now)
+}
+
+// ChangePasswordAndLogin changes a user's password after checking the
+// current one, then signs them in like Login. It is how users whose password
+// has expired sign in again, and is locked out and audited like Login.
+func (uc *AuthUseCase) ChangePasswordAndLogin(ctx context.Context, userLogin, currentPassword, newPassword string, client LoginClient) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if userLogin == "" {
+		return utils.NewResponse(utils.CodeBadReq, "user_login cannot be empty", nil)
+	}
+	if currentPassword == "" || newPassword == "" {
+		return utils.NewResponse(utils.CodeBadReq, "passwords cannot be empty", nil)
 	}
 
-	// Check if password_hash exists
-	if user.PasswordHash == "" {
-		return utils.NewResponse(utils.CodeError, "password not set for this user", nil)
+	now := time.Now().UTC()
+	if resp := uc.checkLockout(ctx, userLogin, client, now); resp != nil {
+		return resp
+	}
+	user, resp := uc.authenticate(ctx, userLogin, currentPassword, client, now)
+	if resp != nil {
+		return resp
+	}
+	if newPassword == currentPassword {
+		return utils.NewResponse(utils.CodeBadReq, "new password must differ from the current one", nil)
+	}
+	if resp := setPassword(ctx, uc.repo, uc.passwordPolicy, user, newPassword); resp != nil {
+		return resp
 	}
 
-	// Compare password with hash
-	err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password))
+	return uc.signIn(ctx, user, client, now)
+}
+
+// ChangePassword changes the signed-in user's password after checking the
+// current one. It is locked out and audited like Login, but a wrong current
+// password gets a bad request rather than signing the client out. Their
+// sessions are revoked and a new token is returned for this one, like
+// Login's.
+func (uc *AuthUseCase) ChangePassword(ctx context.Context, userID int32, currentPassword, newPassword string, client LoginClient) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	user, err := uc.repo.GetUser(ctx, userID)
 	if err != nil {
-		return utils.NewResponse(utils.CodeError, "invalid credentials", nil)
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
 	}
 
+	now := time.Now().UTC()
+	if resp := uc.checkLockout(ctx, user.Username, client, now); resp != nil {
+		return resp
+	}
+	if _, resp := uc.authenticate(ctx, user.Username, currentPassword, client, now); resp != nil {
+		if resp.StatusCode == utils.CodeUnauthorized {
+			return utils.NewResponse(utils.CodeBadReq, "current password is incorrect", nil)
+		}
+		return resp
+	}
+	if newPassword == currentPassword {
+		return utils.NewResponse(utils.CodeBadReq, "new password must differ from the current one", nil)
+	}
+	if resp := setPassword(ctx, uc.repo, uc.passwordPolicy, user, newPassword); resp != nil {
+		return resp
+	}
+
+	return uc.signIn(ctx, user, client, now)
+}
+
+// signIn records a successful login and returns the user's token and default
+// store.
+func (uc *AuthUseCase) signIn(ctx context.Context, user repository.User, client LoginClient, now time.Time) *repository.Response {
 	// Generate JWT token - convert user ID from int32 to string
 	userIDStr := strconv.FormatInt(int64(user.ID), 10)
-	token, err := middleware.GenerateJWTToken(userIDStr, userLogin)
+	token, err := middleware.GenerateJWTToken(userIDStr, user.Username)
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, "failed to generate token", nil)
 	}
 
-	return utils.NewResponse(utils.CodeOK, "login successful", token)
+	defaultStore, err := DefaultStore(ctx, uc.repo, user.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	if err := uc.audit(ctx, &user.ID, user.Username, "", client, now); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	return utils.NewResponse(utils.CodeOK, "login successful", LoginResult{
+		Token:        token,
+		DefaultStore: defaultStore,
+	})
 }
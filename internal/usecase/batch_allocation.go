@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"time"
+
+	"NEMBUS/internal/inventory"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PermissionBatchOverride lets a user pick a batch by hand instead of the
+// first-expiry-first-out allocation.
+const PermissionBatchOverride = "inventory.batch_override"
+
+// allocateBatches picks the batches a quantity of a batch-managed product is
+// taken from in a store: first-expiry-first-out, or the given batch when
+// batchNumber is set. Expired batches are never allocated. Stock problems
+// are returned as documentInputError.
+func allocateBatches(ctx context.Context, q *repository.Queries, product repository.Product, variantID pgtype.Int4, storeID int32, qty *big.Rat, batchNumber string, on time.Time) ([]inventory.Allocation, error) {
+	rows, err := q.ListAllocatableBatches(ctx, repository.ListAllocatableBatchesParams{
+		ProductID:        product.ID,
+		ProductVariantID: variantID,
+		StoreID:          pgtype.Int4{Int32: storeID, Valid: true},
+	})
+	if err != nil {
+		return nil, err
+	}
+	batches := make([]inventory.Batch, len(rows))
+	for i, row := range rows {
+		batches[i] = inventoryBatch(row)
+	}
+
+	var allocs []inventory.Allocation
+	if batchNumber != "" {
+		allocs, err = inventory.AllocateBatch(batches, batchNumber, qty, on)
+	} else {
+		allocs, err = inventory.Allocate(batches, qty, on)
+	}
+	if err != nil {
+		return nil, documentInputErrorf("product %s: %s", product.Sku, err.Error())
+	}
+	return allocs, nil
+}
+
+// checkBatchOverride makes sure userID may choose batches by hand.
+func checkBatchOverride(ctx context.Context, q *repository.Queries, userID *int32) *repository.Response {
+	if userID == nil {
+		return utils.NewResponse(utils.CodeForbidden, "choosing a batch requires the "+PermissionBatchOverride+" permission", nil)
+	}
+	ok, err := q.CheckUserHasPermission(ctx, repository.CheckUserHasPermissionParams{UserID: *userID, Code: PermissionBatchOverride})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if !ok {
+		return utils.NewResponse(utils.CodeForbidden, "choosing a batch requires the "+PermissionBatchOverride+" permission", nil)
+	}
+	return nil
+}
+
+// consumeBatch takes an allocation from its batch. A batch that was sold,
+// expired or emptied since it was allocated fails with documentInputError.
+func consumeBatch(ctx context.Context, q *repository.Queries, a inventory.Allocation, on time.Time) error {
+	_, err := q.ConsumeBatchQuantity(ctx, repository.ConsumeBatchQuantityParams{
+		Quantity: utils.RatToNumeric(a.Quantity, 3),
+		ID:       a.Batch.ID,
+		OnDate:   pgtype.Date{Time: on, Valid: true},
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return documentInputErrorf("batch %s no longer has %s available", a.Batch.Number, a.Quantity.FloatString(3))
+	}
+	return err
+}
+
+func inventoryBatch(row repository.ProductBatch) inventory.Batch {
+	b := inventory.Batch{
+		ID:        row.ID,
+		Number:    row.BatchNumber,
+		Available: utils.NumericToRat(row.QuantityAvailable),
+	}
+	if row.ExpiryDate.Valid {
+		b.Expiry = &row.ExpiryDate.Time
+	}
+	if row.ManufacturingDate.Valid {
+		b.Manufactured = &row.ManufacturingDate.Time
+	}
+	return b
+}
+
+// splitAmount shares amount across parts in proportion to their quantities,
+// rounded to scale places; the last part takes the rounding remainder.
+func splitAmount(amount *big.Rat, parts []*big.Rat, scale int) []*big.Rat {
+	total := new(big.Rat)
+	for _, p := range parts {
+		total.Add(total, p)
+	}
+	out := make([]*big.Rat, len(parts))
+	left := new(big.Rat).Set(amount)
+	for i, p := range parts {
+		if i == len(parts)-1 || total.Sign() == 0 {
+			out[i] = new(big.Rat).Set(left)
+			left.SetInt64(0)
+			continue
+		}
+		share := new(big.Rat).Mul(amount, p)
+		share = utils.RoundRat(share.Quo(share, total), scale)
+		out[i] = share
+		left.Sub(left, share)
+	}
+	return out
+}
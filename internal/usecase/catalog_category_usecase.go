@@ -0,0 +1,348 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CategoryInput is the input for CreateCategory and UpdateCategory. The
+// parent is only read on create; moves go through MoveCategory.
+type CategoryInput struct {
+	ParentID    *int32
+	Name        string
+	Code        string
+	Description *string
+	IsActive    *bool
+}
+
+// CategoryNode is a category in the nested tree, with the number of active
+// products filed directly under it.
+type CategoryNode struct {
+	ID           int32           `json:"id"`
+	ParentID     *int32          `json:"parent_id"`
+	Name         string          `json:"name"`
+	Code         string          `json:"code"`
+	Description  string          `json:"description,omitempty"`
+	Level        int32           `json:"level"`
+	IsActive     bool            `json:"is_active"`
+	ProductCount int64           `json:"product_count"`
+	Children     []*CategoryNode `json:"children"`
+}
+
+// CategoryDetail is a category with its path from the root, its direct
+// subcategories and its product count.
+type CategoryDetail struct {
+	repository.ProductCategory
+	Path         []string                     `json:"path"`
+	ProductCount int64                        `json:"product_count"`
+	Children     []repository.ProductCategory `json:"children"`
+}
+
+// maxCategoryDepth bounds walks up the parent chain.
+const maxCategoryDepth = 100
+
+// GetCategoryTree returns the categories as a nested tree, roots and
+// children sorted by name. With activeOnly inactive categories are left
+// out together with everything below them.
+func (uc *CatalogUseCase) GetCategoryTree(ctx context.Context, activeOnly bool) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	var filter interface{}
+	if activeOnly {
+		filter = true
+	}
+	rows, err := uc.repo.GetCategoryHierarchy(ctx, filter)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	counts, err := uc.repo.ListCategoryProductCounts(ctx)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	productCount := make(map[int32]int64, len(counts))
+	for _, c := range counts {
+		productCount[c.CategoryID.Int32] = c.ProductCount
+	}
+
+	// Rows come in path order, so a parent is always seen before its
+	// children.
+	nodes := make(map[int32]*CategoryNode, len(rows))
+	roots := []*CategoryNode{}
+	for _, r := range rows {
+		n := &CategoryNode{
+			ID:           r.ID,
+			Name:         r.Name,
+			Code:         r.Code,
+			Description:  r.Description.String,
+			Level:        r.Level,
+			IsActive:     r.IsActive.Bool,
+			ProductCount: productCount[r.ID],
+			Children:     []*CategoryNode{},
+		}
+		if !r.ParentCategoryID.Valid {
+			roots = append(roots, n)
+			nodes[r.ID] = n
+			continue
+		}
+		parent, ok := nodes[r.ParentCategoryID.Int32]
+		if !ok {
+			continue // under a filtered-out category
+		}
+		n.ParentID = &parent.ID
+		parent.Children = append(parent.Children, n)
+		nodes[r.ID] = n
+	}
+	sortCategoryNodes(roots)
+	return utils.NewResponse(utils.CodeOK, "category tree fetched successfully", roots)
+}
+
+func sortCategoryNodes(nodes []*CategoryNode) {
+	sort.Slice(nodes, func(i, j int) bool {
+		return strings.ToLower(nodes[i].Name) < strings.ToLower(nodes[j].Name)
+	})
+	for _, n := range nodes {
+		sortCategoryNodes(n.Children)
+	}
+}
+
+// GetCategory returns a category with its path, subcategories and product
+// count.
+func (uc *CatalogUseCase) GetCategory(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	cat, err := uc.repo.GetProductCategory(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "category not found", nil)
+	}
+	d := &CategoryDetail{ProductCategory: cat, Path: []string{cat.Name}}
+	for parent := cat.ParentCategoryID; parent.Valid && len(d.Path) < maxCategoryDepth; {
+		p, err := uc.repo.GetProductCategory(ctx, parent.Int32)
+		if err != nil {
+			break
+		}
+		d.Path = append([]string{p.Name}, d.Path...)
+		parent = p.ParentCategoryID
+	}
+	if d.Children, err = uc.repo.ListCategoryChildren(ctx, repository.ListCategoryChildrenParams{
+		ParentCategoryID: pgtype.Int4{Int32: id, Valid: true},
+	}); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if d.Children == nil {
+		d.Children = []repository.ProductCategory{}
+	}
+	if d.ProductCount, err = uc.repo.CountCategoryProducts(ctx, pgtype.Int4{Int32: id, Valid: true}); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "category fetched successfully", d)
+}
+
+// CreateCategory creates a category under an optional parent; its level
+// follows from the parent.
+func (uc *CatalogUseCase) CreateCategory(ctx context.Context, in *CategoryInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	name, code := strings.TrimSpace(in.Name), strings.TrimSpace(in.Code)
+	if name == "" || code == "" {
+		return utils.NewResponse(utils.CodeBadReq, "name and code are required", nil)
+	}
+	arg := repository.CreateProductCategoryParams{
+		Name:          name,
+		Code:          code,
+		CategoryLevel: pgtype.Int4{Int32: 1, Valid: true},
+		IsActive:      boolOr(in.IsActive, true),
+		Metadata:      []byte("{}"),
+	}
+	if in.Description != nil {
+		arg.Description = optionalText(*in.Description)
+	}
+	if in.ParentID != nil {
+		if _, err := uc.repo.GetProductCategory(ctx, *in.ParentID); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "parent category not found", nil)
+		}
+		arg.ParentCategoryID = pgtype.Int4{Int32: *in.ParentID, Valid: true}
+	}
+
+	var cat repository.ProductCategory
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		if cat, err = q.CreateProductCategory(ctx, arg); err != nil {
+			return err
+		}
+		if _, err = q.UpdateCategorySubtreeLevels(ctx, cat.ID); err != nil {
+			return err
+		}
+		cat, err = q.GetProductCategory(ctx, cat.ID)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "category created successfully", cat)
+}
+
+// UpdateCategory changes a category's name, description or status.
+func (uc *CatalogUseCase) UpdateCategory(ctx context.Context, id int32, in *CategoryInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetProductCategory(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "category not found", nil)
+	}
+	arg := repository.UpdateProductCategoryParams{
+		ID:       id,
+		Name:     optionalText(in.Name),
+		IsActive: optionalBool(in.IsActive),
+	}
+	if in.Description != nil {
+		arg.Description = pgtype.Text{String: strings.TrimSpace(*in.Description), Valid: true}
+	}
+	cat, err := uc.repo.UpdateProductCategory(ctx, arg)
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "category updated successfully", cat)
+}
+
+// MoveCategory moves a category with its subtree under a new parent, or
+// to the root when parentID is nil, and recalculates the levels of the
+// subtree. A category cannot move under itself or its descendants.
+func (uc *CatalogUseCase) MoveCategory(ctx context.Context, id int32, parentID *int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetProductCategory(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "category not found", nil)
+	}
+	var parent pgtype.Int4
+	if parentID != nil {
+		if _, err := uc.repo.GetProductCategory(ctx, *parentID); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "parent category not found", nil)
+		}
+		parent = pgtype.Int4{Int32: *parentID, Valid: true}
+	}
+
+	var cat repository.ProductCategory
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		// Two concurrent moves could each pass the cycle check and
+		// together make a cycle.
+		if err := q.LockCategoryTree(ctx); err != nil {
+			return err
+		}
+		if parent.Valid {
+			if err := checkCategoryTarget(ctx, q, id, parent.Int32); err != nil {
+				return err
+			}
+		}
+		var err error
+		if cat, err = q.SetCategoryParent(ctx, repository.SetCategoryParentParams{ID: id, ParentCategoryID: parent}); err != nil {
+			return err
+		}
+		if _, err = q.UpdateCategorySubtreeLevels(ctx, id); err != nil {
+			return err
+		}
+		cat, err = q.GetProductCategory(ctx, id)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "category moved successfully", cat)
+}
+
+// checkCategoryTarget rejects target when it is category id or one of its
+// descendants: moving there, or handing id's children to it, would make a
+// cycle.
+func checkCategoryTarget(ctx context.Context, q *repository.Queries, id, target int32) error {
+	subtree, err := q.ListCategorySubtreeIDs(ctx, id)
+	if err != nil {
+		return err
+	}
+	for _, sub := range subtree {
+		if sub == target {
+			return documentInputErrorf("category %d is category %d or one of its subcategories", target, id)
+		}
+	}
+	return nil
+}
+
+// CategoryDeleteResult reports what a category delete moved.
+type CategoryDeleteResult struct {
+	ProductsMoved      int64 `json:"products_moved"`
+	SubcategoriesMoved int64 `json:"subcategories_moved"`
+}
+
+// DeleteCategory deletes a category. A category with subcategories or
+// products is only deleted when reassignTo names a category outside its
+// subtree; its products and subcategories move there first. Expiry rules
+// of the category are deleted with it.
+func (uc *CatalogUseCase) DeleteCategory(ctx context.Context, id int32, reassignTo *int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetProductCategory(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "category not found", nil)
+	}
+	if reassignTo != nil {
+		if _, err := uc.repo.GetProductCategory(ctx, *reassignTo); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "reassign_to category not found", nil)
+		}
+	}
+
+	from := pgtype.Int4{Int32: id, Valid: true}
+	res := &CategoryDeleteResult{}
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		if err := q.LockCategoryTree(ctx); err != nil {
+			return err
+		}
+		if reassignTo == nil {
+			children, err := q.ListCategoryChildren(ctx, repository.ListCategoryChildrenParams{ParentCategoryID: from})
+			if err != nil {
+				return err
+			}
+			products, err := q.CountCategoryProducts(ctx, from)
+			if err != nil {
+				return err
+			}
+			if len(children) > 0 || products > 0 {
+				return documentInputErrorf("category has %d subcategories and %d products; pass reassign_to to move them", len(children), products)
+			}
+			return q.DeleteProductCategory(ctx, id)
+		}
+
+		if err := checkCategoryTarget(ctx, q, id, *reassignTo); err != nil {
+			return err
+		}
+		to := pgtype.Int4{Int32: *reassignTo, Valid: true}
+		var err error
+		if res.ProductsMoved, err = q.ReassignCategoryProducts(ctx, repository.ReassignCategoryProductsParams{ToCategoryID: to, FromCategoryID: from}); err != nil {
+			return err
+		}
+		if res.SubcategoriesMoved, err = q.ReparentCategoryChildren(ctx, repository.ReparentCategoryChildrenParams{ToCategoryID: to, FromCategoryID: from}); err != nil {
+			return err
+		}
+		if err := q.DeleteProductCategory(ctx, id); err != nil {
+			return err
+		}
+		_, err = q.UpdateCategorySubtreeLevels(ctx, *reassignTo)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	msg := "category deleted successfully"
+	if reassignTo != nil {
+		msg = fmt.Sprintf("category deleted; %d products and %d subcategories moved to category %d", res.ProductsMoved, res.SubcategoriesMoved, *reassignTo)
+	}
+	return utils.NewResponse(utils.CodeOK, msg, res)
+}
@@ -0,0 +1,1135 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/catalog"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/spreadsheet"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+const (
+	// catalogImportBatchSize is the default number of products committed
+	// per transaction.
+	catalogImportBatchSize = 100
+	maxCatalogImportBatch  = 1000
+	// maxImportErrors caps the row errors kept on a job.
+	maxImportErrors = 1000
+)
+
+// CatalogImportOptions control a catalog import job.
+type CatalogImportOptions struct {
+	OrganizationID int32
+	// DryRun validates the file and reports what would be created without
+	// writing anything.
+	DryRun bool
+	// CreateMissing creates the categories, brands and units of measure the
+	// file refers to by an unknown code.
+	CreateMissing bool
+	// SkipInvalid imports the valid products of a file that has errors;
+	// otherwise any error rejects the whole file.
+	SkipInvalid bool
+	// BatchSize is the number of products committed per transaction.
+	BatchSize int
+	CreatedBy *int32
+}
+
+// CatalogImportSettings are the options stored on an import job.
+type CatalogImportSettings struct {
+	CreateMissing bool `json:"create_missing"`
+	SkipInvalid   bool `json:"skip_invalid"`
+	BatchSize     int  `json:"batch_size"`
+}
+
+// CatalogImportSummary counts what an import created, or would create in
+// a dry run.
+type CatalogImportSummary struct {
+	ProductsCreated   int `json:"products_created"`
+	ProductsUpdated   int `json:"products_updated"`
+	VariantsCreated   int `json:"variants_created"`
+	BarcodesCreated   int `json:"barcodes_created"`
+	PricesSet         int `json:"prices_set"`
+	OpeningStock      int `json:"opening_stock_posted"`
+	CategoriesCreated int `json:"categories_created"`
+	BrandsCreated     int `json:"brands_created"`
+	UnitsCreated      int `json:"units_created"`
+}
+
+// CatalogImportJob is an import job with its row errors and summary.
+type CatalogImportJob struct {
+	ID             int32                 `json:"id"`
+	OrganizationID int32                 `json:"organization_id"`
+	FileName       string                `json:"file_name"`
+	Status         string                `json:"status"`
+	DryRun         bool                  `json:"dry_run"`
+	Options        CatalogImportSettings `json:"options"`
+	TotalRows      int32                 `json:"total_rows"`
+	ProcessedRows  int32                 `json:"processed_rows"`
+	SucceededRows  int32                 `json:"succeeded_rows"`
+	FailedRows     int32                 `json:"failed_rows"`
+	Errors         []ImportRowError      `json:"errors"`
+	Summary        CatalogImportSummary  `json:"summary"`
+	ErrorMessage   string                `json:"error_message,omitempty"`
+	CreatedBy      pgtype.Int4           `json:"created_by"`
+	StartedAt      pgtype.Timestamp      `json:"started_at"`
+	FinishedAt     pgtype.Timestamp      `json:"finished_at"`
+	CreatedAt      pgtype.Timestamp      `json:"created_at"`
+}
+
+func catalogImportJobView(j repository.CatalogImportJob) CatalogImportJob {
+	v := CatalogImportJob{
+		ID:             j.ID,
+		OrganizationID: j.OrganizationID,
+		FileName:       j.FileName,
+		Status:         j.Status,
+		DryRun:         j.DryRun,
+		TotalRows:      j.TotalRows,
+		ProcessedRows:  j.ProcessedRows,
+		SucceededRows:  j.SucceededRows,
+		FailedRows:     j.FailedRows,
+		Errors:         []ImportRowError{},
+		ErrorMessage:   j.ErrorMessage.String,
+		CreatedBy:      j.CreatedBy,
+		StartedAt:      j.StartedAt,
+		FinishedAt:     j.FinishedAt,
+		CreatedAt:      j.CreatedAt,
+	}
+	_ = json.Unmarshal(j.Options, &v.Options)
+	_ = json.Unmarshal(j.Errors, &v.Errors)
+	_ = json.Unmarshal(j.Summary, &v.Summary)
+	return v
+}
+
+// StartImport checks the file's header and queues an import job for it.
+// The job runs in the background; its progress, row errors and summary are
+// read with GetImportJob.
+func (uc *CatalogUseCase) StartImport(ctx context.Context, fileName string, file io.Reader, opt CatalogImportOptions) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	orgID, resp := activeOrganization(ctx, opt.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	opt.OrganizationID = orgID
+	if _, err := uc.repo.GetOrganization(ctx, opt.OrganizationID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "organization not found", nil)
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, "cannot read file", nil)
+	}
+	rows, err := spreadsheet.Read(fileName, bytes.NewReader(data))
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	if len(rows) < 2 {
+		return utils.NewResponse(utils.CodeBadReq, "file has no data rows", nil)
+	}
+	if _, ok := spreadsheet.Header(rows[0])["sku"]; !ok {
+		return utils.NewResponse(utils.CodeBadReq, "missing column sku (columns: "+strings.Join(catalog.Columns, ", ")+")", nil)
+	}
+
+	settings := CatalogImportSettings{
+		CreateMissing: opt.CreateMissing,
+		SkipInvalid:   opt.SkipInvalid,
+		BatchSize:     opt.BatchSize,
+	}
+	if settings.BatchSize <= 0 {
+		settings.BatchSize = catalogImportBatchSize
+	}
+	if settings.BatchSize > maxCatalogImportBatch {
+		settings.BatchSize = maxCatalogImportBatch
+	}
+	options, _ := json.Marshal(settings)
+	job, err := uc.repo.CreateCatalogImportJob(ctx, repository.CreateCatalogImportJobParams{
+		OrganizationID: opt.OrganizationID,
+		FileName:       fileName,
+		DryRun:         opt.DryRun,
+		Options:        options,
+		InputFile:      data,
+		CreatedBy:      optionalInt4(opt.CreatedBy),
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	go runCatalogImport(uc.repo, job.ID)
+	return utils.NewResponse(utils.CodeAccepted, "import queued", catalogImportJobView(job))
+}
+
+// runCatalogImport runs a queued import job outside the request. Jobs it
+// misses, for example because the server stopped, are picked up by the
+// scheduled catalog import job.
+func runCatalogImport(repo *repository.Queries, jobID int32) {
+	worker := NewCatalogUseCase()
+	worker.SetRepository(repo)
+	if _, err := worker.RunImportJob(context.Background(), &jobID); err != nil {
+		log.Printf("catalog import %d: %v", jobID, err)
+	}
+}
+
+// GetImportJob returns an import job with its progress and row errors.
+func (uc *CatalogUseCase) GetImportJob(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	job, err := uc.repo.GetCatalogImportJob(ctx, id)
+	if err != nil || !inActiveOrganization(ctx, job.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "import job not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "import job retrieved successfully", catalogImportJobView(job))
+}
+
+// ListImportJobs returns an organization's import jobs, newest first.
+func (uc *CatalogUseCase) ListImportJobs(ctx context.Context, orgID, limit, offset int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	orgID, resp := activeOrganization(ctx, orgID)
+	if resp != nil {
+		return resp
+	}
+	jobs, err := uc.repo.ListCatalogImportJobs(ctx, repository.ListCatalogImportJobsParams{
+		OrganizationID: orgID,
+		Limit:          limit,
+		Offset:         offset,
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	out := make([]CatalogImportJob, 0, len(jobs))
+	for _, j := range jobs {
+		out = append(out, catalogImportJobView(j))
+	}
+	return utils.NewResponse(utils.CodeOK, "import jobs retrieved successfully", out)
+}
+
+// RunImportJob claims and runs the given queued job, or the oldest queued
+// job when jobID is nil. It reports false when there was nothing to run.
+func (uc *CatalogUseCase) RunImportJob(ctx context.Context, jobID *int32) (bool, error) {
+	if uc.repo == nil {
+		return false, errors.New("repository not set")
+	}
+	job, err := uc.repo.ClaimCatalogImportJob(ctx, optionalInt4(jobID))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	imp := &catalogImport{job: job, refs: newCatalogRefs(job.OrganizationID)}
+	status := "completed"
+	var message pgtype.Text
+	if err := uc.importCatalog(ctx, imp); err != nil {
+		status = "failed"
+		message = pgtype.Text{String: err.Error(), Valid: true}
+	}
+	errs := imp.errors
+	if errs == nil {
+		errs = []ImportRowError{}
+	}
+	errJSON, _ := json.Marshal(errs)
+	summary, _ := json.Marshal(imp.summary)
+	err = uc.repo.FinishCatalogImportJob(ctx, repository.FinishCatalogImportJobParams{
+		ID:            job.ID,
+		Status:        status,
+		TotalRows:     int32(imp.total),
+		ProcessedRows: int32(imp.processed),
+		SucceededRows: int32(imp.succeeded),
+		FailedRows:    int32(imp.failed),
+		Errors:        errJSON,
+		Summary:       summary,
+		ErrorMessage:  message,
+	})
+	return true, err
+}
+
+// catalogImport is the state of one running import.
+type catalogImport struct {
+	job       repository.CatalogImportJob
+	settings  CatalogImportSettings
+	refs      *catalogRefs
+	products  []*importProduct
+	errors    []ImportRowError
+	summary   CatalogImportSummary
+	total     int
+	processed int
+	succeeded int
+	failed    int
+}
+
+func (imp *catalogImport) addErrors(errs ...ImportRowError) {
+	for _, e := range errs {
+		if len(imp.errors) >= maxImportErrors {
+			return
+		}
+		imp.errors = append(imp.errors, e)
+	}
+}
+
+// importProduct is the rows of one SKU in an import file.
+type importProduct struct {
+	sku       string
+	lines     []int
+	fields    map[string]string // non-empty product columns
+	fieldLine map[string]int
+	existing  *repository.Product
+	variants  []*importVariant
+	byVariant map[string]*importVariant
+	items     []importItem
+	invalid   bool
+}
+
+type importVariant struct {
+	sku      string
+	name     string
+	attrs    map[string]string
+	line     int
+	existing *repository.ProductVariant
+}
+
+// importItem is what one row adds to its product or variant.
+type importItem struct {
+	line        int
+	variant     *importVariant
+	barcode     string
+	barcodeType string
+	priceListID int32
+	price       string
+	storeID     int32
+	quantity    *big.Rat
+}
+
+// flag returns a yes/no product column from the file, else the existing
+// product's value.
+func (p *importProduct) flag(col string, current pgtype.Bool) bool {
+	if v, ok := p.fields[col]; ok {
+		b, _ := catalog.ParseBool(v)
+		return b
+	}
+	return current.Bool
+}
+
+// productInput maps the product columns onto ProductInput; reference codes
+// must be resolved by then.
+func (p *importProduct) productInput(orgID int32, refs *catalogRefs) ProductInput {
+	in := ProductInput{OrganizationID: orgID, SKU: p.sku, Name: p.fields["name"]}
+	if v, ok := p.fields["description"]; ok {
+		in.Description = &v
+	}
+	if v, ok := p.fields["product_type"]; ok {
+		in.ProductType = &v
+	}
+	in.CategoryID = refs.id("category_code", p.fields["category_code"])
+	in.BrandID = refs.id("brand_code", p.fields["brand_code"])
+	in.BaseUomID = refs.id("uom_code", p.fields["uom_code"])
+	in.TaxCategoryID = refs.id("tax_category_code", p.fields["tax_category_code"])
+	flags := map[string]**bool{
+		"is_serialized":          &in.IsSerialized,
+		"is_batch_managed":       &in.IsBatchManaged,
+		"is_active":              &in.IsActive,
+		"is_sellable":            &in.IsSellable,
+		"is_purchasable":         &in.IsPurchasable,
+		"allow_decimal_quantity": &in.AllowDecimalQuantity,
+		"track_inventory":        &in.TrackInventory,
+	}
+	for col, dst := range flags {
+		if v, ok := p.fields[col]; ok {
+			b, _ := catalog.ParseBool(v)
+			*dst = &b
+		}
+	}
+	return in
+}
+
+// count adds what writing p creates to sum.
+func (p *importProduct) count(sum *CatalogImportSummary) {
+	if p.existing == nil {
+		sum.ProductsCreated++
+	} else if len(p.fields) > 0 {
+		sum.ProductsUpdated++
+	}
+	for _, v := range p.variants {
+		if v.existing == nil {
+			sum.VariantsCreated++
+		}
+	}
+	for _, it := range p.items {
+		if it.barcode != "" {
+			sum.BarcodesCreated++
+		}
+		if it.price != "" {
+			sum.PricesSet++
+		}
+		if it.quantity != nil {
+			sum.OpeningStock++
+		}
+	}
+}
+
+// catalogRefs resolves the reference codes of an import and remembers the
+// ones to create.
+type catalogRefs struct {
+	orgID   int32
+	ids     map[string]*int32
+	missing map[string][]string
+}
+
+func newCatalogRefs(orgID int32) *catalogRefs {
+	return &catalogRefs{orgID: orgID, ids: map[string]*int32{}, missing: map[string][]string{}}
+}
+
+// creatableRefs are the reference columns CreateMissing may create.
+var creatableRefs = map[string]bool{"category_code": true, "brand_code": true, "uom_code": true}
+
+var refLabels = map[string]string{
+	"category_code":     "category",
+	"brand_code":        "brand",
+	"uom_code":          "unit of measure",
+	"tax_category_code": "tax category",
+	"price_list_code":   "price list",
+	"store_code":        "store",
+}
+
+// resolve returns the ID of a code in a reference column, or nil when it
+// does not exist. An empty price list code is the default price list.
+func (r *catalogRefs) resolve(ctx context.Context, q *repository.Queries, col, code string) *int32 {
+	key := col + "\x00" + code
+	if id, ok := r.ids[key]; ok {
+		return id
+	}
+	var id *int32
+	switch col {
+	case "category_code":
+		if c, err := q.GetProductCategoryByCode(ctx, code); err == nil {
+			id = &c.ID
+		}
+	case "brand_code":
+		if b, err := q.GetBrandByCode(ctx, code); err == nil {
+			id = &b.ID
+		}
+	case "uom_code":
+		if u, err := q.GetUnitOfMeasureByCode(ctx, code); err == nil {
+			id = &u.ID
+		}
+	case "tax_category_code":
+		if t, err := q.GetTaxCategoryByCode(ctx, code); err == nil {
+			id = &t.ID
+		}
+	case "price_list_code":
+		if code == "" {
+			if pl, err := q.GetDefaultPriceList(ctx); err == nil {
+				id = &pl.ID
+			}
+		} else if pl, err := q.GetPriceListByCode(ctx, code); err == nil {
+			id = &pl.ID
+		}
+	case "store_code":
+		if s, err := q.GetStoreByCode(ctx, repository.GetStoreByCodeParams{OrganizationID: r.orgID, Code: code}); err == nil {
+			id = &s.ID
+		}
+	}
+	r.ids[key] = id
+	return id
+}
+
+// check validates a reference code and returns a message for the row when
+// it is unknown and cannot be created.
+func (r *catalogRefs) check(ctx context.Context, q *repository.Queries, col, code string, createMissing bool) string {
+	if r.resolve(ctx, q, col, code) != nil {
+		return ""
+	}
+	if !createMissing || !creatableRefs[col] {
+		return "unknown " + refLabels[col] + " " + code
+	}
+	for _, c := range r.missing[col] {
+		if c == code {
+			return ""
+		}
+	}
+	r.missing[col] = append(r.missing[col], code)
+	return ""
+}
+
+// id returns the resolved ID of a code, nil for an empty code.
+func (r *catalogRefs) id(col, code string) *int32 {
+	if code == "" {
+		return nil
+	}
+	return r.ids[col+"\x00"+code]
+}
+
+// createMissing creates the categories, brands and units of measure found
+// missing while validating, named after their code.
+func (r *catalogRefs) createMissing(ctx context.Context, q *repository.Queries, sum *CatalogImportSummary) error {
+	meta := []byte(`{"source":"import"}`)
+	active := pgtype.Bool{Bool: true, Valid: true}
+	for _, code := range r.missing["category_code"] {
+		c, err := q.CreateProductCategory(ctx, repository.CreateProductCategoryParams{
+			Name:          code,
+			Code:          code,
+			CategoryLevel: pgtype.Int4{Int32: 1, Valid: true},
+			IsActive:      active,
+			Metadata:      meta,
+		})
+		if err != nil {
+			return fmt.Errorf("create category %s: %w", code, err)
+		}
+		r.ids["category_code\x00"+code] = &c.ID
+		sum.CategoriesCreated++
+	}
+	for _, code := range r.missing["brand_code"] {
+		b, err := q.CreateBrand(ctx, repository.CreateBrandParams{Name: code, Code: code, IsActive: active, Metadata: meta})
+		if err != nil {
+			return fmt.Errorf("create brand %s: %w", code, err)
+		}
+		r.ids["brand_code\x00"+code] = &b.ID
+		sum.BrandsCreated++
+	}
+	for _, code := range r.missing["uom_code"] {
+		u, err := q.CreateUnitOfMeasure(ctx, repository.CreateUnitOfMeasureParams{
+			Code:          code,
+			Name:          code,
+			DecimalPlaces: pgtype.Int4{Int32: 2, Valid: true},
+			IsActive:      active,
+			Metadata:      meta,
+		})
+		if err != nil {
+			return fmt.Errorf("create unit of measure %s: %w", code, err)
+		}
+		r.ids["uom_code\x00"+code] = &u.ID
+		sum.UnitsCreated++
+	}
+	return nil
+}
+
+// importCatalog validates the job's file and, unless it is a dry run,
+// writes its products in batches. An error fails the whole job; problems
+// with rows are recorded on imp instead.
+func (uc *CatalogUseCase) importCatalog(ctx context.Context, imp *catalogImport) error {
+	if err := json.Unmarshal(imp.job.Options, &imp.settings); err != nil || imp.settings.BatchSize <= 0 {
+		imp.settings.BatchSize = catalogImportBatchSize
+	}
+	rows, err := spreadsheet.Read(imp.job.FileName, bytes.NewReader(imp.job.InputFile))
+	if err != nil {
+		return err
+	}
+	if len(rows) < 2 {
+		return errors.New("file has no data rows")
+	}
+	uc.parseCatalogRows(ctx, imp, rows, spreadsheet.Header(rows[0]))
+
+	var valid []*importProduct
+	for _, p := range imp.products {
+		if p.invalid {
+			imp.failed += len(p.lines)
+			continue
+		}
+		valid = append(valid, p)
+	}
+	imp.processed = imp.failed
+	if imp.failed > 0 && !imp.settings.SkipInvalid {
+		imp.failed, imp.processed = imp.total, imp.total
+		return errors.New("file has errors; nothing was imported")
+	}
+
+	if imp.job.DryRun {
+		for _, p := range valid {
+			p.count(&imp.summary)
+			imp.succeeded += len(p.lines)
+		}
+		imp.summary.CategoriesCreated = len(imp.refs.missing["category_code"])
+		imp.summary.BrandsCreated = len(imp.refs.missing["brand_code"])
+		imp.summary.UnitsCreated = len(imp.refs.missing["uom_code"])
+		imp.processed = imp.total
+		return nil
+	}
+
+	if len(valid) > 0 {
+		err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+			return imp.refs.createMissing(ctx, q, &imp.summary)
+		})
+		if err != nil {
+			imp.summary = CatalogImportSummary{}
+			return err
+		}
+	}
+	for start := 0; start < len(valid); start += imp.settings.BatchSize {
+		batch := valid[start:min(start+imp.settings.BatchSize, len(valid))]
+		lines := 0
+		for _, p := range batch {
+			lines += len(p.lines)
+		}
+		var failed *importProduct
+		err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+			for _, p := range batch {
+				if err := writeImportProduct(ctx, q, imp, p); err != nil {
+					failed = p
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			msg := "sku " + failed.sku + ": " + importErrorMessage(err)
+			if len(batch) > 1 {
+				msg += fmt.Sprintf("; the other %d products of its batch were rolled back", len(batch)-1)
+			}
+			imp.addErrors(ImportRowError{Row: failed.lines[0], Message: msg})
+			imp.failed += lines
+		} else {
+			for _, p := range batch {
+				p.count(&imp.summary)
+			}
+			imp.succeeded += lines
+		}
+		imp.processed += lines
+		if err := uc.repo.UpdateCatalogImportProgress(ctx, repository.UpdateCatalogImportProgressParams{
+			ID:            imp.job.ID,
+			TotalRows:     int32(imp.total),
+			ProcessedRows: int32(imp.processed),
+			SucceededRows: int32(imp.succeeded),
+			FailedRows:    int32(imp.failed),
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseCatalogRows groups the data rows by SKU and validates them against
+// the database, recording an error per bad cell. A product with any bad
+// row is marked invalid as a whole.
+func (uc *CatalogUseCase) parseCatalogRows(ctx context.Context, imp *catalogImport, rows [][]string, header map[string]int) {
+	orgID := imp.job.OrganizationID
+	refs := imp.refs
+	createMissing := imp.settings.CreateMissing
+	bySKU := map[string]*importProduct{}
+	variantOwner := map[string]*importProduct{}
+	barcodeTarget := map[string]string{}
+	stockLine := map[string]int{}
+
+	for i := 1; i < len(rows); i++ {
+		row, line := rows[i], i+1
+		if spreadsheet.IsBlank(row) {
+			continue
+		}
+		imp.total++
+		cell := func(name string) string { return spreadsheet.Cell(row, header, name) }
+		var errs []ImportRowError
+		fail := func(col, msg string) { errs = append(errs, ImportRowError{Row: line, Column: col, Message: msg}) }
+
+		sku := cell("sku")
+		if sku == "" {
+			imp.addErrors(ImportRowError{Row: line, Column: "sku", Message: "sku is required"})
+			imp.failed++
+			continue
+		}
+		p := bySKU[sku]
+		if p == nil {
+			p = &importProduct{sku: sku, fields: map[string]string{}, fieldLine: map[string]int{}, byVariant: map[string]*importVariant{}}
+			if existing, err := uc.repo.GetProductBySKU(ctx, repository.GetProductBySKUParams{OrganizationID: orgID, Sku: sku}); err == nil {
+				p.existing = &existing
+			}
+			bySKU[sku] = p
+			imp.products = append(imp.products, p)
+		}
+		p.lines = append(p.lines, line)
+
+		for _, col := range catalog.ProductColumns {
+			v := cell(col)
+			if v == "" {
+				continue
+			}
+			if prev, ok := p.fields[col]; ok {
+				if prev != v {
+					fail(col, fmt.Sprintf("differs from row %d (%q)", p.fieldLine[col], prev))
+				}
+				continue
+			}
+			p.fields[col] = v
+			p.fieldLine[col] = line
+			if strings.HasSuffix(col, "_code") {
+				if msg := refs.check(ctx, uc.repo, col, v, createMissing); msg != "" {
+					fail(col, msg)
+				}
+			} else if strings.HasPrefix(col, "is_") || col == "allow_decimal_quantity" || col == "track_inventory" {
+				if _, err := catalog.ParseBool(v); err != nil {
+					fail(col, err.Error())
+				}
+			}
+		}
+
+		item := importItem{line: line}
+		if vs := cell("variant_sku"); vs != "" {
+			if owner := variantOwner[vs]; owner != nil && owner != p {
+				fail("variant_sku", "variant "+vs+" is also given for sku "+owner.sku)
+			} else {
+				v := p.byVariant[vs]
+				if v == nil {
+					v = &importVariant{sku: vs, line: line}
+					if existing, err := uc.repo.GetProductVariantBySKU(ctx, vs); err == nil {
+						if p.existing == nil || existing.ProductID != p.existing.ID {
+							fail("variant_sku", "variant "+vs+" belongs to another product")
+						}
+						v.existing = &existing
+					}
+					variantOwner[vs] = p
+					p.byVariant[vs] = v
+					p.variants = append(p.variants, v)
+				}
+				if name := cell("variant_name"); name != "" {
+					if v.name != "" && v.name != name {
+						fail("variant_name", "differs from an earlier row of variant "+vs)
+					}
+					v.name = name
+				}
+				if s := cell("variant_attributes"); s != "" {
+					attrs, err := catalog.ParseAttributes(s)
+					if err != nil {
+						fail("variant_attributes", err.Error())
+					} else if v.attrs != nil && !catalog.SameAttributes(v.attrs, attrs) {
+						fail("variant_attributes", "differs from an earlier row of variant "+vs)
+					} else {
+						v.attrs = attrs
+					}
+				}
+				item.variant = v
+			}
+		} else if cell("variant_name") != "" || cell("variant_attributes") != "" {
+			fail("variant_sku", "variant_sku is required with variant_name or variant_attributes")
+		}
+
+		if code := cell("barcode"); code != "" {
+			target := sku + "\x00" + cell("variant_sku")
+			if prev, seen := barcodeTarget[code]; seen {
+				if prev != target {
+					fail("barcode", "barcode "+code+" is given for two products or variants")
+				}
+			} else {
+				barcodeTarget[code] = target
+				item.barcode, item.barcodeType = code, cell("barcode_type")
+				if b, err := uc.repo.GetProductByBarcode(ctx, code); err == nil {
+					if !sameBarcodeTarget(b, p, item.variant) {
+						fail("barcode", "barcode "+code+" is already used by sku "+b.Sku)
+					}
+					item.barcode = ""
+				}
+			}
+		}
+
+		if price := cell("price"); price != "" {
+			listCode := cell("price_list_code")
+			if _, err := parseAmount("price", price); err != nil {
+				fail("price", err.Error())
+			} else if id := refs.resolve(ctx, uc.repo, "price_list_code", listCode); id == nil {
+				if listCode == "" {
+					fail("price_list_code", "no default price list; give price_list_code")
+				} else {
+					fail("price_list_code", "unknown price list "+listCode)
+				}
+			} else {
+				item.priceListID, item.price = *id, price
+			}
+		} else if cell("price_list_code") != "" {
+			fail("price", "price is required with price_list_code")
+		}
+
+		storeCode, qty := cell("store_code"), cell("opening_quantity")
+		switch {
+		case storeCode == "" && qty == "":
+		case storeCode == "":
+			fail("store_code", "store_code is required with opening_quantity")
+		case qty == "":
+			fail("opening_quantity", "opening_quantity is required with store_code")
+		default:
+			r, ok := new(big.Rat).SetString(qty)
+			id := refs.resolve(ctx, uc.repo, "store_code", storeCode)
+			key := sku + "\x00" + cell("variant_sku") + "\x00" + storeCode
+			switch {
+			case !ok || r.Sign() <= 0:
+				fail("opening_quantity", "invalid quantity "+qty)
+			case id == nil:
+				fail("store_code", "unknown store "+storeCode)
+			case stockLine[key] != 0:
+				fail("opening_quantity", fmt.Sprintf("opening stock for this store is already given on row %d", stockLine[key]))
+			default:
+				stockLine[key] = line
+				item.storeID, item.quantity = *id, r
+				if p.existing != nil && (item.variant == nil || item.variant.existing != nil) {
+					arg := repository.HasInventoryStockParams{ProductID: p.existing.ID, StoreID: *id}
+					if item.variant != nil {
+						arg.ProductVariantID = pgtype.Int4{Int32: item.variant.existing.ID, Valid: true}
+					}
+					if has, err := uc.repo.HasInventoryStock(ctx, arg); err == nil && has {
+						fail("opening_quantity", "store "+storeCode+" already has stock of this item")
+					}
+				}
+			}
+		}
+
+		p.items = append(p.items, item)
+		if len(errs) > 0 {
+			p.invalid = true
+			imp.addErrors(errs...)
+		}
+	}
+
+	for _, p := range imp.products {
+		var current repository.Product
+		if p.existing != nil {
+			current = *p.existing
+		}
+		fail := func(line int, col, msg string) {
+			p.invalid = true
+			imp.addErrors(ImportRowError{Row: line, Column: col, Message: msg})
+		}
+		if p.existing == nil && p.fields["name"] == "" {
+			fail(p.lines[0], "name", "name is required for a new product")
+		}
+		for _, v := range p.variants {
+			if v.existing == nil && len(v.attrs) == 0 {
+				fail(v.line, "variant_attributes", "variant_attributes is required for new variant "+v.sku)
+			}
+		}
+		serialized := p.flag("is_serialized", current.IsSerialized) || p.flag("is_batch_managed", current.IsBatchManaged)
+		decimals := p.flag("allow_decimal_quantity", current.AllowDecimalQuantity)
+		for _, it := range p.items {
+			if it.quantity == nil {
+				continue
+			}
+			if serialized {
+				fail(it.line, "opening_quantity", "opening stock of serialized or batch-managed products must be received with their serials or batches")
+			} else if !it.quantity.IsInt() && !decimals {
+				fail(it.line, "opening_quantity", "product does not allow decimal quantities")
+			}
+		}
+	}
+}
+
+// sameBarcodeTarget reports whether an existing barcode already belongs to
+// the product and variant a row gives it to.
+func sameBarcodeTarget(b repository.GetProductByBarcodeRow, p *importProduct, v *importVariant) bool {
+	if p.existing == nil || b.ProductID != p.existing.ID {
+		return false
+	}
+	if v == nil {
+		return !b.ProductVariantID.Valid
+	}
+	return v.existing != nil && b.ProductVariantID.Valid && b.ProductVariantID.Int32 == v.existing.ID
+}
+
+// writeImportProduct creates or updates one product with its variants,
+// barcodes, prices and opening stock.
+func writeImportProduct(ctx context.Context, q *repository.Queries, imp *catalogImport, p *importProduct) error {
+	in := p.productInput(imp.job.OrganizationID, imp.refs)
+	var product repository.Product
+	var err error
+	switch {
+	case p.existing == nil:
+		product, err = q.CreateProduct(ctx, createProductParams(&in))
+	case len(p.fields) > 0:
+		product, err = q.UpdateProduct(ctx, updateProductParams(p.existing.ID, &in))
+	default:
+		product = *p.existing
+	}
+	if err != nil {
+		return err
+	}
+
+	variantIDs := map[*importVariant]pgtype.Int4{}
+	for _, v := range p.variants {
+		if v.existing != nil {
+			variantIDs[v] = pgtype.Int4{Int32: v.existing.ID, Valid: true}
+			continue
+		}
+		name := v.name
+		if name == "" {
+			name = product.Name
+		}
+		created, err := createVariant(ctx, q, product, catalog.Variant{SKU: v.sku, Name: name, Attributes: v.attrs}, nil)
+		if err != nil {
+			return err
+		}
+		variantIDs[v] = pgtype.Int4{Int32: created.ID, Valid: true}
+	}
+	variants, err := q.ListProductVariantsByProduct(ctx, product.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, it := range p.items {
+		var variantID pgtype.Int4
+		if it.variant != nil {
+			variantID = variantIDs[it.variant]
+		}
+		if it.barcode != "" {
+			b := BarcodeInput{Barcode: it.barcode, BarcodeType: it.barcodeType}
+			if variantID.Valid {
+				b.VariantID = &variantID.Int32
+			}
+			if _, err := addBarcode(ctx, q, product, variants, b); err != nil {
+				return err
+			}
+		}
+		if it.price != "" {
+			v := &priceVersion{
+				priceListID: it.priceListID,
+				productID:   product.ID,
+				variantID:   variantID,
+				uomID:       product.BaseUomID,
+				metadata:    []byte(`{"source":"import"}`),
+			}
+			if msg := v.setAmounts(it.price, "", ""); msg != "" {
+				return documentInputErrorf("row %d: %s", it.line, msg)
+			}
+			if _, err := applyPriceVersion(ctx, q, v); err != nil {
+				return err
+			}
+		}
+		if it.quantity != nil {
+			if err := postOpeningStock(ctx, q, imp.job, product, variantID, it); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// postOpeningStock opens a store's stock of an item with an opening
+// balance movement referencing the import job.
+func postOpeningStock(ctx context.Context, q *repository.Queries, job repository.CatalogImportJob, product repository.Product, variantID pgtype.Int4, it importItem) error {
+	has, err := q.HasInventoryStock(ctx, repository.HasInventoryStockParams{
+		ProductID:        product.ID,
+		StoreID:          it.storeID,
+		ProductVariantID: variantID,
+	})
+	if err != nil {
+		return err
+	}
+	if has {
+		return documentInputErrorf("row %d: the store already has stock of this item", it.line)
+	}
+	if err := addStock(ctx, q, product.ID, variantID, it.storeID, it.quantity); err != nil {
+		return err
+	}
+	_, err = q.CreateStockMovement(ctx, repository.CreateStockMovementParams{
+		MovementType:     "opening_balance",
+		ReferenceType:    optionalText("catalog_import"),
+		ReferenceID:      pgtype.Int4{Int32: job.ID, Valid: true},
+		ProductID:        product.ID,
+		ProductVariantID: variantID,
+		ToStoreID:        pgtype.Int4{Int32: it.storeID, Valid: true},
+		Quantity:         utils.RatToNumeric(it.quantity, 3),
+		UomID:            product.BaseUomID,
+		MovementDate:     pgtype.Timestamp{Time: time.Now(), Valid: true},
+		PostedBy:         job.CreatedBy,
+		Status:           optionalText("completed"),
+		Metadata:         []byte("{}"),
+	})
+	return err
+}
+
+// importErrorMessage describes a write error for the row error list.
+func importErrorMessage(err error) string {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		if pgErr.Detail != "" {
+			return pgErr.Message + ": " + pgErr.Detail
+		}
+		return pgErr.Message
+	}
+	return err.Error()
+}
+
+// CatalogExportOptions select what ExportCatalog writes.
+type CatalogExportOptions struct {
+	OrganizationID int32
+	// Format is csv or xlsx.
+	Format string
+	// PriceListCode is the list whose current prices fill the price
+	// column; the default list when empty.
+	PriceListCode string
+	// StoreCode, when given, fills opening_quantity with the store's
+	// quantity on hand.
+	StoreCode string
+}
+
+// CatalogExport is an exported catalog file.
+type CatalogExport struct {
+	Filename    string
+	ContentType string
+	Body        []byte
+}
+
+// ExportCatalog writes an organization's catalog in the import layout: a
+// row per product, a row per variant and a row per further barcode, so an
+// edited export can be imported again.
+func (uc *CatalogUseCase) ExportCatalog(ctx context.Context, opt CatalogExportOptions) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if opt.Format == "" {
+		opt.Format = spreadsheet.FormatCSV
+	}
+	if opt.Format != spreadsheet.FormatCSV && opt.Format != spreadsheet.FormatXLSX {
+		return utils.NewResponse(utils.CodeBadReq, spreadsheet.ErrUnsupportedFormat.Error(), nil)
+	}
+	orgID, resp := activeOrganization(ctx, opt.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	opt.OrganizationID = orgID
+	if _, err := uc.repo.GetOrganization(ctx, opt.OrganizationID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "organization not found", nil)
+	}
+	refs := newCatalogRefs(opt.OrganizationID)
+	listID := refs.resolve(ctx, uc.repo, "price_list_code", opt.PriceListCode)
+	if listID == nil && opt.PriceListCode != "" {
+		return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	listCode := opt.PriceListCode
+	if listID != nil && listCode == "" {
+		if pl, err := uc.repo.GetPriceList(ctx, *listID); err == nil {
+			listCode = pl.Code
+		}
+	}
+
+	items, err := uc.repo.ListCatalogExportItems(ctx, opt.OrganizationID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	barcodes, err := uc.repo.ListCatalogExportBarcodes(ctx, opt.OrganizationID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	codes := map[exportKey][]repository.ListCatalogExportBarcodesRow{}
+	for _, b := range barcodes {
+		k := exportKey{b.ProductID, b.ProductVariantID.Int32}
+		codes[k] = append(codes[k], b)
+	}
+	prices := map[exportKey]string{}
+	if listID != nil {
+		rows, err := uc.repo.ListCatalogExportPrices(ctx, repository.ListCatalogExportPricesParams{OrganizationID: opt.OrganizationID, PriceListID: *listID})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		for _, r := range rows {
+			prices[exportKey{r.ProductID, r.ProductVariantID.Int32}] = utils.NumericToRat(r.Price).FloatString(2)
+		}
+	}
+	stock := map[exportKey]string{}
+	if opt.StoreCode != "" {
+		storeID := refs.resolve(ctx, uc.repo, "store_code", opt.StoreCode)
+		if storeID == nil {
+			return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+		}
+		rows, err := uc.repo.ListCatalogExportStock(ctx, repository.ListCatalogExportStockParams{OrganizationID: opt.OrganizationID, StoreID: *storeID})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		for _, r := range rows {
+			stock[exportKey{r.ProductID, r.ProductVariantID.Int32}] = strings.TrimRight(strings.TrimRight(utils.NumericToRat(r.Quantity).FloatString(3), "0"), ".")
+		}
+	}
+
+	out := [][]string{catalog.Columns}
+	col := map[string]int{}
+	for i, name := range catalog.Columns {
+		col[name] = i
+	}
+	emit := func(it repository.ListCatalogExportItemsRow, k exportKey, withVariant bool) {
+		row := make([]string, len(catalog.Columns))
+		row[col["sku"]] = it.Sku
+		row[col["name"]] = it.Name
+		row[col["description"]] = it.Description.String
+		row[col["product_type"]] = it.ProductType.String
+		row[col["category_code"]] = it.CategoryCode.String
+		row[col["brand_code"]] = it.BrandCode.String
+		row[col["uom_code"]] = it.UomCode.String
+		row[col["tax_category_code"]] = it.TaxCategoryCode.String
+		for name, v := range map[string]pgtype.Bool{
+			"is_serialized":          it.IsSerialized,
+			"is_batch_managed":       it.IsBatchManaged,
+			"is_active":              it.IsActive,
+			"is_sellable":            it.IsSellable,
+			"is_purchasable":         it.IsPurchasable,
+			"allow_decimal_quantity": it.AllowDecimalQuantity,
+			"track_inventory":        it.TrackInventory,
+		} {
+			if v.Valid {
+				row[col[name]] = fmt.Sprint(v.Bool)
+			}
+		}
+		if withVariant {
+			row[col["variant_sku"]] = it.VariantSku.String
+			row[col["variant_name"]] = it.VariantName.String
+			var attrs map[string]string
+			if json.Unmarshal(it.VariantAttributes, &attrs) == nil {
+				row[col["variant_attributes"]] = catalog.FormatAttributes(attrs)
+			}
+		}
+		if p, ok := prices[k]; ok {
+			row[col["price_list_code"]] = listCode
+			row[col["price"]] = p
+		}
+		if q, ok := stock[k]; ok {
+			row[col["store_code"]] = opt.StoreCode
+			row[col["opening_quantity"]] = q
+		}
+		bs := codes[k]
+		if len(bs) > 0 {
+			row[col["barcode"]], row[col["barcode_type"]] = bs[0].Barcode, bs[0].BarcodeType.String
+		}
+		out = append(out, row)
+		for _, b := range bs[min(1, len(bs)):] {
+			extra := make([]string, len(catalog.Columns))
+			extra[col["sku"]] = it.Sku
+			if withVariant {
+				extra[col["variant_sku"]] = it.VariantSku.String
+			}
+			extra[col["barcode"]], extra[col["barcode_type"]] = b.Barcode, b.BarcodeType.String
+			out = append(out, extra)
+		}
+	}
+	for i, it := range items {
+		if i == 0 || items[i-1].ProductID != it.ProductID {
+			emit(it, exportKey{it.ProductID, 0}, false)
+		}
+		if it.VariantID.Valid {
+			emit(it, exportKey{it.ProductID, it.VariantID.Int32}, true)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := spreadsheet.Write(&buf, opt.Format, out); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "catalog exported", &CatalogExport{
+		Filename:    "catalog-" + time.Now().Format("20060102") + "." + opt.Format,
+		ContentType: spreadsheet.ContentType(opt.Format),
+		Body:        buf.Bytes(),
+	})
+}
+
+// exportKey identifies a product (variant 0) or one of its variants.
+type exportKey struct {
+	productID int32
+	variantID int32
+}
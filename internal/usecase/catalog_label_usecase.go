@@ -0,0 +1,330 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/barcode"
+	"NEMBUS/internal/labels"
+	"NEMBUS/internal/pricing"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// GenerateBarcodesInput is the input for GenerateBarcodes.
+type GenerateBarcodesInput struct {
+	OrganizationID int32
+	// ProductIDs limits generation to these products; empty means every
+	// active product of the organization.
+	ProductIDs []int32
+	// Symbology is ean13 (default) or code128.
+	Symbology string
+	// Prefix starts generated EAN-13 codes; an in-store prefix, 20 by
+	// default.
+	Prefix string
+}
+
+// GenerateBarcodesResult lists the barcodes created and the items skipped.
+type GenerateBarcodesResult struct {
+	Created []repository.ProductBarcode `json:"created"`
+	Skipped []string                    `json:"skipped,omitempty"`
+}
+
+// maxBarcodeAttempts bounds the search for a free code per item.
+const maxBarcodeAttempts = 1000
+
+// GenerateBarcodes gives every active product without variants and every
+// active variant that has no barcode an internal one: an EAN-13 numbered
+// from the tenant's sequence behind an in-store prefix, or a Code 128 of
+// the SKU. Codes already in use are skipped, so codes stay unique in the
+// tenant.
+func (uc *CatalogUseCase) GenerateBarcodes(ctx context.Context, in *GenerateBarcodesInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	symbology := barcode.Symbology(strings.ToLower(strings.TrimSpace(in.Symbology)))
+	if symbology == "" {
+		symbology = barcode.EAN13
+	}
+	if symbology != barcode.EAN13 && symbology != barcode.Code128 {
+		return utils.NewResponse(utils.CodeBadReq, "symbology must be ean13 or code128", nil)
+	}
+	prefix := strings.TrimSpace(in.Prefix)
+	if prefix == "" {
+		prefix = "20"
+	}
+	if symbology == barcode.EAN13 {
+		if len(prefix) < 2 || len(prefix) > 3 || prefix[0] != '2' || strings.Trim(prefix, "0123456789") != "" {
+			return utils.NewResponse(utils.CodeBadReq, "prefix must be an in-store prefix of 2 or 3 digits starting with 2", nil)
+		}
+		// Scale barcodes under a rule's prefix would be decoded as weights or
+		// prices at the till.
+		rules, err := uc.repo.ListActiveBarcodeRules(ctx)
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		for _, r := range rules {
+			if strings.HasPrefix(prefix, r.Prefix) || strings.HasPrefix(r.Prefix, prefix) {
+				return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("prefix %s overlaps barcode rule %s (%s)", prefix, r.Prefix, r.Name), nil)
+			}
+		}
+	}
+	orgID, resp := activeOrganization(ctx, in.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	in.OrganizationID = orgID
+	if _, err := uc.repo.GetOrganization(ctx, in.OrganizationID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "organization not found", nil)
+	}
+
+	result := &GenerateBarcodesResult{Created: []repository.ProductBarcode{}}
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		arg := repository.ListItemsWithoutBarcodeParams{OrganizationID: in.OrganizationID}
+		if len(in.ProductIDs) > 0 {
+			arg.ProductIds = in.ProductIDs
+		}
+		items, err := q.ListItemsWithoutBarcode(ctx, arg)
+		if err != nil {
+			return err
+		}
+		for _, it := range items {
+			code, err := freeBarcode(ctx, q, symbology, prefix, it.Sku)
+			if err != nil {
+				return err
+			}
+			if code == "" {
+				result.Skipped = append(result.Skipped, fmt.Sprintf("%s: no free %s barcode", it.Sku, symbology))
+				continue
+			}
+			product := repository.Product{ID: it.ProductID, Sku: it.Sku}
+			bi := BarcodeInput{Barcode: code, BarcodeType: string(symbology)}
+			var variants []repository.ProductVariant
+			if it.ProductVariantID.Valid {
+				variants = []repository.ProductVariant{{ID: it.ProductVariantID.Int32, ProductID: it.ProductID, VariantSku: it.Sku}}
+				bi.VariantID = &it.ProductVariantID.Int32
+			}
+			b, err := addBarcode(ctx, q, product, variants, bi)
+			if err != nil {
+				return err
+			}
+			result.Created = append(result.Created, b)
+		}
+		return nil
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, fmt.Sprintf("%d barcodes generated", len(result.Created)), result)
+}
+
+// freeBarcode returns the next unused code in the symbology, or "" when
+// none is found: the next sequence number for EAN-13, the SKU or SKU-2,
+// SKU-3... for Code 128.
+func freeBarcode(ctx context.Context, q *repository.Queries, symbology barcode.Symbology, prefix, sku string) (string, error) {
+	for i := 1; i <= maxBarcodeAttempts; i++ {
+		var code string
+		if symbology == barcode.EAN13 {
+			n, err := q.NextInternalBarcodeNumber(ctx)
+			if err != nil {
+				return "", err
+			}
+			var ok bool
+			if code, ok = barcode.NewEAN13(prefix, n); !ok {
+				return "", documentInputErrorf("internal barcode numbers under prefix %s are exhausted", prefix)
+			}
+		} else {
+			code = sku
+			if i > 1 {
+				code = fmt.Sprintf("%s-%d", sku, i)
+			}
+			if _, err := barcode.Modules(barcode.Code128, code); err != nil {
+				return "", nil
+			}
+		}
+		exists, err := q.CheckBarcodeExists(ctx, code)
+		if err != nil {
+			return "", err
+		}
+		if !exists {
+			return code, nil
+		}
+	}
+	return "", nil
+}
+
+// LabelInput is the input for RenderLabels. Products come from ProductIDs,
+// from the price change batch of PriceListID on EffectiveDate, or both.
+type LabelInput struct {
+	OrganizationID int32
+	ProductIDs     []int32
+	PriceListID    *int32
+	// EffectiveDate prices the labels and picks the batch; today by default.
+	EffectiveDate *time.Time
+	// StoreID applies the store's price lists.
+	StoreID  *int32
+	Template string
+	Format   string
+	// Copies of each label, 1 by default.
+	Copies int
+}
+
+// maxLabelCopies bounds the copies of each label in one request.
+const maxLabelCopies = 100
+
+// RenderLabels renders shelf labels or price tags for products and their
+// active variants with the price in effect on the date. When a promotion
+// sets the price the regular price is shown crossed out next to it. The
+// rendered file is returned as *labels.Output.
+func (uc *CatalogUseCase) RenderLabels(ctx context.Context, in *LabelInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	tpl, err := labels.ParseTemplate(in.Template)
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	format, err := labels.ParseFormat(in.Format)
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	orgID, resp := activeOrganization(ctx, in.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	in.OrganizationID = orgID
+	copies := in.Copies
+	if copies <= 0 {
+		copies = 1
+	}
+	if copies > maxLabelCopies {
+		return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("copies must be at most %d", maxLabelCopies), nil)
+	}
+	date := time.Now().Truncate(24 * time.Hour)
+	if in.EffectiveDate != nil {
+		date = *in.EffectiveDate
+	}
+
+	ids := append([]int32(nil), in.ProductIDs...)
+	if in.PriceListID != nil {
+		if _, err := uc.repo.GetPriceList(ctx, *in.PriceListID); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+		}
+		batch, err := uc.repo.ListPriceChangeProductIDs(ctx, repository.ListPriceChangeProductIDsParams{
+			PriceListID: *in.PriceListID,
+			ValidFrom:   pgtype.Date{Time: date, Valid: true},
+		})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		if len(batch) == 0 && len(ids) == 0 {
+			return utils.NewResponse(utils.CodeNotFound, fmt.Sprintf("no price changes in price list %d on %s", *in.PriceListID, date.Format("2006-01-02")), nil)
+		}
+		ids = append(ids, batch...)
+	}
+	if len(ids) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "product_ids or price_list_id is required", nil)
+	}
+	items, err := uc.repo.ListLabelItems(ctx, repository.ListLabelItemsParams{OrganizationID: in.OrganizationID, ProductIds: ids})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if len(items) == 0 {
+		return utils.NewResponse(utils.CodeNotFound, "no active products to label", nil)
+	}
+
+	sc := saleContext{date: date}
+	if in.StoreID != nil {
+		sc.storeID = *in.StoreID
+	}
+	r, err := newPriceResolver(ctx, uc.repo, sc)
+	if err != nil {
+		return catalogError(err)
+	}
+	productIDs := make([]int32, len(items))
+	for i, it := range items {
+		productIDs[i] = it.ProductID
+	}
+	if err := r.load(ctx, productIDs...); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	lists := map[int32]repository.PriceList{}
+	var out []labels.Label
+	for _, it := range items {
+		l, err := uc.label(ctx, r, lists, it)
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		for i := 0; i < copies; i++ {
+			out = append(out, l)
+		}
+	}
+	rendered, err := labels.Render(out, tpl, format)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "labels rendered", rendered)
+}
+
+// label fills one label, pricing the item with r; lists caches the price
+// lists looked up for their currency and promotion end.
+func (uc *CatalogUseCase) label(ctx context.Context, r *priceResolver, lists map[int32]repository.PriceList, it repository.ListLabelItemsRow) (labels.Label, error) {
+	l := labels.Label{Name: it.Name, SKU: it.Sku, Barcode: it.Barcode.String}
+	var detail []string
+	if it.VariantName.Valid && it.VariantName.String != "" {
+		detail = append(detail, it.VariantName.String)
+	}
+	if it.UomCode.Valid {
+		detail = append(detail, "per "+it.UomCode.String)
+	}
+	l.Detail = strings.Join(detail, " / ")
+
+	req := pricing.Request{
+		ProductID: it.ProductID,
+		VariantID: it.ProductVariantID.Int32,
+		BaseUomID: it.BaseUomID.Int32,
+		Quantity:  big.NewRat(1, 1),
+		Date:      r.date,
+	}
+	res, err := pricing.Resolve(r.lists, r.prices, req)
+	if errors.Is(err, pricing.ErrNoPrice) {
+		return l, nil
+	}
+	if err != nil {
+		return l, err
+	}
+	pl, ok := lists[res.PriceListID]
+	if !ok {
+		if pl, err = uc.repo.GetPriceList(ctx, res.PriceListID); err != nil {
+			return l, err
+		}
+		lists[res.PriceListID] = pl
+	}
+	l.Price = res.Price.FloatString(2)
+	l.Currency = pl.CurrencyCode.String
+	if res.Source != pricing.SourcePromotion {
+		return l, nil
+	}
+
+	// The regular price is what the lists other than promotions charge.
+	var regular []pricing.List
+	for _, list := range r.lists {
+		if list.Source != pricing.SourcePromotion {
+			regular = append(regular, list)
+		}
+	}
+	if reg, err := pricing.Resolve(regular, r.prices, req); err == nil && reg.Price.Cmp(res.Price) > 0 {
+		l.RegularPrice = reg.Price.FloatString(2)
+	}
+	l.PromoText = "PROMO"
+	if pl.ValidTo.Valid {
+		l.PromoText += " until " + pl.ValidTo.Time.Format("02/01")
+	}
+	return l, nil
+}
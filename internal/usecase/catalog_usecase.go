@@ -0,0 +1,941 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+
+	"NEMBUS/internal/catalog"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// CatalogUseCase manages products with their variants, barcodes and unit
+// of measure conversions.
+type CatalogUseCase struct {
+	repo *repository.Queries
+}
+
+// NewCatalogUseCase creates a new catalog use case.
+func NewCatalogUseCase() *CatalogUseCase {
+	return &CatalogUseCase{}
+}
+
+// SetRepository injects repository per request
+func (uc *CatalogUseCase) SetRepository(repo *repository.Queries) {
+	uc.repo = repo
+}
+
+// ProductInput holds the product fields; nil fields keep their default on
+// create and their current value on update.
+type ProductInput struct {
+	OrganizationID       int32
+	SKU                  string
+	Name                 string
+	Description          *string
+	CategoryID           *int32
+	BrandID              *int32
+	BaseUomID            *int32
+	ProductType          *string
+	TaxCategoryID        *int32
+	IsSerialized         *bool
+	IsBatchManaged       *bool
+	IsActive             *bool
+	IsSellable           *bool
+	IsPurchasable        *bool
+	AllowDecimalQuantity *bool
+	TrackInventory       *bool
+	Metadata             map[string]interface{}
+}
+
+// VariantInput is one variant given explicitly rather than generated from
+// an attribute matrix.
+type VariantInput struct {
+	SKU        string
+	Name       string
+	Attributes map[string]string
+	IsActive   *bool
+}
+
+// BarcodeInput is a barcode of a product or of one of its variants, given
+// by ID or, while the product is being created, by SKU.
+type BarcodeInput struct {
+	Barcode     string
+	BarcodeType string
+	VariantID   *int32
+	VariantSKU  string
+	IsPrimary   bool
+}
+
+// UomConversionInput converts quantities of the product between two units.
+type UomConversionInput struct {
+	FromUomID        int32
+	ToUomID          int32
+	ConversionFactor string
+	IsDefault        bool
+}
+
+// CatalogPriceInput is a starting price of a new product, in the default
+// price list unless one is given.
+type CatalogPriceInput struct {
+	PriceListID *int32
+	VariantSKU  string
+	Price       string
+}
+
+// CreateProductInput is the input for CreateProduct. Variants are the
+// explicit ones plus every combination of Attributes.
+type CreateProductInput struct {
+	Product        ProductInput
+	Attributes     []catalog.Attribute
+	Variants       []VariantInput
+	Barcodes       []BarcodeInput
+	UomConversions []UomConversionInput
+	Prices         []CatalogPriceInput
+}
+
+// ProductDetail is a product with its variants, barcodes and unit
+// conversions.
+type ProductDetail struct {
+	Product        repository.Product                `json:"product"`
+	Variants       []repository.ProductVariant       `json:"variants"`
+	Barcodes       []repository.ProductBarcode       `json:"barcodes"`
+	UomConversions []repository.ProductUomConversion `json:"uom_conversions"`
+	Prices         []repository.ProductPrice         `json:"prices,omitempty"`
+}
+
+// ProductFilter selects products for ListProducts.
+type ProductFilter struct {
+	OrganizationID int32
+	Search         string
+	CategoryID     *int32
+	BrandID        *int32
+	ProductType    string
+	IsActive       *bool
+	Limit          int32
+	Offset         int32
+}
+
+// CreateProduct creates a product with its variants, barcodes, unit
+// conversions and starting prices in one database transaction; any failure
+// rolls back everything.
+func (uc *CatalogUseCase) CreateProduct(ctx context.Context, in *CreateProductInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	orgID, resp := activeOrganization(ctx, in.Product.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	in.Product.OrganizationID = orgID
+	var detail *ProductDetail
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		detail, err = createCatalogProduct(ctx, q, in)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "product created", detail)
+}
+
+// GetProduct returns a product with its variants, barcodes and unit
+// conversions.
+func (uc *CatalogUseCase) GetProduct(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	product, err := uc.repo.GetProduct(ctx, id)
+	if err != nil || !inActiveOrganization(ctx, product.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	detail, err := loadProductDetail(ctx, uc.repo, product)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "product retrieved successfully", detail)
+}
+
+// ListProducts returns an organization's products by name.
+func (uc *CatalogUseCase) ListProducts(ctx context.Context, f ProductFilter) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	orgID, resp := activeOrganization(ctx, f.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	arg := repository.ListProductsParams{
+		OrganizationID: orgID,
+		Limit:          f.Limit,
+		Offset:         f.Offset,
+		CategoryID:     optionalInt4(f.CategoryID),
+		BrandID:        optionalInt4(f.BrandID),
+		ProductType:    optionalText(f.ProductType),
+		Search:         optionalText(f.Search),
+	}
+	if f.IsActive != nil {
+		arg.IsActive = pgtype.Bool{Bool: *f.IsActive, Valid: true}
+	}
+	products, err := uc.repo.ListProducts(ctx, arg)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if products == nil {
+		products = []repository.Product{}
+	}
+	return utils.NewResponse(utils.CodeOK, "products retrieved successfully", products)
+}
+
+// UpdateProduct changes the given fields of a product. The SKU and
+// organization cannot change.
+func (uc *CatalogUseCase) UpdateProduct(ctx context.Context, id int32, in *ProductInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if product, err := uc.repo.GetProduct(ctx, id); err != nil || !inActiveOrganization(ctx, product.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	product, err := uc.repo.UpdateProduct(ctx, updateProductParams(id, in))
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "product updated successfully", product)
+}
+
+// DeleteProduct deletes a product with its variants, barcodes, conversions
+// and prices. A product already used on documents or in stock is
+// deactivated instead.
+func (uc *CatalogUseCase) DeleteProduct(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if product, err := uc.repo.GetProduct(ctx, id); err != nil || !inActiveOrganization(ctx, product.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		return q.DeleteProduct(ctx, id)
+	})
+	if isForeignKeyViolation(err) {
+		product, err := uc.repo.UpdateProduct(ctx, repository.UpdateProductParams{
+			ID:       id,
+			IsActive: pgtype.Bool{Bool: false, Valid: true},
+		})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeOK, "product is in use and was deactivated instead", product)
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "product deleted successfully", nil)
+}
+
+// GenerateVariantsResult lists the variants created and the combinations
+// skipped because the product already has them.
+type GenerateVariantsResult struct {
+	Created []repository.ProductVariant `json:"created"`
+	Skipped []string                    `json:"skipped"`
+}
+
+// GenerateVariants adds variants to a product: every combination of attrs
+// plus the explicit variants. Combinations the product already has, by SKU
+// or by attribute values, are skipped.
+func (uc *CatalogUseCase) GenerateVariants(ctx context.Context, productID int32, attrs []catalog.Attribute, explicit []VariantInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if len(attrs) == 0 && len(explicit) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "attributes or variants are required", nil)
+	}
+	product, err := uc.repo.GetProduct(ctx, productID)
+	if err != nil || !inActiveOrganization(ctx, product.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	result := &GenerateVariantsResult{Created: []repository.ProductVariant{}, Skipped: []string{}}
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		wanted, err := productVariants(product, attrs, explicit)
+		if err != nil {
+			return err
+		}
+		existing, err := q.ListProductVariantsByProduct(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		for _, v := range wanted {
+			if hasVariant(existing, v) {
+				result.Skipped = append(result.Skipped, v.SKU)
+				continue
+			}
+			created, err := createVariant(ctx, q, product, v, nil)
+			if err != nil {
+				return err
+			}
+			result.Created = append(result.Created, created)
+		}
+		return nil
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "variants generated", result)
+}
+
+// UpdateVariant renames a variant, replaces its attributes or toggles it;
+// empty fields keep their value.
+func (uc *CatalogUseCase) UpdateVariant(ctx context.Context, productID, variantID int32, in *VariantInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkProduct(ctx, productID); resp != nil {
+		return resp
+	}
+	v, err := uc.repo.GetProductVariant(ctx, variantID)
+	if err != nil || v.ProductID != productID {
+		return utils.NewResponse(utils.CodeNotFound, "variant not found", nil)
+	}
+	arg := repository.UpdateProductVariantParams{
+		ID:                v.ID,
+		VariantName:       v.VariantName,
+		VariantAttributes: v.VariantAttributes,
+		IsActive:          v.IsActive,
+		Metadata:          v.Metadata,
+	}
+	if strings.TrimSpace(in.Name) != "" {
+		arg.VariantName = optionalText(in.Name)
+	}
+	if len(in.Attributes) > 0 {
+		arg.VariantAttributes, _ = json.Marshal(in.Attributes)
+	}
+	if in.IsActive != nil {
+		arg.IsActive = pgtype.Bool{Bool: *in.IsActive, Valid: true}
+	}
+	updated, err := uc.repo.UpdateProductVariant(ctx, arg)
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "variant updated successfully", updated)
+}
+
+// DeleteVariant deletes a variant with its barcodes and prices. A variant
+// already used on documents or in stock is deactivated instead.
+func (uc *CatalogUseCase) DeleteVariant(ctx context.Context, productID, variantID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkProduct(ctx, productID); resp != nil {
+		return resp
+	}
+	v, err := uc.repo.GetProductVariant(ctx, variantID)
+	if err != nil || v.ProductID != productID {
+		return utils.NewResponse(utils.CodeNotFound, "variant not found", nil)
+	}
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		return q.DeleteProductVariant(ctx, v.ID)
+	})
+	if isForeignKeyViolation(err) {
+		updated, err := uc.repo.ToggleProductVariantActive(ctx, repository.ToggleProductVariantActiveParams{
+			ID:       v.ID,
+			IsActive: pgtype.Bool{Bool: false, Valid: true},
+		})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeOK, "variant is in use and was deactivated instead", updated)
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "variant deleted successfully", nil)
+}
+
+// AddBarcode adds a barcode to a product or one of its variants. The first
+// barcode of a product becomes its primary barcode.
+func (uc *CatalogUseCase) AddBarcode(ctx context.Context, productID int32, in *BarcodeInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	product, err := uc.repo.GetProduct(ctx, productID)
+	if err != nil || !inActiveOrganization(ctx, product.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	var barcode repository.ProductBarcode
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		variants, err := q.ListProductVariantsByProduct(ctx, product.ID)
+		if err != nil {
+			return err
+		}
+		barcode, err = addBarcode(ctx, q, product, variants, *in)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "barcode added", barcode)
+}
+
+// SetPrimaryBarcode makes a barcode the product's primary barcode.
+func (uc *CatalogUseCase) SetPrimaryBarcode(ctx context.Context, productID, barcodeID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkProduct(ctx, productID); resp != nil {
+		return resp
+	}
+	b, err := uc.repo.GetProductBarcode(ctx, barcodeID)
+	if err != nil || b.ProductID != productID {
+		return utils.NewResponse(utils.CodeNotFound, "barcode not found", nil)
+	}
+	if err := uc.repo.SetPrimaryBarcode(ctx, repository.SetPrimaryBarcodeParams{ProductID: productID, ID: b.ID}); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	barcodes, err := uc.repo.ListProductBarcodesByProduct(ctx, productID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "primary barcode set", barcodes)
+}
+
+// DeleteBarcode removes a barcode. When it was the primary barcode the
+// next remaining one becomes primary.
+func (uc *CatalogUseCase) DeleteBarcode(ctx context.Context, productID, barcodeID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkProduct(ctx, productID); resp != nil {
+		return resp
+	}
+	b, err := uc.repo.GetProductBarcode(ctx, barcodeID)
+	if err != nil || b.ProductID != productID {
+		return utils.NewResponse(utils.CodeNotFound, "barcode not found", nil)
+	}
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		if err := q.DeleteProductBarcode(ctx, b.ID); err != nil {
+			return err
+		}
+		if !b.IsPrimary.Bool {
+			return nil
+		}
+		rest, err := q.ListProductBarcodesByProduct(ctx, productID)
+		if err != nil || len(rest) == 0 {
+			return err
+		}
+		return q.SetPrimaryBarcode(ctx, repository.SetPrimaryBarcodeParams{ProductID: productID, ID: rest[0].ID})
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "barcode deleted successfully", nil)
+}
+
+// AddUomConversion adds a unit conversion to a product.
+func (uc *CatalogUseCase) AddUomConversion(ctx context.Context, productID int32, in *UomConversionInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	product, err := uc.repo.GetProduct(ctx, productID)
+	if err != nil || !inActiveOrganization(ctx, product.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	var conv repository.ProductUomConversion
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		conv, err = addUomConversion(ctx, q, product, *in)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "unit conversion added", conv)
+}
+
+// UpdateUomConversion changes a conversion's factor and default flag.
+func (uc *CatalogUseCase) UpdateUomConversion(ctx context.Context, productID, conversionID int32, in *UomConversionInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkProduct(ctx, productID); resp != nil {
+		return resp
+	}
+	factor, err := parseConversionFactor(in.ConversionFactor)
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	var conv repository.ProductUomConversion
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		current, err := productUomConversion(ctx, q, productID, conversionID)
+		if err != nil {
+			return err
+		}
+		if in.IsDefault {
+			if err := clearDefaultConversion(ctx, q, productID, current.ID); err != nil {
+				return err
+			}
+		}
+		conv, err = q.UpdateProductUOMConversion(ctx, repository.UpdateProductUOMConversionParams{
+			ID:               current.ID,
+			ConversionFactor: utils.RatToNumeric(factor, 6),
+			IsDefault:        pgtype.Bool{Bool: in.IsDefault, Valid: true},
+			Metadata:         current.Metadata,
+		})
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "unit conversion updated successfully", conv)
+}
+
+// DeleteUomConversion removes a unit conversion from a product.
+func (uc *CatalogUseCase) DeleteUomConversion(ctx context.Context, productID, conversionID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkProduct(ctx, productID); resp != nil {
+		return resp
+	}
+	conv, err := productUomConversion(ctx, uc.repo, productID, conversionID)
+	if err != nil {
+		return catalogError(err)
+	}
+	if err := uc.repo.DeleteProductUOMConversion(ctx, conv.ID); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "unit conversion deleted successfully", nil)
+}
+
+// checkProduct returns a not found response unless the product exists in
+// the active organization.
+func (uc *CatalogUseCase) checkProduct(ctx context.Context, productID int32) *repository.Response {
+	product, err := uc.repo.GetProduct(ctx, productID)
+	if err != nil || !inActiveOrganization(ctx, product.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	return nil
+}
+
+// createCatalogProduct writes a product and everything given with it.
+func createCatalogProduct(ctx context.Context, q *repository.Queries, in *CreateProductInput) (*ProductDetail, error) {
+	p := in.Product
+	if strings.TrimSpace(p.SKU) == "" || strings.TrimSpace(p.Name) == "" {
+		return nil, documentInputErrorf("sku and name are required")
+	}
+	product, err := q.CreateProduct(ctx, createProductParams(&p))
+	if err != nil {
+		return nil, err
+	}
+	detail := &ProductDetail{Product: product}
+
+	wanted, err := productVariants(product, in.Attributes, in.Variants)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range wanted {
+		var isActive *bool
+		if i < len(in.Variants) {
+			isActive = in.Variants[i].IsActive
+		}
+		created, err := createVariant(ctx, q, product, v, isActive)
+		if err != nil {
+			return nil, err
+		}
+		detail.Variants = append(detail.Variants, created)
+	}
+
+	for _, c := range in.UomConversions {
+		conv, err := addUomConversion(ctx, q, product, c)
+		if err != nil {
+			return nil, err
+		}
+		detail.UomConversions = append(detail.UomConversions, conv)
+	}
+
+	for _, b := range in.Barcodes {
+		if _, err := addBarcode(ctx, q, product, detail.Variants, b); err != nil {
+			return nil, err
+		}
+	}
+	if detail.Barcodes, err = q.ListProductBarcodesByProduct(ctx, product.ID); err != nil {
+		return nil, err
+	}
+
+	for _, pr := range in.Prices {
+		price, err := addStartingPrice(ctx, q, product, detail.Variants, pr)
+		if err != nil {
+			return nil, err
+		}
+		detail.Prices = append(detail.Prices, price)
+	}
+	fillProductDetail(detail)
+	return detail, nil
+}
+
+// loadProductDetail loads the variants, barcodes and conversions of product.
+func loadProductDetail(ctx context.Context, q *repository.Queries, product repository.Product) (*ProductDetail, error) {
+	detail := &ProductDetail{Product: product}
+	var err error
+	if detail.Variants, err = q.ListProductVariantsByProduct(ctx, product.ID); err != nil {
+		return nil, err
+	}
+	if detail.Barcodes, err = q.ListProductBarcodesByProduct(ctx, product.ID); err != nil {
+		return nil, err
+	}
+	if detail.UomConversions, err = q.ListProductUOMConversions(ctx, product.ID); err != nil {
+		return nil, err
+	}
+	fillProductDetail(detail)
+	return detail, nil
+}
+
+// fillProductDetail turns nil lists into empty ones for the JSON response.
+func fillProductDetail(d *ProductDetail) {
+	if d.Variants == nil {
+		d.Variants = []repository.ProductVariant{}
+	}
+	if d.Barcodes == nil {
+		d.Barcodes = []repository.ProductBarcode{}
+	}
+	if d.UomConversions == nil {
+		d.UomConversions = []repository.ProductUomConversion{}
+	}
+}
+
+// productVariants returns the explicit variants followed by the
+// combinations of attrs, checking no SKU is repeated.
+func productVariants(product repository.Product, attrs []catalog.Attribute, explicit []VariantInput) ([]catalog.Variant, error) {
+	out := make([]catalog.Variant, 0, len(explicit))
+	for _, v := range explicit {
+		if strings.TrimSpace(v.SKU) == "" {
+			return nil, documentInputErrorf("variant sku is required")
+		}
+		if len(v.Attributes) == 0 {
+			return nil, documentInputErrorf("variant %s has no attributes", v.SKU)
+		}
+		name := strings.TrimSpace(v.Name)
+		if name == "" {
+			name = product.Name
+		}
+		out = append(out, catalog.Variant{SKU: strings.TrimSpace(v.SKU), Name: name, Attributes: v.Attributes})
+	}
+	if len(attrs) > 0 {
+		generated, err := catalog.ExpandVariants(product.Sku, product.Name, attrs)
+		if err != nil {
+			return nil, documentInputErrorf("%s", err.Error())
+		}
+		out = append(out, generated...)
+	}
+	seen := map[string]bool{}
+	for _, v := range out {
+		if seen[strings.ToUpper(v.SKU)] {
+			return nil, documentInputErrorf("variant sku %s is repeated", v.SKU)
+		}
+		seen[strings.ToUpper(v.SKU)] = true
+	}
+	return out, nil
+}
+
+// hasVariant reports whether existing already holds v by SKU or by
+// attribute values.
+func hasVariant(existing []repository.ProductVariant, v catalog.Variant) bool {
+	for _, e := range existing {
+		if strings.EqualFold(e.VariantSku, v.SKU) {
+			return true
+		}
+		var attrs map[string]string
+		if json.Unmarshal(e.VariantAttributes, &attrs) == nil && catalog.SameAttributes(attrs, v.Attributes) {
+			return true
+		}
+	}
+	return false
+}
+
+func createVariant(ctx context.Context, q *repository.Queries, product repository.Product, v catalog.Variant, isActive *bool) (repository.ProductVariant, error) {
+	attrs, _ := json.Marshal(v.Attributes)
+	active := true
+	if isActive != nil {
+		active = *isActive
+	}
+	return q.CreateProductVariant(ctx, repository.CreateProductVariantParams{
+		ProductID:         product.ID,
+		VariantSku:        v.SKU,
+		VariantName:       optionalText(v.Name),
+		VariantAttributes: attrs,
+		IsActive:          pgtype.Bool{Bool: active, Valid: true},
+		Metadata:          []byte("{}"),
+	})
+}
+
+// addBarcode writes a barcode of product or of one of variants. A barcode
+// marked primary, or the product's first, becomes the primary barcode.
+func addBarcode(ctx context.Context, q *repository.Queries, product repository.Product, variants []repository.ProductVariant, in BarcodeInput) (repository.ProductBarcode, error) {
+	code := strings.TrimSpace(in.Barcode)
+	if code == "" {
+		return repository.ProductBarcode{}, documentInputErrorf("barcode is required")
+	}
+	exists, err := q.CheckBarcodeExists(ctx, code)
+	if err != nil {
+		return repository.ProductBarcode{}, err
+	}
+	if exists {
+		return repository.ProductBarcode{}, documentInputErrorf("barcode %s is already in use", code)
+	}
+	var variantID pgtype.Int4
+	if in.VariantID != nil || strings.TrimSpace(in.VariantSKU) != "" {
+		v, ok := findVariant(variants, in.VariantID, in.VariantSKU)
+		if !ok {
+			return repository.ProductBarcode{}, documentInputErrorf("barcode %s: variant is not a variant of product %s", code, product.Sku)
+		}
+		variantID = pgtype.Int4{Int32: v.ID, Valid: true}
+	}
+	primary := in.IsPrimary
+	if !primary {
+		if _, err := q.GetPrimaryBarcode(ctx, product.ID); err != nil {
+			primary = true
+		}
+	}
+	b, err := q.CreateProductBarcode(ctx, repository.CreateProductBarcodeParams{
+		ProductID:        product.ID,
+		ProductVariantID: variantID,
+		Barcode:          code,
+		BarcodeType:      optionalText(in.BarcodeType),
+		IsPrimary:        pgtype.Bool{Bool: false, Valid: true},
+		Metadata:         []byte("{}"),
+	})
+	if err != nil || !primary {
+		return b, err
+	}
+	if err := q.SetPrimaryBarcode(ctx, repository.SetPrimaryBarcodeParams{ProductID: product.ID, ID: b.ID}); err != nil {
+		return b, err
+	}
+	b.IsPrimary = pgtype.Bool{Bool: true, Valid: true}
+	return b, nil
+}
+
+// findVariant finds a variant by ID or, failing that, by SKU.
+func findVariant(variants []repository.ProductVariant, id *int32, sku string) (repository.ProductVariant, bool) {
+	sku = strings.TrimSpace(sku)
+	for _, v := range variants {
+		if (id != nil && v.ID == *id) || (id == nil && strings.EqualFold(v.VariantSku, sku)) {
+			return v, true
+		}
+	}
+	return repository.ProductVariant{}, false
+}
+
+// addUomConversion writes a unit conversion after checking both units
+// exist and differ. A default conversion replaces the previous default.
+func addUomConversion(ctx context.Context, q *repository.Queries, product repository.Product, in UomConversionInput) (repository.ProductUomConversion, error) {
+	if in.FromUomID == in.ToUomID {
+		return repository.ProductUomConversion{}, documentInputErrorf("conversion units must differ")
+	}
+	for _, id := range []int32{in.FromUomID, in.ToUomID} {
+		if _, err := q.GetUnitOfMeasure(ctx, id); err != nil {
+			return repository.ProductUomConversion{}, documentInputErrorf("unit of measure %d not found", id)
+		}
+	}
+	factor, err := parseConversionFactor(in.ConversionFactor)
+	if err != nil {
+		return repository.ProductUomConversion{}, err
+	}
+	if in.IsDefault {
+		if err := clearDefaultConversion(ctx, q, product.ID, 0); err != nil {
+			return repository.ProductUomConversion{}, err
+		}
+	}
+	return q.CreateProductUOMConversion(ctx, repository.CreateProductUOMConversionParams{
+		ProductID:        product.ID,
+		FromUomID:        in.FromUomID,
+		ToUomID:          in.ToUomID,
+		ConversionFactor: utils.RatToNumeric(factor, 6),
+		IsDefault:        pgtype.Bool{Bool: in.IsDefault, Valid: true},
+		Metadata:         []byte("{}"),
+	})
+}
+
+// clearDefaultConversion unsets the default flag of the product's other
+// conversions.
+func clearDefaultConversion(ctx context.Context, q *repository.Queries, productID, keepID int32) error {
+	convs, err := q.ListProductUOMConversions(ctx, productID)
+	if err != nil {
+		return err
+	}
+	for _, c := range convs {
+		if c.ID == keepID || !c.IsDefault.Bool {
+			continue
+		}
+		if _, err := q.UpdateProductUOMConversion(ctx, repository.UpdateProductUOMConversionParams{
+			ID:               c.ID,
+			ConversionFactor: c.ConversionFactor,
+			IsDefault:        pgtype.Bool{Bool: false, Valid: true},
+			Metadata:         c.Metadata,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// productUomConversion finds a conversion of the product by ID.
+func productUomConversion(ctx context.Context, q *repository.Queries, productID, id int32) (repository.ProductUomConversion, error) {
+	convs, err := q.ListProductUOMConversions(ctx, productID)
+	if err != nil {
+		return repository.ProductUomConversion{}, err
+	}
+	for _, c := range convs {
+		if c.ID == id {
+			return c, nil
+		}
+	}
+	return repository.ProductUomConversion{}, documentInputErrorf("unit conversion %d not found on product %d", id, productID)
+}
+
+func parseConversionFactor(s string) (*big.Rat, error) {
+	factor, err := parseAmount("conversion_factor", s)
+	if err != nil {
+		return nil, err
+	}
+	if factor.Sign() <= 0 {
+		return nil, documentInputErrorf("conversion_factor must be positive")
+	}
+	return factor, nil
+}
+
+// addStartingPrice writes a price of a new product in the base unit.
+func addStartingPrice(ctx context.Context, q *repository.Queries, product repository.Product, variants []repository.ProductVariant, in CatalogPriceInput) (repository.ProductPrice, error) {
+	var list repository.PriceList
+	var err error
+	if in.PriceListID != nil {
+		list, err = q.GetPriceList(ctx, *in.PriceListID)
+		if err != nil {
+			return repository.ProductPrice{}, documentInputErrorf("price list %d not found", *in.PriceListID)
+		}
+	} else if list, err = q.GetDefaultPriceList(ctx); err != nil {
+		return repository.ProductPrice{}, documentInputErrorf("no default price list; give price_list_id")
+	}
+	v := &priceVersion{
+		priceListID: list.ID,
+		productID:   product.ID,
+		uomID:       product.BaseUomID,
+		metadata:    []byte("{}"),
+	}
+	if strings.TrimSpace(in.VariantSKU) != "" {
+		variant, ok := findVariant(variants, nil, in.VariantSKU)
+		if !ok {
+			return repository.ProductPrice{}, documentInputErrorf("price: %s is not a variant of product %s", in.VariantSKU, product.Sku)
+		}
+		v.variantID = pgtype.Int4{Int32: variant.ID, Valid: true}
+	}
+	if msg := v.setAmounts(in.Price, "", ""); msg != "" {
+		return repository.ProductPrice{}, documentInputErrorf("%s", msg)
+	}
+	return q.CreateProductPrice(ctx, v.createParams(nil))
+}
+
+// createProductParams maps ProductInput onto CreateProduct, with the
+// catalog's defaults for flags that are not given.
+func createProductParams(in *ProductInput) repository.CreateProductParams {
+	params := repository.CreateProductParams{
+		OrganizationID:       in.OrganizationID,
+		Sku:                  strings.TrimSpace(in.SKU),
+		Name:                 strings.TrimSpace(in.Name),
+		CategoryID:           optionalInt4(in.CategoryID),
+		BrandID:              optionalInt4(in.BrandID),
+		BaseUomID:            optionalInt4(in.BaseUomID),
+		TaxCategoryID:        optionalInt4(in.TaxCategoryID),
+		IsSerialized:         boolOr(in.IsSerialized, false),
+		IsBatchManaged:       boolOr(in.IsBatchManaged, false),
+		IsActive:             boolOr(in.IsActive, true),
+		IsSellable:           boolOr(in.IsSellable, true),
+		IsPurchasable:        boolOr(in.IsPurchasable, false),
+		AllowDecimalQuantity: boolOr(in.AllowDecimalQuantity, false),
+		TrackInventory:       boolOr(in.TrackInventory, true),
+		Metadata:             []byte("{}"),
+	}
+	if in.Description != nil {
+		params.Description = pgtype.Text{String: *in.Description, Valid: true}
+	}
+	if in.ProductType != nil {
+		params.ProductType = pgtype.Text{String: *in.ProductType, Valid: true}
+	}
+	if in.Metadata != nil {
+		params.Metadata, _ = json.Marshal(in.Metadata)
+	}
+	return params
+}
+
+// updateProductParams maps the given fields of ProductInput onto
+// UpdateProduct; nil and empty fields keep their value.
+func updateProductParams(id int32, in *ProductInput) repository.UpdateProductParams {
+	arg := repository.UpdateProductParams{
+		ID:                   id,
+		Name:                 optionalText(in.Name),
+		CategoryID:           optionalInt4(in.CategoryID),
+		BrandID:              optionalInt4(in.BrandID),
+		BaseUomID:            optionalInt4(in.BaseUomID),
+		TaxCategoryID:        optionalInt4(in.TaxCategoryID),
+		IsSerialized:         optionalBool(in.IsSerialized),
+		IsBatchManaged:       optionalBool(in.IsBatchManaged),
+		IsActive:             optionalBool(in.IsActive),
+		IsSellable:           optionalBool(in.IsSellable),
+		IsPurchasable:        optionalBool(in.IsPurchasable),
+		AllowDecimalQuantity: optionalBool(in.AllowDecimalQuantity),
+		TrackInventory:       optionalBool(in.TrackInventory),
+	}
+	if in.Description != nil {
+		arg.Description = pgtype.Text{String: *in.Description, Valid: true}
+	}
+	if in.ProductType != nil {
+		arg.ProductType = pgtype.Text{String: *in.ProductType, Valid: true}
+	}
+	if in.Metadata != nil {
+		arg.Metadata, _ = json.Marshal(in.Metadata)
+	}
+	return arg
+}
+
+func optionalBool(v *bool) pgtype.Bool {
+	if v == nil {
+		return pgtype.Bool{}
+	}
+	return pgtype.Bool{Bool: *v, Valid: true}
+}
+
+func boolOr(v *bool, def bool) pgtype.Bool {
+	if v != nil {
+		def = *v
+	}
+	return pgtype.Bool{Bool: def, Valid: true}
+}
+
+// catalogError maps an error of a catalog write to a response: caller
+// mistakes, duplicates and references to missing rows are 400s.
+func catalogError(err error) *repository.Response {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505":
+			return utils.NewResponse(utils.CodeBadReq, "already exists: "+pgErr.Detail, nil)
+		case "23503":
+			return utils.NewResponse(utils.CodeBadReq, "invalid reference: "+pgErr.Detail, nil)
+		}
+	}
+	var bad *documentInputError
+	if errors.As(err, &bad) && strings.Contains(bad.msg, "not found on product") {
+		return utils.NewResponse(utils.CodeNotFound, bad.Error(), nil)
+	}
+	return checkoutError(err)
+}
+
+// isForeignKeyViolation reports whether err is a foreign key violation,
+// such as deleting a row that documents still reference.
+func isForeignKeyViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23503"
+}
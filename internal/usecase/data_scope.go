@@ -0,0 +1,225 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Scopes of a permission granted to a role (role_permissions.scope), widest
+// first. A user holding a permission through several roles gets the widest
+// of their scopes.
+const (
+	ScopeAll          = "all"
+	ScopeOrganization = "organization"
+	ScopeOwnStores    = "own_stores"
+	ScopeOwn          = "own"
+)
+
+// scopeWidth orders the scopes; unknown scopes count as the narrowest.
+var scopeWidth = map[string]int{
+	ScopeOwn:          1,
+	ScopeOwnStores:    2,
+	ScopeOrganization: 3,
+	ScopeAll:          4,
+}
+
+// Permissions needed to read stores, stock, POS transactions and orders.
+// Their scope decides which rows the reader sees, see DataScope.
+const (
+	PermissionViewStores          = "stores.view"
+	PermissionViewStock           = "inventory.view"
+	PermissionViewPosTransactions = "pos.transactions.view"
+	PermissionViewSalesOrders     = "sales_orders.view"
+	PermissionViewPurchaseOrders  = "purchase_orders.view"
+)
+
+const scopeError = "scope must be all, organization, own_stores or own"
+
+// validScope reports whether scope is one of the known scopes.
+func validScope(scope string) bool {
+	return scopeWidth[scope] > 0
+}
+
+// DataScope limits the rows the signed-in user reads through a permission,
+// on top of the active organization:
+//
+//   - all: every row
+//   - organization: rows of the stores of the active organization
+//   - own_stores: rows of the stores the user has access to
+//   - own: rows the user created, or rang up for POS transactions; rows
+//     nobody owns, like stores and stock, fall back to own_stores
+type DataScope struct {
+	Scope  string
+	UserID int32
+
+	// stores the rows are limited to; nil when they aren't.
+	stores map[int32]bool
+}
+
+// dataScope resolves the scope of the signed-in user for permission. Outside
+// of a signed-in request nothing is limited. Without the permission the
+// user gets 403.
+func dataScope(ctx context.Context, q *repository.Queries, permission string) (*DataScope, *repository.Response) {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return &DataScope{Scope: ScopeAll}, nil
+	}
+
+	granted, err := q.GetUserPermissionsWithScope(ctx, userID)
+	if err != nil {
+		return nil, utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	scope, width := "", 0
+	for _, g := range granted {
+		if g.Code != permission {
+			continue
+		}
+		s := ScopeAll
+		if g.Scope.Valid {
+			s = g.Scope.String
+		}
+		if w := scopeWidth[s]; scope == "" || w > width {
+			scope, width = s, w
+		}
+	}
+	if scope == "" {
+		return nil, utils.NewResponse(utils.CodeForbidden, "requires the "+permission+" permission", nil)
+	}
+	if width == 0 {
+		scope = ScopeOwn
+	}
+
+	s := &DataScope{Scope: scope, UserID: userID}
+	var storeIDs []int32
+	switch scope {
+	case ScopeAll:
+		return s, nil
+	case ScopeOrganization:
+		// The organization the request works in, which may not be the
+		// user's own; OrganizationMiddleware checked they have access.
+		orgID, ok := middleware.OrganizationIDFromContext(ctx)
+		if !ok {
+			user, err := q.GetUser(ctx, userID)
+			if err != nil {
+				return nil, utils.NewResponse(utils.CodeError, err.Error(), nil)
+			}
+			orgID = user.OrganizationID
+		}
+		storeIDs, err = q.ListOrganizationStoreIDs(ctx, orgID)
+		if err != nil {
+			return nil, utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+	default:
+		storeIDs, err = q.ListUserStoreIDs(ctx, userID)
+		if err != nil {
+			return nil, utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+	}
+	s.stores = make(map[int32]bool, len(storeIDs))
+	for _, id := range storeIDs {
+		s.stores[id] = true
+	}
+	return s, nil
+}
+
+// StoreIDs returns the stores rows are limited to, or nil when they aren't,
+// for queries taking an optional store_ids filter.
+func (s *DataScope) StoreIDs() []int32 {
+	if s.stores == nil {
+		return nil
+	}
+	ids := make([]int32, 0, len(s.stores))
+	for id := range s.stores {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// AllowsStore reports whether rows of the store are in scope.
+func (s *DataScope) AllowsStore(storeID int32) bool {
+	return s.stores == nil || s.stores[storeID]
+}
+
+// AllowsOwned reports whether a row of the store owned by owner is in scope.
+func (s *DataScope) AllowsOwned(storeID int32, owner pgtype.Int4) bool {
+	if s.Scope == ScopeOwn {
+		return owner.Valid && owner.Int32 == s.UserID
+	}
+	return s.AllowsStore(storeID)
+}
+
+// FilterStores returns the stores in scope.
+func (s *DataScope) FilterStores(stores []repository.Store) []repository.Store {
+	if s.stores == nil {
+		return stores
+	}
+	out := make([]repository.Store, 0, len(stores))
+	for _, store := range stores {
+		if s.stores[store.ID] {
+			out = append(out, store)
+		}
+	}
+	return out
+}
+
+// checkStoreInScope returns a not found response unless the signed-in user
+// may read the store's rows through permission.
+func checkStoreInScope(ctx context.Context, q *repository.Queries, permission string, storeID int32) *repository.Response {
+	scope, resp := dataScope(ctx, q, permission)
+	if resp != nil {
+		return resp
+	}
+	if !scope.AllowsStore(storeID) {
+		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+	}
+	return nil
+}
+
+// checkStoreAccess returns a forbidden response unless the signed-in user
+// may work in storeID, see middleware.HasStoreAccess. StoreAccessMiddleware
+// only sees stores a request names; use cases call this for the store of the
+// session or document a request works on.
+func checkStoreAccess(ctx context.Context, q *repository.Queries, storeID int32) *repository.Response {
+	userID, ok := middleware.UserIDFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	allowed, err := middleware.HasStoreAccess(ctx, q, userID, storeID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if !allowed {
+		return utils.NewResponse(utils.CodeForbidden, "no access to this store", nil)
+	}
+	return nil
+}
+
+// checkPosTransactionInScope returns a not found response unless the
+// transaction is in the active organization and the signed-in user may read
+// it. A transaction is owned by the user of its cashier.
+func checkPosTransactionInScope(ctx context.Context, q *repository.Queries, txn repository.PosTransaction) *repository.Response {
+	if store, err := q.GetStore(ctx, txn.StoreID); err != nil || !inActiveOrganization(ctx, store.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "transaction not found", nil)
+	}
+	scope, resp := dataScope(ctx, q, PermissionViewPosTransactions)
+	if resp != nil {
+		return resp
+	}
+	var owner pgtype.Int4
+	if scope.Scope == ScopeOwn {
+		if cashier, err := q.GetCashier(ctx, txn.CashierID); err == nil {
+			owner = pgtype.Int4{Int32: cashier.UserID, Valid: true}
+		}
+	}
+	if !scope.AllowsOwned(txn.StoreID, owner) {
+		return utils.NewResponse(utils.CodeNotFound, "transaction not found", nil)
+	}
+	return checkStoreAccess(ctx, q, txn.StoreID)
+}
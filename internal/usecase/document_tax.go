@@ -0,0 +1,249 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/tax"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// documentLine is a priced line of a POS sale, sales order or purchase order,
+// waiting to be taxed.
+type documentLine struct {
+	product   repository.Product
+	variantID pgtype.Int4
+	uomID     pgtype.Int4
+	quantity  *big.Rat
+	unitPrice *big.Rat
+	discount  *big.Rat
+}
+
+// documentInputError reports a caller mistake found while building a
+// document; it maps to a 400 response.
+type documentInputError struct{ msg string }
+
+func (e *documentInputError) Error() string { return e.msg }
+
+func documentInputErrorf(format string, args ...interface{}) error {
+	return &documentInputError{fmt.Sprintf(format, args...)}
+}
+
+// documentTaxOptions returns the tax options for a document of org. The
+// rounding mode comes from organizations.metadata "tax_rounding" (line or
+// invoice, default line); customers.metadata "tax_exempt": true zero-rates
+// the whole document.
+func documentTaxOptions(org repository.Organization, customer *repository.Customer) (tax.Options, error) {
+	opt := tax.Options{Rounding: tax.RoundPerLine, Scale: 2}
+	if v, ok := metadataValue(org.Metadata, "tax_rounding").(string); ok {
+		r, err := tax.ParseRounding(strings.ToLower(strings.TrimSpace(v)))
+		if err != nil {
+			return opt, fmt.Errorf("organization %d: %w", org.ID, err)
+		}
+		opt.Rounding = r
+	}
+	if customer != nil {
+		opt.Exempt = metadataBool(customer.Metadata, "tax_exempt")
+	}
+	return opt, nil
+}
+
+// calculateDocumentTax taxes lines with each product's tax category.
+// Products without a tax category are not taxed.
+func calculateDocumentTax(ctx context.Context, q *repository.Queries, lines []documentLine, opt tax.Options) (*tax.Result, error) {
+	rates := map[int32]tax.Rate{}
+	in := make([]tax.Line, len(lines))
+	for i, l := range lines {
+		rate := tax.Rate{Code: "NONE", Name: "No tax", Percent: new(big.Rat)}
+		if l.product.TaxCategoryID.Valid {
+			id := l.product.TaxCategoryID.Int32
+			r, ok := rates[id]
+			if !ok {
+				cat, err := q.GetTaxCategory(ctx, id)
+				if err != nil {
+					return nil, fmt.Errorf("load tax category %d: %w", id, err)
+				}
+				r = tax.Rate{
+					Code:      cat.Code,
+					Name:      cat.Name,
+					Percent:   utils.NumericToRat(cat.TaxRate),
+					Inclusive: cat.IsInclusive.Bool,
+				}
+				rates[id] = r
+			}
+			rate = r
+		}
+		in[i] = tax.Line{
+			Quantity:  l.quantity,
+			UnitPrice: l.unitPrice,
+			Discount:  l.discount,
+			Rate:      rate,
+		}
+	}
+	res, err := tax.Calculate(in, opt)
+	if err != nil {
+		return nil, &documentInputError{err.Error()}
+	}
+	return res, nil
+}
+
+// TaxBreakdownLine is one rate of a document's tax summary.
+type TaxBreakdownLine struct {
+	Code          string `json:"code"`
+	Name          string `json:"name"`
+	Rate          string `json:"rate"`
+	Inclusive     bool   `json:"inclusive"`
+	Exempt        bool   `json:"exempt"`
+	TaxableAmount string `json:"taxable_amount"`
+	TaxAmount     string `json:"tax_amount"`
+}
+
+// DocumentTotals is the tax summary returned with a created document.
+type DocumentTotals struct {
+	Subtotal       string             `json:"subtotal"`
+	DiscountAmount string             `json:"discount_amount"`
+	TaxAmount      string             `json:"tax_amount"`
+	TotalAmount    string             `json:"total_amount"`
+	Rounding       string             `json:"rounding"`
+	TaxExempt      bool               `json:"tax_exempt"`
+	TaxBreakdown   []TaxBreakdownLine `json:"tax_breakdown"`
+}
+
+func newDocumentTotals(res *tax.Result, opt tax.Options) DocumentTotals {
+	t := DocumentTotals{
+		Subtotal:       res.Subtotal.FloatString(opt.Scale),
+		DiscountAmount: res.Discount.FloatString(opt.Scale),
+		TaxAmount:      res.Tax.FloatString(opt.Scale),
+		TotalAmount:    res.Total.FloatString(opt.Scale),
+		Rounding:       string(opt.Rounding),
+		TaxExempt:      opt.Exempt,
+		TaxBreakdown:   []TaxBreakdownLine{},
+	}
+	for _, b := range res.Breakdown {
+		rate := b.Percent.FloatString(2)
+		if b.Exempt {
+			rate = "0.00"
+		}
+		t.TaxBreakdown = append(t.TaxBreakdown, TaxBreakdownLine{
+			Code:          b.Code,
+			Name:          b.Name,
+			Rate:          rate,
+			Inclusive:     b.Inclusive,
+			Exempt:        b.Exempt,
+			TaxableAmount: b.Taxable.FloatString(opt.Scale),
+			TaxAmount:     b.Tax.FloatString(opt.Scale),
+		})
+	}
+	return t
+}
+
+// documentTaxMetadata records how an order was taxed; order lines do not
+// keep their tax category, so the per-rate breakdown lives in metadata.
+func documentTaxMetadata(t DocumentTotals) []byte {
+	meta, _ := json.Marshal(map[string]interface{}{
+		"tax_rounding":  t.Rounding,
+		"tax_exempt":    t.TaxExempt,
+		"tax_breakdown": t.TaxBreakdown,
+	})
+	return meta
+}
+
+// lineTaxKeys are the line metadata keys holding the tax rate a POS line was
+// charged at.
+var lineTaxKeys = []string{"tax_code", "tax_name", "tax_rate", "tax_inclusive", "tax_exempt"}
+
+// withLineTax adds the rate a line was taxed at to its metadata, so receipts
+// and invoices do not change when the product's tax category is edited later.
+func withLineTax(raw []byte, lr tax.LineResult) []byte {
+	rate := "0"
+	if !lr.Exempt && lr.Rate.Percent != nil {
+		rate = lr.Rate.Percent.FloatString(2)
+	}
+	return mergeMetadata(raw, map[string]interface{}{
+		"tax_code":      lr.Rate.Code,
+		"tax_name":      lr.Rate.Name,
+		"tax_rate":      rate,
+		"tax_inclusive": lr.Rate.Inclusive,
+		"tax_exempt":    lr.Exempt,
+	})
+}
+
+// mergeMetadata sets keys on a JSON metadata object.
+func mergeMetadata(raw []byte, keys map[string]interface{}) []byte {
+	meta := map[string]interface{}{}
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &meta)
+	}
+	for k, v := range keys {
+		meta[k] = v
+	}
+	out, _ := json.Marshal(meta)
+	return out
+}
+
+// parseAmount parses a non-negative decimal request field; empty is zero.
+func parseAmount(field, s string) (*big.Rat, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return new(big.Rat), nil
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok || r.Sign() < 0 {
+		return nil, documentInputErrorf("invalid %s %q", field, s)
+	}
+	return r, nil
+}
+
+// parseQuantity parses a positive quantity, rejecting fractions for products
+// that do not allow decimal quantities.
+func parseQuantity(product repository.Product, s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || r.Sign() <= 0 {
+		return nil, documentInputErrorf("invalid quantity %q for product %s", s, product.Sku)
+	}
+	if !r.IsInt() && !product.AllowDecimalQuantity.Bool {
+		return nil, documentInputErrorf("product %s does not allow decimal quantities", product.Sku)
+	}
+	return r, nil
+}
+
+// documentNumber returns a sortable document number such as
+// SO-20260314-153012345.
+func documentNumber(prefix string, t time.Time) string {
+	return fmt.Sprintf("%s-%s%03d", prefix, t.Format("20060102-150405"), t.Nanosecond()/int(time.Millisecond))
+}
+
+func optionalInt4(v *int32) pgtype.Int4 {
+	if v == nil {
+		return pgtype.Int4{}
+	}
+	return pgtype.Int4{Int32: *v, Valid: true}
+}
+
+func metadataValue(raw []byte, key string) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal(raw, &meta); err != nil {
+		return nil
+	}
+	return meta[key]
+}
+
+func metadataBool(raw []byte, key string) bool {
+	switch v := metadataValue(raw, key).(type) {
+	case bool:
+		return v
+	case string:
+		return strings.EqualFold(v, "true")
+	}
+	return false
+}
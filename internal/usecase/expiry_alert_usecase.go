@@ -0,0 +1,417 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/inventory"
+	"NEMBUS/internal/pricing"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ExpiryAlertUseCase runs the expiry check that raises expired and
+// near-expiry alerts, and serves the per-store alerts inbox and the
+// per-category alert rules.
+type ExpiryAlertUseCase struct {
+	repo *repository.Queries
+}
+
+// NewExpiryAlertUseCase creates a new expiry alert use case.
+func NewExpiryAlertUseCase() *ExpiryAlertUseCase {
+	return &ExpiryAlertUseCase{}
+}
+
+// SetRepository injects repository per request
+func (uc *ExpiryAlertUseCase) SetRepository(repo *repository.Queries) {
+	uc.repo = repo
+}
+
+// ExpiryCheckResult summarizes one run of the expiry check.
+type ExpiryCheckResult struct {
+	ExpiredAlerts    int   `json:"expired_alerts"`
+	NearExpiryAlerts int   `json:"near_expiry_alerts"`
+	ResolvedAlerts   int64 `json:"resolved_alerts"`
+}
+
+// RunExpiryCheck evaluates the tenant's stock against its expiry rules in
+// one database transaction. Expired batches with stock left get an expired
+// alert and are marked expired; batches and in-stock serialized units inside
+// their category's near-expiry window get a near_expiry alert with a
+// suggested markdown price. Near-expiry alerts whose stock is gone are
+// resolved.
+func (uc *ExpiryAlertUseCase) RunExpiryCheck(ctx context.Context) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	result := &ExpiryCheckResult{}
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		c, err := newExpiryCheck(ctx, q)
+		if err != nil {
+			return err
+		}
+
+		expired, err := q.GetExpiredBatches(ctx, pgtype.Int4{})
+		if err != nil {
+			return err
+		}
+		for _, b := range expired {
+			qty := utils.NumericToRat(b.QuantityAvailable)
+			if qty.Sign() <= 0 || !b.StoreID.Valid {
+				continue
+			}
+			if err := q.UpsertInventoryAlert(ctx, repository.UpsertInventoryAlertParams{
+				AlertType:        inventory.AlertExpired,
+				StoreID:          b.StoreID.Int32,
+				ProductID:        b.ProductID,
+				ProductVariantID: b.ProductVariantID,
+				BatchID:          pgtype.Int4{Int32: b.ID, Valid: true},
+				ExpiryDate:       b.ExpiryDate,
+				DaysUntilExpiry:  pgtype.Int4{Int32: int32(inventory.DaysUntil(b.ExpiryDate.Time, c.now)), Valid: true},
+				Quantity:         b.QuantityAvailable,
+				Message: optionalText(fmt.Sprintf("Batch %s of %s expired on %s; %s units can no longer be sold",
+					b.BatchNumber, b.ProductSku, b.ExpiryDate.Time.Format("2006-01-02"), qty.FloatString(3))),
+				LastEvaluatedAt: pgtype.Timestamp{Time: c.now, Valid: true},
+			}); err != nil {
+				return err
+			}
+			result.ExpiredAlerts++
+		}
+		if err := q.ExpireBatches(ctx); err != nil {
+			return err
+		}
+
+		horizon := c.rules.MaxNearExpiryDays()
+		batches, err := q.GetExpiringSoonBatches(ctx, repository.GetExpiringSoonBatchesParams{
+			Column1: pgtype.Interval{Days: int32(horizon), Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		for _, b := range batches {
+			if !b.StoreID.Valid {
+				continue
+			}
+			raised, err := c.raiseNearExpiry(ctx, nearExpiryStock{
+				storeID:   b.StoreID.Int32,
+				productID: b.ProductID,
+				variantID: b.ProductVariantID,
+				batchID:   pgtype.Int4{Int32: b.ID, Valid: true},
+				expiry:    b.ExpiryDate.Time,
+				quantity:  b.QuantityAvailable,
+				label:     "Batch " + b.BatchNumber,
+			})
+			if err != nil {
+				return err
+			}
+			if raised {
+				result.NearExpiryAlerts++
+			}
+		}
+
+		serials, err := q.GetExpiringSerialNumbers(ctx, pgtype.Date{Time: c.now.AddDate(0, 0, horizon), Valid: true})
+		if err != nil {
+			return err
+		}
+		for _, s := range serials {
+			if !s.CurrentStoreID.Valid || !s.ExpiryDate.Valid {
+				continue
+			}
+			raised, err := c.raiseNearExpiry(ctx, nearExpiryStock{
+				storeID:   s.CurrentStoreID.Int32,
+				productID: s.ProductID,
+				variantID: s.ProductVariantID,
+				serialID:  pgtype.Int4{Int32: s.ID, Valid: true},
+				expiry:    s.ExpiryDate.Time,
+				quantity:  utils.RatToNumeric(big.NewRat(1, 1), 3),
+				label:     "Serial number " + s.SerialNumber,
+			})
+			if err != nil {
+				return err
+			}
+			if raised {
+				result.NearExpiryAlerts++
+			}
+		}
+
+		result.ResolvedAlerts, err = q.ResolveStaleInventoryAlerts(ctx, repository.ResolveStaleInventoryAlertsParams{
+			AlertType:       inventory.AlertNearExpiry,
+			EvaluatedBefore: pgtype.Timestamp{Time: c.now, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "expiry check completed", result)
+}
+
+// expiryCheck holds what one run of the expiry check loads once: the rules,
+// the products seen so far and a price resolver per store.
+type expiryCheck struct {
+	q         *repository.Queries
+	now       time.Time
+	rules     inventory.ExpiryRules
+	products  map[int32]repository.Product
+	resolvers map[int32]*priceResolver
+}
+
+// nearExpiryStock is a batch or serialized unit inside the horizon of the
+// widest near-expiry window.
+type nearExpiryStock struct {
+	storeID   int32
+	productID int32
+	variantID pgtype.Int4
+	batchID   pgtype.Int4
+	serialID  pgtype.Int4
+	expiry    time.Time
+	quantity  pgtype.Numeric
+	label     string
+}
+
+func newExpiryCheck(ctx context.Context, q *repository.Queries) (*expiryCheck, error) {
+	rules, err := loadExpiryRules(ctx, q)
+	if err != nil {
+		return nil, err
+	}
+	return &expiryCheck{
+		q:         q,
+		now:       time.Now(),
+		rules:     rules,
+		products:  map[int32]repository.Product{},
+		resolvers: map[int32]*priceResolver{},
+	}, nil
+}
+
+// raiseNearExpiry raises or refreshes the near_expiry alert of s when it is
+// inside its category's window, pricing the markdown at the store's current
+// price. Stock without a price gets an alert without a suggestion.
+func (c *expiryCheck) raiseNearExpiry(ctx context.Context, s nearExpiryStock) (bool, error) {
+	product, ok := c.products[s.productID]
+	if !ok {
+		var err error
+		if product, err = c.q.GetProduct(ctx, s.productID); err != nil {
+			return false, err
+		}
+		c.products[s.productID] = product
+	}
+	rule := c.rules.For(product.CategoryID.Int32)
+	days := inventory.DaysUntil(s.expiry, c.now)
+	if days < 0 || days > rule.NearExpiryDays {
+		return false, nil
+	}
+
+	pct := inventory.SuggestMarkdown(rule, days)
+	msg := fmt.Sprintf("%s of %s expires on %s (%d days)", s.label, product.Sku, s.expiry.Format("2006-01-02"), days)
+	arg := repository.UpsertInventoryAlertParams{
+		AlertType:        inventory.AlertNearExpiry,
+		StoreID:          s.storeID,
+		ProductID:        product.ID,
+		ProductVariantID: s.variantID,
+		BatchID:          s.batchID,
+		SerialNumberID:   s.serialID,
+		ExpiryDate:       pgtype.Date{Time: s.expiry, Valid: true},
+		DaysUntilExpiry:  pgtype.Int4{Int32: int32(days), Valid: true},
+		Quantity:         s.quantity,
+		MarkdownPercent:  utils.RatToNumeric(pct, 2),
+		LastEvaluatedAt:  pgtype.Timestamp{Time: c.now, Valid: true},
+	}
+
+	r, ok := c.resolvers[s.storeID]
+	if !ok {
+		var err error
+		if r, err = newPriceResolver(ctx, c.q, saleContext{storeID: s.storeID, date: c.now}); err != nil {
+			return false, err
+		}
+		c.resolvers[s.storeID] = r
+	}
+	res, err := r.resolve(ctx, product, s.variantID.Int32, 0, big.NewRat(1, 1))
+	switch {
+	case errors.Is(err, pricing.ErrNoPrice):
+	case err != nil:
+		return false, err
+	default:
+		suggested := inventory.MarkdownPrice(res.Price, pct)
+		arg.CurrentPrice = utils.RatToNumeric(res.Price, 2)
+		arg.SuggestedPrice = utils.RatToNumeric(suggested, 2)
+		if pct.Sign() > 0 {
+			msg += fmt.Sprintf("; mark down %s%% to %s", pct.FloatString(0), suggested.FloatString(2))
+		}
+	}
+	arg.Message = optionalText(msg)
+
+	if err := c.q.UpsertInventoryAlert(ctx, arg); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// loadExpiryRules loads the tenant's active expiry rules and the category
+// tree they are inherited through.
+func loadExpiryRules(ctx context.Context, q *repository.Queries) (inventory.ExpiryRules, error) {
+	rules := inventory.ExpiryRules{
+		Default:    inventory.DefaultExpiryRule,
+		ByCategory: map[int32]inventory.ExpiryRule{},
+		Parents:    map[int32]int32{},
+	}
+	rows, err := q.ListExpiryAlertRules(ctx)
+	if err != nil {
+		return rules, fmt.Errorf("load expiry rules: %w", err)
+	}
+	for _, row := range rows {
+		rule := inventory.ExpiryRule{
+			NearExpiryDays: int(row.NearExpiryDays),
+			MaxMarkdown:    utils.NumericToRat(row.MaxMarkdownPercent),
+		}
+		if row.CategoryID.Valid {
+			rules.ByCategory[row.CategoryID.Int32] = rule
+		} else {
+			rules.Default = rule
+		}
+	}
+	parents, err := q.ListCategoryParents(ctx)
+	if err != nil {
+		return rules, fmt.Errorf("load categories: %w", err)
+	}
+	for _, p := range parents {
+		if p.ParentCategoryID.Valid {
+			rules.Parents[p.ID] = p.ParentCategoryID.Int32
+		}
+	}
+	return rules, nil
+}
+
+// ExpiryRuleInput sets the expiry rule of a category, or the default rule
+// when CategoryID is nil.
+type ExpiryRuleInput struct {
+	CategoryID         *int32
+	NearExpiryDays     int32
+	MaxMarkdownPercent string
+}
+
+// ListExpiryRules returns the active expiry rules, the default rule first.
+func (uc *ExpiryAlertUseCase) ListExpiryRules(ctx context.Context) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	rules, err := uc.repo.ListExpiryAlertRules(ctx)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if rules == nil {
+		rules = []repository.ExpiryAlertRule{}
+	}
+	return utils.NewResponse(utils.CodeOK, "expiry rules retrieved successfully", rules)
+}
+
+// SetExpiryRule creates or replaces the expiry rule of a category or the
+// default rule.
+func (uc *ExpiryAlertUseCase) SetExpiryRule(ctx context.Context, in *ExpiryRuleInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if in.NearExpiryDays <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "near_expiry_days must be positive", nil)
+	}
+	markdown, err := parseAmount("max_markdown_percent", in.MaxMarkdownPercent)
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	if markdown.Cmp(big.NewRat(100, 1)) >= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "max_markdown_percent must be below 100", nil)
+	}
+	if in.CategoryID != nil {
+		if _, err := uc.repo.GetCategoryWithPath(ctx, *in.CategoryID); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "category not found", nil)
+		}
+	}
+
+	categoryID := optionalInt4(in.CategoryID)
+	n, err := uc.repo.UpdateExpiryAlertRule(ctx, repository.UpdateExpiryAlertRuleParams{
+		NearExpiryDays:     in.NearExpiryDays,
+		MaxMarkdownPercent: utils.RatToNumeric(markdown, 2),
+		CategoryID:         categoryID,
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if n == 0 {
+		if _, err := uc.repo.CreateExpiryAlertRule(ctx, repository.CreateExpiryAlertRuleParams{
+			CategoryID:         categoryID,
+			NearExpiryDays:     in.NearExpiryDays,
+			MaxMarkdownPercent: utils.RatToNumeric(markdown, 2),
+		}); err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+	}
+	return uc.ListExpiryRules(ctx)
+}
+
+// DeleteExpiryRule removes a category's rule so it inherits again.
+func (uc *ExpiryAlertUseCase) DeleteExpiryRule(ctx context.Context, categoryID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	n, err := uc.repo.DeleteExpiryAlertRule(ctx, pgtype.Int4{Int32: categoryID, Valid: true})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if n == 0 {
+		return utils.NewResponse(utils.CodeNotFound, "expiry rule not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "expiry rule deleted successfully", nil)
+}
+
+// ListStoreAlerts returns a store's alerts inbox, soonest expiry first.
+// Without a status, resolved alerts are left out.
+func (uc *ExpiryAlertUseCase) ListStoreAlerts(ctx context.Context, storeID int32, status, alertType string, limit, offset int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetStore(ctx, storeID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+	}
+	if resp := checkStoreInScope(ctx, uc.repo, PermissionViewStock, storeID); resp != nil {
+		return resp
+	}
+	alerts, err := uc.repo.ListStoreInventoryAlerts(ctx, repository.ListStoreInventoryAlertsParams{
+		StoreID:   storeID,
+		Status:    optionalText(status),
+		AlertType: optionalText(alertType),
+		Limit:     limit,
+		Offset:    offset,
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if alerts == nil {
+		alerts = []repository.ListStoreInventoryAlertsRow{}
+	}
+	return utils.NewResponse(utils.CodeOK, "alerts retrieved successfully", alerts)
+}
+
+// UpdateAlertStatus acknowledges or resolves an alert.
+func (uc *ExpiryAlertUseCase) UpdateAlertStatus(ctx context.Context, id int32, status string, userID *int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	status = strings.ToLower(strings.TrimSpace(status))
+	if status != "acknowledged" && status != "resolved" {
+		return utils.NewResponse(utils.CodeBadReq, "status must be acknowledged or resolved", nil)
+	}
+	alert, err := uc.repo.UpdateInventoryAlertStatus(ctx, repository.UpdateInventoryAlertStatusParams{
+		Status: status,
+		UserID: optionalInt4(userID),
+		ID:     id,
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "alert not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "alert updated successfully", alert)
+}
@@ -0,0 +1,350 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/inventory"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// InventoryUseCase moves stock between stores and traces serialized units.
+type InventoryUseCase struct {
+	repo *repository.Queries
+}
+
+// NewInventoryUseCase creates a new inventory use case.
+func NewInventoryUseCase() *InventoryUseCase {
+	return &InventoryUseCase{}
+}
+
+// SetRepository injects repository per request
+func (uc *InventoryUseCase) SetRepository(repo *repository.Queries) {
+	uc.repo = repo
+}
+
+// StockTransferLine is one product of a stock transfer. BatchNumber
+// overrides the first-expiry-first-out allocation of batch-managed products
+// and needs the batch override permission; serialized products need one
+// serial number per unit.
+type StockTransferLine struct {
+	ProductID        int32
+	ProductVariantID *int32
+	Quantity         string
+	BatchNumber      string
+	SerialNumbers    []string
+}
+
+// StockTransferInput is the input for TransferStock.
+type StockTransferInput struct {
+	UserID      *int32
+	FromStoreID int32
+	ToStoreID   int32
+	Notes       string
+	Lines       []StockTransferLine
+}
+
+// TransferredBatch is the quantity of one batch moved by a transfer.
+type TransferredBatch struct {
+	BatchNumber string  `json:"batch_number"`
+	ExpiryDate  *string `json:"expiry_date,omitempty"`
+	Quantity    string  `json:"quantity"`
+}
+
+// TransferredLine is one product moved by a transfer.
+type TransferredLine struct {
+	ProductID        int32              `json:"product_id"`
+	ProductVariantID *int32             `json:"product_variant_id,omitempty"`
+	Quantity         string             `json:"quantity"`
+	Batches          []TransferredBatch `json:"batches,omitempty"`
+	SerialNumbers    []string           `json:"serial_numbers,omitempty"`
+}
+
+// StockTransferResult is returned by TransferStock.
+type StockTransferResult struct {
+	TransferNumber string            `json:"transfer_number"`
+	FromStoreID    int32             `json:"from_store_id"`
+	ToStoreID      int32             `json:"to_store_id"`
+	Lines          []TransferredLine `json:"lines"`
+}
+
+// TransferStock moves stock from one store of the active organization to
+// another in one database transaction, writing a transfer stock movement per
+// product (per batch for batch-managed products, per unit for serialized
+// ones). Batches are taken first-expiry-first-out and expired batches are
+// never moved; the receiving store gets the same batch numbers and dates.
+// Serialized units must be on hand in the source store and move to the
+// destination.
+func (uc *InventoryUseCase) TransferStock(ctx context.Context, in *StockTransferInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if len(in.Lines) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "transfer has no lines", nil)
+	}
+	if in.FromStoreID == in.ToStoreID {
+		return utils.NewResponse(utils.CodeBadReq, "source and destination store must differ", nil)
+	}
+	from, err := uc.repo.GetStore(ctx, in.FromStoreID)
+	if err != nil || !inActiveOrganization(ctx, from.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "source store not found", nil)
+	}
+	to, err := uc.repo.GetStore(ctx, in.ToStoreID)
+	if err != nil || !inActiveOrganization(ctx, to.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "destination store not found", nil)
+	}
+
+	products := make([]repository.Product, len(in.Lines))
+	qtys := make([]*big.Rat, len(in.Lines))
+	units := make([][]repository.ProductSerialNumber, len(in.Lines))
+	seen := serialCapture{}
+	for i, l := range in.Lines {
+		product, err := uc.repo.GetProduct(ctx, l.ProductID)
+		if err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+		}
+		if !product.TrackInventory.Bool {
+			return utils.NewResponse(utils.CodeBadReq, "product "+product.Sku+" does not track inventory", nil)
+		}
+		qty, err := parseQuantity(product, l.Quantity)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		if strings.TrimSpace(l.BatchNumber) != "" && product.IsBatchManaged.Bool {
+			if resp := checkBatchOverride(ctx, uc.repo, in.UserID); resp != nil {
+				return resp
+			}
+		}
+		if product.IsSerialized.Bool {
+			serials, err := seen.capture(product, l.SerialNumbers, qty)
+			if err == nil {
+				units[i], err = lookupSerials(ctx, uc.repo, product, optionalInt4(l.ProductVariantID), from.ID, serials, "")
+			}
+			if err != nil {
+				return checkoutError(err)
+			}
+			for _, u := range units[i] {
+				if !inventory.Sellable(u.Status.String) {
+					return utils.NewResponse(utils.CodeBadReq, "serial number "+u.SerialNumber+" is not in stock", nil)
+				}
+			}
+		}
+		products[i] = product
+		qtys[i] = qty
+	}
+
+	now := time.Now()
+	result := &StockTransferResult{
+		TransferNumber: documentNumber("TR", now),
+		FromStoreID:    from.ID,
+		ToStoreID:      to.ID,
+	}
+	meta, _ := json.Marshal(map[string]interface{}{
+		"transfer_number": result.TransferNumber,
+		"notes":           strings.TrimSpace(in.Notes),
+	})
+
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		for i, l := range in.Lines {
+			product := products[i]
+			variantID := optionalInt4(l.ProductVariantID)
+			line := TransferredLine{
+				ProductID:        product.ID,
+				ProductVariantID: l.ProductVariantID,
+				Quantity:         qtys[i].FloatString(3),
+			}
+
+			parts := []soldLine{{quantity: qtys[i]}}
+			if product.IsBatchManaged.Bool {
+				allocs, err := allocateBatches(ctx, q, product, variantID, from.ID, qtys[i], strings.TrimSpace(l.BatchNumber), now)
+				if err != nil {
+					return err
+				}
+				parts = parts[:0]
+				for j, a := range allocs {
+					parts = append(parts, soldLine{quantity: a.Quantity, batch: a.Batch.Number, alloc: &allocs[j]})
+					tb := TransferredBatch{BatchNumber: a.Batch.Number, Quantity: a.Quantity.FloatString(3)}
+					if a.Batch.Expiry != nil {
+						d := a.Batch.Expiry.Format("2006-01-02")
+						tb.ExpiryDate = &d
+					}
+					line.Batches = append(line.Batches, tb)
+				}
+			}
+			if units[i] != nil {
+				var err error
+				if parts, err = splitUnits(parts, units[i]); err != nil {
+					return err
+				}
+			}
+			for _, p := range parts {
+				if p.alloc != nil {
+					if err := consumeBatch(ctx, q, *p.alloc, now); err != nil {
+						return err
+					}
+					if _, err := q.ReceiveBatchQuantity(ctx, repository.ReceiveBatchQuantityParams{
+						ProductID:         product.ID,
+						ProductVariantID:  variantID,
+						BatchNumber:       p.alloc.Batch.Number,
+						ManufacturingDate: optionalDate(p.alloc.Batch.Manufactured),
+						ExpiryDate:        optionalDate(p.alloc.Batch.Expiry),
+						StoreID:           pgtype.Int4{Int32: to.ID, Valid: true},
+						QuantityAvailable: utils.RatToNumeric(p.quantity, 3),
+					}); err != nil {
+						return err
+					}
+				}
+				if p.unit != nil {
+					if err := moveSerial(ctx, q, *p.unit, p.unit.Status.String, pgtype.Int4{Int32: to.ID, Valid: true}); err != nil {
+						return err
+					}
+					line.SerialNumbers = append(line.SerialNumbers, p.serial)
+				}
+				if _, err := q.CreateStockMovement(ctx, repository.CreateStockMovementParams{
+					MovementType:     "transfer",
+					ReferenceType:    pgtype.Text{String: "stock_transfer", Valid: true},
+					ProductID:        product.ID,
+					ProductVariantID: variantID,
+					FromStoreID:      pgtype.Int4{Int32: from.ID, Valid: true},
+					ToStoreID:        pgtype.Int4{Int32: to.ID, Valid: true},
+					Quantity:         utils.RatToNumeric(p.quantity, 3),
+					UomID:            product.BaseUomID,
+					BatchNumber:      optionalText(p.batch),
+					SerialNumber:     optionalText(p.serial),
+					MovementDate:     pgtype.Timestamp{Time: now, Valid: true},
+					PostedBy:         optionalInt4(in.UserID),
+					Status:           pgtype.Text{String: "completed", Valid: true},
+					Metadata:         meta,
+				}); err != nil {
+					return err
+				}
+			}
+
+			if err := moveStock(ctx, q, product, variantID, from.ID, to.ID, qtys[i]); err != nil {
+				return err
+			}
+			result.Lines = append(result.Lines, line)
+		}
+		return nil
+	})
+	if err != nil {
+		return checkoutError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "stock transferred", result)
+}
+
+// moveStock takes qty off the source store's stock row and adds it to the
+// destination's.
+func moveStock(ctx context.Context, q *repository.Queries, product repository.Product, variantID pgtype.Int4, fromID, toID int32, qty *big.Rat) error {
+	n, err := q.AdjustInventoryStock(ctx, repository.AdjustInventoryStockParams{
+		QuantityDelta:    utils.RatToNumeric(new(big.Rat).Neg(qty), 3),
+		ProductID:        product.ID,
+		StoreID:          fromID,
+		ProductVariantID: variantID,
+	})
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return documentInputErrorf("product %s has no stock in the source store", product.Sku)
+	}
+	return addStock(ctx, q, product.ID, variantID, toID, qty)
+}
+
+// addStock adds qty to a store's stock row for a product, opening the row
+// when the store has none.
+func addStock(ctx context.Context, q *repository.Queries, productID int32, variantID pgtype.Int4, storeID int32, qty *big.Rat) error {
+	n, err := q.AdjustInventoryStock(ctx, repository.AdjustInventoryStockParams{
+		QuantityDelta:    utils.RatToNumeric(qty, 3),
+		ProductID:        productID,
+		StoreID:          storeID,
+		ProductVariantID: variantID,
+	})
+	if err != nil || n > 0 {
+		return err
+	}
+	return q.CreateInventoryStock(ctx, repository.CreateInventoryStockParams{
+		ProductID:        productID,
+		ProductVariantID: variantID,
+		StoreID:          storeID,
+		Quantity:         utils.RatToNumeric(qty, 3),
+	})
+}
+
+// SerialTrace is the life of one serialized unit.
+type SerialTrace struct {
+	Serial      repository.ProductSerialNumber         `json:"serial"`
+	ProductSku  string                                 `json:"product_sku"`
+	ProductName string                                 `json:"product_name"`
+	Movements   []repository.GetSerialNumberHistoryRow `json:"movements"`
+}
+
+// TraceSerialNumber returns a serialized unit with its current status and
+// store and every stock movement of it, oldest first, each with the number
+// of the sale, return, purchase order or transfer it belongs to. The unit's
+// store, or for units no longer in a store the store of its last movement,
+// must be in the active organization and in the stock scope of the signed-in
+// user, see DataScope; only movements touching such stores are listed.
+func (uc *InventoryUseCase) TraceSerialNumber(ctx context.Context, serial string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	scope, resp := dataScope(ctx, uc.repo, PermissionViewStock)
+	if resp != nil {
+		return resp
+	}
+	unit, err := uc.repo.GetProductSerialNumberBySerial(ctx, strings.TrimSpace(serial))
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "serial number not found", nil)
+	}
+	product, err := uc.repo.GetProduct(ctx, unit.ProductID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	history, err := uc.repo.GetSerialNumberHistory(ctx, pgtype.Text{String: unit.SerialNumber, Valid: true})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	allowed := map[int32]bool{}
+	inScope := func(storeID pgtype.Int4) bool {
+		if !storeID.Valid {
+			return false
+		}
+		ok, seen := allowed[storeID.Int32]
+		if !seen {
+			store, err := uc.repo.GetStore(ctx, storeID.Int32)
+			ok = err == nil && inActiveOrganization(ctx, store.OrganizationID) && scope.AllowsStore(store.ID)
+			allowed[storeID.Int32] = ok
+		}
+		return ok
+	}
+	storeID := unit.CurrentStoreID
+	for i := len(history) - 1; i >= 0 && !storeID.Valid; i-- {
+		storeID = history[i].ToStoreID
+		if !storeID.Valid {
+			storeID = history[i].FromStoreID
+		}
+	}
+	if !inScope(storeID) {
+		return utils.NewResponse(utils.CodeNotFound, "serial number not found", nil)
+	}
+	movements := []repository.GetSerialNumberHistoryRow{}
+	for _, m := range history {
+		if inScope(m.FromStoreID) || inScope(m.ToStoreID) {
+			movements = append(movements, m)
+		}
+	}
+	return utils.NewResponse(utils.CodeOK, "serial number traced successfully", &SerialTrace{
+		Serial:      unit,
+		ProductSku:  product.Sku,
+		ProductName: product.Name,
+		Movements:   movements,
+	})
+}
@@ -0,0 +1,156 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// LockoutPolicy locks out login names and IP addresses after failed logins.
+// After MaxFailures failed attempts at a login name, or MaxFailuresPerIP
+// from an address, within Window, further attempts are refused for Lockout
+// after the last failure, doubling with every further failure up to
+// MaxLockout. A successful login clears the login name's failures, not the
+// address's. Zero maximums disable the lockout.
+type LockoutPolicy struct {
+	MaxFailures      int
+	MaxFailuresPerIP int
+	Window           time.Duration
+	Lockout          time.Duration
+	MaxLockout       time.Duration
+}
+
+// lockedUntil returns when a login name or address with failures failed
+// attempts within the window, the last at last, may try again. The zero
+// time means it isn't locked out.
+func (p LockoutPolicy) lockedUntil(failures, maxFailures int, last time.Time) time.Time {
+	if maxFailures <= 0 || failures < maxFailures || last.IsZero() {
+		return time.Time{}
+	}
+	d := p.Lockout
+	for i := maxFailures; i < failures && (p.MaxLockout <= 0 || d < p.MaxLockout); i++ {
+		d *= 2
+	}
+	if p.MaxLockout > 0 && d > p.MaxLockout {
+		d = p.MaxLockout
+	}
+	return last.Add(d)
+}
+
+// LoginClient identifies where a login attempt comes from, for the lockout
+// and the login audit.
+type LoginClient struct {
+	IP        string
+	UserAgent string
+}
+
+// LoginLockout is the data of the response refusing a locked out attempt.
+type LoginLockout struct {
+	RetryAfter int `json:"retry_after"`
+}
+
+// Reasons of failed attempts recorded in login_audit.failure_reason.
+// Attempts failing for reasonLocked and reasonPasswordExpired don't count
+// towards the lockout.
+const (
+	reasonUnknownUser     = "unknown_user"
+	reasonNoPassword      = "no_password"
+	reasonWrongPassword   = "wrong_password"
+	reasonInactive        = "inactive"
+	reasonLocked          = "locked"
+	reasonPasswordExpired = "password_expired"
+)
+
+// dummyPasswordHash is compared against when there is no hash to check, so
+// that unknown login names take as long to refuse as wrong passwords.
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+
+// checkLockout returns a too many requests response when userLogin or the
+// client's address is locked out, recording the refused attempt.
+func (uc *AuthUseCase) checkLockout(ctx context.Context, userLogin string, client LoginClient, now time.Time) *repository.Response {
+	since := pgtype.Timestamp{Valid: true}
+	if uc.lockout.Window > 0 {
+		since.Time = now.Add(-uc.lockout.Window)
+	}
+
+	var until time.Time
+	if uc.lockout.MaxFailures > 0 {
+		byLogin, err := uc.repo.GetLoginFailuresByLogin(ctx, repository.GetLoginFailuresByLoginParams{UserLogin: userLogin, Since: since})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		until = uc.lockout.lockedUntil(int(byLogin.Failures), uc.lockout.MaxFailures, byLogin.LastFailure.Time)
+	}
+	if uc.lockout.MaxFailuresPerIP > 0 && client.IP != "" {
+		byIP, err := uc.repo.GetLoginFailuresByIP(ctx, repository.GetLoginFailuresByIPParams{IpAddress: optionalText(client.IP), Since: since})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		if t := uc.lockout.lockedUntil(int(byIP.Failures), uc.lockout.MaxFailuresPerIP, byIP.LastFailure.Time); t.After(until) {
+			until = t
+		}
+	}
+	if !until.After(now) {
+		return nil
+	}
+
+	if err := uc.audit(ctx, nil, userLogin, reasonLocked, client, now); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	retryAfter := int(until.Sub(now).Seconds()) + 1
+	return utils.NewResponse(utils.CodeTooManyRequests, "too many failed login attempts, try again later", LoginLockout{RetryAfter: retryAfter})
+}
+
+// authenticate checks userLogin's password. Unknown login names, users
+// without a password, wrong passwords and inactive users are recorded with
+// their reason but answered alike, so callers can't tell them apart.
+func (uc *AuthUseCase) authenticate(ctx context.Context, userLogin, password string, client LoginClient, now time.Time) (repository.User, *repository.Response) {
+	reason := ""
+	user, err := uc.repo.GetUserByUsername(ctx, userLogin)
+	switch {
+	case err != nil && !errors.Is(err, pgx.ErrNoRows):
+		return user, utils.NewResponse(utils.CodeError, err.Error(), nil)
+	case err != nil:
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		reason = reasonUnknownUser
+	case user.PasswordHash == "":
+		bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		reason = reasonNoPassword
+	case bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)) != nil:
+		reason = reasonWrongPassword
+	case !user.IsActive.Valid || !user.IsActive.Bool:
+		reason = reasonInactive
+	}
+	if reason == "" {
+		return user, nil
+	}
+
+	var userID *int32
+	if err == nil {
+		userID = &user.ID
+	}
+	if err := uc.audit(ctx, userID, userLogin, reason, client, now); err != nil {
+		return user, utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return user, utils.NewResponse(utils.CodeUnauthorized, "invalid credentials", nil)
+}
+
+// audit records a login attempt; an empty reason records a success.
+func (uc *AuthUseCase) audit(ctx context.Context, userID *int32, userLogin, reason string, client LoginClient, now time.Time) error {
+	return uc.repo.CreateLoginAudit(ctx, repository.CreateLoginAuditParams{
+		UserID:        optionalInt4(userID),
+		UserLogin:     userLogin,
+		Success:       reason == "",
+		FailureReason: optionalText(reason),
+		IpAddress:     optionalText(client.IP),
+		UserAgent:     optionalText(client.UserAgent),
+		CreatedAt:     pgtype.Timestamp{Time: now, Valid: true},
+	})
+}
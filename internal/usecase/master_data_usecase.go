@@ -0,0 +1,492 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// MasterDataUseCase administers the brands, units of measure and tax
+// categories that products refer to. Tax category changes are audited.
+type MasterDataUseCase struct {
+	repo *repository.Queries
+}
+
+// NewMasterDataUseCase creates a new master data use case.
+func NewMasterDataUseCase() *MasterDataUseCase {
+	return &MasterDataUseCase{}
+}
+
+// SetRepository injects repository per request
+func (uc *MasterDataUseCase) SetRepository(repo *repository.Queries) {
+	uc.repo = repo
+}
+
+// Tax category change actions.
+const (
+	TaxChangeCreated = "created"
+	TaxChangeUpdated = "updated"
+	TaxChangeDeleted = "deleted"
+)
+
+// ---- Brands ----
+
+// BrandInput is the input for CreateBrand and UpdateBrand. The code is only
+// read on create.
+type BrandInput struct {
+	Name     string
+	Code     string
+	IsActive *bool
+}
+
+// ListBrands returns brands with their active product and category counts,
+// most used first. A nil active lists both active and inactive brands.
+func (uc *MasterDataUseCase) ListBrands(ctx context.Context, active *bool, search string, limit, offset int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	rows, err := uc.repo.ListBrandsWithStats(ctx, repository.ListBrandsWithStatsParams{
+		ActiveOnly: optionalBool(active),
+		Search:     strings.TrimSpace(search),
+		Limit:      limit,
+		Offset:     offset,
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "brands fetched successfully", rows)
+}
+
+// GetBrand returns a brand.
+func (uc *MasterDataUseCase) GetBrand(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	b, err := uc.repo.GetBrand(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "brand not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "brand fetched successfully", b)
+}
+
+// CreateBrand creates a brand with a unique code.
+func (uc *MasterDataUseCase) CreateBrand(ctx context.Context, in *BrandInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	name, code := strings.TrimSpace(in.Name), strings.TrimSpace(in.Code)
+	if name == "" || code == "" {
+		return utils.NewResponse(utils.CodeBadReq, "name and code are required", nil)
+	}
+	if _, err := uc.repo.GetBrandByCode(ctx, code); err == nil {
+		return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("brand code %s is already in use", code), nil)
+	}
+	b, err := uc.repo.CreateBrand(ctx, repository.CreateBrandParams{
+		Name:     name,
+		Code:     code,
+		IsActive: boolOr(in.IsActive, true),
+		Metadata: []byte("{}"),
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "brand created successfully", b)
+}
+
+// UpdateBrand renames a brand or (de)activates it.
+func (uc *MasterDataUseCase) UpdateBrand(ctx context.Context, id int32, in *BrandInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetBrand(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "brand not found", nil)
+	}
+	b, err := uc.repo.UpdateBrand(ctx, repository.UpdateBrandParams{
+		ID:       id,
+		Name:     optionalText(in.Name),
+		IsActive: optionalBool(in.IsActive),
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "brand updated successfully", b)
+}
+
+// DeleteBrand deletes a brand no product refers to; deactivate a brand in
+// use instead.
+func (uc *MasterDataUseCase) DeleteBrand(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetBrand(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "brand not found", nil)
+	}
+	n, err := uc.repo.CountBrandProducts(ctx, pgtype.Int4{Int32: id, Valid: true})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if n > 0 {
+		return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("brand is used by %d products; deactivate it instead", n), nil)
+	}
+	if err := uc.repo.DeleteBrand(ctx, id); err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "brand deleted successfully", nil)
+}
+
+// ---- Units of measure ----
+
+// UnitInput is the input for CreateUnit and UpdateUnit. The code is only
+// read on create.
+type UnitInput struct {
+	Code          string
+	Name          string
+	UomType       *string
+	DecimalPlaces *int32
+	IsActive      *bool
+}
+
+// maxUnitDecimalPlaces matches the scale of quantity columns.
+const maxUnitDecimalPlaces = 6
+
+// ListUnits returns units of measure with the number of products and unit
+// conversions using them. A nil active lists both active and inactive
+// units.
+func (uc *MasterDataUseCase) ListUnits(ctx context.Context, active *bool, uomType string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	rows, err := uc.repo.ListUnitsOfMeasureWithUsage(ctx, repository.ListUnitsOfMeasureWithUsageParams{
+		ActiveOnly: optionalBool(active),
+		UomType:    optionalText(uomType),
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "units of measure fetched successfully", rows)
+}
+
+// GetUnit returns a unit of measure.
+func (uc *MasterDataUseCase) GetUnit(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	u, err := uc.repo.GetUnitOfMeasure(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "unit of measure not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "unit of measure fetched successfully", u)
+}
+
+// CreateUnit creates a unit of measure with a unique code.
+func (uc *MasterDataUseCase) CreateUnit(ctx context.Context, in *UnitInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	name, code := strings.TrimSpace(in.Name), strings.TrimSpace(in.Code)
+	if name == "" || code == "" {
+		return utils.NewResponse(utils.CodeBadReq, "name and code are required", nil)
+	}
+	if _, err := uc.repo.GetUnitOfMeasureByCode(ctx, code); err == nil {
+		return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("unit code %s is already in use", code), nil)
+	}
+	arg := repository.CreateUnitOfMeasureParams{
+		Code:          code,
+		Name:          name,
+		DecimalPlaces: pgtype.Int4{Int32: 2, Valid: true},
+		IsActive:      boolOr(in.IsActive, true),
+		Metadata:      []byte("{}"),
+	}
+	if in.UomType != nil {
+		arg.UomType = optionalText(*in.UomType)
+	}
+	if in.DecimalPlaces != nil {
+		if *in.DecimalPlaces < 0 || *in.DecimalPlaces > maxUnitDecimalPlaces {
+			return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("decimal_places must be between 0 and %d", maxUnitDecimalPlaces), nil)
+		}
+		arg.DecimalPlaces.Int32 = *in.DecimalPlaces
+	}
+	u, err := uc.repo.CreateUnitOfMeasure(ctx, arg)
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "unit of measure created successfully", u)
+}
+
+// UpdateUnit changes a unit's name, type, decimal places or status;
+// omitted fields keep their value.
+func (uc *MasterDataUseCase) UpdateUnit(ctx context.Context, id int32, in *UnitInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	u, err := uc.repo.GetUnitOfMeasure(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "unit of measure not found", nil)
+	}
+	arg := repository.UpdateUnitOfMeasureParams{
+		ID:            id,
+		Name:          u.Name,
+		UomType:       u.UomType,
+		DecimalPlaces: u.DecimalPlaces,
+		IsActive:      u.IsActive,
+		Metadata:      u.Metadata,
+	}
+	if name := strings.TrimSpace(in.Name); name != "" {
+		arg.Name = name
+	}
+	if in.UomType != nil {
+		arg.UomType = optionalText(*in.UomType)
+	}
+	if in.DecimalPlaces != nil {
+		if *in.DecimalPlaces < 0 || *in.DecimalPlaces > maxUnitDecimalPlaces {
+			return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("decimal_places must be between 0 and %d", maxUnitDecimalPlaces), nil)
+		}
+		arg.DecimalPlaces = pgtype.Int4{Int32: *in.DecimalPlaces, Valid: true}
+	}
+	if in.IsActive != nil {
+		arg.IsActive = pgtype.Bool{Bool: *in.IsActive, Valid: true}
+	}
+	u, err = uc.repo.UpdateUnitOfMeasure(ctx, arg)
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "unit of measure updated successfully", u)
+}
+
+// DeleteUnit deletes a unit no product, unit conversion or price refers
+// to; deactivate a unit in use instead.
+func (uc *MasterDataUseCase) DeleteUnit(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetUnitOfMeasure(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "unit of measure not found", nil)
+	}
+	usage, err := uc.repo.GetUnitOfMeasureUsage(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if usage.ProductCount > 0 || usage.ConversionCount > 0 || usage.PriceCount > 0 {
+		return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("unit is used by %d products, %d unit conversions and %d prices; deactivate it instead",
+			usage.ProductCount, usage.ConversionCount, usage.PriceCount), usage)
+	}
+	if err := uc.repo.DeleteUnitOfMeasure(ctx, id); err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "unit of measure deleted successfully", nil)
+}
+
+// ---- Tax categories ----
+
+// TaxCategoryInput is the input for CreateTaxCategory and
+// UpdateTaxCategory. The code is only read on create.
+type TaxCategoryInput struct {
+	Name        string
+	Code        string
+	TaxRate     string
+	IsInclusive *bool
+	IsActive    *bool
+}
+
+// ListTaxCategories returns tax categories with the number of products in
+// each. A nil active lists both active and inactive categories.
+func (uc *MasterDataUseCase) ListTaxCategories(ctx context.Context, active *bool) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	rows, err := uc.repo.ListTaxCategoriesWithUsage(ctx, optionalBool(active))
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "tax categories fetched successfully", rows)
+}
+
+// GetTaxCategory returns a tax category.
+func (uc *MasterDataUseCase) GetTaxCategory(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	tc, err := uc.repo.GetTaxCategory(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "tax category not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "tax category fetched successfully", tc)
+}
+
+// CreateTaxCategory creates a tax category with a unique code and records
+// who created it.
+func (uc *MasterDataUseCase) CreateTaxCategory(ctx context.Context, in *TaxCategoryInput, userID *int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	name, code := strings.TrimSpace(in.Name), strings.TrimSpace(in.Code)
+	if name == "" || code == "" {
+		return utils.NewResponse(utils.CodeBadReq, "name and code are required", nil)
+	}
+	rate, err := parseTaxRate(in.TaxRate)
+	if err != nil {
+		return checkoutError(err)
+	}
+	if _, err := uc.repo.GetTaxCategoryByCode(ctx, code); err == nil {
+		return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("tax category code %s is already in use", code), nil)
+	}
+
+	var tc repository.TaxCategory
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		tc, err = q.CreateTaxCategory(ctx, repository.CreateTaxCategoryParams{
+			Name:        name,
+			Code:        code,
+			TaxRate:     rate,
+			IsInclusive: boolOr(in.IsInclusive, false),
+			IsActive:    boolOr(in.IsActive, true),
+			Metadata:    []byte("{}"),
+		})
+		if err != nil {
+			return err
+		}
+		return recordTaxChange(ctx, q, TaxChangeCreated, nil, &tc, userID)
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "tax category created successfully", tc)
+}
+
+// UpdateTaxCategory changes a tax category; omitted fields keep their
+// value. A change to the rate, the inclusive flag or the status is
+// recorded with the user who made it.
+func (uc *MasterDataUseCase) UpdateTaxCategory(ctx context.Context, id int32, in *TaxCategoryInput, userID *int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	old, err := uc.repo.GetTaxCategory(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "tax category not found", nil)
+	}
+	arg := repository.UpdateTaxCategoryParams{
+		ID:          id,
+		Name:        old.Name,
+		TaxRate:     old.TaxRate,
+		IsInclusive: old.IsInclusive,
+		IsActive:    old.IsActive,
+		Metadata:    old.Metadata,
+	}
+	if name := strings.TrimSpace(in.Name); name != "" {
+		arg.Name = name
+	}
+	if strings.TrimSpace(in.TaxRate) != "" {
+		if arg.TaxRate, err = parseTaxRate(in.TaxRate); err != nil {
+			return checkoutError(err)
+		}
+	}
+	if in.IsInclusive != nil {
+		arg.IsInclusive = pgtype.Bool{Bool: *in.IsInclusive, Valid: true}
+	}
+	if in.IsActive != nil {
+		arg.IsActive = pgtype.Bool{Bool: *in.IsActive, Valid: true}
+	}
+
+	var tc repository.TaxCategory
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		if tc, err = q.UpdateTaxCategory(ctx, arg); err != nil {
+			return err
+		}
+		if utils.NumericToRat(old.TaxRate).Cmp(utils.NumericToRat(tc.TaxRate)) == 0 &&
+			old.IsInclusive.Bool == tc.IsInclusive.Bool && old.IsActive.Bool == tc.IsActive.Bool {
+			return nil
+		}
+		return recordTaxChange(ctx, q, TaxChangeUpdated, &old, &tc, userID)
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "tax category updated successfully", tc)
+}
+
+// DeleteTaxCategory deletes a tax category no product refers to and
+// records the delete; deactivate a category in use instead.
+func (uc *MasterDataUseCase) DeleteTaxCategory(ctx context.Context, id int32, userID *int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	old, err := uc.repo.GetTaxCategory(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "tax category not found", nil)
+	}
+	n, err := uc.repo.CountTaxCategoryProducts(ctx, pgtype.Int4{Int32: id, Valid: true})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if n > 0 {
+		return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("tax category is used by %d products; deactivate it instead", n), nil)
+	}
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		if err := recordTaxChange(ctx, q, TaxChangeDeleted, &old, nil, userID); err != nil {
+			return err
+		}
+		return q.DeleteTaxCategory(ctx, id)
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "tax category deleted successfully", nil)
+}
+
+// ListTaxCategoryChanges returns the audit trail of a tax category code,
+// newest first. It stays available after the category is deleted.
+func (uc *MasterDataUseCase) ListTaxCategoryChanges(ctx context.Context, code string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	rows, err := uc.repo.ListTaxCategoryChanges(ctx, strings.TrimSpace(code))
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "tax category changes fetched successfully", rows)
+}
+
+// parseTaxRate parses a percentage between 0 and 100.
+func parseTaxRate(s string) (pgtype.Numeric, error) {
+	rate, err := parseAmount("tax_rate", s)
+	if err != nil {
+		return pgtype.Numeric{}, err
+	}
+	// tax_rate is DECIMAL(5,2).
+	if rate.Cmp(big.NewRat(100, 1)) > 0 || rate.Cmp(utils.NumericToRat(utils.RatToNumeric(rate, 2))) != 0 {
+		return pgtype.Numeric{}, documentInputErrorf("tax_rate must be a percentage between 0 and 100 with at most 2 decimals")
+	}
+	return utils.RatToNumeric(rate, 2), nil
+}
+
+// recordTaxChange writes an audit row; before is nil on create and after
+// nil on delete.
+func recordTaxChange(ctx context.Context, q *repository.Queries, action string, before, after *repository.TaxCategory, userID *int32) error {
+	arg := repository.CreateTaxCategoryChangeParams{Action: action, Metadata: []byte("{}")}
+	if before != nil {
+		arg.TaxCategoryID = pgtype.Int4{Int32: before.ID, Valid: true}
+		arg.TaxCategoryCode = before.Code
+		arg.OldTaxRate = before.TaxRate
+		arg.OldIsInclusive = before.IsInclusive
+		arg.OldIsActive = before.IsActive
+	}
+	if after != nil {
+		arg.TaxCategoryID = pgtype.Int4{Int32: after.ID, Valid: true}
+		arg.TaxCategoryCode = after.Code
+		arg.NewTaxRate = after.TaxRate
+		arg.NewIsInclusive = after.IsInclusive
+		arg.NewIsActive = after.IsActive
+	}
+	if userID != nil {
+		arg.ChangedBy = pgtype.Int4{Int32: *userID, Valid: true}
+	}
+	_, err := q.CreateTaxCategoryChange(ctx, arg)
+	return err
+}
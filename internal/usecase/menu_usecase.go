@@ -67,6 +67,7 @@ func (uc *MenuUseCase) CreateMenu(
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeCreated, "menu created successfully", menu)
 }
 
@@ -215,6 +216,7 @@ func (uc *MenuUseCase) UpdateMenu(
 		return utils.NewResponse(utils.CodeNotFound, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "menu updated successfully", menu)
 }
 
@@ -238,6 +240,7 @@ func (uc *MenuUseCase) ToggleMenuActive(
 		return utils.NewResponse(utils.CodeNotFound, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "menu status updated successfully", menu)
 }
 
@@ -252,6 +255,7 @@ func (uc *MenuUseCase) DeleteMenu(ctx context.Context, id int32) *repository.Res
 		return utils.NewResponse(utils.CodeNotFound, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "menu deleted successfully", nil)
 }
 
@@ -268,3 +272,171 @@ func toPgText(v *string) pgtype.Text {
 	}
 	return pgtype.Text{String: *v, Valid: true}
 }
+
+// ReorderMenus sets the order of the menus of a module under a parent menu
+// (the top-level menus when parentMenuID is nil). menuIDs must list every one
+// of them exactly once; they get display orders 1, 2, 3, ... in that order.
+func (uc *MenuUseCase) ReorderMenus(ctx context.Context, moduleID int32, parentMenuID *int32, menuIDs []int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if moduleID <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "module id is required", nil)
+	}
+	if len(menuIDs) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "menu ids are required", nil)
+	}
+
+	var menus []repository.Menu
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		siblings, err := q.ListMenuSiblings(ctx, repository.ListMenuSiblingsParams{ModuleID: moduleID, ParentMenuID: toPgInt4(parentMenuID)})
+		if err != nil {
+			return err
+		}
+		ids := make([]int32, len(siblings))
+		for i, m := range siblings {
+			ids[i] = m.ID
+		}
+		if !sameIDs(ids, menuIDs) {
+			return documentInputErrorf("menu ids must list every menu under the parent exactly once")
+		}
+		if err := renumberMenus(ctx, q, menuIDs); err != nil {
+			return err
+		}
+		menus, err = q.ListMenuSiblings(ctx, repository.ListMenuSiblingsParams{ModuleID: moduleID, ParentMenuID: toPgInt4(parentMenuID)})
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "menus reordered successfully", menus)
+}
+
+// MoveMenu moves a menu with its child menus under another parent menu of
+// the same module, or to the top level when parentMenuID is nil, at the
+// 1-based position among its new siblings (last when nil). A menu cannot
+// move under itself or its descendants. The display orders of the old and
+// new siblings are renumbered.
+func (uc *MenuUseCase) MoveMenu(ctx context.Context, id int32, parentMenuID *int32, position *int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if position != nil && *position < 1 {
+		return utils.NewResponse(utils.CodeBadReq, "position starts at 1", nil)
+	}
+	menu, err := uc.repo.GetMenu(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "menu not found", nil)
+	}
+	if parentMenuID != nil {
+		parent, err := uc.repo.GetMenu(ctx, *parentMenuID)
+		if err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "parent menu not found", nil)
+		}
+		if parent.ModuleID != menu.ModuleID {
+			return utils.NewResponse(utils.CodeBadReq, "parent menu belongs to another module", nil)
+		}
+	}
+
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		if parentMenuID != nil {
+			subtree, err := q.ListMenuSubtreeIDs(ctx, id)
+			if err != nil {
+				return err
+			}
+			for _, sub := range subtree {
+				if sub == *parentMenuID {
+					return documentInputErrorf("menu %d is menu %d or one of its child menus", *parentMenuID, id)
+				}
+			}
+		}
+		moved, err := q.SetMenuParent(ctx, repository.SetMenuParentParams{ID: id, ParentMenuID: toPgInt4(parentMenuID)})
+		if err != nil {
+			return err
+		}
+		siblings, err := q.ListMenuSiblings(ctx, repository.ListMenuSiblingsParams{ModuleID: moved.ModuleID, ParentMenuID: moved.ParentMenuID})
+		if err != nil {
+			return err
+		}
+		ids := make([]int32, len(siblings))
+		for i, m := range siblings {
+			ids[i] = m.ID
+		}
+		if err := renumberMenus(ctx, q, placeAt(ids, id, position)); err != nil {
+			return err
+		}
+		if menu.ParentMenuID != moved.ParentMenuID {
+			old, err := q.ListMenuSiblings(ctx, repository.ListMenuSiblingsParams{ModuleID: menu.ModuleID, ParentMenuID: menu.ParentMenuID})
+			if err != nil {
+				return err
+			}
+			ids := make([]int32, len(old))
+			for i, m := range old {
+				ids[i] = m.ID
+			}
+			if err := renumberMenus(ctx, q, ids); err != nil {
+				return err
+			}
+		}
+		menu, err = q.GetMenu(ctx, id)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "menu moved successfully", menu)
+}
+
+// renumberMenus gives the menus display orders 1, 2, 3, ... in order.
+func renumberMenus(ctx context.Context, q *repository.Queries, ids []int32) error {
+	for i, id := range ids {
+		if err := q.SetMenuDisplayOrder(ctx, repository.SetMenuDisplayOrderParams{
+			ID:           id,
+			DisplayOrder: pgtype.Int4{Int32: int32(i + 1), Valid: true},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// placeAt returns ids with id moved to the 1-based position, or to the end
+// when position is nil or past the end.
+func placeAt(ids []int32, id int32, position *int32) []int32 {
+	out := make([]int32, 0, len(ids))
+	for _, other := range ids {
+		if other != id {
+			out = append(out, other)
+		}
+	}
+	at := len(out)
+	if position != nil && int(*position)-1 < at {
+		at = int(*position) - 1
+	}
+	out = append(out, 0)
+	copy(out[at+1:], out[at:])
+	out[at] = id
+	return out
+}
+
+// sameIDs reports whether want lists exactly the IDs of have, each once.
+func sameIDs(have, want []int32) bool {
+	if len(have) != len(want) {
+		return false
+	}
+	seen := make(map[int32]bool, len(have))
+	for _, id := range have {
+		seen[id] = true
+	}
+	for _, id := range want {
+		if !seen[id] {
+			return false
+		}
+		delete(seen, id)
+	}
+	return true
+}
@@ -71,6 +71,7 @@ func (uc *ModuleUseCase) CreateModule(
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeCreated, "module created successfully", module)
 }
 
@@ -185,6 +186,7 @@ func (uc *ModuleUseCase) UpdateModule(
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "module updated successfully", module)
 }
 
@@ -204,6 +206,7 @@ func (uc *ModuleUseCase) DeleteModule(ctx context.Context, id string) *repositor
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "module deleted successfully", nil)
 }
 
@@ -0,0 +1,978 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/goccy/go-yaml"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Navigation bundle formats.
+const (
+	NavigationBundleJSON = "json"
+	NavigationBundleYAML = "yaml"
+)
+
+// navigationBundleVersion is the bundle layout this server reads and writes.
+const navigationBundleVersion = 1
+
+// Actions of a NavigationChange.
+const (
+	NavigationChangeCreate     = "create"
+	NavigationChangeUpdate     = "update"
+	NavigationChangeDeactivate = "deactivate"
+	NavigationChangeGrant      = "grant"
+	NavigationChangeRevoke     = "revoke"
+)
+
+// NavigationBundle is a declarative navigation tree: the modules with their
+// menus and submenus, the permissions that open each, and the permissions
+// themselves. Everything is matched by code, never by ID, so a bundle
+// exported from one tenant applies to any other.
+type NavigationBundle struct {
+	Version     int                `json:"version"`
+	Permissions []BundlePermission `json:"permissions"`
+	Modules     []BundleModule     `json:"modules"`
+}
+
+// BundlePermission is a permission of a NavigationBundle.
+type BundlePermission struct {
+	Code        string                 `json:"code"`
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	Metadata    map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// BundleModule is a module of a NavigationBundle. IsActive defaults to true.
+type BundleModule struct {
+	Code         string                 `json:"code"`
+	Name         string                 `json:"name"`
+	Description  string                 `json:"description,omitempty"`
+	Icon         string                 `json:"icon,omitempty"`
+	DisplayOrder int32                  `json:"display_order"`
+	IsActive     *bool                  `json:"is_active,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Permissions  []string               `json:"permissions,omitempty"`
+	Menus        []BundleMenu           `json:"menus,omitempty"`
+}
+
+// BundleMenu is a menu of a BundleModule. Parent is the code of a menu of the
+// same module listed before it.
+type BundleMenu struct {
+	Code         string                 `json:"code"`
+	Name         string                 `json:"name"`
+	Parent       string                 `json:"parent,omitempty"`
+	RoutePath    string                 `json:"route_path,omitempty"`
+	Icon         string                 `json:"icon,omitempty"`
+	DisplayOrder int32                  `json:"display_order"`
+	IsActive     *bool                  `json:"is_active,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Permissions  []string               `json:"permissions,omitempty"`
+	Submenus     []BundleSubmenu        `json:"submenus,omitempty"`
+}
+
+// BundleSubmenu is a submenu of a BundleMenu. Parent is the code of a submenu
+// of the same menu listed before it.
+type BundleSubmenu struct {
+	Code         string                 `json:"code"`
+	Name         string                 `json:"name"`
+	Parent       string                 `json:"parent,omitempty"`
+	RoutePath    string                 `json:"route_path,omitempty"`
+	Icon         string                 `json:"icon,omitempty"`
+	DisplayOrder int32                  `json:"display_order"`
+	IsActive     *bool                  `json:"is_active,omitempty"`
+	Metadata     map[string]interface{} `json:"metadata,omitempty"`
+	Permissions  []string               `json:"permissions,omitempty"`
+}
+
+// NavigationChange is one change applying a bundle makes, or would make on a
+// dry run. Path is the module, menu and submenu codes joined by "/";
+// Permission is set on grants and revokes.
+type NavigationChange struct {
+	Action     string   `json:"action"`
+	Kind       string   `json:"kind"`
+	Path       string   `json:"path"`
+	Fields     []string `json:"fields,omitempty"`
+	Permission string   `json:"permission,omitempty"`
+}
+
+// NavigationBundleResult is the outcome of applying a bundle.
+type NavigationBundleResult struct {
+	DryRun      bool               `json:"dry_run"`
+	Created     int                `json:"created"`
+	Updated     int                `json:"updated"`
+	Deactivated int                `json:"deactivated"`
+	Granted     int                `json:"granted"`
+	Revoked     int                `json:"revoked"`
+	Changes     []NavigationChange `json:"changes"`
+}
+
+// NavigationBundleFile is an exported bundle.
+type NavigationBundleFile struct {
+	Filename    string
+	ContentType string
+	Body        []byte
+}
+
+// ParseNavigationBundle decodes a JSON or YAML bundle.
+func ParseNavigationBundle(format string, data []byte) (*NavigationBundle, error) {
+	var b NavigationBundle
+	switch format {
+	case NavigationBundleJSON:
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&b); err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+	case NavigationBundleYAML:
+		if err := yaml.UnmarshalWithOptions(data, &b, yaml.DisallowUnknownField()); err != nil {
+			return nil, fmt.Errorf("invalid bundle: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported bundle format %q", format)
+	}
+	return &b, nil
+}
+
+// ExportNavigationBundle writes the tenant's navigation tree and the
+// permissions it references as a *NavigationBundleFile.
+func (uc *NavigationUseCase) ExportNavigationBundle(ctx context.Context, format string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if format == "" {
+		format = NavigationBundleJSON
+	}
+	if format != NavigationBundleJSON && format != NavigationBundleYAML {
+		return utils.NewResponse(utils.CodeBadReq, "format must be json or yaml", nil)
+	}
+
+	bundle, err := exportNavigationBundle(ctx, uc.repo)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	out := &NavigationBundleFile{Filename: "navigation." + format}
+	if format == NavigationBundleYAML {
+		out.ContentType = "application/yaml"
+		out.Body, err = yaml.Marshal(bundle)
+	} else {
+		out.ContentType = "application/json"
+		out.Body, err = json.MarshalIndent(bundle, "", "  ")
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "navigation exported", out)
+}
+
+// ApplyNavigationBundle makes the tenant's navigation match the bundle in one
+// transaction: bundle permissions are created or updated, modules, menus and
+// submenus are created or updated by code, those missing from the bundle are
+// deactivated, and permission mappings are granted and revoked to match. A
+// dry run only reports the changes. Applying needs PermissionManage and, as
+// it changes the permissions and navigation of every role, system roles
+// included, PermissionManageSystemRoles.
+func (uc *NavigationUseCase) ApplyNavigationBundle(ctx context.Context, viewerID *int32, bundle *NavigationBundle, dryRun bool) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if !dryRun {
+		if resp := checkCanManagePermissions(ctx, uc.repo, viewerID); resp != nil {
+			return resp
+		}
+		if resp := checkCanManageSystemRoles(ctx, uc.repo, viewerID); resp != nil {
+			return resp
+		}
+	}
+	if err := bundle.validate(); err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+
+	result := &NavigationBundleResult{DryRun: dryRun, Changes: []NavigationChange{}}
+	apply := func(q *repository.Queries) error {
+		a := &bundleApplier{q: q, dryRun: dryRun, result: result, permissions: map[string]int32{}}
+		return a.apply(ctx, bundle)
+	}
+	var err error
+	if dryRun {
+		err = apply(uc.repo)
+	} else {
+		err = uc.repo.ExecTx(ctx, apply)
+	}
+	if err != nil {
+		var bad *documentInputError
+		if errors.As(err, &bad) {
+			return utils.NewResponse(utils.CodeBadReq, bad.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	if dryRun {
+		return utils.NewResponse(utils.CodeOK, "navigation bundle checked", result)
+	}
+	if len(result.Changes) > 0 {
+		invalidateNavigation(ctx)
+	}
+	return utils.NewResponse(utils.CodeOK, "navigation bundle applied", result)
+}
+
+// validate checks codes, names and parents; parents must be listed before
+// their children, which also rules out cycles.
+func (b *NavigationBundle) validate() error {
+	if b.Version != navigationBundleVersion {
+		return fmt.Errorf("bundle version must be %d", navigationBundleVersion)
+	}
+	perms := map[string]bool{}
+	for _, p := range b.Permissions {
+		if p.Code == "" || p.Name == "" {
+			return errors.New("every permission needs a code and a name")
+		}
+		if perms[p.Code] {
+			return fmt.Errorf("permission %s is listed twice", p.Code)
+		}
+		perms[p.Code] = true
+	}
+	modules := map[string]bool{}
+	for _, m := range b.Modules {
+		if m.Code == "" || m.Name == "" {
+			return errors.New("every module needs a code and a name")
+		}
+		if modules[m.Code] {
+			return fmt.Errorf("module %s is listed twice", m.Code)
+		}
+		modules[m.Code] = true
+		menus := map[string]bool{}
+		for _, mn := range m.Menus {
+			path := m.Code + "/" + mn.Code
+			if mn.Code == "" || mn.Name == "" {
+				return fmt.Errorf("every menu of module %s needs a code and a name", m.Code)
+			}
+			if menus[mn.Code] {
+				return fmt.Errorf("menu %s is listed twice", path)
+			}
+			if mn.Parent != "" && !menus[mn.Parent] {
+				return fmt.Errorf("parent %s of menu %s must be listed before it in the same module", mn.Parent, path)
+			}
+			menus[mn.Code] = true
+			submenus := map[string]bool{}
+			for _, s := range mn.Submenus {
+				if s.Code == "" || s.Name == "" {
+					return fmt.Errorf("every submenu of menu %s needs a code and a name", path)
+				}
+				if submenus[s.Code] {
+					return fmt.Errorf("submenu %s/%s is listed twice", path, s.Code)
+				}
+				if s.Parent != "" && !submenus[s.Parent] {
+					return fmt.Errorf("parent %s of submenu %s/%s must be listed before it in the same menu", s.Parent, path, s.Code)
+				}
+				submenus[s.Code] = true
+			}
+		}
+	}
+	return nil
+}
+
+// bundleApplier applies a bundle through q, recording every change. On a dry
+// run it writes nothing; entities that would be created get ID 0 and their
+// children are all reported as created.
+type bundleApplier struct {
+	q           *repository.Queries
+	dryRun      bool
+	result      *NavigationBundleResult
+	permissions map[string]int32
+}
+
+func (a *bundleApplier) record(c NavigationChange) {
+	switch c.Action {
+	case NavigationChangeCreate:
+		a.result.Created++
+	case NavigationChangeUpdate:
+		a.result.Updated++
+	case NavigationChangeDeactivate:
+		a.result.Deactivated++
+	case NavigationChangeGrant:
+		a.result.Granted++
+	case NavigationChangeRevoke:
+		a.result.Revoked++
+	}
+	a.result.Changes = append(a.result.Changes, c)
+}
+
+func (a *bundleApplier) apply(ctx context.Context, b *NavigationBundle) error {
+	for _, p := range b.Permissions {
+		if err := a.applyPermission(ctx, p); err != nil {
+			return err
+		}
+	}
+
+	existing, err := a.q.ListModules(ctx, pgtype.Bool{})
+	if err != nil {
+		return err
+	}
+	byCode := map[string]repository.Module{}
+	for _, m := range existing {
+		byCode[m.Code] = m
+	}
+	for _, bm := range b.Modules {
+		id, err := a.applyModule(ctx, bm, byCode)
+		if err != nil {
+			return err
+		}
+		if err := a.syncPermissions(ctx, "module", bm.Code, id, bm.Permissions); err != nil {
+			return err
+		}
+		if err := a.applyMenus(ctx, bm, id); err != nil {
+			return err
+		}
+		delete(byCode, bm.Code)
+	}
+	for _, m := range existing {
+		if _, gone := byCode[m.Code]; !gone || !m.IsActive.Bool {
+			continue
+		}
+		if !a.dryRun {
+			if _, err := a.q.UpdateModule(ctx, repository.UpdateModuleParams{ID: m.ID, IsActive: pgtype.Bool{Bool: false, Valid: true}}); err != nil {
+				return err
+			}
+		}
+		a.record(NavigationChange{Action: NavigationChangeDeactivate, Kind: "module", Path: m.Code})
+	}
+	return nil
+}
+
+func (a *bundleApplier) applyPermission(ctx context.Context, p BundlePermission) error {
+	meta, err := bundleMetadata(p.Metadata)
+	if err != nil {
+		return err
+	}
+	cur, err := a.q.GetPermissionByCode(ctx, p.Code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		if !a.dryRun {
+			if cur, err = a.q.CreatePermission(ctx, repository.CreatePermissionParams{
+				Name:        p.Name,
+				Code:        p.Code,
+				Description: optionalText(p.Description),
+				Metadata:    meta,
+			}); err != nil {
+				return err
+			}
+		}
+		a.permissions[p.Code] = cur.ID
+		a.record(NavigationChange{Action: NavigationChangeCreate, Kind: "permission", Path: p.Code})
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	a.permissions[p.Code] = cur.ID
+
+	var fields []string
+	if cur.Name != p.Name {
+		fields = append(fields, "name")
+	}
+	if cur.Description.String != p.Description {
+		fields = append(fields, "description")
+	}
+	if !sameMetadata(cur.Metadata, meta) {
+		fields = append(fields, "metadata")
+	}
+	if len(fields) == 0 {
+		return nil
+	}
+	if !a.dryRun {
+		if _, err := a.q.UpdatePermission(ctx, repository.UpdatePermissionParams{
+			ID:          cur.ID,
+			Name:        pgtype.Text{String: p.Name, Valid: true},
+			Description: pgtype.Text{String: p.Description, Valid: true},
+			Metadata:    meta,
+		}); err != nil {
+			return err
+		}
+	}
+	a.record(NavigationChange{Action: NavigationChangeUpdate, Kind: "permission", Path: p.Code, Fields: fields})
+	return nil
+}
+
+// permissionID resolves a permission code given in the bundle or already in
+// the tenant; 0 is a permission the dry run would create.
+func (a *bundleApplier) permissionID(ctx context.Context, code string) (int32, error) {
+	if id, ok := a.permissions[code]; ok {
+		return id, nil
+	}
+	p, err := a.q.GetPermissionByCode(ctx, code)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return 0, documentInputErrorf("permission %s is neither in the bundle nor in the tenant", code)
+	}
+	if err != nil {
+		return 0, err
+	}
+	a.permissions[code] = p.ID
+	return p.ID, nil
+}
+
+func (a *bundleApplier) applyModule(ctx context.Context, bm BundleModule, existing map[string]repository.Module) (int32, error) {
+	meta, err := bundleMetadata(bm.Metadata)
+	if err != nil {
+		return 0, err
+	}
+	active := bm.IsActive == nil || *bm.IsActive
+	cur, ok := existing[bm.Code]
+	if !ok {
+		if !a.dryRun {
+			if cur, err = a.q.CreateModule(ctx, repository.CreateModuleParams{
+				Name:         bm.Name,
+				Code:         bm.Code,
+				Description:  optionalText(bm.Description),
+				Icon:         optionalText(bm.Icon),
+				IsActive:     pgtype.Bool{Bool: active, Valid: true},
+				DisplayOrder: pgtype.Int4{Int32: bm.DisplayOrder, Valid: true},
+				Metadata:     meta,
+			}); err != nil {
+				return 0, err
+			}
+		}
+		a.record(NavigationChange{Action: NavigationChangeCreate, Kind: "module", Path: bm.Code})
+		return cur.ID, nil
+	}
+
+	var fields []string
+	if cur.Name != bm.Name {
+		fields = append(fields, "name")
+	}
+	if cur.Description.String != bm.Description {
+		fields = append(fields, "description")
+	}
+	if cur.Icon.String != bm.Icon {
+		fields = append(fields, "icon")
+	}
+	if cur.DisplayOrder.Int32 != bm.DisplayOrder {
+		fields = append(fields, "display_order")
+	}
+	if cur.IsActive.Bool != active {
+		fields = append(fields, "is_active")
+	}
+	if !sameMetadata(cur.Metadata, meta) {
+		fields = append(fields, "metadata")
+	}
+	if len(fields) == 0 {
+		return cur.ID, nil
+	}
+	if !a.dryRun {
+		if _, err := a.q.UpdateModule(ctx, repository.UpdateModuleParams{
+			ID:           cur.ID,
+			Name:         pgtype.Text{String: bm.Name, Valid: true},
+			Description:  pgtype.Text{String: bm.Description, Valid: true},
+			Icon:         pgtype.Text{String: bm.Icon, Valid: true},
+			IsActive:     pgtype.Bool{Bool: active, Valid: true},
+			DisplayOrder: pgtype.Int4{Int32: bm.DisplayOrder, Valid: true},
+			Metadata:     meta,
+		}); err != nil {
+			return 0, err
+		}
+	}
+	a.record(NavigationChange{Action: NavigationChangeUpdate, Kind: "module", Path: bm.Code, Fields: fields})
+	return cur.ID, nil
+}
+
+func (a *bundleApplier) applyMenus(ctx context.Context, bm BundleModule, moduleID int32) error {
+	var existing []repository.Menu
+	if moduleID != 0 {
+		var err error
+		if existing, err = a.q.ListMenusByModule(ctx, moduleID); err != nil {
+			return err
+		}
+	}
+	byCode := map[string]repository.Menu{}
+	for _, m := range existing {
+		byCode[m.Code] = m
+	}
+	ids := map[string]int32{}
+	for _, mn := range bm.Menus {
+		path := bm.Code + "/" + mn.Code
+		meta, err := bundleMetadata(mn.Metadata)
+		if err != nil {
+			return err
+		}
+		active := mn.IsActive == nil || *mn.IsActive
+		var parent pgtype.Int4
+		if mn.Parent != "" {
+			parent = pgtype.Int4{Int32: ids[mn.Parent], Valid: true}
+		}
+
+		cur, ok := byCode[mn.Code]
+		if !ok {
+			if !a.dryRun {
+				if cur, err = a.q.CreateMenu(ctx, repository.CreateMenuParams{
+					ModuleID:     moduleID,
+					ParentMenuID: parent,
+					Name:         mn.Name,
+					Code:         mn.Code,
+					RoutePath:    optionalText(mn.RoutePath),
+					Icon:         optionalText(mn.Icon),
+					DisplayOrder: pgtype.Int4{Int32: mn.DisplayOrder, Valid: true},
+					IsActive:     pgtype.Bool{Bool: active, Valid: true},
+					Metadata:     meta,
+				}); err != nil {
+					return err
+				}
+			}
+			a.record(NavigationChange{Action: NavigationChangeCreate, Kind: "menu", Path: path})
+		} else {
+			var fields []string
+			if cur.Name != mn.Name {
+				fields = append(fields, "name")
+			}
+			if cur.ParentMenuID != parent {
+				fields = append(fields, "parent")
+			}
+			if cur.RoutePath.String != mn.RoutePath {
+				fields = append(fields, "route_path")
+			}
+			if cur.Icon.String != mn.Icon {
+				fields = append(fields, "icon")
+			}
+			if cur.DisplayOrder.Int32 != mn.DisplayOrder {
+				fields = append(fields, "display_order")
+			}
+			if cur.IsActive.Bool != active {
+				fields = append(fields, "is_active")
+			}
+			if !sameMetadata(cur.Metadata, meta) {
+				fields = append(fields, "metadata")
+			}
+			if len(fields) > 0 {
+				if !a.dryRun {
+					if _, err := a.q.UpdateMenu(ctx, repository.UpdateMenuParams{
+						ID:           cur.ID,
+						ParentMenuID: parent,
+						Name:         mn.Name,
+						RoutePath:    optionalText(mn.RoutePath),
+						Icon:         optionalText(mn.Icon),
+						DisplayOrder: pgtype.Int4{Int32: mn.DisplayOrder, Valid: true},
+						IsActive:     pgtype.Bool{Bool: active, Valid: true},
+						Metadata:     meta,
+					}); err != nil {
+						return err
+					}
+				}
+				a.record(NavigationChange{Action: NavigationChangeUpdate, Kind: "menu", Path: path, Fields: fields})
+			}
+		}
+		ids[mn.Code] = cur.ID
+
+		if err := a.syncPermissions(ctx, "menu", path, cur.ID, mn.Permissions); err != nil {
+			return err
+		}
+		if err := a.applySubmenus(ctx, path, mn, cur.ID); err != nil {
+			return err
+		}
+		delete(byCode, mn.Code)
+	}
+	for _, m := range existing {
+		if _, gone := byCode[m.Code]; !gone || !m.IsActive.Bool {
+			continue
+		}
+		if !a.dryRun {
+			if _, err := a.q.ToggleMenuActive(ctx, repository.ToggleMenuActiveParams{ID: m.ID, IsActive: pgtype.Bool{Bool: false, Valid: true}}); err != nil {
+				return err
+			}
+		}
+		a.record(NavigationChange{Action: NavigationChangeDeactivate, Kind: "menu", Path: bm.Code + "/" + m.Code})
+	}
+	return nil
+}
+
+func (a *bundleApplier) applySubmenus(ctx context.Context, menuPath string, mn BundleMenu, menuID int32) error {
+	var existing []repository.Submenu
+	if menuID != 0 {
+		var err error
+		if existing, err = a.q.ListSubmenusByMenu(ctx, menuID); err != nil {
+			return err
+		}
+	}
+	byCode := map[string]repository.Submenu{}
+	for _, s := range existing {
+		byCode[s.Code] = s
+	}
+	ids := map[string]int32{}
+	for _, bs := range mn.Submenus {
+		path := menuPath + "/" + bs.Code
+		meta, err := bundleMetadata(bs.Metadata)
+		if err != nil {
+			return err
+		}
+		active := bs.IsActive == nil || *bs.IsActive
+		var parent pgtype.Int4
+		if bs.Parent != "" {
+			parent = pgtype.Int4{Int32: ids[bs.Parent], Valid: true}
+		}
+
+		cur, ok := byCode[bs.Code]
+		if !ok {
+			if !a.dryRun {
+				if cur, err = a.q.CreateSubmenu(ctx, repository.CreateSubmenuParams{
+					MenuID:          menuID,
+					ParentSubmenuID: parent,
+					Name:            bs.Name,
+					Code:            bs.Code,
+					RoutePath:       optionalText(bs.RoutePath),
+					Icon:            optionalText(bs.Icon),
+					DisplayOrder:    pgtype.Int4{Int32: bs.DisplayOrder, Valid: true},
+					IsActive:        pgtype.Bool{Bool: active, Valid: true},
+					Metadata:        meta,
+				}); err != nil {
+					return err
+				}
+			}
+			a.record(NavigationChange{Action: NavigationChangeCreate, Kind: "submenu", Path: path})
+		} else {
+			var fields []string
+			if cur.Name != bs.Name {
+				fields = append(fields, "name")
+			}
+			if cur.ParentSubmenuID != parent {
+				fields = append(fields, "parent")
+			}
+			if cur.RoutePath.String != bs.RoutePath {
+				fields = append(fields, "route_path")
+			}
+			if cur.Icon.String != bs.Icon {
+				fields = append(fields, "icon")
+			}
+			if cur.DisplayOrder.Int32 != bs.DisplayOrder {
+				fields = append(fields, "display_order")
+			}
+			if cur.IsActive.Bool != active {
+				fields = append(fields, "is_active")
+			}
+			if !sameMetadata(cur.Metadata, meta) {
+				fields = append(fields, "metadata")
+			}
+			if len(fields) > 0 {
+				if !a.dryRun {
+					if _, err := a.q.UpdateSubmenu(ctx, repository.UpdateSubmenuParams{
+						ID:              cur.ID,
+						ParentSubmenuID: parent,
+						Name:            bs.Name,
+						RoutePath:       optionalText(bs.RoutePath),
+						Icon:            optionalText(bs.Icon),
+						DisplayOrder:    pgtype.Int4{Int32: bs.DisplayOrder, Valid: true},
+						IsActive:        pgtype.Bool{Bool: active, Valid: true},
+						Metadata:        meta,
+					}); err != nil {
+						return err
+					}
+				}
+				a.record(NavigationChange{Action: NavigationChangeUpdate, Kind: "submenu", Path: path, Fields: fields})
+			}
+		}
+		ids[bs.Code] = cur.ID
+
+		if err := a.syncPermissions(ctx, "submenu", path, cur.ID, bs.Permissions); err != nil {
+			return err
+		}
+		delete(byCode, bs.Code)
+	}
+	for _, s := range existing {
+		if _, gone := byCode[s.Code]; !gone || !s.IsActive.Bool {
+			continue
+		}
+		if !a.dryRun {
+			if _, err := a.q.ToggleSubmenuActive(ctx, repository.ToggleSubmenuActiveParams{ID: s.ID, IsActive: pgtype.Bool{Bool: false, Valid: true}}); err != nil {
+				return err
+			}
+		}
+		a.record(NavigationChange{Action: NavigationChangeDeactivate, Kind: "submenu", Path: menuPath + "/" + s.Code})
+	}
+	return nil
+}
+
+// syncPermissions grants and revokes the permission mappings of a module,
+// menu or submenu (kind) until they are exactly codes. id 0 is an entity the
+// dry run would create, which has no mappings yet.
+func (a *bundleApplier) syncPermissions(ctx context.Context, kind, path string, id int32, codes []string) error {
+	have := map[string]int32{}
+	if id != 0 {
+		var err error
+		if have, err = mappedPermissions(ctx, a.q, kind, id); err != nil {
+			return err
+		}
+	}
+	want := map[string]bool{}
+	for _, code := range codes {
+		if want[code] {
+			continue
+		}
+		want[code] = true
+		permID, err := a.permissionID(ctx, code)
+		if err != nil {
+			return err
+		}
+		if _, ok := have[code]; ok {
+			continue
+		}
+		if !a.dryRun {
+			if err := grantMappedPermission(ctx, a.q, kind, id, permID); err != nil {
+				return err
+			}
+		}
+		a.record(NavigationChange{Action: NavigationChangeGrant, Kind: kind, Path: path, Permission: code})
+	}
+
+	revoke := make([]string, 0, len(have))
+	for code := range have {
+		if !want[code] {
+			revoke = append(revoke, code)
+		}
+	}
+	sort.Strings(revoke)
+	for _, code := range revoke {
+		if !a.dryRun {
+			if err := revokeMappedPermission(ctx, a.q, kind, id, have[code]); err != nil {
+				return err
+			}
+		}
+		a.record(NavigationChange{Action: NavigationChangeRevoke, Kind: kind, Path: path, Permission: code})
+	}
+	return nil
+}
+
+// mappedPermissions returns the permission IDs mapped to a module, menu or
+// submenu by permission code.
+func mappedPermissions(ctx context.Context, q *repository.Queries, kind string, id int32) (map[string]int32, error) {
+	out := map[string]int32{}
+	switch kind {
+	case "module":
+		rows, err := q.ListModulePermissionsByModule(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			out[r.PermissionCode] = r.PermissionID
+		}
+	case "menu":
+		rows, err := q.ListMenuPermissionsByMenu(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			out[r.PermissionCode] = r.PermissionID
+		}
+	case "submenu":
+		rows, err := q.ListSubmenuPermissionsBySubmenu(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			out[r.PermissionCode] = r.PermissionID
+		}
+	}
+	return out, nil
+}
+
+func grantMappedPermission(ctx context.Context, q *repository.Queries, kind string, id, permissionID int32) error {
+	var err error
+	switch kind {
+	case "module":
+		_, err = q.CreateModulePermission(ctx, repository.CreateModulePermissionParams{ModuleID: id, PermissionID: permissionID, Metadata: []byte("{}")})
+	case "menu":
+		_, err = q.CreateMenuPermission(ctx, repository.CreateMenuPermissionParams{MenuID: id, PermissionID: permissionID, Metadata: []byte("{}")})
+	case "submenu":
+		_, err = q.CreateSubmenuPermission(ctx, repository.CreateSubmenuPermissionParams{SubmenuID: id, PermissionID: permissionID, Metadata: []byte("{}")})
+	}
+	return err
+}
+
+func revokeMappedPermission(ctx context.Context, q *repository.Queries, kind string, id, permissionID int32) error {
+	switch kind {
+	case "module":
+		return q.DeleteModulePermission(ctx, repository.DeleteModulePermissionParams{ModuleID: id, PermissionID: permissionID})
+	case "menu":
+		return q.DeleteMenuPermission(ctx, repository.DeleteMenuPermissionParams{MenuID: id, PermissionID: permissionID})
+	case "submenu":
+		return q.DeleteSubmenuPermission(ctx, repository.DeleteSubmenuPermissionParams{SubmenuID: id, PermissionID: permissionID})
+	}
+	return nil
+}
+
+// exportNavigationBundle reads the whole navigation tree. Parents are listed
+// before their children so the bundle applies as exported.
+func exportNavigationBundle(ctx context.Context, q *repository.Queries) (*NavigationBundle, error) {
+	b := &NavigationBundle{Version: navigationBundleVersion, Permissions: []BundlePermission{}, Modules: []BundleModule{}}
+	permIDs := map[int32]bool{}
+	mapped := func(kind string, id int32) ([]string, error) {
+		m, err := mappedPermissions(ctx, q, kind, id)
+		if err != nil {
+			return nil, err
+		}
+		codes := make([]string, 0, len(m))
+		for code, permID := range m {
+			codes = append(codes, code)
+			permIDs[permID] = true
+		}
+		sort.Strings(codes)
+		return codes, nil
+	}
+
+	modules, err := q.ListModules(ctx, pgtype.Bool{})
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range modules {
+		bm := BundleModule{
+			Code:         m.Code,
+			Name:         m.Name,
+			Description:  m.Description.String,
+			Icon:         m.Icon.String,
+			DisplayOrder: m.DisplayOrder.Int32,
+			IsActive:     &m.IsActive.Bool,
+			Metadata:     exportMetadata(m.Metadata),
+		}
+		if bm.Permissions, err = mapped("module", m.ID); err != nil {
+			return nil, err
+		}
+
+		menus, err := q.ListMenusByModule(ctx, m.ID)
+		if err != nil {
+			return nil, err
+		}
+		menuCodes := map[int32]string{}
+		for _, mn := range menus {
+			menuCodes[mn.ID] = mn.Code
+		}
+		for _, i := range parentsFirst(len(menus), func(i int) (int32, pgtype.Int4) { return menus[i].ID, menus[i].ParentMenuID }) {
+			mn := menus[i]
+			bmn := BundleMenu{
+				Code:         mn.Code,
+				Name:         mn.Name,
+				Parent:       menuCodes[mn.ParentMenuID.Int32],
+				RoutePath:    mn.RoutePath.String,
+				Icon:         mn.Icon.String,
+				DisplayOrder: mn.DisplayOrder.Int32,
+				IsActive:     &mn.IsActive.Bool,
+				Metadata:     exportMetadata(mn.Metadata),
+			}
+			if bmn.Permissions, err = mapped("menu", mn.ID); err != nil {
+				return nil, err
+			}
+
+			submenus, err := q.ListSubmenusByMenu(ctx, mn.ID)
+			if err != nil {
+				return nil, err
+			}
+			submenuCodes := map[int32]string{}
+			for _, s := range submenus {
+				submenuCodes[s.ID] = s.Code
+			}
+			for _, i := range parentsFirst(len(submenus), func(i int) (int32, pgtype.Int4) { return submenus[i].ID, submenus[i].ParentSubmenuID }) {
+				s := submenus[i]
+				bs := BundleSubmenu{
+					Code:         s.Code,
+					Name:         s.Name,
+					Parent:       submenuCodes[s.ParentSubmenuID.Int32],
+					RoutePath:    s.RoutePath.String,
+					Icon:         s.Icon.String,
+					DisplayOrder: s.DisplayOrder.Int32,
+					IsActive:     &s.IsActive.Bool,
+					Metadata:     exportMetadata(s.Metadata),
+				}
+				if bs.Permissions, err = mapped("submenu", s.ID); err != nil {
+					return nil, err
+				}
+				bmn.Submenus = append(bmn.Submenus, bs)
+			}
+			bm.Menus = append(bm.Menus, bmn)
+		}
+		b.Modules = append(b.Modules, bm)
+	}
+
+	for id := range permIDs {
+		p, err := q.GetPermission(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		b.Permissions = append(b.Permissions, BundlePermission{
+			Code:        p.Code,
+			Name:        p.Name,
+			Description: p.Description.String,
+			Metadata:    exportMetadata(p.Metadata),
+		})
+	}
+	sort.Slice(b.Permissions, func(i, j int) bool { return b.Permissions[i].Code < b.Permissions[j].Code })
+	return b, nil
+}
+
+// parentsFirst returns the indexes of n items ordered so every item comes
+// after its parent, keeping the given order otherwise. Items whose parent is
+// missing or in a cycle come last as they are.
+func parentsFirst(n int, node func(i int) (id int32, parent pgtype.Int4)) []int {
+	out := make([]int, 0, n)
+	placed := map[int32]bool{}
+	done := make([]bool, n)
+	for len(out) < n {
+		progress := false
+		for i := 0; i < n; i++ {
+			id, parent := node(i)
+			if done[i] || (parent.Valid && !placed[parent.Int32]) {
+				continue
+			}
+			out = append(out, i)
+			placed[id], done[i] = true, true
+			progress = true
+		}
+		if !progress {
+			for i := 0; i < n; i++ {
+				if !done[i] {
+					id, _ := node(i)
+					out = append(out, i)
+					placed[id], done[i] = true, true
+				}
+			}
+		}
+	}
+	return out
+}
+
+// bundleMetadata encodes bundle metadata as stored, "{}" when empty.
+func bundleMetadata(m map[string]interface{}) ([]byte, error) {
+	if len(m) == 0 {
+		return []byte("{}"), nil
+	}
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, documentInputErrorf("invalid metadata: %v", err)
+	}
+	return b, nil
+}
+
+// exportMetadata decodes stored metadata for a bundle, nil when empty.
+func exportMetadata(raw []byte) map[string]interface{} {
+	var m map[string]interface{}
+	if err := json.Unmarshal(raw, &m); err != nil || len(m) == 0 {
+		return nil
+	}
+	return m
+}
+
+// sameMetadata compares stored metadata with bundle metadata by content.
+func sameMetadata(stored, bundle []byte) bool {
+	normalize := func(raw []byte) string {
+		var v interface{}
+		if len(strings.TrimSpace(string(raw))) == 0 || json.Unmarshal(raw, &v) != nil || v == nil {
+			return "{}"
+		}
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+	return normalize(stored) == normalize(bundle)
+}
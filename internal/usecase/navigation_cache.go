@@ -0,0 +1,108 @@
+package usecase
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+)
+
+// navigationCacheTTL bounds how long navigation is served from the cache. It
+// also bounds how stale another server instance can be after a change made
+// through this one, since invalidation is local to the process.
+const navigationCacheTTL = 5 * time.Minute
+
+// CachedNavigation is a user's navigation with the ETag of its JSON.
+type CachedNavigation struct {
+	ETag       string
+	Navigation []repository.GetCompleteNavigationByUserIDRow
+}
+
+// NavigationCacheStats reports how well the navigation cache works.
+type NavigationCacheStats struct {
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	HitRate       float64 `json:"hit_rate"`
+	Invalidations int64   `json:"invalidations"`
+	Entries       int     `json:"entries"`
+}
+
+type navigationEntry struct {
+	nav     *CachedNavigation
+	expires time.Time
+}
+
+// navigationCache holds navigation per tenant and role set: users with the
+// same roles see the same navigation.
+type navigationCache struct {
+	mu            sync.Mutex
+	tenants       map[string]map[string]navigationEntry
+	hits          int64
+	misses        int64
+	invalidations int64
+}
+
+var navCache = &navigationCache{tenants: map[string]map[string]navigationEntry{}}
+
+func (c *navigationCache) get(tenant, roleSet string) (*CachedNavigation, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.tenants[tenant][roleSet]
+	if !ok || time.Now().After(e.expires) {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	return e.nav, true
+}
+
+func (c *navigationCache) put(tenant, roleSet string, nav *CachedNavigation) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.tenants[tenant] == nil {
+		c.tenants[tenant] = map[string]navigationEntry{}
+	}
+	c.tenants[tenant][roleSet] = navigationEntry{nav: nav, expires: time.Now().Add(navigationCacheTTL)}
+}
+
+func (c *navigationCache) invalidate(tenant string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.tenants, tenant)
+	c.invalidations++
+}
+
+func (c *navigationCache) stats() NavigationCacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	s := NavigationCacheStats{Hits: c.hits, Misses: c.misses, Invalidations: c.invalidations}
+	if total := c.hits + c.misses; total > 0 {
+		s.HitRate = float64(c.hits) / float64(total)
+	}
+	for _, entries := range c.tenants {
+		s.Entries += len(entries)
+	}
+	return s
+}
+
+// invalidateNavigation drops the cached navigation of the request's tenant.
+// Call it after any change to modules, menus, submenus, permission
+// mappings, role permissions, user roles or UI settings.
+func invalidateNavigation(ctx context.Context) {
+	navCache.invalidate(middleware.TenantIDFromContext(ctx))
+}
+
+// navigationETag returns a strong ETag of the navigation's JSON.
+func navigationETag(nav []repository.GetCompleteNavigationByUserIDRow) (string, error) {
+	b, err := json.Marshal(nav)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(b)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`, nil
+}
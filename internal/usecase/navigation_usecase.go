@@ -2,7 +2,11 @@ package usecase
 
 import (
 	"context"
+	"sort"
+	"strconv"
+	"strings"
 
+	"NEMBUS/internal/middleware"
 	"NEMBUS/internal/repository"
 	"NEMBUS/utils"
 )
@@ -22,18 +26,69 @@ func (uc *NavigationUseCase) SetRepository(repo *repository.Queries) {
 	uc.repo = repo
 }
 
-// GetUserNavigation fetches complete navigation structure for a user
-func (uc *NavigationUseCase) GetUserNavigation(ctx context.Context, userID int32) *repository.Response {
+// GetUserNavigation fetches complete navigation structure for a user as
+// *CachedNavigation. Navigation is cached per tenant and role set until a
+// change to the navigation or to permissions invalidates it. viewerID is the
+// signed-in user, see checkUserVisible.
+func (uc *NavigationUseCase) GetUserNavigation(ctx context.Context, viewerID *int32, userID int32) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
+	if resp := checkUserVisible(ctx, uc.repo, viewerID, userID); resp != nil {
+		return resp
+	}
+
+	roles, err := uc.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	ids := make([]string, len(roles))
+	for i, r := range roles {
+		ids[i] = strconv.Itoa(int(r.ID))
+	}
+	sort.Strings(ids)
+	tenant, roleSet := middleware.TenantIDFromContext(ctx), strings.Join(ids, ",")
 
+	if nav, ok := navCache.get(tenant, roleSet); ok {
+		return utils.NewResponse(utils.CodeOK, "navigation fetched successfully", nav)
+	}
 	navigation, err := uc.repo.GetCompleteNavigationByUserID(ctx, userID)
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
+	etag, err := navigationETag(navigation)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	nav := &CachedNavigation{ETag: etag, Navigation: navigation}
+	navCache.put(tenant, roleSet, nav)
 
-	return utils.NewResponse(utils.CodeOK, "navigation fetched successfully", navigation)
+	return utils.NewResponse(utils.CodeOK, "navigation fetched successfully", nav)
+}
+
+// GetAccessibleRoutes lists the routes of the active submenus a user can
+// open, with the permissions that grant each, for client-side route guards.
+// viewerID is the signed-in user, see checkUserVisible.
+func (uc *NavigationUseCase) GetAccessibleRoutes(ctx context.Context, viewerID *int32, userID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkUserVisible(ctx, uc.repo, viewerID, userID); resp != nil {
+		return resp
+	}
+
+	routes, err := uc.repo.GetAllAccessibleRoutesByUserID(ctx, userID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	return utils.NewResponse(utils.CodeOK, "routes fetched successfully", routes)
+}
+
+// GetNavigationCacheStats returns the hit rate and size of the navigation
+// cache of this server.
+func (uc *NavigationUseCase) GetNavigationCacheStats(ctx context.Context) *repository.Response {
+	return utils.NewResponse(utils.CodeOK, "navigation cache stats fetched successfully", navCache.stats())
 }
 
 // GetNavigationByRoleCode fetches complete navigation structure for a given role code
@@ -4,6 +4,7 @@ import (
 	"context"
 	"strconv"
 
+	"NEMBUS/internal/middleware"
 	"NEMBUS/internal/repository"
 	"NEMBUS/utils" // Assuming your NewResponse is here
 
@@ -227,3 +228,27 @@ func (uc *OrganizationUseCase) DeleteOrganization(ctx context.Context, id string
 	}
 	return utils.NewResponse(utils.CodeOK, "organization deleted successfully", nil)
 }
+
+// activeOrganization returns the organization an operation works in: the
+// active organization of the request. A requested organization, when set,
+// must be the active one. Without an active organization, as in background
+// jobs, the requested one is used.
+func activeOrganization(ctx context.Context, requested int32) (int32, *repository.Response) {
+	orgID, ok := middleware.OrganizationIDFromContext(ctx)
+	switch {
+	case !ok && requested == 0:
+		return 0, utils.NewResponse(utils.CodeBadReq, "organization_id is required", nil)
+	case !ok:
+		return requested, nil
+	case requested != 0 && requested != orgID:
+		return 0, utils.NewResponse(utils.CodeForbidden, "organization_id is not the active organization", nil)
+	}
+	return orgID, nil
+}
+
+// inActiveOrganization reports whether a record of organization orgID is
+// visible to the request; all are without an active organization.
+func inActiveOrganization(ctx context.Context, orgID int32) bool {
+	active, ok := middleware.OrganizationIDFromContext(ctx)
+	return !ok || active == orgID
+}
@@ -0,0 +1,145 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// PasswordPolicy decides which passwords can be set and how long they last.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	// History is how many of the user's latest passwords, the current one
+	// included, can't be set again. Zero allows any.
+	History int
+	// MaxAge is how long a password lasts before it must be changed at
+	// login. Zero means passwords don't expire.
+	MaxAge time.Duration
+}
+
+// Check rejects passwords too short or missing a required kind of
+// character. Anything but a letter or a digit counts as a symbol.
+func (p PasswordPolicy) Check(password string) error {
+	if utf8.RuneCountInString(password) < p.MinLength {
+		return errors.New("password must be at least " + strconv.Itoa(p.MinLength) + " characters")
+	}
+	var upper, lower, digit, symbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			upper = true
+		case unicode.IsLower(r):
+			lower = true
+		case unicode.IsDigit(r):
+			digit = true
+		case !unicode.IsLetter(r):
+			symbol = true
+		}
+	}
+	var missing []string
+	if p.RequireUpper && !upper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !lower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !digit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !symbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return errors.New("password must contain " + strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+// Expired reports whether a password set at changedAt must be changed by now.
+func (p PasswordPolicy) Expired(changedAt, now time.Time) bool {
+	return p.MaxAge > 0 && !changedAt.IsZero() && now.Sub(changedAt) > p.MaxAge
+}
+
+// passwordChangedAt returns when user last set their password, falling back
+// to when the user was created.
+func passwordChangedAt(ctx context.Context, q *repository.Queries, user repository.User) (time.Time, error) {
+	security, err := q.GetUserSecurity(ctx, user.ID)
+	if err == nil && security.PasswordChangedAt.Valid {
+		return security.PasswordChangedAt.Time, nil
+	}
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, err
+	}
+	return user.CreatedAt.Time, nil
+}
+
+// setPassword checks password against policy and the user's recent
+// passwords, stores it and revokes the user's sessions. It returns nil on
+// success.
+func setPassword(ctx context.Context, repo *repository.Queries, policy PasswordPolicy, user repository.User, password string) *repository.Response {
+	if err := policy.Check(password); err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	if policy.History > 0 {
+		hashes := []string{user.PasswordHash}
+		if policy.History > 1 {
+			previous, err := repo.ListRecentPasswordHashes(ctx, repository.ListRecentPasswordHashesParams{
+				UserID: user.ID,
+				Limit:  int32(policy.History - 1),
+			})
+			if err != nil {
+				return utils.NewResponse(utils.CodeError, err.Error(), nil)
+			}
+			hashes = append(hashes, previous...)
+		}
+		for _, hash := range hashes {
+			if hash != "" && bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
+				return utils.NewResponse(utils.CodeBadReq, "password must differ from the last "+strconv.Itoa(policy.History)+" passwords", nil)
+			}
+		}
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, "failed to hash password", nil)
+	}
+	err = repo.ExecTx(ctx, func(q *repository.Queries) error {
+		if _, err := q.UpdateUserPassword(ctx, repository.UpdateUserPasswordParams{ID: user.ID, PasswordHash: string(hash)}); err != nil {
+			return err
+		}
+		if policy.History > 1 && user.PasswordHash != "" {
+			if err := q.CreatePasswordHistory(ctx, repository.CreatePasswordHistoryParams{UserID: user.ID, PasswordHash: user.PasswordHash}); err != nil {
+				return err
+			}
+			if err := q.PrunePasswordHistory(ctx, repository.PrunePasswordHistoryParams{UserID: user.ID, Keep: int32(policy.History - 1)}); err != nil {
+				return err
+			}
+		}
+		if err := q.SetUserPasswordChanged(ctx, repository.SetUserPasswordChangedParams{
+			UserID:    user.ID,
+			ChangedAt: pgtype.Timestamp{Time: time.Now().UTC(), Valid: true},
+		}); err != nil {
+			return err
+		}
+		return revokeSessions(ctx, q, user.ID)
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return nil
+}
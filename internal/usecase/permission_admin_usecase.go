@@ -0,0 +1,476 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// PermissionManage lets a user create, change and delete permissions, map
+// them to modules, menus and submenus and grant them to roles.
+const PermissionManage = "permissions.manage"
+
+// Kinds of navigation nodes permissions are mapped to.
+const (
+	PermissionNodeModule  = "module"
+	PermissionNodeMenu    = "menu"
+	PermissionNodeSubmenu = "submenu"
+)
+
+// builtinPermissions are checked by the application itself and cannot be
+// deleted.
+var builtinPermissions = map[string]bool{
+	PermissionAnyStore:            true,
+	PermissionBatchOverride:       true,
+	PermissionManage:              true,
+	PermissionManageStoreAccess:   true,
+	PermissionManageSystemRoles:   true,
+	PermissionManageUsers:         true,
+	PermissionViewAnyUser:         true,
+	PermissionViewStores:          true,
+	PermissionViewStock:           true,
+	PermissionViewPosTransactions: true,
+	PermissionViewSalesOrders:     true,
+	PermissionViewPurchaseOrders:  true,
+}
+
+// PermissionGrant is one cell of the permission matrix: a permission granted
+// to a role. A nil Scope grants 'all', or keeps the scope of an existing
+// grant.
+type PermissionGrant struct {
+	RoleID       int32   `json:"role_id"`
+	PermissionID int32   `json:"permission_id"`
+	Scope        *string `json:"scope,omitempty"`
+}
+
+// PermissionMatrix is every role, every permission and which permissions
+// each role is granted.
+type PermissionMatrix struct {
+	Roles       []repository.Role                        `json:"roles"`
+	Permissions []repository.Permission                  `json:"permissions"`
+	Grants      []repository.ListRolePermissionGrantsRow `json:"grants"`
+}
+
+// CreatePermission creates a permission. viewerID is the signed-in user, see
+// checkCanManagePermissions.
+func (uc *PermissionUseCase) CreatePermission(ctx context.Context, viewerID *int32, name, code string, description *string, metadata []byte) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkCanManagePermissions(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	name, code = strings.TrimSpace(name), strings.TrimSpace(code)
+	if name == "" || code == "" {
+		return utils.NewResponse(utils.CodeBadReq, "name and code are required", nil)
+	}
+	if metadata == nil || string(metadata) == "null" {
+		metadata = []byte("{}")
+	}
+
+	permission, err := uc.repo.CreatePermission(ctx, repository.CreatePermissionParams{
+		Name:        name,
+		Code:        code,
+		Description: toPgText(description),
+		Metadata:    metadata,
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	return utils.NewResponse(utils.CodeCreated, "permission created successfully", permission)
+}
+
+// GetPermission returns a permission by ID.
+func (uc *PermissionUseCase) GetPermission(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if id <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "invalid permission id", nil)
+	}
+
+	permission, err := uc.repo.GetPermission(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewResponse(utils.CodeNotFound, "permission not found", nil)
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	return utils.NewResponse(utils.CodeOK, "permission fetched successfully", permission)
+}
+
+// ListPermissions lists permissions by name.
+func (uc *PermissionUseCase) ListPermissions(ctx context.Context, limit, offset int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	permissions, err := uc.repo.ListPermissions(ctx, repository.ListPermissionsParams{Limit: limit, Offset: offset})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	return utils.NewResponse(utils.CodeOK, "permissions fetched successfully", permissions)
+}
+
+// UpdatePermission changes the name, description or metadata of a
+// permission; nil leaves a field unchanged. The code cannot change.
+func (uc *PermissionUseCase) UpdatePermission(ctx context.Context, viewerID *int32, id int32, name, description *string, metadata []byte) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkCanManagePermissions(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if id <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "invalid permission id", nil)
+	}
+	if string(metadata) == "null" {
+		metadata = nil
+	}
+
+	permission, err := uc.repo.UpdatePermission(ctx, repository.UpdatePermissionParams{
+		ID:          id,
+		Name:        toPgText(name),
+		Description: toPgText(description),
+		Metadata:    metadata,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewResponse(utils.CodeNotFound, "permission not found", nil)
+	}
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "permission updated successfully", permission)
+}
+
+// DeletePermission deletes a permission with its grants to roles and its
+// mappings to navigation. Permissions the application checks itself cannot
+// be deleted.
+func (uc *PermissionUseCase) DeletePermission(ctx context.Context, viewerID *int32, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkCanManagePermissions(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if id <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "invalid permission id", nil)
+	}
+
+	permission, err := uc.repo.GetPermission(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewResponse(utils.CodeNotFound, "permission not found", nil)
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if builtinPermissions[permission.Code] {
+		return utils.NewResponse(utils.CodeBadReq, "permission "+permission.Code+" is used by the application and cannot be deleted", nil)
+	}
+
+	if err := uc.repo.DeletePermission(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "permission deleted successfully", nil)
+}
+
+// ListNodePermissions lists the permissions required by a module, menu or
+// submenu (see PermissionNodeModule and friends).
+func (uc *PermissionUseCase) ListNodePermissions(ctx context.Context, kind string, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkPermissionNode(ctx, uc.repo, kind, id); resp != nil {
+		return resp
+	}
+
+	permissions, err := nodePermissions(ctx, uc.repo, kind, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	return utils.NewResponse(utils.CodeOK, kind+" permissions fetched successfully", permissions)
+}
+
+// AttachNodePermissions maps permissions to a module, menu or submenu, so
+// roles granted any of them see it. Permissions already mapped are skipped.
+func (uc *PermissionUseCase) AttachNodePermissions(ctx context.Context, viewerID *int32, kind string, id int32, permissionIDs []int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkCanManagePermissions(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if resp := checkPermissionNode(ctx, uc.repo, kind, id); resp != nil {
+		return resp
+	}
+	if resp := checkPermissionIDs(permissionIDs); resp != nil {
+		return resp
+	}
+
+	var permissions []repository.Permission
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		mapped, err := mappedPermissions(ctx, q, kind, id)
+		if err != nil {
+			return err
+		}
+		have := make(map[int32]bool, len(mapped))
+		for _, permissionID := range mapped {
+			have[permissionID] = true
+		}
+		for _, permissionID := range permissionIDs {
+			if have[permissionID] {
+				continue
+			}
+			if err := grantMappedPermission(ctx, q, kind, id, permissionID); err != nil {
+				return err
+			}
+			have[permissionID] = true
+		}
+		permissions, err = nodePermissions(ctx, q, kind, id)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "permissions attached to "+kind+" successfully", permissions)
+}
+
+// DetachNodePermissions removes permissions from a module, menu or submenu.
+// Permissions not mapped to it are ignored.
+func (uc *PermissionUseCase) DetachNodePermissions(ctx context.Context, viewerID *int32, kind string, id int32, permissionIDs []int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkCanManagePermissions(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if resp := checkPermissionNode(ctx, uc.repo, kind, id); resp != nil {
+		return resp
+	}
+	if resp := checkPermissionIDs(permissionIDs); resp != nil {
+		return resp
+	}
+
+	var permissions []repository.Permission
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		for _, permissionID := range permissionIDs {
+			if err := revokeMappedPermission(ctx, q, kind, id, permissionID); err != nil {
+				return err
+			}
+		}
+		var err error
+		permissions, err = nodePermissions(ctx, q, kind, id)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "permissions detached from "+kind+" successfully", permissions)
+}
+
+// GetPermissionMatrix returns every role, every permission and the grants
+// between them.
+func (uc *PermissionUseCase) GetPermissionMatrix(ctx context.Context) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+
+	matrix, err := permissionMatrix(ctx, uc.repo)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	return utils.NewResponse(utils.CodeOK, "permission matrix fetched successfully", matrix)
+}
+
+// UpdatePermissionMatrix grants and revokes permissions of roles in one
+// transaction and returns the resulting matrix. Granting a permission a role
+// already has changes its scope when one is given; revoking one it doesn't
+// have is ignored. Changing the permissions of a system role, or granting
+// guardedPermissions, needs PermissionManageSystemRoles too.
+func (uc *PermissionUseCase) UpdatePermissionMatrix(ctx context.Context, viewerID *int32, grant, revoke []PermissionGrant) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkCanManagePermissions(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if len(grant) == 0 && len(revoke) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "grant or revoke is required", nil)
+	}
+	granted := make(map[[2]int32]bool, len(grant))
+	for _, g := range grant {
+		if g.RoleID <= 0 || g.PermissionID <= 0 {
+			return utils.NewResponse(utils.CodeBadReq, "grant needs a role_id and a permission_id", nil)
+		}
+		if g.Scope != nil && !validScope(*g.Scope) {
+			return utils.NewResponse(utils.CodeBadReq, scopeError, nil)
+		}
+		granted[[2]int32{g.RoleID, g.PermissionID}] = true
+	}
+	for _, r := range revoke {
+		if r.RoleID <= 0 || r.PermissionID <= 0 {
+			return utils.NewResponse(utils.CodeBadReq, "revoke needs a role_id and a permission_id", nil)
+		}
+		if granted[[2]int32{r.RoleID, r.PermissionID}] {
+			return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("permission %d of role %d is both granted and revoked", r.PermissionID, r.RoleID), nil)
+		}
+	}
+	checked := map[int32]bool{}
+	for _, g := range append(append([]PermissionGrant{}, grant...), revoke...) {
+		if checked[g.RoleID] {
+			continue
+		}
+		checked[g.RoleID] = true
+		if resp := checkSystemRolePermissionChange(ctx, uc.repo, viewerID, g.RoleID); resp != nil {
+			return resp
+		}
+	}
+	ids := make([]int32, len(grant))
+	for i, g := range grant {
+		ids[i] = g.PermissionID
+	}
+	codes, resp := permissionCodes(ctx, uc.repo, ids)
+	if resp != nil {
+		return resp
+	}
+	if resp := checkCanGrantPermissions(ctx, uc.repo, viewerID, codes); resp != nil {
+		return resp
+	}
+
+	var matrix *PermissionMatrix
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		for _, g := range grant {
+			if err := q.GrantPermissionToRole(ctx, repository.GrantPermissionToRoleParams{
+				RoleID:       g.RoleID,
+				PermissionID: g.PermissionID,
+				Scope:        toPgText(g.Scope),
+			}); err != nil {
+				return err
+			}
+		}
+		for _, r := range revoke {
+			if err := q.RevokePermissionFromRole(ctx, repository.RevokePermissionFromRoleParams{
+				RoleID:       r.RoleID,
+				PermissionID: r.PermissionID,
+			}); err != nil {
+				return err
+			}
+		}
+		var err error
+		matrix, err = permissionMatrix(ctx, q)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "permission matrix updated successfully", matrix)
+}
+
+// checkCanManagePermissions lets the signed-in viewerID change permissions
+// only with PermissionManage.
+func checkCanManagePermissions(ctx context.Context, q *repository.Queries, viewerID *int32) *repository.Response {
+	if viewerID == nil {
+		return utils.NewResponse(utils.CodeForbidden, "managing permissions requires the "+PermissionManage+" permission", nil)
+	}
+	ok, err := q.CheckUserHasPermission(ctx, repository.CheckUserHasPermissionParams{UserID: *viewerID, Code: PermissionManage})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if !ok {
+		return utils.NewResponse(utils.CodeForbidden, "managing permissions requires the "+PermissionManage+" permission", nil)
+	}
+	return nil
+}
+
+// checkPermissionNode checks that the module, menu or submenu exists.
+func checkPermissionNode(ctx context.Context, q *repository.Queries, kind string, id int32) *repository.Response {
+	if id <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "invalid "+kind+" id", nil)
+	}
+	var err error
+	switch kind {
+	case PermissionNodeModule:
+		_, err = q.GetModule(ctx, id)
+	case PermissionNodeMenu:
+		_, err = q.GetMenu(ctx, id)
+	case PermissionNodeSubmenu:
+		_, err = q.GetSubmenu(ctx, id)
+	default:
+		return utils.NewResponse(utils.CodeBadReq, "kind must be module, menu or submenu", nil)
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewResponse(utils.CodeNotFound, kind+" not found", nil)
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return nil
+}
+
+func checkPermissionIDs(permissionIDs []int32) *repository.Response {
+	if len(permissionIDs) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "permission ids are required", nil)
+	}
+	for _, id := range permissionIDs {
+		if id <= 0 {
+			return utils.NewResponse(utils.CodeBadReq, "invalid permission id", nil)
+		}
+	}
+	return nil
+}
+
+// nodePermissions lists the permissions mapped to a module, menu or submenu.
+func nodePermissions(ctx context.Context, q *repository.Queries, kind string, id int32) ([]repository.Permission, error) {
+	switch kind {
+	case PermissionNodeModule:
+		return q.GetModulePermissions(ctx, id)
+	case PermissionNodeMenu:
+		return q.GetMenuPermissions(ctx, id)
+	case PermissionNodeSubmenu:
+		return q.GetSubmenuPermissions(ctx, id)
+	}
+	return nil, nil
+}
+
+func permissionMatrix(ctx context.Context, q *repository.Queries) (*PermissionMatrix, error) {
+	roles, err := q.ListRoles(ctx)
+	if err != nil {
+		return nil, err
+	}
+	permissions, err := q.ListAllPermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := q.ListRolePermissionGrants(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &PermissionMatrix{Roles: roles, Permissions: permissions, Grants: grants}, nil
+}
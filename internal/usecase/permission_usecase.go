@@ -2,11 +2,19 @@ package usecase
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 
 	"NEMBUS/internal/repository"
 	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5"
 )
 
+// PermissionViewAnyUser lets a user read another user's profile, navigation,
+// permissions and routes by user ID. Everyone can read their own.
+const PermissionViewAnyUser = "users.view_any"
+
 type PermissionUseCase struct {
 	repo *repository.Queries
 }
@@ -22,11 +30,15 @@ func (uc *PermissionUseCase) SetRepository(repo *repository.Queries) {
 	uc.repo = repo
 }
 
-// CheckUserSubmenuPermission checks if a user has access to a submenu by submenu code
-func (uc *PermissionUseCase) CheckUserSubmenuPermission(ctx context.Context, userID int32, submenuCode string) *repository.Response {
+// CheckUserSubmenuPermission checks if a user has access to a submenu by
+// submenu code. viewerID is the signed-in user, see checkUserVisible.
+func (uc *PermissionUseCase) CheckUserSubmenuPermission(ctx context.Context, viewerID *int32, userID int32, submenuCode string) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
+	if resp := checkUserVisible(ctx, uc.repo, viewerID, userID); resp != nil {
+		return resp
+	}
 
 	if submenuCode == "" {
 		return utils.NewResponse(utils.CodeBadReq, "submenu code cannot be empty", nil)
@@ -47,3 +59,63 @@ func (uc *PermissionUseCase) CheckUserSubmenuPermission(ctx context.Context, use
 		"user_id":       userID,
 	})
 }
+
+// UserPermissions is a user's roles and the permissions granted by them.
+type UserPermissions struct {
+	UserID          int32           `json:"user_id"`
+	Roles           json.RawMessage `json:"roles"`
+	Permissions     json.RawMessage `json:"permissions"`
+	PermissionCodes json.RawMessage `json:"permission_codes"`
+}
+
+// GetUserPermissions returns a user's roles and permissions. viewerID is the
+// signed-in user, see checkUserVisible.
+func (uc *PermissionUseCase) GetUserPermissions(ctx context.Context, viewerID *int32, userID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkUserVisible(ctx, uc.repo, viewerID, userID); resp != nil {
+		return resp
+	}
+
+	profile, err := uc.repo.GetUserProfileWithRolesAndPermissions(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	return utils.NewResponse(utils.CodeOK, "user permissions fetched successfully", UserPermissions{
+		UserID:          profile.UserID,
+		Roles:           profile.Roles,
+		Permissions:     profile.Permissions,
+		PermissionCodes: profile.PermissionCodes,
+	})
+}
+
+// checkUserVisible lets the signed-in viewerID read userID's profile,
+// navigation and permissions: their own always, another user's of the active
+// organization only with PermissionViewAnyUser.
+func checkUserVisible(ctx context.Context, q *repository.Queries, viewerID *int32, userID int32) *repository.Response {
+	if userID <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "invalid user id", nil)
+	}
+	if viewerID == nil {
+		return utils.NewResponse(utils.CodeForbidden, "reading another user requires the "+PermissionViewAnyUser+" permission", nil)
+	}
+	if *viewerID == userID {
+		return nil
+	}
+	ok, err := q.CheckUserHasPermission(ctx, repository.CheckUserHasPermissionParams{UserID: *viewerID, Code: PermissionViewAnyUser})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if !ok {
+		return utils.NewResponse(utils.CodeForbidden, "reading another user requires the "+PermissionViewAnyUser+" permission", nil)
+	}
+	if user, err := q.GetUser(ctx, userID); err != nil || !inActiveOrganization(ctx, user.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"NEMBUS/internal/barcode"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PosScannedProduct is a product found through a scale or GS1 barcode,
+// with the cart line values read from the barcode.
+type PosScannedProduct struct {
+	repository.PosProductByBarcodeRow
+	Scan PosScan `json:"scan"`
+}
+
+// PosScan is what a scanned barcode adds to the cart line. Quantity is
+// the embedded weight or count, or for a price-embedded label the label
+// price divided by the unit price; LineTotal is the label price.
+type PosScan struct {
+	Kind         string `json:"kind"`
+	Barcode      string `json:"barcode"`
+	GTIN         string `json:"gtin,omitempty"`
+	ItemCode     string `json:"item_code,omitempty"`
+	Quantity     string `json:"quantity,omitempty"`
+	LineTotal    string `json:"line_total,omitempty"`
+	BatchNumber  string `json:"batch_number,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	ExpiryDate   string `json:"expiry_date,omitempty"`
+	BestBefore   string `json:"best_before,omitempty"`
+	Expired      bool   `json:"expired,omitempty"`
+}
+
+// scanBarcode decodes q as a scale or GS1 barcode and resolves its product
+// in the store. It returns nil when q is not such a barcode or no product
+// matches, so the search can go on.
+func (uc *PosUseCase) scanBarcode(ctx context.Context, storeID int32, q string) *repository.Response {
+	rows, err := uc.repo.ListActiveBarcodeRules(ctx)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	rules := make([]barcode.Rule, 0, len(rows))
+	for _, r := range rows {
+		rules = append(rules, barcodeRule(r))
+	}
+	res, err := barcode.Parse(q, rules)
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+
+	lookup := res.Lookup
+	if res.Kind == barcode.KindPlain {
+		// The code itself was already tried as an exact barcode.
+		lookup = lookup[1:]
+	}
+	for _, code := range lookup {
+		row, err := uc.repo.PosGetProductByBarcode(ctx, code, storeID)
+		if err != nil {
+			continue
+		}
+		if res.Kind == barcode.KindPlain {
+			return utils.NewResponse(utils.CodeOK, "product found by barcode", row)
+		}
+		return utils.NewResponse(utils.CodeOK, "product found by "+string(res.Kind)+" barcode", &PosScannedProduct{
+			PosProductByBarcodeRow: row,
+			Scan:                   scannedLine(res, row),
+		})
+	}
+	return nil
+}
+
+// scannedLine fills the cart line values of a decoded barcode.
+func scannedLine(res *barcode.Result, row repository.PosProductByBarcodeRow) PosScan {
+	s := PosScan{
+		Kind:         string(res.Kind),
+		Barcode:      res.Raw,
+		GTIN:         res.GTIN,
+		ItemCode:     res.ItemCode,
+		BatchNumber:  res.Batch,
+		SerialNumber: res.Serial,
+	}
+	places := 3
+	if row.DecimalPlaces.Valid {
+		places = int(row.DecimalPlaces.Int32)
+	}
+	qty := res.Quantity
+	if res.Price != nil {
+		s.LineTotal = res.Price.FloatString(2)
+		if unit := utils.NumericToRat(row.EffectivePrice); qty == nil && unit.Sign() > 0 {
+			qty = new(big.Rat).Quo(res.Price, unit)
+		}
+	}
+	if qty != nil {
+		if !row.AllowDecimalQty.Bool {
+			places = 0
+		}
+		s.Quantity = qty.FloatString(places)
+	}
+	if res.Expiry != nil {
+		s.ExpiryDate = res.Expiry.Format("2006-01-02")
+		s.Expired = res.Expiry.Before(time.Now().Truncate(24 * time.Hour))
+	}
+	if res.BestBefore != nil {
+		s.BestBefore = res.BestBefore.Format("2006-01-02")
+	}
+	return s
+}
+
+func barcodeRule(r repository.BarcodeRule) barcode.Rule {
+	return barcode.Rule{
+		Prefix:          r.Prefix,
+		ValueType:       barcode.ValueType(r.ValueType),
+		ItemDigits:      int(r.ItemDigits),
+		ValueDigits:     int(r.ValueDigits),
+		ValueDecimals:   int(r.ValueDecimals),
+		ValueCheckDigit: r.ValueCheckDigit,
+	}
+}
+
+// ListBarcodeRules returns the tenant's variable-measure barcode rules.
+func (uc *PosUseCase) ListBarcodeRules(ctx context.Context) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	rows, err := uc.repo.ListBarcodeRules(ctx)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "barcode rules fetched successfully", rows)
+}
+
+// BarcodeRuleInput is the input for SaveBarcodeRule.
+type BarcodeRuleInput struct {
+	Prefix          string
+	Name            string
+	ValueType       string
+	ItemDigits      int32
+	ValueDigits     int32
+	ValueDecimals   int32
+	ValueCheckDigit bool
+	IsActive        *bool
+}
+
+// SaveBarcodeRule creates or replaces the rule for a prefix.
+func (uc *PosUseCase) SaveBarcodeRule(ctx context.Context, in *BarcodeRuleInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	arg := repository.UpsertBarcodeRuleParams{
+		Prefix:          in.Prefix,
+		Name:            in.Name,
+		ValueType:       in.ValueType,
+		ItemDigits:      in.ItemDigits,
+		ValueDigits:     in.ValueDigits,
+		ValueDecimals:   in.ValueDecimals,
+		ValueCheckDigit: in.ValueCheckDigit,
+		IsActive:        pgtype.Bool{Bool: true, Valid: true},
+		Metadata:        []byte("{}"),
+	}
+	if in.IsActive != nil {
+		arg.IsActive.Bool = *in.IsActive
+	}
+	rule := barcodeRule(repository.BarcodeRule{
+		Prefix:          arg.Prefix,
+		ValueType:       arg.ValueType,
+		ItemDigits:      arg.ItemDigits,
+		ValueDigits:     arg.ValueDigits,
+		ValueDecimals:   arg.ValueDecimals,
+		ValueCheckDigit: arg.ValueCheckDigit,
+	})
+	if err := rule.Validate(); err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	row, err := uc.repo.UpsertBarcodeRule(ctx, arg)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "barcode rule saved", row)
+}
+
+// DeleteBarcodeRule removes the rule for a prefix.
+func (uc *PosUseCase) DeleteBarcodeRule(ctx context.Context, prefix string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	n, err := uc.repo.DeleteBarcodeRule(ctx, prefix)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if n == 0 {
+		return utils.NewResponse(utils.CodeNotFound, "barcode rule not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "barcode rule deleted", nil)
+}
@@ -0,0 +1,473 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/inventory"
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/pricing"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PosCheckoutLine is one cart line of a POS sale. Prices come from the
+// price resolver; Discount is a line amount in the same price basis.
+// Serialized products need one serial number per unit (SerialNumber and/or
+// SerialNumbers). Batch-managed products are allocated first-expiry-first-out;
+// BatchNumber overrides the allocation and needs the batch override
+// permission.
+type PosCheckoutLine struct {
+	ProductID        int32
+	ProductVariantID *int32
+	Quantity         string
+	Discount         string
+	SerialNumber     string
+	SerialNumbers    []string
+	BatchNumber      string
+}
+
+// PosCheckoutPayment is one tender of a POS sale.
+type PosCheckoutPayment struct {
+	Method    string
+	Amount    string
+	Reference string
+}
+
+// PosCheckoutInput is the input for Checkout.
+type PosCheckoutInput struct {
+	// UserID is the signed-in user, who rings up the sale on their cashier's
+	// open session and is checked for the batch override permission.
+	UserID *int32
+	// CashierID optionally names the user's cashier; see cashierSession.
+	CashierID  int32
+	CustomerID *int32
+	Lines      []PosCheckoutLine
+	Payments   []PosCheckoutPayment
+}
+
+// PosCheckoutResult is returned by Checkout.
+type PosCheckoutResult struct {
+	TransactionID     int32  `json:"transaction_id"`
+	TransactionNumber string `json:"transaction_number"`
+	DocumentTotals
+	AmountPaid string `json:"amount_paid"`
+	ChangeDue  string `json:"change_due"`
+	// ZatcaQRCode is the QR code of the simplified tax invoice issued with
+	// the sale; empty when the organization has no VAT number.
+	ZatcaQRCode string `json:"zatca_qr_code,omitempty"`
+}
+
+// Checkout records a completed POS sale on the cashier's open session: it
+// prices the cart, applies tax per product tax category, checks that the
+// payments cover the total and writes the transaction, lines, payments and
+// stock movements in one database transaction. A line of a batch-managed
+// product taken from several batches is recorded as one line per batch, and
+// a line of a serialized product as one line per unit, which is marked sold.
+// When the organization is VAT registered, the ZATCA simplified tax invoice
+// is issued and chained in the same database transaction.
+func (uc *PosUseCase) Checkout(ctx context.Context, in *PosCheckoutInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if len(in.Lines) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "cart is empty", nil)
+	}
+	if len(in.Payments) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "at least one payment is required", nil)
+	}
+	session, terminal, store, resp := cashierSession(ctx, uc.repo, in.UserID, in.CashierID)
+	if resp != nil {
+		return resp
+	}
+	org, err := uc.repo.GetOrganization(ctx, store.OrganizationID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "organization not found", nil)
+	}
+
+	var customer *repository.Customer
+	if in.CustomerID != nil {
+		c, err := uc.repo.GetCustomer(ctx, *in.CustomerID)
+		if err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "customer not found", nil)
+		}
+		customer = &c
+	}
+	now := time.Now()
+	prices, err := newPriceResolver(ctx, uc.repo, saleContext{storeID: store.ID, customer: customer, date: now})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	opt, err := documentTaxOptions(org, customer)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	invoiced := org.TaxID.Valid && strings.TrimSpace(org.TaxID.String) != ""
+	exemptionCode := ""
+	if opt.Exempt {
+		exemptionCode = zatcaExemptionCode(customer.Metadata)
+		if invoiced && exemptionCode == "" {
+			return utils.NewResponse(utils.CodeBadReq, "tax-exempt customer has no exemption reason code (metadata tax_exemption_code)", nil)
+		}
+	}
+
+	var (
+		lines      []documentLine
+		priced     []*pricing.Result
+		sold       []soldLine
+		seen       = serialCapture{}
+		overrideOK bool
+	)
+	for _, l := range in.Lines {
+		product, err := uc.repo.GetProduct(ctx, l.ProductID)
+		if err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+		}
+		if !product.IsActive.Bool || !product.IsSellable.Bool {
+			return utils.NewResponse(utils.CodeBadReq, "product "+product.Sku+" is not sellable", nil)
+		}
+		qty, err := parseQuantity(product, l.Quantity)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		discount, err := parseAmount("discount", l.Discount)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		variantID := optionalInt4(l.ProductVariantID)
+		price, err := prices.resolve(ctx, product, variantID.Int32, 0, qty)
+		if errors.Is(err, pricing.ErrNoPrice) {
+			return utils.NewResponse(utils.CodeBadReq, "no price for product "+product.Sku+": "+strings.Join(price.Explanation, "; "), nil)
+		}
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		uomID := product.BaseUomID
+		if price.UomID != 0 {
+			uomID = pgtype.Int4{Int32: price.UomID, Valid: true}
+		}
+		line := documentLine{
+			product:   product,
+			variantID: variantID,
+			uomID:     uomID,
+			quantity:  qty,
+			unitPrice: price.Price,
+			discount:  discount,
+		}
+		parts := []soldLine{{quantity: qty, serial: l.SerialNumber, batch: l.BatchNumber}}
+		var units []repository.ProductSerialNumber
+		if product.IsSerialized.Bool {
+			serials, err := seen.capture(product, lineSerials(l.SerialNumber, l.SerialNumbers), qty)
+			if err == nil {
+				units, err = lookupSerials(ctx, uc.repo, product, variantID, store.ID, serials, inventory.SerialSold)
+			}
+			if err != nil {
+				return checkoutError(err)
+			}
+		}
+		if product.IsBatchManaged.Bool && product.TrackInventory.Bool {
+			batchNumber := strings.TrimSpace(l.BatchNumber)
+			if batchNumber != "" && !overrideOK {
+				if resp := checkBatchOverride(ctx, uc.repo, in.UserID); resp != nil {
+					return resp
+				}
+				overrideOK = true
+			}
+			allocs, err := allocateBatches(ctx, uc.repo, product, variantID, store.ID, qty, batchNumber, now)
+			if err != nil {
+				return checkoutError(err)
+			}
+			parts = parts[:0]
+			for j, a := range allocs {
+				parts = append(parts, soldLine{quantity: a.Quantity, batch: a.Batch.Number, alloc: &allocs[j]})
+			}
+		}
+		if units != nil {
+			if parts, err = splitUnits(parts, units); err != nil {
+				return checkoutError(err)
+			}
+		}
+
+		qtys := make([]*big.Rat, len(parts))
+		for j, p := range parts {
+			qtys[j] = p.quantity
+		}
+		discounts := splitAmount(discount, qtys, 2)
+		for j, p := range parts {
+			part := line
+			part.quantity = p.quantity
+			part.discount = discounts[j]
+			lines = append(lines, part)
+			priced = append(priced, price)
+			sold = append(sold, p)
+		}
+	}
+
+	res, err := calculateDocumentTax(ctx, uc.repo, lines, opt)
+	if err != nil {
+		return checkoutError(err)
+	}
+
+	paid := new(big.Rat)
+	amounts := make([]*big.Rat, len(in.Payments))
+	for i, p := range in.Payments {
+		if strings.TrimSpace(p.Method) == "" {
+			return utils.NewResponse(utils.CodeBadReq, "payment method is required", nil)
+		}
+		amount, err := parseAmount("payment amount", p.Amount)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		amounts[i] = amount
+		paid.Add(paid, amount)
+	}
+	if paid.Cmp(res.Total) < 0 {
+		return utils.NewResponse(utils.CodeBadReq, "payments "+paid.FloatString(2)+" do not cover total "+res.Total.FloatString(2), nil)
+	}
+	change := new(big.Rat).Sub(paid, res.Total)
+
+	result := &PosCheckoutResult{
+		TransactionNumber: documentNumber(terminal.TerminalCode, now),
+		DocumentTotals:    newDocumentTotals(res, opt),
+		AmountPaid:        paid.FloatString(2),
+		ChangeDue:         change.FloatString(2),
+	}
+	meta, _ := json.Marshal(map[string]interface{}{
+		"amount_paid":        result.AmountPaid,
+		"change_due":         result.ChangeDue,
+		"tax_rounding":       result.Rounding,
+		"tax_exempt":         result.TaxExempt,
+		"tax_exemption_code": exemptionCode,
+	})
+
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		header, err := q.CreatePosTransaction(ctx, repository.CreatePosTransactionParams{
+			TransactionNumber: result.TransactionNumber,
+			StoreID:           store.ID,
+			PosTerminalID:     terminal.ID,
+			CashierSessionID:  session.ID,
+			CashierID:         session.CashierID,
+			CustomerID:        optionalInt4(in.CustomerID),
+			PriceListID:       pgtype.Int4{Int32: priced[0].PriceListID, Valid: true},
+			TransactionType:   pgtype.Text{String: "sale", Valid: true},
+			TransactionDate:   pgtype.Timestamp{Time: now, Valid: true},
+			Subtotal:          utils.RatToNumeric(res.Subtotal, 2),
+			TaxAmount:         utils.RatToNumeric(res.Tax, 2),
+			DiscountAmount:    utils.RatToNumeric(res.Discount, 2),
+			TotalAmount:       utils.RatToNumeric(res.Total, 2),
+			TotalCost:         utils.RatToNumeric(new(big.Rat), 2),
+			Status:            pgtype.Text{String: "completed", Valid: true},
+			Metadata:          meta,
+		})
+		if err != nil {
+			return err
+		}
+		result.TransactionID = header.ID
+
+		for i, l := range lines {
+			lr := res.Lines[i]
+			src := sold[i]
+			if err := q.CreatePosTransactionLine(ctx, repository.CreatePosTransactionLineParams{
+				TransactionID:    header.ID,
+				LineNumber:       int32(i + 1),
+				ProductID:        l.product.ID,
+				ProductVariantID: l.variantID,
+				SerialNumber:     optionalText(src.serial),
+				BatchNumber:      optionalText(src.batch),
+				Quantity:         utils.RatToNumeric(l.quantity, 3),
+				UomID:            l.uomID,
+				UnitPrice:        utils.RatToNumeric(l.unitPrice, 4),
+				DiscountAmount:   utils.RatToNumeric(lr.Discount, 2),
+				TaxAmount:        utils.RatToNumeric(lr.Tax, 2),
+				LineTotal:        utils.RatToNumeric(lr.LineTotal, 2),
+				Metadata:         withLineTax(priceMetadata(priced[i]), lr),
+			}); err != nil {
+				return err
+			}
+			if src.unit != nil {
+				if err := moveSerial(ctx, q, *src.unit, inventory.SerialSold, pgtype.Int4{}); err != nil {
+					return err
+				}
+			}
+			if !l.product.TrackInventory.Bool {
+				continue
+			}
+			if src.alloc != nil {
+				if err := consumeBatch(ctx, q, *src.alloc, now); err != nil {
+					return err
+				}
+			}
+			if _, err := q.CreateStockMovement(ctx, repository.CreateStockMovementParams{
+				MovementType:     "sale",
+				ReferenceType:    pgtype.Text{String: "pos_transaction", Valid: true},
+				ReferenceID:      pgtype.Int4{Int32: header.ID, Valid: true},
+				ProductID:        l.product.ID,
+				ProductVariantID: l.variantID,
+				FromStoreID:      pgtype.Int4{Int32: store.ID, Valid: true},
+				Quantity:         utils.RatToNumeric(l.quantity, 3),
+				UomID:            l.uomID,
+				BatchNumber:      optionalText(src.batch),
+				SerialNumber:     optionalText(src.serial),
+				MovementDate:     pgtype.Timestamp{Time: now, Valid: true},
+				Status:           pgtype.Text{String: "completed", Valid: true},
+				Metadata:         []byte("{}"),
+			}); err != nil {
+				return err
+			}
+			if _, err := q.AdjustInventoryStock(ctx, repository.AdjustInventoryStockParams{
+				QuantityDelta:    utils.RatToNumeric(new(big.Rat).Neg(l.quantity), 3),
+				ProductID:        l.product.ID,
+				StoreID:          store.ID,
+				ProductVariantID: l.variantID,
+			}); err != nil {
+				return err
+			}
+		}
+
+		for i, p := range in.Payments {
+			if err := q.AddPaymentToTransaction(ctx, repository.AddPaymentToTransactionParams{
+				TransactionID:   header.ID,
+				PaymentMethod:   strings.ToLower(strings.TrimSpace(p.Method)),
+				Amount:          utils.RatToNumeric(amounts[i], 2),
+				ReferenceNumber: optionalText(p.Reference),
+				Metadata:        []byte("{}"),
+			}); err != nil {
+				return err
+			}
+		}
+
+		if !invoiced {
+			return nil
+		}
+		txn, err := q.GetPosTransactionByNumber(ctx, result.TransactionNumber)
+		if err != nil {
+			return err
+		}
+		inv, err := issueZatcaInvoice(ctx, q, uc.signer, txn)
+		if err != nil {
+			return err
+		}
+		result.ZatcaQRCode = inv.QrCode
+		return nil
+	})
+	if err != nil {
+		return checkoutError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "sale completed", result)
+}
+
+// soldLine is what a transaction line records about the goods sold: the
+// quantity, serial and batch numbers and, for serialized and batch-managed
+// products, the unit and the batch allocation to take from stock.
+type soldLine struct {
+	quantity *big.Rat
+	serial   string
+	batch    string
+	alloc    *inventory.Allocation
+	unit     *repository.ProductSerialNumber
+}
+
+// splitUnits splits sold quantities into one line per serialized unit,
+// assigning units in order. Each unit keeps the batch of its part.
+func splitUnits(parts []soldLine, units []repository.ProductSerialNumber) ([]soldLine, error) {
+	var out []soldLine
+	for _, p := range parts {
+		if !p.quantity.IsInt() {
+			return nil, documentInputErrorf("batch %s holds a partial unit of a serialized product", p.batch)
+		}
+		for n := p.quantity.Num().Int64(); n > 0; n-- {
+			u := &units[len(out)]
+			one := p
+			one.quantity = big.NewRat(1, 1)
+			one.serial = u.SerialNumber
+			one.unit = u
+			if p.alloc != nil {
+				a := *p.alloc
+				a.Quantity = big.NewRat(1, 1)
+				one.alloc = &a
+			}
+			out = append(out, one)
+		}
+	}
+	return out, nil
+}
+
+// checkoutError maps an error found while building a sale to a response.
+func checkoutError(err error) *repository.Response {
+	var bad *documentInputError
+	if errors.As(err, &bad) {
+		return utils.NewResponse(utils.CodeBadReq, bad.Error(), nil)
+	}
+	var invoice *zatcaInputError
+	if errors.As(err, &invoice) {
+		return utils.NewResponse(utils.CodeBadReq, invoice.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeError, err.Error(), nil)
+}
+
+func optionalText(s string) pgtype.Text {
+	s = strings.TrimSpace(s)
+	return pgtype.Text{String: s, Valid: s != ""}
+}
+
+// cashierSession returns the open session of the signed-in user's cashier,
+// with its terminal and store. cashierID, when not zero, names the cashier,
+// which must be the user's; otherwise the user's latest open session is
+// used. The store must be in the active organization and one the user has
+// access to, see middleware.HasStoreAccess.
+func cashierSession(ctx context.Context, q *repository.Queries, userID *int32, cashierID int32) (repository.GetActiveCashierSessionRow, repository.PosTerminal, repository.Store, *repository.Response) {
+	var (
+		session  repository.GetActiveCashierSessionRow
+		terminal repository.PosTerminal
+		store    repository.Store
+	)
+	if userID == nil {
+		return session, terminal, store, utils.NewResponse(utils.CodeUnauthorized, "a signed-in cashier is required", nil)
+	}
+	if cashierID == 0 {
+		id, err := q.GetActiveCashierIDByUser(ctx, *userID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return session, terminal, store, utils.NewResponse(utils.CodeBadReq, "cashier has no open session", nil)
+		}
+		if err != nil {
+			return session, terminal, store, utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		cashierID = id
+	} else {
+		cashier, err := q.GetCashier(ctx, cashierID)
+		if err != nil {
+			return session, terminal, store, utils.NewResponse(utils.CodeNotFound, "cashier not found", nil)
+		}
+		if cashier.UserID != *userID {
+			return session, terminal, store, utils.NewResponse(utils.CodeForbidden, "cashier belongs to another user", nil)
+		}
+	}
+
+	session, err := q.GetActiveCashierSession(ctx, cashierID)
+	if err != nil {
+		return session, terminal, store, utils.NewResponse(utils.CodeBadReq, "cashier has no open session", nil)
+	}
+	terminal, err = q.GetPOSTerminal(ctx, session.PosTerminalID)
+	if err != nil {
+		return session, terminal, store, utils.NewResponse(utils.CodeNotFound, "terminal not found", nil)
+	}
+	store, err = q.GetStore(ctx, terminal.StoreID)
+	if err != nil || !inActiveOrganization(ctx, store.OrganizationID) {
+		return session, terminal, store, utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+	}
+	allowed, err := middleware.HasStoreAccess(ctx, q, *userID, store.ID)
+	if err != nil {
+		return session, terminal, store, utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if !allowed {
+		return session, terminal, store, utils.NewResponse(utils.CodeForbidden, "no access to this store", nil)
+	}
+	return session, terminal, store, nil
+}
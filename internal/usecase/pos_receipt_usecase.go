@@ -0,0 +1,325 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"NEMBUS/internal/receipt"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RenderReceipt renders the receipt for a POS transaction. templateCode picks
+// a receipt template (the tenant default when empty) and language, when set,
+// overrides the template language. On success Data holds a *receipt.Output.
+func (uc *PosUseCase) RenderReceipt(ctx context.Context, transactionNumber string, format receipt.Format, templateCode string, language string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	txn, err := uc.repo.GetPosTransactionByNumber(ctx, transactionNumber)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "transaction not found", nil)
+	}
+	if resp := checkPosTransactionInScope(ctx, uc.repo, txn); resp != nil {
+		return resp
+	}
+	lines, err := uc.repo.GetPosTransactionFull(ctx, txn.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if len(lines) == 0 {
+		return utils.NewResponse(utils.CodeNotFound, "transaction has no lines", nil)
+	}
+	taxes, err := uc.repo.GetPosTransactionTaxBreakdown(ctx, txn.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	payments, err := uc.repo.GetPaymentsForTransaction(ctx, txn.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	store, err := uc.repo.GetStore(ctx, txn.StoreID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+	}
+
+	tpl, resp := uc.loadReceiptTemplate(ctx, templateCode)
+	if resp != nil {
+		return resp
+	}
+	if language != "" {
+		lang := receipt.Language(strings.ToLower(language))
+		if lang != receipt.LanguageEnglish && lang != receipt.LanguageArabic && lang != receipt.LanguageBilingual {
+			return utils.NewResponse(utils.CodeBadReq, "invalid language (use en, ar or bilingual)", nil)
+		}
+		tpl.Language = lang
+	}
+
+	r := buildReceipt(txn, lines, taxes, payments, store)
+	if org, err := uc.repo.GetOrganization(ctx, store.OrganizationID); err == nil {
+		if org.CurrencyCode.Valid {
+			r.Currency = org.CurrencyCode.String
+		}
+		if r.Header.VATNumber == "" && org.TaxID.Valid {
+			r.Header.VATNumber = org.TaxID.String
+		}
+	}
+
+	if inv, err := uc.repo.GetZatcaInvoiceByTransaction(ctx, txn.ID); err == nil {
+		r.TaxInvoice = true
+		r.QRCode = inv.QrCode
+	}
+
+	out, err := receipt.Render(r, tpl, format)
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "receipt rendered", out)
+}
+
+// loadReceiptTemplate returns the named template, or the tenant default, or
+// the built-in layout when the tenant has none.
+func (uc *PosUseCase) loadReceiptTemplate(ctx context.Context, code string) (receipt.Template, *repository.Response) {
+	var row repository.ReceiptTemplate
+	var err error
+	if code != "" {
+		row, err = uc.repo.GetReceiptTemplateByCode(ctx, code)
+		if err != nil {
+			return receipt.Template{}, utils.NewResponse(utils.CodeNotFound, "receipt template not found", nil)
+		}
+	} else {
+		row, err = uc.repo.GetDefaultReceiptTemplate(ctx)
+		if err != nil {
+			return receipt.DefaultTemplate(), nil
+		}
+	}
+
+	tpl := receipt.DefaultTemplate()
+	tpl.Language = receipt.Language(row.Language)
+	tpl.Width = int(row.PaperWidth)
+	tpl.HeaderLines = decodeStringList(row.HeaderLines)
+	tpl.FooterLines = decodeStringList(row.FooterLines)
+	if row.ShowTaxBreakdown.Valid {
+		tpl.ShowTaxBreakdown = row.ShowTaxBreakdown.Bool
+	}
+	if row.ShowBarcode.Valid {
+		tpl.ShowBarcode = row.ShowBarcode.Bool
+	}
+	if row.CodePage.Valid {
+		tpl.CodePage = int(row.CodePage.Int32)
+	}
+	return tpl, nil
+}
+
+func buildReceipt(txn repository.PosTransaction, lines []repository.GetPosTransactionFullRow, taxes []repository.GetPosTransactionTaxBreakdownRow, payments []repository.GetPaymentsForTransactionRow, store repository.Store) *receipt.Receipt {
+	first := lines[0]
+	r := &receipt.Receipt{
+		Header:   storeReceiptHeader(store),
+		Number:   txn.TransactionNumber,
+		Date:     txn.TransactionDate.Time,
+		Subtotal: utils.FormatNumeric(txn.Subtotal, 2),
+		Discount: utils.FormatNumeric(txn.DiscountAmount, 2),
+		Tax:      utils.FormatNumeric(txn.TaxAmount, 2),
+		Total:    utils.FormatNumeric(txn.TotalAmount, 2),
+	}
+	if txn.Status.String == "voided" {
+		r.Title = "VOID"
+	}
+	if name, ok := first.CashierName.(string); ok {
+		r.Cashier = name
+	}
+	if first.TerminalName.Valid {
+		r.Terminal = first.TerminalName.String
+	}
+	if first.CustomerName.Valid {
+		r.Customer = first.CustomerName.String
+	}
+
+	for _, l := range lines {
+		r.Lines = append(r.Lines, receipt.Line{
+			Name:      l.ProductName,
+			SKU:       l.Sku,
+			Quantity:  formatQuantity(l.Quantity),
+			UnitPrice: utils.FormatNumeric(l.UnitPrice, 2),
+			Discount:  utils.FormatNumeric(l.DiscountAmount_2, 2),
+			Total:     utils.FormatNumeric(l.LineTotal, 2),
+		})
+	}
+
+	for _, t := range taxes {
+		name := t.TaxName
+		if name == "" {
+			name = "No tax"
+		}
+		r.TaxBreakdown = append(r.TaxBreakdown, receipt.TaxLine{
+			Name:      name,
+			Rate:      formatQuantity(t.TaxRate),
+			Inclusive: t.IsInclusive,
+			Taxable:   utils.FormatNumeric(t.TaxableAmount, 2),
+			Tax:       utils.FormatNumeric(t.TaxAmount, 2),
+		})
+	}
+
+	paid := new(big.Rat)
+	for _, p := range payments {
+		paid.Add(paid, utils.NumericToRat(p.Amount))
+		r.Payments = append(r.Payments, receipt.Payment{
+			Method:    p.PaymentMethod,
+			Amount:    utils.FormatNumeric(p.Amount, 2),
+			Reference: p.ReferenceNumber.String,
+		})
+	}
+	change := new(big.Rat).Sub(paid, utils.NumericToRat(txn.TotalAmount))
+	if change.Sign() < 0 {
+		change.SetInt64(0)
+	}
+	r.Paid = paid.FloatString(2)
+	r.Change = change.FloatString(2)
+	return r
+}
+
+// storeReceiptHeader reads the printable store details from stores.metadata.
+// Recognised keys: address, phone, vat_number, cr_number, name_ar, address_ar.
+func storeReceiptHeader(store repository.Store) receipt.Header {
+	h := receipt.Header{Name: store.Name}
+	if len(store.Metadata) == 0 {
+		return h
+	}
+	var meta map[string]interface{}
+	if err := json.Unmarshal(store.Metadata, &meta); err != nil {
+		return h
+	}
+	get := func(key string) string {
+		if v, ok := meta[key]; ok && v != nil {
+			return strings.TrimSpace(fmt.Sprint(v))
+		}
+		return ""
+	}
+	h.NameAr = get("name_ar")
+	h.Address = get("address")
+	h.AddressAr = get("address_ar")
+	h.Phone = get("phone")
+	h.VATNumber = get("vat_number")
+	h.CRNumber = get("cr_number")
+	return h
+}
+
+// formatQuantity prints a quantity without trailing zeros (2.500 -> 2.5).
+func formatQuantity(n pgtype.Numeric) string {
+	s := utils.FormatNumeric(n, 3)
+	if strings.Contains(s, ".") {
+		s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	}
+	return s
+}
+
+func decodeStringList(raw []byte) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var out []string
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil
+	}
+	return out
+}
+
+// ListReceiptTemplates returns the tenant's receipt templates.
+func (uc *PosUseCase) ListReceiptTemplates(ctx context.Context) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	rows, err := uc.repo.ListReceiptTemplates(ctx)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "receipt templates fetched successfully", rows)
+}
+
+// ReceiptTemplateInput is the input for SaveReceiptTemplate.
+type ReceiptTemplateInput struct {
+	Code             string
+	Name             string
+	Language         string
+	PaperWidth       int32
+	HeaderLines      []string
+	FooterLines      []string
+	ShowTaxBreakdown *bool
+	ShowBarcode      *bool
+	CodePage         *int32
+	IsDefault        bool
+	IsActive         *bool
+}
+
+// SaveReceiptTemplate creates or replaces the template with the given code.
+// Marking a template as default clears the flag on every other template.
+func (uc *PosUseCase) SaveReceiptTemplate(ctx context.Context, in *ReceiptTemplateInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	switch receipt.Language(in.Language) {
+	case "":
+		in.Language = string(receipt.LanguageEnglish)
+	case receipt.LanguageEnglish, receipt.LanguageArabic, receipt.LanguageBilingual:
+	default:
+		return utils.NewResponse(utils.CodeBadReq, "invalid language (use en, ar or bilingual)", nil)
+	}
+	if in.PaperWidth == 0 {
+		in.PaperWidth = 42
+	}
+	if in.PaperWidth < 24 || in.PaperWidth > 80 {
+		return utils.NewResponse(utils.CodeBadReq, "paper_width must be between 24 and 80 characters", nil)
+	}
+	header, _ := json.Marshal(nonNilStrings(in.HeaderLines))
+	footer, _ := json.Marshal(nonNilStrings(in.FooterLines))
+
+	arg := repository.UpsertReceiptTemplateParams{
+		Code:             in.Code,
+		Name:             in.Name,
+		Language:         in.Language,
+		PaperWidth:       in.PaperWidth,
+		HeaderLines:      header,
+		FooterLines:      footer,
+		ShowTaxBreakdown: pgtype.Bool{Bool: true, Valid: true},
+		ShowBarcode:      pgtype.Bool{Bool: true, Valid: true},
+		CodePage:         pgtype.Int4{Int32: 50, Valid: true},
+		IsDefault:        pgtype.Bool{Bool: in.IsDefault, Valid: true},
+		IsActive:         pgtype.Bool{Bool: true, Valid: true},
+		Metadata:         []byte("{}"),
+	}
+	if in.ShowTaxBreakdown != nil {
+		arg.ShowTaxBreakdown.Bool = *in.ShowTaxBreakdown
+	}
+	if in.ShowBarcode != nil {
+		arg.ShowBarcode.Bool = *in.ShowBarcode
+	}
+	if in.CodePage != nil {
+		arg.CodePage.Int32 = *in.CodePage
+	}
+	if in.IsActive != nil {
+		arg.IsActive.Bool = *in.IsActive
+	}
+
+	if in.IsDefault {
+		if err := uc.repo.ClearDefaultReceiptTemplate(ctx, in.Code); err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+	}
+	row, err := uc.repo.UpsertReceiptTemplate(ctx, arg)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "receipt template saved", row)
+}
+
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
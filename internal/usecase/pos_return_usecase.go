@@ -0,0 +1,390 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/inventory"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// Return dispositions: restocked goods go back into sellable stock, rma goods
+// are held for the supplier.
+const (
+	ReturnRestock = "restock"
+	ReturnRMA     = "rma"
+)
+
+// PosReturnLine is one item brought back against a sale. LineNumber is the
+// line of the original sale; for serialized products the unit's
+// SerialNumber is required and may be given instead. Quantity defaults to
+// what is left of the line.
+type PosReturnLine struct {
+	LineNumber   int32
+	SerialNumber string
+	Quantity     string
+	Disposition  string
+}
+
+// PosReturnInput is the input for ReturnSale.
+type PosReturnInput struct {
+	// UserID is the signed-in user, who records the return on their
+	// cashier's open session.
+	UserID *int32
+	// CashierID optionally names the user's cashier; see cashierSession.
+	CashierID         int32
+	TransactionNumber string
+	RefundMethod      string
+	Reason            string
+	Lines             []PosReturnLine
+}
+
+// PosReturnedLine is one line of a return.
+type PosReturnedLine struct {
+	LineNumber   int32  `json:"line_number"`
+	ProductID    int32  `json:"product_id"`
+	Quantity     string `json:"quantity"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	BatchNumber  string `json:"batch_number,omitempty"`
+	Disposition  string `json:"disposition"`
+	Amount       string `json:"amount"`
+}
+
+// PosReturnResult is returned by ReturnSale.
+type PosReturnResult struct {
+	TransactionID             int32             `json:"transaction_id"`
+	TransactionNumber         string            `json:"transaction_number"`
+	OriginalTransactionNumber string            `json:"original_transaction_number"`
+	Subtotal                  string            `json:"subtotal"`
+	TaxAmount                 string            `json:"tax_amount"`
+	DiscountAmount            string            `json:"discount_amount"`
+	Refund                    string            `json:"refund"`
+	Lines                     []PosReturnedLine `json:"lines"`
+}
+
+// returnedItem is a validated return line with its share of the original
+// line's amounts.
+type returnedItem struct {
+	orig        repository.PosTransactionLine
+	product     repository.Product
+	qty         *big.Rat
+	disposition string
+	unit        *repository.ProductSerialNumber
+	net         *big.Rat
+	tax         *big.Rat
+	discount    *big.Rat
+	lineTotal   *big.Rat
+}
+
+// ReturnSale records a return against a completed sale on the cashier's open
+// session. Amounts are refunded in proportion to the original lines and
+// quantities already returned cannot be returned again. Serialized units
+// must be scanned and move from sold to returned or rma; restocked goods go
+// back into the store's stock (and batch), rma goods do not.
+func (uc *PosUseCase) ReturnSale(ctx context.Context, in *PosReturnInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if len(in.Lines) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "return has no lines", nil)
+	}
+	session, terminal, _, resp := cashierSession(ctx, uc.repo, in.UserID, in.CashierID)
+	if resp != nil {
+		return resp
+	}
+	sale, err := uc.repo.GetPosTransactionByNumber(ctx, in.TransactionNumber)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "transaction not found", nil)
+	}
+	if saleStore, err := uc.repo.GetStore(ctx, sale.StoreID); err != nil || !inActiveOrganization(ctx, saleStore.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "transaction not found", nil)
+	}
+	if resp := checkStoreAccess(ctx, uc.repo, sale.StoreID); resp != nil {
+		return resp
+	}
+	if sale.TransactionType.String == "return" || sale.Status.String != "completed" {
+		return utils.NewResponse(utils.CodeBadReq, "only completed sales can be returned", nil)
+	}
+	origLines, err := uc.repo.ListPosTransactionLines(ctx, sale.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	invoiceLines, err := uc.repo.GetPosTransactionLinesForInvoice(ctx, sale.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	inclusive := make(map[int32]bool, len(invoiceLines))
+	for _, l := range invoiceLines {
+		inclusive[l.LineNumber] = l.IsInclusive
+	}
+	previous, err := uc.repo.ListReturnedQuantities(ctx, sale.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	returned := make(map[int32]*big.Rat, len(previous))
+	for _, r := range previous {
+		returned[r.OriginalLineID] = utils.NumericToRat(r.Quantity)
+	}
+
+	items := make([]returnedItem, len(in.Lines))
+	for i, l := range in.Lines {
+		item, err := uc.returnedItem(ctx, origLines, returned, inclusive, l)
+		if err != nil {
+			return checkoutError(err)
+		}
+		items[i] = *item
+	}
+
+	now := time.Now()
+	subtotal, tax, discount := new(big.Rat), new(big.Rat), new(big.Rat)
+	for _, it := range items {
+		subtotal.Add(subtotal, it.net)
+		tax.Add(tax, it.tax)
+		discount.Add(discount, it.discount)
+	}
+	total := new(big.Rat).Add(subtotal, tax)
+	method := strings.ToLower(strings.TrimSpace(in.RefundMethod))
+	if method == "" {
+		method = "cash"
+	}
+	result := &PosReturnResult{
+		TransactionNumber:         documentNumber(terminal.TerminalCode+"-R", now),
+		OriginalTransactionNumber: sale.TransactionNumber,
+		Subtotal:                  subtotal.FloatString(2),
+		TaxAmount:                 tax.FloatString(2),
+		DiscountAmount:            discount.FloatString(2),
+		Refund:                    total.FloatString(2),
+	}
+	meta, _ := json.Marshal(map[string]interface{}{
+		"original_transaction_id":     sale.ID,
+		"original_transaction_number": sale.TransactionNumber,
+		"reason":                      strings.TrimSpace(in.Reason),
+		"refund_method":               method,
+	})
+	store := pgtype.Int4{Int32: terminal.StoreID, Valid: true}
+
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		header, err := q.CreatePosTransaction(ctx, repository.CreatePosTransactionParams{
+			TransactionNumber: result.TransactionNumber,
+			StoreID:           terminal.StoreID,
+			PosTerminalID:     terminal.ID,
+			CashierSessionID:  session.ID,
+			CashierID:         session.CashierID,
+			CustomerID:        sale.CustomerID,
+			PriceListID:       sale.PriceListID,
+			TransactionType:   pgtype.Text{String: "return", Valid: true},
+			TransactionDate:   pgtype.Timestamp{Time: now, Valid: true},
+			Subtotal:          utils.RatToNumeric(subtotal, 2),
+			TaxAmount:         utils.RatToNumeric(tax, 2),
+			DiscountAmount:    utils.RatToNumeric(discount, 2),
+			TotalAmount:       utils.RatToNumeric(total, 2),
+			TotalCost:         utils.RatToNumeric(new(big.Rat), 2),
+			Status:            pgtype.Text{String: "completed", Valid: true},
+			Metadata:          meta,
+		})
+		if err != nil {
+			return err
+		}
+		result.TransactionID = header.ID
+
+		for i, it := range items {
+			keys := map[string]interface{}{
+				"original_line_id":     it.orig.ID,
+				"original_line_number": it.orig.LineNumber,
+				"disposition":          it.disposition,
+			}
+			// A return is taxed at the rate of the sale it reverses.
+			for _, k := range lineTaxKeys {
+				if v := metadataValue(it.orig.Metadata, k); v != nil {
+					keys[k] = v
+				}
+			}
+			lineMeta := mergeMetadata(nil, keys)
+			if err := q.CreatePosTransactionLine(ctx, repository.CreatePosTransactionLineParams{
+				TransactionID:    header.ID,
+				LineNumber:       int32(i + 1),
+				ProductID:        it.orig.ProductID,
+				ProductVariantID: it.orig.ProductVariantID,
+				SerialNumber:     it.orig.SerialNumber,
+				BatchNumber:      it.orig.BatchNumber,
+				Quantity:         utils.RatToNumeric(it.qty, 3),
+				UomID:            it.orig.UomID,
+				UnitPrice:        it.orig.UnitPrice,
+				DiscountAmount:   utils.RatToNumeric(it.discount, 2),
+				TaxAmount:        utils.RatToNumeric(it.tax, 2),
+				LineTotal:        utils.RatToNumeric(it.lineTotal, 2),
+				Metadata:         lineMeta,
+			}); err != nil {
+				return err
+			}
+
+			if it.unit != nil {
+				status := inventory.SerialReturned
+				if it.disposition == ReturnRMA {
+					status = inventory.SerialRMA
+				}
+				if err := moveSerial(ctx, q, *it.unit, status, store); err != nil {
+					return err
+				}
+			}
+			if !it.product.TrackInventory.Bool {
+				continue
+			}
+			movementType := "return"
+			if it.disposition == ReturnRMA {
+				movementType = "rma"
+			}
+			if _, err := q.CreateStockMovement(ctx, repository.CreateStockMovementParams{
+				MovementType:     movementType,
+				ReferenceType:    pgtype.Text{String: "pos_transaction", Valid: true},
+				ReferenceID:      pgtype.Int4{Int32: header.ID, Valid: true},
+				ProductID:        it.orig.ProductID,
+				ProductVariantID: it.orig.ProductVariantID,
+				ToStoreID:        store,
+				Quantity:         utils.RatToNumeric(it.qty, 3),
+				UomID:            it.orig.UomID,
+				BatchNumber:      it.orig.BatchNumber,
+				SerialNumber:     it.orig.SerialNumber,
+				MovementDate:     pgtype.Timestamp{Time: now, Valid: true},
+				Status:           pgtype.Text{String: "completed", Valid: true},
+				Metadata:         []byte("{}"),
+			}); err != nil {
+				return err
+			}
+			if it.disposition == ReturnRMA {
+				continue
+			}
+			if err := addStock(ctx, q, it.orig.ProductID, it.orig.ProductVariantID, terminal.StoreID, it.qty); err != nil {
+				return err
+			}
+			if it.product.IsBatchManaged.Bool && it.orig.BatchNumber.String != "" {
+				if _, err := q.ReceiveBatchQuantity(ctx, repository.ReceiveBatchQuantityParams{
+					ProductID:         it.orig.ProductID,
+					ProductVariantID:  it.orig.ProductVariantID,
+					BatchNumber:       it.orig.BatchNumber.String,
+					StoreID:           store,
+					QuantityAvailable: utils.RatToNumeric(it.qty, 3),
+				}); err != nil {
+					return err
+				}
+			}
+		}
+
+		return q.AddPaymentToTransaction(ctx, repository.AddPaymentToTransactionParams{
+			TransactionID: header.ID,
+			PaymentMethod: method,
+			Amount:        utils.RatToNumeric(total, 2),
+			Metadata:      []byte(`{"refund":true}`),
+		})
+	})
+	if err != nil {
+		return checkoutError(err)
+	}
+	for _, it := range items {
+		result.Lines = append(result.Lines, PosReturnedLine{
+			LineNumber:   it.orig.LineNumber,
+			ProductID:    it.orig.ProductID,
+			Quantity:     it.qty.FloatString(3),
+			SerialNumber: it.orig.SerialNumber.String,
+			BatchNumber:  it.orig.BatchNumber.String,
+			Disposition:  it.disposition,
+			Amount:       new(big.Rat).Add(it.net, it.tax).FloatString(2),
+		})
+	}
+	return utils.NewResponse(utils.CodeCreated, "return completed", result)
+}
+
+// returnedItem matches a return line to its original sale line, checks the
+// quantity still returnable and the serial number, and works out its share
+// of the line's amounts. returned is updated with the quantity.
+func (uc *PosUseCase) returnedItem(ctx context.Context, origLines []repository.PosTransactionLine, returned map[int32]*big.Rat, inclusive map[int32]bool, l PosReturnLine) (*returnedItem, error) {
+	serial := strings.TrimSpace(l.SerialNumber)
+	var orig *repository.PosTransactionLine
+	for i := range origLines {
+		o := &origLines[i]
+		if (l.LineNumber != 0 && o.LineNumber == l.LineNumber) ||
+			(l.LineNumber == 0 && serial != "" && o.SerialNumber.String == serial) {
+			orig = o
+			break
+		}
+	}
+	if orig == nil {
+		if l.LineNumber != 0 {
+			return nil, documentInputErrorf("sale has no line %d", l.LineNumber)
+		}
+		return nil, documentInputErrorf("serial number %s was not sold on this transaction", serial)
+	}
+	product, err := uc.repo.GetProduct(ctx, orig.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	disposition := strings.ToLower(strings.TrimSpace(l.Disposition))
+	switch disposition {
+	case "":
+		disposition = ReturnRestock
+	case ReturnRestock, ReturnRMA:
+	default:
+		return nil, documentInputErrorf("invalid disposition %q (use restock or rma)", l.Disposition)
+	}
+
+	sold := utils.NumericToRat(orig.Quantity)
+	done, ok := returned[orig.ID]
+	if !ok {
+		done = new(big.Rat)
+		returned[orig.ID] = done
+	}
+	left := new(big.Rat).Sub(sold, done)
+	qty := left
+	if strings.TrimSpace(l.Quantity) != "" {
+		if qty, err = parseQuantity(product, l.Quantity); err != nil {
+			return nil, err
+		}
+	}
+	if qty.Sign() <= 0 || qty.Cmp(left) > 0 {
+		return nil, documentInputErrorf("line %d: only %s left to return", orig.LineNumber, left.FloatString(3))
+	}
+	done.Add(done, qty)
+
+	item := &returnedItem{orig: *orig, product: product, qty: qty, disposition: disposition}
+	if product.IsSerialized.Bool {
+		if serial == "" {
+			return nil, documentInputErrorf("line %d: serial number is required for product %s", orig.LineNumber, product.Sku)
+		}
+		if serial != orig.SerialNumber.String {
+			return nil, documentInputErrorf("serial number %s was not sold on line %d", serial, orig.LineNumber)
+		}
+		to := inventory.SerialReturned
+		if disposition == ReturnRMA {
+			to = inventory.SerialRMA
+		}
+		units, err := lookupSerials(ctx, uc.repo, product, orig.ProductVariantID, 0, []string{serial}, to)
+		if err != nil {
+			return nil, err
+		}
+		item.unit = &units[0]
+	}
+
+	// Share of the original line; line_total is net, or net plus tax for
+	// inclusive rates.
+	ratio := new(big.Rat).Quo(qty, sold)
+	lineTax := utils.NumericToRat(orig.TaxAmount)
+	lineNet := utils.NumericToRat(orig.LineTotal)
+	if inclusive[orig.LineNumber] {
+		lineNet.Sub(lineNet, lineTax)
+	}
+	item.net = utils.RoundRat(new(big.Rat).Mul(lineNet, ratio), 2)
+	item.tax = utils.RoundRat(new(big.Rat).Mul(lineTax, ratio), 2)
+	item.discount = utils.RoundRat(new(big.Rat).Mul(utils.NumericToRat(orig.DiscountAmount), ratio), 2)
+	item.lineTotal = item.net
+	if inclusive[orig.LineNumber] {
+		item.lineTotal = new(big.Rat).Add(item.net, item.tax)
+	}
+	return item, nil
+}
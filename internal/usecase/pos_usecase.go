@@ -2,22 +2,28 @@ package usecase
 
 import (
 	"context"
-	"math/big"
+	"errors"
 	"strconv"
 	"strings"
+	"time"
 
+	"NEMBUS/internal/pricing"
 	"NEMBUS/internal/repository"
+	"NEMBUS/internal/zatca"
 	"NEMBUS/utils"
 
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
 type PosUseCase struct {
-	repo *repository.Queries
+	repo   *repository.Queries
+	signer *zatca.Signer
 }
 
-func NewPosUseCase() *PosUseCase {
-	return &PosUseCase{}
+// NewPosUseCase creates a POS use case. signer signs the ZATCA invoices
+// issued at checkout and may be nil, see NewZatcaUseCase.
+func NewPosUseCase(signer *zatca.Signer) *PosUseCase {
+	return &PosUseCase{signer: signer}
 }
 
 func (uc *PosUseCase) SetRepository(repo *repository.Queries) {
@@ -25,7 +31,8 @@ func (uc *PosUseCase) SetRepository(repo *repository.Queries) {
 }
 
 // ListProductsForStore returns POS products with stock for a store (categories, prices, barcode).
-func (uc *PosUseCase) ListProductsForStore(ctx context.Context, storeID int32, categoryID *int32, searchTerm *string, includeOutOfStock bool) *repository.Response {
+// Prices are resolved for the store and, when given, the customer.
+func (uc *PosUseCase) ListProductsForStore(ctx context.Context, storeID int32, customerID *int32, categoryID *int32, searchTerm *string, includeOutOfStock bool) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
@@ -33,6 +40,9 @@ func (uc *PosUseCase) ListProductsForStore(ctx context.Context, storeID int32, c
 	if err != nil {
 		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
 	}
+	if resp := checkStoreInScope(ctx, uc.repo, PermissionViewStock, storeID); resp != nil {
+		return resp
+	}
 	arg := repository.PosGetProductsWithStockParams{
 		StoreID:           storeID,
 		IncludeOutOfStock: includeOutOfStock,
@@ -47,10 +57,31 @@ func (uc *PosUseCase) ListProductsForStore(ctx context.Context, storeID int32, c
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
+	ids := make([]int32, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ProductID
+	}
+	prices, err := uc.listingPrices(ctx, storeID, customerID, ids)
+	if err != nil {
+		var bad *documentInputError
+		if errors.As(err, &bad) {
+			return utils.NewResponse(utils.CodeNotFound, bad.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	for i := range rows {
+		if p := prices[rows[i].ProductID]; p != nil {
+			rows[i].EffectivePrice, rows[i].PromoPrice, rows[i].HasPromotion = listingPrice(p)
+			if p.Price == nil {
+				rows[i].RetailPrice = pgtype.Numeric{}
+			}
+		}
+	}
 	return utils.NewResponse(utils.CodeOK, "products fetched successfully", rows)
 }
 
-// SearchProduct searches by barcode (exact), id (exact), or name/sku (fuzzy).
+// SearchProduct searches by barcode (exact), scale or GS1 barcode, id
+// (exact), or name/sku (fuzzy).
 func (uc *PosUseCase) SearchProduct(ctx context.Context, storeID int32, q string, limit int32) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
@@ -63,6 +94,9 @@ func (uc *PosUseCase) SearchProduct(ctx context.Context, storeID int32, q string
 	if err != nil {
 		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
 	}
+	if resp := checkStoreInScope(ctx, uc.repo, PermissionViewStock, storeID); resp != nil {
+		return resp
+	}
 
 	// 1. Exact barcode
 	byBarcode, err := uc.repo.PosGetProductByBarcode(ctx, q, storeID)
@@ -70,7 +104,12 @@ func (uc *PosUseCase) SearchProduct(ctx context.Context, storeID int32, q string
 		return utils.NewResponse(utils.CodeOK, "product found by barcode", byBarcode)
 	}
 
-	// 2. Numeric-only: try as product id
+	// 2. Scale (variable-measure) and GS1 barcodes, and other GTIN forms
+	if resp := uc.scanBarcode(ctx, storeID, q); resp != nil {
+		return resp
+	}
+
+	// 3. Numeric-only: try as product id
 	if isNumericID(q) {
 		id, _ := strconv.ParseInt(q, 10, 32)
 		pid := int32(id)
@@ -101,7 +140,7 @@ func (uc *PosUseCase) SearchProduct(ctx context.Context, storeID int32, q string
 		}
 	}
 
-	// 3. Name/sku fuzzy search
+	// 4. Name/sku fuzzy search
 	if limit <= 0 {
 		limit = 50
 	}
@@ -129,8 +168,59 @@ func isPositiveNumeric(n pgtype.Numeric) bool {
 	return n.Int != nil && n.Int.Sign() > 0
 }
 
+// listingPrices resolves the unit price of listed products for a store and
+// optional customer. Products checkout could not price map to a result
+// without a price, so the listing shows none instead of the listing query's
+// RETAIL_SAR fallback.
+func (uc *PosUseCase) listingPrices(ctx context.Context, storeID int32, customerID *int32, productIDs []int32) (map[int32]*pricing.Result, error) {
+	sc := saleContext{storeID: storeID, date: time.Now()}
+	if customerID != nil {
+		c, err := uc.repo.GetCustomer(ctx, *customerID)
+		if err != nil {
+			return nil, documentInputErrorf("customer %d not found", *customerID)
+		}
+		sc.customer = &c
+	}
+	r, err := newPriceResolver(ctx, uc.repo, sc)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.load(ctx, productIDs...); err != nil {
+		return nil, err
+	}
+	products, err := uc.repo.ListProductsByIDs(ctx, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int32]*pricing.Result, len(products))
+	for _, p := range products {
+		res, err := r.resolveListing(p, 0)
+		if err != nil && !errors.Is(err, pricing.ErrNoPrice) {
+			return nil, err
+		}
+		out[p.ID] = res
+	}
+	return out, nil
+}
+
+// listingPrice returns the effective price, promotion price and promotion
+// flag a listing shows for a resolved price; all are empty when there is no
+// price.
+func listingPrice(p *pricing.Result) (effective, promo pgtype.Numeric, promoted pgtype.Bool) {
+	if p.Price == nil {
+		return effective, promo, promoted
+	}
+	effective = utils.RatToNumeric(p.Price, 2)
+	promoted = pgtype.Bool{Bool: p.Source == pricing.SourcePromotion, Valid: true}
+	if promoted.Bool {
+		promo = effective
+	}
+	return effective, promo, promoted
+}
+
 // GetProductsByCategory returns products in a category (and optionally subcategories) for a store.
-func (uc *PosUseCase) GetProductsByCategory(ctx context.Context, storeID int32, categoryID int32, includeSubcategories bool) *repository.Response {
+// Prices are resolved for the store and, when given, the customer.
+func (uc *PosUseCase) GetProductsByCategory(ctx context.Context, storeID int32, customerID *int32, categoryID int32, includeSubcategories bool) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
@@ -138,6 +228,9 @@ func (uc *PosUseCase) GetProductsByCategory(ctx context.Context, storeID int32,
 	if err != nil {
 		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
 	}
+	if resp := checkStoreInScope(ctx, uc.repo, PermissionViewStock, storeID); resp != nil {
+		return resp
+	}
 	arg := repository.PosGetProductsByCategoryParams{
 		CategoryID:           categoryID,
 		StoreID:              storeID,
@@ -147,6 +240,23 @@ func (uc *PosUseCase) GetProductsByCategory(ctx context.Context, storeID int32,
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
+	ids := make([]int32, len(rows))
+	for i, row := range rows {
+		ids[i] = row.ProductID
+	}
+	prices, err := uc.listingPrices(ctx, storeID, customerID, ids)
+	if err != nil {
+		var bad *documentInputError
+		if errors.As(err, &bad) {
+			return utils.NewResponse(utils.CodeNotFound, bad.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	for i := range rows {
+		if p := prices[rows[i].ProductID]; p != nil {
+			rows[i].EffectivePrice, rows[i].PromoPrice, rows[i].HasPromotion = listingPrice(p)
+		}
+	}
 	return utils.NewResponse(utils.CodeOK, "products by category fetched successfully", rows)
 }
 
@@ -184,141 +294,55 @@ type PosAddProductInput struct {
 	RetailPrice          *string
 }
 
-// AddProduct creates a product and optionally barcode + retail price.
+// AddProduct creates a product with an optional barcode and retail price in
+// one transaction, so a bad barcode or price leaves no half-created product.
+// The price goes to the RETAIL_SAR list when it exists, else the default list.
 func (uc *PosUseCase) AddProduct(ctx context.Context, in *PosAddProductInput) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
-	params := posAddProductToCreateParams(in)
-	prod, err := uc.repo.CreateProduct(ctx, params)
-	if err != nil {
-		return utils.NewResponse(utils.CodeError, err.Error(), nil)
-	}
-	if in.Barcode != nil && *in.Barcode != "" {
-		exist, _ := uc.repo.CheckBarcodeExists(ctx, *in.Barcode)
-		if !exist {
-			_, _ = uc.repo.CreateProductBarcode(ctx, repository.CreateProductBarcodeParams{
-				ProductID:        prod.ID,
-				ProductVariantID: pgtype.Int4{},
-				Barcode:          *in.Barcode,
-				BarcodeType:      pgtype.Text{},
-				IsPrimary:        pgtype.Bool{Bool: true, Valid: true},
-				Metadata:         nil,
-			})
-		}
-	}
-	if in.RetailPrice != nil && *in.RetailPrice != "" {
-		pl, err := uc.repo.GetPriceListByCode(ctx, "RETAIL_SAR")
-		if err == nil {
-			price, err := uc.repo.ParseNumeric(ctx, strings.TrimSpace(*in.RetailPrice))
-			if err == nil {
-				minQty, err2 := uc.repo.ParseNumeric(ctx, "1")
-				if err2 != nil {
-					minQty = pgtype.Numeric{Int: big.NewInt(1), Exp: 0}
-				}
-				uomID := pgtype.Int4{}
-				if prod.BaseUomID.Valid {
-					uomID = prod.BaseUomID
-				}
-				_, _ = uc.repo.CreateProductPrice(ctx, repository.CreateProductPriceParams{
-					ProductID:        prod.ID,
-					ProductVariantID: pgtype.Int4{},
-					PriceListID:      pl.ID,
-					UomID:            uomID,
-					Price:            price,
-					MinQuantity:      minQty,
-					MaxQuantity:      pgtype.Numeric{},
-					ValidFrom:        pgtype.Date{},
-					ValidTo:          pgtype.Date{},
-					IsActive:         pgtype.Bool{Bool: true, Valid: true},
-					Metadata:         nil,
-				})
+	orgID, resp := activeOrganization(ctx, in.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	create := &CreateProductInput{
+		Product: ProductInput{
+			OrganizationID:       orgID,
+			SKU:                  in.SKU,
+			Name:                 in.Name,
+			Description:          in.Description,
+			CategoryID:           in.CategoryID,
+			BrandID:              in.BrandID,
+			BaseUomID:            in.BaseUomID,
+			ProductType:          in.ProductType,
+			TaxCategoryID:        in.TaxCategoryID,
+			IsSerialized:         in.IsSerialized,
+			IsBatchManaged:       in.IsBatchManaged,
+			IsActive:             in.IsActive,
+			IsSellable:           in.IsSellable,
+			IsPurchasable:        in.IsPurchasable,
+			AllowDecimalQuantity: in.AllowDecimalQuantity,
+			TrackInventory:       in.TrackInventory,
+		},
+	}
+	if in.Barcode != nil && strings.TrimSpace(*in.Barcode) != "" {
+		create.Barcodes = []BarcodeInput{{Barcode: *in.Barcode, IsPrimary: true}}
+	}
+	var detail *ProductDetail
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		if in.RetailPrice != nil && strings.TrimSpace(*in.RetailPrice) != "" {
+			price := CatalogPriceInput{Price: *in.RetailPrice}
+			if pl, err := q.GetPriceListByCode(ctx, "RETAIL_SAR"); err == nil {
+				price.PriceListID = &pl.ID
 			}
+			create.Prices = []CatalogPriceInput{price}
 		}
-	}
-	return utils.NewResponse(utils.CodeCreated, "product created", prod)
-}
-
-func parseNumericFromString(s string) (pgtype.Numeric, error) {
-	var n pgtype.Numeric
-	// Use a query to parse decimal string; pgx sends string and pg receives ::numeric.
-	// Alternatively we could use strconv.ParseFloat + manual build. Simple alternative:
-	// run "SELECT $1::numeric" and scan into n. Use repo – but we don't have repo in helper.
-	// Use global DB? No. Use strconv.ParseFloat then set pgtype.Numeric.
-	// pgtype.Numeric has Int (Int8) and Exp. We use crude conversion: * 100 for 2 decimals, etc.
-	_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+		var err error
+		detail, err = createCatalogProduct(ctx, q, create)
+		return err
+	})
 	if err != nil {
-		return n, err
-	}
-	// pgtype.Numeric from float: use Exp scaling. Simplest: Valid=true, Int=scaled int, Exp=-2.
-	// pgtype.Numeric typically uses big.Int internally. Check pgtype source.
-	// We'll use作業率 use db wire format: many impls use "SELECT $1::numeric" withParser.
-	// Skip price set if parse fails; we already return. So we need a working parse.
-	// Use repository abstraction: add PosParseNumeric(s string) (pgtype.Numeric, error) that
-	// runs "SELECT $1::numeric" on repo. But that requires ctx and Queries.
-	// Simpler: keep retail price optional. If we can't parse, skip CreateProductPrice.
-	// Implement minimal parse:支撑 2 decimal places, thousands<｜tool▁calls▁end｜>
-	return n, err
-}
-
-func posAddProductToCreateParams(in *PosAddProductInput) repository.CreateProductParams {
-	params := repository.CreateProductParams{
-		OrganizationID:       in.OrganizationID,
-		Sku:                  in.SKU,
-		Name:                 in.Name,
-		Description:          pgtype.Text{},
-		CategoryID:           pgtype.Int4{},
-		BrandID:              pgtype.Int4{},
-		BaseUomID:            pgtype.Int4{},
-		ProductType:          pgtype.Text{},
-		TaxCategoryID:        pgtype.Int4{},
-		IsSerialized:         pgtype.Bool{Bool: false, Valid: true},
-		IsBatchManaged:       pgtype.Bool{Bool: false, Valid: true},
-		IsActive:             pgtype.Bool{Bool: true, Valid: true},
-		IsSellable:           pgtype.Bool{Bool: true, Valid: true},
-		IsPurchasable:        pgtype.Bool{Bool: false, Valid: true},
-		AllowDecimalQuantity: pgtype.Bool{Bool: false, Valid: true},
-		TrackInventory:       pgtype.Bool{Bool: true, Valid: true},
-		Metadata:             nil,
-	}
-	if in.Description != nil {
-		params.Description = pgtype.Text{String: *in.Description, Valid: true}
-	}
-	if in.CategoryID != nil {
-		params.CategoryID = pgtype.Int4{Int32: *in.CategoryID, Valid: true}
-	}
-	if in.BrandID != nil {
-		params.BrandID = pgtype.Int4{Int32: *in.BrandID, Valid: true}
-	}
-	if in.BaseUomID != nil {
-		params.BaseUomID = pgtype.Int4{Int32: *in.BaseUomID, Valid: true}
-	}
-	if in.ProductType != nil {
-		params.ProductType = pgtype.Text{String: *in.ProductType, Valid: true}
-	}
-	if in.TaxCategoryID != nil {
-		params.TaxCategoryID = pgtype.Int4{Int32: *in.TaxCategoryID, Valid: true}
-	}
-	if in.IsSerialized != nil {
-		params.IsSerialized = pgtype.Bool{Bool: *in.IsSerialized, Valid: true}
-	}
-	if in.IsBatchManaged != nil {
-		params.IsBatchManaged = pgtype.Bool{Bool: *in.IsBatchManaged, Valid: true}
-	}
-	if in.IsActive != nil {
-		params.IsActive = pgtype.Bool{Bool: *in.IsActive, Valid: true}
-	}
-	if in.IsSellable != nil {
-		params.IsSellable = pgtype.Bool{Bool: *in.IsSellable, Valid: true}
-	}
-	if in.IsPurchasable != nil {
-		params.IsPurchasable = pgtype.Bool{Bool: *in.IsPurchasable, Valid: true}
-	}
-	if in.AllowDecimalQuantity != nil {
-		params.AllowDecimalQuantity = pgtype.Bool{Bool: *in.AllowDecimalQuantity, Valid: true}
-	}
-	if in.TrackInventory != nil {
-		params.TrackInventory = pgtype.Bool{Bool: *in.TrackInventory, Valid: true}
-	}
-	return params
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "product created", detail.Product)
 }
@@ -0,0 +1,833 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/pricing"
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/spreadsheet"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PriceListUseCase manages price lists and their product prices.
+type PriceListUseCase struct {
+	repo *repository.Queries
+}
+
+// NewPriceListUseCase creates a new price list use case.
+func NewPriceListUseCase() *PriceListUseCase {
+	return &PriceListUseCase{}
+}
+
+// SetRepository injects repository per request
+func (uc *PriceListUseCase) SetRepository(repo *repository.Queries) {
+	uc.repo = repo
+}
+
+// PriceListInput is the input for CreatePriceList and UpdatePriceList.
+type PriceListInput struct {
+	Name          string
+	Code          string
+	PriceListType string
+	CurrencyCode  string
+	ValidFrom     *time.Time
+	ValidTo       *time.Time
+	IsDefault     bool
+	IsActive      *bool
+}
+
+// ListPriceLists returns price lists; activeOnly keeps active lists and
+// validOnly keeps lists that are active and valid today.
+func (uc *PriceListUseCase) ListPriceLists(ctx context.Context, activeOnly, validOnly bool) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	var (
+		lists []repository.PriceList
+		err   error
+	)
+	switch {
+	case validOnly:
+		lists, err = uc.repo.ListValidPriceLists(ctx)
+	case activeOnly:
+		lists, err = uc.repo.ListActivePriceLists(ctx)
+	default:
+		lists, err = uc.repo.ListPriceLists(ctx)
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "price lists fetched successfully", lists)
+}
+
+// GetPriceList returns a price list.
+func (uc *PriceListUseCase) GetPriceList(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	pl, err := uc.repo.GetPriceList(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "price list fetched successfully", pl)
+}
+
+// CreatePriceList creates a price list. A new default list replaces the
+// previous default.
+func (uc *PriceListUseCase) CreatePriceList(ctx context.Context, in *PriceListInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	in.Code = strings.TrimSpace(in.Code)
+	if strings.TrimSpace(in.Name) == "" || in.Code == "" {
+		return utils.NewResponse(utils.CodeBadReq, "name and code are required", nil)
+	}
+	if msg := checkValidity(in.ValidFrom, in.ValidTo); msg != "" {
+		return utils.NewResponse(utils.CodeBadReq, msg, nil)
+	}
+	if _, err := uc.repo.GetPriceListByCode(ctx, in.Code); err == nil {
+		return utils.NewResponse(utils.CodeBadReq, "price list code already exists", nil)
+	}
+	active := true
+	if in.IsActive != nil {
+		active = *in.IsActive
+	}
+
+	var pl repository.PriceList
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		pl, err = q.CreatePriceList(ctx, repository.CreatePriceListParams{
+			Name:          strings.TrimSpace(in.Name),
+			Code:          in.Code,
+			PriceListType: optionalText(in.PriceListType),
+			CurrencyCode:  optionalText(in.CurrencyCode),
+			ValidFrom:     optionalDate(in.ValidFrom),
+			ValidTo:       optionalDate(in.ValidTo),
+			IsDefault:     pgtype.Bool{Bool: in.IsDefault, Valid: true},
+			IsActive:      pgtype.Bool{Bool: active, Valid: true},
+			Metadata:      []byte("{}"),
+		})
+		if err != nil || !in.IsDefault {
+			return err
+		}
+		return q.SetDefaultPriceList(ctx, pl.ID)
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeCreated, "price list created", pl)
+}
+
+// UpdatePriceList updates a price list; the code cannot change.
+func (uc *PriceListUseCase) UpdatePriceList(ctx context.Context, id int32, in *PriceListInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	current, err := uc.repo.GetPriceList(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	if strings.TrimSpace(in.Name) == "" {
+		return utils.NewResponse(utils.CodeBadReq, "name is required", nil)
+	}
+	if msg := checkValidity(in.ValidFrom, in.ValidTo); msg != "" {
+		return utils.NewResponse(utils.CodeBadReq, msg, nil)
+	}
+	active := current.IsActive
+	if in.IsActive != nil {
+		active = pgtype.Bool{Bool: *in.IsActive, Valid: true}
+	}
+
+	var pl repository.PriceList
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		pl, err = q.UpdatePriceList(ctx, repository.UpdatePriceListParams{
+			ID:            id,
+			Name:          strings.TrimSpace(in.Name),
+			PriceListType: optionalText(in.PriceListType),
+			CurrencyCode:  optionalText(in.CurrencyCode),
+			ValidFrom:     optionalDate(in.ValidFrom),
+			ValidTo:       optionalDate(in.ValidTo),
+			IsDefault:     pgtype.Bool{Bool: in.IsDefault, Valid: true},
+			IsActive:      active,
+			Metadata:      current.Metadata,
+		})
+		if err != nil || !in.IsDefault {
+			return err
+		}
+		return q.SetDefaultPriceList(ctx, id)
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "price list updated", pl)
+}
+
+// DeletePriceList deletes a price list and its prices.
+func (uc *PriceListUseCase) DeletePriceList(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetPriceList(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	if err := uc.repo.DeletePriceList(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "price list deleted", nil)
+}
+
+// SetDefaultPriceList makes a price list the tenant's default.
+func (uc *PriceListUseCase) SetDefaultPriceList(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	pl, err := uc.repo.GetPriceList(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	if !pl.IsActive.Bool {
+		return utils.NewResponse(utils.CodeBadReq, "an inactive price list cannot be the default", nil)
+	}
+	if err := uc.repo.SetDefaultPriceList(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "default price list set", nil)
+}
+
+// ExpirePriceList ends every running price of a list yesterday.
+func (uc *PriceListUseCase) ExpirePriceList(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetPriceList(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	if err := uc.repo.ExpirePrices(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "price list prices expired", nil)
+}
+
+// ListPrices returns the active prices of a price list, including scheduled
+// and expired versions.
+func (uc *PriceListUseCase) ListPrices(ctx context.Context, priceListID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	rows, err := uc.repo.ListPricesByPriceList(ctx, priceListID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "prices fetched successfully", rows)
+}
+
+// GetPriceComparison returns a product's prices across active price lists.
+func (uc *PriceListUseCase) GetPriceComparison(ctx context.Context, productID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetProduct(ctx, productID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	rows, err := uc.repo.GetPriceComparison(ctx, productID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "price comparison fetched successfully", rows)
+}
+
+// ProductPriceInput is one price of a product in a price list.
+type ProductPriceInput struct {
+	ProductID        int32
+	ProductVariantID *int32
+	UomID            *int32
+	Price            string
+	MinQuantity      string
+	MaxQuantity      string
+	ValidFrom        *time.Time
+	ValidTo          *time.Time
+}
+
+// priceVersion is a validated price ready to be written.
+type priceVersion struct {
+	priceListID int32
+	productID   int32
+	variantID   pgtype.Int4
+	uomID       pgtype.Int4
+	price       *big.Rat
+	minQuantity *big.Rat
+	maxQuantity *big.Rat
+	validFrom   *time.Time
+	validTo     *time.Time
+	metadata    []byte
+}
+
+// CreateProductPrice adds a price row to a price list as given, without
+// touching other versions of the same price.
+func (uc *PriceListUseCase) CreateProductPrice(ctx context.Context, priceListID int32, in *ProductPriceInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	v, resp := uc.productPriceVersion(ctx, priceListID, in)
+	if resp != nil {
+		return resp
+	}
+	p, err := uc.repo.CreateProductPrice(ctx, v.createParams(v.validTo))
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeCreated, "product price created", p)
+}
+
+// SchedulePriceChange sets a product's price from a date (today when not
+// given). Versions of the same price still running then end the day
+// before, a version starting that same day is repriced, and the new
+// version ends before any later scheduled version. Future changes take
+// effect automatically on their date.
+func (uc *PriceListUseCase) SchedulePriceChange(ctx context.Context, priceListID int32, in *ProductPriceInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	v, resp := uc.productPriceVersion(ctx, priceListID, in)
+	if resp != nil {
+		return resp
+	}
+	var p repository.ProductPrice
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		p, err = applyPriceVersion(ctx, q, v)
+		return err
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeCreated, "price change scheduled", p)
+}
+
+// UpdateProductPrice changes fields of one price row; empty fields are kept.
+func (uc *PriceListUseCase) UpdateProductPrice(ctx context.Context, id int32, in *ProductPriceInput, isActive *bool) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	current, err := uc.repo.GetProductPrice(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "product price not found", nil)
+	}
+	arg := repository.UpdateProductPriceParams{ID: id, ValidFrom: optionalDate(in.ValidFrom), ValidTo: optionalDate(in.ValidTo)}
+	for _, f := range []struct {
+		name string
+		s    string
+		dst  *pgtype.Numeric
+	}{
+		{"price", in.Price, &arg.Price},
+		{"min_quantity", in.MinQuantity, &arg.MinQuantity},
+		{"max_quantity", in.MaxQuantity, &arg.MaxQuantity},
+	} {
+		if f.s == "" {
+			continue
+		}
+		r, err := parseAmount(f.name, f.s)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		*f.dst = utils.RatToNumeric(r, 4)
+	}
+	from, to := in.ValidFrom, in.ValidTo
+	if from == nil && current.ValidFrom.Valid {
+		from = &current.ValidFrom.Time
+	}
+	if to == nil && current.ValidTo.Valid {
+		to = &current.ValidTo.Time
+	}
+	if msg := checkValidity(from, to); msg != "" {
+		return utils.NewResponse(utils.CodeBadReq, msg, nil)
+	}
+	if isActive != nil {
+		arg.IsActive = pgtype.Bool{Bool: *isActive, Valid: true}
+	}
+	p, err := uc.repo.UpdateProductPrice(ctx, arg)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "product price updated", p)
+}
+
+// DeleteProductPrice deletes one price row.
+func (uc *PriceListUseCase) DeleteProductPrice(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetProductPrice(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "product price not found", nil)
+	}
+	if err := uc.repo.DeleteProductPrice(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "product price deleted", nil)
+}
+
+// productPriceVersion validates in against the price list, product,
+// variant and unit.
+func (uc *PriceListUseCase) productPriceVersion(ctx context.Context, priceListID int32, in *ProductPriceInput) (*priceVersion, *repository.Response) {
+	if _, err := uc.repo.GetPriceList(ctx, priceListID); err != nil {
+		return nil, utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	product, err := uc.repo.GetProduct(ctx, in.ProductID)
+	if err != nil {
+		return nil, utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	v := &priceVersion{
+		priceListID: priceListID,
+		productID:   product.ID,
+		validFrom:   in.ValidFrom,
+		validTo:     in.ValidTo,
+		metadata:    []byte("{}"),
+	}
+	if in.ProductVariantID != nil {
+		variant, err := uc.repo.GetProductVariant(ctx, *in.ProductVariantID)
+		if err != nil || variant.ProductID != product.ID {
+			return nil, utils.NewResponse(utils.CodeBadReq, "variant does not belong to product "+product.Sku, nil)
+		}
+		v.variantID = pgtype.Int4{Int32: variant.ID, Valid: true}
+	}
+	if in.UomID != nil {
+		if _, err := uc.repo.GetUnitOfMeasure(ctx, *in.UomID); err != nil {
+			return nil, utils.NewResponse(utils.CodeNotFound, "unit of measure not found", nil)
+		}
+		v.uomID = pgtype.Int4{Int32: *in.UomID, Valid: true}
+	}
+	if msg := v.setAmounts(in.Price, in.MinQuantity, in.MaxQuantity); msg != "" {
+		return nil, utils.NewResponse(utils.CodeBadReq, msg, nil)
+	}
+	if msg := checkValidity(in.ValidFrom, in.ValidTo); msg != "" {
+		return nil, utils.NewResponse(utils.CodeBadReq, msg, nil)
+	}
+	return v, nil
+}
+
+// setAmounts parses the price and quantity tier; it returns a message for
+// the caller on bad input.
+func (v *priceVersion) setAmounts(price, minQty, maxQty string) string {
+	if strings.TrimSpace(price) == "" {
+		return "price is required"
+	}
+	var err error
+	if v.price, err = parseAmount("price", price); err != nil {
+		return err.Error()
+	}
+	v.minQuantity = big.NewRat(1, 1)
+	if strings.TrimSpace(minQty) != "" {
+		if v.minQuantity, err = parseAmount("min_quantity", minQty); err != nil {
+			return err.Error()
+		}
+	}
+	if strings.TrimSpace(maxQty) != "" {
+		if v.maxQuantity, err = parseAmount("max_quantity", maxQty); err != nil {
+			return err.Error()
+		}
+		if v.maxQuantity.Cmp(v.minQuantity) < 0 {
+			return "max_quantity is below min_quantity"
+		}
+	}
+	return ""
+}
+
+func (v *priceVersion) createParams(validTo *time.Time) repository.CreateProductPriceParams {
+	arg := repository.CreateProductPriceParams{
+		ProductID:        v.productID,
+		ProductVariantID: v.variantID,
+		PriceListID:      v.priceListID,
+		UomID:            v.uomID,
+		Price:            utils.RatToNumeric(v.price, 2),
+		MinQuantity:      utils.RatToNumeric(v.minQuantity, 3),
+		ValidFrom:        optionalDate(v.validFrom),
+		ValidTo:          optionalDate(validTo),
+		IsActive:         pgtype.Bool{Bool: true, Valid: true},
+		Metadata:         v.metadata,
+	}
+	if v.maxQuantity != nil {
+		arg.MaxQuantity = utils.RatToNumeric(v.maxQuantity, 3)
+	}
+	return arg
+}
+
+// applyPriceVersion writes v as a dated change of its price (see
+// SchedulePriceChange).
+func applyPriceVersion(ctx context.Context, q *repository.Queries, v *priceVersion) (repository.ProductPrice, error) {
+	from := time.Now()
+	if v.validFrom != nil {
+		from = *v.validFrom
+	}
+	from = time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	v.validFrom = &from
+
+	rows, err := q.ListPriceVersions(ctx, repository.ListPriceVersionsParams{
+		PriceListID:      v.priceListID,
+		ProductID:        v.productID,
+		ProductVariantID: v.variantID,
+		UomID:            v.uomID,
+		MinQuantity:      utils.RatToNumeric(v.minQuantity, 3),
+	})
+	if err != nil {
+		return repository.ProductPrice{}, err
+	}
+	versions := make([]pricing.Price, len(rows))
+	for i, row := range rows {
+		versions[i] = pricingPrice(row)
+	}
+	ch := pricing.PlanChange(versions, from)
+
+	for _, id := range ch.Close {
+		if _, err := q.UpdateProductPrice(ctx, repository.UpdateProductPriceParams{
+			ID:      id,
+			ValidTo: pgtype.Date{Time: ch.CloseOn, Valid: true},
+		}); err != nil {
+			return repository.ProductPrice{}, err
+		}
+	}
+	validTo := v.validTo
+	if ch.ValidTo != nil && (validTo == nil || ch.ValidTo.Before(*validTo)) {
+		validTo = ch.ValidTo
+	}
+	if ch.Replace != 0 {
+		arg := repository.UpdateProductPriceParams{
+			ID:       ch.Replace,
+			Price:    utils.RatToNumeric(v.price, 2),
+			ValidTo:  optionalDate(validTo),
+			Metadata: v.metadata,
+		}
+		if v.maxQuantity != nil {
+			arg.MaxQuantity = utils.RatToNumeric(v.maxQuantity, 3)
+		}
+		return q.UpdateProductPrice(ctx, arg)
+	}
+	return q.CreateProductPrice(ctx, v.createParams(validTo))
+}
+
+// PriceAdjustmentInput is the input for AdjustPrices.
+type PriceAdjustmentInput struct {
+	// Mode is "percent" or "fixed"; Amount may be negative.
+	Mode          string
+	Amount        string
+	CategoryID    *int32
+	BrandID       *int32
+	EffectiveFrom *time.Time
+}
+
+// PriceAdjustmentResult reports a bulk adjustment.
+type PriceAdjustmentResult struct {
+	Adjusted      int64  `json:"adjusted"`
+	EffectiveFrom string `json:"effective_from"`
+	Scheduled     bool   `json:"scheduled"`
+}
+
+// AdjustPrices raises or lowers the prices of a list, optionally only for
+// a category or brand. Without a future effective date the prices running
+// today are changed in place and versions scheduled for later keep theirs;
+// with one, a new version of each price running on that date is scheduled.
+func (uc *PriceListUseCase) AdjustPrices(ctx context.Context, priceListID int32, in *PriceAdjustmentInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetPriceList(ctx, priceListID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	mode := strings.ToLower(strings.TrimSpace(in.Mode))
+	if mode != "percent" && mode != "fixed" {
+		return utils.NewResponse(utils.CodeBadReq, "mode must be percent or fixed", nil)
+	}
+	amount, ok := new(big.Rat).SetString(strings.TrimSpace(in.Amount))
+	if !ok || amount.Sign() == 0 {
+		return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("invalid amount %q", in.Amount), nil)
+	}
+	if mode == "percent" && amount.Cmp(big.NewRat(-100, 1)) < 0 {
+		return utils.NewResponse(utils.CodeBadReq, "a percentage cut cannot exceed 100", nil)
+	}
+	if in.CategoryID != nil {
+		if _, err := uc.repo.GetProductCategory(ctx, *in.CategoryID); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "category not found", nil)
+		}
+	}
+	if in.BrandID != nil {
+		if _, err := uc.repo.GetBrand(ctx, *in.BrandID); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "brand not found", nil)
+		}
+	}
+
+	today := time.Now()
+	result := &PriceAdjustmentResult{EffectiveFrom: today.Format("2006-01-02")}
+	if in.EffectiveFrom == nil || !in.EffectiveFrom.After(today) {
+		n, err := uc.repo.BulkUpdatePrices(ctx, repository.BulkUpdatePricesParams{
+			Mode:        mode,
+			Amount:      utils.RatToNumeric(amount, 4),
+			PriceListID: priceListID,
+			CategoryID:  optionalInt4(in.CategoryID),
+			BrandID:     optionalInt4(in.BrandID),
+		})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		result.Adjusted = n
+		return utils.NewResponse(utils.CodeOK, "prices adjusted", result)
+	}
+
+	from := *in.EffectiveFrom
+	result.EffectiveFrom = from.Format("2006-01-02")
+	result.Scheduled = true
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		rows, err := q.ListPricesForAdjustment(ctx, repository.ListPricesForAdjustmentParams{
+			PriceListID: priceListID,
+			OnDate:      pgtype.Date{Time: from, Valid: true},
+			CategoryID:  optionalInt4(in.CategoryID),
+			BrandID:     optionalInt4(in.BrandID),
+		})
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			p := pricingPrice(row)
+			v := &priceVersion{
+				priceListID: row.PriceListID,
+				productID:   row.ProductID,
+				variantID:   row.ProductVariantID,
+				uomID:       row.UomID,
+				price:       pricing.Adjust(p.Price, amount, mode == "percent", 2),
+				minQuantity: p.MinQuantity,
+				maxQuantity: p.MaxQuantity,
+				validFrom:   &from,
+				validTo:     p.ValidTo,
+				metadata:    row.Metadata,
+			}
+			if v.minQuantity == nil {
+				v.minQuantity = big.NewRat(1, 1)
+			}
+			if _, err := applyPriceVersion(ctx, q, v); err != nil {
+				return err
+			}
+			result.Adjusted++
+		}
+		return nil
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "price adjustment scheduled", result)
+}
+
+// PriceImportOptions control ImportPrices.
+type PriceImportOptions struct {
+	OrganizationID int32
+	// DryRun validates the file without writing anything.
+	DryRun bool
+	// SkipInvalid imports the valid rows of a file that has errors;
+	// otherwise any error rejects the whole file.
+	SkipInvalid bool
+}
+
+// ImportRowError is a problem with one row of an imported file. Row is the
+// 1-based line in the file, header included.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Column  string `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// PriceImportResult reports an import.
+type PriceImportResult struct {
+	Rows     int              `json:"rows"`
+	Imported int              `json:"imported"`
+	Skipped  int              `json:"skipped"`
+	DryRun   bool             `json:"dry_run"`
+	Errors   []ImportRowError `json:"errors"`
+}
+
+// priceImportColumns are the columns of a price import file; sku and price
+// are required.
+var priceImportColumns = []string{"sku", "variant_sku", "uom", "price", "min_quantity", "max_quantity", "valid_from", "valid_to"}
+
+// ImportPrices imports prices into a list from a CSV or XLSX file with a
+// header row (sku, variant_sku, uom, price, min_quantity, max_quantity,
+// valid_from, valid_to). Products are matched by SKU within the
+// organization and units by code. Each row is applied as a dated price
+// change (see SchedulePriceChange), so rows with a future valid_from
+// activate on that date.
+func (uc *PriceListUseCase) ImportPrices(ctx context.Context, priceListID int32, fileName string, file io.Reader, opt PriceImportOptions) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if _, err := uc.repo.GetPriceList(ctx, priceListID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	orgID, resp := activeOrganization(ctx, opt.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	opt.OrganizationID = orgID
+	if _, err := uc.repo.GetOrganization(ctx, opt.OrganizationID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "organization not found", nil)
+	}
+	rows, err := spreadsheet.Read(fileName, file)
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	if len(rows) < 2 {
+		return utils.NewResponse(utils.CodeBadReq, "file has no data rows", nil)
+	}
+	header := spreadsheet.Header(rows[0])
+	for _, col := range []string{"sku", "price"} {
+		if _, ok := header[col]; !ok {
+			return utils.NewResponse(utils.CodeBadReq, "missing column "+col+" (columns: "+strings.Join(priceImportColumns, ", ")+")", nil)
+		}
+	}
+
+	result := &PriceImportResult{DryRun: opt.DryRun, Errors: []ImportRowError{}}
+	versions, errs := uc.parsePriceRows(ctx, priceListID, opt.OrganizationID, rows, header)
+	result.Errors = append(result.Errors, errs...)
+	for i := 1; i < len(rows); i++ {
+		if !spreadsheet.IsBlank(rows[i]) {
+			result.Rows++
+		}
+	}
+	result.Skipped = result.Rows - len(versions)
+
+	if len(result.Errors) > 0 && !opt.SkipInvalid {
+		return utils.NewResponse(utils.CodeBadReq, "file has errors; nothing was imported", result)
+	}
+	if opt.DryRun {
+		result.Imported = len(versions)
+		return utils.NewResponse(utils.CodeOK, "file validated", result)
+	}
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		for _, v := range versions {
+			if _, err := applyPriceVersion(ctx, q, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	result.Imported = len(versions)
+	return utils.NewResponse(utils.CodeOK, "prices imported", result)
+}
+
+// parsePriceRows validates the data rows of a price import and returns the
+// valid ones with an error per invalid cell or row.
+func (uc *PriceListUseCase) parsePriceRows(ctx context.Context, priceListID, orgID int32, rows [][]string, header map[string]int) ([]*priceVersion, []ImportRowError) {
+	products := map[string]*repository.Product{}
+	uoms := map[string]*int32{}
+	seen := map[string]int{}
+	var (
+		out  []*priceVersion
+		errs []ImportRowError
+	)
+	for i := 1; i < len(rows); i++ {
+		row, line := rows[i], i+1
+		if spreadsheet.IsBlank(row) {
+			continue
+		}
+		cell := func(name string) string { return spreadsheet.Cell(row, header, name) }
+		fail := func(col, msg string) { errs = append(errs, ImportRowError{Row: line, Column: col, Message: msg}) }
+		before := len(errs)
+
+		sku := cell("sku")
+		product, cached := products[sku]
+		if !cached && sku != "" {
+			if p, err := uc.repo.GetProductBySKU(ctx, repository.GetProductBySKUParams{OrganizationID: orgID, Sku: sku}); err == nil {
+				product = &p
+			}
+			products[sku] = product
+		}
+		if sku == "" {
+			fail("sku", "sku is required")
+		} else if product == nil {
+			fail("sku", "unknown sku "+sku)
+		}
+
+		v := &priceVersion{priceListID: priceListID, metadata: []byte(`{"source":"import"}`)}
+		if product != nil {
+			v.productID = product.ID
+			if vs := cell("variant_sku"); vs != "" {
+				variant, err := uc.repo.GetProductVariantBySKU(ctx, vs)
+				if err != nil || variant.ProductID != product.ID {
+					fail("variant_sku", "variant "+vs+" does not belong to "+sku)
+				} else {
+					v.variantID = pgtype.Int4{Int32: variant.ID, Valid: true}
+				}
+			}
+		}
+		if code := cell("uom"); code != "" {
+			id, cached := uoms[code]
+			if !cached {
+				if u, err := uc.repo.GetUnitOfMeasureByCode(ctx, code); err == nil {
+					id = &u.ID
+				}
+				uoms[code] = id
+			}
+			if id == nil {
+				fail("uom", "unknown unit of measure "+code)
+			} else {
+				v.uomID = pgtype.Int4{Int32: *id, Valid: true}
+			}
+		}
+		if msg := v.setAmounts(cell("price"), cell("min_quantity"), cell("max_quantity")); msg != "" {
+			fail("price", msg)
+		}
+		for _, col := range []string{"valid_from", "valid_to"} {
+			s := cell(col)
+			if s == "" {
+				continue
+			}
+			t, err := spreadsheet.ParseDate(s)
+			if err != nil {
+				fail(col, err.Error())
+				continue
+			}
+			if col == "valid_from" {
+				v.validFrom = &t
+			} else {
+				v.validTo = &t
+			}
+		}
+		if msg := checkValidity(v.validFrom, v.validTo); msg != "" {
+			fail("valid_to", msg)
+		}
+		if len(errs) > before {
+			continue
+		}
+
+		key := fmt.Sprintf("%d/%d/%d/%s/%s", v.productID, v.variantID.Int32, v.uomID.Int32, v.minQuantity.FloatString(3), cell("valid_from"))
+		if first, dup := seen[key]; dup {
+			fail("", fmt.Sprintf("duplicates row %d", first))
+			continue
+		}
+		seen[key] = line
+		out = append(out, v)
+	}
+	return out, errs
+}
+
+// checkValidity returns a message when a validity range ends before it
+// starts.
+func checkValidity(from, to *time.Time) string {
+	if from != nil && to != nil && to.Before(*from) {
+		return "valid_to is before valid_from"
+	}
+	return ""
+}
+
+func optionalDate(t *time.Time) pgtype.Date {
+	if t == nil {
+		return pgtype.Date{}
+	}
+	return pgtype.Date{Time: *t, Valid: true}
+}
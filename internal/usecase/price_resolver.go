@@ -0,0 +1,171 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"time"
+
+	"NEMBUS/internal/pricing"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// saleContext identifies who is buying, where and when; it decides which
+// price lists apply.
+type saleContext struct {
+	storeID     int32
+	customer    *repository.Customer
+	date        time.Time
+	priceListID *int32
+}
+
+// priceResolver prices the products of one sale. It loads the applicable
+// price lists once and the candidate price rows per batch of products.
+type priceResolver struct {
+	q      *repository.Queries
+	date   time.Time
+	lists  []pricing.List
+	prices []pricing.Price
+	loaded map[int32]bool
+}
+
+// newPriceResolver loads the price lists that apply to sc. An explicit
+// price list replaces the precedence rules.
+func newPriceResolver(ctx context.Context, q *repository.Queries, sc saleContext) (*priceResolver, error) {
+	r := &priceResolver{q: q, date: sc.date, loaded: map[int32]bool{}}
+	if r.date.IsZero() {
+		r.date = time.Now()
+	}
+	if sc.priceListID != nil {
+		pl, err := q.GetPriceList(ctx, *sc.priceListID)
+		if err != nil {
+			return nil, documentInputErrorf("price list %d not found", *sc.priceListID)
+		}
+		r.lists = []pricing.List{{ID: pl.ID, Code: pl.Code, Source: pricing.SourceExplicit}}
+		return r, nil
+	}
+
+	arg := repository.ListApplicablePriceListsParams{OnDate: pgtype.Date{Time: r.date, Valid: true}}
+	if sc.storeID != 0 {
+		arg.StoreID.Int32, arg.StoreID.Valid = sc.storeID, true
+	}
+	if sc.customer != nil {
+		arg.CustomerID.Int32, arg.CustomerID.Valid = sc.customer.ID, true
+		arg.CustomerType = sc.customer.CustomerType
+	}
+	rows, err := q.ListApplicablePriceLists(ctx, arg)
+	if err != nil {
+		return nil, fmt.Errorf("load price lists: %w", err)
+	}
+	for _, row := range rows {
+		r.lists = append(r.lists, pricing.List{
+			ID:       row.ID,
+			Code:     row.Code,
+			Source:   pricing.Source(row.Scope),
+			Priority: row.Priority,
+		})
+	}
+	r.lists = pricing.SortLists(r.lists)
+	return r, nil
+}
+
+// load fetches the candidate prices of productIDs not loaded yet.
+func (r *priceResolver) load(ctx context.Context, productIDs ...int32) error {
+	var ids []int32
+	for _, id := range productIDs {
+		if !r.loaded[id] {
+			r.loaded[id] = true
+			ids = append(ids, id)
+		}
+	}
+	if len(ids) == 0 || len(r.lists) == 0 {
+		return nil
+	}
+	listIDs := make([]int32, len(r.lists))
+	for i, l := range r.lists {
+		listIDs[i] = l.ID
+	}
+	rows, err := r.q.ListPriceCandidates(ctx, repository.ListPriceCandidatesParams{
+		ProductIds:   ids,
+		PriceListIds: listIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("load prices: %w", err)
+	}
+	for _, row := range rows {
+		r.prices = append(r.prices, pricingPrice(row))
+	}
+	return nil
+}
+
+// resolve prices one line. Variant and unit are optional (zero); a zero
+// unit means the product's base unit.
+func (r *priceResolver) resolve(ctx context.Context, product repository.Product, variantID, uomID int32, qty *big.Rat) (*pricing.Result, error) {
+	if err := r.load(ctx, product.ID); err != nil {
+		return nil, err
+	}
+	return pricing.Resolve(r.lists, r.prices, pricing.Request{
+		ProductID: product.ID,
+		VariantID: variantID,
+		UomID:     uomID,
+		BaseUomID: product.BaseUomID.Int32,
+		Quantity:  qty,
+		Date:      r.date,
+	})
+}
+
+// resolveListing prices one base unit of a listed product or variant the
+// way checkout does; the caller loads the products first.
+func (r *priceResolver) resolveListing(product repository.Product, variantID int32) (*pricing.Result, error) {
+	return pricing.Resolve(r.lists, r.prices, pricing.Request{
+		ProductID: product.ID,
+		VariantID: variantID,
+		BaseUomID: product.BaseUomID.Int32,
+		Quantity:  big.NewRat(1, 1),
+		Date:      r.date,
+	})
+}
+
+// pricingPrice converts a product_prices row for the pricing package.
+func pricingPrice(row repository.ProductPrice) pricing.Price {
+	p := pricing.Price{
+		ID:          row.ID,
+		PriceListID: row.PriceListID,
+		ProductID:   row.ProductID,
+		VariantID:   row.ProductVariantID.Int32,
+		UomID:       row.UomID.Int32,
+		Price:       utils.NumericToRat(row.Price),
+	}
+	if row.MinQuantity.Valid {
+		p.MinQuantity = utils.NumericToRat(row.MinQuantity)
+	}
+	if row.MaxQuantity.Valid {
+		p.MaxQuantity = utils.NumericToRat(row.MaxQuantity)
+	}
+	if row.ValidFrom.Valid {
+		p.ValidFrom = &row.ValidFrom.Time
+	}
+	if row.ValidTo.Valid {
+		p.ValidTo = &row.ValidTo.Time
+	}
+	return p
+}
+
+// priceMetadata records on a document line which list priced it and why.
+func priceMetadata(res *pricing.Result) []byte {
+	if res == nil {
+		return []byte("{}")
+	}
+	meta, _ := json.Marshal(map[string]interface{}{
+		"price_list_id":     res.PriceListID,
+		"price_list_code":   res.PriceListCode,
+		"price_source":      res.Source,
+		"price_id":          res.PriceID,
+		"price_explanation": res.Explanation,
+	})
+	return meta
+}
@@ -0,0 +1,232 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/pricing"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PricingUseCase resolves prices and manages price list assignments.
+type PricingUseCase struct {
+	repo *repository.Queries
+}
+
+// NewPricingUseCase creates a new pricing use case.
+func NewPricingUseCase() *PricingUseCase {
+	return &PricingUseCase{}
+}
+
+// SetRepository injects repository per request
+func (uc *PricingUseCase) SetRepository(repo *repository.Queries) {
+	uc.repo = repo
+}
+
+// PriceQuery is the input for ResolvePrice.
+type PriceQuery struct {
+	ProductID   int32
+	VariantID   *int32
+	StoreID     *int32
+	CustomerID  *int32
+	PriceListID *int32
+	UomID       *int32
+	Quantity    string
+	Date        *time.Time
+}
+
+// ResolvedPrice is a resolved price with the lists that were tried.
+type ResolvedPrice struct {
+	ProductID     int32    `json:"product_id"`
+	Price         string   `json:"price"`
+	PriceID       int32    `json:"price_id"`
+	PriceListID   int32    `json:"price_list_id"`
+	PriceListCode string   `json:"price_list_code"`
+	Source        string   `json:"source"`
+	UomID         *int32   `json:"uom_id"`
+	Date          string   `json:"date"`
+	Explanation   []string `json:"explanation"`
+}
+
+// ResolvePrice returns the price a sale would use and explains how it was
+// chosen. When no list prices the product the explanation is returned with
+// a 404.
+func (uc *PricingUseCase) ResolvePrice(ctx context.Context, in *PriceQuery) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	product, err := uc.repo.GetProduct(ctx, in.ProductID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+	}
+	qty := big.NewRat(1, 1)
+	if strings.TrimSpace(in.Quantity) != "" {
+		if qty, err = parseQuantity(product, in.Quantity); err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+	}
+	sc := saleContext{date: time.Now(), priceListID: in.PriceListID}
+	if in.Date != nil {
+		sc.date = *in.Date
+	}
+	if in.StoreID != nil {
+		if _, err := uc.repo.GetStore(ctx, *in.StoreID); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+		}
+		sc.storeID = *in.StoreID
+	}
+	if in.CustomerID != nil {
+		c, err := uc.repo.GetCustomer(ctx, *in.CustomerID)
+		if err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "customer not found", nil)
+		}
+		sc.customer = &c
+	}
+	r, err := newPriceResolver(ctx, uc.repo, sc)
+	if err != nil {
+		var bad *documentInputError
+		if errors.As(err, &bad) {
+			return utils.NewResponse(utils.CodeNotFound, bad.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	var variantID, uomID int32
+	if in.VariantID != nil {
+		variantID = *in.VariantID
+	}
+	if in.UomID != nil {
+		uomID = *in.UomID
+	}
+	res, err := r.resolve(ctx, product, variantID, uomID, qty)
+	if err != nil && !errors.Is(err, pricing.ErrNoPrice) {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	out := &ResolvedPrice{
+		ProductID:   product.ID,
+		Date:        sc.date.Format("2006-01-02"),
+		Explanation: res.Explanation,
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "no applicable price", out)
+	}
+	out.Price = res.Price.FloatString(2)
+	out.PriceID = res.PriceID
+	out.PriceListID = res.PriceListID
+	out.PriceListCode = res.PriceListCode
+	out.Source = string(res.Source)
+	if res.UomID != 0 {
+		out.UomID = &res.UomID
+	}
+	return utils.NewResponse(utils.CodeOK, "price resolved", out)
+}
+
+// PriceListAssignmentInput is the input for CreatePriceListAssignment.
+type PriceListAssignmentInput struct {
+	PriceListID  int32
+	Scope        string
+	CustomerID   *int32
+	CustomerType string
+	StoreID      *int32
+	Priority     int32
+	ValidFrom    *time.Time
+	ValidTo      *time.Time
+}
+
+// ListPriceListAssignments returns price list assignments, optionally of one scope.
+func (uc *PricingUseCase) ListPriceListAssignments(ctx context.Context, scope string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	var arg pgtype.Text
+	if scope != "" {
+		src, err := pricing.ParseScope(scope)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		arg = pgtype.Text{String: string(src), Valid: true}
+	}
+	rows, err := uc.repo.ListPriceListAssignments(ctx, arg)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "price list assignments fetched successfully", rows)
+}
+
+// CreatePriceListAssignment makes a price list applicable to a customer,
+// customer type or store, or as a promotion (optionally limited to a store).
+func (uc *PricingUseCase) CreatePriceListAssignment(ctx context.Context, in *PriceListAssignmentInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	scope, err := pricing.ParseScope(in.Scope)
+	if err != nil {
+		return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+	}
+	if _, err := uc.repo.GetPriceList(ctx, in.PriceListID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "price list not found", nil)
+	}
+	arg := repository.CreatePriceListAssignmentParams{
+		PriceListID: in.PriceListID,
+		Scope:       string(scope),
+		Priority:    in.Priority,
+		IsActive:    pgtype.Bool{Bool: true, Valid: true},
+		Metadata:    []byte("{}"),
+	}
+	switch scope {
+	case pricing.SourceCustomer:
+		if in.CustomerID == nil {
+			return utils.NewResponse(utils.CodeBadReq, "customer_id is required for customer scope", nil)
+		}
+		if _, err := uc.repo.GetCustomer(ctx, *in.CustomerID); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "customer not found", nil)
+		}
+		arg.CustomerID = optionalInt4(in.CustomerID)
+	case pricing.SourceCustomerType:
+		if strings.TrimSpace(in.CustomerType) == "" {
+			return utils.NewResponse(utils.CodeBadReq, "customer_type is required for customer_type scope", nil)
+		}
+		arg.CustomerType = optionalText(in.CustomerType)
+	case pricing.SourceStore, pricing.SourcePromotion:
+		if scope == pricing.SourceStore && in.StoreID == nil {
+			return utils.NewResponse(utils.CodeBadReq, "store_id is required for store scope", nil)
+		}
+		if in.StoreID != nil {
+			if _, err := uc.repo.GetStore(ctx, *in.StoreID); err != nil {
+				return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+			}
+			arg.StoreID = optionalInt4(in.StoreID)
+		}
+	}
+	if in.ValidFrom != nil {
+		arg.ValidFrom = pgtype.Date{Time: *in.ValidFrom, Valid: true}
+	}
+	if in.ValidTo != nil {
+		if in.ValidFrom != nil && in.ValidTo.Before(*in.ValidFrom) {
+			return utils.NewResponse(utils.CodeBadReq, "valid_to is before valid_from", nil)
+		}
+		arg.ValidTo = pgtype.Date{Time: *in.ValidTo, Valid: true}
+	}
+	a, err := uc.repo.CreatePriceListAssignment(ctx, arg)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeCreated, "price list assignment created", a)
+}
+
+// DeletePriceListAssignment removes a price list assignment.
+func (uc *PricingUseCase) DeletePriceListAssignment(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if err := uc.repo.DeletePriceListAssignment(ctx, id); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "price list assignment deleted", nil)
+}
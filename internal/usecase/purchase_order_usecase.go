@@ -0,0 +1,460 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/inventory"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PurchaseOrderUseCase creates and reads purchase orders and receives goods
+// against them.
+type PurchaseOrderUseCase struct {
+	repo *repository.Queries
+}
+
+// NewPurchaseOrderUseCase creates a new purchase order use case.
+func NewPurchaseOrderUseCase() *PurchaseOrderUseCase {
+	return &PurchaseOrderUseCase{}
+}
+
+// SetRepository injects repository per request
+func (uc *PurchaseOrderUseCase) SetRepository(repo *repository.Queries) {
+	uc.repo = repo
+}
+
+// PurchaseOrderInput is the input for CreatePurchaseOrder.
+type PurchaseOrderInput struct {
+	OrganizationID       int32
+	SupplierID           int32
+	StoreID              int32
+	PoDate               *time.Time
+	ExpectedDeliveryDate *time.Time
+	CreatedBy            *int32
+	Lines                []OrderLineInput
+}
+
+// CreatePurchaseOrder creates a draft purchase order. Unit prices are the
+// supplier's prices and are required; input tax is computed per product tax
+// category.
+func (uc *PurchaseOrderUseCase) CreatePurchaseOrder(ctx context.Context, in *PurchaseOrderInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if len(in.Lines) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "order has no lines", nil)
+	}
+	orgID, resp := activeOrganization(ctx, in.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	in.OrganizationID = orgID
+	org, err := uc.repo.GetOrganization(ctx, in.OrganizationID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "organization not found", nil)
+	}
+	if supplier, err := uc.repo.GetSupplier(ctx, in.SupplierID); err != nil || supplier.OrganizationID != org.ID {
+		return utils.NewResponse(utils.CodeNotFound, "supplier not found", nil)
+	}
+	if store, err := uc.repo.GetStore(ctx, in.StoreID); err != nil || store.OrganizationID != org.ID {
+		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+	}
+	opt, err := documentTaxOptions(org, nil)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	lines := make([]documentLine, len(in.Lines))
+	for i, l := range in.Lines {
+		product, err := uc.repo.GetProduct(ctx, l.ProductID)
+		if err != nil || product.OrganizationID != org.ID {
+			return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+		}
+		if !product.IsPurchasable.Bool {
+			return utils.NewResponse(utils.CodeBadReq, "product "+product.Sku+" is not purchasable", nil)
+		}
+		qty, err := parseQuantity(product, l.Quantity)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		if l.UnitPrice == "" {
+			return utils.NewResponse(utils.CodeBadReq, "unit price is required for product "+product.Sku, nil)
+		}
+		price, err := parseAmount("unit price", l.UnitPrice)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		discount, err := parseAmount("discount", l.Discount)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		uom := optionalInt4(l.UomID)
+		if !uom.Valid {
+			uom = product.BaseUomID
+		}
+		lines[i] = documentLine{
+			product:   product,
+			variantID: optionalInt4(l.ProductVariantID),
+			uomID:     uom,
+			quantity:  qty,
+			unitPrice: price,
+			discount:  discount,
+		}
+	}
+
+	res, err := calculateDocumentTax(ctx, uc.repo, lines, opt)
+	if err != nil {
+		var bad *documentInputError
+		if errors.As(err, &bad) {
+			return utils.NewResponse(utils.CodeBadReq, bad.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	now := time.Now()
+	totals := newDocumentTotals(res, opt)
+	poDate := now
+	if in.PoDate != nil {
+		poDate = *in.PoDate
+	}
+	arg := repository.CreatePurchaseOrderHeaderParams{
+		PoNumber:       documentNumber("PO", now),
+		OrganizationID: org.ID,
+		SupplierID:     in.SupplierID,
+		StoreID:        in.StoreID,
+		PoDate:         pgtype.Date{Time: poDate, Valid: true},
+		Status:         pgtype.Text{String: "draft", Valid: true},
+		Subtotal:       utils.RatToNumeric(res.Subtotal, 2),
+		TaxAmount:      utils.RatToNumeric(res.Tax, 2),
+		DiscountAmount: utils.RatToNumeric(res.Discount, 2),
+		TotalAmount:    utils.RatToNumeric(res.Total, 2),
+		CreatedBy:      optionalInt4(in.CreatedBy),
+		Metadata:       documentTaxMetadata(totals),
+	}
+	if in.ExpectedDeliveryDate != nil {
+		arg.ExpectedDeliveryDate = pgtype.Date{Time: *in.ExpectedDeliveryDate, Valid: true}
+	}
+
+	result := &OrderResult{Number: arg.PoNumber, DocumentTotals: totals}
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		header, err := q.CreatePurchaseOrderHeader(ctx, arg)
+		if err != nil {
+			return err
+		}
+		result.ID = header.ID
+		result.Status = header.Status.String
+		for i, l := range lines {
+			lr := res.Lines[i]
+			if err := q.CreatePurchaseOrderLine(ctx, repository.CreatePurchaseOrderLineParams{
+				PurchaseOrderID:  header.ID,
+				LineNumber:       int32(i + 1),
+				ProductID:        l.product.ID,
+				ProductVariantID: l.variantID,
+				Quantity:         utils.RatToNumeric(l.quantity, 3),
+				UomID:            l.uomID,
+				UnitPrice:        utils.RatToNumeric(l.unitPrice, 4),
+				DiscountAmount:   utils.RatToNumeric(lr.Discount, 2),
+				TaxAmount:        utils.RatToNumeric(lr.Tax, 2),
+				LineTotal:        utils.RatToNumeric(lr.LineTotal, 2),
+				Metadata:         []byte("{}"),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeCreated, "purchase order created", result)
+}
+
+// GetPurchaseOrder returns a purchase order with its lines and received
+// quantities.
+func (uc *PurchaseOrderUseCase) GetPurchaseOrder(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	po, err := uc.repo.GetPurchaseOrder(ctx, id)
+	if err != nil || !inActiveOrganization(ctx, po.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "purchase order not found", nil)
+	}
+	scope, resp := dataScope(ctx, uc.repo, PermissionViewPurchaseOrders)
+	if resp != nil {
+		return resp
+	}
+	if !scope.AllowsOwned(po.StoreID, po.CreatedBy) {
+		return utils.NewResponse(utils.CodeNotFound, "purchase order not found", nil)
+	}
+	rows, err := uc.repo.GetPurchaseOrderWithReceivedQty(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if len(rows) == 0 {
+		return utils.NewResponse(utils.CodeNotFound, "purchase order not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "purchase order fetched successfully", rows)
+}
+
+// GoodsReceiptLine is a quantity received against one purchase order line.
+// Batch-managed products need the batch number (and usually its dates);
+// serialized products need one serial number per unit. A purchase order line
+// may appear more than once, for example for two batches.
+type GoodsReceiptLine struct {
+	LineNumber        int32
+	Quantity          string
+	BatchNumber       string
+	ManufacturingDate *time.Time
+	ExpiryDate        *time.Time
+	SerialNumbers     []string
+}
+
+// GoodsReceiptInput is the input for ReceiveGoods.
+type GoodsReceiptInput struct {
+	ReceivedBy *int32
+	Lines      []GoodsReceiptLine
+}
+
+// ReceivedLine is one line of a goods receipt.
+type ReceivedLine struct {
+	LineNumber    int32    `json:"line_number"`
+	ProductID     int32    `json:"product_id"`
+	Quantity      string   `json:"quantity"`
+	BatchNumber   string   `json:"batch_number,omitempty"`
+	SerialNumbers []string `json:"serial_numbers,omitempty"`
+}
+
+// GoodsReceiptResult is returned by ReceiveGoods.
+type GoodsReceiptResult struct {
+	ReceiptNumber   string         `json:"receipt_number"`
+	PurchaseOrderID int32          `json:"purchase_order_id"`
+	PoNumber        string         `json:"po_number"`
+	Status          string         `json:"status"`
+	Lines           []ReceivedLine `json:"lines"`
+}
+
+// receivedGoods is a validated goods receipt line.
+type receivedGoods struct {
+	line    repository.PurchaseOrderLine
+	product repository.Product
+	qty     *big.Rat
+	in      GoodsReceiptLine
+	serials []string
+	// units are serials already known, coming back from an RMA.
+	units map[string]repository.ProductSerialNumber
+}
+
+// ReceiveGoods books goods received against a purchase order into the
+// order's store: it raises the received quantities, stock, batches and
+// serial numbers and writes receipt stock movements, then marks the order
+// partially_received or received. Quantities beyond what is outstanding are
+// refused, as are expired batches and serial numbers already in use (a unit
+// back from an RMA may be received again).
+func (uc *PurchaseOrderUseCase) ReceiveGoods(ctx context.Context, id int32, in *GoodsReceiptInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if len(in.Lines) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "receipt has no lines", nil)
+	}
+	po, err := uc.repo.GetPurchaseOrder(ctx, id)
+	if err != nil || !inActiveOrganization(ctx, po.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "purchase order not found", nil)
+	}
+	switch po.Status.String {
+	case "cancelled", "received":
+		return utils.NewResponse(utils.CodeBadReq, "purchase order is "+po.Status.String, nil)
+	}
+	poLines, err := uc.repo.ListPurchaseOrderLines(ctx, po.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	byNumber := make(map[int32]repository.PurchaseOrderLine, len(poLines))
+	for _, l := range poLines {
+		byNumber[l.LineNumber] = l
+	}
+
+	now := time.Now()
+	seen := serialCapture{}
+	received := make(map[int32]*big.Rat)
+	goods := make([]receivedGoods, len(in.Lines))
+	for i, l := range in.Lines {
+		pol, ok := byNumber[l.LineNumber]
+		if !ok {
+			return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("purchase order has no line %d", l.LineNumber), nil)
+		}
+		product, err := uc.repo.GetProduct(ctx, pol.ProductID)
+		if err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+		}
+		qty, err := parseQuantity(product, l.Quantity)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		total, ok := received[pol.ID]
+		if !ok {
+			total = new(big.Rat)
+			received[pol.ID] = total
+		}
+		total.Add(total, qty)
+		outstanding := new(big.Rat).Sub(utils.NumericToRat(pol.Quantity), utils.NumericToRat(pol.ReceivedQuantity))
+		if total.Cmp(outstanding) > 0 {
+			return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("line %d: only %s outstanding", l.LineNumber, outstanding.FloatString(3)), nil)
+		}
+
+		g := receivedGoods{line: pol, product: product, qty: qty, in: l}
+		if product.IsBatchManaged.Bool {
+			if strings.TrimSpace(l.BatchNumber) == "" {
+				return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("line %d: batch number is required for product %s", l.LineNumber, product.Sku), nil)
+			}
+			if l.ExpiryDate != nil && inventory.Expired(inventory.Batch{Expiry: l.ExpiryDate}, now) {
+				return utils.NewResponse(utils.CodeBadReq, fmt.Sprintf("line %d: batch %s is already expired", l.LineNumber, l.BatchNumber), nil)
+			}
+		}
+		if product.IsSerialized.Bool {
+			if g.serials, err = seen.capture(product, l.SerialNumbers, qty); err != nil {
+				return checkoutError(err)
+			}
+			g.units = make(map[string]repository.ProductSerialNumber)
+			for _, s := range g.serials {
+				u, err := uc.repo.GetProductSerialNumberBySerial(ctx, s)
+				if errors.Is(err, pgx.ErrNoRows) {
+					continue
+				}
+				if err != nil {
+					return utils.NewResponse(utils.CodeError, err.Error(), nil)
+				}
+				if u.ProductID != product.ID || u.Status.String != inventory.SerialRMA {
+					return utils.NewResponse(utils.CodeBadReq, "serial number "+s+" is already in use", nil)
+				}
+				g.units[s] = u
+			}
+		}
+		goods[i] = g
+	}
+
+	result := &GoodsReceiptResult{
+		ReceiptNumber:   documentNumber("GR", now),
+		PurchaseOrderID: po.ID,
+		PoNumber:        po.PoNumber,
+	}
+	meta, _ := json.Marshal(map[string]interface{}{"receipt_number": result.ReceiptNumber})
+	store := pgtype.Int4{Int32: po.StoreID, Valid: true}
+
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		for _, g := range goods {
+			n, err := q.ReceivePurchaseOrderLine(ctx, repository.ReceivePurchaseOrderLineParams{
+				Quantity: utils.RatToNumeric(g.qty, 3),
+				ID:       g.line.ID,
+			})
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return documentInputErrorf("line %d: quantity exceeds what is outstanding", g.line.LineNumber)
+			}
+			if g.product.TrackInventory.Bool {
+				if err := addStock(ctx, q, g.product.ID, g.line.ProductVariantID, po.StoreID, g.qty); err != nil {
+					return err
+				}
+			}
+			batch := strings.TrimSpace(g.in.BatchNumber)
+			if g.product.IsBatchManaged.Bool {
+				if _, err := q.ReceiveBatchQuantity(ctx, repository.ReceiveBatchQuantityParams{
+					ProductID:         g.product.ID,
+					ProductVariantID:  g.line.ProductVariantID,
+					BatchNumber:       batch,
+					ManufacturingDate: optionalDate(g.in.ManufacturingDate),
+					ExpiryDate:        optionalDate(g.in.ExpiryDate),
+					StoreID:           store,
+					QuantityAvailable: utils.RatToNumeric(g.qty, 3),
+				}); err != nil {
+					return err
+				}
+			}
+
+			movement := repository.CreateStockMovementParams{
+				MovementType:     "receipt",
+				ReferenceType:    pgtype.Text{String: "purchase_order", Valid: true},
+				ReferenceID:      pgtype.Int4{Int32: po.ID, Valid: true},
+				ProductID:        g.product.ID,
+				ProductVariantID: g.line.ProductVariantID,
+				ToStoreID:        store,
+				Quantity:         utils.RatToNumeric(g.qty, 3),
+				UomID:            g.line.UomID,
+				BatchNumber:      optionalText(batch),
+				MovementDate:     pgtype.Timestamp{Time: now, Valid: true},
+				PostedBy:         optionalInt4(in.ReceivedBy),
+				Status:           pgtype.Text{String: "completed", Valid: true},
+				CostPerUnit:      g.line.UnitPrice,
+				Metadata:         meta,
+			}
+			if g.serials == nil {
+				if _, err := q.CreateStockMovement(ctx, movement); err != nil {
+					return err
+				}
+			}
+			for _, s := range g.serials {
+				if u, ok := g.units[s]; ok {
+					if err := moveSerial(ctx, q, u, inventory.SerialInStock, store); err != nil {
+						return err
+					}
+				} else if _, err := q.CreateProductSerialNumber(ctx, repository.CreateProductSerialNumberParams{
+					ProductID:         g.product.ID,
+					ProductVariantID:  g.line.ProductVariantID,
+					SerialNumber:      s,
+					Status:            pgtype.Text{String: inventory.SerialInStock, Valid: true},
+					CurrentStoreID:    store,
+					ManufacturingDate: optionalDate(g.in.ManufacturingDate),
+					ExpiryDate:        optionalDate(g.in.ExpiryDate),
+					Metadata:          []byte("{}"),
+				}); err != nil {
+					return err
+				}
+				unit := movement
+				unit.Quantity = utils.RatToNumeric(big.NewRat(1, 1), 3)
+				unit.SerialNumber = pgtype.Text{String: s, Valid: true}
+				if _, err := q.CreateStockMovement(ctx, unit); err != nil {
+					return err
+				}
+			}
+			result.Lines = append(result.Lines, ReceivedLine{
+				LineNumber:    g.line.LineNumber,
+				ProductID:     g.product.ID,
+				Quantity:      g.qty.FloatString(3),
+				BatchNumber:   batch,
+				SerialNumbers: g.serials,
+			})
+		}
+
+		lines, err := q.ListPurchaseOrderLines(ctx, po.ID)
+		if err != nil {
+			return err
+		}
+		result.Status = "received"
+		for _, l := range lines {
+			if utils.NumericToRat(l.ReceivedQuantity).Cmp(utils.NumericToRat(l.Quantity)) < 0 {
+				result.Status = "partially_received"
+				break
+			}
+		}
+		return q.UpdatePurchaseOrderStatus(ctx, repository.UpdatePurchaseOrderStatusParams{
+			ID:     po.ID,
+			Status: pgtype.Text{String: result.Status, Valid: true},
+		})
+	})
+	if err != nil {
+		return checkoutError(err)
+	}
+	return utils.NewResponse(utils.CodeCreated, "goods received", result)
+}
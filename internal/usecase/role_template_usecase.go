@@ -0,0 +1,418 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"strings"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// RoleTemplate is a starting point for a role: its default permissions and
+// UI customizations. Templates ship with the application; creating a role
+// from one copies them, so later changes to either don't affect the other.
+type RoleTemplate struct {
+	Code        string                `json:"code"`
+	Name        string                `json:"name"`
+	Description string                `json:"description"`
+	Metadata    map[string]string     `json:"metadata"`
+	Permissions []RoleTemplateGrant   `json:"permissions"`
+	UI          []RoleTemplateUISetup `json:"ui_customizations"`
+}
+
+// RoleTemplateGrant is a permission of a RoleTemplate, by code.
+type RoleTemplateGrant struct {
+	Code  string `json:"code"`
+	Scope string `json:"scope"`
+}
+
+// RoleTemplateUISetup is a UI customization of a RoleTemplate. Submenu is
+// the module, menu and submenu codes joined by "/", as in navigation
+// bundles.
+type RoleTemplateUISetup struct {
+	Submenu string                 `json:"submenu"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+// roleTemplates are the shipped templates. Their roles are store-bound
+// (metadata scope own), so assigning one to a user needs a store.
+var roleTemplates = []RoleTemplate{
+	{
+		Code:        "CASHIER",
+		Name:        "Cashier",
+		Description: "Rings up sales on the POS of their stores",
+		Metadata:    map[string]string{"scope": "own"},
+		Permissions: []RoleTemplateGrant{
+			{Code: PermissionViewStores, Scope: ScopeOwnStores},
+			{Code: PermissionViewStock, Scope: ScopeOwnStores},
+			{Code: PermissionViewPosTransactions, Scope: ScopeOwn},
+			{Code: PermissionViewSalesOrders, Scope: ScopeOwn},
+		},
+		UI: []RoleTemplateUISetup{
+			{Submenu: "POS/SALES/CHECKOUT", Data: map[string]interface{}{"layout": "touch", "show_stock": true, "allow_discount": false}},
+			{Submenu: "POS/SALES/RETURNS", Data: map[string]interface{}{"require_receipt": true}},
+		},
+	},
+	{
+		Code:        "STORE_MANAGER",
+		Name:        "Store Manager",
+		Description: "Runs their stores: sales, stock, orders and batch overrides",
+		Metadata:    map[string]string{"scope": "own"},
+		Permissions: []RoleTemplateGrant{
+			{Code: PermissionViewStores, Scope: ScopeOwnStores},
+			{Code: PermissionViewStock, Scope: ScopeOwnStores},
+			{Code: PermissionViewPosTransactions, Scope: ScopeOwnStores},
+			{Code: PermissionViewSalesOrders, Scope: ScopeOwnStores},
+			{Code: PermissionViewPurchaseOrders, Scope: ScopeOwnStores},
+			{Code: PermissionBatchOverride, Scope: ScopeOwnStores},
+		},
+		UI: []RoleTemplateUISetup{
+			{Submenu: "POS/SALES/CHECKOUT", Data: map[string]interface{}{"layout": "standard", "show_stock": true, "allow_discount": true}},
+			{Submenu: "POS/SALES/RETURNS", Data: map[string]interface{}{"require_receipt": false}},
+			{Submenu: "INVENTORY/STOCK/OVERVIEW", Data: map[string]interface{}{"default_view": "store", "show_expiry_alerts": true}},
+		},
+	},
+	{
+		Code:        "WAREHOUSE_CLERK",
+		Name:        "Warehouse Clerk",
+		Description: "Receives and moves stock in their warehouses",
+		Metadata:    map[string]string{"scope": "own"},
+		Permissions: []RoleTemplateGrant{
+			{Code: PermissionViewStores, Scope: ScopeOwnStores},
+			{Code: PermissionViewStock, Scope: ScopeOwnStores},
+			{Code: PermissionViewPurchaseOrders, Scope: ScopeOwnStores},
+			{Code: PermissionBatchOverride, Scope: ScopeOwnStores},
+		},
+		UI: []RoleTemplateUISetup{
+			{Submenu: "INVENTORY/STOCK/OVERVIEW", Data: map[string]interface{}{"default_view": "location", "show_expiry_alerts": true}},
+		},
+	},
+}
+
+// RoleCopyResult is returned when a role is created from a template or
+// cloned: the new role, what was copied to it and what was skipped because
+// the tenant doesn't have it.
+type RoleCopyResult struct {
+	Role             repository.Role `json:"role"`
+	Permissions      int             `json:"permissions"`
+	UICustomizations int             `json:"ui_customizations"`
+	Skipped          []string        `json:"skipped,omitempty"`
+}
+
+// RolePermissionDiff is a permission in the effective permissions of either
+// or both roles of a diff. An empty scope means the role lacks it.
+type RolePermissionDiff struct {
+	Code   string `json:"code"`
+	Name   string `json:"name"`
+	ScopeA string `json:"scope_a,omitempty"`
+	ScopeB string `json:"scope_b,omitempty"`
+}
+
+// RoleDiff compares the effective permissions of two roles, by permission
+// code.
+type RoleDiff struct {
+	RoleA        repository.Role      `json:"role_a"`
+	RoleB        repository.Role      `json:"role_b"`
+	OnlyInA      []RolePermissionDiff `json:"only_in_a"`
+	OnlyInB      []RolePermissionDiff `json:"only_in_b"`
+	ScopeChanged []RolePermissionDiff `json:"scope_changed"`
+	Same         []RolePermissionDiff `json:"same"`
+}
+
+// ListRoleTemplates lists the shipped role templates.
+func (uc *RoleUseCase) ListRoleTemplates(ctx context.Context) *repository.Response {
+	return utils.NewResponse(utils.CodeOK, "role templates fetched successfully", roleTemplates)
+}
+
+// CreateRoleFromTemplate creates an active, non-system role from a template
+// with its permissions and UI customizations. name and code default to the
+// template's. Permissions and submenus the tenant doesn't have are skipped.
+// It is gated like CreateRole on the template's permissions.
+func (uc *RoleUseCase) CreateRoleFromTemplate(ctx context.Context, viewerID *int32, templateCode, name, code string, description *string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	var t *RoleTemplate
+	for i := range roleTemplates {
+		if strings.EqualFold(roleTemplates[i].Code, templateCode) {
+			t = &roleTemplates[i]
+		}
+	}
+	if t == nil {
+		return utils.NewResponse(utils.CodeNotFound, "role template not found", nil)
+	}
+	codes := make([]string, len(t.Permissions))
+	for i, g := range t.Permissions {
+		codes[i] = g.Code
+	}
+	if resp := checkCanCreateRole(ctx, uc.repo, viewerID, false, codes); resp != nil {
+		return resp
+	}
+	if name == "" {
+		name = t.Name
+	}
+	if code == "" {
+		code = t.Code
+	}
+	desc := t.Description
+	if description != nil {
+		desc = *description
+	}
+	metadata := map[string]string{"template": t.Code}
+	for k, v := range t.Metadata {
+		metadata[k] = v
+	}
+	metaBytes, err := json.Marshal(metadata)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	var result RoleCopyResult
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		role, err := q.CreateRole(ctx, repository.CreateRoleParams{
+			Name:         name,
+			Code:         code,
+			Description:  toPgText(&desc),
+			IsSystemRole: pgtype.Bool{Bool: false, Valid: true},
+			IsActive:     pgtype.Bool{Bool: true, Valid: true},
+			Metadata:     metaBytes,
+		})
+		if err != nil {
+			return err
+		}
+		result.Role = role
+
+		for _, g := range t.Permissions {
+			p, err := q.GetPermissionByCode(ctx, g.Code)
+			if err != nil {
+				result.Skipped = append(result.Skipped, "permission "+g.Code)
+				continue
+			}
+			if _, err := q.AssignPermissionToRole(ctx, repository.AssignPermissionToRoleParams{
+				RoleID:       role.ID,
+				PermissionID: p.ID,
+				Scope:        pgtype.Text{String: g.Scope, Valid: true},
+				Metadata:     []byte("{}"),
+			}); err != nil {
+				return err
+			}
+			result.Permissions++
+		}
+
+		for _, ui := range t.UI {
+			submenuID, ok := submenuByPath(ctx, q, ui.Submenu)
+			if !ok {
+				result.Skipped = append(result.Skipped, "submenu "+ui.Submenu)
+				continue
+			}
+			data, err := json.Marshal(ui.Data)
+			if err != nil {
+				return err
+			}
+			if _, err := q.CreateRoleUICustomization(ctx, repository.CreateRoleUICustomizationParams{
+				RoleID:            role.ID,
+				SubmenuID:         submenuID,
+				CustomizationData: data,
+				Metadata:          []byte("{}"),
+			}); err != nil {
+				return err
+			}
+			result.UICustomizations++
+		}
+		return nil
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeCreated, "role created from template successfully", result)
+}
+
+// CloneRole creates an active, non-system role with the permissions, scopes,
+// metadata and UI customizations of role id. The description defaults to
+// the source role's. It is gated like CreateRole: cloning a system role, or
+// a role granting guardedPermissions, needs
+// PermissionManageSystemRoles.
+func (uc *RoleUseCase) CloneRole(ctx context.Context, viewerID *int32, id int32, name, code string, description *string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if name == "" {
+		return utils.NewResponse(utils.CodeBadReq, "role name cannot be empty", nil)
+	}
+	if code == "" {
+		return utils.NewResponse(utils.CodeBadReq, "role code cannot be empty", nil)
+	}
+	source, err := uc.repo.GetRole(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "role not found", nil)
+	}
+	perms, err := uc.repo.GetRolePermissions(ctx, source.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	codes := make([]string, len(perms))
+	for i, p := range perms {
+		codes[i] = p.Code
+	}
+	if resp := checkCanCreateRole(ctx, uc.repo, viewerID, source.IsSystemRole.Bool, codes); resp != nil {
+		return resp
+	}
+	desc := source.Description
+	if description != nil {
+		desc = toPgText(description)
+	}
+
+	var result RoleCopyResult
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		role, err := q.CreateRole(ctx, repository.CreateRoleParams{
+			Name:         name,
+			Code:         code,
+			Description:  desc,
+			IsSystemRole: pgtype.Bool{Bool: false, Valid: true},
+			IsActive:     pgtype.Bool{Bool: true, Valid: true},
+			Metadata:     source.Metadata,
+		})
+		if err != nil {
+			return err
+		}
+		result.Role = role
+
+		for _, p := range perms {
+			if _, err := q.AssignPermissionToRole(ctx, repository.AssignPermissionToRoleParams{
+				RoleID:       role.ID,
+				PermissionID: p.PermissionID,
+				Scope:        p.Scope,
+				Metadata:     p.Metadata,
+			}); err != nil {
+				return err
+			}
+			result.Permissions++
+		}
+
+		customizations, err := q.ListRoleUICustomizationsByRole(ctx, source.ID)
+		if err != nil {
+			return err
+		}
+		for _, cz := range customizations {
+			if _, err := q.CreateRoleUICustomization(ctx, repository.CreateRoleUICustomizationParams{
+				RoleID:            role.ID,
+				SubmenuID:         cz.SubmenuID,
+				CustomizationData: cz.CustomizationData,
+				Metadata:          cz.Metadata,
+			}); err != nil {
+				return err
+			}
+			result.UICustomizations++
+		}
+		return nil
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeCreated, "role cloned successfully", result)
+}
+
+// DiffRoles compares the effective permissions of roles a and b: a
+// permission granted without a scope counts as scope all.
+func (uc *RoleUseCase) DiffRoles(ctx context.Context, a, b int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	roleA, err := uc.repo.GetRole(ctx, a)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "role not found", nil)
+	}
+	roleB, err := uc.repo.GetRole(ctx, b)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "role not found", nil)
+	}
+	permsA, err := uc.repo.GetRolePermissions(ctx, a)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	permsB, err := uc.repo.GetRolePermissions(ctx, b)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	byCode := map[string]*RolePermissionDiff{}
+	entry := func(code, name string) *RolePermissionDiff {
+		d, ok := byCode[code]
+		if !ok {
+			d = &RolePermissionDiff{Code: code, Name: name}
+			byCode[code] = d
+		}
+		return d
+	}
+	for _, p := range permsA {
+		entry(p.Code, p.Name).ScopeA = effectiveScope(p.Scope)
+	}
+	for _, p := range permsB {
+		entry(p.Code, p.Name).ScopeB = effectiveScope(p.Scope)
+	}
+	codes := make([]string, 0, len(byCode))
+	for code := range byCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	diff := RoleDiff{
+		RoleA:        roleA,
+		RoleB:        roleB,
+		OnlyInA:      []RolePermissionDiff{},
+		OnlyInB:      []RolePermissionDiff{},
+		ScopeChanged: []RolePermissionDiff{},
+		Same:         []RolePermissionDiff{},
+	}
+	for _, code := range codes {
+		d := *byCode[code]
+		switch {
+		case d.ScopeB == "":
+			diff.OnlyInA = append(diff.OnlyInA, d)
+		case d.ScopeA == "":
+			diff.OnlyInB = append(diff.OnlyInB, d)
+		case d.ScopeA != d.ScopeB:
+			diff.ScopeChanged = append(diff.ScopeChanged, d)
+		default:
+			diff.Same = append(diff.Same, d)
+		}
+	}
+	return utils.NewResponse(utils.CodeOK, "role diff fetched successfully", diff)
+}
+
+// effectiveScope is the scope a role_permissions row grants.
+func effectiveScope(scope pgtype.Text) string {
+	if !scope.Valid || scope.String == "" {
+		return ScopeAll
+	}
+	return scope.String
+}
+
+// submenuByPath resolves a "MODULE/MENU/SUBMENU" code path to a submenu ID.
+func submenuByPath(ctx context.Context, q *repository.Queries, path string) (int32, bool) {
+	parts := strings.Split(path, "/")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	module, err := q.GetModuleByCode(ctx, parts[0])
+	if err != nil {
+		return 0, false
+	}
+	menu, err := q.GetMenuByCode(ctx, repository.GetMenuByCodeParams{ModuleID: module.ID, Code: parts[1]})
+	if err != nil {
+		return 0, false
+	}
+	submenu, err := q.GetSubmenuByCode(ctx, repository.GetSubmenuByCodeParams{MenuID: menu.ID, Code: parts[2]})
+	if err != nil {
+		return 0, false
+	}
+	return submenu.ID, true
+}
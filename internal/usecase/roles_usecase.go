@@ -2,13 +2,21 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"slices"
 
 	"NEMBUS/internal/repository"
 	"NEMBUS/utils"
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+// PermissionManageSystemRoles lets a user create system roles and change
+// them. System roles can never be deleted or deactivated.
+const PermissionManageSystemRoles = "roles.manage_system"
+
 type RolePermissionInput struct {
 	PermissionID int32
 	Scope        *string
@@ -31,9 +39,11 @@ func (uc *RoleUseCase) SetRepository(repo *repository.Queries) {
 	uc.repo = repo
 }
 
-// CreateRole creates a new role.
+// CreateRole creates a new role. Creating a system role needs
+// PermissionManageSystemRoles, see checkCanCreateRole.
 func (uc *RoleUseCase) CreateRole(
 	ctx context.Context,
+	viewerID *int32,
 	name string,
 	code string,
 	description *string,
@@ -51,6 +61,9 @@ func (uc *RoleUseCase) CreateRole(
 	if code == "" {
 		return utils.NewResponse(utils.CodeBadReq, "role code cannot be empty", nil)
 	}
+	if resp := checkCanCreateRole(ctx, uc.repo, viewerID, isSystemRole, nil); resp != nil {
+		return resp
+	}
 
 	var descText pgtype.Text
 	if description != nil && *description != "" {
@@ -149,9 +162,11 @@ func (uc *RoleUseCase) ListNonSystemRoles(ctx context.Context) *repository.Respo
 	return utils.NewResponse(utils.CodeOK, "non-system roles fetched successfully", roles)
 }
 
-// UpdateRole updates an existing role.
+// UpdateRole updates an existing role. Changing a system role needs
+// PermissionManageSystemRoles, and a system role stays active.
 func (uc *RoleUseCase) UpdateRole(
 	ctx context.Context,
+	viewerID *int32,
 	id int32,
 	name string,
 	description *string,
@@ -167,6 +182,9 @@ func (uc *RoleUseCase) UpdateRole(
 	if name == "" {
 		return utils.NewResponse(utils.CodeBadReq, "role name cannot be empty", nil)
 	}
+	if resp := uc.checkSystemRoleChange(ctx, viewerID, id, isActive); resp != nil {
+		return resp
+	}
 
 	var descText pgtype.Text
 	if description != nil && *description != "" {
@@ -188,10 +206,11 @@ func (uc *RoleUseCase) UpdateRole(
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "role updated successfully", role)
 }
 
-// DeleteRole deletes a role by ID.
+// DeleteRole deletes a role by ID. System roles cannot be deleted.
 func (uc *RoleUseCase) DeleteRole(ctx context.Context, id int32) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
@@ -199,16 +218,29 @@ func (uc *RoleUseCase) DeleteRole(ctx context.Context, id int32) *repository.Res
 	if id <= 0 {
 		return utils.NewResponse(utils.CodeBadReq, "invalid role id", nil)
 	}
+	role, err := uc.repo.GetRole(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "role not found", nil)
+	}
+	if role.IsSystemRole.Bool {
+		return utils.NewResponse(utils.CodeForbidden, "system roles cannot be deleted", nil)
+	}
 
 	if err := uc.repo.DeleteRole(ctx, id); err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "role deleted successfully", nil)
 }
 
+// AssignPermissionToRole grants permissions to a role. Like
+// UpdatePermissionMatrix it needs PermissionManage, and changing the
+// permissions of a system role, or granting guardedPermissions, needs
+// PermissionManageSystemRoles too.
 func (uc *RoleUseCase) AssignPermissionToRole(
 	ctx context.Context,
+	viewerID *int32,
 	roleID int32,
 	permissions []RolePermissionInput,
 ) *repository.Response {
@@ -218,15 +250,34 @@ func (uc *RoleUseCase) AssignPermissionToRole(
 	if roleID <= 0 {
 		return utils.NewResponse(utils.CodeBadReq, "invalid role id", nil)
 	}
-
-	var results []repository.RolePermission
-	for _, p := range permissions {
+	if resp := checkCanManagePermissions(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if resp := checkSystemRolePermissionChange(ctx, uc.repo, viewerID, roleID); resp != nil {
+		return resp
+	}
+	ids := make([]int32, len(permissions))
+	for i, p := range permissions {
 		if p.PermissionID <= 0 {
 			return utils.NewResponse(utils.CodeBadReq, "invalid permission id", nil)
 		}
+		ids[i] = p.PermissionID
+	}
+	codes, resp := permissionCodes(ctx, uc.repo, ids)
+	if resp != nil {
+		return resp
+	}
+	if resp := checkCanGrantPermissions(ctx, uc.repo, viewerID, codes); resp != nil {
+		return resp
+	}
 
+	var results []repository.RolePermission
+	for _, p := range permissions {
 		var scopeText pgtype.Text
 		if p.Scope != nil && *p.Scope != "" {
+			if !validScope(*p.Scope) {
+				return utils.NewResponse(utils.CodeBadReq, scopeError, nil)
+			}
 			scopeText = pgtype.Text{String: *p.Scope, Valid: true}
 		}
 
@@ -247,12 +298,16 @@ func (uc *RoleUseCase) AssignPermissionToRole(
 		results = append(results, rolePermission)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeCreated, "permissions assigned to role successfully", results)
 }
 
-// RemovePermissionFromRole removes a permission from a role.
+// RemovePermissionFromRole removes permissions from a role. Like
+// UpdatePermissionMatrix it needs PermissionManage, and changing the
+// permissions of a system role needs PermissionManageSystemRoles too.
 func (uc *RoleUseCase) RemovePermissionFromRole(
 	ctx context.Context,
+	viewerID *int32,
 	roleID int32,
 	permissionIDs []int32,
 ) *repository.Response {
@@ -265,6 +320,12 @@ func (uc *RoleUseCase) RemovePermissionFromRole(
 	if len(permissionIDs) == 0 {
 		return utils.NewResponse(utils.CodeBadReq, "permission ids are required", nil)
 	}
+	if resp := checkCanManagePermissions(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if resp := checkSystemRolePermissionChange(ctx, uc.repo, viewerID, roleID); resp != nil {
+		return resp
+	}
 
 	for _, permID := range permissionIDs {
 		if permID <= 0 {
@@ -280,6 +341,7 @@ func (uc *RoleUseCase) RemovePermissionFromRole(
 		}
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "permission removed from role successfully", nil)
 }
 
@@ -300,9 +362,11 @@ func (uc *RoleUseCase) GetRolePermissions(ctx context.Context, roleID int32) *re
 	return utils.NewResponse(utils.CodeOK, "role permissions fetched successfully", perms)
 }
 
-// ToggleRoleActive toggles the active flag for a role.
+// ToggleRoleActive toggles the active flag for a role. System roles follow
+// the rules of UpdateRole.
 func (uc *RoleUseCase) ToggleRoleActive(
 	ctx context.Context,
+	viewerID *int32,
 	id int32,
 	isActive bool,
 ) *repository.Response {
@@ -312,6 +376,9 @@ func (uc *RoleUseCase) ToggleRoleActive(
 	if id <= 0 {
 		return utils.NewResponse(utils.CodeBadReq, "invalid role id", nil)
 	}
+	if resp := uc.checkSystemRoleChange(ctx, viewerID, id, isActive); resp != nil {
+		return resp
+	}
 
 	role, err := uc.repo.ToggleRoleActive(ctx, repository.ToggleRoleActiveParams{
 		ID:       id,
@@ -321,6 +388,7 @@ func (uc *RoleUseCase) ToggleRoleActive(
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "role status updated successfully", role)
 }
 
@@ -350,3 +418,100 @@ func (uc *RoleUseCase) CheckRoleHasPermission(
 
 	return utils.NewResponse(utils.CodeOK, "role permission check completed", hasPerm)
 }
+
+// checkSystemRoleChange returns an error response when role id doesn't exist,
+// or is a system role that viewerID may not change or that would be
+// deactivated.
+func (uc *RoleUseCase) checkSystemRoleChange(ctx context.Context, viewerID *int32, id int32, isActive bool) *repository.Response {
+	role, err := uc.repo.GetRole(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "role not found", nil)
+	}
+	if !role.IsSystemRole.Bool {
+		return nil
+	}
+	if !isActive {
+		return utils.NewResponse(utils.CodeForbidden, "system roles cannot be deactivated", nil)
+	}
+	return checkCanManageSystemRoles(ctx, uc.repo, viewerID)
+}
+
+// checkSystemRolePermissionChange returns an error response when role id
+// doesn't exist, or is a system role whose permissions viewerID may not
+// change.
+func checkSystemRolePermissionChange(ctx context.Context, q *repository.Queries, viewerID *int32, id int32) *repository.Response {
+	role, err := q.GetRole(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "role not found", nil)
+	}
+	if !role.IsSystemRole.Bool {
+		return nil
+	}
+	return checkCanManageSystemRoles(ctx, q, viewerID)
+}
+
+// guardedPermissions guard users, roles, permissions and store access.
+// Granting one to a role, like changing a system role, needs
+// PermissionManageSystemRoles.
+var guardedPermissions = []string{
+	PermissionManage,
+	PermissionManageSystemRoles,
+	PermissionManageUsers,
+	PermissionAnyStore,
+	PermissionManageStoreAccess,
+}
+
+// checkCanCreateRole returns a forbidden response unless viewerID may create
+// a role, a system role or not, granting the permissions with codes. System
+// roles need PermissionManageSystemRoles, and so do roles granting
+// guardedPermissions, see checkCanGrantPermissions.
+func checkCanCreateRole(ctx context.Context, q *repository.Queries, viewerID *int32, isSystemRole bool, codes []string) *repository.Response {
+	if isSystemRole {
+		return checkCanManageSystemRoles(ctx, q, viewerID)
+	}
+	return checkCanGrantPermissions(ctx, q, viewerID, codes)
+}
+
+// checkCanGrantPermissions returns a forbidden response unless viewerID may
+// grant a role the permissions with codes: any of guardedPermissions needs
+// PermissionManageSystemRoles.
+func checkCanGrantPermissions(ctx context.Context, q *repository.Queries, viewerID *int32, codes []string) *repository.Response {
+	for _, code := range codes {
+		if slices.Contains(guardedPermissions, code) {
+			return checkCanManageSystemRoles(ctx, q, viewerID)
+		}
+	}
+	return nil
+}
+
+// permissionCodes returns the codes of the permissions with ids, or a not
+// found response when one doesn't exist.
+func permissionCodes(ctx context.Context, q *repository.Queries, ids []int32) ([]string, *repository.Response) {
+	codes := make([]string, 0, len(ids))
+	for _, id := range ids {
+		p, err := q.GetPermission(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, utils.NewResponse(utils.CodeNotFound, fmt.Sprintf("permission %d not found", id), nil)
+		}
+		if err != nil {
+			return nil, utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		codes = append(codes, p.Code)
+	}
+	return codes, nil
+}
+
+// checkCanManageSystemRoles returns a forbidden response unless viewerID
+// holds PermissionManageSystemRoles.
+func checkCanManageSystemRoles(ctx context.Context, q *repository.Queries, viewerID *int32) *repository.Response {
+	if viewerID != nil {
+		ok, err := q.CheckUserHasPermission(ctx, repository.CheckUserHasPermissionParams{UserID: *viewerID, Code: PermissionManageSystemRoles})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		if ok {
+			return nil
+		}
+	}
+	return utils.NewResponse(utils.CodeForbidden, "changing system roles requires the "+PermissionManageSystemRoles+" permission", nil)
+}
@@ -0,0 +1,255 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/pricing"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// SalesOrderUseCase creates and reads sales orders.
+type SalesOrderUseCase struct {
+	repo *repository.Queries
+}
+
+// NewSalesOrderUseCase creates a new sales order use case.
+func NewSalesOrderUseCase() *SalesOrderUseCase {
+	return &SalesOrderUseCase{}
+}
+
+// SetRepository injects repository per request
+func (uc *SalesOrderUseCase) SetRepository(repo *repository.Queries) {
+	uc.repo = repo
+}
+
+// OrderLineInput is one line of a sales or purchase order. UnitPrice is
+// optional on sales orders, where it defaults to the price list price.
+type OrderLineInput struct {
+	ProductID        int32
+	ProductVariantID *int32
+	UomID            *int32
+	Quantity         string
+	UnitPrice        string
+	Discount         string
+}
+
+// SalesOrderInput is the input for CreateSalesOrder.
+type SalesOrderInput struct {
+	OrganizationID int32
+	StoreID        int32
+	CustomerID     *int32
+	PriceListID    *int32
+	OrderDate      *time.Time
+	DeliveryDate   *time.Time
+	CreatedBy      *int32
+	Lines          []OrderLineInput
+}
+
+// OrderResult is returned when a sales or purchase order is created.
+type OrderResult struct {
+	ID     int32  `json:"id"`
+	Number string `json:"number"`
+	Status string `json:"status"`
+	DocumentTotals
+}
+
+// CreateSalesOrder creates a draft sales order, pricing lines without a unit
+// price through the price resolver (or from the given price list only) on
+// the order date and taxing them per product tax category.
+func (uc *SalesOrderUseCase) CreateSalesOrder(ctx context.Context, in *SalesOrderInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if len(in.Lines) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "order has no lines", nil)
+	}
+	orgID, resp := activeOrganization(ctx, in.OrganizationID)
+	if resp != nil {
+		return resp
+	}
+	in.OrganizationID = orgID
+	org, err := uc.repo.GetOrganization(ctx, in.OrganizationID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "organization not found", nil)
+	}
+	if store, err := uc.repo.GetStore(ctx, in.StoreID); err != nil || store.OrganizationID != org.ID {
+		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+	}
+	var customer *repository.Customer
+	if in.CustomerID != nil {
+		c, err := uc.repo.GetCustomer(ctx, *in.CustomerID)
+		if err != nil || c.OrganizationID != org.ID {
+			return utils.NewResponse(utils.CodeNotFound, "customer not found", nil)
+		}
+		customer = &c
+	}
+
+	orderDate := time.Now()
+	if in.OrderDate != nil {
+		orderDate = *in.OrderDate
+	}
+	prices, err := newPriceResolver(ctx, uc.repo, saleContext{
+		storeID:     in.StoreID,
+		customer:    customer,
+		date:        orderDate,
+		priceListID: in.PriceListID,
+	})
+	if err != nil {
+		var bad *documentInputError
+		if errors.As(err, &bad) {
+			return utils.NewResponse(utils.CodeNotFound, bad.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	opt, err := documentTaxOptions(org, customer)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	lines := make([]documentLine, len(in.Lines))
+	priced := make([]*pricing.Result, len(in.Lines))
+	var priceListID int32
+	if in.PriceListID != nil {
+		priceListID = *in.PriceListID
+	}
+	for i, l := range in.Lines {
+		product, err := uc.repo.GetProduct(ctx, l.ProductID)
+		if err != nil || product.OrganizationID != org.ID {
+			return utils.NewResponse(utils.CodeNotFound, "product not found", nil)
+		}
+		if !product.IsSellable.Bool {
+			return utils.NewResponse(utils.CodeBadReq, "product "+product.Sku+" is not sellable", nil)
+		}
+		qty, err := parseQuantity(product, l.Quantity)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		discount, err := parseAmount("discount", l.Discount)
+		if err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
+		line := documentLine{
+			product:   product,
+			variantID: optionalInt4(l.ProductVariantID),
+			uomID:     optionalInt4(l.UomID),
+			quantity:  qty,
+			discount:  discount,
+		}
+		if l.UnitPrice != "" {
+			if line.unitPrice, err = parseAmount("unit price", l.UnitPrice); err != nil {
+				return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+			}
+		} else {
+			price, err := prices.resolve(ctx, product, line.variantID.Int32, line.uomID.Int32, qty)
+			if errors.Is(err, pricing.ErrNoPrice) {
+				return utils.NewResponse(utils.CodeBadReq, "unit price is required for product "+product.Sku+": "+strings.Join(price.Explanation, "; "), nil)
+			}
+			if err != nil {
+				return utils.NewResponse(utils.CodeError, err.Error(), nil)
+			}
+			line.unitPrice = price.Price
+			if !line.uomID.Valid && price.UomID != 0 {
+				line.uomID = pgtype.Int4{Int32: price.UomID, Valid: true}
+			}
+			if priceListID == 0 {
+				priceListID = price.PriceListID
+			}
+			priced[i] = price
+		}
+		lines[i] = line
+	}
+
+	res, err := calculateDocumentTax(ctx, uc.repo, lines, opt)
+	if err != nil {
+		var bad *documentInputError
+		if errors.As(err, &bad) {
+			return utils.NewResponse(utils.CodeBadReq, bad.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	now := time.Now()
+	totals := newDocumentTotals(res, opt)
+	arg := repository.CreateSalesOrderHeaderParams{
+		OrderNumber:    documentNumber("SO", now),
+		OrganizationID: org.ID,
+		CustomerID:     optionalInt4(in.CustomerID),
+		StoreID:        in.StoreID,
+		OrderDate:      pgtype.Date{Time: orderDate, Valid: true},
+		Status:         pgtype.Text{String: "draft", Valid: true},
+		Subtotal:       utils.RatToNumeric(res.Subtotal, 2),
+		TaxAmount:      utils.RatToNumeric(res.Tax, 2),
+		DiscountAmount: utils.RatToNumeric(res.Discount, 2),
+		TotalAmount:    utils.RatToNumeric(res.Total, 2),
+		CreatedBy:      optionalInt4(in.CreatedBy),
+		Metadata:       documentTaxMetadata(totals),
+	}
+	if in.DeliveryDate != nil {
+		arg.DeliveryDate = pgtype.Date{Time: *in.DeliveryDate, Valid: true}
+	}
+	if priceListID != 0 {
+		arg.PriceListID = pgtype.Int4{Int32: priceListID, Valid: true}
+	}
+
+	result := &OrderResult{Number: arg.OrderNumber, DocumentTotals: totals}
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		header, err := q.CreateSalesOrderHeader(ctx, arg)
+		if err != nil {
+			return err
+		}
+		result.ID = header.ID
+		result.Status = header.Status.String
+		for i, l := range lines {
+			lr := res.Lines[i]
+			if err := q.CreateSalesOrderLine(ctx, repository.CreateSalesOrderLineParams{
+				SalesOrderID:     header.ID,
+				LineNumber:       int32(i + 1),
+				ProductID:        l.product.ID,
+				ProductVariantID: l.variantID,
+				Quantity:         utils.RatToNumeric(l.quantity, 3),
+				UomID:            l.uomID,
+				UnitPrice:        utils.RatToNumeric(l.unitPrice, 4),
+				DiscountAmount:   utils.RatToNumeric(lr.Discount, 2),
+				TaxAmount:        utils.RatToNumeric(lr.Tax, 2),
+				LineTotal:        utils.RatToNumeric(lr.LineTotal, 2),
+				Metadata:         priceMetadata(priced[i]),
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeCreated, "sales order created", result)
+}
+
+// GetSalesOrder returns a sales order with its lines.
+func (uc *SalesOrderUseCase) GetSalesOrder(ctx context.Context, id int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	rows, err := uc.repo.GetSalesOrderFull(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if len(rows) == 0 || !inActiveOrganization(ctx, rows[0].OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "sales order not found", nil)
+	}
+	scope, resp := dataScope(ctx, uc.repo, PermissionViewSalesOrders)
+	if resp != nil {
+		return resp
+	}
+	if !scope.AllowsOwned(rows[0].StoreID, rows[0].CreatedBy) {
+		return utils.NewResponse(utils.CodeNotFound, "sales order not found", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "sales order fetched successfully", rows)
+}
@@ -0,0 +1,87 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+
+	"NEMBUS/internal/inventory"
+	"NEMBUS/internal/repository"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// serialCapture collects the serial numbers captured on one document so the
+// same unit cannot appear on two lines.
+type serialCapture map[string]bool
+
+// capture checks the serial numbers given for qty units of a serialized
+// product: one per unit, none repeated on the document.
+func (seen serialCapture) capture(product repository.Product, serials []string, qty *big.Rat) ([]string, error) {
+	out, err := inventory.CheckSerials(serials, qty)
+	if err != nil {
+		return nil, documentInputErrorf("product %s: %s", product.Sku, err.Error())
+	}
+	for _, s := range out {
+		if seen[s] {
+			return nil, documentInputErrorf("serial number %s is repeated", s)
+		}
+		seen[s] = true
+	}
+	return out, nil
+}
+
+// lookupSerials loads the units with the given serial numbers and checks
+// they are units of the product and variant, are in the store when storeID
+// is set, and may move to status to (when set).
+func lookupSerials(ctx context.Context, q *repository.Queries, product repository.Product, variantID pgtype.Int4, storeID int32, serials []string, to string) ([]repository.ProductSerialNumber, error) {
+	units := make([]repository.ProductSerialNumber, len(serials))
+	for i, s := range serials {
+		u, err := q.GetProductSerialNumberBySerial(ctx, s)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, documentInputErrorf("serial number %s not found", s)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if u.ProductID != product.ID || u.ProductVariantID != variantID {
+			return nil, documentInputErrorf("serial number %s is not a unit of product %s", s, product.Sku)
+		}
+		if to != "" {
+			if err := inventory.CheckSerialTransition(u.Status.String, to); err != nil {
+				return nil, documentInputErrorf("serial number %s: %s", s, err.Error())
+			}
+		}
+		if storeID != 0 && u.CurrentStoreID.Int32 != storeID {
+			return nil, documentInputErrorf("serial number %s is not in this store", s)
+		}
+		units[i] = u
+	}
+	return units, nil
+}
+
+// moveSerial changes a unit's status and store. It fails with
+// documentInputError when the unit changed since it was looked up.
+func moveSerial(ctx context.Context, q *repository.Queries, u repository.ProductSerialNumber, to string, storeID pgtype.Int4) error {
+	_, err := q.MoveSerialNumber(ctx, repository.MoveSerialNumberParams{
+		Status:     pgtype.Text{String: to, Valid: true},
+		StoreID:    storeID,
+		ID:         u.ID,
+		FromStatus: u.Status,
+	})
+	if errors.Is(err, pgx.ErrNoRows) {
+		return documentInputErrorf("serial number %s changed while being processed", u.SerialNumber)
+	}
+	return err
+}
+
+// lineSerials returns the serial numbers of a line given either as a single
+// serial_number or as a list.
+func lineSerials(single string, list []string) []string {
+	if strings.TrimSpace(single) == "" {
+		return list
+	}
+	return append([]string{single}, list...)
+}
@@ -29,24 +29,26 @@ func (uc *StoreUseCase) SetRepository(repo *repository.Queries) {
 // helpers
 // --------------------------------------------------
 
+// getOrganizationID returns the active organization of the request
 func (uc *StoreUseCase) getOrganizationID(ctx context.Context) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
-
-	orgs, err := uc.repo.ListOrganizations(ctx, repository.ListOrganizationsParams{
-		Limit:    1,
-		Offset:   0,
-		IsActive: pgtype.Bool{Bool: true, Valid: true},
-	})
-	if err != nil {
-		return utils.NewResponse(utils.CodeError, err.Error(), nil)
-	}
-	if len(orgs) == 0 {
-		return utils.NewResponse(utils.CodeNotFound, "no active organization found", nil)
+	orgID, resp := activeOrganization(ctx, 0)
+	if resp != nil {
+		return resp
 	}
+	return utils.NewResponse(utils.CodeOK, "organization found", orgID)
+}
 
-	return utils.NewResponse(utils.CodeOK, "organization found", orgs[0].ID)
+// checkStore returns a not found response unless the store exists in the
+// active organization
+func (uc *StoreUseCase) checkStore(ctx context.Context, storeID int32) *repository.Response {
+	store, err := uc.repo.GetStore(ctx, storeID)
+	if err != nil || !inActiveOrganization(ctx, store.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+	}
+	return nil
 }
 
 // --------------------------------------------------
@@ -85,6 +87,9 @@ func (uc *StoreUseCase) CreateStore(
 	// Optional fields
 	var parentID pgtype.Int4
 	if parentStoreID != nil {
+		if resp := uc.checkStore(ctx, *parentStoreID); resp != nil {
+			return utils.NewResponse(utils.CodeBadReq, "parent store not found", nil)
+		}
 		parentID = pgtype.Int4{Int32: *parentStoreID, Valid: true}
 	}
 
@@ -143,6 +148,12 @@ func (uc *StoreUseCase) GetStore(ctx context.Context, id string) *repository.Res
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
+	if !inActiveOrganization(ctx, store.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+	}
+	if resp := checkStoreInScope(ctx, uc.repo, PermissionViewStores, store.ID); resp != nil {
+		return resp
+	}
 
 	return utils.NewResponse(utils.CodeOK, "store fetched successfully", store)
 }
@@ -167,6 +178,10 @@ func (uc *StoreUseCase) ListStores(
 		return orgResp
 	}
 	orgID := orgResp.Data.(int32)
+	scope, resp := dataScope(ctx, uc.repo, PermissionViewStores)
+	if resp != nil {
+		return resp
+	}
 
 	var activeBool pgtype.Bool
 	if isActive != nil {
@@ -184,6 +199,7 @@ func (uc *StoreUseCase) ListStores(
 		Offset:         offset,
 		IsActive:       activeBool,
 		StoreType:      storeTypeText,
+		StoreIds:       scope.StoreIDs(),
 	})
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
@@ -216,6 +232,9 @@ func (uc *StoreUseCase) UpdateStore(
 	if err != nil {
 		return utils.NewResponse(utils.CodeBadReq, "invalid store id", nil)
 	}
+	if resp := uc.checkStore(ctx, int32(storeID)); resp != nil {
+		return resp
+	}
 
 	var nameText pgtype.Text
 	if name != nil {
@@ -284,6 +303,9 @@ func (uc *StoreUseCase) DeleteStore(ctx context.Context, id string) *repository.
 	if err != nil {
 		return utils.NewResponse(utils.CodeBadReq, "invalid store id", nil)
 	}
+	if resp := uc.checkStore(ctx, int32(storeID)); resp != nil {
+		return resp
+	}
 
 	if err := uc.repo.DeleteStore(ctx, int32(storeID)); err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
@@ -306,12 +328,17 @@ func (uc *StoreUseCase) ListPOSEnabledStores(ctx context.Context) *repository.Re
 	}
 	orgID := orgResp.Data.(int32)
 
+	scope, resp := dataScope(ctx, uc.repo, PermissionViewStores)
+	if resp != nil {
+		return resp
+	}
+
 	stores, err := uc.repo.ListPOSEnabledStores(ctx, orgID)
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
-	return utils.NewResponse(utils.CodeOK, "POS enabled stores fetched successfully", stores)
+	return utils.NewResponse(utils.CodeOK, "POS enabled stores fetched successfully", scope.FilterStores(stores))
 }
 
 // --------------------------------------------------
@@ -328,12 +355,17 @@ func (uc *StoreUseCase) ListWarehouseStores(ctx context.Context) *repository.Res
 	}
 	orgID := orgResp.Data.(int32)
 
+	scope, resp := dataScope(ctx, uc.repo, PermissionViewStores)
+	if resp != nil {
+		return resp
+	}
+
 	stores, err := uc.repo.ListWarehouseStores(ctx, orgID)
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
-	return utils.NewResponse(utils.CodeOK, "warehouse stores fetched successfully", stores)
+	return utils.NewResponse(utils.CodeOK, "warehouse stores fetched successfully", scope.FilterStores(stores))
 }
 
 // --------------------------------------------------
@@ -343,6 +375,13 @@ func (uc *StoreUseCase) ListStoresByParent(ctx context.Context, parentStoreID in
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
+	if resp := uc.checkStore(ctx, parentStoreID); resp != nil {
+		return resp
+	}
+	scope, resp := dataScope(ctx, uc.repo, PermissionViewStores)
+	if resp != nil {
+		return resp
+	}
 
 	var activeBool pgtype.Bool
 	if isActive != nil {
@@ -357,7 +396,7 @@ func (uc *StoreUseCase) ListStoresByParent(ctx context.Context, parentStoreID in
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
-	return utils.NewResponse(utils.CodeOK, "stores by parent fetched successfully", stores)
+	return utils.NewResponse(utils.CodeOK, "stores by parent fetched successfully", scope.FilterStores(stores))
 }
 
 // --------------------------------------------------
@@ -367,6 +406,12 @@ func (uc *StoreUseCase) GetStorageLocationHierarchy(ctx context.Context, storeID
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
+	if resp := uc.checkStore(ctx, storeID); resp != nil {
+		return resp
+	}
+	if resp := checkStoreInScope(ctx, uc.repo, PermissionViewStores, storeID); resp != nil {
+		return resp
+	}
 
 	var filter interface{}
 	if filterIsActive != nil {
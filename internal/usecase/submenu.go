@@ -69,6 +69,7 @@ func (uc *SubmenuUseCase) CreateSubmenu(
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeCreated, "submenu created successfully", submenu)
 }
 
@@ -204,6 +205,7 @@ func (uc *SubmenuUseCase) UpdateSubmenu(
 		return utils.NewResponse(utils.CodeNotFound, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "submenu updated successfully", submenu)
 }
 
@@ -222,6 +224,7 @@ func (uc *SubmenuUseCase) ToggleSubmenuActive(ctx context.Context, id int32, isA
 		return utils.NewResponse(utils.CodeNotFound, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "submenu status updated successfully", submenu)
 }
 
@@ -236,5 +239,159 @@ func (uc *SubmenuUseCase) DeleteSubmenu(ctx context.Context, id int32) *reposito
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
 
+	invalidateNavigation(ctx)
 	return utils.NewResponse(utils.CodeOK, "submenu deleted successfully", nil)
 }
+
+// ReorderSubmenus sets the order of the submenus of a menu under a parent
+// submenu (the top-level submenus when parentSubmenuID is nil). submenuIDs
+// must list every one of them exactly once; they get display orders 1, 2,
+// 3, ... in that order.
+func (uc *SubmenuUseCase) ReorderSubmenus(ctx context.Context, menuID int32, parentSubmenuID *int32, submenuIDs []int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if menuID <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "menu id is required", nil)
+	}
+	if len(submenuIDs) == 0 {
+		return utils.NewResponse(utils.CodeBadReq, "submenu ids are required", nil)
+	}
+
+	var submenus []repository.Submenu
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		arg := repository.ListSubmenuSiblingsParams{MenuID: menuID, ParentSubmenuID: toPgInt4(parentSubmenuID)}
+		siblings, err := q.ListSubmenuSiblings(ctx, arg)
+		if err != nil {
+			return err
+		}
+		ids := make([]int32, len(siblings))
+		for i, s := range siblings {
+			ids[i] = s.ID
+		}
+		if !sameIDs(ids, submenuIDs) {
+			return documentInputErrorf("submenu ids must list every submenu under the parent exactly once")
+		}
+		if err := renumberSubmenus(ctx, q, submenuIDs); err != nil {
+			return err
+		}
+		submenus, err = q.ListSubmenuSiblings(ctx, arg)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "submenus reordered successfully", submenus)
+}
+
+// MoveSubmenu moves a submenu with its descendants to a menu (its own when
+// menuID is nil) under a parent submenu of that menu, or to the top level
+// when parentSubmenuID is nil, at the 1-based position among its new
+// siblings (last when nil). A submenu cannot move under itself or its
+// descendants. The display orders of the old and new siblings are
+// renumbered.
+func (uc *SubmenuUseCase) MoveSubmenu(ctx context.Context, id int32, menuID *int32, parentSubmenuID *int32, position *int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if position != nil && *position < 1 {
+		return utils.NewResponse(utils.CodeBadReq, "position starts at 1", nil)
+	}
+	submenu, err := uc.repo.GetSubmenu(ctx, id)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "submenu not found", nil)
+	}
+	target := submenu.MenuID
+	if menuID != nil {
+		if _, err := uc.repo.GetMenu(ctx, *menuID); err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "menu not found", nil)
+		}
+		target = *menuID
+	}
+	if parentSubmenuID != nil {
+		parent, err := uc.repo.GetSubmenu(ctx, *parentSubmenuID)
+		if err != nil {
+			return utils.NewResponse(utils.CodeNotFound, "parent submenu not found", nil)
+		}
+		if parent.MenuID != target {
+			return utils.NewResponse(utils.CodeBadReq, "parent submenu belongs to another menu", nil)
+		}
+	}
+
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		if parentSubmenuID != nil {
+			subtree, err := q.ListSubmenuSubtreeIDs(ctx, id)
+			if err != nil {
+				return err
+			}
+			for _, sub := range subtree {
+				if sub == *parentSubmenuID {
+					return documentInputErrorf("submenu %d is submenu %d or one of its child submenus", *parentSubmenuID, id)
+				}
+			}
+		}
+		moved, err := q.SetSubmenuParent(ctx, repository.SetSubmenuParentParams{
+			ID:              id,
+			MenuID:          target,
+			ParentSubmenuID: toPgInt4(parentSubmenuID),
+		})
+		if err != nil {
+			return err
+		}
+		if moved.MenuID != submenu.MenuID {
+			if _, err := q.SetSubmenuSubtreeMenu(ctx, repository.SetSubmenuSubtreeMenuParams{
+				ID:     pgtype.Int4{Int32: id, Valid: true},
+				MenuID: moved.MenuID,
+			}); err != nil {
+				return err
+			}
+		}
+		siblings, err := q.ListSubmenuSiblings(ctx, repository.ListSubmenuSiblingsParams{MenuID: moved.MenuID, ParentSubmenuID: moved.ParentSubmenuID})
+		if err != nil {
+			return err
+		}
+		ids := make([]int32, len(siblings))
+		for i, s := range siblings {
+			ids[i] = s.ID
+		}
+		if err := renumberSubmenus(ctx, q, placeAt(ids, id, position)); err != nil {
+			return err
+		}
+		if moved.MenuID != submenu.MenuID || moved.ParentSubmenuID != submenu.ParentSubmenuID {
+			old, err := q.ListSubmenuSiblings(ctx, repository.ListSubmenuSiblingsParams{MenuID: submenu.MenuID, ParentSubmenuID: submenu.ParentSubmenuID})
+			if err != nil {
+				return err
+			}
+			ids := make([]int32, len(old))
+			for i, s := range old {
+				ids[i] = s.ID
+			}
+			if err := renumberSubmenus(ctx, q, ids); err != nil {
+				return err
+			}
+		}
+		submenu, err = q.GetSubmenu(ctx, id)
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "submenu moved successfully", submenu)
+}
+
+// renumberSubmenus gives the submenus display orders 1, 2, 3, ... in order.
+func renumberSubmenus(ctx context.Context, q *repository.Queries, ids []int32) error {
+	for i, id := range ids {
+		if err := q.SetSubmenuDisplayOrder(ctx, repository.SetSubmenuDisplayOrderParams{
+			ID:           id,
+			DisplayOrder: pgtype.Int4{Int32: int32(i + 1), Valid: true},
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PermissionManageUsers lets a user change other users of the active
+// organization, activate and deactivate them, reset their passwords and
+// revoke their roles.
+const PermissionManageUsers = "users.manage"
+
+// UserAccount is a user without their password hash.
+type UserAccount struct {
+	ID             int32            `json:"id"`
+	OrganizationID int32            `json:"organization_id"`
+	Username       string           `json:"username"`
+	Email          string           `json:"email"`
+	FirstName      pgtype.Text      `json:"first_name"`
+	LastName       pgtype.Text      `json:"last_name"`
+	EmployeeCode   pgtype.Text      `json:"employee_code"`
+	IsActive       pgtype.Bool      `json:"is_active"`
+	Metadata       []byte           `json:"metadata"`
+	CreatedAt      pgtype.Timestamp `json:"created_at"`
+	UpdatedAt      pgtype.Timestamp `json:"updated_at"`
+}
+
+// UserDetail is a user with their roles and store access.
+type UserDetail struct {
+	UserAccount
+	PasswordChangedAt pgtype.Timestamp                    `json:"password_changed_at"`
+	Roles             []repository.Role                   `json:"roles"`
+	Stores            []repository.ListUserStoreAccessRow `json:"stores"`
+}
+
+// UserFilter narrows SearchUsers; nil fields don't filter.
+type UserFilter struct {
+	Search   *string
+	IsActive *bool
+	RoleID   *int32
+	StoreID  *int32
+}
+
+// UserPage is a page of SearchUsers with the number of matching users.
+type UserPage struct {
+	Users  []UserAccount `json:"users"`
+	Total  int64         `json:"total"`
+	Limit  int32         `json:"limit"`
+	Offset int32         `json:"offset"`
+}
+
+// UserProfileInput is the input for UpdateUserProfile; nil fields are kept.
+type UserProfileInput struct {
+	Email        *string
+	FirstName    *string
+	LastName     *string
+	EmployeeCode *string
+	Metadata     []byte
+}
+
+// SearchUsers lists the users of the active organization matching filter,
+// a page at a time. It needs PermissionViewAnyUser.
+func (uc *UserUseCase) SearchUsers(ctx context.Context, viewerID *int32, filter UserFilter, limit, offset int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkUserPermission(ctx, uc.repo, viewerID, PermissionViewAnyUser, "listing users"); resp != nil {
+		return resp
+	}
+	orgID, resp := activeOrganization(ctx, 0)
+	if resp != nil {
+		return resp
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	params := repository.FilterUsersParams{
+		OrganizationID: orgID,
+		IsActive:       optionalBool(filter.IsActive),
+		RoleID:         optionalInt4(filter.RoleID),
+		StoreID:        optionalInt4(filter.StoreID),
+		Limit:          limit,
+		Offset:         offset,
+	}
+	if filter.Search != nil && *filter.Search != "" {
+		params.Search = pgtype.Text{String: *filter.Search, Valid: true}
+	}
+	rows, err := uc.repo.FilterUsers(ctx, params)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	page := UserPage{Users: make([]UserAccount, 0, len(rows)), Limit: limit, Offset: offset}
+	for _, r := range rows {
+		page.Total = r.TotalCount
+		page.Users = append(page.Users, UserAccount{
+			ID:             r.ID,
+			OrganizationID: r.OrganizationID,
+			Username:       r.Username,
+			Email:          r.Email,
+			FirstName:      r.FirstName,
+			LastName:       r.LastName,
+			EmployeeCode:   r.EmployeeCode,
+			IsActive:       r.IsActive,
+			Metadata:       r.Metadata,
+			CreatedAt:      r.CreatedAt,
+			UpdatedAt:      r.UpdatedAt,
+		})
+	}
+	return utils.NewResponse(utils.CodeOK, "users fetched successfully", page)
+}
+
+// GetUserDetail returns a user with their roles and store access. viewerID
+// is the signed-in user, see checkUserVisible.
+func (uc *UserUseCase) GetUserDetail(ctx context.Context, viewerID *int32, userID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkUserVisible(ctx, uc.repo, viewerID, userID); resp != nil {
+		return resp
+	}
+
+	user, err := uc.repo.GetUser(ctx, userID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
+	}
+	detail := UserDetail{UserAccount: userAccount(user)}
+	if detail.Roles, err = uc.repo.GetUserRoles(ctx, userID); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if detail.Stores, err = uc.repo.ListUserStoreAccess(ctx, userID); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	security, err := uc.repo.GetUserSecurity(ctx, userID)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	detail.PasswordChangedAt = security.PasswordChangedAt
+	return utils.NewResponse(utils.CodeOK, "user fetched successfully", detail)
+}
+
+// UpdateUserProfile changes the email, name, employee code and metadata of a
+// user of the active organization. It needs PermissionManageUsers.
+func (uc *UserUseCase) UpdateUserProfile(ctx context.Context, viewerID *int32, userID int32, in UserProfileInput) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkCanManageUser(ctx, viewerID, userID); resp != nil {
+		return resp
+	}
+	if in.Email != nil && *in.Email == "" {
+		return utils.NewResponse(utils.CodeBadReq, "email cannot be empty", nil)
+	}
+	if in.FirstName != nil && *in.FirstName == "" {
+		return utils.NewResponse(utils.CodeBadReq, "first name cannot be empty", nil)
+	}
+
+	user, err := uc.repo.UpdateUser(ctx, repository.UpdateUserParams{
+		ID:           userID,
+		Email:        toPgText(in.Email),
+		FirstName:    toPgText(in.FirstName),
+		LastName:     toPgText(in.LastName),
+		EmployeeCode: toPgText(in.EmployeeCode),
+		Metadata:     in.Metadata,
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "user updated successfully", userAccount(user))
+}
+
+// SetUserActive activates or deactivates a user of the active organization.
+// Deactivating revokes the user's sessions; users cannot deactivate
+// themselves. It needs PermissionManageUsers.
+func (uc *UserUseCase) SetUserActive(ctx context.Context, viewerID *int32, userID int32, isActive bool) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkCanManageUser(ctx, viewerID, userID); resp != nil {
+		return resp
+	}
+	if !isActive && *viewerID == userID {
+		return utils.NewResponse(utils.CodeBadReq, "you cannot deactivate yourself", nil)
+	}
+
+	var user repository.User
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		user, err = q.UpdateUser(ctx, repository.UpdateUserParams{
+			ID:       userID,
+			IsActive: pgtype.Bool{Bool: isActive, Valid: true},
+		})
+		if err != nil {
+			return err
+		}
+		if isActive {
+			return nil
+		}
+		return revokeSessions(ctx, q, userID)
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if isActive {
+		return utils.NewResponse(utils.CodeOK, "user activated successfully", userAccount(user))
+	}
+	return utils.NewResponse(utils.CodeOK, "user deactivated successfully", userAccount(user))
+}
+
+// ResetUserPassword sets a new password for a user of the active
+// organization and revokes their sessions. It needs PermissionManageUsers.
+func (uc *UserUseCase) ResetUserPassword(ctx context.Context, viewerID *int32, userID int32, newPassword string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkCanManageUser(ctx, viewerID, userID); resp != nil {
+		return resp
+	}
+	user, err := uc.repo.GetUser(ctx, userID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
+	}
+	if resp := setPassword(ctx, uc.repo, uc.passwordPolicy, user, newPassword); resp != nil {
+		return resp
+	}
+	return utils.NewResponse(utils.CodeOK, "password reset successfully", nil)
+}
+
+// RevokeRoleFromUser takes a role away from a user of the active
+// organization. It needs PermissionManageUsers, and revoking a system role
+// needs PermissionManageSystemRoles too, like assigning one.
+func (uc *UserUseCase) RevokeRoleFromUser(ctx context.Context, viewerID *int32, userID, roleID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkCanManageUser(ctx, viewerID, userID); resp != nil {
+		return resp
+	}
+	if roleID <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "invalid role id", nil)
+	}
+	has, err := uc.repo.CheckUserHasRole(ctx, repository.CheckUserHasRoleParams{UserID: userID, RoleID: roleID})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if !has {
+		return utils.NewResponse(utils.CodeNotFound, "user does not have this role", nil)
+	}
+	role, err := uc.repo.GetRole(ctx, roleID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if role.IsSystemRole.Bool {
+		if resp := checkCanManageSystemRoles(ctx, uc.repo, viewerID); resp != nil {
+			return resp
+		}
+	}
+	if err := uc.repo.RevokeRoleFromUser(ctx, repository.RevokeRoleFromUserParams{UserID: userID, RoleID: roleID}); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	invalidateNavigation(ctx)
+	return utils.NewResponse(utils.CodeOK, "role revoked successfully", nil)
+}
+
+// ListUserLoginAudit lists the login attempts recorded for a user of the
+// active organization, latest first. It needs PermissionManageUsers.
+// Attempts at login names matching no user aren't tied to a user.
+func (uc *UserUseCase) ListUserLoginAudit(ctx context.Context, viewerID *int32, userID, limit, offset int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := uc.checkCanManageUser(ctx, viewerID, userID); resp != nil {
+		return resp
+	}
+	if limit <= 0 {
+		limit = 100
+	}
+	if offset < 0 {
+		offset = 0
+	}
+
+	attempts, err := uc.repo.ListUserLoginAudit(ctx, repository.ListUserLoginAuditParams{UserID: userID, Limit: limit, Offset: offset})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "login audit fetched successfully", attempts)
+}
+
+// checkCanManageUser returns an error response unless viewerID holds
+// PermissionManageUsers and userID is a user of the active organization.
+func (uc *UserUseCase) checkCanManageUser(ctx context.Context, viewerID *int32, userID int32) *repository.Response {
+	if userID <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "invalid user id", nil)
+	}
+	if resp := checkUserPermission(ctx, uc.repo, viewerID, PermissionManageUsers, "managing users"); resp != nil {
+		return resp
+	}
+	if user, err := uc.repo.GetUser(ctx, userID); err != nil || !inActiveOrganization(ctx, user.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
+	}
+	return nil
+}
+
+// checkUserPermission returns a forbidden response unless viewerID holds
+// permission; action names what needs it.
+func checkUserPermission(ctx context.Context, q *repository.Queries, viewerID *int32, permission, action string) *repository.Response {
+	if viewerID != nil {
+		ok, err := q.CheckUserHasPermission(ctx, repository.CheckUserHasPermissionParams{UserID: *viewerID, Code: permission})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		if ok {
+			return nil
+		}
+	}
+	return utils.NewResponse(utils.CodeForbidden, action+" requires the "+permission+" permission", nil)
+}
+
+// revokeSessions refuses the tokens issued to userID until now. Token issue
+// times, like timestamps, have microseconds, so the revocation time is
+// truncated to match.
+func revokeSessions(ctx context.Context, q *repository.Queries, userID int32) error {
+	return q.RevokeUserSessions(ctx, repository.RevokeUserSessionsParams{
+		UserID:    userID,
+		RevokedAt: pgtype.Timestamp{Time: time.Now().UTC().Truncate(time.Microsecond), Valid: true},
+	})
+}
+
+// userAccount drops the password hash of a user.
+func userAccount(u repository.User) UserAccount {
+	return UserAccount{
+		ID:             u.ID,
+		OrganizationID: u.OrganizationID,
+		Username:       u.Username,
+		Email:          u.Email,
+		FirstName:      u.FirstName,
+		LastName:       u.LastName,
+		EmployeeCode:   u.EmployeeCode,
+		IsActive:       u.IsActive,
+		Metadata:       u.Metadata,
+		CreatedAt:      u.CreatedAt,
+		UpdatedAt:      u.UpdatedAt,
+	}
+}
@@ -0,0 +1,197 @@
+package usecase
+
+import (
+	"context"
+
+	"NEMBUS/internal/middleware"
+	"NEMBUS/internal/repository"
+	"NEMBUS/utils"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// PermissionManageStoreAccess lets a user grant and revoke other users'
+// access to stores. PermissionAnyStore, checked by
+// middleware.StoreAccessMiddleware, lets a user work in every store.
+const (
+	PermissionManageStoreAccess = "stores.access_manage"
+	PermissionAnyStore          = middleware.PermissionAnyStore
+)
+
+// ListUserStoreAccess lists the stores userID has access to, primary first.
+// Users read their own; another user's needs PermissionManageStoreAccess.
+func (uc *UserUseCase) ListUserStoreAccess(ctx context.Context, viewerID *int32, userID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if viewerID == nil || *viewerID != userID {
+		if resp := checkCanManageStoreAccess(ctx, uc.repo, viewerID); resp != nil {
+			return resp
+		}
+	}
+	if _, err := uc.repo.GetUser(ctx, userID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
+	}
+
+	access, err := uc.repo.ListUserStoreAccess(ctx, userID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "store access fetched successfully", access)
+}
+
+// GrantStoreAccess gives userID access to a store of the active
+// organization, or updates the access they have. A primary store is the one
+// selected at login; making one primary clears the flag on the others.
+func (uc *UserUseCase) GrantStoreAccess(ctx context.Context, viewerID *int32, userID, storeID int32, isPrimary bool, metadata []byte) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkCanManageStoreAccess(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if resp := uc.checkStoreAccessTarget(ctx, userID, storeID); resp != nil {
+		return resp
+	}
+
+	var access repository.UserStoreAccess
+	err := uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		if isPrimary {
+			if err := q.SetUserPrimaryStore(ctx, userID); err != nil {
+				return err
+			}
+		}
+		has, err := q.CheckUserHasStoreAccess(ctx, repository.CheckUserHasStoreAccessParams{UserID: userID, StoreID: storeID})
+		if err != nil {
+			return err
+		}
+		if has {
+			access, err = q.UpdateUserStoreAccess(ctx, repository.UpdateUserStoreAccessParams{
+				UserID:    userID,
+				StoreID:   storeID,
+				IsPrimary: pgtype.Bool{Bool: isPrimary, Valid: true},
+				Metadata:  metadata,
+			})
+			return err
+		}
+		if metadata == nil {
+			metadata = []byte("{}")
+		}
+		access, err = q.GrantStoreAccessToUser(ctx, repository.GrantStoreAccessToUserParams{
+			UserID:    userID,
+			StoreID:   storeID,
+			IsPrimary: pgtype.Bool{Bool: isPrimary, Valid: true},
+			Metadata:  metadata,
+		})
+		return err
+	})
+	if err != nil {
+		return catalogError(err)
+	}
+	return utils.NewResponse(utils.CodeOK, "store access granted successfully", access)
+}
+
+// SetPrimaryStore makes storeID, which userID must have access to, their
+// primary store.
+func (uc *UserUseCase) SetPrimaryStore(ctx context.Context, viewerID *int32, userID, storeID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkCanManageStoreAccess(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if resp := uc.checkStoreAccessTarget(ctx, userID, storeID); resp != nil {
+		return resp
+	}
+	has, err := uc.repo.CheckUserHasStoreAccess(ctx, repository.CheckUserHasStoreAccessParams{UserID: userID, StoreID: storeID})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	if !has {
+		return utils.NewResponse(utils.CodeBadReq, "user has no access to this store", nil)
+	}
+
+	var access repository.UserStoreAccess
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		if err := q.SetUserPrimaryStore(ctx, userID); err != nil {
+			return err
+		}
+		access, err = q.UpdateUserStoreAccess(ctx, repository.UpdateUserStoreAccessParams{
+			UserID:    userID,
+			StoreID:   storeID,
+			IsPrimary: pgtype.Bool{Bool: true, Valid: true},
+		})
+		return err
+	})
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "primary store set successfully", access)
+}
+
+// RevokeStoreAccess takes userID's access to a store of the active
+// organization away.
+func (uc *UserUseCase) RevokeStoreAccess(ctx context.Context, viewerID *int32, userID, storeID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkCanManageStoreAccess(ctx, uc.repo, viewerID); resp != nil {
+		return resp
+	}
+	if resp := uc.checkStoreAccessTarget(ctx, userID, storeID); resp != nil {
+		return resp
+	}
+	if err := uc.repo.RevokeStoreAccessFromUser(ctx, repository.RevokeStoreAccessFromUserParams{UserID: userID, StoreID: storeID}); err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "store access revoked successfully", nil)
+}
+
+// DefaultStore returns the store selected for userID at login: their primary
+// store, else the first active store they have access to by name, or nil.
+func DefaultStore(ctx context.Context, q *repository.Queries, userID int32) (*repository.Store, error) {
+	stores, err := q.GetUserStores(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, s := range stores {
+		if s.IsActive.Bool {
+			return &s, nil
+		}
+	}
+	return nil, nil
+}
+
+// checkStoreAccessTarget checks that the user exists and the store is in
+// the active organization. The user may belong to another organization:
+// store access is how users work across organizations.
+func (uc *UserUseCase) checkStoreAccessTarget(ctx context.Context, userID, storeID int32) *repository.Response {
+	if userID <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "invalid user id", nil)
+	}
+	if storeID <= 0 {
+		return utils.NewResponse(utils.CodeBadReq, "invalid store id", nil)
+	}
+	if _, err := uc.repo.GetUser(ctx, userID); err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
+	}
+	if store, err := uc.repo.GetStore(ctx, storeID); err != nil || !inActiveOrganization(ctx, store.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "store not found", nil)
+	}
+	return nil
+}
+
+// checkCanManageStoreAccess returns a forbidden response unless viewerID
+// holds PermissionManageStoreAccess.
+func checkCanManageStoreAccess(ctx context.Context, q *repository.Queries, viewerID *int32) *repository.Response {
+	if viewerID != nil {
+		ok, err := q.CheckUserHasPermission(ctx, repository.CheckUserHasPermissionParams{UserID: *viewerID, Code: PermissionManageStoreAccess})
+		if err != nil {
+			return utils.NewResponse(utils.CodeError, err.Error(), nil)
+		}
+		if ok {
+			return nil
+		}
+	}
+	return utils.NewResponse(utils.CodeForbidden, "managing store access requires the "+PermissionManageStoreAccess+" permission", nil)
+}
@@ -3,6 +3,7 @@ package usecase
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"log"
 	"strconv"
 
@@ -10,18 +11,21 @@ import (
 
 	"NEMBUS/utils" // Assuming your NewResponse is here
 
+	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgtype"
 	"golang.org/x/crypto/bcrypt"
 )
 
 type UserUseCase struct {
-	repo *repository.Queries
+	repo           *repository.Queries
+	passwordPolicy PasswordPolicy
 }
 
 // NewUserUseCase creates a new use case without a repository
 // Repository will be injected per request via SetRepository
-func NewUserUseCase() *UserUseCase {
-	return &UserUseCase{}
+// Passwords set through it must satisfy passwordPolicy
+func NewUserUseCase(passwordPolicy PasswordPolicy) *UserUseCase {
+	return &UserUseCase{passwordPolicy: passwordPolicy}
 }
 
 // SetRepository sets the repository for this request
@@ -29,34 +33,28 @@ func (uc *UserUseCase) SetRepository(repo *repository.Queries) {
 	uc.repo = repo
 }
 
-// getOrganizationID gets the first active organization from the tenant database
-// Since each tenant database is isolated, we assume there's one organization
+// getOrganizationID returns the active organization of the request
 func (uc *UserUseCase) getOrganizationID(ctx context.Context) *repository.Response {
 	if uc.repo == nil {
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
-
-	// Get the first active organization
-	orgs, err := uc.repo.ListOrganizations(ctx, repository.ListOrganizationsParams{
-		Limit:    1,
-		Offset:   0,
-		IsActive: pgtype.Bool{Bool: true, Valid: true},
-	})
-	if err != nil {
-		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	orgID, resp := activeOrganization(ctx, 0)
+	if resp != nil {
+		return resp
 	}
-	if len(orgs) == 0 {
-		return utils.NewResponse(utils.CodeNotFound, "no active organization found in tenant database", nil)
-	}
-	return utils.NewResponse(utils.CodeOK, "organization found successfully", orgs[0].ID)
+	return utils.NewResponse(utils.CodeOK, "organization found successfully", orgID)
 }
 
-// CreateUser creates a new user
-func (uc *UserUseCase) CreateUser(ctx context.Context, firstName, lastName, username, email string, isActive bool, password *string, employeeCode *string) *repository.Response {
+// CreateUser creates a new user in the active organization. It needs
+// PermissionManageUsers.
+func (uc *UserUseCase) CreateUser(ctx context.Context, viewerID *int32, firstName, lastName, username, email string, isActive bool, password *string, employeeCode *string) *repository.Response {
 	if uc.repo == nil {
 		//return repository.User{}, errors.New("repository not set")
 		return utils.NewResponse(utils.CodeError, "repository not set", nil)
 	}
+	if resp := checkUserPermission(ctx, uc.repo, viewerID, PermissionManageUsers, "creating users"); resp != nil {
+		return resp
+	}
 	if firstName == "" {
 		//return repository.User{}, errors.New("first name cannot be empty")
 		return utils.NewResponse(utils.CodeBadReq, "first name cannot be empty", nil)
@@ -80,6 +78,9 @@ func (uc *UserUseCase) CreateUser(ctx context.Context, firstName, lastName, user
 	// Prepare password_hash
 	var passwordHash string
 	if password != nil && *password != "" {
+		if err := uc.passwordPolicy.Check(*password); err != nil {
+			return utils.NewResponse(utils.CodeBadReq, err.Error(), nil)
+		}
 		// Hash the password
 		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(*password), bcrypt.DefaultCost)
 		if err != nil {
@@ -152,10 +153,35 @@ func (uc *UserUseCase) GetUser(ctx context.Context, id string) *repository.Respo
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
+	if !inActiveOrganization(ctx, user.OrganizationID) {
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
+	}
 
 	return utils.NewResponse(utils.CodeOK, "user fetched successfully", user)
 }
 
+// GetUserProfile returns a user with their organization, roles, permissions,
+// store access and cashier details. viewerID is the signed-in user, see
+// checkUserVisible.
+func (uc *UserUseCase) GetUserProfile(ctx context.Context, viewerID *int32, userID int32) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	if resp := checkUserVisible(ctx, uc.repo, viewerID, userID); resp != nil {
+		return resp
+	}
+
+	profile, err := uc.repo.GetUserProfileWithRolesAndPermissions(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return utils.NewResponse(utils.CodeNotFound, "user not found", nil)
+	}
+	if err != nil {
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+
+	return utils.NewResponse(utils.CodeOK, "user profile fetched successfully", profile)
+}
+
 // ListUsers lists all users for the organization
 func (uc *UserUseCase) ListUsers(ctx context.Context, limit, offset int32) *repository.Response {
 	if uc.repo == nil {
@@ -203,9 +229,12 @@ func decodeJSONMetadata(b []byte) (map[string]interface{}, error) {
 	return result, nil
 }
 
-// AssignRoleToUser assigns a role to a user
+// AssignRoleToUser assigns a role to a user of the active organization. It
+// needs PermissionManageUsers, and assigning a system role needs
+// PermissionManageSystemRoles too.
 func (uc *UserUseCase) AssignRoleToUser(
 	ctx context.Context,
+	viewerID *int32,
 	userID int32,
 	roleID int32,
 	storeID *int32, // 👈 optional
@@ -221,9 +250,9 @@ func (uc *UserUseCase) AssignRoleToUser(
 	}
 
 	// 2. Validation
-	if userID <= 0 {
-		log.Println("[AssignRoleToUser] invalid user id")
-		return utils.NewResponse(utils.CodeBadReq, "invalid user id", nil)
+	if resp := uc.checkCanManageUser(ctx, viewerID, userID); resp != nil {
+		log.Printf("[AssignRoleToUser] refused | userID=%d status=%d", userID, resp.StatusCode)
+		return resp
 	}
 
 	if roleID <= 0 {
@@ -231,6 +260,18 @@ func (uc *UserUseCase) AssignRoleToUser(
 		return utils.NewResponse(utils.CodeBadReq, "invalid role id", nil)
 	}
 
+	role, err := uc.repo.GetRole(ctx, roleID)
+	if err != nil {
+		log.Printf("[AssignRoleToUser] role not found | roleID=%d", roleID)
+		return utils.NewResponse(utils.CodeNotFound, "role not found", nil)
+	}
+	if role.IsSystemRole.Bool {
+		if resp := checkCanManageSystemRoles(ctx, uc.repo, viewerID); resp != nil {
+			log.Printf("[AssignRoleToUser] system role refused | roleID=%d", roleID)
+			return resp
+		}
+	}
+
 	if metadata == nil {
 		log.Println("[AssignRoleToUser] metadata is nil, defaulting to {}")
 		metadata = []byte("{}")
@@ -266,17 +307,11 @@ func (uc *UserUseCase) AssignRoleToUser(
 		log.Printf("[AssignRoleToUser] failed to assign role | err=%v", err)
 		return utils.NewResponse(utils.CodeBadReq, "failed to assign role", nil)
 	}
+	invalidateNavigation(ctx)
 
 	// After Assigning Role → assign store access
 	log.Println("[AssignRoleToUser] role assigned, fetching role metadata")
 
-	// 3. Fetch role
-	role, err := uc.repo.GetRole(ctx, roleID)
-	if err != nil {
-		log.Printf("[AssignRoleToUser] failed to fetch role | roleID=%d err=%v", roleID, err)
-		return utils.NewResponse(utils.CodeBadReq, "failed to fetch role", nil)
-	}
-
 	// 4. Decode role metadata (BASE64 → JSON)
 	roleMetadata, err := decodeJSONMetadata(role.Metadata)
 	if err != nil {
@@ -351,6 +386,14 @@ func (uc *UserUseCase) GetUsersByRole(ctx context.Context, roleID int32) *reposi
 	if err != nil {
 		return utils.NewResponse(utils.CodeError, err.Error(), nil)
 	}
+	// Roles are shared by the organizations of the tenant
+	var inOrg []repository.User
+	for _, u := range users {
+		if inActiveOrganization(ctx, u.OrganizationID) {
+			inOrg = append(inOrg, u)
+		}
+	}
+	users = inOrg
 
 	return utils.NewResponse(utils.CodeOK, "users fetched successfully", users)
 }
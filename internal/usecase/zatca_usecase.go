@@ -0,0 +1,360 @@
+package usecase
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	"NEMBUS/internal/repository"
+	"NEMBUS/internal/zatca"
+	"NEMBUS/utils"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// ZatcaUseCase issues ZATCA simplified tax invoices for POS transactions.
+type ZatcaUseCase struct {
+	repo   *repository.Queries
+	signer *zatca.Signer
+}
+
+// NewZatcaUseCase creates a ZATCA use case. signer may be nil, in which case
+// invoices are hashed and chained but not signed (QR tags 1-5 only).
+func NewZatcaUseCase(signer *zatca.Signer) *ZatcaUseCase {
+	return &ZatcaUseCase{signer: signer}
+}
+
+// SetRepository injects repository per request
+func (uc *ZatcaUseCase) SetRepository(repo *repository.Queries) {
+	uc.repo = repo
+}
+
+// IssueInvoice generates the simplified tax invoice for a POS transaction.
+// It is idempotent: an already issued invoice is returned unchanged.
+func (uc *ZatcaUseCase) IssueInvoice(ctx context.Context, transactionNumber string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	txn, err := uc.repo.GetPosTransactionByNumber(ctx, transactionNumber)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "transaction not found", nil)
+	}
+	if resp := checkPosTransactionInScope(ctx, uc.repo, txn); resp != nil {
+		return resp
+	}
+	if existing, err := uc.repo.GetZatcaInvoiceByTransaction(ctx, txn.ID); err == nil {
+		return utils.NewResponse(utils.CodeOK, "invoice already issued", existing)
+	}
+	if txn.Status.String == "voided" {
+		return utils.NewResponse(utils.CodeBadReq, "cannot issue an invoice for a voided transaction", nil)
+	}
+
+	var inv repository.ZatcaInvoice
+	err = uc.repo.ExecTx(ctx, func(q *repository.Queries) error {
+		var err error
+		inv, err = issueZatcaInvoice(ctx, q, uc.signer, txn)
+		return err
+	})
+	if err != nil {
+		var bad *zatcaInputError
+		if errors.As(err, &bad) {
+			return utils.NewResponse(utils.CodeBadReq, bad.Error(), nil)
+		}
+		return utils.NewResponse(utils.CodeError, err.Error(), nil)
+	}
+	return utils.NewResponse(utils.CodeCreated, "invoice issued", inv)
+}
+
+// GetInvoice returns the issued invoice for a POS transaction.
+func (uc *ZatcaUseCase) GetInvoice(ctx context.Context, transactionNumber string) *repository.Response {
+	if uc.repo == nil {
+		return utils.NewResponse(utils.CodeError, "repository not set", nil)
+	}
+	txn, err := uc.repo.GetPosTransactionByNumber(ctx, transactionNumber)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "transaction not found", nil)
+	}
+	if resp := checkPosTransactionInScope(ctx, uc.repo, txn); resp != nil {
+		return resp
+	}
+	inv, err := uc.repo.GetZatcaInvoiceByTransaction(ctx, txn.ID)
+	if err != nil {
+		return utils.NewResponse(utils.CodeNotFound, "no invoice issued for this transaction", nil)
+	}
+	return utils.NewResponse(utils.CodeOK, "invoice fetched successfully", inv)
+}
+
+// zatcaInputError reports master data that must be fixed before an invoice
+// can be issued.
+type zatcaInputError struct{ msg string }
+
+func (e *zatcaInputError) Error() string { return e.msg }
+
+// issueZatcaInvoice builds, hashes, signs and stores the invoice for txn.
+// q must be bound to a database transaction: the chain lock is held until
+// it ends, so invoice counters and previous hashes never fork per terminal.
+func issueZatcaInvoice(ctx context.Context, q *repository.Queries, signer *zatca.Signer, txn repository.PosTransaction) (repository.ZatcaInvoice, error) {
+	store, err := q.GetStore(ctx, txn.StoreID)
+	if err != nil {
+		return repository.ZatcaInvoice{}, fmt.Errorf("load store: %w", err)
+	}
+	org, err := q.GetOrganization(ctx, store.OrganizationID)
+	if err != nil {
+		return repository.ZatcaInvoice{}, fmt.Errorf("load organization: %w", err)
+	}
+	if !org.TaxID.Valid || strings.TrimSpace(org.TaxID.String) == "" {
+		return repository.ZatcaInvoice{}, &zatcaInputError{"organization tax_id (VAT registration number) is not set"}
+	}
+	lines, err := q.GetPosTransactionLinesForInvoice(ctx, txn.ID)
+	if err != nil {
+		return repository.ZatcaInvoice{}, fmt.Errorf("load lines: %w", err)
+	}
+	if len(lines) == 0 {
+		return repository.ZatcaInvoice{}, &zatcaInputError{"transaction has no lines"}
+	}
+	if metadataBool(txn.Metadata, "tax_exempt") && zatcaExemptionCode(txn.Metadata) == "" {
+		return repository.ZatcaInvoice{}, &zatcaInputError{"tax-exempt sale has no exemption reason code (customer metadata tax_exemption_code)"}
+	}
+
+	if err := q.LockZatcaInvoiceChain(ctx, int64(txn.PosTerminalID)); err != nil {
+		return repository.ZatcaInvoice{}, fmt.Errorf("lock invoice chain: %w", err)
+	}
+	counter, previousHash := int64(1), zatca.InitialPreviousHash
+	last, err := q.GetLastZatcaInvoiceForTerminal(ctx, txn.PosTerminalID)
+	switch {
+	case err == nil:
+		counter, previousHash = last.InvoiceCounter+1, last.InvoiceHash
+	case !errors.Is(err, pgx.ErrNoRows):
+		return repository.ZatcaInvoice{}, fmt.Errorf("load previous invoice: %w", err)
+	}
+
+	invoiceUUID := uuid.New()
+	inv := buildZatcaInvoice(txn, store, org, lines)
+	inv.UUID = invoiceUUID.String()
+	inv.Counter = counter
+	inv.PreviousHash = previousHash
+
+	hash, digest, err := inv.Hash()
+	if err != nil {
+		return repository.ZatcaInvoice{}, fmt.Errorf("hash invoice: %w", err)
+	}
+	qr := zatca.QRFields{
+		SellerName:   inv.Seller.Name,
+		VATNumber:    inv.Seller.VATNumber,
+		Timestamp:    inv.IssuedAt,
+		InvoiceTotal: inv.TaxInclusiveAmount,
+		VATTotal:     inv.TaxAmount,
+	}
+	signature := pgtype.Text{}
+	if signer != nil {
+		sig, err := signer.Sign(digest)
+		if err != nil {
+			return repository.ZatcaInvoice{}, fmt.Errorf("sign invoice: %w", err)
+		}
+		inv.SignatureValue = base64.StdEncoding.EncodeToString(sig)
+		signature = pgtype.Text{String: inv.SignatureValue, Valid: true}
+		qr.InvoiceHash = hash
+		qr.Signature = sig
+		qr.PublicKey = signer.PublicKey()
+	}
+	if inv.QRCode, err = zatca.EncodeQR(qr); err != nil {
+		return repository.ZatcaInvoice{}, err
+	}
+	xmlDoc, err := inv.XML()
+	if err != nil {
+		return repository.ZatcaInvoice{}, fmt.Errorf("render invoice xml: %w", err)
+	}
+
+	return q.CreateZatcaInvoice(ctx, repository.CreateZatcaInvoiceParams{
+		TransactionID:  txn.ID,
+		PosTerminalID:  txn.PosTerminalID,
+		InvoiceCounter: counter,
+		InvoiceUuid:    invoiceUUID,
+		InvoiceHash:    hash,
+		PreviousHash:   previousHash,
+		QrCode:         inv.QRCode,
+		InvoiceXml:     string(xmlDoc),
+		Signature:      signature,
+		Status:         pgtype.Text{String: "generated", Valid: true},
+		Metadata:       []byte("{}"),
+	})
+}
+
+// buildZatcaInvoice maps a POS transaction onto the invoice model. Line
+// totals are stored at the listed price, so inclusive lines carry their tax
+// inside line_total while exclusive lines add it on top. Line discounts are
+// reported as document allowances per category, so lines are listed before
+// discount and the tax subtotals carry the tax actually charged. A sale to
+// a tax-exempt customer is exempt ("E") with the exemption code recorded
+// at checkout.
+func buildZatcaInvoice(txn repository.PosTransaction, store repository.Store, org repository.Organization, lines []repository.GetPosTransactionLinesForInvoiceRow) *zatca.Invoice {
+	inv := &zatca.Invoice{
+		Number:   txn.TransactionNumber,
+		IssuedAt: storeLocalTime(txn.TransactionDate, store.Timezone),
+		Currency: "SAR",
+		Seller:   zatcaSeller(store, org),
+	}
+	if org.CurrencyCode.Valid && org.CurrencyCode.String != "" {
+		inv.Currency = org.CurrencyCode.String
+	}
+	exempt := metadataBool(txn.Metadata, "tax_exempt")
+	exemptionCode := ""
+	if exempt {
+		exemptionCode = zatcaExemptionCode(txn.Metadata)
+	}
+
+	type subtotalKey struct{ category, percent string }
+	type sums struct{ taxable, tax, allowance *big.Rat }
+	subtotals := map[subtotalKey]sums{}
+	var order []subtotalKey
+	lineTotal, netTotal, taxTotal, allowanceTotal := new(big.Rat), new(big.Rat), new(big.Rat), new(big.Rat)
+	hundred := big.NewRat(100, 1)
+
+	for _, l := range lines {
+		tax := utils.NumericToRat(l.TaxAmount)
+		net := utils.NumericToRat(l.LineTotal)
+		if l.IsInclusive {
+			net.Sub(net, tax)
+		}
+		rate := utils.NumericToRat(l.TaxRate)
+		category := zatca.TaxStandard
+		switch {
+		case exempt:
+			category, rate = zatca.TaxExempt, new(big.Rat)
+		case rate.Sign() == 0:
+			category = zatca.TaxZero
+		}
+		percent := rate.FloatString(2)
+
+		// The discount is in the price basis; take the tax out of an
+		// inclusive one so it lowers the net amount.
+		discount := utils.NumericToRat(l.DiscountAmount)
+		if l.IsInclusive && tax.Sign() != 0 {
+			discount.Quo(discount.Mul(discount, hundred), new(big.Rat).Add(hundred, rate))
+			discount = utils.RoundRat(discount, 2)
+		}
+		listed := new(big.Rat).Add(net, discount)
+		listedTax := utils.RoundRat(new(big.Rat).Quo(new(big.Rat).Mul(listed, rate), hundred), 2)
+		unit := new(big.Rat)
+		if qty := utils.NumericToRat(l.Quantity); qty.Sign() != 0 {
+			unit.Quo(listed, qty)
+		}
+
+		inv.Lines = append(inv.Lines, zatca.Line{
+			ID:          int(l.LineNumber),
+			Name:        l.ProductName,
+			Quantity:    formatQuantity(l.Quantity),
+			NetAmount:   listed.FloatString(2),
+			TaxAmount:   listedTax.FloatString(2),
+			GrossAmount: new(big.Rat).Add(listed, listedTax).FloatString(2),
+			UnitPrice:   unit.FloatString(2),
+			TaxPercent:  percent,
+			TaxCategory: category,
+		})
+
+		key := subtotalKey{category, percent}
+		sum, ok := subtotals[key]
+		if !ok {
+			sum = sums{new(big.Rat), new(big.Rat), new(big.Rat)}
+			subtotals[key] = sum
+			order = append(order, key)
+		}
+		sum.taxable.Add(sum.taxable, net)
+		sum.tax.Add(sum.tax, tax)
+		sum.allowance.Add(sum.allowance, discount)
+		lineTotal.Add(lineTotal, listed)
+		netTotal.Add(netTotal, net)
+		taxTotal.Add(taxTotal, tax)
+		allowanceTotal.Add(allowanceTotal, discount)
+	}
+	for _, key := range order {
+		sum := subtotals[key]
+		code := ""
+		if key.category == zatca.TaxExempt {
+			code = exemptionCode
+		}
+		if sum.allowance.Sign() != 0 {
+			inv.Allowances = append(inv.Allowances, zatca.Allowance{
+				Amount:        sum.allowance.FloatString(2),
+				Percent:       key.percent,
+				Category:      key.category,
+				ExemptionCode: code,
+			})
+		}
+		inv.TaxSubtotals = append(inv.TaxSubtotals, zatca.TaxSubtotal{
+			TaxableAmount: sum.taxable.FloatString(2),
+			TaxAmount:     sum.tax.FloatString(2),
+			Percent:       key.percent,
+			Category:      key.category,
+			ExemptionCode: code,
+		})
+	}
+
+	inclusive := new(big.Rat).Add(netTotal, taxTotal)
+	inv.LineExtensionAmount = lineTotal.FloatString(2)
+	inv.TaxExclusiveAmount = netTotal.FloatString(2)
+	inv.TaxAmount = taxTotal.FloatString(2)
+	inv.TaxInclusiveAmount = inclusive.FloatString(2)
+	inv.AllowanceAmount = allowanceTotal.FloatString(2)
+	inv.PayableAmount = inclusive.FloatString(2)
+	return inv
+}
+
+// zatcaExemptionCode returns the exemption reason code recorded on a
+// tax-exempt sale (metadata tax_exemption_code).
+func zatcaExemptionCode(meta []byte) string {
+	v, _ := metadataValue(meta, "tax_exemption_code").(string)
+	return strings.ToUpper(strings.TrimSpace(v))
+}
+
+// zatcaSeller reads the seller address from stores.metadata (street,
+// building_number, district, city, postal_code, country_code, cr_number).
+func zatcaSeller(store repository.Store, org repository.Organization) zatca.Party {
+	p := zatca.Party{Name: org.Name, VATNumber: strings.TrimSpace(org.TaxID.String)}
+	if org.LegalName.Valid && org.LegalName.String != "" {
+		p.Name = org.LegalName.String
+	}
+	var meta map[string]interface{}
+	if len(store.Metadata) > 0 && json.Unmarshal(store.Metadata, &meta) == nil {
+		get := func(key string) string {
+			if v, ok := meta[key]; ok && v != nil {
+				return strings.TrimSpace(fmt.Sprint(v))
+			}
+			return ""
+		}
+		p.Street = get("street")
+		if p.Street == "" {
+			p.Street = get("address")
+		}
+		p.BuildingNumber = get("building_number")
+		p.District = get("district")
+		p.City = get("city")
+		p.PostalCode = get("postal_code")
+		p.CountryCode = get("country_code")
+		p.CRNumber = get("cr_number")
+	}
+	return p
+}
+
+// storeLocalTime interprets a timestamp without time zone in the store's
+// time zone, falling back to UTC when the zone is unset or unknown.
+func storeLocalTime(ts pgtype.Timestamp, timezone pgtype.Text) time.Time {
+	t := ts.Time
+	if !ts.Valid {
+		t = time.Now().UTC()
+	}
+	loc := time.UTC
+	if timezone.Valid && timezone.String != "" {
+		if l, err := time.LoadLocation(timezone.String); err == nil {
+			loc = l
+		}
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), loc)
+}
@@ -0,0 +1,445 @@
+package zatca
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/xml"
+	"strconv"
+	"time"
+)
+
+// InitialPreviousHash is the PIH of the first invoice in a chain: the
+// Base64 of the hex SHA-256 digest of "0", as defined by ZATCA.
+const InitialPreviousHash = "NWZlY2ViNjZmZmM4NmYzOGQ5NTI3ODZjNmQ2OTZjNzljMmRiYzIzOWRkNGU5MWI0NjcyOWQ3M2EyN2ZiNTdlOQ=="
+
+// Tax category codes (UN/ECE 5305) used by ZATCA.
+const (
+	TaxStandard = "S"
+	TaxZero     = "Z"
+	TaxExempt   = "E"
+	TaxOutOfVAT = "O"
+)
+
+// Party is the seller of a simplified invoice.
+type Party struct {
+	Name           string
+	VATNumber      string
+	CRNumber       string
+	Street         string
+	BuildingNumber string
+	District       string
+	City           string
+	PostalCode     string
+	CountryCode    string
+}
+
+// Line is one invoice line. Amounts are decimal strings with two places.
+type Line struct {
+	ID          int
+	Name        string
+	Quantity    string
+	NetAmount   string // excluding VAT, after discount
+	TaxAmount   string
+	GrossAmount string // including VAT
+	UnitPrice   string // excluding VAT
+	TaxPercent  string
+	TaxCategory string
+}
+
+// TaxSubtotal is the VAT total for one category and rate. ExemptionCode is
+// the ZATCA exemption reason code (VATEX-SA-...) of an exempt category.
+type TaxSubtotal struct {
+	TaxableAmount string
+	TaxAmount     string
+	Percent       string
+	Category      string
+	ExemptionCode string
+}
+
+// Allowance is a document level discount on the lines of one category and
+// rate; it lowers the taxable amount of that category.
+type Allowance struct {
+	Amount        string
+	Percent       string
+	Category      string
+	ExemptionCode string
+}
+
+// Invoice is a ZATCA simplified tax invoice (KSA type 0200000).
+type Invoice struct {
+	Number       string
+	UUID         string
+	IssuedAt     time.Time
+	Counter      int64
+	PreviousHash string
+	Currency     string
+	Seller       Party
+	Lines        []Line
+	Allowances   []Allowance
+	TaxSubtotals []TaxSubtotal
+
+	LineExtensionAmount string
+	TaxExclusiveAmount  string
+	TaxAmount           string
+	TaxInclusiveAmount  string
+	AllowanceAmount     string
+	PayableAmount       string
+
+	// Set after hashing and signing.
+	QRCode         string
+	SignatureValue string
+}
+
+// Hash returns the invoice hash: the Base64 SHA-256 digest of the invoice
+// XML without the signature and QR code, together with the raw digest.
+func (inv *Invoice) Hash() (string, []byte, error) {
+	body, err := inv.marshal(false)
+	if err != nil {
+		return "", nil, err
+	}
+	sum := sha256.Sum256(body)
+	return base64.StdEncoding.EncodeToString(sum[:]), sum[:], nil
+}
+
+// XML returns the complete invoice document, including the QR code and the
+// signature value when they are set.
+func (inv *Invoice) XML() ([]byte, error) {
+	body, err := inv.marshal(true)
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
+
+func (inv *Invoice) marshal(full bool) ([]byte, error) {
+	cur := inv.Currency
+	if cur == "" {
+		cur = "SAR"
+	}
+	amount := func(v string) *ublAmount {
+		if v == "" {
+			v = "0.00"
+		}
+		return &ublAmount{Currency: cur, Value: v}
+	}
+
+	doc := ublInvoice{
+		Xmlns:            "urn:oasis:names:specification:ubl:schema:xsd:Invoice-2",
+		XmlnsCac:         "urn:oasis:names:specification:ubl:schema:xsd:CommonAggregateComponents-2",
+		XmlnsCbc:         "urn:oasis:names:specification:ubl:schema:xsd:CommonBasicComponents-2",
+		XmlnsExt:         "urn:oasis:names:specification:ubl:schema:xsd:CommonExtensionComponents-2",
+		XmlnsDs:          "http://www.w3.org/2000/09/xmldsig#",
+		ProfileID:        "reporting:1.0",
+		ID:               inv.Number,
+		UUID:             inv.UUID,
+		IssueDate:        inv.IssuedAt.UTC().Format("2006-01-02"),
+		IssueTime:        inv.IssuedAt.UTC().Format("15:04:05"),
+		InvoiceTypeCode:  ublCode{Name: "0200000", Value: "388"},
+		DocumentCurrency: cur,
+		TaxCurrency:      cur,
+		References: []ublDocumentReference{
+			{ID: "ICV", UUID: strconv.FormatInt(inv.Counter, 10)},
+			{ID: "PIH", Attachment: &ublAttachment{Object: ublBinary{MimeCode: "text/plain", Value: inv.PreviousHash}}},
+		},
+		Seller: ublSupplier{Party: ublParty{
+			Identification: &ublIdentification{ID: ublSchemeID{Scheme: "CRN", Value: inv.Seller.CRNumber}},
+			Address: ublAddress{
+				Street:         inv.Seller.Street,
+				BuildingNumber: inv.Seller.BuildingNumber,
+				District:       inv.Seller.District,
+				City:           inv.Seller.City,
+				PostalCode:     inv.Seller.PostalCode,
+				Country:        ublCountry{Code: countryOrDefault(inv.Seller.CountryCode)},
+			},
+			TaxScheme: ublPartyTaxScheme{CompanyID: inv.Seller.VATNumber, TaxScheme: ublTaxScheme{ID: "VAT"}},
+			Legal:     ublLegalEntity{Name: inv.Seller.Name},
+		}},
+		Delivery: &ublDelivery{Date: inv.IssuedAt.UTC().Format("2006-01-02")},
+		Payment:  &ublPaymentMeans{Code: "10"},
+		TaxTotals: []ublTaxTotal{
+			{TaxAmount: amount(inv.TaxAmount)},
+			{TaxAmount: amount(inv.TaxAmount)},
+		},
+		Monetary: ublMonetaryTotal{
+			LineExtension:  amount(inv.LineExtensionAmount),
+			TaxExclusive:   amount(inv.TaxExclusiveAmount),
+			TaxInclusive:   amount(inv.TaxInclusiveAmount),
+			AllowanceTotal: amount(inv.AllowanceAmount),
+			PayableAmount:  amount(inv.PayableAmount),
+		},
+	}
+	if inv.Seller.CRNumber == "" {
+		doc.Seller.Party.Identification = nil
+	}
+	for _, st := range inv.TaxSubtotals {
+		doc.TaxTotals[1].Subtotals = append(doc.TaxTotals[1].Subtotals, ublTaxSubtotal{
+			TaxableAmount: amount(st.TaxableAmount),
+			TaxAmount:     amount(st.TaxAmount),
+			Category: ublTaxCategory{
+				ID:         st.Category,
+				Percent:    st.Percent,
+				ReasonCode: st.ExemptionCode,
+				Reason:     exemptionReason(st.Category),
+				TaxScheme:  ublTaxScheme{ID: "VAT"},
+			},
+		})
+	}
+	for _, a := range inv.Allowances {
+		doc.Allowances = append(doc.Allowances, ublAllowanceCharge{
+			ChargeIndicator: false,
+			Reason:          "discount",
+			Amount:          amount(a.Amount),
+			Category: ublTaxCategory{
+				ID:         a.Category,
+				Percent:    a.Percent,
+				ReasonCode: a.ExemptionCode,
+				Reason:     exemptionReason(a.Category),
+				TaxScheme:  ublTaxScheme{ID: "VAT"},
+			},
+		})
+	}
+	for _, l := range inv.Lines {
+		doc.Lines = append(doc.Lines, ublLine{
+			ID:            strconv.Itoa(l.ID),
+			Quantity:      ublQuantity{UnitCode: "PCE", Value: l.Quantity},
+			LineExtension: amount(l.NetAmount),
+			TaxTotal: ublLineTaxTotal{
+				TaxAmount:      amount(l.TaxAmount),
+				RoundingAmount: amount(l.GrossAmount),
+			},
+			Item: ublItem{
+				Name: l.Name,
+				Category: ublTaxCategory{
+					ID:        l.TaxCategory,
+					Percent:   l.TaxPercent,
+					TaxScheme: ublTaxScheme{ID: "VAT"},
+				},
+			},
+			Price: ublPrice{Amount: amount(l.UnitPrice)},
+		})
+	}
+
+	if full {
+		if inv.QRCode != "" {
+			doc.References = append(doc.References, ublDocumentReference{
+				ID:         "QR",
+				Attachment: &ublAttachment{Object: ublBinary{MimeCode: "text/plain", Value: inv.QRCode}},
+			})
+		}
+		if inv.SignatureValue != "" {
+			doc.Extensions = &ublExtensions{Extension: ublExtension{
+				URI:            "urn:oasis:names:specification:ubl:dsig:enveloped:xades",
+				SignatureValue: inv.SignatureValue,
+			}}
+			doc.Signature = &ublSignature{
+				ID:     "urn:oasis:names:specification:ubl:signature:Invoice",
+				Method: "urn:oasis:names:specification:ubl:dsig:enveloped:xades",
+			}
+		}
+	}
+	return xml.MarshalIndent(doc, "", "    ")
+}
+
+func countryOrDefault(code string) string {
+	if code == "" {
+		return "SA"
+	}
+	return code
+}
+
+func exemptionReason(category string) string {
+	switch category {
+	case TaxExempt:
+		return "Exempt supply"
+	case TaxZero:
+		return "Zero rated supply"
+	case TaxOutOfVAT:
+		return "Not subject to VAT"
+	}
+	return ""
+}
+
+// UBL 2.1 document structure. Element names carry their namespace prefix
+// literally; the prefixes are declared on the root element.
+
+type ublInvoice struct {
+	XMLName          xml.Name               `xml:"Invoice"`
+	Xmlns            string                 `xml:"xmlns,attr"`
+	XmlnsCac         string                 `xml:"xmlns:cac,attr"`
+	XmlnsCbc         string                 `xml:"xmlns:cbc,attr"`
+	XmlnsExt         string                 `xml:"xmlns:ext,attr"`
+	XmlnsDs          string                 `xml:"xmlns:ds,attr"`
+	Extensions       *ublExtensions         `xml:"ext:UBLExtensions,omitempty"`
+	ProfileID        string                 `xml:"cbc:ProfileID"`
+	ID               string                 `xml:"cbc:ID"`
+	UUID             string                 `xml:"cbc:UUID"`
+	IssueDate        string                 `xml:"cbc:IssueDate"`
+	IssueTime        string                 `xml:"cbc:IssueTime"`
+	InvoiceTypeCode  ublCode                `xml:"cbc:InvoiceTypeCode"`
+	DocumentCurrency string                 `xml:"cbc:DocumentCurrencyCode"`
+	TaxCurrency      string                 `xml:"cbc:TaxCurrencyCode"`
+	References       []ublDocumentReference `xml:"cac:AdditionalDocumentReference"`
+	Signature        *ublSignature          `xml:"cac:Signature,omitempty"`
+	Seller           ublSupplier            `xml:"cac:AccountingSupplierParty"`
+	Delivery         *ublDelivery           `xml:"cac:Delivery,omitempty"`
+	Payment          *ublPaymentMeans       `xml:"cac:PaymentMeans,omitempty"`
+	Allowances       []ublAllowanceCharge   `xml:"cac:AllowanceCharge"`
+	TaxTotals        []ublTaxTotal          `xml:"cac:TaxTotal"`
+	Monetary         ublMonetaryTotal       `xml:"cac:LegalMonetaryTotal"`
+	Lines            []ublLine              `xml:"cac:InvoiceLine"`
+}
+
+type ublExtensions struct {
+	Extension ublExtension `xml:"ext:UBLExtension"`
+}
+
+type ublExtension struct {
+	URI            string `xml:"ext:ExtensionURI"`
+	SignatureValue string `xml:"ext:ExtensionContent>ds:SignatureValue"`
+}
+
+type ublSignature struct {
+	ID     string `xml:"cbc:ID"`
+	Method string `xml:"cbc:SignatureMethod"`
+}
+
+type ublCode struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:",chardata"`
+}
+
+type ublDocumentReference struct {
+	ID         string         `xml:"cbc:ID"`
+	UUID       string         `xml:"cbc:UUID,omitempty"`
+	Attachment *ublAttachment `xml:"cac:Attachment,omitempty"`
+}
+
+type ublAttachment struct {
+	Object ublBinary `xml:"cbc:EmbeddedDocumentBinaryObject"`
+}
+
+type ublBinary struct {
+	MimeCode string `xml:"mimeCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublSupplier struct {
+	Party ublParty `xml:"cac:Party"`
+}
+
+type ublParty struct {
+	Identification *ublIdentification `xml:"cac:PartyIdentification,omitempty"`
+	Address        ublAddress         `xml:"cac:PostalAddress"`
+	TaxScheme      ublPartyTaxScheme  `xml:"cac:PartyTaxScheme"`
+	Legal          ublLegalEntity     `xml:"cac:PartyLegalEntity"`
+}
+
+type ublIdentification struct {
+	ID ublSchemeID `xml:"cbc:ID"`
+}
+
+type ublSchemeID struct {
+	Scheme string `xml:"schemeID,attr"`
+	Value  string `xml:",chardata"`
+}
+
+type ublAddress struct {
+	Street         string     `xml:"cbc:StreetName,omitempty"`
+	BuildingNumber string     `xml:"cbc:BuildingNumber,omitempty"`
+	District       string     `xml:"cbc:CitySubdivisionName,omitempty"`
+	City           string     `xml:"cbc:CityName,omitempty"`
+	PostalCode     string     `xml:"cbc:PostalZone,omitempty"`
+	Country        ublCountry `xml:"cac:Country"`
+}
+
+type ublCountry struct {
+	Code string `xml:"cbc:IdentificationCode"`
+}
+
+type ublPartyTaxScheme struct {
+	CompanyID string       `xml:"cbc:CompanyID"`
+	TaxScheme ublTaxScheme `xml:"cac:TaxScheme"`
+}
+
+type ublTaxScheme struct {
+	ID string `xml:"cbc:ID"`
+}
+
+type ublLegalEntity struct {
+	Name string `xml:"cbc:RegistrationName"`
+}
+
+type ublDelivery struct {
+	Date string `xml:"cbc:ActualDeliveryDate"`
+}
+
+type ublPaymentMeans struct {
+	Code string `xml:"cbc:PaymentMeansCode"`
+}
+
+type ublAmount struct {
+	Currency string `xml:"currencyID,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublTaxTotal struct {
+	TaxAmount *ublAmount       `xml:"cbc:TaxAmount"`
+	Subtotals []ublTaxSubtotal `xml:"cac:TaxSubtotal"`
+}
+
+type ublTaxSubtotal struct {
+	TaxableAmount *ublAmount     `xml:"cbc:TaxableAmount"`
+	TaxAmount     *ublAmount     `xml:"cbc:TaxAmount"`
+	Category      ublTaxCategory `xml:"cac:TaxCategory"`
+}
+
+type ublTaxCategory struct {
+	ID         string       `xml:"cbc:ID"`
+	Percent    string       `xml:"cbc:Percent"`
+	ReasonCode string       `xml:"cbc:TaxExemptionReasonCode,omitempty"`
+	Reason     string       `xml:"cbc:TaxExemptionReason,omitempty"`
+	TaxScheme  ublTaxScheme `xml:"cac:TaxScheme"`
+}
+
+type ublAllowanceCharge struct {
+	ChargeIndicator bool           `xml:"cbc:ChargeIndicator"`
+	Reason          string         `xml:"cbc:AllowanceChargeReason"`
+	Amount          *ublAmount     `xml:"cbc:Amount"`
+	Category        ublTaxCategory `xml:"cac:TaxCategory"`
+}
+
+type ublMonetaryTotal struct {
+	LineExtension  *ublAmount `xml:"cbc:LineExtensionAmount"`
+	TaxExclusive   *ublAmount `xml:"cbc:TaxExclusiveAmount"`
+	TaxInclusive   *ublAmount `xml:"cbc:TaxInclusiveAmount"`
+	AllowanceTotal *ublAmount `xml:"cbc:AllowanceTotalAmount"`
+	PayableAmount  *ublAmount `xml:"cbc:PayableAmount"`
+}
+
+type ublLine struct {
+	ID            string          `xml:"cbc:ID"`
+	Quantity      ublQuantity     `xml:"cbc:InvoicedQuantity"`
+	LineExtension *ublAmount      `xml:"cbc:LineExtensionAmount"`
+	TaxTotal      ublLineTaxTotal `xml:"cac:TaxTotal"`
+	Item          ublItem         `xml:"cac:Item"`
+	Price         ublPrice        `xml:"cac:Price"`
+}
+
+type ublQuantity struct {
+	UnitCode string `xml:"unitCode,attr"`
+	Value    string `xml:",chardata"`
+}
+
+type ublLineTaxTotal struct {
+	TaxAmount      *ublAmount `xml:"cbc:TaxAmount"`
+	RoundingAmount *ublAmount `xml:"cbc:RoundingAmount"`
+}
+
+type ublItem struct {
+	Name     string         `xml:"cbc:Name"`
+	Category ublTaxCategory `xml:"cac:ClassifiedTaxCategory"`
+}
+
+type ublPrice struct {
+	Amount *ublAmount `xml:"cbc:PriceAmount"`
+}
@@ -0,0 +1,93 @@
+// Package zatca produces Saudi ZATCA (FATOORA) simplified tax invoices for
+// POS sales: the UBL 2.1 invoice XML, the invoice hash chain and the TLV
+// Base64 QR code printed on receipts.
+package zatca
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"time"
+)
+
+// QR tag numbers defined by the ZATCA e-invoicing specification.
+const (
+	TagSellerName       = 1
+	TagVATNumber        = 2
+	TagTimestamp        = 3
+	TagInvoiceTotal     = 4
+	TagVATTotal         = 5
+	TagInvoiceHash      = 6
+	TagSignature        = 7
+	TagPublicKey        = 8
+	TagCertificateStamp = 9
+)
+
+// QRFields are the values encoded in the invoice QR code. Tags 1-5 are
+// always present; tags 6-8 are added once the invoice is signed.
+type QRFields struct {
+	SellerName   string
+	VATNumber    string
+	Timestamp    time.Time
+	InvoiceTotal string
+	VATTotal     string
+	InvoiceHash  string
+	Signature    []byte
+	PublicKey    []byte
+}
+
+type tlv struct {
+	tag   byte
+	value []byte
+}
+
+// EncodeQR returns the Base64 TLV payload for the QR code.
+func EncodeQR(f QRFields) (string, error) {
+	fields := []tlv{
+		{TagSellerName, []byte(f.SellerName)},
+		{TagVATNumber, []byte(f.VATNumber)},
+		{TagTimestamp, []byte(f.Timestamp.UTC().Format("2006-01-02T15:04:05Z"))},
+		{TagInvoiceTotal, []byte(f.InvoiceTotal)},
+		{TagVATTotal, []byte(f.VATTotal)},
+	}
+	if f.InvoiceHash != "" && len(f.Signature) > 0 && len(f.PublicKey) > 0 {
+		fields = append(fields,
+			tlv{TagInvoiceHash, []byte(f.InvoiceHash)},
+			tlv{TagSignature, []byte(base64.StdEncoding.EncodeToString(f.Signature))},
+			tlv{TagPublicKey, f.PublicKey},
+		)
+	}
+
+	var buf bytes.Buffer
+	for _, fl := range fields {
+		if len(fl.value) > 255 {
+			return "", fmt.Errorf("zatca: QR tag %d value is %d bytes, limit is 255", fl.tag, len(fl.value))
+		}
+		buf.WriteByte(fl.tag)
+		buf.WriteByte(byte(len(fl.value)))
+		buf.Write(fl.value)
+	}
+	return base64.StdEncoding.EncodeToString(buf.Bytes()), nil
+}
+
+// DecodeQR parses a Base64 TLV payload into its raw tag values.
+func DecodeQR(s string) (map[int][]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	out := make(map[int][]byte)
+	for i := 0; i < len(raw); {
+		if i+2 > len(raw) {
+			return nil, fmt.Errorf("zatca: truncated QR TLV at offset %d", i)
+		}
+		tag, n := int(raw[i]), int(raw[i+1])
+		i += 2
+		if i+n > len(raw) {
+			return nil, fmt.Errorf("zatca: QR tag %d overruns payload", tag)
+		}
+		out[tag] = raw[i : i+n]
+		i += n
+	}
+	return out, nil
+}
@@ -0,0 +1,73 @@
+package zatca
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"os"
+)
+
+// Signer signs invoice hashes with an ECDSA private key configured locally,
+// so invoices can be produced and verified offline. The standard library
+// only parses NIST curves, so the key must be P-256 (ZATCA's production
+// secp256k1 keys need a separate crypto provider).
+type Signer struct {
+	key       *ecdsa.PrivateKey
+	publicDER []byte
+}
+
+// LoadSigner reads a PEM encoded EC private key ("EC PRIVATE KEY" or
+// PKCS#8 "PRIVATE KEY") from path.
+func LoadSigner(path string) (*Signer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("zatca: read signing key: %w", err)
+	}
+	return ParseSigner(data)
+}
+
+// ParseSigner parses a PEM encoded EC private key.
+func ParseSigner(pemData []byte) (*Signer, error) {
+	block, _ := pem.Decode(pemData)
+	if block == nil {
+		return nil, fmt.Errorf("zatca: signing key is not PEM encoded")
+	}
+	var key *ecdsa.PrivateKey
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		k, err := x509.ParseECPrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("zatca: parse EC key: %w", err)
+		}
+		key = k
+	case "PRIVATE KEY":
+		k, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("zatca: parse PKCS#8 key: %w", err)
+		}
+		ec, ok := k.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("zatca: signing key must be an EC key")
+		}
+		key = ec
+	default:
+		return nil, fmt.Errorf("zatca: unsupported PEM block %q", block.Type)
+	}
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		return nil, fmt.Errorf("zatca: marshal public key: %w", err)
+	}
+	return &Signer{key: key, publicDER: pub}, nil
+}
+
+// Sign returns the ASN.1 DER ECDSA signature of digest.
+func (s *Signer) Sign(digest []byte) ([]byte, error) {
+	return ecdsa.SignASN1(rand.Reader, s.key, digest)
+}
+
+// PublicKey returns the DER encoded SubjectPublicKeyInfo of the signing key.
+func (s *Signer) PublicKey() []byte {
+	return s.publicDER
+}
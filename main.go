@@ -7,11 +7,13 @@ import (
 
 	"NEMBUS/internal/config"
 	"NEMBUS/internal/handler"
+	"NEMBUS/internal/jobs"
 	"NEMBUS/internal/middleware"
 	"NEMBUS/internal/middleware/manager"
 	"NEMBUS/internal/repository"
 	router "NEMBUS/internal/routing"
 	"NEMBUS/internal/usecase"
+	"NEMBUS/internal/zatca"
 
 	_ "NEMBUS/docs/swagger" // Swagger generated docs
 
@@ -58,7 +60,7 @@ func setupDatabase(ctx context.Context, cfg *config.Config) (*pgxpool.Pool, *rep
 }
 
 // setupRouter initializes handlers, use cases, middleware, and routes, then returns the configured router
-func setupRouter(tenantManager *manager.Manager, userUC *usecase.UserUseCase, orgUC *usecase.OrganizationUseCase, authUC *usecase.AuthUseCase, moduleUC *usecase.ModuleUseCase, imageUC *usecase.ImageUseCase, navigationUC *usecase.NavigationUseCase, permissionUC *usecase.PermissionUseCase, roleUC *usecase.RoleUseCase, menuUC *usecase.MenuUseCase, submenuUC *usecase.SubmenuUseCase, posUC *usecase.PosUseCase, tenantUC *usecase.TenantUseCase, storesUC *usecase.StoreUseCase, cfg *config.Config) *gin.Engine {
+func setupRouter(tenantManager *manager.Manager, userUC *usecase.UserUseCase, orgUC *usecase.OrganizationUseCase, authUC *usecase.AuthUseCase, moduleUC *usecase.ModuleUseCase, imageUC *usecase.ImageUseCase, navigationUC *usecase.NavigationUseCase, permissionUC *usecase.PermissionUseCase, roleUC *usecase.RoleUseCase, menuUC *usecase.MenuUseCase, submenuUC *usecase.SubmenuUseCase, posUC *usecase.PosUseCase, tenantUC *usecase.TenantUseCase, storesUC *usecase.StoreUseCase, zatcaUC *usecase.ZatcaUseCase, salesOrderUC *usecase.SalesOrderUseCase, purchaseOrderUC *usecase.PurchaseOrderUseCase, pricingUC *usecase.PricingUseCase, priceListUC *usecase.PriceListUseCase, inventoryUC *usecase.InventoryUseCase, expiryAlertUC *usecase.ExpiryAlertUseCase, catalogUC *usecase.CatalogUseCase, masterDataUC *usecase.MasterDataUseCase, cfg *config.Config) *gin.Engine {
 	// Set Gin mode based on environment
 	if cfg.Env == "production" || cfg.Env == "prod" {
 		gin.SetMode(gin.ReleaseMode)
@@ -66,6 +68,9 @@ func setupRouter(tenantManager *manager.Manager, userUC *usecase.UserUseCase, or
 
 	// Create router
 	r := gin.Default()
+	if err := r.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		log.Fatalf("Invalid TRUSTED_PROXIES: %v", err)
+	}
 
 	// -------------------------
 	// CORS Middleware (DROP-IN)
@@ -73,8 +78,8 @@ func setupRouter(tenantManager *manager.Manager, userUC *usecase.UserUseCase, or
 	r.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "http://localhost:4200") // allow all origins in dev
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, x-tenant-id, ngrok-skip-browser-warning")
-		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Origin, Content-Type, Authorization, x-tenant-id, x-organization-id, If-None-Match, ngrok-skip-browser-warning")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "Content-Length, ETag, Retry-After")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
 
 		// Handle preflight OPTIONS request
@@ -107,13 +112,17 @@ func setupRouter(tenantManager *manager.Manager, userUC *usecase.UserUseCase, or
 	{
 		authHandler := handler.NewAuthHandler(authUC)
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/change-password", authHandler.ChangePasswordAndLogin)
 	}
 
 	// Tenant-Specific Routes (Wrapped in TenantMiddleware and JWT Auth)
-	// These routes require the 'x-tenant-id' header and JWT authentication
+	// These routes require the 'x-tenant-id' header and JWT authentication; the
+	// optional 'x-organization-id' header selects the organization to work in
 	api := r.Group("/api")
 	api.Use(middleware.JWTAuthMiddleware())             // JWT authentication first
 	api.Use(middleware.TenantMiddleware(tenantManager)) // Then tenant middleware
+	api.Use(middleware.OrganizationMiddleware())        // Then the active organization
+	api.Use(middleware.StoreAccessMiddleware())         // Then access to the stores the request names
 	{
 		// Initialize handlers (they will get repo from context)
 		userHandler := handler.NewUserHandler(userUC)
@@ -132,6 +141,9 @@ func setupRouter(tenantManager *manager.Manager, userUC *usecase.UserUseCase, or
 		permissionHandler := handler.NewPermissionHandler(permissionUC)
 		router.RegisterPermissionRoutes(api, permissionHandler)
 
+		meHandler := handler.NewMeHandler(userUC, authUC, navigationUC, permissionUC)
+		router.RegisterMeRoutes(api, meHandler)
+
 		roleHandler := handler.NewRoleHandler(roleUC)
 		router.RegisterRoleRoutes(api, roleHandler)
 
@@ -150,6 +162,34 @@ func setupRouter(tenantManager *manager.Manager, userUC *usecase.UserUseCase, or
 		storeHandler := handler.NewStoreHandler(storesUC)
 		router.RegisterStoreRoutes(api, storeHandler)
 
+		zatcaHandler := handler.NewZatcaHandler(zatcaUC)
+		router.RegisterZatcaRoutes(api, zatcaHandler)
+
+		salesOrderHandler := handler.NewSalesOrderHandler(salesOrderUC)
+		router.RegisterSalesOrderRoutes(api, salesOrderHandler)
+
+		purchaseOrderHandler := handler.NewPurchaseOrderHandler(purchaseOrderUC)
+		router.RegisterPurchaseOrderRoutes(api, purchaseOrderHandler)
+
+		pricingHandler := handler.NewPricingHandler(pricingUC)
+		router.RegisterPricingRoutes(api, pricingHandler)
+
+		priceListHandler := handler.NewPriceListHandler(priceListUC)
+		router.RegisterPriceListRoutes(api, priceListHandler)
+
+		inventoryHandler := handler.NewInventoryHandler(inventoryUC)
+		router.RegisterInventoryRoutes(api, inventoryHandler)
+		router.RegisterSerialRoutes(api, inventoryHandler)
+
+		expiryAlertHandler := handler.NewExpiryAlertHandler(expiryAlertUC)
+		router.RegisterExpiryAlertRoutes(api, expiryAlertHandler)
+
+		catalogHandler := handler.NewCatalogHandler(catalogUC)
+		router.RegisterCatalogRoutes(api, catalogHandler)
+
+		masterDataHandler := handler.NewMasterDataHandler(masterDataUC)
+		router.RegisterMasterDataRoutes(api, masterDataHandler)
+
 	}
 
 	return r
@@ -214,9 +254,25 @@ func main() {
 	tenantManager := manager.NewManager(masterRepo)
 
 	// Initialize Use Cases (without repository - will be injected per request)
-	userUC := usecase.NewUserUseCase()
+	passwordPolicy := usecase.PasswordPolicy{
+		MinLength:     cfg.PasswordMinLength,
+		RequireUpper:  cfg.PasswordRequireUpper,
+		RequireLower:  cfg.PasswordRequireLower,
+		RequireDigit:  cfg.PasswordRequireDigit,
+		RequireSymbol: cfg.PasswordRequireSymbol,
+		History:       cfg.PasswordHistory,
+		MaxAge:        cfg.PasswordMaxAge,
+	}
+	lockoutPolicy := usecase.LockoutPolicy{
+		MaxFailures:      cfg.LoginMaxFailures,
+		MaxFailuresPerIP: cfg.LoginMaxFailuresPerIP,
+		Window:           cfg.LoginFailureWindow,
+		Lockout:          cfg.LoginLockout,
+		MaxLockout:       cfg.LoginMaxLockout,
+	}
+	userUC := usecase.NewUserUseCase(passwordPolicy)
 	orgUC := usecase.NewOrganizationUseCase()
-	authUC := usecase.NewAuthUseCase()
+	authUC := usecase.NewAuthUseCase(passwordPolicy, lockoutPolicy)
 	moduleUC := usecase.NewModuleUseCase()
 	imageUC := usecase.NewImageUseCase()
 	navigationUC := usecase.NewNavigationUseCase()
@@ -224,12 +280,36 @@ func main() {
 	roleUC := usecase.NewRoleUseCase()
 	menuUC := usecase.NewMenuUseCase()
 	submenuUC := usecase.NewSubmenuUseCase()
-	posUC := usecase.NewPosUseCase()
 	tenantUC := usecase.NewTenantUseCase()
 	storesUC := usecase.NewStoreUseCase()
+	salesOrderUC := usecase.NewSalesOrderUseCase()
+	purchaseOrderUC := usecase.NewPurchaseOrderUseCase()
+	pricingUC := usecase.NewPricingUseCase()
+	priceListUC := usecase.NewPriceListUseCase()
+	inventoryUC := usecase.NewInventoryUseCase()
+	expiryAlertUC := usecase.NewExpiryAlertUseCase()
+	catalogUC := usecase.NewCatalogUseCase()
+	masterDataUC := usecase.NewMasterDataUseCase()
+
+	// ZATCA invoices are signed only when a local signing key is configured
+	var zatcaSigner *zatca.Signer
+	if cfg.ZatcaSigningKeyPath != "" {
+		zatcaSigner, err = zatca.LoadSigner(cfg.ZatcaSigningKeyPath)
+		if err != nil {
+			log.Fatalf("Unable to load ZATCA signing key: %v", err)
+		}
+	}
+	posUC := usecase.NewPosUseCase(zatcaSigner)
+	zatcaUC := usecase.NewZatcaUseCase(zatcaSigner)
+
+	// Background jobs run for every active tenant
+	scheduler := jobs.NewScheduler(masterRepo, tenantManager)
+	scheduler.Add(jobs.ExpiryAlertJob(cfg.ExpiryAlertInterval))
+	scheduler.Add(jobs.CatalogImportJob(cfg.CatalogImportInterval))
+	scheduler.Start(ctx)
 
 	// Setup Router
-	r := setupRouter(tenantManager, userUC, orgUC, authUC, moduleUC, imageUC, navigationUC, permissionUC, roleUC, menuUC, submenuUC, posUC, tenantUC, storesUC, cfg)
+	r := setupRouter(tenantManager, userUC, orgUC, authUC, moduleUC, imageUC, navigationUC, permissionUC, roleUC, menuUC, submenuUC, posUC, tenantUC, storesUC, zatcaUC, salesOrderUC, purchaseOrderUC, pricingUC, priceListUC, inventoryUC, expiryAlertUC, catalogUC, masterDataUC, cfg)
 	// Serve the images folder under /images URL path
 	r.Static("/images", "./images") // <-- this makes /images/* accessible
 
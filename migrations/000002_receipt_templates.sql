@@ -0,0 +1,28 @@
+-- +goose Up
+-- Receipt templates: per-tenant layout for printed POS receipts
+
+CREATE TABLE receipt_templates (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    language VARCHAR(20) NOT NULL DEFAULT 'en' CHECK (language IN ('en', 'ar', 'bilingual')),
+    paper_width INTEGER NOT NULL DEFAULT 42,
+    header_lines JSONB DEFAULT '[]',
+    footer_lines JSONB DEFAULT '[]',
+    show_tax_breakdown BOOLEAN DEFAULT true,
+    show_barcode BOOLEAN DEFAULT true,
+    code_page INTEGER DEFAULT 50,
+    is_default BOOLEAN DEFAULT false,
+    is_active BOOLEAN DEFAULT true,
+    metadata JSONB DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE UNIQUE INDEX idx_receipt_templates_default ON receipt_templates(is_default) WHERE is_default = true;
+
+CREATE TRIGGER update_receipt_templates_updated_at BEFORE UPDATE ON receipt_templates FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose Down
+
+DROP TABLE IF EXISTS receipt_templates CASCADE;
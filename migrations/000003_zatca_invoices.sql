@@ -0,0 +1,27 @@
+-- +goose Up
+-- ZATCA simplified tax invoices generated for POS transactions.
+-- Invoices are chained per terminal: invoice_counter is the ICV and
+-- previous_hash the PIH of the preceding invoice on the same terminal.
+
+CREATE TABLE zatca_invoices (
+    id SERIAL PRIMARY KEY,
+    transaction_id INTEGER UNIQUE NOT NULL REFERENCES pos_transactions(id) ON DELETE CASCADE,
+    pos_terminal_id INTEGER NOT NULL REFERENCES pos_terminals(id) ON DELETE CASCADE,
+    invoice_counter BIGINT NOT NULL,
+    invoice_uuid UUID NOT NULL,
+    invoice_hash VARCHAR(100) NOT NULL,
+    previous_hash VARCHAR(100) NOT NULL,
+    qr_code TEXT NOT NULL,
+    invoice_xml TEXT NOT NULL,
+    signature TEXT,
+    status VARCHAR(20) DEFAULT 'generated',
+    metadata JSONB DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(pos_terminal_id, invoice_counter)
+);
+
+CREATE INDEX idx_zatca_invoices_terminal ON zatca_invoices(pos_terminal_id, invoice_counter DESC);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS zatca_invoices CASCADE;
@@ -0,0 +1,38 @@
+-- +goose Up
+-- Assigns price lists to customers, customer types, stores and promotions.
+-- The price resolver tries applicable lists in scope order
+-- customer > customer_type > store > promotion > default, and by descending
+-- priority within a scope. customers.price_list_id and price_lists.is_default
+-- keep working as customer and default assignments.
+
+CREATE TABLE price_list_assignments (
+    id SERIAL PRIMARY KEY,
+    price_list_id INTEGER NOT NULL REFERENCES price_lists(id) ON DELETE CASCADE,
+    scope VARCHAR(20) NOT NULL,
+    customer_id INTEGER REFERENCES customers(id) ON DELETE CASCADE,
+    customer_type VARCHAR(50),
+    store_id INTEGER REFERENCES stores(id) ON DELETE CASCADE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    valid_from DATE,
+    valid_to DATE,
+    is_active BOOLEAN DEFAULT true,
+    metadata JSONB DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_price_list_assignments_scope CHECK (
+        (scope = 'customer' AND customer_id IS NOT NULL)
+        OR (scope = 'customer_type' AND customer_type IS NOT NULL)
+        OR (scope = 'store' AND store_id IS NOT NULL)
+        OR scope = 'promotion'
+    )
+);
+
+CREATE INDEX idx_price_list_assignments_scope ON price_list_assignments(scope, is_active);
+CREATE INDEX idx_price_list_assignments_customer ON price_list_assignments(customer_id) WHERE customer_id IS NOT NULL;
+CREATE INDEX idx_price_list_assignments_store ON price_list_assignments(store_id) WHERE store_id IS NOT NULL;
+
+CREATE TRIGGER update_price_list_assignments_updated_at BEFORE UPDATE ON price_list_assignments FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose Down
+
+DROP TABLE IF EXISTS price_list_assignments CASCADE;
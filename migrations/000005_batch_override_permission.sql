@@ -0,0 +1,19 @@
+-- +goose Up
+-- Batch-managed products are sold and transferred first-expiry-first-out.
+-- Picking a batch by hand needs the inventory.batch_override permission.
+
+INSERT INTO permissions (name, code, description)
+VALUES (
+    'Override batch allocation',
+    'inventory.batch_override',
+    'Choose the batch to sell or transfer instead of first-expiry-first-out allocation'
+)
+ON CONFLICT (code) DO NOTHING;
+
+CREATE INDEX idx_product_batches_allocation
+    ON product_batches(product_id, store_id, expiry_date)
+    WHERE status = 'active';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_product_batches_allocation;
+DELETE FROM permissions WHERE code = 'inventory.batch_override';
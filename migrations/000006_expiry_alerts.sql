@@ -0,0 +1,65 @@
+-- +goose Up
+-- Expiry alerting. A scheduled job marks expired batches, raises
+-- inventory_alerts for expired and near-expiry stock per store and suggests
+-- markdown prices. expiry_alert_rules sets the near-expiry window and the
+-- largest markdown per product category; categories without a rule use
+-- their parent's, and the rule without a category is the default.
+
+CREATE TABLE expiry_alert_rules (
+    id SERIAL PRIMARY KEY,
+    category_id INTEGER REFERENCES product_categories(id) ON DELETE CASCADE,
+    near_expiry_days INTEGER NOT NULL DEFAULT 30,
+    max_markdown_percent DECIMAL(5,2) NOT NULL DEFAULT 0,
+    is_active BOOLEAN DEFAULT true,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_expiry_alert_rules_days CHECK (near_expiry_days > 0),
+    CONSTRAINT chk_expiry_alert_rules_markdown CHECK (max_markdown_percent >= 0 AND max_markdown_percent < 100)
+);
+
+CREATE UNIQUE INDEX idx_expiry_alert_rules_category ON expiry_alert_rules(category_id) WHERE category_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_expiry_alert_rules_default ON expiry_alert_rules((category_id IS NULL)) WHERE category_id IS NULL;
+
+CREATE TRIGGER update_expiry_alert_rules_updated_at BEFORE UPDATE ON expiry_alert_rules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO expiry_alert_rules (category_id, near_expiry_days, max_markdown_percent)
+VALUES (NULL, 30, 30);
+
+-- One alert per alert type and batch or serialized unit. Re-running the job
+-- refreshes open alerts; near-expiry alerts whose stock is gone or expired
+-- are resolved.
+CREATE TABLE inventory_alerts (
+    id SERIAL PRIMARY KEY,
+    alert_type VARCHAR(50) NOT NULL,
+    store_id INTEGER NOT NULL REFERENCES stores(id) ON DELETE CASCADE,
+    product_id INTEGER NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    product_variant_id INTEGER REFERENCES product_variants(id) ON DELETE CASCADE,
+    batch_id INTEGER REFERENCES product_batches(id) ON DELETE CASCADE,
+    serial_number_id INTEGER REFERENCES product_serial_numbers(id) ON DELETE CASCADE,
+    expiry_date DATE,
+    days_until_expiry INTEGER,
+    quantity DECIMAL(15,3),
+    current_price DECIMAL(15,2),
+    suggested_price DECIMAL(15,2),
+    markdown_percent DECIMAL(5,2),
+    message TEXT,
+    status VARCHAR(20) NOT NULL DEFAULT 'open',
+    acknowledged_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    acknowledged_at TIMESTAMP,
+    resolved_at TIMESTAMP,
+    last_evaluated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_inventory_alerts_status CHECK (status IN ('open', 'acknowledged', 'resolved'))
+);
+
+CREATE UNIQUE INDEX idx_inventory_alerts_source
+    ON inventory_alerts(alert_type, (COALESCE(batch_id, 0)), (COALESCE(serial_number_id, 0)));
+CREATE INDEX idx_inventory_alerts_store ON inventory_alerts(store_id, status, created_at);
+
+CREATE TRIGGER update_inventory_alerts_updated_at BEFORE UPDATE ON inventory_alerts FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose Down
+
+DROP TABLE IF EXISTS inventory_alerts CASCADE;
+DROP TABLE IF EXISTS expiry_alert_rules CASCADE;
@@ -0,0 +1,36 @@
+-- +goose Up
+-- Bulk catalog imports run as background jobs. The uploaded file is kept
+-- in input_file until the job finishes; errors holds one entry per bad row
+-- and summary the counts of what was created or would be in a dry run.
+
+CREATE TABLE catalog_import_jobs (
+    id SERIAL PRIMARY KEY,
+    organization_id INTEGER NOT NULL REFERENCES organizations(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued',
+    dry_run BOOLEAN NOT NULL DEFAULT false,
+    options JSONB DEFAULT '{}',
+    input_file BYTEA,
+    total_rows INTEGER NOT NULL DEFAULT 0,
+    processed_rows INTEGER NOT NULL DEFAULT 0,
+    succeeded_rows INTEGER NOT NULL DEFAULT 0,
+    failed_rows INTEGER NOT NULL DEFAULT 0,
+    errors JSONB DEFAULT '[]',
+    summary JSONB DEFAULT '{}',
+    error_message TEXT,
+    created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_catalog_import_jobs_status CHECK (status IN ('queued', 'running', 'completed', 'failed'))
+);
+
+CREATE INDEX idx_catalog_import_jobs_status ON catalog_import_jobs(status, id);
+CREATE INDEX idx_catalog_import_jobs_org ON catalog_import_jobs(organization_id, created_at);
+
+CREATE TRIGGER update_catalog_import_jobs_updated_at BEFORE UPDATE ON catalog_import_jobs FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+-- +goose Down
+
+DROP TABLE IF EXISTS catalog_import_jobs CASCADE;
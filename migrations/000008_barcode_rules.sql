@@ -0,0 +1,39 @@
+-- +goose Up
+-- Variable-measure barcode rules: per-tenant layouts of the in-store EAN-13
+-- barcodes printed by scales (prefix 20-29). A rule gives the prefix, the
+-- digits of the item code and of the embedded value, and whether the value
+-- is a weight, a price or a count. POS search resolves the item code to a
+-- product and pre-fills the cart line with the value.
+
+CREATE TABLE barcode_rules (
+    id SERIAL PRIMARY KEY,
+    prefix VARCHAR(3) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    value_type VARCHAR(20) NOT NULL,
+    item_digits INTEGER NOT NULL DEFAULT 5,
+    value_digits INTEGER NOT NULL DEFAULT 5,
+    value_decimals INTEGER NOT NULL DEFAULT 3,
+    value_check_digit BOOLEAN NOT NULL DEFAULT false,
+    is_active BOOLEAN DEFAULT true,
+    metadata JSONB DEFAULT '{}',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    CONSTRAINT chk_barcode_rules_prefix CHECK (prefix ~ '^2[0-9]{1,2}$'),
+    CONSTRAINT chk_barcode_rules_value_type CHECK (value_type IN ('weight', 'price', 'count')),
+    CONSTRAINT chk_barcode_rules_layout CHECK (
+        length(prefix) + item_digits + value_digits + (CASE WHEN value_check_digit THEN 1 ELSE 0 END) = 12
+        AND item_digits > 0 AND value_digits > 0
+        AND value_decimals >= 0 AND value_decimals <= value_digits
+    )
+);
+
+CREATE TRIGGER update_barcode_rules_updated_at BEFORE UPDATE ON barcode_rules FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO barcode_rules (prefix, name, value_type, item_digits, value_digits, value_decimals)
+VALUES
+    ('21', 'Scale weight (kg)', 'weight', 5, 5, 3),
+    ('22', 'Scale price', 'price', 5, 5, 2);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS barcode_rules CASCADE;
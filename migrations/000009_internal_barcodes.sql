@@ -0,0 +1,10 @@
+-- +goose Up
+-- Internal barcodes: a per-tenant sequence numbers the EAN-13 codes the
+-- catalog generates for products and variants that have no barcode. The
+-- codes use an in-store prefix (20-29) that no scale barcode rule claims.
+
+CREATE SEQUENCE internal_barcode_seq START WITH 1 INCREMENT BY 1;
+
+-- +goose Down
+
+DROP SEQUENCE IF EXISTS internal_barcode_seq;
@@ -0,0 +1,28 @@
+-- +goose Up
+-- Audit trail of tax categories: every create, rate or inclusive change,
+-- activation and delete, with the user who made it. A tax change affects
+-- every price downstream, so rows keep the category code and survive the
+-- category being deleted.
+
+CREATE TABLE tax_category_changes (
+    id SERIAL PRIMARY KEY,
+    tax_category_id INTEGER REFERENCES tax_categories(id) ON DELETE SET NULL,
+    tax_category_code VARCHAR(50) NOT NULL,
+    action VARCHAR(20) NOT NULL,
+    old_tax_rate DECIMAL(5,2),
+    new_tax_rate DECIMAL(5,2),
+    old_is_inclusive BOOLEAN,
+    new_is_inclusive BOOLEAN,
+    old_is_active BOOLEAN,
+    new_is_active BOOLEAN,
+    changed_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    metadata JSONB DEFAULT '{}',
+    CONSTRAINT chk_tax_category_changes_action CHECK (action IN ('created', 'updated', 'deleted'))
+);
+
+CREATE INDEX idx_tax_category_changes_category ON tax_category_changes(tax_category_id, changed_at DESC);
+
+-- +goose Down
+
+DROP TABLE IF EXISTS tax_category_changes CASCADE;
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Users read their own profile, navigation and permissions from /api/me.
+-- Reading another user's by ID needs the users.view_any permission, which
+-- the administrator roles get here.
+
+INSERT INTO permissions (name, code, description)
+VALUES (
+    'View any user',
+    'users.view_any',
+    'Read the profile, navigation, permissions and routes of other users'
+)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.code IN ('ADMIN', 'SUPER_ADMIN') AND p.code = 'users.view_any'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE code = 'users.view_any';
@@ -0,0 +1,21 @@
+-- +goose Up
+-- Creating, changing and deleting permissions, mapping them to modules, menus
+-- and submenus and granting them to roles through /api/permissions needs the
+-- permissions.manage permission, which the administrator roles get here.
+
+INSERT INTO permissions (name, code, description)
+VALUES (
+    'Manage permissions',
+    'permissions.manage',
+    'Create, change and delete permissions, map them to navigation and grant them to roles'
+)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.code IN ('ADMIN', 'SUPER_ADMIN') AND p.code = 'permissions.manage'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE code = 'permissions.manage';
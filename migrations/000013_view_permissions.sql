@@ -0,0 +1,34 @@
+-- +goose Up
+-- Reading stores, stock, POS transactions and sales and purchase orders needs
+-- these permissions; the scope of the grant limits the rows a user sees.
+-- Every existing role gets them with scope all so nobody loses access.
+
+INSERT INTO permissions (name, code, description)
+VALUES
+    ('View stores', 'stores.view', 'Read stores and their storage locations'),
+    ('View stock', 'inventory.view', 'Read the products, stock and expiry alerts of a store'),
+    ('View POS transactions', 'pos.transactions.view', 'Read POS receipts and invoices'),
+    ('View sales orders', 'sales_orders.view', 'Read sales orders'),
+    ('View purchase orders', 'purchase_orders.view', 'Read purchase orders')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id, scope)
+SELECT r.id, p.id, 'all'
+FROM roles r, permissions p
+WHERE p.code IN (
+    'stores.view',
+    'inventory.view',
+    'pos.transactions.view',
+    'sales_orders.view',
+    'purchase_orders.view'
+)
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE code IN (
+    'stores.view',
+    'inventory.view',
+    'pos.transactions.view',
+    'sales_orders.view',
+    'purchase_orders.view'
+);
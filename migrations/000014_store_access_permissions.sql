@@ -0,0 +1,21 @@
+-- +goose Up
+-- Requests naming a store (store_id in the path, query or body) need a row in
+-- user_store_access for it, unless the user has stores.access_any. Granting
+-- and revoking store access needs stores.access_manage. The administrator
+-- roles get both here.
+
+INSERT INTO permissions (name, code, description)
+VALUES
+    ('Access any store', 'stores.access_any', 'Work in every store without being granted access to it'),
+    ('Manage store access', 'stores.access_manage', 'Grant and revoke the access of users to stores')
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.code IN ('ADMIN', 'SUPER_ADMIN')
+  AND p.code IN ('stores.access_any', 'stores.access_manage')
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE code IN ('stores.access_any', 'stores.access_manage');
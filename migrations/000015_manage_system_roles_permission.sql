@@ -0,0 +1,21 @@
+-- +goose Up
+-- Creating system roles and changing them needs the roles.manage_system
+-- permission, which the super administrator role gets here. System roles can
+-- never be deleted or deactivated.
+
+INSERT INTO permissions (name, code, description)
+VALUES (
+    'Manage system roles',
+    'roles.manage_system',
+    'Create system roles and change their name, description and metadata'
+)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.code = 'SUPER_ADMIN' AND p.code = 'roles.manage_system'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE code = 'roles.manage_system';
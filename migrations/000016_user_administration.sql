@@ -0,0 +1,32 @@
+-- +goose Up
+-- Deactivating a user and resetting or changing a password revoke the user's
+-- sessions: tokens issued before sessions_revoked_at are refused. Changing
+-- users, their roles and their passwords needs the users.manage permission,
+-- which the administrator roles get here.
+
+CREATE TABLE user_security (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    sessions_revoked_at TIMESTAMP,
+    password_changed_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TRIGGER update_user_security_updated_at BEFORE UPDATE ON user_security FOR EACH ROW EXECUTE FUNCTION update_updated_at_column();
+
+INSERT INTO permissions (name, code, description)
+VALUES (
+    'Manage users',
+    'users.manage',
+    'Change users, activate and deactivate them, reset their passwords and revoke their roles'
+)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id
+FROM roles r, permissions p
+WHERE r.code IN ('ADMIN', 'SUPER_ADMIN') AND p.code = 'users.manage'
+ON CONFLICT (role_id, permission_id) DO NOTHING;
+
+-- +goose Down
+DELETE FROM permissions WHERE code = 'users.manage';
+DROP TABLE IF EXISTS user_security;
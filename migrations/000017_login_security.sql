@@ -0,0 +1,33 @@
+-- +goose Up
+-- Every login attempt is audited with the client's IP address and user
+-- agent. Failed attempts lock out the login name, and separately the IP
+-- address, for a while; see usecase.LockoutPolicy. Replaced password hashes
+-- are kept so recent passwords can't be reused.
+
+CREATE TABLE login_audit (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    user_login VARCHAR(255) NOT NULL,
+    success BOOLEAN NOT NULL,
+    failure_reason VARCHAR(50),
+    ip_address VARCHAR(45),
+    user_agent TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_login_audit_user_login ON login_audit(user_login, created_at);
+CREATE INDEX idx_login_audit_ip_address ON login_audit(ip_address, created_at);
+CREATE INDEX idx_login_audit_user_id ON login_audit(user_id, created_at);
+
+CREATE TABLE password_history (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    password_hash VARCHAR(255) NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_password_history_user ON password_history(user_id, created_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS password_history;
+DROP TABLE IF EXISTS login_audit;
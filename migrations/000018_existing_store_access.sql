@@ -0,0 +1,28 @@
+-- +goose Up
+-- 000014 made requests naming a store need a row in user_store_access or
+-- stores.access_any, which only the administrator roles got. Existing users
+-- are granted the stores they are assigned to today so nobody loses access:
+-- cashiers the stores they work at, with the first one as primary, and users
+-- without a cashier the stores of their organization. Narrow a user's access
+-- by revoking stores from them.
+
+INSERT INTO user_store_access (user_id, store_id, is_primary, metadata)
+SELECT DISTINCT ON (c.user_id, c.store_id)
+    c.user_id,
+    c.store_id,
+    c.id = (SELECT MIN(c2.id) FROM cashiers c2 WHERE c2.user_id = c.user_id)
+        AND NOT EXISTS (SELECT 1 FROM user_store_access a WHERE a.user_id = c.user_id AND a.is_primary),
+    '{"granted_by": "000018"}'::jsonb
+FROM cashiers c
+ORDER BY c.user_id, c.store_id, c.id
+ON CONFLICT (user_id, store_id) DO NOTHING;
+
+INSERT INTO user_store_access (user_id, store_id, is_primary, metadata)
+SELECT u.id, s.id, false, '{"granted_by": "000018"}'::jsonb
+FROM users u
+JOIN stores s ON s.organization_id = u.organization_id
+WHERE NOT EXISTS (SELECT 1 FROM cashiers c WHERE c.user_id = u.id)
+ON CONFLICT (user_id, store_id) DO NOTHING;
+
+-- +goose Down
+DELETE FROM user_store_access WHERE metadata->>'granted_by' = '000018';
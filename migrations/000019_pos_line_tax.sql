@@ -0,0 +1,30 @@
+-- +goose Up
+-- POS lines keep the tax rate they were charged at in their metadata, so
+-- receipts, returns and invoices no longer follow later edits of the
+-- product's tax category. Existing lines take the rate their product has now.
+
+UPDATE pos_transaction_lines tl
+SET metadata = COALESCE(tl.metadata, '{}'::jsonb) || jsonb_build_object(
+        'tax_code',      COALESCE(tc.code, ''),
+        'tax_name',      COALESCE(tc.name, ''),
+        'tax_rate',      CASE WHEN COALESCE((t.metadata->>'tax_exempt')::bool, false) THEN 0 ELSE COALESCE(tc.tax_rate, 0) END,
+        'tax_inclusive', COALESCE(tc.is_inclusive, false),
+        'tax_exempt',    COALESCE((t.metadata->>'tax_exempt')::bool, false))
+FROM pos_transactions t, products p
+LEFT JOIN tax_categories tc ON p.tax_category_id = tc.id
+WHERE t.id = tl.transaction_id
+  AND p.id = tl.product_id
+  AND NOT COALESCE(tl.metadata, '{}'::jsonb) ? 'tax_rate';
+
+-- ESC/POS table 37 is PC864 (Arabic); 50 was never an Arabic code page on
+-- Epson printers. English receipts always use Windows-1252.
+ALTER TABLE receipt_templates ALTER COLUMN code_page SET DEFAULT 37;
+UPDATE receipt_templates SET code_page = 37 WHERE code_page = 50;
+
+-- +goose Down
+
+ALTER TABLE receipt_templates ALTER COLUMN code_page SET DEFAULT 50;
+UPDATE receipt_templates SET code_page = 50 WHERE code_page = 37;
+
+UPDATE pos_transaction_lines
+SET metadata = metadata - 'tax_code' - 'tax_name' - 'tax_rate' - 'tax_inclusive' - 'tax_exempt';
@@ -0,0 +1,38 @@
+-- name: DeleteBarcodeRule :execrows
+DELETE FROM barcode_rules
+WHERE prefix = $1;
+
+-- name: ListActiveBarcodeRules :many
+-- Longest prefix first, so a three-digit rule wins over a two-digit one.
+SELECT * FROM barcode_rules
+WHERE is_active = true
+ORDER BY length(prefix) DESC, prefix;
+
+-- name: ListBarcodeRules :many
+SELECT * FROM barcode_rules
+ORDER BY prefix;
+
+-- name: UpsertBarcodeRule :one
+INSERT INTO barcode_rules (
+    prefix,
+    name,
+    value_type,
+    item_digits,
+    value_digits,
+    value_decimals,
+    value_check_digit,
+    is_active,
+    metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9
+)
+ON CONFLICT (prefix) DO UPDATE SET
+    name = EXCLUDED.name,
+    value_type = EXCLUDED.value_type,
+    item_digits = EXCLUDED.item_digits,
+    value_digits = EXCLUDED.value_digits,
+    value_decimals = EXCLUDED.value_decimals,
+    value_check_digit = EXCLUDED.value_check_digit,
+    is_active = EXCLUDED.is_active,
+    metadata = EXCLUDED.metadata
+RETURNING *;
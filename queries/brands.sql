@@ -5,8 +5,12 @@ SELECT
     COUNT(DISTINCT p.category_id) AS category_count
 FROM brands b
 LEFT JOIN products p ON p.brand_id = b.id AND p.is_active = true
-WHERE b.is_active = sqlc.arg('active_only')
-  AND (sqlc.arg('search')::text IS NULL OR b.name ILIKE '%' || sqlc.arg('search') || '%')
+WHERE (sqlc.narg('active_only')::boolean IS NULL OR b.is_active = sqlc.narg('active_only'))
+  AND (sqlc.arg('search')::text IS NULL OR b.name ILIKE '%' || sqlc.arg('search') || '%' OR b.code ILIKE '%' || sqlc.arg('search') || '%')
 GROUP BY b.id
 ORDER BY product_count DESC, b.name
-LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
\ No newline at end of file
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: CountBrandProducts :one
+SELECT COUNT(*) FROM products
+WHERE brand_id = $1;
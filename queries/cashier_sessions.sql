@@ -27,6 +27,16 @@ WHERE cs.cashier_id = $1
 ORDER BY cs.opening_time DESC
 LIMIT 1;
 
+-- name: GetActiveCashierIDByUser :one
+SELECT cs.cashier_id
+FROM cashier_sessions cs
+JOIN cashiers c ON cs.cashier_id = c.id
+WHERE c.user_id = $1
+  AND cs.status = 'open'
+  AND cs.closing_time IS NULL
+ORDER BY cs.opening_time DESC
+LIMIT 1;
+
 -- name: CloseCashierSession :one
 UPDATE cashier_sessions
 SET 
@@ -28,4 +28,8 @@ LEFT JOIN cashier_sessions cs ON cs.cashier_id = c.id AND cs.status = 'open'
 WHERE c.store_id = $1
   AND c.is_active = true
 GROUP BY c.id, u.first_name, u.last_name
-ORDER BY u.first_name;
\ No newline at end of file
+ORDER BY u.first_name;
+
+-- name: GetCashier :one
+SELECT * FROM cashiers
+WHERE id = $1;
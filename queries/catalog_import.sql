@@ -0,0 +1,138 @@
+-- name: CreateCatalogImportJob :one
+INSERT INTO catalog_import_jobs (
+    organization_id,
+    file_name,
+    dry_run,
+    options,
+    input_file,
+    created_by
+) VALUES (
+    $1, $2, $3, $4, $5, $6
+) RETURNING *;
+
+-- name: GetCatalogImportJob :one
+SELECT * FROM catalog_import_jobs
+WHERE id = $1;
+
+-- name: ListCatalogImportJobs :many
+SELECT * FROM catalog_import_jobs
+WHERE organization_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2 OFFSET $3;
+
+-- name: ClaimCatalogImportJob :one
+-- Marks the oldest queued job, or the given one, as running. A job another
+-- worker has already claimed is skipped.
+UPDATE catalog_import_jobs
+SET status = 'running',
+    started_at = CURRENT_TIMESTAMP
+WHERE id = (
+    SELECT j.id FROM catalog_import_jobs j
+    WHERE j.status = 'queued'
+      AND (sqlc.narg('id')::int IS NULL OR j.id = sqlc.narg('id')::int)
+    ORDER BY j.id
+    LIMIT 1
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: UpdateCatalogImportProgress :exec
+UPDATE catalog_import_jobs
+SET total_rows = $2,
+    processed_rows = $3,
+    succeeded_rows = $4,
+    failed_rows = $5
+WHERE id = $1;
+
+-- name: FinishCatalogImportJob :exec
+-- Records the outcome of a job and drops the uploaded file.
+UPDATE catalog_import_jobs
+SET status = $2,
+    total_rows = $3,
+    processed_rows = $4,
+    succeeded_rows = $5,
+    failed_rows = $6,
+    errors = $7,
+    summary = $8,
+    error_message = $9,
+    input_file = NULL,
+    finished_at = CURRENT_TIMESTAMP
+WHERE id = $1;
+
+-- name: HasInventoryStock :one
+-- Reports whether a store has a stock row for a product or variant.
+SELECT EXISTS (
+    SELECT 1 FROM inventory_stock
+    WHERE product_id = sqlc.arg('product_id')
+      AND store_id = sqlc.arg('store_id')
+      AND product_variant_id IS NOT DISTINCT FROM sqlc.narg('product_variant_id')
+) AS has_stock;
+
+-- name: ListCatalogExportItems :many
+-- Products of an organization with their reference codes, one row per
+-- variant (a single row with NULL variant columns when there are none).
+SELECT
+    p.id AS product_id,
+    p.sku,
+    p.name,
+    p.description,
+    p.product_type,
+    pc.code AS category_code,
+    b.code AS brand_code,
+    u.code AS uom_code,
+    tc.code AS tax_category_code,
+    p.is_serialized,
+    p.is_batch_managed,
+    p.is_active,
+    p.is_sellable,
+    p.is_purchasable,
+    p.allow_decimal_quantity,
+    p.track_inventory,
+    pv.id AS variant_id,
+    pv.variant_sku,
+    pv.variant_name,
+    pv.variant_attributes
+FROM products p
+LEFT JOIN product_categories pc ON p.category_id = pc.id
+LEFT JOIN brands b ON p.brand_id = b.id
+LEFT JOIN units_of_measure u ON p.base_uom_id = u.id
+LEFT JOIN tax_categories tc ON p.tax_category_id = tc.id
+LEFT JOIN product_variants pv ON pv.product_id = p.id
+WHERE p.organization_id = $1
+ORDER BY p.sku, pv.variant_sku NULLS FIRST;
+
+-- name: ListCatalogExportBarcodes :many
+-- Barcodes of an organization's products, primary barcode first.
+SELECT pb.product_id, pb.product_variant_id, pb.barcode, pb.barcode_type
+FROM product_barcodes pb
+INNER JOIN products p ON pb.product_id = p.id
+WHERE p.organization_id = $1
+ORDER BY pb.product_id, pb.is_primary DESC, pb.id;
+
+-- name: ListCatalogExportPrices :many
+-- The price running today in a list for each product and variant of an
+-- organization, in the base unit and for a quantity of one.
+SELECT DISTINCT ON (pp.product_id, pp.product_variant_id)
+    pp.product_id,
+    pp.product_variant_id,
+    pp.price
+FROM product_prices pp
+INNER JOIN products p ON pp.product_id = p.id
+WHERE p.organization_id = $1
+  AND pp.price_list_id = $2
+  AND pp.is_active = true
+  AND (pp.uom_id IS NULL OR pp.uom_id = p.base_uom_id)
+  AND COALESCE(pp.min_quantity, 0) <= 1
+  AND (pp.valid_from IS NULL OR pp.valid_from <= CURRENT_DATE)
+  AND (pp.valid_to IS NULL OR pp.valid_to >= CURRENT_DATE)
+ORDER BY pp.product_id, pp.product_variant_id, pp.valid_from DESC NULLS LAST, pp.id DESC;
+
+-- name: ListCatalogExportStock :many
+-- Quantity on hand in a store for each product and variant of an
+-- organization.
+SELECT s.product_id, s.product_variant_id, SUM(COALESCE(s.quantity_on_hand, 0))::numeric AS quantity
+FROM inventory_stock s
+INNER JOIN products p ON s.product_id = p.id
+WHERE p.organization_id = $1
+  AND s.store_id = $2
+GROUP BY s.product_id, s.product_variant_id;
@@ -0,0 +1,99 @@
+-- name: CreateExpiryAlertRule :one
+INSERT INTO expiry_alert_rules (
+    category_id,
+    near_expiry_days,
+    max_markdown_percent
+) VALUES (
+    $1, $2, $3
+) RETURNING *;
+
+-- name: DeleteExpiryAlertRule :execrows
+DELETE FROM expiry_alert_rules
+WHERE category_id = $1;
+
+-- name: ListCategoryParents :many
+SELECT id, parent_category_id FROM product_categories;
+
+-- name: ListExpiryAlertRules :many
+SELECT * FROM expiry_alert_rules
+WHERE is_active = true
+ORDER BY category_id NULLS FIRST;
+
+-- name: ListStoreInventoryAlerts :many
+-- Alerts of a store, soonest expiry first. Without a status filter resolved
+-- alerts are left out.
+SELECT
+    ia.*,
+    p.sku AS product_sku,
+    p.name AS product_name,
+    pb.batch_number,
+    psn.serial_number
+FROM inventory_alerts ia
+INNER JOIN products p ON ia.product_id = p.id
+LEFT JOIN product_batches pb ON ia.batch_id = pb.id
+LEFT JOIN product_serial_numbers psn ON ia.serial_number_id = psn.id
+WHERE ia.store_id = sqlc.arg('store_id')
+  AND (
+    (sqlc.narg('status')::text IS NULL AND ia.status <> 'resolved')
+    OR ia.status = sqlc.narg('status')::text
+  )
+  AND ia.alert_type = COALESCE(sqlc.narg('alert_type')::text, ia.alert_type)
+ORDER BY ia.expiry_date NULLS LAST, ia.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: ResolveStaleInventoryAlerts :execrows
+-- Resolves unresolved alerts of a type that the last run no longer raised.
+UPDATE inventory_alerts
+SET status = 'resolved',
+    resolved_at = CURRENT_TIMESTAMP
+WHERE alert_type = sqlc.arg('alert_type')
+  AND status <> 'resolved'
+  AND last_evaluated_at < sqlc.arg('evaluated_before');
+
+-- name: UpdateExpiryAlertRule :execrows
+UPDATE expiry_alert_rules
+SET near_expiry_days = sqlc.arg('near_expiry_days'),
+    max_markdown_percent = sqlc.arg('max_markdown_percent'),
+    is_active = true
+WHERE category_id IS NOT DISTINCT FROM sqlc.narg('category_id');
+
+-- name: UpdateInventoryAlertStatus :one
+UPDATE inventory_alerts
+SET status = sqlc.arg('status'),
+    acknowledged_by = COALESCE(acknowledged_by, sqlc.narg('user_id')),
+    acknowledged_at = COALESCE(acknowledged_at, CURRENT_TIMESTAMP),
+    resolved_at = CASE WHEN sqlc.arg('status') = 'resolved' THEN CURRENT_TIMESTAMP END
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: UpsertInventoryAlert :exec
+-- Raises an alert or refreshes the one already raised for the batch or unit.
+-- The status set by users is kept.
+INSERT INTO inventory_alerts (
+    alert_type,
+    store_id,
+    product_id,
+    product_variant_id,
+    batch_id,
+    serial_number_id,
+    expiry_date,
+    days_until_expiry,
+    quantity,
+    current_price,
+    suggested_price,
+    markdown_percent,
+    message,
+    last_evaluated_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14
+)
+ON CONFLICT (alert_type, (COALESCE(batch_id, 0)), (COALESCE(serial_number_id, 0))) DO UPDATE SET
+    store_id = EXCLUDED.store_id,
+    expiry_date = EXCLUDED.expiry_date,
+    days_until_expiry = EXCLUDED.days_until_expiry,
+    quantity = EXCLUDED.quantity,
+    current_price = EXCLUDED.current_price,
+    suggested_price = EXCLUDED.suggested_price,
+    markdown_percent = EXCLUDED.markdown_percent,
+    message = EXCLUDED.message,
+    last_evaluated_at = EXCLUDED.last_evaluated_at;
@@ -34,4 +34,29 @@ FROM inventory_stock s
 JOIN stores st ON s.store_id = st.id
 WHERE st.organization_id = sqlc.arg('org_id')
 GROUP BY s.store_id, st.name
-ORDER BY total_stock_value DESC;
\ No newline at end of file
+ORDER BY total_stock_value DESC;
+
+-- name: AdjustInventoryStock :execrows
+-- Applies a signed quantity change to the store's stock row for a product
+-- (the unlocated row first when stock is split across locations).
+UPDATE inventory_stock
+SET quantity_on_hand   = COALESCE(quantity_on_hand, 0) + sqlc.arg('quantity_delta'),
+    quantity_available = COALESCE(quantity_available, 0) + sqlc.arg('quantity_delta'),
+    updated_at         = CURRENT_TIMESTAMP
+WHERE id = (
+    SELECT s.id FROM inventory_stock s
+    WHERE s.product_id = sqlc.arg('product_id')
+      AND s.store_id = sqlc.arg('store_id')
+      AND s.product_variant_id IS NOT DISTINCT FROM sqlc.narg('product_variant_id')
+    ORDER BY s.storage_location_id NULLS FIRST, s.id
+    LIMIT 1
+);
+
+-- name: CreateInventoryStock :exec
+-- Opens the store's stock row for a product that has none yet.
+INSERT INTO inventory_stock (
+    product_id, product_variant_id, store_id, quantity_on_hand, quantity_available
+) VALUES (
+    sqlc.arg('product_id'), sqlc.narg('product_variant_id'), sqlc.arg('store_id'),
+    sqlc.arg('quantity'), sqlc.arg('quantity')
+);
@@ -0,0 +1,62 @@
+-- name: ListItemsWithoutBarcode :many
+-- Active products without variants that have no barcode, and active
+-- variants without a barcode of their own. All products of the
+-- organization when product_ids is null.
+SELECT
+    p.id AS product_id,
+    v.id AS product_variant_id,
+    COALESCE(v.variant_sku, p.sku)::text AS sku
+FROM products p
+LEFT JOIN product_variants v ON v.product_id = p.id AND v.is_active = true
+WHERE p.organization_id = sqlc.arg('organization_id')
+  AND p.is_active = true
+  AND (sqlc.narg('product_ids')::int[] IS NULL OR p.id = ANY(sqlc.narg('product_ids')::int[]))
+  AND (
+      (v.id IS NULL AND NOT EXISTS (
+          SELECT 1 FROM product_barcodes b WHERE b.product_id = p.id
+      ))
+      OR (v.id IS NOT NULL AND NOT EXISTS (
+          SELECT 1 FROM product_barcodes b WHERE b.product_variant_id = v.id
+      ))
+  )
+ORDER BY p.id, v.id;
+
+-- name: ListLabelItems :many
+-- One row per active product or variant to label, with the barcode to
+-- print: the variant's own, else the product-level one, primary first.
+SELECT
+    p.id AS product_id,
+    p.base_uom_id,
+    v.id AS product_variant_id,
+    p.name,
+    v.variant_name,
+    COALESCE(v.variant_sku, p.sku)::text AS sku,
+    u.code AS uom_code,
+    bc.barcode
+FROM products p
+LEFT JOIN product_variants v ON v.product_id = p.id AND v.is_active = true
+LEFT JOIN units_of_measure u ON u.id = p.base_uom_id
+LEFT JOIN LATERAL (
+    SELECT b.barcode
+    FROM product_barcodes b
+    WHERE b.product_id = p.id
+      AND (b.product_variant_id = v.id OR b.product_variant_id IS NULL)
+    ORDER BY (b.product_variant_id IS NOT NULL) DESC, b.is_primary DESC NULLS LAST, b.id
+    LIMIT 1
+) bc ON true
+WHERE p.organization_id = sqlc.arg('organization_id')
+  AND p.is_active = true
+  AND p.id = ANY(sqlc.arg('product_ids')::int[])
+ORDER BY p.name, v.variant_name NULLS FIRST, v.id;
+
+-- name: ListPriceChangeProductIDs :many
+-- Products whose price in a list changes on a date: the batch written by a
+-- scheduled price change or adjustment.
+SELECT DISTINCT product_id
+FROM product_prices
+WHERE price_list_id = sqlc.arg('price_list_id')
+  AND valid_from = sqlc.arg('valid_from')
+ORDER BY product_id;
+
+-- name: NextInternalBarcodeNumber :one
+SELECT nextval('internal_barcode_seq')::bigint AS number;
@@ -0,0 +1,36 @@
+-- name: CreateLoginAudit :exec
+INSERT INTO login_audit (
+    user_id, user_login, success, failure_reason, ip_address, user_agent, created_at
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7
+);
+
+-- name: GetLoginFailuresByLogin :one
+-- Failed attempts at user_login after since and after its last successful
+-- login. Attempts refused while locked out or for an expired password don't
+-- count.
+SELECT COUNT(*)::int AS failures, MAX(created_at)::timestamp AS last_failure
+FROM login_audit
+WHERE user_login = sqlc.arg(user_login)
+  AND success = false
+  AND failure_reason NOT IN ('locked', 'password_expired')
+  AND created_at > GREATEST(sqlc.arg(since)::timestamp, COALESCE((
+      SELECT MAX(s.created_at) FROM login_audit s
+      WHERE s.user_login = sqlc.arg(user_login) AND s.success = true
+  ), sqlc.arg(since)::timestamp));
+
+-- name: GetLoginFailuresByIP :one
+-- Failed attempts from ip_address after since, whatever the login. Attempts
+-- refused while locked out or for an expired password don't count.
+SELECT COUNT(*)::int AS failures, MAX(created_at)::timestamp AS last_failure
+FROM login_audit
+WHERE ip_address = sqlc.arg(ip_address)
+  AND success = false
+  AND failure_reason NOT IN ('locked', 'password_expired')
+  AND created_at > sqlc.arg(since)::timestamp;
+
+-- name: ListUserLoginAudit :many
+SELECT * FROM login_audit
+WHERE user_id = sqlc.arg(user_id)::int
+ORDER BY created_at DESC, id DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
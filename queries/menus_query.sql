@@ -62,3 +62,34 @@ UPDATE menus
 SET is_active = $2
 WHERE id = $1
 RETURNING *;
+
+-- name: ListMenuSiblings :many
+-- Menus of a module under the same parent menu (top-level menus when the
+-- parent is NULL), in display order.
+SELECT * FROM menus
+WHERE module_id = sqlc.arg('module_id')
+  AND parent_menu_id IS NOT DISTINCT FROM sqlc.narg('parent_menu_id')
+ORDER BY display_order, id;
+
+-- name: ListMenuSubtreeIDs :many
+-- The menu and all of its descendant menus.
+WITH RECURSIVE subtree AS (
+    SELECT id FROM menus WHERE id = $1
+    UNION
+    SELECT m.id
+    FROM menus m
+    INNER JOIN subtree s ON m.parent_menu_id = s.id
+)
+SELECT id FROM subtree;
+
+-- name: SetMenuDisplayOrder :exec
+UPDATE menus
+SET display_order = $2
+WHERE id = $1;
+
+-- name: SetMenuParent :one
+-- Unlike UpdateMenu this leaves every other column alone.
+UPDATE menus
+SET parent_menu_id = sqlc.narg('parent_menu_id')
+WHERE id = sqlc.arg('id')
+RETURNING *;
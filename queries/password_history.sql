@@ -0,0 +1,20 @@
+-- name: CreatePasswordHistory :exec
+INSERT INTO password_history (user_id, password_hash)
+VALUES ($1, $2);
+
+-- name: ListRecentPasswordHashes :many
+SELECT password_hash FROM password_history
+WHERE user_id = $1
+ORDER BY created_at DESC, id DESC
+LIMIT $2;
+
+-- name: PrunePasswordHistory :exec
+-- Keeps the newest keep hashes of the user.
+DELETE FROM password_history
+WHERE user_id = sqlc.arg(user_id)
+  AND id NOT IN (
+      SELECT h.id FROM password_history h
+      WHERE h.user_id = sqlc.arg(user_id)
+      ORDER BY h.created_at DESC, h.id DESC
+      LIMIT sqlc.arg(keep)
+  );
@@ -165,4 +165,25 @@ INNER JOIN submenu_permissions sp ON sm.id = sp.submenu_id
 INNER JOIN role_permissions rp ON sp.permission_id = rp.permission_id
 INNER JOIN user_roles ur ON rp.role_id = ur.role_id
 WHERE ur.user_id = $1 AND sm.is_active = true
-ORDER BY sm.display_order;
\ No newline at end of file
+ORDER BY sm.display_order;
+
+-- =====================================================
+-- PERMISSION MATRIX
+-- =====================================================
+
+-- name: ListAllPermissions :many
+SELECT * FROM permissions
+ORDER BY code;
+
+-- name: ListRolePermissionGrants :many
+-- Every grant of a permission to a role, for the role x permission matrix.
+SELECT role_id, permission_id, scope FROM role_permissions
+ORDER BY role_id, permission_id;
+
+-- name: GrantPermissionToRole :exec
+-- Grants the permission, or changes the scope of an existing grant when a
+-- scope is given.
+INSERT INTO role_permissions (role_id, permission_id, scope)
+VALUES (sqlc.arg(role_id), sqlc.arg(permission_id), COALESCE(sqlc.narg(scope), 'all'))
+ON CONFLICT (role_id, permission_id)
+DO UPDATE SET scope = COALESCE(sqlc.narg(scope), role_permissions.scope);
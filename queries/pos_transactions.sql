@@ -28,6 +28,10 @@ INSERT INTO pos_transaction_lines (
     unit_price, discount_amount, tax_amount, line_total, cost_price, metadata
 ) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13,$14);
 
+-- name: GetPosTransactionByNumber :one
+SELECT * FROM pos_transactions
+WHERE transaction_number = $1;
+
 -- name: GetPosTransactionFull :many
 SELECT 
     t.id, t.transaction_number, t.transaction_date, t.status,
@@ -61,6 +65,52 @@ LEFT JOIN product_barcodes pb
 WHERE t.id = $1
 ORDER BY tl.line_number;
 
+-- name: GetPosTransactionLinesForInvoice :many
+SELECT
+    tl.line_number,
+    p.name AS product_name,
+    tl.quantity,
+    tl.unit_price,
+    tl.discount_amount,
+    tl.tax_amount,
+    tl.line_total,
+    COALESCE((tl.metadata->>'tax_rate')::numeric, 0)::numeric      AS tax_rate,
+    COALESCE((tl.metadata->>'tax_inclusive')::bool, false)::bool AS is_inclusive
+FROM pos_transaction_lines tl
+JOIN products p ON tl.product_id = p.id
+WHERE tl.transaction_id = $1
+ORDER BY tl.line_number;
+
+-- name: GetPosTransactionTaxBreakdown :many
+SELECT
+    COALESCE(tl.metadata->>'tax_name', '')::text                  AS tax_name,
+    COALESCE((tl.metadata->>'tax_rate')::numeric, 0)::numeric      AS tax_rate,
+    COALESCE((tl.metadata->>'tax_inclusive')::bool, false)::bool AS is_inclusive,
+    SUM(tl.line_total - CASE WHEN (tl.metadata->>'tax_inclusive')::bool THEN tl.tax_amount ELSE 0 END)::numeric AS taxable_amount,
+    SUM(tl.tax_amount)::numeric                                    AS tax_amount
+FROM pos_transaction_lines tl
+WHERE tl.transaction_id = $1
+GROUP BY 1, 2, 3
+ORDER BY tax_rate DESC, tax_name;
+
+-- name: ListPosTransactionLines :many
+SELECT * FROM pos_transaction_lines
+WHERE transaction_id = $1
+ORDER BY line_number;
+
+-- name: ListReturnedQuantities :many
+-- Quantities already returned per line of a sale, summed over its completed
+-- return transactions.
+SELECT
+    (l.metadata->>'original_line_id')::int AS original_line_id,
+    SUM(l.quantity)::numeric               AS quantity
+FROM pos_transaction_lines l
+JOIN pos_transactions t ON t.id = l.transaction_id
+WHERE t.transaction_type = 'return'
+  AND t.status = 'completed'
+  AND (t.metadata->>'original_transaction_id')::int = sqlc.arg('original_transaction_id')::int
+GROUP BY 1;
+
 -- name: ListTodaysPosTransactions :many
 SELECT 
     t.id,
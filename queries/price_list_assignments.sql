@@ -0,0 +1,67 @@
+-- name: CreatePriceListAssignment :one
+INSERT INTO price_list_assignments (
+    price_list_id,
+    scope,
+    customer_id,
+    customer_type,
+    store_id,
+    priority,
+    valid_from,
+    valid_to,
+    is_active,
+    metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10
+) RETURNING *;
+
+-- name: DeletePriceListAssignment :exec
+DELETE FROM price_list_assignments
+WHERE id = $1;
+
+-- name: ListApplicablePriceLists :many
+-- Active price lists that can price a sale for a customer, customer type and
+-- store on a date, with the scope that makes each one applicable. The same
+-- list may appear under several scopes.
+SELECT
+    pl.id,
+    pl.code,
+    pl.name,
+    pl.currency_code,
+    src.scope::varchar AS scope,
+    src.priority::int AS priority
+FROM (
+    SELECT c.price_list_id, 'customer' AS scope, 0 AS priority
+    FROM customers c
+    WHERE c.id = sqlc.narg('customer_id') AND c.price_list_id IS NOT NULL
+    UNION ALL
+    SELECT a.price_list_id, a.scope, a.priority
+    FROM price_list_assignments a
+    WHERE a.is_active = true
+      AND (
+            (a.scope = 'customer' AND a.customer_id = sqlc.narg('customer_id'))
+         OR (a.scope = 'customer_type' AND a.customer_type = sqlc.narg('customer_type'))
+         OR (a.scope = 'store' AND a.store_id = sqlc.narg('store_id'))
+         OR (a.scope = 'promotion' AND (a.store_id IS NULL OR a.store_id = sqlc.narg('store_id')))
+      )
+      AND (a.valid_from IS NULL OR a.valid_from <= sqlc.arg('on_date')::date)
+      AND (a.valid_to IS NULL OR a.valid_to >= sqlc.arg('on_date')::date)
+    UNION ALL
+    SELECT id, 'promotion', 0 FROM price_lists WHERE price_list_type = 'promotion'
+    UNION ALL
+    SELECT id, 'default', 0 FROM price_lists WHERE is_default = true
+) src
+JOIN price_lists pl ON pl.id = src.price_list_id
+WHERE pl.is_active = true
+  AND (pl.valid_from IS NULL OR pl.valid_from <= sqlc.arg('on_date')::date)
+  AND (pl.valid_to IS NULL OR pl.valid_to >= sqlc.arg('on_date')::date)
+ORDER BY src.priority DESC, pl.id;
+
+-- name: ListPriceListAssignments :many
+SELECT
+    a.*,
+    pl.code AS price_list_code,
+    pl.name AS price_list_name
+FROM price_list_assignments a
+JOIN price_lists pl ON pl.id = a.price_list_id
+WHERE a.scope = COALESCE(sqlc.narg('scope'), a.scope)
+ORDER BY a.scope, a.priority DESC, a.id;
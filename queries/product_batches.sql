@@ -113,6 +113,42 @@ WHERE product_id = $1
   AND quantity_available > 0
 ORDER BY expiry_date NULLS LAST, manufacturing_date;
 
+-- name: ListAllocatableBatches :many
+-- Active batches of a product (exact variant) with stock in a store, in
+-- first-expiry-first-out order. Expired batches are included so callers can
+-- report them; they are never allocated.
+SELECT * FROM product_batches
+WHERE product_id = sqlc.arg('product_id')
+  AND product_variant_id IS NOT DISTINCT FROM sqlc.narg('product_variant_id')
+  AND store_id = sqlc.arg('store_id')
+  AND status = 'active'
+  AND quantity_available > 0
+ORDER BY expiry_date NULLS LAST, manufacturing_date NULLS LAST, id;
+
+-- name: ConsumeBatchQuantity :one
+-- Takes stock from a batch only while it is active, not expired on the given
+-- date and still holds enough; no row means the allocation is stale.
+UPDATE product_batches
+SET quantity_available = quantity_available - sqlc.arg('quantity')
+WHERE id = sqlc.arg('id')
+  AND status = 'active'
+  AND quantity_available >= sqlc.arg('quantity')
+  AND (expiry_date IS NULL OR expiry_date >= sqlc.arg('on_date'))
+RETURNING *;
+
+-- name: ReceiveBatchQuantity :one
+-- Adds stock to a batch in a store, creating the batch on first receipt.
+INSERT INTO product_batches (
+    product_id, product_variant_id, batch_number,
+    manufacturing_date, expiry_date, store_id,
+    quantity_available, status, metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, 'active', '{}'
+)
+ON CONFLICT (product_id, batch_number, store_id) DO UPDATE
+SET quantity_available = product_batches.quantity_available + EXCLUDED.quantity_available
+RETURNING *;
+
 -- =====================================================
 -- PRODUCT SERIAL NUMBERS
 -- Note: Product serial number queries are in product_serial_numbers_query.sql
@@ -124,13 +160,16 @@ SELECT
     p.name as product_name,
     p.sku as product_sku,
     from_store.name as from_store_name,
-    to_store.name as to_store_name
+    to_store.name as to_store_name,
+    COALESCE(pt.transaction_number, po.po_number, sm.metadata->>'transfer_number', '')::text as reference_number
 FROM stock_movements sm
 INNER JOIN products p ON sm.product_id = p.id
 LEFT JOIN stores from_store ON sm.from_store_id = from_store.id
 LEFT JOIN stores to_store ON sm.to_store_id = to_store.id
+LEFT JOIN pos_transactions pt ON sm.reference_type = 'pos_transaction' AND pt.id = sm.reference_id
+LEFT JOIN purchase_orders po ON sm.reference_type = 'purchase_order' AND po.id = sm.reference_id
 WHERE sm.serial_number = $1
-ORDER BY sm.movement_date DESC;
+ORDER BY sm.movement_date, sm.id;
 
 -- =====================================================
 -- BATCH AND SERIAL NUMBER REPORTS
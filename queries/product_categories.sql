@@ -41,4 +41,76 @@ WHERE c.is_active = true
       SELECT 1 FROM product_categories child 
       WHERE child.parent_category_id = c.id AND child.is_active = true
   )
-ORDER BY c.name;
\ No newline at end of file
+ORDER BY c.name;
+
+-- name: CountCategoryProducts :one
+-- Products of any status filed under the category.
+SELECT COUNT(*) FROM products
+WHERE category_id = $1;
+
+-- name: ListCategoryProductCounts :many
+SELECT category_id, COUNT(*) AS product_count
+FROM products
+WHERE category_id IS NOT NULL
+  AND is_active = true
+GROUP BY category_id;
+
+-- name: ListCategorySubtreeIDs :many
+-- The category and all of its descendants.
+WITH RECURSIVE subtree AS (
+    SELECT id FROM product_categories WHERE id = $1
+    UNION
+    SELECT c.id
+    FROM product_categories c
+    INNER JOIN subtree s ON c.parent_category_id = s.id
+)
+SELECT id FROM subtree;
+
+-- name: LockCategoryTree :exec
+-- Serialises changes to the category tree until the transaction ends.
+SELECT pg_advisory_xact_lock(hashtext('product_categories'), 0);
+
+-- name: ReassignCategoryProducts :execrows
+UPDATE products
+SET category_id = sqlc.arg('to_category_id')
+WHERE category_id = sqlc.arg('from_category_id');
+
+-- name: ReparentCategoryChildren :execrows
+UPDATE product_categories
+SET parent_category_id = sqlc.narg('to_category_id')
+WHERE parent_category_id = sqlc.arg('from_category_id');
+
+-- name: SetCategoryParent :one
+-- Unlike UpdateProductCategory this can also clear the parent.
+UPDATE product_categories
+SET parent_category_id = sqlc.narg('parent_category_id')
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: UpdateCategorySubtreeLevels :execrows
+-- Sets category_level to the depth (roots are 1) for the category and its
+-- descendants, after it was created or moved.
+WITH RECURSIVE ancestors AS (
+    SELECT parent_category_id AS id, 1 AS depth
+    FROM product_categories
+    WHERE id = $1 AND parent_category_id IS NOT NULL
+    UNION ALL
+    SELECT c.parent_category_id, a.depth + 1
+    FROM product_categories c
+    INNER JOIN ancestors a ON c.id = a.id
+    WHERE c.parent_category_id IS NOT NULL
+),
+subtree AS (
+    SELECT id, (SELECT COUNT(*) FROM ancestors)::int + 1 AS level
+    FROM product_categories
+    WHERE id = $1
+    UNION ALL
+    SELECT c.id, s.level + 1
+    FROM product_categories c
+    INNER JOIN subtree s ON c.parent_category_id = s.id
+)
+UPDATE product_categories pc
+SET category_level = s.level
+FROM subtree s
+WHERE pc.id = s.id
+  AND pc.category_level IS DISTINCT FROM s.level;
@@ -65,14 +65,14 @@ ORDER BY p.name;
 -- name: GetEffectivePrice :one
 SELECT pp.* FROM product_prices pp
 WHERE pp.product_id = $1
-  AND pp.product_variant_id = COALESCE(sqlc.narg(product_variant_id), pp.product_variant_id)
+  AND (pp.product_variant_id IS NULL OR pp.product_variant_id = sqlc.narg(product_variant_id))
   AND pp.price_list_id = $2
   AND pp.is_active = true
   AND (pp.valid_from IS NULL OR pp.valid_from <= CURRENT_DATE)
   AND (pp.valid_to IS NULL OR pp.valid_to >= CURRENT_DATE)
   AND pp.min_quantity <= $3
   AND (pp.max_quantity IS NULL OR pp.max_quantity >= $3)
-ORDER BY pp.min_quantity DESC
+ORDER BY pp.product_variant_id NULLS LAST, pp.min_quantity DESC
 LIMIT 1;
 
 -- name: UpdateProductPrice :one
@@ -91,11 +91,48 @@ RETURNING *;
 -- name: DeleteProductPrice :exec
 DELETE FROM product_prices WHERE id = $1;
 
--- name: BulkUpdatePrices :exec
-UPDATE product_prices
-SET price = price * (1 + $2 / 100.0)
-WHERE price_list_id = $1
-  AND is_active = true;
+-- name: BulkUpdatePrices :execrows
+-- Adjusts the active prices of a list running today by a percentage or a
+-- fixed amount, optionally only for one category or brand. Versions
+-- scheduled for later dates keep their price. Prices never go below zero.
+UPDATE product_prices pp
+SET price = GREATEST(ROUND(CASE
+        WHEN sqlc.arg('mode')::text = 'fixed' THEN pp.price + sqlc.arg('amount')::numeric
+        ELSE pp.price * (1 + sqlc.arg('amount')::numeric / 100.0)
+    END, 2), 0)
+FROM products p
+WHERE p.id = pp.product_id
+  AND pp.price_list_id = sqlc.arg('price_list_id')
+  AND pp.is_active = true
+  AND (pp.valid_from IS NULL OR pp.valid_from <= CURRENT_DATE)
+  AND (pp.valid_to IS NULL OR pp.valid_to >= CURRENT_DATE)
+  AND (sqlc.narg('category_id')::int IS NULL OR p.category_id = sqlc.narg('category_id'))
+  AND (sqlc.narg('brand_id')::int IS NULL OR p.brand_id = sqlc.narg('brand_id'));
+
+-- name: ListPricesForAdjustment :many
+-- Active prices of a list running on a date, optionally only for one
+-- category or brand.
+SELECT pp.* FROM product_prices pp
+JOIN products p ON p.id = pp.product_id
+WHERE pp.price_list_id = sqlc.arg('price_list_id')
+  AND pp.is_active = true
+  AND (pp.valid_from IS NULL OR pp.valid_from <= sqlc.arg('on_date')::date)
+  AND (pp.valid_to IS NULL OR pp.valid_to >= sqlc.arg('on_date')::date)
+  AND (sqlc.narg('category_id')::int IS NULL OR p.category_id = sqlc.narg('category_id'))
+  AND (sqlc.narg('brand_id')::int IS NULL OR p.brand_id = sqlc.narg('brand_id'))
+ORDER BY pp.product_id, pp.id;
+
+-- name: ListPriceVersions :many
+-- All active versions of one price: same list, product, variant, unit and
+-- quantity tier.
+SELECT * FROM product_prices
+WHERE price_list_id = sqlc.arg('price_list_id')
+  AND product_id = sqlc.arg('product_id')
+  AND product_variant_id IS NOT DISTINCT FROM sqlc.narg('product_variant_id')
+  AND uom_id IS NOT DISTINCT FROM sqlc.narg('uom_id')
+  AND min_quantity IS NOT DISTINCT FROM sqlc.narg('min_quantity')
+  AND is_active = true
+ORDER BY valid_from NULLS FIRST, id;
 
 -- name: ExpirePrices :exec
 UPDATE product_prices
@@ -166,4 +203,13 @@ LEFT JOIN product_prices pp ON pl.id = pp.price_list_id
     AND pp.product_id = $1
     AND pp.is_active = true
 WHERE pl.is_active = true
-ORDER BY pl.name;
\ No newline at end of file
+ORDER BY pl.name;
+
+-- name: ListPriceCandidates :many
+-- Active price rows of the given products in the given price lists; the
+-- price resolver applies variant, UoM, tier and date rules in Go.
+SELECT * FROM product_prices
+WHERE product_id = ANY(sqlc.arg('product_ids')::int[])
+  AND price_list_id = ANY(sqlc.arg('price_list_ids')::int[])
+  AND is_active = true
+ORDER BY product_id, price_list_id, id;
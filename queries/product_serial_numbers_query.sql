@@ -69,6 +69,17 @@ SET
 WHERE serial_number = $1
 RETURNING *;
 
+-- name: MoveSerialNumber :one
+-- Changes a serial's status and store only if it still has from_status, so
+-- concurrent sales or returns of the same unit cannot both succeed.
+UPDATE product_serial_numbers
+SET
+    status = sqlc.arg('status'),
+    current_store_id = sqlc.narg('store_id')
+WHERE id = sqlc.arg('id')
+  AND status = sqlc.arg('from_status')
+RETURNING *;
+
 -- name: DeleteProductSerialNumber :exec
 DELETE FROM product_serial_numbers
 WHERE id = $1;
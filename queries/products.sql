@@ -147,12 +147,15 @@ WHERE organization_id = $1 AND sku = $2
 LIMIT 1;
 
 -- name: ListProducts :many
+-- Products of an organization; each filter applies only when given, and
+-- search matches SKU or name.
 SELECT * FROM products
 WHERE organization_id = $1
   AND is_active = COALESCE(sqlc.narg(is_active), is_active)
-  AND category_id = COALESCE(sqlc.narg(category_id), category_id)
-  AND brand_id = COALESCE(sqlc.narg(brand_id), brand_id)
-  AND product_type = COALESCE(sqlc.narg(product_type), product_type)
+  AND (sqlc.narg(category_id)::int IS NULL OR category_id = sqlc.narg(category_id))
+  AND (sqlc.narg(brand_id)::int IS NULL OR brand_id = sqlc.narg(brand_id))
+  AND (sqlc.narg(product_type)::text IS NULL OR product_type = sqlc.narg(product_type))
+  AND (sqlc.narg(search)::text IS NULL OR sku ILIKE '%' || sqlc.narg(search) || '%' OR name ILIKE '%' || sqlc.narg(search) || '%')
 ORDER BY name
 LIMIT $2 OFFSET $3;
 
@@ -176,6 +179,10 @@ WHERE organization_id = $1
 ORDER BY name
 LIMIT $2 OFFSET $3;
 
+-- name: ListProductsByIDs :many
+SELECT * FROM products
+WHERE id = ANY(sqlc.arg('ids')::int[]);
+
 -- name: ListPurchasableProducts :many
 SELECT * FROM products
 WHERE organization_id = $1
@@ -18,4 +18,41 @@ JOIN suppliers sup ON po.supplier_id = sup.id
 JOIN purchase_order_lines pol ON pol.purchase_order_id = po.id
 JOIN products p ON pol.product_id = p.id
 WHERE po.id = $1
-ORDER BY pol.line_number;
\ No newline at end of file
+ORDER BY pol.line_number;
+
+-- name: CreatePurchaseOrderHeader :one
+INSERT INTO purchase_orders (
+    po_number, organization_id, supplier_id, store_id,
+    po_date, expected_delivery_date, status,
+    subtotal, tax_amount, discount_amount, total_amount,
+    created_by, metadata
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+RETURNING id, po_number, status, total_amount;
+
+-- name: CreatePurchaseOrderLine :exec
+INSERT INTO purchase_order_lines (
+    purchase_order_id, line_number, product_id, product_variant_id,
+    quantity, uom_id, unit_price, discount_amount,
+    tax_amount, line_total, metadata
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11);
+
+-- name: GetPurchaseOrder :one
+SELECT * FROM purchase_orders WHERE id = $1;
+
+-- name: ListPurchaseOrderLines :many
+SELECT * FROM purchase_order_lines
+WHERE purchase_order_id = $1
+ORDER BY line_number;
+
+-- name: ReceivePurchaseOrderLine :execrows
+-- Adds a received quantity to an order line unless it would exceed the
+-- ordered quantity.
+UPDATE purchase_order_lines
+SET received_quantity = COALESCE(received_quantity, 0) + sqlc.arg('quantity')
+WHERE id = sqlc.arg('id')
+  AND COALESCE(received_quantity, 0) + sqlc.arg('quantity') <= quantity;
+
+-- name: UpdatePurchaseOrderStatus :exec
+UPDATE purchase_orders
+SET status = $2
+WHERE id = $1;
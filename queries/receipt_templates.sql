@@ -0,0 +1,48 @@
+-- name: ClearDefaultReceiptTemplate :exec
+UPDATE receipt_templates
+SET is_default = false
+WHERE is_default = true AND code <> $1;
+
+-- name: GetDefaultReceiptTemplate :one
+SELECT * FROM receipt_templates
+WHERE is_default = true AND is_active = true
+LIMIT 1;
+
+-- name: GetReceiptTemplateByCode :one
+SELECT * FROM receipt_templates
+WHERE code = $1;
+
+-- name: ListReceiptTemplates :many
+SELECT * FROM receipt_templates
+ORDER BY is_default DESC, name;
+
+-- name: UpsertReceiptTemplate :one
+INSERT INTO receipt_templates (
+    code,
+    name,
+    language,
+    paper_width,
+    header_lines,
+    footer_lines,
+    show_tax_breakdown,
+    show_barcode,
+    code_page,
+    is_default,
+    is_active,
+    metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12
+)
+ON CONFLICT (code) DO UPDATE SET
+    name = EXCLUDED.name,
+    language = EXCLUDED.language,
+    paper_width = EXCLUDED.paper_width,
+    header_lines = EXCLUDED.header_lines,
+    footer_lines = EXCLUDED.footer_lines,
+    show_tax_breakdown = EXCLUDED.show_tax_breakdown,
+    show_barcode = EXCLUDED.show_barcode,
+    code_page = EXCLUDED.code_page,
+    is_default = EXCLUDED.is_default,
+    is_active = EXCLUDED.is_active,
+    metadata = EXCLUDED.metadata
+RETURNING *;
@@ -70,4 +70,11 @@ WHERE organization_id = $1
   AND order_date >= $2::date
   AND order_date <= $3::date
 GROUP BY status
-ORDER BY status;
\ No newline at end of file
+ORDER BY status;
+
+-- name: CreateSalesOrderLine :exec
+INSERT INTO sales_order_lines (
+    sales_order_id, line_number, product_id, product_variant_id,
+    quantity, uom_id, unit_price, discount_amount,
+    tax_amount, line_total, cost_price, metadata
+) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12);
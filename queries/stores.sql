@@ -23,6 +23,7 @@ SELECT * FROM stores
 WHERE organization_id = $1
   AND is_active = COALESCE(sqlc.narg(is_active), is_active)
   AND store_type = COALESCE(sqlc.narg(store_type), store_type)
+  AND (sqlc.narg(store_ids)::int[] IS NULL OR id = ANY(sqlc.narg(store_ids)::int[]))
 ORDER BY name
 LIMIT $2 OFFSET $3;
 
@@ -106,4 +107,9 @@ WHERE CASE
     WHEN sqlc.narg(filter_is_active) IS NULL THEN true
     ELSE tree_data.is_active = sqlc.narg(filter_is_active)
 END
-ORDER BY tree_data.path;
\ No newline at end of file
+ORDER BY tree_data.path;
+
+-- name: ListOrganizationStoreIDs :many
+SELECT id FROM stores
+WHERE organization_id = $1
+ORDER BY id;
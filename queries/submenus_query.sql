@@ -62,3 +62,49 @@ UPDATE submenus
 SET is_active = $2
 WHERE id = $1
 RETURNING *;
+
+-- name: ListSubmenuSiblings :many
+-- Submenus of a menu under the same parent submenu (top-level submenus when
+-- the parent is NULL), in display order.
+SELECT * FROM submenus
+WHERE menu_id = sqlc.arg('menu_id')
+  AND parent_submenu_id IS NOT DISTINCT FROM sqlc.narg('parent_submenu_id')
+ORDER BY display_order, id;
+
+-- name: ListSubmenuSubtreeIDs :many
+-- The submenu and all of its descendant submenus.
+WITH RECURSIVE subtree AS (
+    SELECT id FROM submenus WHERE id = $1
+    UNION
+    SELECT s.id
+    FROM submenus s
+    INNER JOIN subtree t ON s.parent_submenu_id = t.id
+)
+SELECT id FROM subtree;
+
+-- name: SetSubmenuDisplayOrder :exec
+UPDATE submenus
+SET display_order = $2
+WHERE id = $1;
+
+-- name: SetSubmenuParent :one
+-- Moves a submenu to a menu and parent submenu, leaving every other column
+-- alone. Its descendants follow with SetSubmenuSubtreeMenu.
+UPDATE submenus
+SET menu_id = sqlc.arg('menu_id'),
+    parent_submenu_id = sqlc.narg('parent_submenu_id')
+WHERE id = sqlc.arg('id')
+RETURNING *;
+
+-- name: SetSubmenuSubtreeMenu :execrows
+-- Moves the descendants of a submenu to its new menu.
+WITH RECURSIVE subtree AS (
+    SELECT id FROM submenus WHERE parent_submenu_id = sqlc.arg('id')
+    UNION
+    SELECT s.id
+    FROM submenus s
+    INNER JOIN subtree t ON s.parent_submenu_id = t.id
+)
+UPDATE submenus
+SET menu_id = sqlc.arg('menu_id')
+WHERE id IN (SELECT id FROM subtree);
@@ -47,3 +47,17 @@ UPDATE tax_categories
 SET is_active = $2
 WHERE id = $1
 RETURNING *;
+
+-- name: CountTaxCategoryProducts :one
+SELECT COUNT(*) FROM products
+WHERE tax_category_id = $1;
+
+-- name: ListTaxCategoriesWithUsage :many
+SELECT
+    tc.id, tc.name, tc.code, tc.tax_rate, tc.is_inclusive, tc.is_active,
+    COUNT(p.id) AS product_count
+FROM tax_categories tc
+LEFT JOIN products p ON p.tax_category_id = tc.id
+WHERE (sqlc.narg('active_only')::boolean IS NULL OR tc.is_active = sqlc.narg('active_only'))
+GROUP BY tc.id
+ORDER BY tc.name;
@@ -0,0 +1,31 @@
+-- name: CreateTaxCategoryChange :one
+INSERT INTO tax_category_changes (
+    tax_category_id,
+    tax_category_code,
+    action,
+    old_tax_rate,
+    new_tax_rate,
+    old_is_inclusive,
+    new_is_inclusive,
+    old_is_active,
+    new_is_active,
+    changed_by,
+    metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: ListTaxCategoryChanges :many
+-- Newest first; by code so the history of a deleted category stays
+-- reachable.
+SELECT
+    tcc.id, tcc.tax_category_id, tcc.tax_category_code, tcc.action,
+    tcc.old_tax_rate, tcc.new_tax_rate,
+    tcc.old_is_inclusive, tcc.new_is_inclusive,
+    tcc.old_is_active, tcc.new_is_active,
+    tcc.changed_by, tcc.changed_at,
+    u.username AS changed_by_username
+FROM tax_category_changes tcc
+LEFT JOIN users u ON u.id = tcc.changed_by
+WHERE tcc.tax_category_code = $1
+ORDER BY tcc.changed_at DESC, tcc.id DESC;
@@ -80,3 +80,21 @@ RETURNING *;
 -- name: DeleteProductUOMConversion :exec
 DELETE FROM product_uom_conversions
 WHERE id = $1;
+
+-- name: GetUnitOfMeasureUsage :one
+-- What references a unit: products using it as base unit, unit
+-- conversions (deleted with the unit) and prices.
+SELECT
+    (SELECT COUNT(*) FROM products p WHERE p.base_uom_id = sqlc.arg('id')::int) AS product_count,
+    (SELECT COUNT(*) FROM product_uom_conversions c WHERE c.from_uom_id = sqlc.arg('id')::int OR c.to_uom_id = sqlc.arg('id')::int) AS conversion_count,
+    (SELECT COUNT(*) FROM product_prices pp WHERE pp.uom_id = sqlc.arg('id')::int) AS price_count;
+
+-- name: ListUnitsOfMeasureWithUsage :many
+SELECT
+    u.id, u.code, u.name, u.uom_type, u.decimal_places, u.is_active,
+    (SELECT COUNT(*) FROM products p WHERE p.base_uom_id = u.id) AS product_count,
+    (SELECT COUNT(*) FROM product_uom_conversions c WHERE c.from_uom_id = u.id OR c.to_uom_id = u.id) AS conversion_count
+FROM units_of_measure u
+WHERE (sqlc.narg('active_only')::boolean IS NULL OR u.is_active = sqlc.narg('active_only'))
+  AND (sqlc.narg('uom_type')::text IS NULL OR u.uom_type = sqlc.narg('uom_type'))
+ORDER BY u.name;
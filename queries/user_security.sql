@@ -0,0 +1,16 @@
+-- name: GetUserSecurity :one
+SELECT * FROM user_security
+WHERE user_id = $1;
+
+-- name: RevokeUserSessions :exec
+-- Tokens issued before revoked_at are refused.
+INSERT INTO user_security (user_id, sessions_revoked_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(revoked_at)::timestamp)
+ON CONFLICT (user_id) DO UPDATE
+SET sessions_revoked_at = EXCLUDED.sessions_revoked_at;
+
+-- name: SetUserPasswordChanged :exec
+INSERT INTO user_security (user_id, password_changed_at)
+VALUES (sqlc.arg(user_id), sqlc.arg(changed_at)::timestamp)
+ON CONFLICT (user_id) DO UPDATE
+SET password_changed_at = EXCLUDED.password_changed_at;
@@ -45,6 +45,33 @@ WHERE organization_id = $1
 ORDER BY first_name, last_name
 LIMIT $3 OFFSET $4;
 
+-- name: FilterUsers :many
+-- Users of an organization matching the optional filters, with the number of
+-- matches for paging. Password hashes are left out.
+SELECT
+    u.id, u.organization_id, u.username, u.email, u.first_name, u.last_name,
+    u.employee_code, u.is_active, u.metadata, u.created_at, u.updated_at,
+    COUNT(*) OVER () AS total_count
+FROM users u
+WHERE u.organization_id = sqlc.arg(organization_id)
+  AND (sqlc.narg(search)::text IS NULL
+    OR u.username ILIKE '%' || sqlc.narg(search) || '%'
+    OR u.email ILIKE '%' || sqlc.narg(search) || '%'
+    OR u.first_name ILIKE '%' || sqlc.narg(search) || '%'
+    OR u.last_name ILIKE '%' || sqlc.narg(search) || '%'
+    OR u.employee_code ILIKE '%' || sqlc.narg(search) || '%')
+  AND (sqlc.narg(is_active)::boolean IS NULL OR u.is_active = sqlc.narg(is_active))
+  AND (sqlc.narg(role_id)::int IS NULL OR EXISTS (
+    SELECT 1 FROM user_roles ur
+    WHERE ur.user_id = u.id AND ur.role_id = sqlc.narg(role_id)
+  ))
+  AND (sqlc.narg(store_id)::int IS NULL OR EXISTS (
+    SELECT 1 FROM user_store_access usa
+    WHERE usa.user_id = u.id AND usa.store_id = sqlc.narg(store_id)
+  ))
+ORDER BY u.first_name, u.last_name, u.id
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
 -- name: UpdateUser :one
 UPDATE users
 SET 
@@ -125,6 +152,20 @@ INNER JOIN user_store_access usa ON s.id = usa.store_id
 WHERE usa.user_id = $1
 ORDER BY usa.is_primary DESC, s.name;
 
+-- name: ListUserStoreAccess :many
+-- The store access of a user with the stores' names, primary first.
+SELECT usa.id, usa.user_id, usa.store_id, usa.is_primary, usa.metadata, usa.granted_at,
+    s.organization_id, s.name AS store_name, s.code AS store_code, s.is_active AS store_is_active
+FROM user_store_access usa
+INNER JOIN stores s ON s.id = usa.store_id
+WHERE usa.user_id = $1
+ORDER BY usa.is_primary DESC, s.name;
+
+-- name: ListUserStoreIDs :many
+SELECT store_id FROM user_store_access
+WHERE user_id = $1
+ORDER BY store_id;
+
 -- name: GetUserPrimaryStore :one
 SELECT s.* FROM stores s
 INNER JOIN user_store_access usa ON s.id = usa.store_id
@@ -142,6 +183,18 @@ ORDER BY u.first_name, u.last_name;
 DELETE FROM user_store_access 
 WHERE user_id = $1 AND store_id = $2;
 
+-- name: CheckUserHasOrganizationAccess :one
+-- A user works in their own organization and in the organizations of the
+-- stores they have access to.
+SELECT EXISTS(
+    SELECT 1 FROM users u
+    WHERE u.id = sqlc.arg(user_id) AND u.organization_id = sqlc.arg(organization_id)
+    UNION ALL
+    SELECT 1 FROM user_store_access usa
+    INNER JOIN stores s ON s.id = usa.store_id
+    WHERE usa.user_id = sqlc.arg(user_id) AND s.organization_id = sqlc.arg(organization_id)
+) as has_access;
+
 -- name: CheckUserHasStoreAccess :one
 SELECT EXISTS(
     SELECT 1 FROM user_store_access 
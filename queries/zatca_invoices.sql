@@ -0,0 +1,30 @@
+-- name: CreateZatcaInvoice :one
+INSERT INTO zatca_invoices (
+    transaction_id,
+    pos_terminal_id,
+    invoice_counter,
+    invoice_uuid,
+    invoice_hash,
+    previous_hash,
+    qr_code,
+    invoice_xml,
+    signature,
+    status,
+    metadata
+) VALUES (
+    $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11
+) RETURNING *;
+
+-- name: GetLastZatcaInvoiceForTerminal :one
+SELECT * FROM zatca_invoices
+WHERE pos_terminal_id = $1
+ORDER BY invoice_counter DESC
+LIMIT 1;
+
+-- name: GetZatcaInvoiceByTransaction :one
+SELECT * FROM zatca_invoices
+WHERE transaction_id = $1;
+
+-- name: LockZatcaInvoiceChain :exec
+-- Serialises invoice numbering per terminal until the transaction ends.
+SELECT pg_advisory_xact_lock(sqlc.arg(lock_key)::bigint);
@@ -0,0 +1,55 @@
+package utils
+
+import (
+	"math/big"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgtype"
+)
+
+// NumericToRat converts a pgtype.Numeric into an exact rational.
+// NULL and NaN values convert to zero.
+func NumericToRat(n pgtype.Numeric) *big.Rat {
+	r := new(big.Rat)
+	if !n.Valid || n.NaN || n.Int == nil {
+		return r
+	}
+	r.SetInt(n.Int)
+	if n.Exp > 0 {
+		r.Mul(r, new(big.Rat).SetInt(pow10(n.Exp)))
+	} else if n.Exp < 0 {
+		r.Quo(r, new(big.Rat).SetInt(pow10(-n.Exp)))
+	}
+	return r
+}
+
+// RatToNumeric rounds r half away from zero to scale decimal places and
+// returns it as a pgtype.Numeric.
+func RatToNumeric(r *big.Rat, scale int32) pgtype.Numeric {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(pow10(scale)))
+	i, _ := new(big.Int).SetString(scaled.FloatString(0), 10)
+	return pgtype.Numeric{Int: i, Exp: -scale, Valid: true}
+}
+
+// RoundRat rounds r half away from zero to scale decimal places.
+func RoundRat(r *big.Rat, scale int) *big.Rat {
+	out, _ := new(big.Rat).SetString(r.FloatString(scale))
+	return out
+}
+
+// ParseDecimal parses a plain decimal string ("12.50") into a pgtype.Numeric
+// without a database round trip.
+func ParseDecimal(s string) (pgtype.Numeric, error) {
+	var n pgtype.Numeric
+	err := n.Scan(strings.TrimSpace(s))
+	return n, err
+}
+
+// FormatNumeric formats n with the given number of decimal places.
+func FormatNumeric(n pgtype.Numeric, places int) string {
+	return NumericToRat(n).FloatString(places)
+}
+
+func pow10(exp int32) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil)
+}
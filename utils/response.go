@@ -4,11 +4,15 @@ import "NEMBUS/internal/repository"
 
 // Standard codes
 const (
-	CodeOK       = 200
-	CodeCreated  = 201
-	CodeNotFound = 404
-	CodeBadReq   = 400
-	CodeError    = 500
+	CodeOK              = 200
+	CodeCreated         = 201
+	CodeAccepted        = 202
+	CodeNotFound        = 404
+	CodeBadReq          = 400
+	CodeUnauthorized    = 401
+	CodeForbidden       = 403
+	CodeTooManyRequests = 429
+	CodeError           = 500
 )
 
 // NewResponse creates a standard response object